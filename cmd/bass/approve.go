@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/cli"
+	"github.com/vito/bass/pkg/zapctx"
+	"go.uber.org/zap"
+)
+
+// approve approves the gate given by --approve, releasing each run waiting
+// on it. Errors if no run is waiting on it.
+func approve(ctx context.Context) error {
+	logger := zapctx.FromContext(ctx)
+
+	approval, err := bass.ApproveGate(approveGate, approver, bass.GateViaCLI)
+	if err != nil {
+		cli.WriteError(ctx, err)
+		return err
+	}
+
+	logger.Info("approved gate",
+		zap.String("gate", approval.Gate),
+		zap.String("approver", approval.Approver))
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/mattn/go-isatty"
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/cli"
+	"github.com/vito/progrock"
+)
+
+func buildBin(ctx context.Context) error {
+	return cli.Task(ctx, cmdline, func(ctx context.Context, vertex *progrock.VertexRecorder) error {
+		if flags.NArg() != 1 {
+			return fmt.Errorf("usage: %s --build-bin mytool script.bass", os.Args[0])
+		}
+
+		bundle := new(bytes.Buffer)
+		if _, err := cli.Bundle(ctx, bass.ImportSystemEnv(), vars, bundle, flags.Arg(0)); err != nil {
+			return err
+		}
+
+		exePath, err := os.Executable()
+		if err != nil {
+			return err
+		}
+
+		exe, err := os.Open(exePath)
+		if err != nil {
+			return err
+		}
+
+		defer exe.Close()
+
+		out, err := os.OpenFile(binPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+		if err != nil {
+			return err
+		}
+
+		defer out.Close()
+
+		if err := cli.BuildBin(out, exe, bundle.Bytes()); err != nil {
+			return err
+		}
+
+		return out.Close()
+	})
+}
+
+// embeddedBundle returns the bundle embedded by --build-bin, if any.
+func embeddedBundle() ([]byte, bool) {
+	exePath, err := os.Executable()
+	if err != nil {
+		return nil, false
+	}
+
+	bundle, ok, err := cli.EmbeddedBundle(exePath)
+	if err != nil {
+		return nil, false
+	}
+
+	return bundle, ok
+}
+
+// runBin runs the script embedded by --build-bin, passing all args along to
+// it.
+func runBin(ctx context.Context, bundle []byte) error {
+	script, err := cli.LoadBundle(bytes.NewReader(bundle))
+	if err != nil {
+		cli.WriteError(ctx, err)
+		return err
+	}
+
+	ctx, _, finish, err := initRuntimes(ctx)
+	if err != nil {
+		cli.WriteError(ctx, err)
+		return err
+	}
+
+	defer finish()
+
+	return cli.WithProgress(ctx, func(ctx context.Context) error {
+		return cli.Task(ctx, cmdline, func(ctx context.Context, vtx *progrock.VertexRecorder) error {
+			isTty := isatty.IsTerminal(os.Stdout.Fd())
+
+			stdout := bass.Stdout
+			if isTty {
+				stdout = bass.NewSink(bass.NewJSONSink("stdout vertex", vtx.Stdout()))
+			}
+
+			err := cli.RunFS(ctx, bass.ImportSystemEnv(), nil, script, os.Args[1:], stdout)
+
+			if !isTty {
+				// ensure a chained unix pipeline exits
+				os.Stdout.Close()
+			}
+
+			return err
+		})
+	})
+}
@@ -36,6 +36,7 @@ func bumpLockfile(ctx context.Context, bumpLock string) error {
 		return err
 	}
 
+	kept := content.Memos[:0]
 	for _, memo := range content.Memos {
 		thunk := bass.Thunk{}
 		err := thunk.UnmarshalProto(memo.Module)
@@ -43,6 +44,18 @@ func bumpLockfile(ctx context.Context, bumpLock string) error {
 			return err
 		}
 
+		// memos kept by Bass itself aren't modules; either leave them alone or
+		// discard them so the next run records them fresh
+		if refresh, internal := bass.InternalMemo(thunk); internal {
+			if !refresh {
+				kept = append(kept, memo)
+			}
+
+			continue
+		}
+
+		kept = append(kept, memo)
+
 		scope, err := bass.Bass.Load(ctx, thunk)
 		if err != nil {
 			return err
@@ -78,6 +91,8 @@ func bumpLockfile(ctx context.Context, bumpLock string) error {
 		}
 	}
 
+	content.Memos = kept
+
 	payload, err := prototext.MarshalOptions{Multiline: true}.Marshal(content)
 	if err != nil {
 		return err
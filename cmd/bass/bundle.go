@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/cli"
+	"github.com/vito/progrock"
+)
+
+func bundle(ctx context.Context) error {
+	return cli.Task(ctx, cmdline, func(ctx context.Context, vertex *progrock.VertexRecorder) error {
+		if flags.NArg() != 1 {
+			return fmt.Errorf("usage: %s --bundle out.bundle script.bass", os.Args[0])
+		}
+
+		out, err := os.Create(bundlePath)
+		if err != nil {
+			return err
+		}
+
+		defer out.Close()
+
+		digest, err := cli.Bundle(ctx, bass.ImportSystemEnv(), vars, out, flags.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(digest)
+
+		return out.Close()
+	})
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+	"github.com/vito/bass/pkg/cli"
+)
+
+// extracts command names from (defcommand) forms in a script, without
+// evaluating it
+const defcommandSed = `sed -n 's/.*(defcommand[[:space:]]\{1,\}\([^][[:space:]()]\{1,\}\).*/\1/p'`
+
+func completion(ctx context.Context) error {
+	var err error
+	switch completionShell {
+	case "bash":
+		err = bashCompletion(os.Stdout)
+	case "zsh":
+		fmt.Fprintln(os.Stdout, "autoload -U +X bashcompinit && bashcompinit")
+		err = bashCompletion(os.Stdout)
+	case "fish":
+		err = fishCompletion(os.Stdout)
+	default:
+		err = fmt.Errorf("unknown shell: %s (supported: bash, zsh, fish)", completionShell)
+	}
+
+	if err != nil {
+		cli.WriteError(ctx, err)
+		return err
+	}
+
+	return nil
+}
+
+func bashCompletion(w io.Writer) error {
+	var words []string
+	flags.VisitAll(func(f *flag.Flag) {
+		words = append(words, "--"+f.Name)
+		if f.Shorthand != "" {
+			words = append(words, "-"+f.Shorthand)
+		}
+	})
+
+	_, err := fmt.Fprintf(w, `_bass() {
+  local cur="${COMP_WORDS[COMP_CWORD]}"
+
+  local i script=""
+  for ((i = 1; i < COMP_CWORD; i++)); do
+    case "${COMP_WORDS[i]}" in
+      *.bass)
+        script="${COMP_WORDS[i]}"
+        break
+        ;;
+    esac
+  done
+
+  if [[ -n "$script" ]]; then
+    if [[ $((i + 1)) -eq $COMP_CWORD && -f "$script" ]]; then
+      COMPREPLY=($(compgen -W "$(%s "$script")" -- "$cur"))
+    fi
+
+    if [[ ${#COMPREPLY[@]} -eq 0 ]]; then
+      COMPREPLY=($(compgen -f -- "$cur"))
+    fi
+
+    return
+  fi
+
+  if [[ "$cur" == -* ]]; then
+    COMPREPLY=($(compgen -W "%s" -- "$cur"))
+  else
+    COMPREPLY=($(compgen -f -X '!*.bass' -- "$cur") $(compgen -d -- "$cur"))
+  fi
+}
+
+complete -o filenames -F _bass bass
+`, defcommandSed, strings.Join(words, " "))
+	return err
+}
+
+func fishCompletion(w io.Writer) error {
+	_, err := fmt.Fprintf(w, `function __bass_needs_command
+  set -l tokens (commandline -opc)
+  test (count $tokens) -ge 2; and string match -q -- '*.bass' $tokens[-1]
+end
+
+function __bass_commands
+  set -l script (commandline -opc)[-1]
+  test -f $script; and %s $script
+end
+
+complete -c bass -n __bass_needs_command -f -a '(__bass_commands)'
+`, defcommandSed)
+	if err != nil {
+		return err
+	}
+
+	flags.VisitAll(func(f *flag.Flag) {
+		if err != nil {
+			return
+		}
+
+		line := "complete -c bass -l " + f.Name
+		if f.Shorthand != "" {
+			line += " -s " + f.Shorthand
+		}
+
+		if f.Value.Type() != "bool" {
+			line += " -r"
+		}
+
+		line += " -d " + fishQuote(f.Usage)
+
+		_, err = fmt.Fprintln(w, line)
+	})
+
+	return err
+}
+
+func fishQuote(s string) string {
+	return "'" + strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), `'`, `\'`) + "'"
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/cli"
+	"github.com/vito/bass/pkg/runtimes"
+)
+
+func daemon(ctx context.Context, pool *runtimes.Pool) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	config, err := bass.LoadConfig(DefaultConfig)
+	if err != nil {
+		cli.WriteError(ctx, err)
+		return err
+	}
+
+	err = runtimes.ServeDaemon(ctx, pool, runtimes.NewQueue(config.Queue), drainTimeout)
+	if err != nil {
+		cli.WriteError(ctx, err)
+		return err
+	}
+
+	return nil
+}
+
+// daemonPool returns the runtimes served by a running daemon, unless this
+// process is the daemon.
+func daemonPool(ctx context.Context) (*runtimes.Pool, bool, error) {
+	if runDaemon {
+		return nil, false, nil
+	}
+
+	return runtimes.DaemonPool(ctx)
+}
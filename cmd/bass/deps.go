@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/cli"
+	"github.com/vito/progrock"
+)
+
+// depsDOT is the --out format for printing dependencies as a Graphviz graph.
+const depsDOT = "dot"
+
+func deps(ctx context.Context) error {
+	return cli.Task(ctx, cmdline, func(ctx context.Context, vertex *progrock.VertexRecorder) error {
+		if flags.NArg() != 1 {
+			return fmt.Errorf("usage: %s --deps [-o json|dot] script.bass", os.Args[0])
+		}
+
+		format := outputFormat
+		if format != string(cli.OutputJSON) && format != depsDOT {
+			return fmt.Errorf("unsupported --deps format: %s (must be json or dot)", format)
+		}
+
+		graph, err := cli.Deps(ctx, bass.ImportSystemEnv(), vars, flags.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		if format == depsDOT {
+			return graph.WriteDOT(os.Stdout)
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(graph.Tree())
+	})
+}
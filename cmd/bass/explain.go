@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/cli"
+)
+
+func explain(ctx context.Context) error {
+	dec := bass.NewRawDecoder(os.Stdin)
+
+	var thunk bass.Thunk
+	if err := dec.Decode(&thunk); err != nil {
+		cli.WriteError(ctx, err)
+		return err
+	}
+
+	exp, err := bass.Explain(thunk)
+	if err != nil {
+		cli.WriteError(ctx, err)
+		return err
+	}
+
+	fmt.Println(string(exp.JSON))
+	fmt.Println()
+	fmt.Println("sha256:", exp.SHA256)
+	fmt.Println("hash:", exp.Hash)
+
+	if exp.Cached {
+		fmt.Println("this thunk has run before; it should hit cache if the runtime still has it")
+		return nil
+	}
+
+	if exp.Similar == nil {
+		fmt.Println("no previous thunk found with the same image and command")
+		return nil
+	}
+
+	fmt.Println()
+	fmt.Println("changes since the most recent thunk with the same image and command:")
+
+	if len(exp.Diff) > 0 {
+		for _, change := range exp.Diff {
+			fmt.Println(change)
+		}
+
+		return nil
+	}
+
+	for _, change := range exp.Changes {
+		switch {
+		case change.Before == nil:
+			fmt.Printf("+ %s: %s\n", change.Path, change.After)
+		case change.After == nil:
+			fmt.Printf("- %s: %s\n", change.Path, change.Before)
+		default:
+			fmt.Printf("~ %s: %s -> %s\n", change.Path, change.Before, change.After)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/cli"
+	"github.com/vito/bass/pkg/zapctx"
+	"go.uber.org/zap"
+)
+
+// history lists recorded runs, optionally filtered by the category given as
+// the first argument.
+func history(ctx context.Context) error {
+	records, err := bass.ReadHistory(flags.Arg(0))
+	if err != nil {
+		cli.WriteError(ctx, err)
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "STARTED\tCATEGORY\tSTATUS\tDURATION\tTHUNKS\tSCRIPT")
+
+	for _, record := range records {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n",
+			record.Started.Local().Format(time.RFC3339),
+			record.Category,
+			record.Status,
+			record.Duration.Truncate(time.Millisecond),
+			len(record.Thunks),
+			record.Script)
+	}
+
+	return w.Flush()
+}
+
+// flakes lists the thunks run with retries, flakiest first, optionally
+// filtered by the category given as the first argument.
+func flakes(ctx context.Context) error {
+	records, err := bass.ReadHistory(flags.Arg(0))
+	if err != nil {
+		cli.WriteError(ctx, err)
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "FLAKES\tFAILURES\tRUNS\tTHUNK")
+
+	for _, stat := range bass.FlakeStats(records) {
+		fmt.Fprintf(w, "%d\t%d\t%d\t%s\n",
+			stat.Flakes,
+			stat.Failures,
+			stat.Runs,
+			stat.Name)
+	}
+
+	return w.Flush()
+}
+
+// recordRun calls f and records its outcome in the run history.
+func recordRun(ctx context.Context, script string, f func(context.Context) error) error {
+	ctx, runHistory := bass.TrackHistory(ctx)
+
+	category := runCategory
+	if category == "" {
+		category = strings.TrimSuffix(filepath.Base(script), bass.Ext)
+	}
+
+	record := bass.RunRecord{
+		Category: category,
+		Script:   script,
+		Started:  time.Now(),
+	}
+
+	if abs, err := filepath.Abs(script); err == nil {
+		record.Script = abs
+	}
+
+	if content, err := os.ReadFile(script); err == nil {
+		record.ScriptDigest = fmt.Sprintf("%x", sha256.Sum256(content))
+	}
+
+	err := f(ctx)
+
+	record.Duration = time.Since(record.Started)
+	record.Thunks = runHistory.Thunks()
+	record.Approvals = runHistory.Approvals()
+	record.Flakes = runHistory.Flakes()
+
+	if err != nil {
+		record.Status = bass.RunFailed
+		record.Error = err.Error()
+	} else {
+		record.Status = bass.RunSucceeded
+	}
+
+	if herr := bass.AppendHistory(record); herr != nil {
+		zapctx.FromContext(ctx).Warn("failed to record run history", zap.Error(herr))
+	}
+
+	return err
+}
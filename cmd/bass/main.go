@@ -7,8 +7,11 @@ import (
 	"net/http"
 	_ "net/http/pprof"
 	"os"
+	"os/signal"
 	"runtime/pprof"
 	"strings"
+	"syscall"
+	"time"
 
 	flag "github.com/spf13/pflag"
 	"github.com/vito/bass/pkg/bass"
@@ -24,12 +27,45 @@ var flags = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
 var cmdline = strings.Join(os.Args, " ")
 
 var inputs []string
+var vars []string
+var outputFormat string
 
 var runRun bool
 var runExport bool
+var runVerify bool
+var runExplain bool
+var runDeps bool
+var bundlePath string
+var binPath string
 var runBump bool
+var rewritePattern string
+var rewriteReplacement string
+var shellTarget string
 var runPrune bool
 var runnerAddr string
+var runDaemon bool
+var queueClass string
+var showJobs bool
+var showHistory bool
+var showFlakes bool
+var runCategory string
+var resumeRun bool
+var keepWorkspace bool
+var resolveTTL time.Duration
+var drainTimeout time.Duration
+var proxyURL string
+var noProxy string
+var caCerts []string
+var summaryPath string
+var eventsFD int
+var eventsPath string
+var approveGate string
+var approver string
+
+var assumeYes bool
+var checkContracts bool
+var unusedMounts string
+var traceAccess bool
 
 var runLSP bool
 var lspLogs string
@@ -37,6 +73,8 @@ var lspLogs string
 var profPort int
 var profFilePath string
 
+var completionShell string
+
 var showHelp bool
 var showVersion bool
 var showDebug bool
@@ -45,15 +83,51 @@ func init() {
 	flags.SetOutput(os.Stdout)
 	flags.SortFlags = false
 
+	// pass flags following the script path along to the script
+	flags.SetInterspersed(false)
+
 	flags.StringSliceVarP(&inputs, "input", "i", nil, "inputs to encode as JSON on *stdin*, name=value; value may be a path")
+	flags.StringArrayVar(&vars, "var", nil, "set a variable in *vars*, name=value, parsing the value as JSON if possible; overrides bass.vars.yaml and bass.vars.json files")
+	flags.StringVarP(&outputFormat, "out", "o", string(cli.OutputJSON), "format of values emitted to *stdout*: json, yaml, or pretty; with --deps, json or dot")
 
 	flags.BoolVarP(&runExport, "export", "e", false, "write a thunk path to stdout as a tar stream, or log the tar contents if stdout is a tty")
 	flags.BoolVar(&runRun, "run", false, "run a thunk read from stdin in JSON format")
+	flags.BoolVar(&runVerify, "verify", false, "run a thunk read from stdin in JSON format twice, bypassing the cache, and report any differing output files")
+	flags.BoolVar(&runExplain, "explain", false, "explain why a thunk read from stdin in JSON format would miss cache")
+	flags.BoolVar(&runDeps, "deps", false, "load a script without running its main and print the modules, remote scripts, and pinned image digests it depends on")
+	flags.StringVar(&bundlePath, "bundle", "", "package a script with the modules it loads and their bass.lock files into a bundle at this path, which can be run like a script")
+	flags.StringVar(&binPath, "build-bin", "", "build a standalone executable at this path which runs a script without needing bass installed")
 	flags.BoolVarP(&runBump, "bump", "b", false, "re-generate all calls in bass.lock files")
+	flags.StringVar(&rewritePattern, "rewrite", "", "rewrite forms matching this pattern in the scripts and directories given as arguments; ?name matches any form")
+	flags.StringVar(&rewriteReplacement, "with", "", "replacement for forms matched by --rewrite, which may refer to its ?name variables")
+	flags.StringVar(&shellTarget, "shell", "", "run an interactive command (default sh) in an image, or in a thunk selected from a script as script.bass:form")
 
-	flags.BoolVarP(&runPrune, "prune", "p", false, "release data and caches retained by runtimes")
+	flags.BoolVarP(&runPrune, "prune", "p", false, "release data and caches retained by runtimes, including those left behind by runs which never finished, and clear the cache in ~/.cache/bass")
 
 	flags.StringVarP(&runnerAddr, "runner", "r", "", "serve locally configured runtimes over SSH")
+	flags.BoolVar(&runDaemon, "daemon", false, "serve locally configured runtimes to other bass commands, which use them instead of initializing their own; each command still evaluates its own scripts and shows its own progress")
+	flags.StringVar(&queueClass, "class", "", "queue class of the runs submitted to the daemon, limited by the daemon's config")
+	flags.BoolVar(&showJobs, "ps", false, "list the running and queued runs in the daemon")
+	flags.BoolVar(&resumeRun, "resume", false, "skip thunks already completed by a previous failed run of the same script and args")
+	flags.BoolVar(&keepWorkspace, "keep-workspace", false, "keep the directory returned by (workspace) after the run instead of removing it")
+	flags.DurationVar(&resolveTTL, "resolve-ttl", 0, "save image tag resolutions to the script's bass.lock and reuse them for this long")
+	flags.BoolVar(&showHistory, "history", false, "list recorded runs, most recent first, optionally limited to the category given as an argument")
+	flags.BoolVar(&showFlakes, "flakes", false, "list thunks run with (with-retries) by how often they only passed on retry, optionally limited to the category given as an argument")
+	flags.StringVar(&summaryPath, "summary", "", "write a Markdown summary of the run to this path, e.g. $GITHUB_STEP_SUMMARY")
+	flags.IntVar(&eventsFD, "events-fd", 0, "write progress as newline-delimited JSON events to this file descriptor")
+	flags.StringVar(&eventsPath, "events-file", "", "write progress as newline-delimited JSON events to this path")
+	flags.StringVar(&approveGate, "approve", "", "approve the named (gate), releasing the runs waiting on it")
+	flags.StringVar(&approver, "approver", "", "who to record as approving the --approve gate; defaults to the current user")
+	flags.StringVar(&runCategory, "category", "", "category under which to record the run in the history; defaults to the script name")
+	flags.DurationVar(&drainTimeout, "drain-timeout", runtimes.DefaultDrainTimeout, "on shutdown of --runner or --daemon, wait this long for in-flight runs to finish before canceling them")
+	flags.StringVar(&proxyURL, "proxy", "", "proxy for outbound HTTP(S) and SSH connections; overrides $HTTP_PROXY, $HTTPS_PROXY, and the config")
+	flags.StringVar(&noProxy, "no-proxy", "", "comma-separated hosts, domains, and CIDRs to connect to without the proxy; overrides $NO_PROXY and the config")
+	flags.StringSliceVar(&caCerts, "ca-cert", nil, "path to a PEM-encoded CA certificate to trust for outbound connections, in addition to the system's")
+
+	flags.BoolVarP(&assumeYes, "yes", "y", false, "answer yes to all (confirm) prompts, except (gate)s")
+	flags.BoolVar(&checkContracts, "contracts", false, "check the (pre) and (post) contracts of functions")
+	flags.StringVar(&unusedMounts, "unused-mounts", "", "warn or error when a thunk never accesses one of its mounts; resets access times in each mount before running, so it is slower for large mounts")
+	flags.BoolVar(&traceAccess, "trace-access", false, "report which files thunks read from each host dir, with a suggested .bassignore to exclude the rest; slower for large mounts, like --unused-mounts")
 
 	flags.BoolVar(&runLSP, "lsp", false, "run the bass language server")
 	flags.StringVar(&lspLogs, "lsp-log-file", "", "write language server logs to this file")
@@ -61,6 +135,8 @@ func init() {
 	flags.IntVar(&profPort, "profile", 0, "port number to bind for Go HTTP profiling")
 	flags.StringVar(&profFilePath, "cpu-profile", "", "take a CPU profile and save it to this path")
 
+	flags.StringVar(&completionShell, "completion", "", "print a completion script for the given shell (bash, zsh, or fish)")
+
 	flags.BoolVarP(&showVersion, "version", "v", false, "print the version number and exit")
 	flags.BoolVarP(&showHelp, "help", "h", false, "show bass usage and exit")
 
@@ -80,6 +156,16 @@ func main() {
 	ctx = bass.WithTrace(ctx, &bass.Trace{})
 	ctx = ioctx.StderrToContext(ctx, os.Stderr)
 
+	if bundle, ok := embeddedBundle(); ok {
+		ctx = zapctx.ToContext(ctx, bass.StdLogger(logLevel()))
+
+		if err := runBin(ctx, bundle); err != nil {
+			os.Exit(1)
+		}
+
+		return
+	}
+
 	err := flags.Parse(os.Args[1:])
 	if err != nil {
 		cli.WriteError(ctx, bass.FlagError{
@@ -92,12 +178,96 @@ func main() {
 
 	ctx = zapctx.ToContext(ctx, bass.StdLogger(logLevel()))
 
+	cacheStats, err := bass.LoadCacheStats()
+	if err != nil {
+		zapctx.FromContext(ctx).Warn("failed to load cache durations", zap.Error(err))
+		cacheStats = bass.NewCacheStats()
+	}
+
+	ctx = bass.WithCacheStats(ctx, cacheStats)
+	ctx = cli.TeeProgress(ctx, cli.CacheStatsWriter(cacheStats))
+
+	var summary *cli.Progress
+	if summaryPath != "" {
+		summary = cli.NewProgress()
+		ctx = cli.TeeProgress(ctx, summary)
+	}
+
+	events, closeEvents, err := openEvents()
+	if err != nil {
+		cli.WriteError(ctx, err)
+		os.Exit(1)
+		return
+	}
+
+	if events != nil {
+		ctx = cli.TeeProgress(ctx, events)
+		events.RunStarted(os.Args[1:])
+	}
+
 	err = root(ctx)
+
+	if serr := cacheStats.Save(); serr != nil {
+		zapctx.FromContext(ctx).Warn("failed to save cache durations", zap.Error(serr))
+	}
+
+	if events != nil {
+		events.CacheStats(cacheStats.Report())
+		events.RunFinished(err)
+		closeEvents()
+	}
+
+	if summary != nil {
+		if err := writeSummary(summary, cacheStats.Report(), err); err != nil {
+			cli.WriteError(ctx, err)
+		}
+	}
+
 	if err != nil {
 		os.Exit(1)
 	}
 }
 
+// writeSummary appends the summary to --summary, since CI systems like
+// GitHub Actions may share the file between steps.
+func writeSummary(summary *cli.Progress, cache bass.CacheReport, runErr error) error {
+	file, err := os.OpenFile(summaryPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("write summary: %w", err)
+	}
+
+	defer file.Close()
+
+	if err := summary.WriteMarkdown(file, runErr); err != nil {
+		return err
+	}
+
+	return cli.WriteCacheStatsMarkdown(file, cache)
+}
+
+// openEvents opens the event stream configured by --events-fd or
+// --events-file, if any.
+func openEvents() (*cli.EventWriter, func() error, error) {
+	var file *os.File
+	switch {
+	case eventsPath != "":
+		var err error
+		file, err = os.Create(eventsPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open events: %w", err)
+		}
+	case eventsFD != 0:
+		file = os.NewFile(uintptr(eventsFD), "events")
+		if file == nil {
+			return nil, nil, fmt.Errorf("open events: invalid file descriptor: %d", eventsFD)
+		}
+	default:
+		return nil, nil, nil
+	}
+
+	return cli.NewEventWriter(file), file.Close, nil
+}
+
 var DefaultConfig = bass.Config{
 	Runtimes: []bass.RuntimeConfig{
 		{
@@ -118,6 +288,30 @@ func root(ctx context.Context) error {
 		return nil
 	}
 
+	if completionShell != "" {
+		return completion(ctx)
+	}
+
+	if showJobs {
+		return ps(ctx)
+	}
+
+	if showHistory {
+		return history(ctx)
+	}
+
+	if showFlakes {
+		return flakes(ctx)
+	}
+
+	if approveGate != "" {
+		return approve(ctx)
+	}
+
+	if rewritePattern != "" {
+		return rewrite(ctx)
+	}
+
 	if profPort != 0 {
 		zapctx.FromContext(ctx).Sugar().Debugf("serving pprof on :%d", profPort)
 
@@ -143,32 +337,46 @@ func root(ctx context.Context) error {
 		defer pprof.StopCPUProfile()
 	}
 
-	config, err := bass.LoadConfig(DefaultConfig)
-	if err != nil {
-		cli.WriteError(ctx, err)
-		return err
-	}
+	started := time.Now()
 
-	pool, err := runtimes.NewPool(ctx, config)
+	ctx, pool, finish, err := initRuntimes(ctx)
 	if err != nil {
 		cli.WriteError(ctx, err)
 		return err
 	}
 
-	ctx = bass.WithRuntimePool(ctx, pool)
+	defer finish()
+
+	if stats, ok := bass.CacheStatsFromContext(ctx); ok {
+		defer func() {
+			if err := stats.CountReused(ctx, pool, started); err != nil {
+				zapctx.FromContext(ctx).Warn("failed to count reused cache", zap.Error(err))
+			}
+		}()
+	}
 
 	if runnerAddr != "" {
+		// stop accepting work on SIGTERM and drain in-flight runs
+		ctx, stop := signal.NotifyContext(ctx, syscall.SIGTERM)
+		defer stop()
+
 		client, err := runnerDial(ctx, runnerAddr)
 		if err != nil {
 			cli.WriteError(ctx, err)
 			return err
 		}
 
+		client.DrainTimeout = drainTimeout
+
 		return cli.WithProgress(ctx, func(ctx context.Context) error {
 			return runnerLoop(ctx, client, pool.Runtimes)
 		})
 	}
 
+	if runDaemon {
+		return daemon(ctx, pool)
+	}
+
 	if runExport {
 		return cli.WithProgress(ctx, export)
 	}
@@ -189,6 +397,30 @@ func root(ctx context.Context) error {
 		return cli.WithProgress(ctx, runThunk)
 	}
 
+	if runVerify {
+		return cli.WithProgress(ctx, verify)
+	}
+
+	if runExplain {
+		return explain(ctx)
+	}
+
+	if runDeps {
+		return cli.WithProgress(ctx, deps)
+	}
+
+	if bundlePath != "" {
+		return cli.WithProgress(ctx, bundle)
+	}
+
+	if binPath != "" {
+		return cli.WithProgress(ctx, buildBin)
+	}
+
+	if shellTarget != "" {
+		return shell(ctx)
+	}
+
 	if flags.NArg() == 0 {
 		return repl(ctx)
 	}
@@ -196,6 +428,130 @@ func root(ctx context.Context) error {
 	return cli.WithProgress(ctx, run)
 }
 
+// initRuntimes configures the runtime pool and other dependencies of
+// evaluation.
+//
+// When the runtimes are initialized locally rather than served by a daemon,
+// the run is registered so that the resources it leaves behind can be pruned
+// if it never finishes, and resources left behind by earlier runs are pruned.
+// The returned function marks the run as finished.
+func initRuntimes(ctx context.Context) (context.Context, *runtimes.Pool, func(), error) {
+	finish := func() {}
+
+	config, err := bass.LoadConfig(DefaultConfig)
+	if err != nil {
+		return ctx, nil, finish, err
+	}
+
+	// flags take precedence over the config, which takes precedence over the
+	// environment
+	config.Network = bass.NetworkConfigFromEnv().Merge(config.Network).Merge(bass.NetworkConfig{
+		HTTPProxy:  proxyURL,
+		HTTPSProxy: proxyURL,
+		NoProxy:    noProxy,
+		CACerts:    caCerts,
+	})
+
+	ctx = bass.WithNetworkConfig(ctx, config.Network)
+
+	if helper, found := bass.LookupCredentialHelper(config.CredentialHelper); found {
+		ctx = bass.WithCredentialHelper(ctx, helper)
+	}
+
+	pool, found, err := daemonPool(ctx)
+	if err != nil {
+		return ctx, nil, finish, err
+	}
+
+	if found {
+		zapctx.FromContext(ctx).Info("using runtimes from running daemon; local runtime config is ignored",
+			zap.String("socket", runtimes.DaemonControlSocket()),
+			zap.Any("platforms", pool.Platforms()))
+
+		ctx = bass.WithLocker(ctx, runtimes.DaemonLocker{})
+	} else {
+		ctx, finish = startRun(ctx)
+
+		pool, err = runtimes.NewPool(ctx, config)
+		if err != nil {
+			finish()
+			return ctx, nil, func() {}, err
+		}
+
+		if err := runtimes.PruneOrphans(ctx, pool); err != nil {
+			zapctx.FromContext(ctx).Warn("failed to prune orphaned runs", zap.Error(err))
+		}
+	}
+
+	ctx = bass.WithRuntimePool(ctx, pool)
+
+	store, err := runtimes.OpenOCIStore(runtimes.DefaultOCIStoreDir())
+	if err != nil {
+		finish()
+		return ctx, nil, func() {}, err
+	}
+
+	ctx = bass.WithScriptFetcher(ctx, runtimes.ScriptFetcher{Store: store})
+
+	if queueClass != "" {
+		ctx = runtimes.WithQueueClass(ctx, queueClass)
+	}
+
+	var prompter bass.Prompter = &bass.TTY{}
+	if assumeYes {
+		prompter = bass.AssumeYes{Prompter: prompter}
+	}
+
+	ctx = bass.WithPrompter(ctx, prompter)
+	ctx = bass.WithTerminal(ctx, &cli.Terminal{})
+
+	if checkContracts {
+		ctx = bass.WithContracts(ctx)
+	}
+
+	if unusedMounts != "" {
+		check, err := bass.ParseMountCheck(unusedMounts)
+		if err != nil {
+			finish()
+			return ctx, nil, func() {}, err
+		}
+
+		ctx = bass.WithMountCheck(ctx, check)
+	}
+
+	if traceAccess {
+		trace := bass.NewAccessTrace()
+		ctx = bass.WithAccessTrace(ctx, trace)
+
+		finishRun := finish
+		finish = func() {
+			trace.Report(os.Stderr)
+			finishRun()
+		}
+	}
+
+	return ctx, pool, finish, nil
+}
+
+// startRun registers the run in bass.RunsDir, returning a context carrying its
+// ID and a function which marks it as finished.
+//
+// Failing to register is not fatal; the run's resources just won't be pruned
+// if it never finishes.
+func startRun(ctx context.Context) (context.Context, func()) {
+	run, err := bass.StartRun()
+	if err != nil {
+		zapctx.FromContext(ctx).Warn("failed to register run", zap.Error(err))
+		return ctx, func() {}
+	}
+
+	return bass.WithRunID(ctx, run.ID), func() {
+		if err := run.Finish(); err != nil {
+			zapctx.FromContext(ctx).Warn("failed to finish run", zap.Error(err))
+		}
+	}
+}
+
 func repl(ctx context.Context) error {
 	scope := bass.NewRunScope(bass.Ground, bass.RunState{
 		Dir:    bass.NewHostDir("."),
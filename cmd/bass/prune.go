@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/tonistiigi/units"
 	"github.com/vito/bass/pkg/bass"
 	"github.com/vito/bass/pkg/cli"
 	"github.com/vito/progrock"
@@ -28,6 +29,13 @@ func prune(ctx context.Context) error {
 			}
 		}
 
+		freed, err := bass.PruneCache(bass.PruneOpts{})
+		if err != nil {
+			return fmt.Errorf("prune cache: %w", err)
+		}
+
+		fmt.Fprintf(vertex.Stdout(), "pruned %s\tsize: %.2f\n", bass.CacheHome, units.Bytes(freed))
+
 		return nil
 	})
 }
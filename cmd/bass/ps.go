@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/vito/bass/pkg/cli"
+	"github.com/vito/bass/pkg/runtimes"
+)
+
+func ps(ctx context.Context) error {
+	jobs, err := runtimes.DaemonJobs(ctx)
+	if err != nil {
+		cli.WriteError(ctx, err)
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tCLASS\tSTATE\tSINCE\tCOMMAND")
+
+	for _, job := range jobs {
+		state := "queued"
+		if job.Running {
+			state = "running"
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n",
+			job.ID,
+			job.Class,
+			state,
+			time.Since(job.Since).Truncate(time.Second),
+			job.Description)
+	}
+
+	return w.Flush()
+}
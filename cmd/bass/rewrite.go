@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/cli"
+	"github.com/vito/bass/pkg/zapctx"
+	"go.uber.org/zap"
+)
+
+// rewrite replaces each form matching the --rewrite pattern with the --with
+// replacement in the scripts given as arguments, descending into directories.
+func rewrite(ctx context.Context) error {
+	rw, err := bass.ParseRewrite(rewritePattern, rewriteReplacement)
+	if err != nil {
+		cli.WriteError(ctx, err)
+		return err
+	}
+
+	logger := zapctx.FromContext(ctx)
+
+	for _, arg := range flags.Args() {
+		err := filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() || (path != arg && filepath.Ext(path) != bass.Ext) {
+				return nil
+			}
+
+			changed, err := rewriteFile(rw, path)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+
+			if changed > 0 {
+				logger.Info("rewrote", zap.String("path", path), zap.Int("forms", changed))
+			}
+
+			return nil
+		})
+		if err != nil {
+			cli.WriteError(ctx, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func rewriteFile(rw bass.Rewrite, path string) (int, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	syntax, err := bass.ParseSyntax(string(src), bass.ParseHostPath(path))
+	if err != nil {
+		return 0, err
+	}
+
+	changed := rw.RewriteSyntax(syntax)
+	if changed == 0 {
+		return 0, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+
+	return changed, os.WriteFile(path, []byte(syntax.String()), info.Mode())
+}
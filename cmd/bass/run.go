@@ -2,12 +2,19 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/mattn/go-isatty"
 	"github.com/vito/bass/pkg/bass"
 	"github.com/vito/bass/pkg/cli"
+	"github.com/vito/bass/pkg/zapctx"
 	"github.com/vito/progrock"
+	"go.uber.org/zap"
 )
 
 func run(ctx context.Context) error {
@@ -19,9 +26,62 @@ func run(ctx context.Context) error {
 			stdout = bass.NewSink(bass.NewJSONSink("stdout vertex", vtx.Stdout()))
 		}
 
+		if cli.OutputFormat(outputFormat) != cli.OutputJSON {
+			name, w := "stdout", io.Writer(os.Stdout)
+			if isTty {
+				name, w = "stdout vertex", vtx.Stdout()
+			}
+
+			var err error
+			stdout, err = cli.NewOutputSink(name, cli.OutputFormat(outputFormat), w)
+			if err != nil {
+				return err
+			}
+		}
+
+		ws := bass.NewWorkspace(keepWorkspace)
+		ctx = bass.WithWorkspace(ctx, ws)
+		defer cleanupWorkspace(ctx, ws)
+
 		argv := flags.Args()
 
-		err := cli.Run(ctx, bass.ImportSystemEnv(), inputs, argv[0], argv[1:], stdout)
+		script := argv[0]
+		if bass.IsRemoteScript(script) {
+			remote, err := bass.ParseRemoteScript(script)
+			if err != nil {
+				return err
+			}
+
+			// pin the image in the current directory, since the script is not
+			// on the host yet
+			script, err = bass.FetchRemoteScript(ctx, remote, bass.NewLockfileMemo("bass.lock"))
+			if err != nil {
+				return err
+			}
+		} else if cli.IsBundle(script) {
+			var err error
+			script, err = cli.OpenBundle(script)
+			if err != nil {
+				return err
+			}
+		}
+
+		resolveCache := bass.NewResolveCache(resolveTTL)
+		if resolveTTL > 0 {
+			resolveCache.Memos = bass.NewLockfileMemo(filepath.Join(filepath.Dir(script), "bass.lock"))
+		}
+
+		ctx = bass.WithResolveCache(ctx, resolveCache)
+
+		err := recordRun(ctx, script, func(ctx context.Context) error {
+			if !resumeRun {
+				return cli.Run(ctx, bass.ImportSystemEnv(), inputs, vars, script, argv[1:], stdout)
+			}
+
+			return checkpointRun(ctx, script, argv[1:], func(ctx context.Context) error {
+				return cli.Run(ctx, bass.ImportSystemEnv(), inputs, vars, script, argv[1:], stdout)
+			})
+		})
 
 		if !isTty {
 			// ensure a chained unix pipeline exits
@@ -31,3 +91,48 @@ func run(ctx context.Context) error {
 		return err
 	})
 }
+
+// cleanupWorkspace removes the run's workspace, or logs its path if it is
+// kept.
+func cleanupWorkspace(ctx context.Context, ws *bass.Workspace) {
+	logger := zapctx.FromContext(ctx)
+
+	if dir := ws.Created(); dir != "" && ws.Keep {
+		logger.Info("kept workspace", zap.String("dir", dir))
+		return
+	}
+
+	if err := ws.Cleanup(); err != nil {
+		logger.Warn("failed to clean up workspace", zap.Error(err))
+	}
+}
+
+// checkpointRun calls f with a checkpoint for the script and its args, so
+// that thunks completed by a previous failed run are skipped. The checkpoint
+// is removed once the run succeeds.
+func checkpointRun(ctx context.Context, script string, args []string, f func(context.Context) error) error {
+	logger := zapctx.FromContext(ctx)
+
+	abs, err := filepath.Abs(script)
+	if err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(append([]string{abs}, args...), "\x00"))))
+
+	cp, err := bass.LoadCheckpoint(key)
+	if err != nil {
+		return err
+	}
+
+	if n := cp.Len(); n > 0 {
+		logger.Info("resuming run", zap.Int("completed", n))
+	}
+
+	err = f(bass.WithCheckpoint(ctx, cp))
+	if err != nil {
+		return err
+	}
+
+	return cp.Remove()
+}
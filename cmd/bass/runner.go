@@ -12,6 +12,7 @@ import (
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/morikuni/aec"
+	"github.com/vito/bass/pkg/bass"
 	"github.com/vito/bass/pkg/cli"
 	"github.com/vito/bass/pkg/runtimes"
 	"github.com/vito/bass/pkg/zapctx"
@@ -190,9 +191,12 @@ func runnerDial(ctx context.Context, sshAddr string) (*runtimes.SSHClient, error
 		clientConfig.Auth = append(clientConfig.Auth, ssh.PublicKeys(pks...))
 	}
 
+	network, _ := bass.NetworkConfigFromContext(ctx)
+
 	client := &runtimes.SSHClient{
-		Hosts: []string{net.JoinHostPort(host, port)},
-		User:  login,
+		Hosts:   []string{net.JoinHostPort(host, port)},
+		User:    login,
+		Network: network,
 	}
 
 	if err := client.Dial(ctx, clientConfig); err != nil {
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/cli"
+	"golang.org/x/term"
+)
+
+// defaultShell is run by --shell when no command is given.
+const defaultShell = "sh"
+
+// shell runs an interactive command in the image or thunk given to --shell,
+// attached to the terminal.
+//
+// The target is either an image reference, e.g. alpine:3.16, or a script and
+// a selector form evaluated in its module, e.g. ci/build.bass:go-build, in
+// which case the command inherits the thunk's image, env, dir, and mounts.
+func shell(ctx context.Context) error {
+	thunk, err := shellThunk(ctx, shellTarget)
+	if err != nil {
+		cli.WriteError(ctx, err)
+		return err
+	}
+
+	argv := flags.Args()
+	if len(argv) == 0 {
+		argv = []string{defaultShell}
+	}
+
+	args := make([]bass.Value, len(argv)-1)
+	for i, arg := range argv[1:] {
+		args[i] = bass.String(arg)
+	}
+
+	thunk = thunk.
+		WithCmd(bass.ThunkCmd{Cmd: &bass.CommandPath{Command: argv[0]}}).
+		WithArgs(args).
+		WithStdin(nil)
+
+	tio := bass.InteractiveIO{
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	}
+
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		tio.TTY = true
+
+		restore, err := cli.AttachTerminal(os.Stdin)
+		if err != nil {
+			cli.WriteError(ctx, err)
+			return err
+		}
+
+		defer restore()
+
+		sizes, stop := cli.WatchWindowSize(os.Stdin)
+		defer stop()
+
+		tio.Resize = sizes
+	}
+
+	err = thunk.Interact(ctx, tio)
+	if err != nil {
+		cli.WriteError(ctx, err)
+		return err
+	}
+
+	return nil
+}
+
+// shellThunk returns the thunk to base the shell on.
+func shellThunk(ctx context.Context, target string) (bass.Thunk, error) {
+	if i := strings.Index(target, bass.Ext+":"); i != -1 {
+		script := target[:i+len(bass.Ext)]
+		selector := target[i+len(bass.Ext)+1:]
+		return selectThunk(ctx, script, selector)
+	}
+
+	named, err := reference.ParseNormalizedNamed(target)
+	if err != nil {
+		return bass.Thunk{}, fmt.Errorf("parse image reference: %w", err)
+	}
+
+	ref := bass.ImageRef{
+		Repository: bass.ImageRepository{
+			Static: reference.FamiliarName(named),
+		},
+		Platform: bass.LinuxPlatform,
+	}
+
+	if tagged, ok := named.(reference.Tagged); ok {
+		ref.Tag = tagged.Tag()
+	}
+
+	if digested, ok := named.(reference.Digested); ok {
+		ref.Digest = digested.Digest().String()
+	}
+
+	return bass.Thunk{}.WithImage(bass.ThunkImage{Ref: &ref}), nil
+}
+
+// selectThunk loads the script as a module and evaluates the selector form
+// in it, which must return a thunk.
+func selectThunk(ctx context.Context, script, selector string) (bass.Thunk, error) {
+	dir, base := filepath.Split(script)
+
+	cmd := bass.NewHostPath(
+		dir,
+		bass.ParseFileOrDirPath(filepath.ToSlash(base)),
+	)
+
+	module, err := bass.NewBass().Load(ctx, bass.Thunk{
+		Cmd: bass.ThunkCmd{Host: &cmd},
+		Env: bass.ImportSystemEnv(),
+	})
+	if err != nil {
+		return bass.Thunk{}, err
+	}
+
+	val, err := bass.EvalString(ctx, module, selector, bass.NewInMemoryFile("selector", selector))
+	if err != nil {
+		return bass.Thunk{}, err
+	}
+
+	var thunk bass.Thunk
+	if err := val.Decode(&thunk); err != nil {
+		return bass.Thunk{}, fmt.Errorf("selector %q returned %s, not a thunk", selector, val)
+	}
+
+	return thunk, nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/cli"
+	"github.com/vito/progrock"
+)
+
+func verify(ctx context.Context) error {
+	return cli.Task(ctx, cmdline, func(ctx context.Context, vtx *progrock.VertexRecorder) error {
+		dec := bass.NewRawDecoder(os.Stdin)
+
+		var thunk bass.Thunk
+		if err := dec.Decode(&thunk); err != nil {
+			return err
+		}
+
+		changes, err := thunk.Verify(ctx)
+		if err != nil {
+			return err
+		}
+
+		if len(changes) == 0 {
+			fmt.Fprintf(vtx.Stdout(), "%s is reproducible\n", thunk)
+			return nil
+		}
+
+		for _, change := range changes {
+			fmt.Fprintf(vtx.Stdout(), "%s %s: %s\n", change.Change, change.Path, change.Cause())
+		}
+
+		return fmt.Errorf("%s is not reproducible: %d files differ", thunk, len(changes))
+	})
+}
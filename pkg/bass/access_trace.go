@@ -0,0 +1,157 @@
+package bass
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"sync"
+)
+
+// AccessTrace collects which files thunks read from the host paths mounted
+// into them, merged across every thunk using the same context dir.
+//
+// Like MountCheck, tracing is only supported by some runtimes. Runtimes record
+// the files in each host path mount along with whether they were read, and
+// the trace suggests a .bassignore for each context dir which excludes the
+// files no thunk read, so that changes to them no longer bust the cache.
+type AccessTrace struct {
+	contexts   map[string]map[string]bool
+	incomplete map[string]bool
+	mu         sync.Mutex
+}
+
+// NewAccessTrace returns an empty trace.
+func NewAccessTrace() *AccessTrace {
+	return &AccessTrace{
+		contexts:   map[string]map[string]bool{},
+		incomplete: map[string]bool{},
+	}
+}
+
+type accessTraceKey struct{}
+
+// WithAccessTrace enables tracing which files thunks read from host paths
+// within the returned context, recording them in the given trace.
+func WithAccessTrace(ctx context.Context, trace *AccessTrace) context.Context {
+	return context.WithValue(ctx, accessTraceKey{}, trace)
+}
+
+// AccessTraceFromContext returns the trace set by WithAccessTrace, if any.
+func AccessTraceFromContext(ctx context.Context) (*AccessTrace, bool) {
+	trace, ok := ctx.Value(accessTraceKey{}).(*AccessTrace)
+	return trace, ok
+}
+
+// Record merges the files a thunk had mounted from the context dir, mapping
+// each slash-separated path relative to the context dir to whether the thunk
+// read it.
+func (trace *AccessTrace) Record(contextDir string, files map[string]bool) {
+	trace.mu.Lock()
+	defer trace.mu.Unlock()
+
+	seen, found := trace.contexts[contextDir]
+	if !found {
+		seen = map[string]bool{}
+		trace.contexts[contextDir] = seen
+	}
+
+	for file, read := range files {
+		seen[file] = seen[file] || read
+	}
+}
+
+// Incomplete marks a context dir as used by a thunk whose reads could not be
+// traced, e.g. because it was cached before tracing was enabled.
+//
+// No .bassignore is suggested for an incomplete context dir, since it might
+// exclude files that the untraced thunk needs.
+func (trace *AccessTrace) Incomplete(contextDir string) {
+	trace.mu.Lock()
+	defer trace.mu.Unlock()
+
+	trace.incomplete[contextDir] = true
+}
+
+// Report writes a summary of the files read from each context dir along
+// with a suggested .bassignore which excludes everything else.
+//
+// Directories whose files were all read are included as a whole, keeping the
+// suggestion short and letting new files in them through.
+func (trace *AccessTrace) Report(w io.Writer) {
+	trace.mu.Lock()
+	defer trace.mu.Unlock()
+
+	var dirs []string
+	for dir := range trace.contexts {
+		dirs = append(dirs, dir)
+	}
+
+	for dir := range trace.incomplete {
+		if _, found := trace.contexts[dir]; !found {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	sort.Strings(dirs)
+
+	for _, dir := range dirs {
+		if trace.incomplete[dir] {
+			fmt.Fprintf(w, "bass: context %s: some thunks using it were not traced, so no .bassignore is suggested\n", dir)
+			continue
+		}
+
+		files := trace.contexts[dir]
+
+		var paths []string
+		total := map[string]int{}
+		reads := map[string]int{}
+		for file, read := range files {
+			paths = append(paths, file)
+
+			for parent := path.Dir(file); ; parent = path.Dir(parent) {
+				total[parent]++
+				if read {
+					reads[parent]++
+				}
+
+				if parent == "." {
+					break
+				}
+			}
+		}
+
+		sort.Strings(paths)
+
+		if reads["."] == total["."] {
+			fmt.Fprintf(w, "bass: context %s: all %d files read\n", dir, total["."])
+			continue
+		}
+
+		fmt.Fprintf(w, "bass: context %s: read %d of %d files; suggested .bassignore:\n", dir, reads["."], total["."])
+		fmt.Fprintln(w, "  *")
+
+		included := map[string]bool{}
+		for _, file := range paths {
+			if !files[file] {
+				continue
+			}
+
+			include := file
+			for parent := path.Dir(file); parent != "."; parent = path.Dir(parent) {
+				if reads[parent] == total[parent] {
+					include = parent
+				}
+			}
+
+			if included[include] {
+				continue
+			}
+
+			included[include] = true
+
+			fmt.Fprintf(w, "  !%s\n", include)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package bass
+
+import (
+	"context"
+	"math/big"
+)
+
+// BigInt is an arbitrary-precision integer, used for integers which do not
+// fit in an Int.
+//
+// Arithmetic on Ints which would overflow results in a BigInt, and arithmetic
+// on BigInts which results in a small enough integer results in an Int, so
+// that an integer only ever has one representation.
+type BigInt struct {
+	int *big.Int
+}
+
+// NewBigInt returns the integer as an Int if it fits, or a BigInt otherwise.
+//
+// The integer must not be modified afterwards.
+func NewBigInt(i *big.Int) Number {
+	if i.IsInt64() {
+		if small := i.Int64(); int64(int(small)) == small {
+			return IntValue(int(small))
+		}
+	}
+
+	return BigInt{i}
+}
+
+func (value BigInt) String() string {
+	return value.int.String()
+}
+
+func (value BigInt) Equal(other Value) bool {
+	var o BigInt
+	return other.Decode(&o) == nil && value.int.Cmp(o.int) == 0
+}
+
+func (value BigInt) Decode(dest any) error {
+	switch x := dest.(type) {
+	case *BigInt:
+		*x = value
+		return nil
+	case *Number:
+		*x = value
+		return nil
+	case *Value:
+		*x = value
+		return nil
+	case *Bindable:
+		*x = value
+		return nil
+	case **big.Int:
+		*x = new(big.Int).Set(value.int)
+		return nil
+	default:
+		return DecodeError{
+			Source:      value,
+			Destination: dest,
+		}
+	}
+}
+
+// MarshalJSON encodes the integer as a JSON number.
+func (value BigInt) MarshalJSON() ([]byte, error) {
+	return value.int.MarshalJSON()
+}
+
+// UnmarshalJSON decodes the integer from a JSON number.
+func (value *BigInt) UnmarshalJSON(payload []byte) error {
+	i := new(big.Int)
+	if err := i.UnmarshalJSON(payload); err != nil {
+		return err
+	}
+
+	value.int = i
+	return nil
+}
+
+// Eval returns the value.
+func (value BigInt) Eval(_ context.Context, _ *Scope, cont Cont) ReadyCont {
+	return cont.Call(value, nil)
+}
+
+// BigInt returns a copy of the integer.
+func (value BigInt) BigInt() *big.Int {
+	return new(big.Int).Set(value.int)
+}
+
+// Float64 returns the nearest float64 to the integer.
+func (value BigInt) Float64() float64 {
+	f, _ := new(big.Float).SetInt(value.int).Float64()
+	return f
+}
+
+var _ Bindable = BigInt{}
+
+func (binding BigInt) Bind(_ context.Context, _ *Scope, cont Cont, val Value, _ ...Annotated) ReadyCont {
+	return cont.Call(binding, BindConst(binding, val))
+}
+
+func (BigInt) EachBinding(func(Symbol, Range) error) error {
+	return nil
+}
@@ -2,9 +2,13 @@ package bass_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/rand"
 	"testing"
 
 	"github.com/vito/bass/pkg/bass"
+	. "github.com/vito/bass/pkg/basstest"
 	"github.com/vito/is"
 )
 
@@ -351,3 +355,305 @@ func TestBinding(t *testing.T) {
 		})
 	}
 }
+
+// bindCase is a randomly generated pattern along with a value it matches and
+// the bindings that result.
+type bindCase struct {
+	Bindable bass.Bindable
+	Value    bass.Value
+	Bindings bass.Bindings
+
+	// Mismatches are values which do not match the pattern, along with the
+	// sub-pattern which should be named by the error.
+	Mismatches []bindMismatch
+}
+
+type bindMismatch struct {
+	Value bass.Value
+	Need  bass.Value
+	Have  bass.Value
+}
+
+type bindGen struct {
+	rand *rand.Rand
+	syms int
+}
+
+func (gen *bindGen) symbol() bass.Symbol {
+	gen.syms++
+	return bass.Symbol(fmt.Sprintf("sym-%d", gen.syms))
+}
+
+func (gen *bindGen) gen(depth int) bindCase {
+	choice := gen.rand.Intn(7)
+	if depth <= 0 {
+		choice = gen.rand.Intn(3)
+	}
+
+	switch choice {
+	case 0:
+		sym := gen.symbol()
+		val := gen.value()
+		return bindCase{
+			Bindable: sym,
+			Value:    val,
+			Bindings: bass.Bindings{sym: val},
+		}
+	case 1:
+		return bindCase{
+			Bindable: bass.Ignore{},
+			Value:    gen.value(),
+			Bindings: bass.Bindings{},
+		}
+	case 2:
+		val := gen.constant()
+		other := bass.String(fmt.Sprintf("not %s", val))
+		return bindCase{
+			Bindable: val,
+			Value:    val,
+			Bindings: bass.Bindings{},
+			Mismatches: []bindMismatch{
+				{Value: other, Need: val, Have: other},
+			},
+		}
+	case 3, 4:
+		return gen.list(depth, choice == 4)
+	default:
+		return gen.bind(depth)
+	}
+}
+
+func (gen *bindGen) value() bass.Value {
+	switch gen.rand.Intn(5) {
+	case 0:
+		return bass.Int(gen.rand.Intn(100))
+	case 1:
+		return bass.String(fmt.Sprintf("str-%d", gen.rand.Intn(100)))
+	case 2:
+		return bass.Bool(gen.rand.Intn(2) == 0)
+	case 3:
+		return bass.Keyword(fmt.Sprintf("kw-%d", gen.rand.Intn(100)))
+	default:
+		return bass.Null{}
+	}
+}
+
+// constant returns a value which binds only to itself. Keywords are excluded;
+// they bind to the symbol of the same name.
+func (gen *bindGen) constant() bass.Bindable {
+	for {
+		val := gen.value()
+		if _, isKw := val.(bass.Keyword); !isKw {
+			return val.(bass.Bindable)
+		}
+	}
+}
+
+func (gen *bindGen) list(depth int, cons bool) bindCase {
+	children := make([]bindCase, gen.rand.Intn(4))
+	for i := range children {
+		children[i] = gen.gen(depth - 1)
+	}
+
+	var rest bass.Symbol
+	var restVals []bass.Value
+	dotted := gen.rand.Intn(3) == 0
+	if dotted {
+		rest = gen.symbol()
+		for i := gen.rand.Intn(3); i > 0; i-- {
+			restVals = append(restVals, gen.value())
+		}
+	}
+
+	newList := func(vals []bass.Value, tail bass.Value) bass.Value {
+		for i := len(vals) - 1; i >= 0; i-- {
+			if cons {
+				tail = bass.Cons{A: vals[i], D: tail}
+			} else {
+				tail = bass.Pair{A: vals[i], D: tail}
+			}
+		}
+
+		return tail
+	}
+
+	// patterns[i] is the pattern remaining from the ith child onward
+	patterns := make([]bass.Value, len(children)+1)
+	if dotted {
+		patterns[len(children)] = rest
+	} else {
+		patterns[len(children)] = bass.Empty{}
+	}
+
+	for i := len(children) - 1; i >= 0; i-- {
+		patterns[i] = newList([]bass.Value{children[i].Bindable}, patterns[i+1])
+	}
+
+	vals := make([]bass.Value, len(children))
+	bindings := bass.Bindings{}
+	for i, child := range children {
+		vals[i] = child.Value
+
+		for k, v := range child.Bindings {
+			bindings[k] = v
+		}
+	}
+
+	if dotted {
+		bindings[rest] = bass.NewList(restVals...)
+	}
+
+	value := bass.NewList(append(vals, restVals...)...)
+
+	var mismatches []bindMismatch
+	for i, child := range children {
+		for _, m := range child.Mismatches {
+			mutated := append([]bass.Value{}, vals...)
+			mutated[i] = m.Value
+			m.Value = bass.NewList(append(mutated, restVals...)...)
+			mismatches = append(mismatches, m)
+		}
+	}
+
+	if len(children) > 0 {
+		short := bass.NewList(vals[:len(vals)-1]...)
+		mismatches = append(mismatches, bindMismatch{
+			Value: short,
+			Need:  patterns[len(children)-1],
+			Have:  bass.Empty{},
+		})
+	}
+
+	if !dotted {
+		extra := bass.NewList(gen.value())
+		mismatches = append(mismatches, bindMismatch{
+			Value: bass.NewList(append(vals, extra.(bass.Pair).A)...),
+			Need:  bass.Empty{},
+			Have:  extra,
+		})
+	}
+
+	if len(children) > 0 || !dotted {
+		scalar := bass.Int(-1)
+		mismatches = append(mismatches, bindMismatch{
+			Value: scalar,
+			Need:  patterns[0],
+			Have:  scalar,
+		})
+	}
+
+	return bindCase{
+		Bindable:   patterns[0].(bass.Bindable),
+		Value:      value,
+		Bindings:   bindings,
+		Mismatches: mismatches,
+	}
+}
+
+func (gen *bindGen) bind(depth int) bindCase {
+	children := make([]bindCase, gen.rand.Intn(4))
+
+	var bind bass.Bind
+	vals := bass.Bindings{}
+	bindings := bass.Bindings{}
+	for i := range children {
+		child := gen.gen(depth - 1)
+		children[i] = child
+
+		key := bass.Symbol(fmt.Sprintf("key-%d", i))
+		bind = append(bind, key.Keyword(), child.Bindable)
+		vals[key] = child.Value
+
+		for k, v := range child.Bindings {
+			bindings[k] = v
+		}
+	}
+
+	// an extra value which should be ignored
+	vals["unused"] = gen.value()
+
+	var mismatches []bindMismatch
+	for i, child := range children {
+		for _, m := range child.Mismatches {
+			mutated := bass.Bindings{}
+			for k, v := range vals {
+				mutated[k] = v
+			}
+
+			mutated[bass.Symbol(fmt.Sprintf("key-%d", i))] = m.Value
+			m.Value = mutated.Scope()
+			mismatches = append(mismatches, m)
+		}
+	}
+
+	scalar := bass.Int(-1)
+	mismatches = append(mismatches, bindMismatch{
+		Value: scalar,
+		Need:  bind,
+		Have:  scalar,
+	})
+
+	return bindCase{
+		Bindable:   bind,
+		Value:      vals.Scope(),
+		Bindings:   bindings,
+		Mismatches: mismatches,
+	}
+}
+
+func TestBindingProperties(t *testing.T) {
+	ctx := context.Background()
+
+	for seed := int64(0); seed < 500; seed++ {
+		gen := &bindGen{rand: rand.New(rand.NewSource(seed))}
+
+		var example bindCase
+		switch seed % 3 {
+		case 0:
+			example = gen.list(4, false)
+		case 1:
+			example = gen.list(4, true)
+		default:
+			example = gen.bind(4)
+		}
+
+		t.Run(fmt.Sprintf("seed %d", seed), func(t *testing.T) {
+			is := is.New(t)
+
+			t.Logf("formals: %s", example.Bindable)
+			t.Logf("value: %s", example.Value)
+
+			scope := bass.NewEmptyScope()
+			_, err := bass.Trampoline(ctx, example.Bindable.Bind(ctx, scope, bass.Identity, example.Value))
+			is.NoErr(err)
+
+			for sym, val := range example.Bindings {
+				bound, found := scope.Get(sym)
+				is.True(found)
+				Equal(t, bound, val)
+			}
+
+			var syms int
+			err = example.Bindable.EachBinding(func(bass.Symbol, bass.Range) error {
+				syms++
+				return nil
+			})
+			is.NoErr(err)
+			is.Equal(syms, len(example.Bindings))
+
+			for _, m := range example.Mismatches {
+				scope := bass.NewEmptyScope()
+				_, err := bass.Trampoline(ctx, example.Bindable.Bind(ctx, scope, bass.Identity, m.Value))
+
+				var mismatch bass.BindMismatchError
+				if !errors.As(err, &mismatch) {
+					t.Fatalf("binding %s: expected mismatch, got %v", m.Value, err)
+				}
+
+				if !mismatch.Need.Equal(m.Need) || !mismatch.Have.Equal(m.Have) {
+					t.Fatalf("binding %s: expected need %s, have %s; got %s", m.Value, m.Need, m.Have, err)
+				}
+			}
+		})
+	}
+}
@@ -180,6 +180,12 @@ func (builtin Builtin) Call(ctx context.Context, val Value, scope *Scope, cont C
 		fargs = append(fargs, dest.Elem())
 	}
 
+	if lim := limiterFromContext(ctx); lim != nil {
+		if err := lim.alloc(); err != nil {
+			return cont.Call(nil, err)
+		}
+	}
+
 	result := builtin.Func.Call(fargs)
 
 	switch ftype.NumOut() {
@@ -0,0 +1,193 @@
+package bass
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+)
+
+// PruneCache removes the data kept in CacheHome which was not modified within
+// opts.KeepDuration, or all of it if it is zero. It returns the number of
+// bytes freed.
+//
+// This covers the thunk history, thunk paths exported by Materialize, the run
+// history, cache stats, gates, locks, applied migrations, and remote scripts.
+// Locks which are held and gates which are being waited on are left alone.
+//
+// Runs in RunsDir are not pruned here; they are forgotten once their
+// resources are released.
+func PruneCache(opts PruneOpts) (int64, error) {
+	var freed int64
+
+	for _, dir := range []string{
+		ThunkHistoryDir(),
+		thunkDirsPath(),
+		MigrationsDir(),
+		RemoteScriptsDir(),
+	} {
+		size, err := pruneDir(dir, opts, nil)
+		freed += size
+		if err != nil {
+			return freed, err
+		}
+	}
+
+	size, err := pruneDir(GatesDir(), opts, lockGateWait)
+	freed += size
+	if err != nil {
+		return freed, err
+	}
+
+	size, err = pruneDir(LocksDir(), opts, lockFile)
+	freed += size
+	if err != nil {
+		return freed, err
+	}
+
+	for _, file := range []string{
+		HistoryPath(),
+		CacheDurationsPath(),
+	} {
+		info, err := os.Stat(file)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return freed, err
+		}
+
+		if !shouldPrune(info, opts) {
+			continue
+		}
+
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return freed, err
+		}
+
+		freed += info.Size()
+	}
+
+	return freed, nil
+}
+
+// pruneDir removes each entry in the directory which should be pruned.
+//
+// If lock is non-nil, it is called to lock each entry before removing it, and
+// entries which are in use are skipped.
+func pruneDir(dir string, opts PruneOpts, lock func(string) (func(), bool)) (int64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+
+		return 0, err
+	}
+
+	var freed int64
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			// removed concurrently
+			continue
+		}
+
+		if !shouldPrune(info, opts) {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+
+		unlock := func() {}
+		if lock != nil {
+			var ok bool
+			unlock, ok = lock(path)
+			if !ok {
+				continue
+			}
+		}
+
+		size, err := removeAll(path)
+		unlock()
+		if err != nil {
+			return freed, err
+		}
+
+		freed += size
+	}
+
+	return freed, nil
+}
+
+func shouldPrune(info fs.FileInfo, opts PruneOpts) bool {
+	return opts.All || opts.KeepDuration == 0 || time.Since(info.ModTime()) >= opts.KeepDuration
+}
+
+// lockFile locks the file, returning false if it is locked elsewhere.
+func lockFile(path string) (func(), bool) {
+	lock := flock.New(path)
+
+	locked, err := lock.TryLock()
+	if err != nil || !locked {
+		return nil, false
+	}
+
+	return func() { _ = lock.Unlock() }, true
+}
+
+// lockGateWait locks the pending file of the gate waiter that the file in
+// GatesDir belongs to, returning false if the waiter is still waiting.
+func lockGateWait(path string) (func(), bool) {
+	wait := strings.TrimSuffix(path, ".tmp")
+	wait = strings.TrimSuffix(wait, filepath.Ext(wait))
+
+	pending := wait + ".pending"
+	if _, err := os.Stat(pending); err != nil {
+		// the waiter is gone; don't create the file by locking it
+		return func() {}, true
+	}
+
+	return lockFile(pending)
+}
+
+// removeAll removes the path, returning the number of bytes freed.
+func removeAll(path string) (int64, error) {
+	size, err := dirSize(path)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.RemoveAll(path); err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.Type().IsRegular() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+
+			size += info.Size()
+		}
+
+		return nil
+	})
+
+	return size, err
+}
@@ -0,0 +1,88 @@
+package bass_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/is"
+)
+
+func TestPruneCache(t *testing.T) {
+	is := is.New(t)
+
+	ctx := context.Background()
+
+	oldHome := bass.CacheHome
+	bass.CacheHome = t.TempDir()
+	defer func() { bass.CacheHome = oldHome }()
+
+	oldInterval := bass.GatePollInterval
+	bass.GatePollInterval = 10 * time.Millisecond
+	defer func() { bass.GatePollInterval = oldInterval }()
+
+	write := func(path, content string) {
+		is.NoErr(os.MkdirAll(filepath.Dir(path), 0700))
+		is.NoErr(os.WriteFile(path, []byte(content), 0600))
+	}
+
+	pruned := []string{
+		filepath.Join(bass.ThunkHistoryDir(), "abc.json"),
+		bass.HistoryPath(),
+		bass.CacheDurationsPath(),
+		bass.MigrationsPath("abc", "sha256:def"),
+		filepath.Join(bass.RemoteScriptsDir(), "abc", "main.bass"),
+		filepath.Join(bass.LocksDir(), "free.lock"),
+		filepath.Join(bass.GatesDir(), "stale.abc.pending"),
+		filepath.Join(bass.GatesDir(), "stale.abc.approved"),
+	}
+
+	for _, path := range pruned {
+		write(path, "x")
+	}
+
+	// a lock being held
+	unlock, err := bass.FileLocker{Dir: bass.LocksDir()}.Lock(ctx, "held")
+	is.NoErr(err)
+	defer unlock()
+
+	// a gate being waited on
+	waited := make(chan error, 1)
+	go func() {
+		_, err := bass.WaitForGate(ctx, "deploy")
+		waited <- err
+	}()
+
+	is.Eventually(func() bool {
+		pending, err := bass.PendingGates()
+		return err == nil && len(pending) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// recently modified, so kept
+	freed, err := bass.PruneCache(bass.PruneOpts{KeepDuration: time.Hour})
+	is.NoErr(err)
+	is.Equal(freed, int64(0))
+
+	freed, err = bass.PruneCache(bass.PruneOpts{})
+	is.NoErr(err)
+	is.Equal(freed, int64(len(pruned)))
+
+	for _, path := range pruned {
+		_, err := os.Stat(path)
+		is.True(os.IsNotExist(err))
+	}
+
+	_, err = os.Stat(filepath.Join(bass.LocksDir(), "held.lock"))
+	is.NoErr(err)
+
+	pending, err := bass.PendingGates()
+	is.NoErr(err)
+	is.Equal(len(pending), 1)
+
+	_, err = bass.ApproveGate("deploy", "alice", bass.GateViaCLI)
+	is.NoErr(err)
+	is.NoErr(<-waited)
+}
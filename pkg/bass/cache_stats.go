@@ -0,0 +1,296 @@
+package bass
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gofrs/flock"
+)
+
+// CacheDurationsPath is the file where the duration of each step that missed
+// cache is recorded, so that later cache hits can estimate the time saved.
+func CacheDurationsPath() string {
+	return filepath.Join(CacheHome, "durations.json")
+}
+
+// CacheDurationsLimit is the maximum number of step durations kept in
+// CacheDurationsPath. The least recently seen steps are forgotten first.
+var CacheDurationsLimit = 10000
+
+// CacheReuser is an optional interface implemented by runtimes which can
+// report how much of their cache was reused.
+type CacheReuser interface {
+	// ReusedBytes returns the total size of the cache records which were
+	// created before the given time and used since.
+	ReusedBytes(ctx context.Context, since time.Time) (int64, error)
+}
+
+// CacheStats counts the cache hits and misses of the steps run by runtimes
+// during a run.
+type CacheStats struct {
+	mu sync.Mutex
+
+	hits        int
+	misses      int
+	bytesReused int64
+	timeSaved   time.Duration
+
+	durations map[string]cacheDuration
+
+	// seen tracks the steps hit or missed during the run
+	seen map[string]bool
+}
+
+// cacheDuration is how long a step took when it last ran.
+type cacheDuration struct {
+	Duration time.Duration `json:"duration"`
+
+	// Seen is when the step was last hit or missed.
+	Seen time.Time `json:"seen"`
+}
+
+// CacheReport is a snapshot of CacheStats.
+type CacheReport struct {
+	// Hits is the number of steps which were cached.
+	Hits int `json:"hits"`
+
+	// Misses is the number of steps which ran.
+	Misses int `json:"misses"`
+
+	// BytesReused is the size of the cache records reused by the run, as
+	// reported by runtimes which implement CacheReuser.
+	BytesReused int64 `json:"bytes_reused"`
+
+	// TimeSaved is the sum of the durations of cached steps when they last
+	// ran, for those which have been seen running before.
+	TimeSaved time.Duration `json:"time_saved"`
+}
+
+// NewCacheStats returns empty stats with no known durations.
+func NewCacheStats() *CacheStats {
+	return &CacheStats{
+		durations: map[string]cacheDuration{},
+		seen:      map[string]bool{},
+	}
+}
+
+// LoadCacheStats returns empty stats which estimate time saved using the
+// durations saved to CacheDurationsPath by previous runs.
+func LoadCacheStats() (*CacheStats, error) {
+	stats := NewCacheStats()
+
+	durations, err := loadCacheDurations()
+	if err != nil {
+		return nil, err
+	}
+
+	stats.durations = durations
+
+	return stats, nil
+}
+
+// Save merges the durations of the steps seen during the run into
+// CacheDurationsPath, keeping at most CacheDurationsLimit of them. It does
+// nothing if no steps were seen.
+//
+// The file is locked while it is updated, so that concurrent runs do not
+// clobber each other's durations.
+func (stats *CacheStats) Save() error {
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+
+	if len(stats.seen) == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(CacheDurationsPath()), 0700); err != nil {
+		return err
+	}
+
+	lock := flock.New(CacheDurationsPath())
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("lock: %w", err)
+	}
+
+	defer lock.Unlock()
+
+	durations, err := loadCacheDurations()
+	if err != nil {
+		// start over rather than failing every run from now on
+		durations = map[string]cacheDuration{}
+	}
+
+	for key := range stats.seen {
+		if dur, found := stats.durations[key]; found {
+			durations[key] = dur
+		}
+	}
+
+	trimCacheDurations(durations, CacheDurationsLimit)
+
+	payload, err := json.Marshal(durations)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(CacheDurationsPath(), payload, 0600)
+}
+
+// Hit records a cached step, identified by key.
+func (stats *CacheStats) Hit(key string) {
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+
+	stats.hits++
+
+	if dur, found := stats.durations[key]; found {
+		stats.timeSaved += dur.Duration
+		dur.Seen = Clock.Now()
+		stats.durations[key] = dur
+		stats.seen[key] = true
+	}
+}
+
+// Miss records a step, identified by key, which ran for the given duration.
+func (stats *CacheStats) Miss(key string, dur time.Duration) {
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+
+	stats.misses++
+	stats.durations[key] = cacheDuration{
+		Duration: dur,
+		Seen:     Clock.Now(),
+	}
+	stats.seen[key] = true
+}
+
+// CountReused sets the bytes reused to the total size of the cache records
+// reused since the given time by each runtime in the pool which implements
+// CacheReuser.
+//
+// Records used by other runs sharing the same runtime since then are counted
+// too, since runtimes do not track which run used them.
+func (stats *CacheStats) CountReused(ctx context.Context, pool RuntimePool, since time.Time) error {
+	runtimes, err := pool.All()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, runtime := range runtimes {
+		reuser, ok := runtime.(CacheReuser)
+		if !ok {
+			continue
+		}
+
+		size, err := reuser.ReusedBytes(ctx, since)
+		if err != nil {
+			return err
+		}
+
+		total += size
+	}
+
+	stats.mu.Lock()
+	stats.bytesReused = total
+	stats.mu.Unlock()
+
+	return nil
+}
+
+// Report returns a snapshot of the stats.
+func (stats *CacheStats) Report() CacheReport {
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+
+	return CacheReport{
+		Hits:        stats.hits,
+		Misses:      stats.misses,
+		BytesReused: stats.bytesReused,
+		TimeSaved:   stats.timeSaved,
+	}
+}
+
+// HitPercent returns the percentage of steps which were cached.
+func (report CacheReport) HitPercent() int {
+	total := report.Hits + report.Misses
+	if total == 0 {
+		return 0
+	}
+
+	return report.Hits * 100 / total
+}
+
+// ToValue returns the report as a scope.
+func (report CacheReport) ToValue() Value {
+	return Bindings{
+		"hits":          Int(report.Hits),
+		"misses":        Int(report.Misses),
+		"hit-percent":   Int(report.HitPercent()),
+		"bytes-reused":  Int(report.BytesReused),
+		"time-saved-ms": Int(report.TimeSaved.Milliseconds()),
+	}.Scope()
+}
+
+type cacheStatsKey struct{}
+
+// WithCacheStats sets the cache stats collected within the returned context.
+func WithCacheStats(ctx context.Context, stats *CacheStats) context.Context {
+	return context.WithValue(ctx, cacheStatsKey{}, stats)
+}
+
+// CacheStatsFromContext returns the cache stats set by WithCacheStats.
+func CacheStatsFromContext(ctx context.Context) (*CacheStats, bool) {
+	stats, ok := ctx.Value(cacheStatsKey{}).(*CacheStats)
+	return stats, ok
+}
+
+func loadCacheDurations() (map[string]cacheDuration, error) {
+	durations := map[string]cacheDuration{}
+
+	payload, err := os.ReadFile(CacheDurationsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return durations, nil
+		}
+
+		return nil, err
+	}
+
+	if len(payload) == 0 {
+		// created by locking it, but not yet written
+		return durations, nil
+	}
+
+	if err := json.Unmarshal(payload, &durations); err != nil {
+		return nil, err
+	}
+
+	return durations, nil
+}
+
+// trimCacheDurations removes the least recently seen durations beyond limit.
+func trimCacheDurations(durations map[string]cacheDuration, limit int) {
+	if len(durations) <= limit {
+		return
+	}
+
+	keys := make([]string, 0, len(durations))
+	for key := range durations {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return durations[keys[i]].Seen.After(durations[keys[j]].Seen)
+	})
+
+	for _, key := range keys[limit:] {
+		delete(durations, key)
+	}
+}
@@ -0,0 +1,121 @@
+package bass_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/basstest"
+	"github.com/vito/bass/pkg/runtimes/fake"
+	"github.com/vito/is"
+)
+
+func TestCacheStats(t *testing.T) {
+	is := is.New(t)
+
+	oldHome := bass.CacheHome
+	bass.CacheHome = t.TempDir()
+	defer func() { bass.CacheHome = oldHome }()
+
+	stats, err := bass.LoadCacheStats()
+	is.NoErr(err)
+
+	stats.Miss("build", 3*time.Second)
+	stats.Hit("fetch")
+	is.Equal(stats.Report(), bass.CacheReport{
+		Hits:   1,
+		Misses: 1,
+	})
+
+	// a concurrent run saves its own durations first
+	other, err := bass.LoadCacheStats()
+	is.NoErr(err)
+	other.Miss("lint", 2*time.Second)
+	is.NoErr(other.Save())
+
+	is.NoErr(stats.Save())
+
+	// a later run estimates the time saved from the saved durations
+	stats, err = bass.LoadCacheStats()
+	is.NoErr(err)
+
+	stats.Hit("build")
+	stats.Hit("lint")
+	stats.Hit("fetch")
+	stats.Miss("test", time.Second)
+	is.Equal(stats.Report(), bass.CacheReport{
+		Hits:      3,
+		Misses:    1,
+		TimeSaved: 5 * time.Second,
+	})
+	is.Equal(stats.Report().HitPercent(), 75)
+
+	ctx := context.Background()
+
+	// bytes reused are counted from the runtime's cache records
+	pool := fake.Pool{Runtime: reusingRuntime{reused: 1024}}
+	is.NoErr(stats.CountReused(ctx, pool, time.Now()))
+	is.Equal(stats.Report().BytesReused, int64(1024))
+
+	ctx = bass.WithCacheStats(ctx, stats)
+	res, err := bass.EvalFSFile(ctx, bass.NewStandardScope(), bass.NewInMemoryFile("test", "(cache-stats)"))
+	is.NoErr(err)
+	basstest.Equal(t, res, bass.Bindings{
+		"hits":          bass.Int(3),
+		"misses":        bass.Int(1),
+		"hit-percent":   bass.Int(75),
+		"bytes-reused":  bass.Int(1024),
+		"time-saved-ms": bass.Int(5000),
+	}.Scope())
+}
+
+func TestCacheStatsLimit(t *testing.T) {
+	is := is.New(t)
+
+	oldHome := bass.CacheHome
+	bass.CacheHome = t.TempDir()
+	defer func() { bass.CacheHome = oldHome }()
+
+	oldLimit := bass.CacheDurationsLimit
+	bass.CacheDurationsLimit = 3
+	defer func() { bass.CacheDurationsLimit = oldLimit }()
+
+	for i := 0; i < 5; i++ {
+		stats, err := bass.LoadCacheStats()
+		is.NoErr(err)
+		stats.Miss(fmt.Sprintf("step-%d", i), time.Second)
+		is.NoErr(stats.Save())
+	}
+
+	stats, err := bass.LoadCacheStats()
+	is.NoErr(err)
+
+	for i := 0; i < 5; i++ {
+		stats.Hit(fmt.Sprintf("step-%d", i))
+	}
+
+	// only the most recently seen steps are kept
+	is.Equal(stats.Report().TimeSaved, 3*time.Second)
+
+	// a run which sees nothing leaves the file alone
+	info, err := os.Stat(bass.CacheDurationsPath())
+	is.NoErr(err)
+	is.NoErr(bass.NewCacheStats().Save())
+	again, err := os.Stat(bass.CacheDurationsPath())
+	is.NoErr(err)
+	is.Equal(again.ModTime(), info.ModTime())
+}
+
+// reusingRuntime reports a fixed number of bytes reused.
+type reusingRuntime struct {
+	bass.Runtime
+
+	reused int64
+}
+
+func (runtime reusingRuntime) ReusedBytes(context.Context, time.Time) (int64, error) {
+	return runtime.reused, nil
+}
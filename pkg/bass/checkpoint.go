@@ -0,0 +1,166 @@
+package bass
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/vito/bass/pkg/zapctx"
+	"go.uber.org/zap"
+)
+
+// CheckpointPath returns the path to the checkpoint file for the given run
+// key.
+func CheckpointPath(key string) string {
+	return filepath.Join(CacheHome, "checkpoints", key)
+}
+
+// Checkpoint records the thunks completed by a run, so that re-running it
+// after a failure can skip them.
+//
+// Each completed thunk's SHA256 digest is appended to the checkpoint file as
+// soon as it succeeds, so progress survives crashes.
+type Checkpoint struct {
+	path string
+
+	mu   sync.Mutex
+	done map[string]bool
+}
+
+// LoadCheckpoint loads the checkpoint for the given run key, which will be
+// empty if the run has not been checkpointed before.
+func LoadCheckpoint(key string) (*Checkpoint, error) {
+	cp := &Checkpoint{
+		path: CheckpointPath(key),
+		done: map[string]bool{},
+	}
+
+	f, err := os.Open(cp.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cp, nil
+		}
+
+		return nil, err
+	}
+
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if digest := scanner.Text(); digest != "" {
+			cp.done[digest] = true
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return cp, nil
+}
+
+// Len returns the number of completed thunks.
+func (cp *Checkpoint) Len() int {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return len(cp.done)
+}
+
+// Done returns true if the thunk digest was completed.
+func (cp *Checkpoint) Done(digest string) bool {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return cp.done[digest]
+}
+
+// Complete records the thunk digest as completed.
+func (cp *Checkpoint) Complete(digest string) error {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	if cp.done[digest] {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cp.path), 0700); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(cp.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	if _, err := f.WriteString(digest + "\n"); err != nil {
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	cp.done[digest] = true
+
+	return nil
+}
+
+// Remove deletes the checkpoint, typically once the run has succeeded.
+func (cp *Checkpoint) Remove() error {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	cp.done = map[string]bool{}
+
+	err := os.Remove(cp.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+type checkpointKey struct{}
+
+// WithCheckpoint returns a context in which thunks completed by the
+// checkpoint are skipped by Thunk.Run, and newly completed thunks are
+// recorded.
+func WithCheckpoint(ctx context.Context, cp *Checkpoint) context.Context {
+	return context.WithValue(ctx, checkpointKey{}, cp)
+}
+
+// runCheckpointed calls run unless the thunk was already completed by the
+// checkpoint in the context, recording it as completed if it succeeds.
+func runCheckpointed(ctx context.Context, thunk Thunk, run func() error) error {
+	cp, ok := ctx.Value(checkpointKey{}).(*Checkpoint)
+	if !ok {
+		return run()
+	}
+
+	digest, err := thunk.SHA256()
+	if err != nil {
+		return err
+	}
+
+	logger := zapctx.FromContext(ctx)
+
+	if cp.Done(digest) {
+		logger.Info("skipping thunk completed by previous run",
+			zap.String("thunk", thunk.Name()))
+		return nil
+	}
+
+	if err := run(); err != nil {
+		return err
+	}
+
+	if err := cp.Complete(digest); err != nil {
+		logger.Warn("failed to record checkpoint", zap.Error(err))
+	}
+
+	return nil
+}
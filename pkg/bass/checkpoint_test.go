@@ -0,0 +1,87 @@
+package bass_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/runtimes"
+	"github.com/vito/is"
+)
+
+type countingRuntime struct {
+	FakeRuntime
+
+	runs  map[string]int
+	fails map[string]bool
+}
+
+func (fake *countingRuntime) Run(ctx context.Context, thunk bass.Thunk) error {
+	name := thunk.Cmd.ToValue().String()
+	fake.runs[name]++
+
+	if fake.fails[name] {
+		return fmt.Errorf("%s failed", name)
+	}
+
+	return nil
+}
+
+func TestCheckpoint(t *testing.T) {
+	is := is.New(t)
+
+	oldHome := bass.CacheHome
+	bass.CacheHome = t.TempDir()
+	defer func() { bass.CacheHome = oldHome }()
+
+	fake := &countingRuntime{
+		runs:  map[string]int{},
+		fails: map[string]bool{".b": true},
+	}
+
+	ctx := bass.WithRuntimePool(context.Background(), &runtimes.Pool{
+		Runtimes: []runtimes.Assoc{
+			{
+				Platform: bass.LinuxPlatform,
+				Runtime:  fake,
+			},
+		},
+	})
+
+	script := `
+		(def image {:platform {:os "linux"} :repository "alpine" :tag "latest"})
+		(run (with-image (.a) image))
+		(run (with-image (.b) image))
+		(run (with-image (.c) image))
+	`
+
+	run := func() error {
+		cp, err := bass.LoadCheckpoint("test")
+		is.NoErr(err)
+
+		_, err = bass.EvalFSFile(bass.WithCheckpoint(ctx, cp), bass.NewStandardScope(), bass.NewInMemoryFile("test", script))
+		return err
+	}
+
+	is.True(run() != nil)
+	is.Equal(fake.runs, map[string]int{".a": 1, ".b": 1})
+
+	fake.fails = map[string]bool{}
+
+	is.NoErr(run())
+	is.Equal(fake.runs, map[string]int{".a": 1, ".b": 2, ".c": 1})
+
+	cp, err := bass.LoadCheckpoint("test")
+	is.NoErr(err)
+	is.Equal(cp.Len(), 3)
+
+	is.NoErr(run())
+	is.Equal(fake.runs, map[string]int{".a": 1, ".b": 2, ".c": 1})
+
+	is.NoErr(cp.Remove())
+
+	cp, err = bass.LoadCheckpoint("test")
+	is.NoErr(err)
+	is.Equal(cp.Len(), 0)
+}
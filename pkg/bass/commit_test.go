@@ -0,0 +1,54 @@
+package bass_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/runtimes"
+	"github.com/vito/is"
+)
+
+func TestCommit(t *testing.T) {
+	is := is.New(t)
+
+	fake := &flakyRuntime{
+		runs: map[string]int{},
+		failures: map[string]int{
+			".broken": 1,
+		},
+	}
+
+	ctx := bass.WithRuntimePool(context.Background(), &runtimes.Pool{
+		Runtimes: []runtimes.Assoc{
+			{
+				Platform: bass.LinuxPlatform,
+				Runtime:  fake,
+			},
+		},
+	})
+
+	eval := func(src string) (bass.Value, error) {
+		return bass.EvalFSFile(ctx, bass.NewStandardScope(), bass.NewInMemoryFile("test", `
+			(def image {:platform {:os "linux"} :repository "alpine" :tag "latest"})
+		`+src))
+	}
+
+	res, err := eval(`
+		(def deps (commit (from image (.install))))
+		[deps (from deps (.test)) (from deps (.lint))]
+	`)
+	is.NoErr(err)
+	is.Equal(fake.runs[".install"], 1)
+	is.Equal(fake.runs[".test"], 0)
+
+	var thunks []bass.Thunk
+	is.NoErr(res.Decode(&thunks))
+	is.Equal(len(thunks), 3)
+	is.True(thunks[1].Image.Thunk.Equal(thunks[0]))
+	is.True(thunks[2].Image.Thunk.Equal(thunks[0]))
+
+	_, err = eval(`(commit (from image (.broken)))`)
+	is.True(err != nil)
+	is.Equal(fake.runs[".broken"], 1)
+}
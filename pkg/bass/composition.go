@@ -0,0 +1,92 @@
+package bass
+
+import (
+	"context"
+)
+
+// Composition is an operative which calls a series of applicatives from
+// right to left, passing the arguments it's given to the last applicative
+// and each result to the one before it.
+//
+// It is wrapped by (comp) so that its arguments are evaluated once before
+// being passed along.
+type Composition struct {
+	Applicatives []Applicative
+}
+
+var _ Value = Composition{}
+
+func (value Composition) Equal(other Value) bool {
+	var o Composition
+	if err := other.Decode(&o); err != nil {
+		return false
+	}
+
+	if len(value.Applicatives) != len(o.Applicatives) {
+		return false
+	}
+
+	for i, app := range value.Applicatives {
+		if !app.Equal(o.Applicatives[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (value Composition) String() string {
+	form := []Value{Symbol("comp")}
+	for _, app := range value.Applicatives {
+		form = append(form, app)
+	}
+
+	return NewList(form...).String()
+}
+
+func (value Composition) Decode(dest any) error {
+	switch x := dest.(type) {
+	case *Composition:
+		*x = value
+		return nil
+	case *Combiner:
+		*x = value
+		return nil
+	case *Value:
+		*x = value
+		return nil
+	default:
+		return DecodeError{
+			Source:      value,
+			Destination: dest,
+		}
+	}
+}
+
+func (value Composition) MarshalJSON() ([]byte, error) {
+	return nil, EncodeError{value}
+}
+
+// Eval returns the value.
+func (value Composition) Eval(_ context.Context, _ *Scope, cont Cont) ReadyCont {
+	return cont.Call(value, nil)
+}
+
+var _ Combiner = Composition{}
+
+// Call calls the last applicative with the given arguments, and each
+// preceding applicative with the result of the one after it.
+func (combiner Composition) Call(ctx context.Context, val Value, scope *Scope, cont Cont) ReadyCont {
+	return combiner.call(ctx, len(combiner.Applicatives)-1, val, scope, cont)
+}
+
+func (combiner Composition) call(ctx context.Context, i int, args Value, scope *Scope, cont Cont) ReadyCont {
+	op := combiner.Applicatives[i].Unwrap()
+	if i == 0 {
+		return op.Call(ctx, args, scope, cont)
+	}
+
+	return op.Call(ctx, args, scope, Continue(func(res Value) Value {
+		return combiner.call(ctx, i-1, NewList(res), scope, cont)
+	}))
+}
@@ -11,6 +11,43 @@ import (
 // run on the same machine.
 type Config struct {
 	Runtimes []RuntimeConfig `json:"runtimes"`
+
+	// Emulate configures whether to fall back to emulating a platform when no
+	// runtime matches it, using a runtime for the same OS on another
+	// architecture.
+	Emulate bool `json:"emulate,omitempty"`
+
+	// Queue configures the queue of runs submitted to a daemon.
+	Queue QueueConfig `json:"queue,omitempty"`
+
+	// Network configures proxies and CA certificates for outbound network
+	// calls.
+	Network NetworkConfig `json:"network,omitempty"`
+
+	// CredentialHelper is the command run to resolve registry credentials and
+	// named secrets. See CredentialHelper for its protocol.
+	CredentialHelper string `json:"credential_helper,omitempty"`
+}
+
+// QueueConfig limits the concurrency of runs submitted to a daemon.
+type QueueConfig struct {
+	// Concurrency is the maximum number of runs across all classes. Zero means
+	// no limit.
+	Concurrency int `json:"concurrency,omitempty"`
+
+	// Classes configures each class of runs, keyed by name.
+	Classes map[string]QueueClass `json:"classes,omitempty"`
+}
+
+// QueueClass configures a class of runs, such as builds or deploys.
+type QueueClass struct {
+	// Concurrency is the maximum number of runs in the class. Zero means no
+	// limit.
+	Concurrency int `json:"concurrency,omitempty"`
+
+	// Priority determines which waiting runs start first; higher priority
+	// classes go first.
+	Priority int `json:"priority,omitempty"`
 }
 
 // RuntimeConfig associates a platform object to a runtime command to run.
@@ -21,6 +58,9 @@ type RuntimeConfig struct {
 	Platform Platform `json:"platform"`
 	Runtime  string   `json:"runtime"`
 	Config   *Scope   `json:"config,omitempty"`
+
+	// Network overrides the top-level network config for the runtime.
+	Network NetworkConfig `json:"network,omitempty"`
 }
 
 // LoadConfig loads a Config from the JSON file at the given path.
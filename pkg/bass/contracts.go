@@ -0,0 +1,228 @@
+package bass
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Kinds of checks which may fail with an AssertionError.
+const (
+	CheckAssertion     = "assertion"
+	CheckPrecondition  = "precondition"
+	CheckPostcondition = "postcondition"
+)
+
+// AssertionError is returned when an (assert) or a function's (pre) or
+// (post) contract fails.
+type AssertionError struct {
+	// Kind is the kind of check that failed.
+	Kind string
+
+	// Form is the expression that was not truthy.
+	Form Value
+
+	// Range is the location of the form in its source, if known.
+	Range *Range
+
+	// Message is an optional explanation provided with the check.
+	Message string
+}
+
+func (err AssertionError) Error() string {
+	var msg strings.Builder
+
+	msg.WriteString(err.Kind)
+	msg.WriteString(" failed")
+
+	if err.Range != nil {
+		fmt.Fprintf(&msg, " at %s", err.Range)
+	}
+
+	fmt.Fprintf(&msg, ": %s", err.Form)
+
+	if err.Message != "" {
+		fmt.Fprintf(&msg, ": %s", err.Message)
+	}
+
+	return msg.String()
+}
+
+type contractsKey struct{}
+
+// WithContracts enables checking the (pre) and (post) contracts of functions
+// called within the returned context.
+//
+// Contracts are skipped by default so that they cost nothing in production.
+func WithContracts(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contractsKey{}, true)
+}
+
+// ContractsEnabled returns true if contracts are checked in the context.
+func ContractsEnabled(ctx context.Context) bool {
+	enabled, _ := ctx.Value(contractsKey{}).(bool)
+	return enabled
+}
+
+// Contract clause names, which may lead the body of an operative.
+const (
+	PreSymbol  Symbol = "pre"
+	PostSymbol Symbol = "post"
+)
+
+// extractContracts splits the leading (pre) and (post) clauses from the body
+// of an operative, returning the precondition expressions, the postcondition
+// predicates, and the remaining body.
+func extractContracts(body Value) ([]Value, []Value, Value) {
+	var forms Pair
+	if err := body.Decode(&forms); err != nil {
+		return nil, nil, body
+	}
+
+	if !isDo(forms.A) {
+		return nil, nil, body
+	}
+
+	var pre, post []Value
+
+	rest := forms.D
+	for {
+		var clause Pair
+		if err := rest.Decode(&clause); err != nil {
+			break
+		}
+
+		form := clause.A
+
+		var ann Annotate
+		if err := form.Decode(&ann); err == nil {
+			form = ann.Value
+		}
+
+		var call Pair
+		if err := form.Decode(&call); err != nil {
+			break
+		}
+
+		var sym Symbol
+		if err := call.A.Decode(&sym); err != nil || (sym != PreSymbol && sym != PostSymbol) {
+			break
+		}
+
+		var argList List
+		if err := call.D.Decode(&argList); err != nil {
+			break
+		}
+
+		args, err := ToSlice(argList)
+		if err != nil {
+			break
+		}
+
+		if sym == PreSymbol {
+			pre = append(pre, args...)
+		} else {
+			post = append(post, args...)
+		}
+
+		rest = clause.D
+	}
+
+	if pre == nil && post == nil {
+		return nil, nil, body
+	}
+
+	return pre, post, Pair{A: forms.A, D: rest}
+}
+
+func isDo(val Value) bool {
+	var sym Symbol
+	if err := val.Decode(&sym); err == nil {
+		return sym == "do"
+	}
+
+	var builtin *Builtin
+	if err := val.Decode(&builtin); err == nil {
+		return builtin.Name == "do"
+	}
+
+	return false
+}
+
+// checkPre evaluates each precondition in the scope, failing if any are not
+// truthy.
+func checkPre(ctx context.Context, scope *Scope, pre []Value, cont Cont) ReadyCont {
+	if len(pre) == 0 {
+		return cont.Call(Null{}, nil)
+	}
+
+	check := pre[0]
+	return check.Eval(ctx, scope, Continue(func(res Value) Value {
+		if !truthy(res) {
+			return cont.Call(nil, newAssertionError(CheckPrecondition, check, ""))
+		}
+
+		return checkPre(ctx, scope, pre[1:], cont)
+	}))
+}
+
+// checkPost calls each postcondition predicate with the result, failing if
+// any return a value that is not truthy.
+func checkPost(ctx context.Context, scope *Scope, post []Value, res Value, cont Cont) ReadyCont {
+	if len(post) == 0 {
+		return cont.Call(res, nil)
+	}
+
+	check := post[0]
+	return check.Eval(ctx, scope, Continue(func(predVal Value) Value {
+		var pred Combiner
+		if err := predVal.Decode(&pred); err != nil {
+			return cont.Call(nil, err)
+		}
+
+		// call the underlying operative so the result is not evaluated again
+		var app Applicative
+		if err := pred.Decode(&app); err == nil {
+			pred = app.Unwrap()
+		}
+
+		return pred.Call(ctx, NewList(res), scope, Continue(func(ok Value) Value {
+			if !truthy(ok) {
+				var ann Annotate
+				if err := check.Decode(&ann); err == nil {
+					ann.Value = NewList(ann.Value, res)
+					return cont.Call(nil, newAssertionError(CheckPostcondition, ann, ""))
+				}
+
+				return cont.Call(nil, newAssertionError(CheckPostcondition, NewList(check, res), ""))
+			}
+
+			return checkPost(ctx, scope, post[1:], res, cont)
+		}))
+	}))
+}
+
+func newAssertionError(kind string, form Value, msg string) AssertionError {
+	err := AssertionError{
+		Kind:    kind,
+		Form:    form,
+		Message: msg,
+	}
+
+	var ann Annotate
+	if decErr := form.Decode(&ann); decErr == nil {
+		err.Form = ann.Value
+		err.Range = &ann.Range
+	}
+
+	return err
+}
+
+func truthy(val Value) bool {
+	var b bool
+	if err := val.Decode(&b); err != nil {
+		return true
+	}
+
+	return b
+}
@@ -0,0 +1,198 @@
+package bass
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// DefaultCredentialHelper is the executable used as the credential helper if
+// it is in $PATH and no other helper is configured.
+const DefaultCredentialHelper = "bass-credential-helper"
+
+// Kinds of credentials requested from a credential helper.
+const (
+	// CredentialKindRegistry is a credential for an image registry, keyed by
+	// its host, e.g. registry-1.docker.io.
+	CredentialKindRegistry = "registry"
+
+	// CredentialKindSecret is a named secret, keyed by its name.
+	CredentialKindSecret = "secret"
+)
+
+// ErrNoCredentialHelper is returned by (secret) when no credential helper is
+// configured.
+var ErrNoCredentialHelper = errors.New("no credential helper configured")
+
+// CredentialNotFoundError is returned by (secret) when the credential helper
+// has no credential for the name.
+type CredentialNotFoundError struct {
+	Kind string
+	Key  string
+}
+
+func (err CredentialNotFoundError) Error() string {
+	return fmt.Sprintf("credential helper has no %s: %s", err.Kind, err.Key)
+}
+
+// Credential is a credential returned by a credential helper.
+type Credential struct {
+	// Username is the username to authenticate as, if any.
+	Username string `json:"username,omitempty"`
+
+	// Secret is the password, token, or other secret value.
+	Secret string `json:"secret"`
+}
+
+// CredentialHelper resolves credentials by running an external command, so
+// that organizations can plug in their own credential stores.
+//
+// The command is run with the arguments "get <kind> <key>". If it has a
+// credential, it prints it to stdout as a JSON object with "secret" and
+// optional "username" fields. If it has none, it prints nothing. Any other
+// failure is signalled with a non-zero exit status.
+//
+// Helpers returned by LookupCredentialHelper cache each response for the rest
+// of the session, so that the helper runs once per credential rather than
+// once per pull. Failures are not cached.
+type CredentialHelper struct {
+	// Command is the helper command and any leading arguments.
+	Command []string
+
+	// cache holds the responses from the helper, if caching
+	cache *credentialCache
+}
+
+type credentialCache struct {
+	responses map[credentialKey]credentialResponse
+	mu        sync.Mutex
+}
+
+type credentialKey struct {
+	kind, key string
+}
+
+type credentialResponse struct {
+	cred  Credential
+	found bool
+}
+
+// LookupCredentialHelper returns the configured credential helper, or
+// DefaultCredentialHelper if it is in $PATH.
+func LookupCredentialHelper(configured string) (CredentialHelper, bool) {
+	cache := &credentialCache{
+		responses: map[credentialKey]credentialResponse{},
+	}
+
+	if configured != "" {
+		return CredentialHelper{Command: strings.Fields(configured), cache: cache}, true
+	}
+
+	path, err := exec.LookPath(DefaultCredentialHelper)
+	if err != nil {
+		return CredentialHelper{}, false
+	}
+
+	return CredentialHelper{Command: []string{path}, cache: cache}, true
+}
+
+// Get requests a credential from the helper. It returns false if the helper
+// has no credential for the key.
+func (helper CredentialHelper) Get(ctx context.Context, kind, key string) (Credential, bool, error) {
+	if len(helper.Command) == 0 {
+		return Credential{}, false, ErrNoCredentialHelper
+	}
+
+	if helper.cache == nil {
+		return helper.run(ctx, kind, key)
+	}
+
+	// NB: hold the lock while running the helper so that concurrent requests
+	// for the same credential don't each run it
+	helper.cache.mu.Lock()
+	defer helper.cache.mu.Unlock()
+
+	ck := credentialKey{kind, key}
+	if res, found := helper.cache.responses[ck]; found {
+		return res.cred, res.found, nil
+	}
+
+	cred, found, err := helper.run(ctx, kind, key)
+	if err != nil {
+		return Credential{}, false, err
+	}
+
+	helper.cache.responses[ck] = credentialResponse{cred, found}
+
+	return cred, found, nil
+}
+
+// run runs the helper to request a credential.
+func (helper CredentialHelper) run(ctx context.Context, kind, key string) (Credential, bool, error) {
+	args := append([]string{}, helper.Command[1:]...)
+	args = append(args, "get", kind, key)
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+
+	cmd := exec.CommandContext(ctx, helper.Command[0], args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg != "" {
+			return Credential{}, false, fmt.Errorf("credential helper: %w: %s", err, msg)
+		}
+
+		return Credential{}, false, fmt.Errorf("credential helper: %w", err)
+	}
+
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return Credential{}, false, nil
+	}
+
+	var cred Credential
+	if err := json.Unmarshal(stdout.Bytes(), &cred); err != nil {
+		return Credential{}, false, fmt.Errorf("credential helper: decode response: %w", err)
+	}
+
+	return cred, true, nil
+}
+
+// Secret returns the named secret from the helper.
+func (helper CredentialHelper) Secret(ctx context.Context, name string) (Secret, error) {
+	cred, found, err := helper.Get(ctx, CredentialKindSecret, name)
+	if err != nil {
+		return Secret{}, err
+	}
+
+	if !found {
+		return Secret{}, CredentialNotFoundError{
+			Kind: CredentialKindSecret,
+			Key:  name,
+		}
+	}
+
+	return NewSecret(name, []byte(cred.Secret)), nil
+}
+
+type credentialHelperKey struct{}
+
+// WithCredentialHelper sets the credential helper used within the returned
+// context.
+func WithCredentialHelper(ctx context.Context, helper CredentialHelper) context.Context {
+	return context.WithValue(ctx, credentialHelperKey{}, helper)
+}
+
+// CredentialHelperFromContext returns the credential helper set by
+// WithCredentialHelper.
+func CredentialHelperFromContext(ctx context.Context) (CredentialHelper, bool) {
+	helper, ok := ctx.Value(credentialHelperKey{}).(CredentialHelper)
+	return helper, ok
+}
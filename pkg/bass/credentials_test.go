@@ -0,0 +1,101 @@
+package bass_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/is"
+)
+
+const fakeCredentialHelper = `#!/bin/sh
+set -e
+
+[ "$1" = "get" ] || exit 1
+
+echo "$2/$3" >> "$BASS_TEST_HELPER_CALLS"
+
+case "$2/$3" in
+  registry/ghcr.io)
+    echo '{"username":"bass","secret":"registry-token"}'
+    ;;
+  secret/github-token)
+    echo '{"secret":"super secret"}'
+    ;;
+  secret/broken)
+    echo "the vault is sealed" >&2
+    exit 1
+    ;;
+esac
+`
+
+func TestCredentialHelper(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake credential helper is a shell script")
+	}
+
+	is := is.New(t)
+
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("BASS_TEST_HELPER_CALLS", calls)
+
+	helperPath := filepath.Join(t.TempDir(), "bass-credential-helper")
+	is.NoErr(os.WriteFile(helperPath, []byte(fakeCredentialHelper), 0755))
+
+	helper, found := bass.LookupCredentialHelper(helperPath)
+	is.True(found)
+
+	ctx := context.Background()
+
+	cred, found, err := helper.Get(ctx, bass.CredentialKindRegistry, "ghcr.io")
+	is.NoErr(err)
+	is.True(found)
+	is.Equal(cred, bass.Credential{Username: "bass", Secret: "registry-token"})
+
+	_, found, err = helper.Get(ctx, bass.CredentialKindRegistry, "docker.io")
+	is.NoErr(err)
+	is.True(!found)
+
+	_, _, err = helper.Get(ctx, bass.CredentialKindSecret, "broken")
+	is.True(err != nil)
+	is.Equal(err.Error(), "credential helper: exit status 1: the vault is sealed")
+
+	// responses are cached for the session, including missing credentials,
+	// but failures are not
+	_, _, err = helper.Get(ctx, bass.CredentialKindRegistry, "ghcr.io")
+	is.NoErr(err)
+	_, _, err = helper.Get(ctx, bass.CredentialKindRegistry, "docker.io")
+	is.NoErr(err)
+	_, _, err = helper.Get(ctx, bass.CredentialKindSecret, "broken")
+	is.True(err != nil)
+
+	called, err := os.ReadFile(calls)
+	is.NoErr(err)
+	is.Equal(string(called), "registry/ghcr.io\nregistry/docker.io\nsecret/broken\nsecret/broken\n")
+
+	scope := bass.NewStandardScope()
+	helperCtx := bass.WithCredentialHelper(ctx, helper)
+
+	res, err := bass.EvalFSFile(helperCtx, scope, bass.NewInMemoryFile("test", `(secret :github-token)`))
+	is.NoErr(err)
+
+	var secret bass.Secret
+	is.NoErr(res.Decode(&secret))
+	is.Equal(secret.Name, "github-token")
+	is.Equal(string(secret.Reveal()), "super secret")
+
+	_, err = bass.EvalFSFile(helperCtx, scope, bass.NewInMemoryFile("test", `(secret :missing)`))
+	var notFound bass.CredentialNotFoundError
+	is.True(errors.As(err, &notFound))
+	is.Equal(notFound, bass.CredentialNotFoundError{
+		Kind: bass.CredentialKindSecret,
+		Key:  "missing",
+	})
+
+	_, err = bass.EvalFSFile(ctx, scope, bass.NewInMemoryFile("test", `(secret :github-token)`))
+	is.True(errors.Is(err, bass.ErrNoCredentialHelper))
+}
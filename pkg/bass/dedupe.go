@@ -0,0 +1,42 @@
+package bass
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// RunGroup coalesces concurrent runs of identical thunks into a single run.
+//
+// The zero value is ready to use.
+type RunGroup struct {
+	sf singleflight.Group
+}
+
+// inflightRuns coalesces runs started by Thunk.Run.
+var inflightRuns RunGroup
+
+// Run calls run unless a run of a thunk with the same SHA256 digest is
+// already in flight in the group, in which case it waits for that run and
+// returns its result instead.
+//
+// If the shared run is canceled by its caller while this caller's context is
+// still live, run is tried again.
+func (group *RunGroup) Run(ctx context.Context, thunk Thunk, run func() error) error {
+	digest, err := thunk.SHA256()
+	if err != nil {
+		return run()
+	}
+
+	for {
+		_, err, shared := group.sf.Do(digest, func() (any, error) {
+			return nil, run()
+		})
+		if shared && errors.Is(err, context.Canceled) && ctx.Err() == nil {
+			continue
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,82 @@
+package bass_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/is"
+	"golang.org/x/sync/errgroup"
+)
+
+func TestRunGroup(t *testing.T) {
+	is := is.New(t)
+
+	ctx := context.Background()
+
+	thunk := uniq(bass.Thunk{
+		Cmd: bass.ThunkCmd{Cmd: &bass.CommandPath{"build"}},
+	})
+
+	var group bass.RunGroup
+	var runs int32
+
+	release := make(chan struct{})
+
+	eg := new(errgroup.Group)
+	for i := 0; i < 5; i++ {
+		eg.Go(func() error {
+			return group.Run(ctx, thunk, func() error {
+				atomic.AddInt32(&runs, 1)
+				<-release
+				return nil
+			})
+		})
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+
+	is.NoErr(eg.Wait())
+	is.Equal(atomic.LoadInt32(&runs), int32(1))
+
+	// runs again once the first run is done
+	is.NoErr(group.Run(ctx, thunk, func() error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	}))
+	is.Equal(atomic.LoadInt32(&runs), int32(2))
+
+	t.Run("retries when the shared run is canceled", func(t *testing.T) {
+		is := is.New(t)
+
+		leaderCtx, cancel := context.WithCancel(ctx)
+
+		started := make(chan struct{})
+		leaderErr := make(chan error)
+		go func() {
+			leaderErr <- group.Run(leaderCtx, thunk, func() error {
+				close(started)
+				<-leaderCtx.Done()
+				return leaderCtx.Err()
+			})
+		}()
+
+		<-started
+
+		followerErr := make(chan error)
+		go func() {
+			followerErr <- group.Run(ctx, thunk, func() error {
+				return nil
+			})
+		}()
+
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+
+		is.Equal(<-leaderErr, context.Canceled)
+		is.NoErr(<-followerErr)
+	})
+}
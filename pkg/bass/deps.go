@@ -0,0 +1,271 @@
+package bass
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// DepKind is the kind of a dependency in a DepGraph.
+type DepKind string
+
+const (
+	// DepModule is a module loaded from a file, e.g. the script itself or a
+	// module loaded from *dir*.
+	DepModule DepKind = "module"
+
+	// DepStd is a module from the standard library, e.g. (.strings).
+	DepStd DepKind = "std"
+
+	// DepLibrary is a module loaded from a thunk path, e.g. a library fetched
+	// from a git repository.
+	DepLibrary DepKind = "library"
+
+	// DepRemoteScript is a script run from an OCI image.
+	DepRemoteScript DepKind = "remote-script"
+
+	// DepImage is an image pinned to a digest, either by resolving it or by
+	// recalling an earlier resolution from memos.
+	DepImage DepKind = "image"
+)
+
+// depShapes are the DOT node shapes for each kind of dependency.
+var depShapes = map[DepKind]string{
+	DepModule:       "box",
+	DepStd:          "box",
+	DepLibrary:      "folder",
+	DepRemoteScript: "folder",
+	DepImage:        "cylinder",
+}
+
+// Dep is a dependency recorded in a DepGraph.
+type Dep struct {
+	Kind   DepKind `json:"kind"`
+	Name   string  `json:"name"`
+	Digest string  `json:"digest,omitempty"`
+}
+
+func (dep Dep) key() string {
+	return string(dep.Kind) + "\x00" + dep.Name + "\x00" + dep.Digest
+}
+
+// DepTree is a dependency along with its transitive dependencies.
+type DepTree struct {
+	Dep
+	Deps []DepTree `json:"deps,omitempty"`
+}
+
+// DepGraph records the modules, remote scripts, and images that a script
+// depends on as it is loaded.
+//
+// Dependencies are recorded as they are observed, so the graph only includes
+// what was actually loaded, including modules loaded by other modules.
+type DepGraph struct {
+	mu    sync.Mutex
+	deps  map[string]Dep
+	order []string
+	edges map[string][]string
+	roots []string
+}
+
+// NewDepGraph returns an empty DepGraph.
+func NewDepGraph() *DepGraph {
+	return &DepGraph{
+		deps:  map[string]Dep{},
+		edges: map[string][]string{},
+	}
+}
+
+type depGraphKey struct{}
+type depParentKey struct{}
+
+// WithDepGraph records dependencies into the graph within the returned
+// context.
+func WithDepGraph(ctx context.Context, graph *DepGraph) context.Context {
+	return context.WithValue(ctx, depGraphKey{}, graph)
+}
+
+// DepGraphFromContext returns the graph set by WithDepGraph.
+func DepGraphFromContext(ctx context.Context) (*DepGraph, bool) {
+	graph, ok := ctx.Value(depGraphKey{}).(*DepGraph)
+	return graph, ok
+}
+
+// RecordDep records the dependency as a dependency of the module currently
+// being loaded, or as a root if there is none.
+//
+// The returned context records further dependencies under this one.
+func RecordDep(ctx context.Context, dep Dep) context.Context {
+	graph, ok := DepGraphFromContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	parent, _ := ctx.Value(depParentKey{}).(string)
+
+	key := graph.add(parent, dep)
+
+	return context.WithValue(ctx, depParentKey{}, key)
+}
+
+func (graph *DepGraph) add(parent string, dep Dep) string {
+	graph.mu.Lock()
+	defer graph.mu.Unlock()
+
+	key := dep.key()
+	if _, found := graph.deps[key]; !found {
+		graph.deps[key] = dep
+		graph.order = append(graph.order, key)
+	}
+
+	if parent == "" {
+		graph.roots = appendUnique(graph.roots, key)
+	} else if parent != key {
+		graph.edges[parent] = appendUnique(graph.edges[parent], key)
+	}
+
+	return key
+}
+
+func appendUnique(keys []string, key string) []string {
+	for _, k := range keys {
+		if k == key {
+			return keys
+		}
+	}
+
+	return append(keys, key)
+}
+
+// Deps returns every dependency in the order they were first recorded.
+func (graph *DepGraph) Deps() []Dep {
+	graph.mu.Lock()
+	defer graph.mu.Unlock()
+
+	deps := make([]Dep, len(graph.order))
+	for i, key := range graph.order {
+		deps[i] = graph.deps[key]
+	}
+
+	return deps
+}
+
+// Tree returns the transitive tree of dependencies from each root.
+//
+// A dependency shared by multiple modules appears under each of them.
+func (graph *DepGraph) Tree() []DepTree {
+	graph.mu.Lock()
+	defer graph.mu.Unlock()
+
+	trees := []DepTree{}
+	for _, root := range graph.roots {
+		trees = append(trees, graph.tree(root, map[string]bool{}))
+	}
+
+	return trees
+}
+
+func (graph *DepGraph) tree(key string, visiting map[string]bool) DepTree {
+	tree := DepTree{Dep: graph.deps[key]}
+
+	visiting[key] = true
+	defer delete(visiting, key)
+
+	for _, child := range graph.edges[key] {
+		if visiting[child] {
+			// cycles can't be loaded, but don't recurse forever if they are
+			continue
+		}
+
+		tree.Deps = append(tree.Deps, graph.tree(child, visiting))
+	}
+
+	return tree
+}
+
+// WriteDOT writes the graph in Graphviz DOT format.
+func (graph *DepGraph) WriteDOT(w io.Writer) error {
+	graph.mu.Lock()
+	defer graph.mu.Unlock()
+
+	ids := map[string]string{}
+	for i, key := range graph.order {
+		ids[key] = fmt.Sprintf("dep%d", i)
+	}
+
+	if _, err := fmt.Fprintln(w, "digraph deps {"); err != nil {
+		return err
+	}
+
+	for _, key := range graph.order {
+		dep := graph.deps[key]
+
+		label := dep.Name
+		if dep.Digest != "" {
+			label += "\n" + dep.Digest
+		}
+
+		_, err := fmt.Fprintf(w, "  %s [label=%s, shape=%s];\n", ids[key], strconv.Quote(label), depShapes[dep.Kind])
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, key := range graph.order {
+		for _, child := range graph.edges[key] {
+			if _, err := fmt.Fprintf(w, "  %s -> %s;\n", ids[key], ids[child]); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+// recordModuleDep records the module run by the command.
+func recordModuleDep(ctx context.Context, cmd ThunkCmd) context.Context {
+	if _, ok := DepGraphFromContext(ctx); !ok {
+		return ctx
+	}
+
+	switch {
+	case cmd.Cmd != nil:
+		return RecordDep(ctx, Dep{Kind: DepStd, Name: cmd.Cmd.String()})
+	case cmd.Host != nil:
+		name, err := filepath.Abs(cmd.Host.FromSlash())
+		if err != nil {
+			name = cmd.Host.FromSlash()
+		}
+
+		return RecordDep(ctx, Dep{Kind: DepModule, Name: name})
+	case cmd.FS != nil:
+		return RecordDep(ctx, Dep{Kind: DepModule, Name: cmd.FS.String()})
+	case cmd.Thunk != nil:
+		return RecordDep(ctx, Dep{Kind: DepLibrary, Name: cmd.Thunk.String()})
+	default:
+		return ctx
+	}
+}
+
+// recordImageDep records the image if it is pinned to a digest.
+func recordImageDep(ctx context.Context, ref ImageRef) {
+	name := ref.Repository.Static
+	if ref.Digest == "" || name == "" {
+		// not pinned, or not a registry image, e.g. an OCI archive from a thunk
+		return
+	}
+
+	if ref.Tag != "" {
+		name += ":" + ref.Tag
+	}
+
+	RecordDep(ctx, Dep{
+		Kind:   DepImage,
+		Name:   name,
+		Digest: ref.Digest,
+	})
+}
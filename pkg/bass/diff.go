@@ -0,0 +1,432 @@
+package bass
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FileSummary describes a file within a thunk path.
+type FileSummary struct {
+	Size   int64  `json:"size"`
+	Mode   int64  `json:"mode"`
+	Digest string `json:"digest"`
+}
+
+// ToValue returns the summary as a scope.
+func (sum FileSummary) ToValue() Value {
+	return Bindings{
+		"size":   Int(sum.Size),
+		"mode":   Int(sum.Mode),
+		"digest": String(sum.Digest),
+	}.Scope()
+}
+
+// Kinds of changes reported by DiffPaths.
+const (
+	FileAdded   Symbol = "added"
+	FileRemoved Symbol = "removed"
+	FileChanged Symbol = "changed"
+)
+
+// FileChange describes a file which differs between two thunk paths.
+type FileChange struct {
+	Path   string       `json:"path"`
+	Change Symbol       `json:"change"`
+	Before *FileSummary `json:"before,omitempty"`
+	After  *FileSummary `json:"after,omitempty"`
+}
+
+// ToValue returns the change as a scope.
+func (change FileChange) ToValue() Value {
+	val := Bindings{
+		"path":   String(change.Path),
+		"change": change.Change,
+	}
+
+	if change.Before != nil {
+		val["before"] = change.Before.ToValue()
+	}
+
+	if change.After != nil {
+		val["after"] = change.After.ToValue()
+	}
+
+	return val.Scope()
+}
+
+// DiffPaths compares the files within two thunk paths.
+//
+// It returns a list of scopes for each file that was added, removed, or
+// changed, sorted by path. Each scope contains the :path, the kind of
+// :change, and the :before and/or :after summary of the file.
+func DiffPaths(ctx context.Context, a, b ThunkPath) (List, error) {
+	changes, err := DiffFiles(ctx, a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	vals := make([]Value, len(changes))
+	for i, change := range changes {
+		vals[i] = change.ToValue()
+	}
+
+	return NewList(vals...), nil
+}
+
+// DiffFiles compares the files within two thunk paths, returning the changes
+// sorted by path.
+func DiffFiles(ctx context.Context, a, b ThunkPath) ([]FileChange, error) {
+	before, err := SummarizePath(ctx, a)
+	if err != nil {
+		return nil, fmt.Errorf("summarize %s: %w", a, err)
+	}
+
+	after, err := SummarizePath(ctx, b)
+	if err != nil {
+		return nil, fmt.Errorf("summarize %s: %w", b, err)
+	}
+
+	paths := map[string]struct{}{}
+	for p := range before {
+		paths[p] = struct{}{}
+	}
+	for p := range after {
+		paths[p] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	changes := []FileChange{}
+	for _, p := range sorted {
+		bsum, inBefore := before[p]
+		asum, inAfter := after[p]
+
+		change := FileChange{
+			Path: p,
+		}
+
+		switch {
+		case !inBefore:
+			change.Change = FileAdded
+			change.After = &asum
+		case !inAfter:
+			change.Change = FileRemoved
+			change.Before = &bsum
+		case bsum != asum:
+			change.Change = FileChanged
+			change.Before = &bsum
+			change.After = &asum
+		default:
+			continue
+		}
+
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// SummarizePath exports the thunk path and summarizes each file it
+// contains, keyed by its path relative to the thunk path.
+func SummarizePath(ctx context.Context, tp ThunkPath) (map[string]FileSummary, error) {
+	platform := tp.Thunk.Platform()
+	if platform == nil {
+		return nil, fmt.Errorf("cannot export bass thunk path: %s", tp)
+	}
+
+	runtime, err := RuntimeFromContext(ctx, *platform)
+	if err != nil {
+		return nil, err
+	}
+
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(runtime.ExportPath(ctx, w, tp))
+	}()
+
+	defer r.Close()
+
+	sums := map[string]FileSummary{}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, err
+		}
+
+		name := strings.TrimPrefix(path.Clean(hdr.Name), "./")
+
+		var digest string
+		switch hdr.Typeflag {
+		case tar.TypeReg:
+			h := sha256.New()
+			if _, err := io.Copy(h, tr); err != nil {
+				return nil, fmt.Errorf("digest %s: %w", name, err)
+			}
+
+			digest = fmt.Sprintf("sha256:%x", h.Sum(nil))
+		case tar.TypeSymlink, tar.TypeLink:
+			digest = fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(hdr.Linkname)))
+		default:
+			// directories and special files are only compared by their content
+			continue
+		}
+
+		sums[name] = FileSummary{
+			Size:   hdr.Size,
+			Mode:   hdr.Mode & 07777,
+			Digest: digest,
+		}
+	}
+
+	return sums, nil
+}
+
+// ValueChange describes a difference between two values.
+type ValueChange struct {
+	// Path locates the change within the values, with a keyword for each
+	// scope binding and an int for each list index. It is empty if the values
+	// differ entirely.
+	Path []Value
+
+	// Change is the kind of change: FileAdded, FileRemoved, or FileChanged.
+	Change Symbol
+
+	// Before is the prior value, or nil if it was added.
+	Before Value
+
+	// After is the new value, or nil if it was removed.
+	After Value
+}
+
+// ToValue returns the change as a scope.
+func (change ValueChange) ToValue() Value {
+	val := Bindings{
+		"path":   NewList(change.Path...),
+		"change": change.Change,
+	}
+
+	if change.Before != nil {
+		val["before"] = change.Before
+	}
+
+	if change.After != nil {
+		val["after"] = change.After
+	}
+
+	return val.Scope()
+}
+
+// PathString renders the change's path, e.g. env.FOO or args[0].
+func (change ValueChange) PathString() string {
+	var out strings.Builder
+	for _, seg := range change.Path {
+		var idx Int
+		if err := seg.Decode(&idx); err == nil {
+			fmt.Fprintf(&out, "[%d]", idx)
+			continue
+		}
+
+		var kw Keyword
+		if err := seg.Decode(&kw); err == nil {
+			if out.Len() > 0 {
+				out.WriteString(".")
+			}
+
+			out.WriteString(kw.Symbol().String())
+			continue
+		}
+
+		out.WriteString(seg.String())
+	}
+
+	return out.String()
+}
+
+// String renders the change as a single line prefixed with + for added
+// values, - for removed values, and ~ for changed values.
+func (change ValueChange) String() string {
+	prefix := "~ "
+	if path := change.PathString(); path != "" {
+		prefix += path + ": "
+	}
+
+	switch change.Change {
+	case FileAdded:
+		return "+" + prefix[1:] + change.After.String()
+	case FileRemoved:
+		return "-" + prefix[1:] + change.Before.String()
+	default:
+		return prefix + change.Before.String() + " -> " + change.After.String()
+	}
+}
+
+// DiffValues returns the structural differences between two values.
+//
+// Scopes are compared binding by binding, lists are compared element by
+// element, and thunks are compared field by field. Any other values are
+// compared with Equal. An empty result means the values are equal.
+func DiffValues(a, b Value) []ValueChange {
+	return diffValues(nil, a, b)
+}
+
+// DiffValuesList returns the differences between two values as a list of
+// scopes, each with a :path, the kind of :change, and the :before and/or
+// :after value.
+func DiffValuesList(a, b Value) List {
+	changes := DiffValues(a, b)
+
+	vals := make([]Value, len(changes))
+	for i, change := range changes {
+		vals[i] = change.ToValue()
+	}
+
+	return NewList(vals...)
+}
+
+func diffValues(path []Value, a, b Value) []ValueChange {
+	var at, bt Thunk
+	if a.Decode(&at) == nil && b.Decode(&bt) == nil {
+		as, aerr := thunkFields(at)
+		bs, berr := thunkFields(bt)
+		if aerr == nil && berr == nil {
+			return diffValues(path, as, bs)
+		}
+	}
+
+	var as, bs *Scope
+	if a.Decode(&as) == nil && b.Decode(&bs) == nil {
+		return diffScopes(path, as, bs)
+	}
+
+	if isFiniteList(a) && isFiniteList(b) {
+		avs, aerr := ToSlice(a.(List))
+		bvs, berr := ToSlice(b.(List))
+		if aerr == nil && berr == nil {
+			return diffLists(path, avs, bvs)
+		}
+	}
+
+	if a.Equal(b) {
+		return nil
+	}
+
+	return []ValueChange{{
+		Path:   path,
+		Change: FileChanged,
+		Before: a,
+		After:  b,
+	}}
+}
+
+func diffScopes(path []Value, a, b *Scope) []ValueChange {
+	var changes []ValueChange
+
+	_ = a.Each(func(k Symbol, av Value) error {
+		sub := subPath(path, k.Keyword())
+
+		bv, found := b.Get(k)
+		if !found {
+			changes = append(changes, ValueChange{
+				Path:   sub,
+				Change: FileRemoved,
+				Before: av,
+			})
+		} else {
+			changes = append(changes, diffValues(sub, av, bv)...)
+		}
+
+		return nil
+	})
+
+	_ = b.Each(func(k Symbol, bv Value) error {
+		if _, found := a.Get(k); !found {
+			changes = append(changes, ValueChange{
+				Path:   subPath(path, k.Keyword()),
+				Change: FileAdded,
+				After:  bv,
+			})
+		}
+
+		return nil
+	})
+
+	return changes
+}
+
+func diffLists(path []Value, a, b []Value) []ValueChange {
+	var changes []ValueChange
+
+	for i := 0; i < len(a) || i < len(b); i++ {
+		sub := subPath(path, Int(i))
+
+		switch {
+		case i >= len(b):
+			changes = append(changes, ValueChange{
+				Path:   sub,
+				Change: FileRemoved,
+				Before: a[i],
+			})
+		case i >= len(a):
+			changes = append(changes, ValueChange{
+				Path:   sub,
+				Change: FileAdded,
+				After:  b[i],
+			})
+		default:
+			changes = append(changes, diffValues(sub, a[i], b[i])...)
+		}
+	}
+
+	return changes
+}
+
+func subPath(path []Value, seg Value) []Value {
+	sub := make([]Value, len(path), len(path)+1)
+	copy(sub, path)
+	return append(sub, seg)
+}
+
+// isFiniteList returns true if the value is a list which can be fully
+// traversed, i.e. not a lazy sequence.
+func isFiniteList(val Value) bool {
+	switch val.(type) {
+	case Pair, Empty:
+		return true
+	default:
+		return false
+	}
+}
+
+// thunkFields returns a scope of the thunk's fields, keyed by their JSON
+// names, so that thunks can be compared field by field.
+func thunkFields(thunk Thunk) (*Scope, error) {
+	val, err := valueOfStruct(reflect.TypeOf(thunk), reflect.ValueOf(thunk))
+	if err != nil {
+		return nil, err
+	}
+
+	var scope *Scope
+	if err := val.Decode(&scope); err != nil {
+		return nil, err
+	}
+
+	return scope, nil
+}
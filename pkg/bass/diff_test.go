@@ -0,0 +1,198 @@
+package bass_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/basstest"
+	"github.com/vito/is"
+)
+
+func TestDiffPaths(t *testing.T) {
+	is := is.New(t)
+
+	baseThunk := bass.Thunk{
+		Image: &bass.ThunkImage{
+			Ref: &bass.ImageRef{
+				Platform: fakePlatform,
+			},
+		},
+		Cmd: bass.ThunkCmd{Cmd: &bass.CommandPath{"build"}},
+	}
+
+	before := bass.ThunkPath{
+		Thunk: uniq(baseThunk),
+		Path:  bass.ParseFileOrDirPath("out/"),
+	}
+
+	after := bass.ThunkPath{
+		Thunk: uniq(baseThunk),
+		Path:  bass.ParseFileOrDirPath("out/"),
+	}
+
+	ctx := withFakeRuntime(context.Background(), []ExportPath{
+		{before, fstest.MapFS{
+			"same":    {Data: []byte("same"), Mode: 0644},
+			"removed": {Data: []byte("bye"), Mode: 0644},
+			"content": {Data: []byte("old"), Mode: 0644},
+			"mode":    {Data: []byte("mode"), Mode: 0644},
+		}},
+		{after, fstest.MapFS{
+			"same":      {Data: []byte("same"), Mode: 0644},
+			"added":     {Data: []byte("hi"), Mode: 0644},
+			"content":   {Data: []byte("new"), Mode: 0644},
+			"mode":      {Data: []byte("mode"), Mode: 0755},
+			"sub/added": {Data: []byte("nested"), Mode: 0600},
+		}},
+	})
+
+	changes, err := bass.DiffPaths(ctx, before, after)
+	is.NoErr(err)
+
+	summary := func(data string, mode int) bass.Value {
+		return bass.Bindings{
+			"size":   bass.Int(len(data)),
+			"mode":   bass.Int(mode),
+			"digest": bass.String(fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(data)))),
+		}.Scope()
+	}
+
+	basstest.Equal(t, changes, bass.NewList(
+		bass.Bindings{
+			"path":   bass.String("added"),
+			"change": bass.FileAdded,
+			"after":  summary("hi", 0644),
+		}.Scope(),
+		bass.Bindings{
+			"path":   bass.String("content"),
+			"change": bass.FileChanged,
+			"before": summary("old", 0644),
+			"after":  summary("new", 0644),
+		}.Scope(),
+		bass.Bindings{
+			"path":   bass.String("mode"),
+			"change": bass.FileChanged,
+			"before": summary("mode", 0644),
+			"after":  summary("mode", 0755),
+		}.Scope(),
+		bass.Bindings{
+			"path":   bass.String("removed"),
+			"change": bass.FileRemoved,
+			"before": summary("bye", 0644),
+		}.Scope(),
+		bass.Bindings{
+			"path":   bass.String("sub/added"),
+			"change": bass.FileAdded,
+			"after":  summary("nested", 0600),
+		}.Scope(),
+	))
+
+	changes, err = bass.DiffPaths(ctx, before, before)
+	is.NoErr(err)
+	basstest.Equal(t, changes, bass.NewList())
+}
+
+func TestFileChangeCause(t *testing.T) {
+	is := is.New(t)
+
+	sum := bass.FileSummary{Size: 4, Mode: 0644, Digest: "sha256:a"}
+
+	mode := sum
+	mode.Mode = 0755
+
+	content := sum
+	content.Digest = "sha256:b"
+
+	size := content
+	size.Size = 5
+
+	is.Equal(bass.FileChange{Change: bass.FileAdded, After: &sum}.Cause(),
+		"file is only sometimes created; possibly random or time-based naming")
+	is.Equal(bass.FileChange{Change: bass.FileChanged, Before: &sum, After: &mode}.Cause(),
+		"file mode differs")
+	is.Equal(bass.FileChange{Change: bass.FileChanged, Before: &sum, After: &content}.Cause(),
+		"content differs with the same size; possibly an embedded timestamp, random value, or ordering")
+	is.Equal(bass.FileChange{Change: bass.FileChanged, Before: &sum, After: &size}.Cause(),
+		"content differs in size; possibly an embedded timestamp, random value, or ordering")
+}
+
+func TestDiffValues(t *testing.T) {
+	for _, example := range []struct {
+		Name    string
+		Bass    string
+		Changes []string
+	}{
+		{
+			Name:    "equal",
+			Bass:    `(diff {:a [1 2]} {:a [1 2]})`,
+			Changes: nil,
+		},
+		{
+			Name: "scopes",
+			Bass: `(diff {:a 1 :b 2} {:b 3 :c 4})`,
+			Changes: []string{
+				"- a: 1",
+				"~ b: 2 -> 3",
+				"+ c: 4",
+			},
+		},
+		{
+			Name: "lists",
+			Bass: `(diff [1 {:x 2} 3] [1 {:x 4}])`,
+			Changes: []string{
+				"~ [1].x: 2 -> 4",
+				"- [2]: 3",
+			},
+		},
+		{
+			Name: "different types",
+			Bass: `(diff {:a [1]} {:a "one"})`,
+			Changes: []string{
+				`~ a: (1) -> "one"`,
+			},
+		},
+		{
+			Name: "thunks",
+			Bass: `(diff (with-env ($ build a) {:FOO "1"}) (with-env ($ build b) {:FOO "2"}))`,
+			Changes: []string{
+				`~ args[0]: "a" -> "b"`,
+				`~ env.FOO: "1" -> "2"`,
+			},
+		},
+	} {
+		t.Run(example.Name, func(t *testing.T) {
+			is := is.New(t)
+
+			res, err := bass.EvalFSFile(context.Background(), bass.NewStandardScope(), bass.NewInMemoryFile("test", example.Bass))
+			is.NoErr(err)
+
+			var list bass.List
+			is.NoErr(res.Decode(&list))
+
+			changes, err := bass.ToSlice(list)
+			is.NoErr(err)
+
+			var lines []string
+			for _, change := range changes {
+				var scope *bass.Scope
+				is.NoErr(change.Decode(&scope))
+
+				var vc bass.ValueChange
+				is.NoErr(scope.GetDecode("path", &list))
+				vc.Path, err = bass.ToSlice(list)
+				is.NoErr(err)
+				is.NoErr(scope.GetDecode("change", &vc.Change))
+				vc.Before, _ = scope.Get("before")
+				vc.After, _ = scope.Get("after")
+
+				lines = append(lines, vc.String())
+			}
+
+			is.Equal(lines, example.Changes)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package bass
+
+import (
+	"context"
+	"fmt"
+)
+
+// Dynamic is a dynamically scoped variable.
+//
+// Evaluating a symbol bound to a Dynamic returns the value set by the nearest
+// enclosing WithDynamic in the context, or its default value. The value is
+// carried by the context rather than the scope, so it reaches every function
+// called within its extent without being passed as an argument.
+type Dynamic struct {
+	// Name is the symbol the variable was defined as.
+	Name Symbol
+
+	// Default is the value of the variable when it is not set in the context.
+	Default Value
+}
+
+var _ Value = (*Dynamic)(nil)
+
+// NewDynamic constructs a new dynamic variable.
+func NewDynamic(name Symbol, def Value) *Dynamic {
+	return &Dynamic{
+		Name:    name,
+		Default: def,
+	}
+}
+
+type dynamicKey struct {
+	dyn *Dynamic
+}
+
+// WithDynamic returns a context in which the variable has the given value.
+func WithDynamic(ctx context.Context, dyn *Dynamic, val Value) context.Context {
+	return context.WithValue(ctx, dynamicKey{dyn}, val)
+}
+
+// Get returns the variable's value in the context.
+func (dyn *Dynamic) Get(ctx context.Context) Value {
+	val, found := ctx.Value(dynamicKey{dyn}).(Value)
+	if !found {
+		return dyn.Default
+	}
+
+	return val
+}
+
+func (dyn *Dynamic) String() string {
+	return fmt.Sprintf("<dynamic: %s>", dyn.Name)
+}
+
+func (dyn *Dynamic) Equal(other Value) bool {
+	var o *Dynamic
+	return other.Decode(&o) == nil && dyn == o
+}
+
+func (dyn *Dynamic) Decode(dest any) error {
+	switch x := dest.(type) {
+	case **Dynamic:
+		*x = dyn
+		return nil
+	case *Value:
+		*x = dyn
+		return nil
+	default:
+		return DecodeError{
+			Source:      dyn,
+			Destination: dest,
+		}
+	}
+}
+
+func (dyn *Dynamic) MarshalJSON() ([]byte, error) {
+	return nil, EncodeError{dyn}
+}
+
+// Eval returns the variable itself; it is dereferenced when a symbol bound to
+// it is evaluated.
+func (dyn *Dynamic) Eval(_ context.Context, _ *Scope, cont Cont) ReadyCont {
+	return cont.Call(dyn, nil)
+}
+
+// derefDynamic returns the value of the variable in the context if the value
+// is a Dynamic, and otherwise returns the value as-is.
+func derefDynamic(ctx context.Context, val Value) Value {
+	switch x := val.(type) {
+	case *Dynamic:
+		return x.Get(ctx)
+	case Annotated:
+		if dyn, ok := x.Value.(*Dynamic); ok {
+			return dyn.Get(ctx)
+		}
+	}
+
+	return val
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"reflect"
 	"testing"
 
@@ -19,6 +20,9 @@ var encodable = []bass.Value{
 	bass.Bool(true),
 	bass.Bool(false),
 	bass.Int(42),
+	bass.Float(4.2),
+	bass.Float(42),
+	bass.NewBigInt(new(big.Int).Lsh(big.NewInt(42), 100)),
 	bass.NewList(
 		bass.Bool(true),
 		bass.Int(1),
@@ -72,6 +72,154 @@ func (err DecodeError) Error() string {
 	return fmt.Sprintf("cannot decode %s (%T) into %T", err.Source, err.Source, err.Destination)
 }
 
+// EnumDecodeError is returned when a value cannot be decoded into any of the
+// candidate types of an enum, such as a thunk's command or image.
+type EnumDecodeError struct {
+	Source      Value
+	Destination any
+
+	// Candidates are the types tried, in order, and the errors they failed
+	// with.
+	Candidates []DecodeCandidate
+}
+
+// DecodeCandidate is a type tried by an enum along with the error it failed
+// with.
+type DecodeCandidate struct {
+	Type string
+	Err  error
+}
+
+// Try records a candidate which failed to decode. The type is named after
+// dest, e.g. bass.FilePath.
+func (err *EnumDecodeError) Try(dest any, cerr error) {
+	err.Candidates = append(err.Candidates, DecodeCandidate{
+		Type: fmt.Sprintf("%T", dest),
+		Err:  cerr,
+	})
+}
+
+// Closest returns the candidate with the most specific mismatch, i.e. the
+// deepest one, preferring earlier candidates. A candidate whose type simply
+// did not match the value is never the closest.
+func (err EnumDecodeError) Closest() (DecodeCandidate, bool) {
+	i := err.closest()
+	if i == -1 {
+		return DecodeCandidate{}, false
+	}
+
+	return err.Candidates[i], true
+}
+
+func (err EnumDecodeError) closest() int {
+	closest := -1
+	best := 0
+	for i, c := range err.Candidates {
+		if depth := err.specificity(c.Err); depth > best {
+			closest = i
+			best = depth
+		}
+	}
+
+	return closest
+}
+
+func (err EnumDecodeError) Error() string {
+	closest, found := err.Closest()
+	if !found {
+		types := make([]string, len(err.Candidates))
+		for i, c := range err.Candidates {
+			types[i] = c.Type
+		}
+
+		return fmt.Sprintf("cannot decode %s (%T) into %T; tried %s", err.Source, err.Source, err.Destination, strings.Join(types, ", "))
+	}
+
+	return fmt.Sprintf("cannot decode %s (%T) into %T: closest match %s: %s", err.Source, err.Source, err.Destination, closest.Type, closest.Err)
+}
+
+// Unwrap returns the error of the closest candidate, if any.
+func (err EnumDecodeError) Unwrap() error {
+	closest, found := err.Closest()
+	if !found {
+		return nil
+	}
+
+	return closest.Err
+}
+
+// NiceError renders the candidates as a tree, marking the closest match.
+func (err EnumDecodeError) NiceError(w io.Writer, outer error) error {
+	fmt.Fprintln(w, aec.RedF.Apply(outer.Error()))
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "tried decoding %s (%T) as:\n", err.Source, err.Source)
+	err.writeTree(w, "")
+	return nil
+}
+
+// Tree renders the candidates as a tree, without color.
+func (err EnumDecodeError) Tree() string {
+	buf := new(strings.Builder)
+	err.writeTree(buf, "")
+	return buf.String()
+}
+
+func (err EnumDecodeError) writeTree(w io.Writer, indent string) {
+	closest := err.closest()
+
+	for i, c := range err.Candidates {
+		branch, next := "├─ ", "│  "
+		if i == len(err.Candidates)-1 {
+			branch, next = "└─ ", "   "
+		}
+
+		var nested EnumDecodeError
+		switch {
+		case errors.As(c.Err, &nested) && nested.Source.Equal(err.Source):
+			fmt.Fprintf(w, "%s%s%s\n", indent, branch, c.Type)
+			nested.writeTree(w, indent+next)
+		case err.specificity(c.Err) == 0:
+			fmt.Fprintf(w, "%s%s%s: type mismatch\n", indent, branch, c.Type)
+		default:
+			line := fmt.Sprintf("%s: %s", c.Type, c.Err)
+			if i == closest {
+				line += " (closest match)"
+			}
+
+			fmt.Fprintf(w, "%s%s%s\n", indent, branch, line)
+		}
+	}
+}
+
+// specificity returns how deep the candidate error is: 0 if the value is not
+// of the candidate's type at all, increasing with each layer of wrapping, such
+// as decoding a field of a struct.
+func (err EnumDecodeError) specificity(cerr error) int {
+	var mismatch DecodeError
+	if errors.As(cerr, &mismatch) {
+		if src, ok := mismatch.Source.(Value); ok && src.Equal(err.Source) {
+			return 0
+		}
+	}
+
+	var nested EnumDecodeError
+	if errors.As(cerr, &nested) && nested.Source.Equal(err.Source) {
+		closest, found := nested.Closest()
+		if !found {
+			return 0
+		}
+
+		return nested.specificity(closest.Err)
+	}
+
+	depth := 1
+	for e := errors.Unwrap(cerr); e != nil; e = errors.Unwrap(e) {
+		depth++
+	}
+
+	return depth
+}
+
 type UnboundError struct {
 	Symbol Symbol
 	Scope  *Scope
@@ -152,6 +300,35 @@ var ErrEndOfSource = errors.New("end of source")
 
 var ErrInterrupted = errors.New("interrupted")
 
+var ErrTooDeep = errors.New("form nested too deeply")
+
+var ErrMisplacedContract = errors.New("(pre) and (post) must lead the body of a function")
+
+var ErrDivideByZero = errors.New("division by zero")
+
+var ErrNegativeExponent = errors.New("negative exponent")
+
+var ErrExponentTooLarge = errors.New("exponent too large")
+
+var ErrNotFinite = errors.New("number is not finite")
+
+var ErrNegativeShift = errors.New("negative shift amount")
+
+var ErrNotInteger = errors.New("number is not an integer")
+
+type FrozenError struct {
+	Scope   *Scope
+	Binding Symbol
+}
+
+func (err FrozenError) Error() string {
+	if err.Scope.Name != "" {
+		return fmt.Sprintf("cannot bind %s: %s is frozen", err.Binding, err.Scope)
+	}
+
+	return fmt.Sprintf("cannot bind %s: scope is frozen", err.Binding)
+}
+
 type EncodeError struct {
 	Value Value
 }
@@ -175,6 +352,23 @@ func (err ExtendError) Error() string {
 	)
 }
 
+// PathOpError is returned when a path operation is not meaningful for the
+// given paths, e.g. taking the extension of a command path or relating paths
+// from two different thunks.
+type PathOpError struct {
+	Op    string
+	Paths []Path
+}
+
+func (err PathOpError) Error() string {
+	var paths []string
+	for _, p := range err.Paths {
+		paths = append(paths, fmt.Sprintf("%s (%T)", p, p))
+	}
+
+	return fmt.Sprintf("cannot (%s) %s", err.Op, strings.Join(paths, " "))
+}
+
 // ReadError is returned when the reader trips on a syntax token.
 type ReadError struct {
 	Err   reader.Error
@@ -240,3 +434,42 @@ type HostPathEscapeError struct {
 func (err HostPathEscapeError) Error() string {
 	return fmt.Sprintf("attempted to escape %s by opening %s", err.ContextDir, err.Attempted)
 }
+
+// MergeConflictError is returned by DeepMergeWith with MergeErrorOnConflict
+// when both scopes bind different values to the same symbol.
+type MergeConflictError struct {
+	// Path is the symbol's path through nested scopes.
+	Path []Symbol
+
+	Base     Value
+	Override Value
+}
+
+func (err MergeConflictError) Error() string {
+	path := make([]string, len(err.Path))
+	for i, sym := range err.Path {
+		path[i] = sym.String()
+	}
+
+	return fmt.Sprintf("merge conflict at %s: %s != %s", strings.Join(path, "."), err.Base, err.Override)
+}
+
+// LeakError is returned when runs are still running after they should have
+// finished, e.g. started thunks which did not stop.
+type LeakError struct {
+	Runs []string
+}
+
+func (err LeakError) Error() string {
+	return fmt.Sprintf("%d runs still running: %s", len(err.Runs), strings.Join(err.Runs, ", "))
+}
+
+// CollectLimitError is returned by (collect) when a source yields more values
+// than the given limit.
+type CollectLimitError struct {
+	Limit int
+}
+
+func (err CollectLimitError) Error() string {
+	return fmt.Sprintf("source yielded more than %d values", err.Limit)
+}
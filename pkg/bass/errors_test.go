@@ -85,3 +85,40 @@ func TestUnboundErrorNice(t *testing.T) {
 		})
 	}
 }
+
+func TestEnumDecodeErrorNice(t *testing.T) {
+	is := is.New(t)
+
+	val := bass.Bindings{
+		"platform": bass.Bindings{"os": bass.String("linux")}.Scope(),
+		"file":     bass.Bindings{"thunk": bass.Int(42)}.Scope(),
+	}.Scope()
+
+	var image bass.ThunkImage
+	err := val.Decode(&image)
+
+	var enumErr bass.EnumDecodeError
+	is.True(errors.As(err, &enumErr))
+
+	closest, found := enumErr.Closest()
+	is.True(found)
+	is.Equal(closest.Type, "bass.ImageArchive")
+
+	buf := new(bytes.Buffer)
+	is.NoErr(enumErr.NiceError(buf, fmt.Errorf("wrapped: %w", err)))
+
+	is.Equal(buf.String(), aec.RedF.Apply("wrapped: "+err.Error())+"\n"+
+		"\n"+
+		"tried decoding "+val.String()+" (*bass.Scope) as:\n"+
+		"├─ bass.ImageRef: missing key repository\n"+
+		"├─ bass.Thunk: type mismatch\n"+
+		"└─ bass.ImageArchive: "+closest.Err.Error()+" (closest match)\n")
+
+	var cmd bass.ThunkCmd
+	err = bass.Bindings{"foo": bass.Int(42)}.Scope().Decode(&cmd)
+	is.True(errors.As(err, &enumErr))
+
+	_, found = enumErr.Closest()
+	is.True(!found)
+	is.Equal(err.Error(), "cannot decode {:foo 42} (*bass.Scope) into *bass.ThunkCmd; tried bass.FilePath, bass.CommandPath, bass.ThunkPath, bass.HostPath, *bass.FSPath, bass.CachePath")
+}
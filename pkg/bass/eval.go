@@ -61,6 +61,8 @@ func EvalReader(ctx context.Context, e *Scope, r io.Reader, source Readable) (Va
 }
 
 func Trampoline(ctx context.Context, val Value) (Value, error) {
+	lim := limiterFromContext(ctx)
+
 	var err error
 	for ctx.Err() == nil {
 		cont, ok := val.(ReadyCont)
@@ -68,6 +70,12 @@ func Trampoline(ctx context.Context, val Value) (Value, error) {
 			return val, nil
 		}
 
+		if lim != nil {
+			if err := lim.step(); err != nil {
+				return nil, err
+			}
+		}
+
 		val, err = cont.Go()
 		if err != nil {
 			return nil, err
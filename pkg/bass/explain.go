@@ -0,0 +1,382 @@
+package bass
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/vito/bass/pkg/zapctx"
+	"go.uber.org/zap"
+)
+
+// ThunkHistoryDir is the directory where the canonical JSON of each thunk run
+// by a runtime is recorded, so that cache misses can be explained later.
+func ThunkHistoryDir() string {
+	return filepath.Join(CacheHome, "thunks")
+}
+
+// ThunkHistoryLimit is the number of thunks kept in the thunk history. The
+// least recently run thunks are removed to make room for new ones.
+var ThunkHistoryLimit = 1000
+
+// CanonicalJSON returns the thunk's JSON encoding with object keys sorted
+// and no insignificant whitespace.
+func (thunk Thunk) CanonicalJSON() ([]byte, error) {
+	payload, err := json.Marshal(thunk)
+	if err != nil {
+		return nil, err
+	}
+
+	var tree any
+	if err := json.Unmarshal(payload, &tree); err != nil {
+		return nil, err
+	}
+
+	// NB: encoding/json sorts map keys
+	return json.Marshal(tree)
+}
+
+// SHA256 returns the hex-encoded SHA256 digest of the thunk's canonical JSON.
+func (thunk Thunk) SHA256() (string, error) {
+	payload, err := thunk.CanonicalJSON()
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(payload)), nil
+}
+
+// RecordThunk saves the thunk's canonical JSON to the thunk history, keyed by
+// its hash, so that later runs which miss cache can be compared against it.
+//
+// Errors are logged rather than returned; the history is best-effort.
+func RecordThunk(ctx context.Context, thunk Thunk) {
+	logger := zapctx.FromContext(ctx)
+
+	hash, err := thunk.Hash()
+	if err != nil {
+		logger.Debug("failed to record thunk", zap.Error(err))
+		return
+	}
+
+	payload, err := thunk.CanonicalJSON()
+	if err != nil {
+		logger.Debug("failed to record thunk", zap.Error(err))
+		return
+	}
+
+	dir := ThunkHistoryDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		logger.Debug("failed to record thunk", zap.Error(err))
+		return
+	}
+
+	path := filepath.Join(dir, hash+".json")
+	if err := os.WriteFile(path, payload, 0600); err != nil {
+		logger.Debug("failed to record thunk", zap.Error(err))
+		return
+	}
+
+	// bump the mtime so the most recently run thunk is preferred
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	if err := trimThunkHistory(); err != nil {
+		logger.Debug("failed to trim thunk history", zap.Error(err))
+	}
+}
+
+// trimThunkHistory removes the least recently run thunks beyond
+// ThunkHistoryLimit.
+func trimThunkHistory() error {
+	entries, err := os.ReadDir(ThunkHistoryDir())
+	if err != nil {
+		return err
+	}
+
+	if len(entries) <= ThunkHistoryLimit {
+		return nil
+	}
+
+	infos := make([]fs.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			// removed concurrently
+			continue
+		}
+
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].ModTime().Before(infos[j].ModTime())
+	})
+
+	for len(infos) > ThunkHistoryLimit {
+		err := os.Remove(filepath.Join(ThunkHistoryDir(), infos[0].Name()))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		infos = infos[1:]
+	}
+
+	return nil
+}
+
+// Explanation describes a thunk and how it differs from the most recent
+// similar thunk that was run.
+type Explanation struct {
+	// JSON is the thunk's canonical JSON encoding.
+	JSON []byte
+
+	// SHA256 is the digest of the canonical JSON.
+	SHA256 string
+
+	// Hash is the thunk's hash, which keys it in the thunk history.
+	Hash string
+
+	// Cached is true if the exact thunk has been run before.
+	Cached bool
+
+	// Similar is the canonical JSON of the most recent thunk with the same
+	// image and command, or nil if none was found.
+	Similar []byte
+
+	// Changes lists the JSON fields which differ from the similar thunk.
+	Changes []FieldChange
+
+	// Diff lists the values which differ from the similar thunk, as returned
+	// by DiffValues. It is empty if the similar thunk could not be decoded.
+	Diff []ValueChange
+}
+
+// FieldChange describes a value which differs between two JSON documents.
+type FieldChange struct {
+	// Path is the location of the field, e.g. env.FOO or args[0].
+	Path string
+
+	// Before is the JSON encoding of the field's prior value, or nil if it
+	// was added.
+	Before json.RawMessage
+
+	// After is the JSON encoding of the field's new value, or nil if it was
+	// removed.
+	After json.RawMessage
+}
+
+// Explain compares the thunk against the thunk history to show why it might
+// miss cache.
+//
+// The thunk is compared with the most recently recorded thunk which has the
+// same image and command.
+func Explain(thunk Thunk) (Explanation, error) {
+	payload, err := thunk.CanonicalJSON()
+	if err != nil {
+		return Explanation{}, err
+	}
+
+	hash, err := thunk.Hash()
+	if err != nil {
+		return Explanation{}, err
+	}
+
+	exp := Explanation{
+		JSON:   payload,
+		SHA256: fmt.Sprintf("%x", sha256.Sum256(payload)),
+		Hash:   hash,
+	}
+
+	var tree map[string]any
+	if err := json.Unmarshal(payload, &tree); err != nil {
+		return Explanation{}, err
+	}
+
+	entries, err := os.ReadDir(ThunkHistoryDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return exp, nil
+		}
+
+		return Explanation{}, err
+	}
+
+	var newest time.Time
+	var similar map[string]any
+	for _, entry := range entries {
+		if filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return Explanation{}, err
+		}
+
+		if entry.Name() == exp.Hash+".json" {
+			exp.Cached = true
+			continue
+		}
+
+		if !info.ModTime().After(newest) {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(ThunkHistoryDir(), entry.Name()))
+		if err != nil {
+			return Explanation{}, err
+		}
+
+		var candidate map[string]any
+		if err := json.Unmarshal(content, &candidate); err != nil {
+			// ignore corrupt entries
+			continue
+		}
+
+		if !sameJSON(candidate["image"], tree["image"]) || !sameJSON(candidate["cmd"], tree["cmd"]) {
+			continue
+		}
+
+		newest = info.ModTime()
+		similar = candidate
+		exp.Similar = content
+	}
+
+	if similar != nil {
+		exp.Changes, err = DiffJSON("", similar, tree)
+		if err != nil {
+			return Explanation{}, err
+		}
+
+		var prev Thunk
+		if err := prev.UnmarshalJSON(exp.Similar); err == nil {
+			exp.Diff = DiffValues(prev, thunk)
+		}
+	}
+
+	return exp, nil
+}
+
+// DiffJSON returns the fields which differ between two decoded JSON values,
+// sorted by path.
+func DiffJSON(path string, before, after any) ([]FieldChange, error) {
+	var changes []FieldChange
+
+	switch b := before.(type) {
+	case map[string]any:
+		a, ok := after.(map[string]any)
+		if !ok {
+			break
+		}
+
+		keys := map[string]struct{}{}
+		for k := range b {
+			keys[k] = struct{}{}
+		}
+		for k := range a {
+			keys[k] = struct{}{}
+		}
+
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+
+		for _, k := range sorted {
+			sub := k
+			if path != "" {
+				sub = path + "." + k
+			}
+
+			bv, inBefore := b[k]
+			av, inAfter := a[k]
+
+			var change FieldChange
+			switch {
+			case !inBefore:
+				after, err := json.Marshal(av)
+				if err != nil {
+					return nil, err
+				}
+
+				change = FieldChange{Path: sub, After: after}
+			case !inAfter:
+				before, err := json.Marshal(bv)
+				if err != nil {
+					return nil, err
+				}
+
+				change = FieldChange{Path: sub, Before: before}
+			default:
+				subChanges, err := DiffJSON(sub, bv, av)
+				if err != nil {
+					return nil, err
+				}
+
+				changes = append(changes, subChanges...)
+				continue
+			}
+
+			changes = append(changes, change)
+		}
+
+		return changes, nil
+
+	case []any:
+		a, ok := after.([]any)
+		if !ok || len(a) != len(b) {
+			break
+		}
+
+		for i := range b {
+			subChanges, err := DiffJSON(path+"["+strconv.Itoa(i)+"]", b[i], a[i])
+			if err != nil {
+				return nil, err
+			}
+
+			changes = append(changes, subChanges...)
+		}
+
+		return changes, nil
+	}
+
+	if sameJSON(before, after) {
+		return nil, nil
+	}
+
+	bp, err := json.Marshal(before)
+	if err != nil {
+		return nil, err
+	}
+
+	ap, err := json.Marshal(after)
+	if err != nil {
+		return nil, err
+	}
+
+	return []FieldChange{{Path: path, Before: bp, After: ap}}, nil
+}
+
+func sameJSON(a, b any) bool {
+	ap, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+
+	bp, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(ap, bp)
+}
@@ -0,0 +1,132 @@
+package bass_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/is"
+)
+
+func TestExplain(t *testing.T) {
+	is := is.New(t)
+
+	ctx := context.Background()
+
+	oldHome := bass.CacheHome
+	bass.CacheHome = t.TempDir()
+	defer func() { bass.CacheHome = oldHome }()
+
+	thunk := bass.Thunk{
+		Cmd: bass.ThunkCmd{Cmd: &bass.CommandPath{"build"}},
+	}.WithArgs([]bass.Value{bass.String("a")})
+
+	exp, err := bass.Explain(thunk)
+	is.NoErr(err)
+	is.True(!exp.Cached)
+	is.True(exp.Similar == nil)
+
+	sha, err := thunk.SHA256()
+	is.NoErr(err)
+	is.Equal(exp.SHA256, sha)
+
+	hash, err := thunk.Hash()
+	is.NoErr(err)
+	is.Equal(exp.Hash, hash)
+
+	bass.RecordThunk(ctx, thunk)
+
+	exp, err = bass.Explain(thunk)
+	is.NoErr(err)
+	is.True(exp.Cached)
+
+	changed := thunk.
+		WithArgs([]bass.Value{bass.String("b")}).
+		WithLabel("at", bass.Int(42))
+
+	exp, err = bass.Explain(changed)
+	is.NoErr(err)
+	is.True(!exp.Cached)
+	is.True(exp.Similar != nil)
+	is.Equal(len(exp.Changes), 2)
+	is.Equal(exp.Changes[0].Path, "args[0].string.value")
+	is.Equal(string(exp.Changes[0].Before), `"a"`)
+	is.Equal(string(exp.Changes[0].After), `"b"`)
+	is.Equal(exp.Changes[1].Path, "labels")
+	is.True(exp.Changes[1].Before == nil)
+	is.Equal(len(exp.Diff), 2)
+	is.Equal(exp.Diff[0].String(), `~ args[0]: "a" -> "b"`)
+	is.Equal(exp.Diff[1].String(), `+ labels: {:at 42}`)
+
+	different := bass.Thunk{
+		Cmd: bass.ThunkCmd{Cmd: &bass.CommandPath{"test"}},
+	}
+
+	exp, err = bass.Explain(different)
+	is.NoErr(err)
+	is.True(exp.Similar == nil)
+}
+
+func TestRecordThunkLimit(t *testing.T) {
+	is := is.New(t)
+
+	ctx := context.Background()
+
+	oldHome := bass.CacheHome
+	bass.CacheHome = t.TempDir()
+	defer func() { bass.CacheHome = oldHome }()
+
+	oldLimit := bass.ThunkHistoryLimit
+	bass.ThunkHistoryLimit = 2
+	defer func() { bass.ThunkHistoryLimit = oldLimit }()
+
+	thunk := func(arg string) bass.Thunk {
+		return bass.Thunk{
+			Cmd: bass.ThunkCmd{Cmd: &bass.CommandPath{"build"}},
+		}.WithArgs([]bass.Value{bass.String(arg)})
+	}
+
+	for i, arg := range []string{"a", "b", "c"} {
+		bass.RecordThunk(ctx, thunk(arg))
+
+		// ensure distinct mtimes
+		hash, err := thunk(arg).Hash()
+		is.NoErr(err)
+		at := time.Now().Add(time.Duration(i) * time.Second)
+		is.NoErr(os.Chtimes(filepath.Join(bass.ThunkHistoryDir(), hash+".json"), at, at))
+	}
+
+	entries, err := os.ReadDir(bass.ThunkHistoryDir())
+	is.NoErr(err)
+	is.Equal(len(entries), 2)
+
+	// the least recently run thunk is removed
+	exp, err := bass.Explain(thunk("a"))
+	is.NoErr(err)
+	is.True(!exp.Cached)
+
+	exp, err = bass.Explain(thunk("c"))
+	is.NoErr(err)
+	is.True(exp.Cached)
+}
+
+func TestDiffJSON(t *testing.T) {
+	is := is.New(t)
+
+	var before, after any
+	is.NoErr(json.Unmarshal([]byte(`{"a":1,"b":[1,2],"c":{"d":true},"e":"gone"}`), &before))
+	is.NoErr(json.Unmarshal([]byte(`{"a":1,"b":[1,3],"c":{"d":false},"f":"new"}`), &after))
+
+	changes, err := bass.DiffJSON("", before, after)
+	is.NoErr(err)
+	is.Equal(changes, []bass.FieldChange{
+		{Path: "b[1]", Before: json.RawMessage(`2`), After: json.RawMessage(`3`)},
+		{Path: "c.d", Before: json.RawMessage(`true`), After: json.RawMessage(`false`)},
+		{Path: "e", Before: json.RawMessage(`"gone"`)},
+		{Path: "f", After: json.RawMessage(`"new"`)},
+	})
+}
@@ -13,6 +13,8 @@ import (
 
 type FakeRuntime struct {
 	ExportPaths []ExportPath
+
+	Interacted []bass.Thunk
 }
 
 type ExportPath struct {
@@ -93,6 +95,12 @@ func (fake *FakeRuntime) ExportPath(ctx context.Context, w io.Writer, path bass.
 	return fmt.Errorf("thunk path not faked out: %s", path)
 }
 
+func (fake *FakeRuntime) Interact(ctx context.Context, thunk bass.Thunk, tio bass.InteractiveIO) error {
+	fake.Interacted = append(fake.Interacted, thunk)
+	_, err := fmt.Fprintf(tio.Stdout, "interacted with %s", thunk.Cmdline())
+	return err
+}
+
 func (fake *FakeRuntime) Prune(context.Context, bass.PruneOpts) error {
 	return fmt.Errorf("Prune unimplemented")
 }
@@ -0,0 +1,143 @@
+package bass
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/vito/bass/pkg/zapctx"
+	"go.uber.org/zap"
+)
+
+// FlakeRecord records the run of a thunk with retries.
+type FlakeRecord struct {
+	// Thunk is the SHA256 digest of the thunk.
+	Thunk string `json:"thunk"`
+
+	// Name is the thunk's name.
+	Name string `json:"name"`
+
+	// Attempts is the number of times the thunk was run.
+	Attempts int `json:"attempts"`
+
+	// Passed is true if any attempt succeeded.
+	Passed bool `json:"passed"`
+}
+
+// Flaked returns true if the thunk only passed on retry.
+func (record FlakeRecord) Flaked() bool {
+	return record.Passed && record.Attempts > 1
+}
+
+// FlakeStat summarizes the flake records of a thunk across runs.
+type FlakeStat struct {
+	// Thunk is the SHA256 digest of the thunk.
+	Thunk string
+
+	// Name is the thunk's name.
+	Name string
+
+	// Runs is the number of runs of the thunk.
+	Runs int
+
+	// Flakes is the number of runs which only passed on retry.
+	Flakes int
+
+	// Failures is the number of runs in which every attempt failed.
+	Failures int
+}
+
+// FlakeStats summarizes the flake records in the run history, with the
+// flakiest thunks first.
+func FlakeStats(records []RunRecord) []FlakeStat {
+	stats := map[string]*FlakeStat{}
+	for _, record := range records {
+		for _, flake := range record.Flakes {
+			stat, found := stats[flake.Thunk]
+			if !found {
+				stat = &FlakeStat{
+					Thunk: flake.Thunk,
+					Name:  flake.Name,
+				}
+
+				stats[flake.Thunk] = stat
+			}
+
+			stat.Runs++
+
+			if flake.Flaked() {
+				stat.Flakes++
+			}
+
+			if !flake.Passed {
+				stat.Failures++
+			}
+		}
+	}
+
+	sorted := make([]FlakeStat, 0, len(stats))
+	for _, stat := range stats {
+		sorted = append(sorted, *stat)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Flakes != b.Flakes {
+			return a.Flakes > b.Flakes
+		}
+
+		return a.Name < b.Name
+	})
+
+	return sorted
+}
+
+// runWithRetries calls run, retrying up to the thunk's Retries if it fails,
+// and records the outcome in the run history.
+func runWithRetries(ctx context.Context, thunk Thunk, run func() error) error {
+	if thunk.Retries <= 0 {
+		return run()
+	}
+
+	logger := zapctx.FromContext(ctx)
+
+	record := FlakeRecord{
+		Name: thunk.String(),
+	}
+
+	if digest, err := thunk.SHA256(); err == nil {
+		record.Thunk = digest
+	}
+
+	var err error
+	for record.Attempts <= thunk.Retries {
+		record.Attempts++
+
+		err = run()
+		if err == nil {
+			record.Passed = true
+			break
+		}
+
+		if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+			break
+		}
+
+		if record.Attempts <= thunk.Retries {
+			logger.Warn("retrying flaky thunk",
+				zap.String("thunk", record.Name),
+				zap.Int("attempt", record.Attempts),
+				zap.Error(err))
+		}
+	}
+
+	if record.Flaked() {
+		logger.Warn("flaky thunk passed on retry",
+			zap.String("thunk", record.Name),
+			zap.Int("attempts", record.Attempts))
+	}
+
+	noteFlake(ctx, record)
+
+	return err
+}
@@ -0,0 +1,91 @@
+package bass_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/runtimes"
+	"github.com/vito/is"
+)
+
+type flakyRuntime struct {
+	FakeRuntime
+
+	runs     map[string]int
+	failures map[string]int
+}
+
+func (fake *flakyRuntime) Run(ctx context.Context, thunk bass.Thunk) error {
+	name := thunk.Cmd.ToValue().String()
+	fake.runs[name]++
+
+	if fake.runs[name] <= fake.failures[name] {
+		return fmt.Errorf("%s failed", name)
+	}
+
+	return nil
+}
+
+func TestWithRetries(t *testing.T) {
+	is := is.New(t)
+
+	fake := &flakyRuntime{
+		runs: map[string]int{},
+		failures: map[string]int{
+			".flaky":  2,
+			".broken": 10,
+		},
+	}
+
+	ctx := bass.WithRuntimePool(context.Background(), &runtimes.Pool{
+		Runtimes: []runtimes.Assoc{
+			{
+				Platform: bass.LinuxPlatform,
+				Runtime:  fake,
+			},
+		},
+	})
+
+	ctx, history := bass.TrackHistory(ctx)
+
+	eval := func(src string) (bass.Value, error) {
+		return bass.EvalFSFile(ctx, bass.NewStandardScope(), bass.NewInMemoryFile("test", `
+			(def image {:platform {:os "linux"} :repository "alpine" :tag "latest"})
+		`+src))
+	}
+
+	_, err := eval(`(run (with-retries (with-image (.flaky) image) 2))`)
+	is.NoErr(err)
+	is.Equal(fake.runs[".flaky"], 3)
+
+	_, err = eval(`(run (with-retries (with-image (.broken) image) 1))`)
+	is.True(err != nil)
+	is.Equal(fake.runs[".broken"], 2)
+
+	_, err = eval(`(run (with-image (.stable) image))`)
+	is.NoErr(err)
+	is.Equal(fake.runs[".stable"], 1)
+
+	res, err := eval(`(= (with-image (.x) image) (with-retries (with-image (.x) image) 3))`)
+	is.NoErr(err)
+	is.Equal(res, bass.Bool(true))
+
+	flakes := history.Flakes()
+	is.Equal(len(flakes), 2)
+	is.Equal(flakes[0].Attempts, 3)
+	is.True(flakes[0].Flaked())
+	is.Equal(flakes[1].Attempts, 2)
+	is.True(!flakes[1].Passed)
+
+	stats := bass.FlakeStats([]bass.RunRecord{
+		{Flakes: flakes},
+		{Flakes: []bass.FlakeRecord{{Thunk: flakes[0].Thunk, Name: flakes[0].Name, Attempts: 1, Passed: true}}},
+	})
+	is.Equal(len(stats), 2)
+	is.Equal(stats[0].Thunk, flakes[0].Thunk)
+	is.Equal(stats[0].Runs, 2)
+	is.Equal(stats[0].Flakes, 1)
+	is.Equal(stats[1].Failures, 1)
+}
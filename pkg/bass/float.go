@@ -0,0 +1,81 @@
+package bass
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Float is a floating-point number.
+type Float float64
+
+// String formats the float so that it reads back as a Float, i.e. always
+// with a decimal point or exponent.
+func (value Float) String() string {
+	str := strconv.FormatFloat(float64(value), 'g', -1, 64)
+	if !strings.ContainsAny(str, ".eIN") {
+		str += ".0"
+	}
+
+	return str
+}
+
+func (value Float) Equal(other Value) bool {
+	var o Float
+	return other.Decode(&o) == nil && value == o
+}
+
+func (value Float) Decode(dest any) error {
+	switch x := dest.(type) {
+	case *Float:
+		*x = value
+		return nil
+	case *Number:
+		*x = value
+		return nil
+	case *Value:
+		*x = value
+		return nil
+	case *Bindable:
+		*x = value
+		return nil
+	case *float64:
+		*x = float64(value)
+		return nil
+	default:
+		return DecodeError{
+			Source:      value,
+			Destination: dest,
+		}
+	}
+}
+
+// MarshalJSON encodes the float so that it decodes back as a Float.
+func (value Float) MarshalJSON() ([]byte, error) {
+	if math.IsNaN(float64(value)) || math.IsInf(float64(value), 0) {
+		return nil, EncodeError{value}
+	}
+
+	return []byte(value.String()), nil
+}
+
+// Eval returns the value.
+func (value Float) Eval(_ context.Context, _ *Scope, cont Cont) ReadyCont {
+	return cont.Call(value, nil)
+}
+
+// Float64 returns the float as a float64.
+func (value Float) Float64() float64 {
+	return float64(value)
+}
+
+var _ Bindable = Float(0)
+
+func (binding Float) Bind(_ context.Context, _ *Scope, cont Cont, val Value, _ ...Annotated) ReadyCont {
+	return cont.Call(binding, BindConst(binding, val))
+}
+
+func (Float) EachBinding(func(Symbol, Range) error) error {
+	return nil
+}
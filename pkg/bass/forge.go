@@ -0,0 +1,736 @@
+package bass
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// CredentialKindForge is a token for a code forge's API, keyed by its host,
+// e.g. api.github.com or gitlab.com.
+const CredentialKindForge = "forge"
+
+// Code forges supported by the forge builtins.
+const (
+	ForgeGitHub Symbol = "github"
+	ForgeGitLab Symbol = "gitlab"
+)
+
+// ForgeEndpoints are the default API endpoints for each forge.
+var ForgeEndpoints = map[Symbol]string{
+	ForgeGitHub: "https://api.github.com",
+	ForgeGitLab: "https://gitlab.com/api/v4",
+}
+
+func init() {
+	Ground.MustSet("forge-release",
+		Func("forge-release", "[repo tag & opts]", func(ctx context.Context, repo ForgeRepo, tag string, opts ...ForgeReleaseOpts) (*Scope, error) {
+			client, err := NewForgeClient(ctx, repo)
+			if err != nil {
+				return nil, err
+			}
+
+			var o ForgeReleaseOpts
+			if len(opts) > 0 {
+				o = opts[0]
+			}
+
+			release, err := client.CreateRelease(ctx, tag, o)
+			if err != nil {
+				return nil, err
+			}
+
+			return release.Scope(), nil
+		}),
+		`creates a release for a tag in a GitHub or GitLab repository`,
+		`The repo is either an "owner/name" string for a GitHub repository or a scope containing the :repo, the :provider, either :github or :gitlab, an optional API :endpoint for self-hosted forges, and an optional :token, typically a secret.`,
+		`If no token is given, it is requested from the credential helper as "get forge <endpoint host>".`,
+		`Options may be given as a scope: :name and :body describe the release, :target is the commit to tag if the tag does not exist yet, and :draft and :prerelease are flags supported by GitHub.`,
+		`Returns a scope describing the release, which can be passed to (forge-upload).`,
+		`=> (forge-release {:repo "vito/bass" :token (secret :github-token)} "v1.0.0" {:name "v1.0.0" :body "The first release."})`)
+
+	Ground.MustSet("forge-releases",
+		Func("forge-releases", "[repo]", func(ctx context.Context, repo ForgeRepo) (List, error) {
+			client, err := NewForgeClient(ctx, repo)
+			if err != nil {
+				return nil, err
+			}
+
+			releases, err := client.Releases(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			vals := make([]Value, len(releases))
+			for i, release := range releases {
+				vals[i] = release.Scope()
+			}
+
+			return NewList(vals...), nil
+		}),
+		`lists every release in a GitHub or GitLab repository`,
+		`Takes the same repo as (forge-release). All pages of releases are fetched.`,
+		`=> (forge-releases "vito/bass")`)
+
+	Ground.MustSet("forge-upload",
+		Func("forge-upload", "[repo release asset & opts]", func(ctx context.Context, repo ForgeRepo, release ForgeRelease, asset Readable, opts ...ForgeAssetOpts) (*Scope, error) {
+			client, err := NewForgeClient(ctx, repo)
+			if err != nil {
+				return nil, err
+			}
+
+			var o ForgeAssetOpts
+			if len(opts) > 0 {
+				o = opts[0]
+			}
+
+			if o.Name == "" {
+				path, ok := asset.(Path)
+				if !ok {
+					return nil, fmt.Errorf("forge-upload: no :name given for %s", asset)
+				}
+
+				o.Name = path.Name()
+			}
+
+			if o.ContentType == "" {
+				o.ContentType = "application/octet-stream"
+			}
+
+			rc, err := asset.Open(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			defer rc.Close()
+
+			assetURL, err := client.UploadAsset(ctx, release, o, rc, readableSize(rc))
+			if err != nil {
+				return nil, err
+			}
+
+			return Bindings{
+				"name": String(o.Name),
+				"url":  String(assetURL),
+			}.Scope(), nil
+		}),
+		`uploads an asset to a release in a GitHub or GitLab repository`,
+		`Takes the same repo as (forge-release), a release returned by it or by (forge-releases), and a readable path to upload, like a thunk path or host path.`,
+		`Options may be given as a scope: :name overrides the asset name, which defaults to the name of the path, and :content_type sets its Content-Type.`,
+		`Returns a scope containing the :name and download :url of the asset.`,
+		`=> (forge-upload repo (forge-release repo "v1.0.0") built/app.tgz)`)
+
+	Ground.MustSet("forge-comment",
+		Func("forge-comment", "[repo number body]", func(ctx context.Context, repo ForgeRepo, number int, body string) (*Scope, error) {
+			client, err := NewForgeClient(ctx, repo)
+			if err != nil {
+				return nil, err
+			}
+
+			return client.Comment(ctx, number, body)
+		}),
+		`comments on a pull request in a GitHub repository or a merge request in a GitLab repository`,
+		`Takes the same repo as (forge-release) and the number of the pull request or merge request.`,
+		`Returns a scope containing the comment's :id and :url.`,
+		`=> (forge-comment "vito/bass" 123 "Benchmarks look good!")`)
+
+	Ground.MustSet("forge-status",
+		Func("forge-status", "[repo sha state & opts]", func(ctx context.Context, repo ForgeRepo, sha string, state Symbol, opts ...ForgeStatusOpts) error {
+			client, err := NewForgeClient(ctx, repo)
+			if err != nil {
+				return err
+			}
+
+			var o ForgeStatusOpts
+			if len(opts) > 0 {
+				o = opts[0]
+			}
+
+			return client.SetStatus(ctx, sha, state, o)
+		}),
+		`sets the status of a commit in a GitHub or GitLab repository`,
+		`Takes the same repo as (forge-release). The state is one of :pending, :success, :failure, or :error, which are translated to their GitLab equivalents.`,
+		`Options may be given as a scope: :context names the status, defaulting to "bass", :description summarizes it, and :target_url links to details.`,
+		`=> (forge-status "vito/bass" "4f1a2c3" :success {:context "ci/test" :description "All tests passed."})`)
+}
+
+// ForgeRepo is a repository targeted by the forge builtins.
+//
+// It may be specified either as an "owner/name" string for a GitHub
+// repository or as a scope with the following fields.
+type ForgeRepo struct {
+	// Repo is the repository's "owner/name" on GitHub, or its project path on
+	// GitLab, e.g. "group/subgroup/name".
+	Repo string `json:"repo"`
+
+	// Provider is the forge hosting the repository, defaulting to GitHub.
+	Provider Symbol `json:"provider,omitempty"`
+
+	// Endpoint is the URL of the forge's API, for self-hosted forges.
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Token authenticates requests. It may be a string or a Secret.
+	Token Value `json:"token,omitempty"`
+}
+
+var _ Decodable = &ForgeRepo{}
+
+func (repo *ForgeRepo) FromValue(val Value) error {
+	var str string
+	if err := val.Decode(&str); err == nil {
+		repo.Repo = str
+		return nil
+	}
+
+	var scope *Scope
+	if err := val.Decode(&scope); err != nil {
+		return fmt.Errorf("repo must be a string or a scope: %w", err)
+	}
+
+	return decodeStruct(scope, repo)
+}
+
+// ForgeReleaseOpts are the options accepted by (forge-release).
+type ForgeReleaseOpts struct {
+	Name       string `json:"name,omitempty"`
+	Body       string `json:"body,omitempty"`
+	Target     string `json:"target,omitempty"`
+	Draft      bool   `json:"draft,omitempty"`
+	Prerelease bool   `json:"prerelease,omitempty"`
+}
+
+// ForgeAssetOpts are the options accepted by (forge-upload).
+type ForgeAssetOpts struct {
+	Name        string `json:"name,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+}
+
+// ForgeStatusOpts are the options accepted by (forge-status).
+type ForgeStatusOpts struct {
+	Context     string `json:"context,omitempty"`
+	Description string `json:"description,omitempty"`
+	TargetURL   string `json:"target_url,omitempty"`
+}
+
+// ForgeRelease is a release in a repository.
+type ForgeRelease struct {
+	// ID is the release's ID. GitLab releases are identified by their tag
+	// instead.
+	ID int `json:"id,omitempty"`
+
+	// Tag is the name of the release's tag.
+	Tag string `json:"tag"`
+
+	// Name is the release's title.
+	Name string `json:"name,omitempty"`
+
+	// URL is the release's web page.
+	URL string `json:"url,omitempty"`
+
+	// UploadURL is the URL to upload GitHub release assets to.
+	UploadURL string `json:"upload_url,omitempty"`
+}
+
+// Scope returns the release as a scope.
+func (release ForgeRelease) Scope() *Scope {
+	scope := Bindings{
+		"tag":  String(release.Tag),
+		"name": String(release.Name),
+		"url":  String(release.URL),
+	}.Scope()
+
+	if release.ID != 0 {
+		scope.Set("id", Int(release.ID))
+	}
+
+	if release.UploadURL != "" {
+		scope.Set("upload_url", String(release.UploadURL))
+	}
+
+	return scope
+}
+
+var _ Decodable = &ForgeRelease{}
+
+func (release *ForgeRelease) FromValue(val Value) error {
+	var scope *Scope
+	if err := val.Decode(&scope); err != nil {
+		return fmt.Errorf("release must be a scope: %w", err)
+	}
+
+	return decodeStruct(scope, release)
+}
+
+// ForgeClient is a minimal client for the GitHub and GitLab APIs.
+type ForgeClient struct {
+	Provider Symbol
+	Endpoint string
+	Repo     string
+	Token    string
+
+	http *http.Client
+}
+
+// NewForgeClient initializes a client, resolving the token from the repo or
+// from the credential helper.
+func NewForgeClient(ctx context.Context, repo ForgeRepo) (*ForgeClient, error) {
+	provider := repo.Provider
+	if provider == "" {
+		provider = ForgeGitHub
+	}
+
+	endpoint := repo.Endpoint
+	if endpoint == "" {
+		var found bool
+		endpoint, found = ForgeEndpoints[provider]
+		if !found {
+			return nil, fmt.Errorf("unknown forge provider: %s", provider)
+		}
+	}
+
+	endpoint = strings.TrimSuffix(endpoint, "/")
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("forge endpoint: %w", err)
+	}
+
+	var token string
+	if repo.Token != nil {
+		token, err = revealString(repo.Token)
+		if err != nil {
+			return nil, fmt.Errorf("token: %w", err)
+		}
+	} else {
+		helper, found := CredentialHelperFromContext(ctx)
+		if !found {
+			return nil, fmt.Errorf("no forge token given: %w", ErrNoCredentialHelper)
+		}
+
+		cred, found, err := helper.Get(ctx, CredentialKindForge, u.Host)
+		if err != nil {
+			return nil, err
+		}
+
+		if !found {
+			return nil, CredentialNotFoundError{
+				Kind: CredentialKindForge,
+				Key:  u.Host,
+			}
+		}
+
+		token = cred.Secret
+	}
+
+	network, _ := NetworkConfigFromContext(ctx)
+	httpClient, err := network.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ForgeClient{
+		Provider: provider,
+		Endpoint: endpoint,
+		Repo:     repo.Repo,
+		Token:    token,
+
+		http: httpClient,
+	}, nil
+}
+
+// CreateRelease creates a release for the tag.
+func (client *ForgeClient) CreateRelease(ctx context.Context, tag string, opts ForgeReleaseOpts) (ForgeRelease, error) {
+	var payload map[string]any
+	if client.Provider == ForgeGitLab {
+		payload = map[string]any{
+			"tag_name":    tag,
+			"name":        opts.Name,
+			"description": opts.Body,
+		}
+
+		if opts.Target != "" {
+			payload["ref"] = opts.Target
+		}
+	} else {
+		payload = map[string]any{
+			"tag_name":   tag,
+			"name":       opts.Name,
+			"body":       opts.Body,
+			"draft":      opts.Draft,
+			"prerelease": opts.Prerelease,
+		}
+
+		if opts.Target != "" {
+			payload["target_commitish"] = opts.Target
+		}
+	}
+
+	var res forgeRelease
+	_, err := client.requestJSON(ctx, http.MethodPost, client.repoPath("/releases"), payload, &res)
+	if err != nil {
+		return ForgeRelease{}, fmt.Errorf("create release %s: %w", tag, err)
+	}
+
+	return res.release(), nil
+}
+
+// Releases lists every release, following pagination links until there are
+// no more pages.
+func (client *ForgeClient) Releases(ctx context.Context) ([]ForgeRelease, error) {
+	var releases []ForgeRelease
+
+	next := client.repoPath("/releases?per_page=100")
+	for next != "" {
+		var page []forgeRelease
+		header, err := client.requestJSON(ctx, http.MethodGet, next, nil, &page)
+		if err != nil {
+			return nil, fmt.Errorf("list releases: %w", err)
+		}
+
+		for _, res := range page {
+			releases = append(releases, res.release())
+		}
+
+		next = forgeNextLink(header.Get("Link"))
+	}
+
+	return releases, nil
+}
+
+// UploadAsset uploads an asset to the release and returns its download URL.
+//
+// The content is streamed from the reader. Its size should be given if it is
+// known, or -1 otherwise. GitHub requires the size up front, so content of
+// unknown size is first spooled to a temporary file.
+func (client *ForgeClient) UploadAsset(ctx context.Context, release ForgeRelease, opts ForgeAssetOpts, content io.Reader, size int64) (string, error) {
+	if client.Provider == ForgeGitLab {
+		return client.uploadGitLabAsset(ctx, release, opts, content)
+	}
+
+	if release.UploadURL == "" {
+		return "", fmt.Errorf("upload %s: release %s has no upload_url", opts.Name, release.Tag)
+	}
+
+	if size < 0 {
+		spool, err := os.CreateTemp("", "bass-forge-upload.*")
+		if err != nil {
+			return "", err
+		}
+
+		defer os.Remove(spool.Name())
+		defer spool.Close()
+
+		size, err = io.Copy(spool, content)
+		if err != nil {
+			return "", fmt.Errorf("upload %s: %w", opts.Name, err)
+		}
+
+		if _, err := spool.Seek(0, io.SeekStart); err != nil {
+			return "", err
+		}
+
+		content = spool
+	}
+
+	// NB: the upload URL is a URI template, e.g. ".../assets{?name,label}"
+	uploadURL, _, _ := strings.Cut(release.UploadURL, "{")
+	uploadURL += "?" + url.Values{"name": {opts.Name}}.Encode()
+
+	var res struct {
+		BrowserDownloadURL string `json:"browser_download_url"`
+	}
+	_, err := client.request(ctx, http.MethodPost, uploadURL, opts.ContentType, sizedBody{content, size}, &res)
+	if err != nil {
+		return "", fmt.Errorf("upload %s: %w", opts.Name, err)
+	}
+
+	return res.BrowserDownloadURL, nil
+}
+
+// uploadGitLabAsset uploads the asset to the project and links it to the
+// release, since GitLab releases do not store assets themselves.
+//
+// The multipart form is streamed through a pipe as it is sent.
+func (client *ForgeClient) uploadGitLabAsset(ctx context.Context, release ForgeRelease, opts ForgeAssetOpts, content io.Reader) (string, error) {
+	body, w := io.Pipe()
+	form := multipart.NewWriter(w)
+
+	go func() {
+		w.CloseWithError(writeFormFile(form, "file", opts.Name, content))
+	}()
+
+	var upload struct {
+		FullPath string `json:"full_path"`
+	}
+	_, err := client.request(ctx, http.MethodPost, client.repoPath("/uploads"), form.FormDataContentType(), body, &upload)
+	body.Close()
+	if err != nil {
+		return "", fmt.Errorf("upload %s: %w", opts.Name, err)
+	}
+
+	var link struct {
+		URL string `json:"url"`
+	}
+	_, err = client.requestJSON(ctx, http.MethodPost, client.repoPath("/releases/"+url.PathEscape(release.Tag)+"/assets/links"), map[string]any{
+		"name": opts.Name,
+		"url":  client.webURL() + upload.FullPath,
+	}, &link)
+	if err != nil {
+		return "", fmt.Errorf("link %s to release %s: %w", opts.Name, release.Tag, err)
+	}
+
+	return link.URL, nil
+}
+
+// writeFormFile writes a multipart form containing only the file.
+func writeFormFile(form *multipart.Writer, field, name string, content io.Reader) error {
+	part, err := form.CreateFormFile(field, name)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(part, content); err != nil {
+		return err
+	}
+
+	return form.Close()
+}
+
+// sizedBody is a request body whose size is known up front.
+type sizedBody struct {
+	io.Reader
+	size int64
+}
+
+// readableSize returns the size of a file opened from a Readable, or -1 if it
+// is not known, e.g. for a thunk's output stream.
+func readableSize(r io.Reader) int64 {
+	file, ok := r.(interface{ Stat() (fs.FileInfo, error) })
+	if !ok {
+		return -1
+	}
+
+	info, err := file.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		return -1
+	}
+
+	return info.Size()
+}
+
+// Comment comments on a pull request or merge request.
+func (client *ForgeClient) Comment(ctx context.Context, number int, body string) (*Scope, error) {
+	var res struct {
+		ID      int    `json:"id"`
+		HTMLURL string `json:"html_url"`
+	}
+
+	path := client.repoPath("/issues/" + strconv.Itoa(number) + "/comments")
+	if client.Provider == ForgeGitLab {
+		path = client.repoPath("/merge_requests/" + strconv.Itoa(number) + "/notes")
+	}
+
+	_, err := client.requestJSON(ctx, http.MethodPost, path, map[string]any{"body": body}, &res)
+	if err != nil {
+		return nil, fmt.Errorf("comment on #%d: %w", number, err)
+	}
+
+	commentURL := res.HTMLURL
+	if client.Provider == ForgeGitLab {
+		commentURL = fmt.Sprintf("%s/%s/-/merge_requests/%d#note_%d", client.webURL(), client.Repo, number, res.ID)
+	}
+
+	return Bindings{
+		"id":  Int(res.ID),
+		"url": String(commentURL),
+	}.Scope(), nil
+}
+
+// SetStatus sets the status of a commit.
+//
+// The state is one of GitHub's states: pending, success, failure, or error.
+func (client *ForgeClient) SetStatus(ctx context.Context, sha string, state Symbol, opts ForgeStatusOpts) error {
+	switch state {
+	case "pending", "success", "failure", "error":
+	default:
+		return fmt.Errorf("invalid commit state: %s", state)
+	}
+
+	name := opts.Context
+	if name == "" {
+		name = "bass"
+	}
+
+	payload := map[string]any{
+		"state":       string(state),
+		"description": opts.Description,
+		"target_url":  opts.TargetURL,
+	}
+
+	if client.Provider == ForgeGitLab {
+		if state == "failure" || state == "error" {
+			payload["state"] = "failed"
+		}
+
+		payload["name"] = name
+	} else {
+		payload["context"] = name
+	}
+
+	_, err := client.requestJSON(ctx, http.MethodPost, client.repoPath("/statuses/"+sha), payload, nil)
+	if err != nil {
+		return fmt.Errorf("set status of %s: %w", sha, err)
+	}
+
+	return nil
+}
+
+// repoPath returns the API path for the repository's sub-resource.
+func (client *ForgeClient) repoPath(path string) string {
+	if client.Provider == ForgeGitLab {
+		return "/projects/" + url.PathEscape(client.Repo) + path
+	}
+
+	return "/repos/" + client.Repo + path
+}
+
+// webURL returns the URL of the GitLab web UI that serves the API.
+func (client *ForgeClient) webURL() string {
+	return strings.TrimSuffix(client.Endpoint, "/api/v4")
+}
+
+func (client *ForgeClient) requestJSON(ctx context.Context, method, path string, payload any, dest any) (http.Header, error) {
+	var body io.Reader
+	var contentType string
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+
+		body = bytes.NewReader(encoded)
+		contentType = "application/json"
+	}
+
+	return client.request(ctx, method, path, contentType, body, dest)
+}
+
+// request sends a request to the API, decoding the JSON response into dest
+// if it is non-nil. The path may also be an absolute URL, e.g. a pagination
+// link.
+func (client *ForgeClient) request(ctx context.Context, method, path string, contentType string, body io.Reader, dest any) (http.Header, error) {
+	u := path
+	if strings.HasPrefix(path, "/") {
+		u = client.Endpoint + path
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if sized, ok := body.(sizedBody); ok {
+		req.ContentLength = sized.size
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	if client.Provider == ForgeGitLab {
+		req.Header.Set("PRIVATE-TOKEN", client.Token)
+	} else {
+		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("Authorization", "Bearer "+client.Token)
+	}
+
+	res, err := client.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		var status struct {
+			Message json.RawMessage `json:"message"`
+		}
+		_ = json.NewDecoder(res.Body).Decode(&status)
+		if len(status.Message) == 0 {
+			return nil, fmt.Errorf("%s %s: %s", method, req.URL.Path, res.Status)
+		}
+
+		// NB: GitLab validation errors are objects rather than strings
+		var msg string
+		if err := json.Unmarshal(status.Message, &msg); err != nil {
+			msg = string(status.Message)
+		}
+
+		return nil, fmt.Errorf("%s %s: %s: %s", method, req.URL.Path, res.Status, msg)
+	}
+
+	if dest != nil {
+		if err := json.NewDecoder(res.Body).Decode(dest); err != nil {
+			return nil, fmt.Errorf("decode response: %w", err)
+		}
+	}
+
+	return res.Header, nil
+}
+
+// forgeRelease is a release as returned by either the GitHub or GitLab API.
+type forgeRelease struct {
+	ID        int    `json:"id"`
+	TagName   string `json:"tag_name"`
+	Name      string `json:"name"`
+	HTMLURL   string `json:"html_url"`
+	UploadURL string `json:"upload_url"`
+
+	Links struct {
+		Self string `json:"self"`
+	} `json:"_links"`
+}
+
+func (res forgeRelease) release() ForgeRelease {
+	release := ForgeRelease{
+		ID:        res.ID,
+		Tag:       res.TagName,
+		Name:      res.Name,
+		URL:       res.HTMLURL,
+		UploadURL: res.UploadURL,
+	}
+
+	if release.URL == "" {
+		release.URL = res.Links.Self
+	}
+
+	return release
+}
+
+// forgeNextLink returns the URL of the next page from a Link header, or ""
+// if there is none.
+func forgeNextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		target, params, found := strings.Cut(link, ";")
+		if !found {
+			continue
+		}
+
+		for _, param := range strings.Split(params, ";") {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(target), "<>")
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,218 @@
+package bass_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	. "github.com/vito/bass/pkg/basstest"
+	"github.com/vito/is"
+)
+
+func TestForgeGitHub(t *testing.T) {
+	is := is.New(t)
+
+	var uploaded, commented string
+	var status map[string]any
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprintln(w, `{"message":"Bad credentials"}`)
+			return
+		}
+
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/repos/vito/bass/releases":
+			var payload map[string]any
+			is.NoErr(json.NewDecoder(r.Body).Decode(&payload))
+			is.Equal(payload["tag_name"], "v1.0.0")
+			is.Equal(payload["prerelease"], true)
+
+			fmt.Fprintf(w, `{"id":42,"tag_name":"v1.0.0","name":"%s","html_url":"https://github.com/vito/bass/releases/v1.0.0","upload_url":"%s/uploads/42/assets{?name,label}"}`, payload["name"], srv.URL)
+
+		case r.Method == "GET" && r.URL.Path == "/repos/vito/bass/releases":
+			if r.URL.Query().Get("page") == "" {
+				w.Header().Set("Link", fmt.Sprintf(`<%s/repos/vito/bass/releases?per_page=100&page=2>; rel="next", <%s/repos/vito/bass/releases?per_page=100&page=2>; rel="last"`, srv.URL, srv.URL))
+				fmt.Fprintln(w, `[{"id":2,"tag_name":"v0.2.0","name":"v0.2.0","html_url":"https://github.com/vito/bass/releases/v0.2.0"}]`)
+			} else {
+				fmt.Fprintln(w, `[{"id":1,"tag_name":"v0.1.0","name":"v0.1.0","html_url":"https://github.com/vito/bass/releases/v0.1.0"}]`)
+			}
+
+		case r.Method == "POST" && r.URL.Path == "/uploads/42/assets":
+			is.Equal(r.URL.Query().Get("name"), "app.tgz")
+			// streamed with its size known up front
+			is.Equal(r.ContentLength, int64(len("tarball")))
+			content, err := io.ReadAll(r.Body)
+			is.NoErr(err)
+			uploaded = string(content)
+			fmt.Fprintln(w, `{"browser_download_url":"https://github.com/vito/bass/releases/download/v1.0.0/app.tgz"}`)
+
+		case r.Method == "POST" && r.URL.Path == "/repos/vito/bass/issues/7/comments":
+			var payload map[string]string
+			is.NoErr(json.NewDecoder(r.Body).Decode(&payload))
+			commented = payload["body"]
+			fmt.Fprintln(w, `{"id":99,"html_url":"https://github.com/vito/bass/pull/7#issuecomment-99"}`)
+
+		case r.Method == "POST" && r.URL.Path == "/repos/vito/bass/statuses/abc123":
+			is.NoErr(json.NewDecoder(r.Body).Decode(&status))
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintln(w, `{}`)
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintln(w, `{"message":"Not Found"}`)
+		}
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	is.NoErr(os.WriteFile(filepath.Join(dir, "app.tgz"), []byte("tarball"), 0644))
+
+	scope := bass.NewStandardScope()
+	scope.Set("repo", bass.Bindings{
+		"repo":     bass.String("vito/bass"),
+		"endpoint": bass.String(srv.URL),
+		"token":    bass.NewSecret("github-token", []byte("s3cr3t")),
+	}.Scope())
+	scope.Set("app", bass.NewHostPath(dir, bass.ParseFileOrDirPath("app.tgz")))
+
+	res, err := bass.EvalFSFile(context.Background(), scope, bass.NewInMemoryFile("test", `
+		(def release (forge-release repo "v1.0.0" {:name "One" :prerelease true}))
+		[release:id
+		 release:name
+		 (forge-upload repo release app)
+		 (map (fn [r] r:tag) (forge-releases repo))
+		 (forge-comment repo 7 "LGTM")
+		 (forge-status repo "abc123" :failure {:context "ci/test" :target_url "https://ci.example.com/1"})]
+	`))
+	is.NoErr(err)
+	Equal(t, res, bass.NewList(
+		bass.Int(42),
+		bass.String("One"),
+		bass.Bindings{
+			"name": bass.String("app.tgz"),
+			"url":  bass.String("https://github.com/vito/bass/releases/download/v1.0.0/app.tgz"),
+		}.Scope(),
+		bass.NewList(bass.String("v0.2.0"), bass.String("v0.1.0")),
+		bass.Bindings{
+			"id":  bass.Int(99),
+			"url": bass.String("https://github.com/vito/bass/pull/7#issuecomment-99"),
+		}.Scope(),
+		bass.Null{},
+	))
+	is.Equal(uploaded, "tarball")
+	is.Equal(commented, "LGTM")
+	is.Equal(status, map[string]any{
+		"state":       "failure",
+		"context":     "ci/test",
+		"description": "",
+		"target_url":  "https://ci.example.com/1",
+	})
+
+	_, err = bass.EvalFSFile(context.Background(), scope, bass.NewInMemoryFile("test", `
+		(forge-comment (assoc repo :token "bogus") 7 "LGTM")
+	`))
+	is.True(err != nil)
+	is.True(strings.Contains(err.Error(), "401 Unauthorized: Bad credentials"))
+}
+
+func TestForgeGitLab(t *testing.T) {
+	is := is.New(t)
+
+	var linked, status map[string]any
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprintln(w, `{"message":"401 Unauthorized"}`)
+			return
+		}
+
+		const project = "/api/v4/projects/group%2Fapp"
+
+		switch {
+		case r.Method == "POST" && r.URL.EscapedPath() == project+"/releases":
+			fmt.Fprintln(w, `{"tag_name":"v1.0.0","name":"v1.0.0","_links":{"self":"https://gitlab.example.com/group/app/-/releases/v1.0.0"}}`)
+
+		case r.Method == "POST" && r.URL.EscapedPath() == project+"/uploads":
+			// the form is streamed as it is written
+			is.Equal(r.TransferEncoding, []string{"chunked"})
+			file, header, err := r.FormFile("file")
+			is.NoErr(err)
+			content, err := io.ReadAll(file)
+			is.NoErr(err)
+			is.Equal(header.Filename, "app.tgz")
+			is.Equal(string(content), "tarball")
+			fmt.Fprintln(w, `{"full_path":"/-/project/1/uploads/abc/app.tgz"}`)
+
+		case r.Method == "POST" && r.URL.EscapedPath() == project+"/releases/v1.0.0/assets/links":
+			is.NoErr(json.NewDecoder(r.Body).Decode(&linked))
+			fmt.Fprintf(w, `{"url":"%s"}`, linked["url"])
+
+		case r.Method == "POST" && r.URL.EscapedPath() == project+"/statuses/abc123":
+			is.NoErr(json.NewDecoder(r.Body).Decode(&status))
+			fmt.Fprintln(w, `{}`)
+
+		case r.Method == "POST" && r.URL.EscapedPath() == project+"/merge_requests/3/notes":
+			fmt.Fprintln(w, `{"id":5}`)
+
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintln(w, `{"message":{"tag_name":["is missing"]}}`)
+		}
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	is.NoErr(os.WriteFile(filepath.Join(dir, "app.tgz"), []byte("tarball"), 0644))
+
+	scope := bass.NewStandardScope()
+	scope.Set("repo", bass.Bindings{
+		"repo":     bass.String("group/app"),
+		"provider": bass.Symbol("gitlab"),
+		"endpoint": bass.String(srv.URL + "/api/v4"),
+		"token":    bass.String("s3cr3t"),
+	}.Scope())
+	scope.Set("app", bass.NewHostPath(dir, bass.ParseFileOrDirPath("app.tgz")))
+
+	res, err := bass.EvalFSFile(context.Background(), scope, bass.NewInMemoryFile("test", `
+		(def release (forge-release repo "v1.0.0"))
+		[release:url
+		 (forge-upload repo release app)
+		 (forge-comment repo 3 "LGTM")
+		 (forge-status repo "abc123" :error)]
+	`))
+	is.NoErr(err)
+	Equal(t, res, bass.NewList(
+		bass.String("https://gitlab.example.com/group/app/-/releases/v1.0.0"),
+		bass.Bindings{
+			"name": bass.String("app.tgz"),
+			"url":  bass.String(srv.URL + "/-/project/1/uploads/abc/app.tgz"),
+		}.Scope(),
+		bass.Bindings{
+			"id":  bass.Int(5),
+			"url": bass.String(srv.URL + "/group/app/-/merge_requests/3#note_5"),
+		}.Scope(),
+		bass.Null{},
+	))
+	is.Equal(linked["name"], "app.tgz")
+	is.Equal(status["state"], "failed")
+	is.Equal(status["name"], "bass")
+
+	_, err = bass.EvalFSFile(context.Background(), scope, bass.NewInMemoryFile("test", `
+		(forge-releases repo)
+	`))
+	is.True(err != nil)
+	is.True(strings.Contains(err.Error(), `400 Bad Request: {"tag_name":["is missing"]}`))
+}
@@ -0,0 +1,142 @@
+package bass
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Format renders the values into the printf-style template.
+//
+// %v renders any value as Bass would print it, so strings are quoted. %j
+// renders any value as JSON.
+//
+// For other verbs, strings, numbers, and bools are passed to fmt as their Go
+// equivalents, so %s renders a string without quotes and %d renders an int.
+// Other values are rendered in a Bass-aware way: %s renders host paths as
+// paths on the host, thunks as their name, and thunk paths as the thunk's
+// name followed by the path.
+func Format(template string, vals ...Value) string {
+	args := make([]any, len(vals))
+	for i, val := range vals {
+		args[i] = formatValue{val}
+	}
+
+	return fmt.Sprintf(template, args...)
+}
+
+// formatNative returns the Go equivalent of the value, if it has one.
+func formatNative(val Value) (any, bool) {
+	switch x := val.(type) {
+	case String:
+		return string(x), true
+	case Int:
+		return int(x), true
+	case Float:
+		return float64(x), true
+	case BigInt:
+		return x.BigInt(), true
+	case Bool:
+		return bool(x), true
+	default:
+		return nil, false
+	}
+}
+
+// formatValue renders a Value for Format.
+type formatValue struct {
+	Value
+}
+
+var _ fmt.Formatter = formatValue{}
+
+func (val formatValue) Format(state fmt.State, verb rune) {
+	if verb != 'j' && verb != 'v' {
+		if native, ok := formatNative(val.Value); ok {
+			fmt.Fprintf(state, formatDirective(state, verb), native)
+			return
+		}
+	}
+
+	var str string
+	switch verb {
+	case 's', 'q':
+		str = formatString(val.Value)
+	case 'j':
+		payload, err := MarshalJSON(val.Value)
+		if err != nil {
+			fmt.Fprintf(state, "%%!j(%s)", err)
+			return
+		}
+
+		str = string(payload)
+		verb = 's'
+	case 'v':
+		str = val.Value.String()
+		verb = 's'
+	default:
+		fmt.Fprintf(state, "%%!%c(%s)", verb, val.Value)
+		return
+	}
+
+	// pass flags, width, and precision along, e.g. for %-10s
+	fmt.Fprintf(state, formatDirective(state, verb), str)
+}
+
+// formatString renders the value for %s.
+func formatString(val Value) string {
+	switch x := val.(type) {
+	case HostPath:
+		return x.fpath()
+	case ThunkPath:
+		return x.Thunk.Name() + "/" + strings.TrimPrefix(x.Path.Slash(), "./")
+	case Thunk:
+		return x.Name()
+	default:
+		return val.String()
+	}
+}
+
+// formatDirective reconstructs the directive for the state and verb.
+func formatDirective(state fmt.State, verb rune) string {
+	directive := "%"
+	for _, flag := range "+-# 0" {
+		if state.Flag(int(flag)) {
+			directive += string(flag)
+		}
+	}
+
+	if width, ok := state.Width(); ok {
+		directive += strconv.Itoa(width)
+	}
+
+	if prec, ok := state.Precision(); ok {
+		directive += "." + strconv.Itoa(prec)
+	}
+
+	return directive + string(verb)
+}
+
+// PadLeft pads the start of the string with pad until it is width characters
+// long.
+func PadLeft(str string, width int, pad string) string {
+	return padding(str, width, pad) + str
+}
+
+// PadRight pads the end of the string with pad until it is width characters
+// long.
+func PadRight(str string, width int, pad string) string {
+	return str + padding(str, width, pad)
+}
+
+func padding(str string, width int, pad string) string {
+	missing := width - utf8.RuneCountInString(str)
+	if missing <= 0 || pad == "" {
+		return ""
+	}
+
+	padLen := utf8.RuneCountInString(pad)
+	fill := strings.Repeat(pad, missing/padLen+1)
+	return string([]rune(fill)[:missing])
+}
@@ -0,0 +1,34 @@
+package bass_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/is"
+)
+
+func TestFormat(t *testing.T) {
+	is := is.New(t)
+
+	thunk := bass.Thunk{
+		Cmd: bass.ThunkCmd{
+			Cmd: &bass.CommandPath{"echo"},
+		},
+	}
+
+	thunkPath := bass.ThunkPath{
+		Thunk: thunk,
+		Path:  bass.ParseFileOrDirPath("out/file"),
+	}
+
+	host := bass.NewHostPath("/src", bass.ParseFileOrDirPath("dir/file"))
+
+	is.Equal(bass.Format("%s", thunk), thunk.Name())
+	is.Equal(bass.Format("%s", thunkPath), thunk.Name()+"/out/file")
+	is.Equal(bass.Format("%s", host), filepath.Join("/src", "dir", "file"))
+	is.Equal(bass.Format("%v", thunk), thunk.String())
+	is.Equal(bass.Format("%v", host), host.String())
+	is.Equal(bass.Format("%q", host), `"`+filepath.Join("/src", "dir", "file")+`"`)
+	is.Equal(bass.Format("%05d %.2f %t %s", bass.Int(42), bass.Float(1.5), bass.Bool(true), bass.String("x")), "00042 1.50 true x")
+}
@@ -0,0 +1,139 @@
+package bass_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+)
+
+var readerSeeds = []string{
+	`_`,
+	`null`,
+	`42`,
+	`-1`,
+	`hello`,
+	`:hello`,
+	`foo:bar:baz`,
+	`"hello world"`,
+	`"hello \"\n\\\t\a\f\r\b\v"`,
+	`"\x00é\U0001F600"`,
+	`[1 true "three"]`,
+	`(a b & c)`,
+	`{:a 1 :b [2 3]}`,
+	`./foo/bar`,
+	`.git`,
+	`^:meta {}`,
+	`; comment
+	(foo)`,
+	`((((((()))))))`,
+}
+
+func FuzzReader(f *testing.F) {
+	for _, seed := range readerSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, src string) {
+		forms, err := readAll(src)
+		if err != nil {
+			return
+		}
+
+		syntax, err := bass.ParseSyntax(src, bass.NewInMemoryFile("fuzz", src))
+		if err != nil {
+			t.Fatalf("parse syntax %q: %s", src, err)
+		}
+
+		if syntax.String() != src {
+			t.Fatalf("syntax round trip %q: got %q", src, syntax.String())
+		}
+
+		if len(syntax.Nodes) != len(forms) {
+			t.Fatalf("syntax %q: got %d nodes, want %d", src, len(syntax.Nodes), len(forms))
+		}
+
+		for _, form := range forms {
+			repr := form.String()
+
+			reread, err := readAll(repr)
+			if err != nil {
+				t.Fatalf("re-read %q (from %q): %s", repr, src, err)
+			}
+
+			if len(reread) != 1 {
+				t.Fatalf("re-read %q (from %q): got %d forms", repr, src, len(reread))
+			}
+
+			if reread[0].String() != repr {
+				t.Fatalf("round trip %q (from %q): got %q", repr, src, reread[0].String())
+			}
+		}
+	})
+}
+
+func FuzzValueJSON(f *testing.F) {
+	for _, seed := range []string{
+		`null`,
+		`true`,
+		`42`,
+		`"hello"`,
+		`"\u0000é😀<>&"`,
+		`[]`,
+		`[1,"two",null,true,{"b":[]},[[]]]`,
+		`{"a":{"b-c":1},"":[]}`,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		var val bass.Value
+		if err := bass.UnmarshalJSON(payload, &val); err != nil {
+			return
+		}
+
+		marshaled, err := bass.MarshalJSON(val)
+		if err != nil {
+			t.Fatalf("marshal %s (from %q): %s", val, payload, err)
+		}
+
+		var roundTripped bass.Value
+		if err := bass.UnmarshalJSON(marshaled, &roundTripped); err != nil {
+			t.Fatalf("unmarshal %q: %s", marshaled, err)
+		}
+
+		if !roundTripped.Equal(val) {
+			t.Fatalf("round trip %q: got %s, want %s", payload, roundTripped, val)
+		}
+
+		buf := new(bytes.Buffer)
+		if err := bass.NewValueEncoder(buf).EncodeValue(val); err != nil {
+			t.Fatalf("encode %s: %s", val, err)
+		}
+
+		if streamed := bytes.TrimSuffix(buf.Bytes(), []byte("\n")); !bytes.Equal(streamed, marshaled) {
+			t.Fatalf("encode %s: got %q, want %q", val, streamed, marshaled)
+		}
+	})
+}
+
+func readAll(src string) ([]bass.Value, error) {
+	reader := bass.NewReader(strings.NewReader(src), bass.NewInMemoryFile("fuzz", src))
+
+	var forms []bass.Value
+	for {
+		form, err := reader.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return forms, nil
+			}
+
+			return nil, err
+		}
+
+		forms = append(forms, form)
+	}
+}
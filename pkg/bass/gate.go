@@ -0,0 +1,357 @@
+package bass
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/vito/bass/pkg/zapctx"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Ground.MustSet("gate",
+		Func("gate", "[name]", func(ctx context.Context, name string) (Value, error) {
+			approval, err := WaitForGate(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+
+			return approval.ToValue(), nil
+		}),
+		`pauses until the named gate is approved`,
+		`When running interactively, asks for approval on the terminal. Otherwise, waits until the gate is approved by running bass --approve with its name, or by a POST to /gates/<name>/approve on the control socket of a running daemon, e.g. from a webhook.`,
+		`Approvals are only accepted while a run is waiting on the gate, and only release the runs waiting at the time. Errors if the gate is rejected on the terminal. Gates always ask, even with --yes.`,
+		`Returns the approval as a scope with :gate, :approver, :via, and :approved fields. Approvals are recorded in the run history.`,
+		`=> (gate "deploy-prod")`)
+}
+
+// Ways a gate may be approved.
+const (
+	GateViaPrompt  = "prompt"
+	GateViaCLI     = "cli"
+	GateViaWebhook = "webhook"
+)
+
+// GatePollInterval is how often a gate waiting for approval checks for it.
+var GatePollInterval = time.Second
+
+// GateApprovalTTL is how long an approval remains valid. An approval which
+// isn't taken in time, e.g. because its run went away, is ignored.
+var GateApprovalTTL = time.Hour
+
+// ErrGateNotPending is returned by ApproveGate when no run is waiting on the
+// gate.
+var ErrGateNotPending = errors.New("no run is waiting on the gate")
+
+// GatesDir is the directory where pending gates and their approvals are
+// recorded.
+func GatesDir() string {
+	return filepath.Join(CacheHome, "gates")
+}
+
+// GateRejectedError is returned by (gate) when the gate is rejected on the
+// terminal.
+type GateRejectedError struct {
+	Gate string
+}
+
+func (err GateRejectedError) Error() string {
+	return fmt.Sprintf("gate rejected: %s", err.Gate)
+}
+
+// GateApproval records the approval of a gate.
+type GateApproval struct {
+	// Gate is the name of the gate.
+	Gate string `json:"gate"`
+
+	// Approver is who approved the gate.
+	Approver string `json:"approver"`
+
+	// Via is how the gate was approved, e.g. GateViaCLI.
+	Via string `json:"via"`
+
+	// Approved is when the gate was approved.
+	Approved time.Time `json:"approved"`
+
+	// Expires is when the approval is no longer valid.
+	Expires time.Time `json:"expires,omitempty"`
+}
+
+// ToValue returns the approval as a scope.
+func (approval GateApproval) ToValue() Value {
+	return Bindings{
+		"gate":     String(approval.Gate),
+		"approver": String(approval.Approver),
+		"via":      Symbol(approval.Via),
+		"approved": String(approval.Approved.Format(time.RFC3339)),
+	}.Scope()
+}
+
+// PendingGate is a gate waiting for approval.
+type PendingGate struct {
+	// Gate is the name of the gate.
+	Gate string `json:"gate"`
+
+	// ID identifies the wait, so that each run waiting on the same gate is
+	// approved separately.
+	ID string `json:"id"`
+
+	// Since is when the gate was reached.
+	Since time.Time `json:"since"`
+
+	// PID is the process waiting on the gate.
+	PID int `json:"pid"`
+}
+
+// WaitForGate blocks until the named gate is approved.
+//
+// The user is asked to approve it on the terminal, and if not running
+// interactively, the gate is marked as pending until it is approved with
+// ApproveGate. Gates are never approved by AssumeYes.
+func WaitForGate(ctx context.Context, name string) (GateApproval, error) {
+	if err := validateFileName("gate", name); err != nil {
+		return GateApproval{}, err
+	}
+
+	prompter := PrompterFromContext(ctx)
+	if yes, ok := prompter.(AssumeYes); ok {
+		// gates are for humans; don't let --yes approve them
+		prompter = yes.Prompter
+	}
+
+	ok, err := prompter.Confirm(fmt.Sprintf("Approve gate %q?", name))
+	if err == nil {
+		if !ok {
+			return GateApproval{}, GateRejectedError{Gate: name}
+		}
+
+		approval := GateApproval{
+			Gate:     name,
+			Approver: currentUser(),
+			Via:      GateViaPrompt,
+			Approved: time.Now().UTC(),
+		}
+
+		noteApproval(ctx, approval)
+
+		return approval, nil
+	}
+
+	if !errors.Is(err, ErrNonInteractive) {
+		return GateApproval{}, err
+	}
+
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return GateApproval{}, err
+	}
+
+	pending := PendingGate{
+		Gate:  name,
+		ID:    hex.EncodeToString(buf),
+		Since: time.Now().UTC(),
+		PID:   os.Getpid(),
+	}
+
+	pendingPath := gateWaitPath(pending, ".pending")
+	if err := writeGateFile(pendingPath, pending); err != nil {
+		return GateApproval{}, err
+	}
+
+	// hold a lock on the file while waiting, so that a pending gate can be
+	// told apart from one left behind by a run which died
+	lock := flock.New(pendingPath)
+	if locked, err := lock.TryLock(); err != nil || !locked {
+		return GateApproval{}, fmt.Errorf("lock pending gate %s", name)
+	}
+
+	defer lock.Unlock()
+	defer os.Remove(pendingPath)
+
+	// clean up an approval which arrives as we stop waiting
+	defer os.Remove(gateWaitPath(pending, ".approved"))
+
+	zapctx.FromContext(ctx).Warn("waiting for approval",
+		zap.String("gate", name),
+		zap.String("approve", "bass --approve "+name))
+
+	ticker := time.NewTicker(GatePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return GateApproval{}, ctx.Err()
+		case <-ticker.C:
+		}
+
+		approval, found, err := takeApproval(pending)
+		if err != nil {
+			return GateApproval{}, err
+		}
+
+		if found {
+			noteApproval(ctx, approval)
+			return approval, nil
+		}
+	}
+}
+
+// ApproveGate approves the named gate, releasing each run waiting on it.
+// Returns ErrGateNotPending if no run is waiting on it.
+//
+// If approver is empty, the current user is recorded as the approver.
+func ApproveGate(name, approver, via string) (GateApproval, error) {
+	if err := validateFileName("gate", name); err != nil {
+		return GateApproval{}, err
+	}
+
+	gates, err := PendingGates()
+	if err != nil {
+		return GateApproval{}, err
+	}
+
+	if approver == "" {
+		approver = currentUser()
+	}
+
+	now := time.Now().UTC()
+
+	approval := GateApproval{
+		Gate:     name,
+		Approver: approver,
+		Via:      via,
+		Approved: now,
+		Expires:  now.Add(GateApprovalTTL),
+	}
+
+	var approved bool
+	for _, pending := range gates {
+		if pending.Gate != name {
+			continue
+		}
+
+		if err := writeGateFile(gateWaitPath(pending, ".approved"), approval); err != nil {
+			return GateApproval{}, err
+		}
+
+		approved = true
+	}
+
+	if !approved {
+		return GateApproval{}, fmt.Errorf("approve %s: %w", name, ErrGateNotPending)
+	}
+
+	return approval, nil
+}
+
+// PendingGates returns the gates waiting for approval.
+func PendingGates() ([]PendingGate, error) {
+	matches, err := filepath.Glob(filepath.Join(GatesDir(), "*.pending"))
+	if err != nil {
+		return nil, err
+	}
+
+	var gates []PendingGate
+	for _, match := range matches {
+		payload, err := os.ReadFile(match)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		var gate PendingGate
+		if err := json.Unmarshal(payload, &gate); err != nil {
+			continue
+		}
+
+		gates = append(gates, gate)
+	}
+
+	return gates, nil
+}
+
+// takeApproval reads and removes the approval for the pending gate, if any.
+// An expired approval is removed and ignored.
+func takeApproval(pending PendingGate) (GateApproval, bool, error) {
+	path := gateWaitPath(pending, ".approved")
+
+	payload, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return GateApproval{}, false, nil
+		}
+
+		return GateApproval{}, false, err
+	}
+
+	if err := os.Remove(path); err != nil {
+		return GateApproval{}, false, err
+	}
+
+	var approval GateApproval
+	if err := json.Unmarshal(payload, &approval); err != nil {
+		return GateApproval{}, false, fmt.Errorf("decode approval: %w", err)
+	}
+
+	if !approval.Expires.IsZero() && time.Now().After(approval.Expires) {
+		return GateApproval{}, false, nil
+	}
+
+	return approval, true, nil
+}
+
+// validateFileName checks that the name of a gate or lock can be used as a
+// file name.
+func validateFileName(kind, name string) error {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return fmt.Errorf("invalid %s name: %q", kind, name)
+	}
+
+	return nil
+}
+
+func gateWaitPath(pending PendingGate, ext string) string {
+	return filepath.Join(GatesDir(), pending.Gate+"."+pending.ID+ext)
+}
+
+func writeGateFile(path string, v any) error {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	// write to a temporary file first so that pollers never see a partial
+	// write
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, payload, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+
+	return os.Getenv("USER")
+}
@@ -0,0 +1,167 @@
+package bass_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/is"
+)
+
+func TestGate(t *testing.T) {
+	is := is.New(t)
+
+	oldHome := bass.CacheHome
+	bass.CacheHome = t.TempDir()
+	defer func() { bass.CacheHome = oldHome }()
+
+	oldInterval := bass.GatePollInterval
+	bass.GatePollInterval = 10 * time.Millisecond
+	defer func() { bass.GatePollInterval = oldInterval }()
+
+	ctx, history := bass.TrackHistory(context.Background())
+
+	evalGate := func(ctx context.Context) (bass.Value, error) {
+		return bass.EvalFSFile(ctx, bass.NewStandardScope(), bass.NewInMemoryFile("test", `(gate "deploy-prod")`))
+	}
+
+	t.Run("approved on the terminal", func(t *testing.T) {
+		is := is.New(t)
+
+		ctx := bass.WithPrompter(ctx, fakePrompter{map[string]string{`Approve gate "deploy-prod"?`: "y"}})
+		res, err := evalGate(ctx)
+		is.NoErr(err)
+
+		var approval struct {
+			Gate string      `json:"gate"`
+			Via  bass.Symbol `json:"via"`
+		}
+		is.NoErr(res.Decode(&approval))
+		is.Equal(approval.Gate, "deploy-prod")
+		is.Equal(approval.Via, bass.Symbol(bass.GateViaPrompt))
+	})
+
+	t.Run("rejected on the terminal", func(t *testing.T) {
+		is := is.New(t)
+
+		ctx := bass.WithPrompter(ctx, fakePrompter{})
+		_, err := evalGate(ctx)
+
+		var rejected bass.GateRejectedError
+		is.True(errors.As(err, &rejected))
+		is.Equal(rejected.Gate, "deploy-prod")
+	})
+
+	t.Run("approved before reaching the gate", func(t *testing.T) {
+		is := is.New(t)
+
+		_, err := bass.ApproveGate("deploy-prod", "alice", bass.GateViaCLI)
+		is.True(errors.Is(err, bass.ErrGateNotPending))
+	})
+
+	approveWhenPending := func(waiters int) <-chan error {
+		approved := make(chan error, 1)
+		go func() {
+			for {
+				pending, err := bass.PendingGates()
+				if err != nil {
+					approved <- err
+					return
+				}
+
+				if len(pending) >= waiters {
+					_, err := bass.ApproveGate(pending[0].Gate, "bob", bass.GateViaWebhook)
+					approved <- err
+					return
+				}
+
+				time.Sleep(10 * time.Millisecond)
+			}
+		}()
+
+		return approved
+	}
+
+	t.Run("approved while waiting", func(t *testing.T) {
+		is := is.New(t)
+
+		approved := approveWhenPending(1)
+
+		res, err := evalGate(ctx)
+		is.NoErr(err)
+		is.NoErr(<-approved)
+
+		var approver string
+		is.NoErr(res.(*bass.Scope).GetDecode("approver", &approver))
+		is.Equal(approver, "bob")
+
+		pending, err := bass.PendingGates()
+		is.NoErr(err)
+		is.Equal(len(pending), 0)
+	})
+
+	t.Run("not approved with --yes", func(t *testing.T) {
+		is := is.New(t)
+
+		ctx := bass.WithPrompter(ctx, bass.AssumeYes{Prompter: bass.NonInteractive{}})
+
+		approved := approveWhenPending(1)
+
+		res, err := evalGate(ctx)
+		is.NoErr(err)
+		is.NoErr(<-approved)
+
+		var via bass.Symbol
+		is.NoErr(res.(*bass.Scope).GetDecode("via", &via))
+		is.Equal(via, bass.Symbol(bass.GateViaWebhook))
+	})
+
+	t.Run("approving multiple waiters", func(t *testing.T) {
+		is := is.New(t)
+
+		approved := approveWhenPending(2)
+
+		errs := make(chan error, 2)
+		for i := 0; i < 2; i++ {
+			go func() {
+				_, err := evalGate(ctx)
+				errs <- err
+			}()
+		}
+
+		is.NoErr(<-errs)
+		is.NoErr(<-errs)
+		is.NoErr(<-approved)
+
+		pending, err := bass.PendingGates()
+		is.NoErr(err)
+		is.Equal(len(pending), 0)
+	})
+
+	t.Run("expired approval", func(t *testing.T) {
+		is := is.New(t)
+
+		oldTTL := bass.GateApprovalTTL
+		bass.GateApprovalTTL = -time.Second
+		defer func() { bass.GateApprovalTTL = oldTTL }()
+
+		approved := approveWhenPending(1)
+
+		ctx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+		defer cancel()
+
+		_, err := evalGate(ctx)
+		is.True(errors.Is(err, bass.ErrInterrupted))
+		is.NoErr(<-approved)
+	})
+
+	approvals := history.Approvals()
+	is.Equal(len(approvals), 5)
+	is.Equal(approvals[1].Approver, "bob")
+	is.Equal(approvals[1].Via, bass.GateViaWebhook)
+
+	_, err := bass.ApproveGate("../escape", "mallory", bass.GateViaCLI)
+	is.True(err != nil)
+}
@@ -3,9 +3,16 @@ package bass
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"math/big"
 	"path"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/jonboulle/clockwork"
 	"github.com/vito/bass/pkg/ioctx"
@@ -31,7 +38,7 @@ func NewStandardScope() *Scope {
 func init() {
 	Ground.Name = "ground"
 
-	Ground.Set("def",
+	Ground.MustSet("def",
 		Op("def", "[binding value]", func(ctx context.Context, cont Cont, scope *Scope, formals Bindable, val Value) ReadyCont {
 			return val.Eval(ctx, scope, Continue(func(res Value) Value {
 				return formals.Bind(ctx, scope, cont, res)
@@ -43,7 +50,42 @@ func init() {
 		`=> (def [a b c] [1 2 3])`,
 		`=> [abc a b c]`)
 
-	Ground.Set("if",
+	Ground.MustSet("defdynamic",
+		Op("defdynamic", "[name default]", func(ctx context.Context, cont Cont, scope *Scope, name Symbol, def Value) ReadyCont {
+			return def.Eval(ctx, scope, Continue(func(res Value) Value {
+				return name.Bind(ctx, scope, cont, NewDynamic(name, res))
+			}))
+		}),
+		`define a dynamically scoped variable with a default value`,
+		`Evaluating the variable returns the value set by the innermost (with-dynamic) currently running, or the default value.`,
+		`By convention, dynamic variable names are surrounded by *earmuffs*.`,
+		`=> (defdynamic *verbosity* 1)`,
+		`=> *verbosity*`)
+
+	Ground.MustSet("with-dynamic",
+		Op("with-dynamic", "[name val & body]", func(ctx context.Context, cont Cont, scope *Scope, name Symbol, val Value, body ...Value) ReadyCont {
+			bound, found := scope.Get(name)
+			if !found {
+				return cont.Call(nil, UnboundError{name, scope})
+			}
+
+			var dyn *Dynamic
+			if err := bound.Decode(&dyn); err != nil {
+				return cont.Call(nil, err)
+			}
+
+			return val.Eval(ctx, scope, Continue(func(res Value) Value {
+				return do(WithDynamic(ctx, dyn, res), cont, scope, body)
+			}))
+		}),
+		`evaluate a body with a dynamic variable set to a value`,
+		`The value is visible to everything evaluated during the body, including functions defined elsewhere, and reverts once the body returns.`,
+		`=> (defdynamic *verbosity* 1)`,
+		`=> (defn verbosity [] *verbosity*)`,
+		`=> (with-dynamic *verbosity* 3 (verbosity))`,
+		`=> (verbosity)`)
+
+	Ground.MustSet("if",
 		Annotated{
 			Value: Op("if", "[cond yes no]", func(ctx context.Context, cont Cont, scope *Scope, cond, yes, no Value) ReadyCont {
 				return cond.Eval(ctx, scope, Continue(func(res Value) Value {
@@ -66,7 +108,7 @@ func init() {
 		`Evaluates the cond form. If the result is truthy (not false or null), evaluates the yes form. Otherwise, evaluates the no form.`,
 		`=> (if false (error "bam") :phew)`)
 
-	Ground.Set("dump",
+	Ground.MustSet("dump",
 		Func("dump", "[val]", func(ctx context.Context, val Value) Value {
 			Dump(ioctx.StderrFromContext(ctx), val)
 			return val
@@ -75,7 +117,7 @@ func init() {
 		`Returns the given value.`,
 		`=> (dump {:foo-bar "baz"})`)
 
-	Ground.Set("mkfs",
+	Ground.MustSet("mkfs",
 		Func("mkfs", "file-content-kv", NewInMemoryFSDir),
 		`returns a dir path backed by an in-memory filesystem`,
 		`Takes alternating file paths and their content, which must be a text string, and returns the root directory of an in-memory filesystem containing the specified files.`,
@@ -84,7 +126,7 @@ func init() {
 		`=> (next (read (from (linux/alpine) ($ cat fs/file)) :raw))`,
 	)
 
-	Ground.Set("json",
+	Ground.MustSet("json",
 		Func("json", "[val]", func(ctx context.Context, val Value) (string, error) {
 			payload, err := MarshalJSON(val)
 			if err != nil {
@@ -96,7 +138,7 @@ func init() {
 		`returns a string containing val encoded as JSON`,
 		`=> (json {:foo-bar "baz"})`)
 
-	Ground.Set("log",
+	Ground.MustSet("log",
 		Func("log", "[val & fields]", func(ctx context.Context, v Value, kv ...Value) (Value, error) {
 			logger := zapctx.FromContext(ctx)
 
@@ -134,14 +176,14 @@ func init() {
 		`=> (log "hello, world!")`,
 		`=> (log "doing something" :a 1 :since {:day 1})`)
 
-	Ground.Set("error",
+	Ground.MustSet("error",
 		Func("error", "[msg & fields]", NewError),
 		`errors with the given message`,
 		`Accepts key-value fields for structured error data.`,
 		`=> (error "oh no!")`,
 		`=> (error "oh no!" :exit-code 2)`)
 
-	Ground.Set("now",
+	Ground.MustSet("now",
 		Func("now", "[seconds]", func(duration int) string {
 			return Clock.Now().Truncate(time.Duration(duration) * time.Second).UTC().Format(time.RFC3339)
 		}),
@@ -149,7 +191,7 @@ func init() {
 		`Typically used to influence caching for thunks whose result may change over time.`,
 		`=> (now 60)`)
 
-	Ground.Set("do",
+	Ground.MustSet("do",
 		Op("do", "body", func(ctx context.Context, cont Cont, scope *Scope, body ...Value) ReadyCont {
 			return do(ctx, cont, scope, body)
 		}),
@@ -157,7 +199,7 @@ func init() {
 		`=> (do (def abc 123) (+ abc 1))`,
 		`=> abc`)
 
-	Ground.Set("cons",
+	Ground.MustSet("cons",
 		Func("cons", "[a d]", func(a, d Value) Value {
 			return Pair{a, d}
 		}),
@@ -165,7 +207,45 @@ func init() {
 		`=> (cons 1 [2 3])`,
 		`=> (cons 1 2)`)
 
-	Ground.Set("wrap",
+	Ground.MustSet("delay",
+		Op("delay", "[form]", func(scope *Scope, form Value) *Promise {
+			return Delay(form, scope)
+		}),
+		`returns a promise to evaluate the form when forced`,
+		`The form is evaluated at most once, the first time the promise is passed to (force).`,
+		`=> (def p (delay (do (log "computing") 42)))`,
+		`=> [(force p) (force p)]`)
+
+	Ground.MustSet("force",
+		Func("force", "[val]", func(ctx context.Context, cont Cont, val Value) ReadyCont {
+			var promise *Promise
+			if err := val.Decode(&promise); err == nil {
+				return promise.Force(ctx, cont)
+			}
+
+			return cont.Call(val, nil)
+		}),
+		`evaluates a promise created by (delay), returning its value`,
+		`Values which are not promises are returned as-is.`,
+		`=> (force (delay (+ 1 2)))`,
+		`=> (force 3)`)
+
+	Ground.MustSet("lazy-cons",
+		Op("lazy-cons", "[first rest]", func(ctx context.Context, cont Cont, scope *Scope, first, rest Value) ReadyCont {
+			return first.Eval(ctx, scope, Continue(func(res Value) Value {
+				return cont.Call(LazyCons{
+					A: res,
+					D: Delay(rest, scope),
+				}, nil)
+			}))
+		}),
+		`construct a lazy sequence from a value and a form which returns the rest of the sequence`,
+		`The rest form is not evaluated until it is forced, so the sequence may be infinite. It must return another lazy sequence or an empty list.`,
+		`(first) returns the first value and (rest) returns a promise for the rest. Use (take) to read values from the sequence.`,
+		`=> (defn nats [n] (lazy-cons n (nats (+ n 1))))`,
+		`=> (take 3 (nats 0))`)
+
+	Ground.MustSet("wrap",
 		Func("wrap", "[comb]", Wrap),
 		`construct an applicative from a combiner (typically an operative)`,
 		`When called, an applicative evaluates its arguments before passing them along to the underlying combiner.`,
@@ -173,24 +253,66 @@ func init() {
 		`=> (log-quote (* 6 7))`,
 		`=> ((wrap log-quote) (* 6 7))`)
 
-	Ground.Set("unwrap",
+	Ground.MustSet("unwrap",
 		Func("unwrap", "[app]", (Applicative).Unwrap),
 		`returns an applicative's underlying combiner`,
 		`You probably won't use this a lot. It's used to implement higher level abstractions like (apply).`)
 
-	Ground.Set("op",
+	Ground.MustSet("apply",
+		Func("apply", "[comb args & scope]", func(ctx context.Context, cont Cont, comb Combiner, args List, scope ...*Scope) ReadyCont {
+			env := NewEmptyScope()
+			if len(scope) > 0 {
+				env = scope[0]
+			}
+
+			var app Applicative
+			if err := comb.Decode(&app); err == nil {
+				comb = app.Unwrap()
+			}
+
+			return comb.Call(ctx, args, env, cont)
+		}),
+		`call a combiner with a list of arguments`,
+		`Applicatives are called with the arguments as-is, skipping the evaluation they would normally perform prior to calling their underlying operative. Operatives are called with the arguments as their unevaluated operands.`,
+		`A scope may be provided as the third argument. If not specified, the combiner will be called in a new empty scope.`,
+		`=> (apply * [1 2 3])`,
+		`=> (apply quote [:unevaluated])`)
+
+	Ground.MustSet("partial",
+		Func("partial", "[comb & args]", NewPartial),
+		`partially apply a combiner to a list of arguments`,
+		`Returns a combiner which calls the given combiner with args prepended to its own arguments. Applicatives remain applicative and operatives remain operative.`,
+		`=> (def add2 (partial + 2))`,
+		`=> (add2 40)`,
+		`=> (map (partial * 2) [1 2 3])`)
+
+	Ground.MustSet("comp",
+		Func("comp", "[f & fs]", func(f Applicative, fs ...Applicative) Applicative {
+			return Wrap(Composition{
+				Applicatives: append([]Applicative{f}, fs...),
+			})
+		}),
+		`compose applicatives from right to left`,
+		`Returns an applicative which calls the last applicative with its arguments, and each preceding applicative with the result of the one after it.`,
+		`=> ((comp str (partial * 2) +) 1 2 3)`,
+		`=> (map (comp :name first) [[{:name "a"}] [{:name "b"}]])`)
+
+	Ground.MustSet("op",
 		Op("op", "[formals eformal body]", func(scope *Scope, formals, eformal Bindable, body Value) *Operative {
+			pre, post, body := extractContracts(body)
 			return &Operative{
 				StaticScope:  scope,
 				Bindings:     formals,
 				ScopeBinding: eformal,
 				Body:         body,
+				Pre:          pre,
+				Post:         post,
 			}
 		}),
 		// no commentary; it's redefined later
 	)
 
-	Ground.Set("eval",
+	Ground.MustSet("eval",
 		Func("eval", "[form scope]", func(ctx context.Context, cont Cont, val Value, scope *Scope) ReadyCont {
 			return val.Eval(ctx, scope, cont)
 		}),
@@ -198,16 +320,22 @@ func init() {
 		`=> (eval :abc {:abc 123})`,
 		`=> (eval [* :x :y] {:x 6 :y 7})`)
 
-	Ground.Set("make-scope",
+	Ground.MustSet("make-scope",
 		Func("make-scope", "parents", NewEmptyScope),
 		`construct a scope with the given parents`,
 		`=> (make-scope {:a 1} {:b 2})`,
 		`=> (eval [+ :a :b] (make-scope {:a 1} {:b 2}))`)
 
-	Ground.Set("bind",
+	Ground.MustSet("bind",
 		Func("bind", "[scope formals val]", func(ctx context.Context, cont Cont, scope *Scope, formals Bindable, val Value) ReadyCont {
 			// TODO: using a Trampoline here is a bit of a smell
 			_, err := Trampoline(ctx, formals.Bind(ctx, scope, Identity, val))
+
+			var frozen FrozenError
+			if errors.As(err, &frozen) {
+				return cont.Call(nil, err)
+			}
+
 			return cont.Call(Bool(err == nil), nil)
 		}),
 		`attempts to bind values in the scope`,
@@ -215,7 +343,65 @@ func init() {
 		`=> (if (bind (current-scope) :abc 123) abc :mismatch)`,
 		`=> (if (bind (current-scope) [] 123) _ :mismatch)`)
 
-	Ground.Set("meta",
+	Ground.MustSet("assert",
+		Op("assert", "[expr & msg]", func(ctx context.Context, cont Cont, scope *Scope, expr Value, msg ...Value) ReadyCont {
+			return expr.Eval(ctx, scope, Continue(func(res Value) Value {
+				if truthy(res) {
+					return cont.Call(res, nil)
+				}
+
+				if len(msg) == 0 {
+					return cont.Call(nil, newAssertionError(CheckAssertion, expr, ""))
+				}
+
+				return msg[0].Eval(ctx, scope, Continue(func(msgVal Value) Value {
+					var str string
+					if err := msgVal.Decode(&str); err != nil {
+						str = msgVal.String()
+					}
+
+					return cont.Call(nil, newAssertionError(CheckAssertion, expr, str))
+				}))
+			}))
+		}),
+		`raises an error if the expression is not truthy`,
+		`The error includes the expression and its location in the source, followed by the message, if given. Returns the expression's value otherwise.`,
+		`Unlike (pre) and (post), assertions are always checked.`,
+		`=> (assert (= 4 (+ 2 2)) "math still works")`,
+		`=> (assert (= 5 (+ 2 2)) "math is broken")`)
+
+	Ground.MustSet("pre",
+		Op("pre", "checks", func(_ *Scope, _ ...Value) error {
+			return ErrMisplacedContract
+		}),
+		`declares preconditions of a function`,
+		`When leading the body of a function, each check is evaluated with its arguments bound before the body is evaluated. An error is raised if any check is not truthy.`,
+		`Contracts are only checked when enabled with the --contracts flag.`,
+		`=> (defn halve [x] (pre (number? x) (>= x 0)) (quot x 2))`,
+		`=> (halve 42)`)
+
+	Ground.MustSet("post",
+		Op("post", "preds", func(_ *Scope, _ ...Value) error {
+			return ErrMisplacedContract
+		}),
+		`declares postconditions of a function`,
+		`When leading the body of a function, each predicate is called with the function's result. An error is raised if any predicate returns a value that is not truthy.`,
+		`Contracts are only checked when enabled with the --contracts flag.`,
+		`=> (defn halve [x] (post number?) (quot x 2))`,
+		`=> (halve 42)`)
+
+	Ground.MustSet("freeze!",
+		Func("freeze!", "[scope]", func(scope *Scope) *Scope {
+			scope.Freeze()
+			return scope
+		}),
+		`prevents any more bindings from being set in the scope`,
+		`Binding a symbol in a frozen scope raises an error. Child scopes may still shadow its bindings.`,
+		`Returns the scope. The ground scope is frozen once the standard library is loaded.`,
+		`=> (def frozen (freeze! {:a 1}))`,
+		`=> (bind (make-scope frozen) :a 2)`)
+
+	Ground.MustSet("meta",
 		Func("meta", "[val]", func(val Value) Value {
 			var ann Annotated
 			if err := val.Decode(&ann); err == nil {
@@ -228,62 +414,221 @@ func init() {
 		`Returns null if the value has no metadata.`,
 		`=> (meta meta) ; whoa`)
 
-	Ground.Set("with-meta",
+	Ground.MustSet("with-meta",
 		Func("with-meta", "[val meta]", WithMeta),
 		`returns val with the given scope as its metadata`,
 		`=> (meta (with-meta _ {:a 1}))`,
 		`=> (meta (with-meta (with-meta _ {:a 1}) {:b 2}))`)
 
-	Ground.Set("doc",
+	Ground.MustSet("doc",
 		Op("doc", "symbols", PrintDocs),
 		`print docs for symbols`,
 		`Prints the documentation for the given symbols resolved from the current scope.`,
 		`=> (doc doc)`)
 
 	for _, pred := range primPreds {
-		Ground.Set(pred.name, Func(string(pred.name), "[val]", pred.check), pred.docs...)
+		Ground.MustSet(pred.name, Func(string(pred.name), "[val]", pred.check), pred.docs...)
 	}
 
-	Ground.Set("+",
-		Func("+", "nums", func(nums ...int) int {
-			sum := 0
+	Ground.MustSet("+",
+		Func("+", "nums", func(nums ...Number) Number {
+			var sum Number = Int(0)
 			for _, num := range nums {
-				sum += num
+				sum = AddNumbers(sum, num)
 			}
 
 			return sum
 		}),
 		`sums numbers`,
-		`=> (+ 1 2 3)`)
+		`The result is a float if any of the numbers are floats.`,
+		`=> (+ 1 2 3)`,
+		`=> (+ 1 2.5)`)
 
-	Ground.Set("*",
-		Func("*", "nums", func(nums ...int) int {
-			mul := 1
+	Ground.MustSet("*",
+		Func("*", "nums", func(nums ...Number) Number {
+			var mul Number = Int(1)
 			for _, num := range nums {
-				mul *= num
+				mul = MulNumbers(mul, num)
 			}
 
 			return mul
 		}),
 		`multiplies numbers`,
-		`=> (* 2 3 7)`)
+		`The result is a float if any of the numbers are floats. Integers do not overflow; they are promoted to arbitrary precision as needed.`,
+		`=> (* 2 3 7)`,
+		`=> (* 1.5 4)`,
+		`=> (* 4294967296 4294967296)`)
+
+	Ground.MustSet("/",
+		Func("/", "[num & denoms]", func(num Number, denoms ...Number) (Number, error) {
+			if len(denoms) == 0 {
+				return DivNumbers(Int(1), num)
+			}
+
+			var err error
+			for _, denom := range denoms {
+				num, err = DivNumbers(num, denom)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			return num, nil
+		}),
+		`divides num by each denom`,
+		`With no denoms, returns the reciprocal of num.`,
+		`Dividing integers results in an integer if the division is exact, and a float otherwise. Use (quot) for integer division which truncates instead. Errors if any denom is zero.`,
+		`Only a / at the head of a list is read as this function. Everywhere else, / is the root directory.`,
+		`=> (/ 84 2)`,
+		`=> (/ 7 2)`,
+		`=> (/ 100 2 5)`,
+		`=> (/ 4)`)
+
+	Ground.MustSet("quot",
+		Func("quot", "[num denom]", QuotNumbers),
+		`quot[ient] of dividing num by denom`,
+		`The result is truncated towards zero. Errors if denom is zero.`,
+		`=> (quot 84 2)`,
+		`=> (quot -7 2)`)
+
+	Ground.MustSet("rem",
+		Func("rem", "[num denom]", RemNumbers),
+		`rem[ainder] of dividing num by denom`,
+		`The result has the same sign as num, so that (+ (* (quot n d) d) (rem n d)) is n. Errors if denom is zero.`,
+		`=> (rem 7 2)`,
+		`=> (rem -7 2)`)
+
+	Ground.MustSet("mod",
+		Func("mod", "[num denom]", ModNumbers),
+		`mod[ulus] of num by denom`,
+		`Unlike (rem), the result has the same sign as denom, which is usually what you want for wrapping around, e.g. indexing into a list. Errors if denom is zero.`,
+		`=> (mod 7 2)`,
+		`=> (mod -7 2)`)
+
+	Ground.MustSet("abs",
+		Func("abs", "[num]", func(num Number) Number {
+			if CompareNumbers(num, Int(0)) < 0 {
+				return NegateNumber(num)
+			}
+
+			return num
+		}),
+		`returns the absolute value of num`,
+		`=> (abs -42)`)
+
+	Ground.MustSet("floor",
+		Func("floor", "[num]", func(num Number) (Number, error) {
+			return RoundNumber(num, math.Floor)
+		}),
+		`returns the greatest integer less than or equal to num`,
+		`Integers are returned as-is. Errors if num is not finite.`,
+		`=> (floor 3.7)`,
+		`=> (floor -3.2)`)
+
+	Ground.MustSet("ceil",
+		Func("ceil", "[num]", func(num Number) (Number, error) {
+			return RoundNumber(num, math.Ceil)
+		}),
+		`returns the least integer greater than or equal to num`,
+		`Integers are returned as-is. Errors if num is not finite.`,
+		`=> (ceil 3.2)`,
+		`=> (ceil -3.7)`)
+
+	Ground.MustSet("pow",
+		Func("pow", "[base exp]", PowNumbers),
+		`raises base to the power of exp`,
+		`Integers do not overflow; they are promoted to arbitrary precision as needed.`,
+		`The result is a float if either number is a float. Otherwise, errors if exp is negative, since the result would not be an integer, or if exp is too large to compute the result.`,
+		`=> (pow 2 10)`,
+		`=> (pow 2 100)`,
+		`=> (pow 1.5 2)`)
+
+	Ground.MustSet("bit-and",
+		Func("bit-and", "[num & nums]", func(num Number, nums ...Number) (Number, error) {
+			return bitwise(num, nums, func(x, y int) int { return x & y }, (*big.Int).And)
+		}),
+		`returns the bitwise AND of the integers`,
+		`=> (bit-and 12 10)`)
 
-	Ground.Set("quot",
-		Func("quot", "[num denom]", func(num, denom int) int {
-			return num / denom
+	Ground.MustSet("bit-or",
+		Func("bit-or", "[num & nums]", func(num Number, nums ...Number) (Number, error) {
+			return bitwise(num, nums, func(x, y int) int { return x | y }, (*big.Int).Or)
+		}),
+		`returns the bitwise OR of the integers`,
+		`=> (bit-or 1 2 4)`)
+
+	Ground.MustSet("bit-xor",
+		Func("bit-xor", "[num & nums]", func(num Number, nums ...Number) (Number, error) {
+			return bitwise(num, nums, func(x, y int) int { return x ^ y }, (*big.Int).Xor)
+		}),
+		`returns the bitwise XOR of the integers`,
+		`=> (bit-xor 6 3)`)
+
+	Ground.MustSet("bit-not",
+		Func("bit-not", "[num]", func(num Number) (Number, error) {
+			switch x := num.(type) {
+			case Int:
+				return ^x, nil
+			case BigInt:
+				return NewBigInt(new(big.Int).Not(x.int)), nil
+			default:
+				return nil, ErrNotInteger
+			}
 		}),
-		`quot[ient] of dividing num by denum`,
-		`=> (quot 84 2)`)
+		`returns the bitwise complement of num`,
+		`=> (bit-not 0)`)
 
-	Ground.Set("-",
-		Func("-", "[num & nums]", func(num int, nums ...int) int {
+	Ground.MustSet("shift-left",
+		Func("shift-left", "[num n]", func(num Number, n int) (Number, error) {
+			if n < 0 {
+				return nil, ErrNegativeShift
+			}
+
+			if isFloat(num) {
+				return nil, ErrNotInteger
+			}
+
+			if x, ok := num.(Int); ok && n < strconv.IntSize {
+				if shifted := int(x) << n; shifted>>n == int(x) {
+					return Int(shifted), nil
+				}
+			}
+
+			return NewBigInt(new(big.Int).Lsh(bigInt(num), uint(n))), nil
+		}),
+		`shifts the bits of num left by n`,
+		`Integers do not overflow; they are promoted to arbitrary precision as needed. Errors if n is negative.`,
+		`=> (shift-left 1 4)`,
+		`=> (shift-left 1 64)`)
+
+	Ground.MustSet("shift-right",
+		Func("shift-right", "[num n]", func(num Number, n int) (Number, error) {
+			if n < 0 {
+				return nil, ErrNegativeShift
+			}
+
+			switch x := num.(type) {
+			case Int:
+				return x >> n, nil
+			case BigInt:
+				return NewBigInt(new(big.Int).Rsh(x.int, uint(n))), nil
+			default:
+				return nil, ErrNotInteger
+			}
+		}),
+		`shifts the bits of num right by n`,
+		`The sign of num is preserved, i.e. this is an arithmetic shift. Errors if n is negative.`,
+		`=> (shift-right 256 4)`)
+
+	Ground.MustSet("-",
+		Func("-", "[num & nums]", func(num Number, nums ...Number) Number {
 			if len(nums) == 0 {
-				return -num
+				return NegateNumber(num)
 			}
 
 			sub := num
 			for _, num := range nums {
-				sub -= num
+				sub = SubNumbers(sub, num)
 			}
 
 			return sub
@@ -294,11 +639,11 @@ func init() {
 		`=> (- 10 4 1)`,
 		`=> (- 6)`)
 
-	Ground.Set("max",
-		Func("max", "[num & nums]", func(num int, nums ...int) int {
+	Ground.MustSet("max",
+		Func("max", "[num & nums]", func(num Number, nums ...Number) Number {
 			max := num
 			for _, num := range nums {
-				if num > max {
+				if CompareNumbers(num, max) > 0 {
 					max = num
 				}
 			}
@@ -308,11 +653,11 @@ func init() {
 		`returns the largest number`,
 		`=> (max 6 42 7)`)
 
-	Ground.Set("min",
-		Func("min", "[num & nums]", func(num int, nums ...int) int {
+	Ground.MustSet("min",
+		Func("min", "[num & nums]", func(num Number, nums ...Number) Number {
 			min := num
 			for _, num := range nums {
-				if num < min {
+				if CompareNumbers(num, min) < 0 {
 					min = num
 				}
 			}
@@ -322,7 +667,7 @@ func init() {
 		`returns the smallest number`,
 		`=> (min 6 42 7)`)
 
-	Ground.Set("=",
+	Ground.MustSet("=",
 		Func("=", "[val & vals]", func(val Value, others ...Value) bool {
 			for _, other := range others {
 				if !other.Equal(val) {
@@ -338,11 +683,11 @@ func init() {
 		`=> (= {:a 1} {:a 1})`,
 	)
 
-	Ground.Set(">",
-		Func(">", "[num & nums]", func(num int, nums ...int) bool {
+	Ground.MustSet(">",
+		Func(">", "[num & nums]", func(num Number, nums ...Number) bool {
 			min := num
 			for _, num := range nums {
-				if num >= min {
+				if CompareNumbers(num, min) >= 0 {
 					return false
 				}
 
@@ -355,11 +700,11 @@ func init() {
 		`=> (> 9 8 7)`,
 		`=> (> 9 8 8)`)
 
-	Ground.Set(">=",
-		Func(">=", "[num & nums]", func(num int, nums ...int) bool {
+	Ground.MustSet(">=",
+		Func(">=", "[num & nums]", func(num Number, nums ...Number) bool {
 			max := num
 			for _, num := range nums {
-				if num > max {
+				if CompareNumbers(num, max) > 0 {
 					return false
 				}
 
@@ -372,11 +717,11 @@ func init() {
 		`=> (> 9 8 7)`,
 		`=> (> 9 8 8)`)
 
-	Ground.Set("<",
-		Func("<", "[num & nums]", func(num int, nums ...int) bool {
+	Ground.MustSet("<",
+		Func("<", "[num & nums]", func(num Number, nums ...Number) bool {
 			max := num
 			for _, num := range nums {
-				if num <= max {
+				if CompareNumbers(num, max) <= 0 {
 					return false
 				}
 
@@ -389,11 +734,11 @@ func init() {
 		`=> (< 7 8 9)`,
 		`=> (> 8 8 9)`)
 
-	Ground.Set("<=",
-		Func("<=", "[num & nums]", func(num int, nums ...int) bool {
+	Ground.MustSet("<=",
+		Func("<=", "[num & nums]", func(num Number, nums ...Number) bool {
 			max := num
 			for _, num := range nums {
-				if num < max {
+				if CompareNumbers(num, max) < 0 {
 					return false
 				}
 
@@ -406,14 +751,48 @@ func init() {
 		`=> (< 7 8 9)`,
 		`=> (> 8 8 9)`)
 
-	Ground.Set("list->source",
+	Ground.MustSet("list->source",
 		Func("list->source", "[list]", func(list []Value) Value {
 			return &Source{NewInMemorySource(list...)}
 		}),
 		"creates a pipe source from a list of values in chronological order",
 		`=> (list->source [1 2 3])`)
 
-	Ground.Set("across",
+	Ground.MustSet("stream",
+		Func("stream", "[list]", func(list []Value) Value {
+			return &Source{NewInMemorySource(list...)}
+		}),
+		"creates a pipe source from a list of values in chronological order",
+		`Alias for (list->source); the inverse of (collect).`,
+		`=> (next (stream [1 2 3]))`)
+
+	Ground.MustSet("collect",
+		Func("collect", "[source & limit]", func(ctx context.Context, source PipeSource, limit ...int) (List, error) {
+			var vals []Value
+			for {
+				val, err := source.Next(ctx)
+				if err != nil {
+					if errors.Is(err, ErrEndOfSource) {
+						return NewList(vals...), nil
+					}
+
+					return nil, err
+				}
+
+				if len(limit) > 0 && len(vals) >= limit[0] {
+					return nil, CollectLimitError{limit[0]}
+				}
+
+				vals = append(vals, val)
+			}
+		}),
+		`reads all values from a source into a list`,
+		`Blocks until the source ends, so it should not be used with sources that never end, like a stream of commits.`,
+		`A limit may be given to raise an error if the source yields more values, guarding against unexpectedly large or endless sources.`,
+		`=> (collect (stream [1 2 3]))`,
+		`=> (collect (stream [1 2 3]) 3)`)
+
+	Ground.MustSet("across",
 		Func("across", "sources", Across),
 		"returns a pipe source that yields a list of values across all the given sources",
 		`Each list has the last value for each source. Values from each source are never skipped, but not every combination will be produced.`,
@@ -422,14 +801,14 @@ func init() {
 		`=> (def combined (across evens odds))`,
 		`=> [(next combined) (next combined)]`)
 
-	Ground.Set("emit",
+	Ground.MustSet("emit",
 		Func("emit", "[val sink]", func(val Value, sink PipeSink) error {
 			return sink.Emit(val)
 		}),
 		`emits a value to a sink`,
 		`=> (emit {:a 1} *stdout*)`)
 
-	Ground.Set("next",
+	Ground.MustSet("next",
 		Func("next", "[src & default]", func(ctx context.Context, source PipeSource, def ...Value) (Value, error) {
 			val, err := source.Next(ctx)
 			if err != nil {
@@ -447,7 +826,7 @@ func init() {
 		`=> (next (list->source [1]) :eof)`,
 		`=> (next *stdin* :eof)`)
 
-	Ground.Set("reduce-kv",
+	Ground.MustSet("reduce-kv",
 		Wrap(Op("reduce-kv", "[f init kv]", func(ctx context.Context, scope *Scope, fn Applicative, init Value, kv *Scope) (Value, error) {
 			op := fn.Unwrap()
 
@@ -474,7 +853,120 @@ func init() {
 		`=> (reduce-kv assoc {:d 4} {:a 1 :b 2 :c 3})`,
 	)
 
-	Ground.Set("assoc",
+	Ground.MustSet("map",
+		Wrap(Op("map", "[f xs]", func(ctx context.Context, scope *Scope, fn Applicative, xs List) (List, error) {
+			var ys []Value
+			err := callEach(ctx, scope, fn.Unwrap(), xs, func(_, y Value) error {
+				ys = append(ys, y)
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			return NewList(ys...), nil
+		})),
+		`returns a list containing the result of applying f to each member of xs`,
+		`=> (map (fn [x] (* x 7)) [5 6 7])`,
+	)
+
+	Ground.MustSet("filter",
+		Wrap(Op("filter", "[predicate xs]", func(ctx context.Context, scope *Scope, fn Applicative, xs List) (List, error) {
+			var ys []Value
+			err := callEach(ctx, scope, fn.Unwrap(), xs, func(x, res Value) error {
+				var ok bool
+				if err := res.Decode(&ok); err != nil || ok {
+					ys = append(ys, x)
+				}
+
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			return NewList(ys...), nil
+		})),
+		`returns only values from xs which satisfy the predicate`,
+		`A value satisfies the predicate if the result is truthy (not false or null).`,
+		`=> (filter symbol? [:abc 123 :def "456"])`,
+	)
+
+	Ground.MustSet("flat-map",
+		Wrap(Op("flat-map", "[f xs]", func(ctx context.Context, scope *Scope, fn Applicative, xs List) (List, error) {
+			var ys []Value
+			err := callEach(ctx, scope, fn.Unwrap(), xs, func(_, res Value) error {
+				var list List
+				if err := res.Decode(&list); err != nil {
+					return err
+				}
+
+				return Each(list, func(y Value) error {
+					ys = append(ys, y)
+					return nil
+				})
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			return NewList(ys...), nil
+		})),
+		`returns the concatenation of the lists returned by applying f to each member of xs`,
+		`=> (flat-map (fn [x] [x x]) [1 2 3])`,
+	)
+
+	Ground.MustSet("zip",
+		Func("zip", "lists", func(lists ...List) List {
+			var tuples []Value
+			for {
+				tuple := make([]Value, 0, len(lists))
+				for i, list := range lists {
+					if list == (Empty{}) {
+						return NewList(tuples...)
+					}
+
+					tuple = append(tuple, list.First())
+
+					// a malformed list ends the zip early, like a short one
+					if err := list.Rest().Decode(&lists[i]); err != nil {
+						lists[i] = Empty{}
+					}
+				}
+
+				if len(tuple) == 0 {
+					return NewList(tuples...)
+				}
+
+				tuples = append(tuples, NewList(tuple...))
+			}
+		}),
+		`returns a list of lists pairing up the members of each list by index`,
+		`The result is as long as the shortest list.`,
+		`=> (zip [1 2 3] [:a :b :c])`,
+		`=> (zip [1 2 3] [:a])`,
+	)
+
+	Ground.MustSet("concat",
+		Func("concat", "lists", func(lists ...List) (List, error) {
+			var vals []Value
+			for _, list := range lists {
+				err := Each(list, func(v Value) error {
+					vals = append(vals, v)
+					return nil
+				})
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			return NewList(vals...), nil
+		}),
+		`joins all given lists into one list`,
+		`=> (concat [1] [2 3] [4 5 6])`,
+	)
+
+	Ground.MustSet("assoc",
 		Func("assoc", "[obj & kvs]", Assoc),
 		`assoc[iate] keys with values in a clone of a scope`,
 		`Takes a scope and a flat pair sequence alternating symbols and values.`,
@@ -482,31 +974,102 @@ func init() {
 		`=> (assoc {:a 1} :b 2 :c 3)`,
 	)
 
-	Ground.Set("symbol->string",
+	Ground.MustSet("get",
+		Func("get", "[obj key & default]", func(obj Value, key Value, def ...Value) Value {
+			return getOr(lookup(obj, key), def)
+		}),
+		`looks up a key in a scope, returning a default if it is missing`,
+		`The key may be a symbol or a string. Lists may also be indexed by an int, starting at 0.`,
+		`Returns the default, or null if none is given, when the key is missing or the value is not a scope or list, e.g. when consuming loosely-shaped JSON.`,
+		`=> (get {:a 1} :a)`,
+		`=> (get {:a 1} :b 42)`,
+		`=> (get null :a :none)`,
+	)
+
+	Ground.MustSet("get-in",
+		Func("get-in", "[obj keys & default]", func(obj Value, keys []Value, def ...Value) Value {
+			val := obj
+			for _, key := range keys {
+				val = lookup(val, key)
+				if val == nil {
+					break
+				}
+			}
+
+			return getOr(val, def)
+		}),
+		`looks up a path of keys through nested scopes and lists, returning a default if any is missing`,
+		`Each key is looked up as with (get).`,
+		`=> (get-in {:a {:b [1 2 3]}} [:a :b 1])`,
+		`=> (get-in {:a {:b [1 2 3]}} [:a :c :d] :none)`,
+	)
+
+	Ground.MustSet("or-else",
+		Func("or-else", "[val default]", func(val Value, def Value) Value {
+			var null Null
+			if err := val.Decode(&null); err == nil {
+				return def
+			}
+
+			return val
+		}),
+		`returns a default if a value is null`,
+		`Unlike (or), false is returned as-is.`,
+		`=> (or-else null 42)`,
+		`=> (or-else false 42)`,
+	)
+
+	Ground.MustSet("deep-merge",
+		Func("deep-merge", "[a b & strategy]", func(a, b *Scope, strategy ...Symbol) (*Scope, error) {
+			if len(strategy) > 0 {
+				return DeepMergeWith(a, b, MergeStrategy(strategy[0]))
+			}
+
+			return DeepMerge(a, b)
+		}),
+		`merges two scopes, recursively merging scopes bound to the same symbol`,
+		`Other values bound in both scopes are resolved by the strategy: :last-wins (the default) takes the value from b, :list-append appends the lists when both are lists and otherwise takes the value from b, and :error-on-conflict raises an error unless the values are equal.`,
+		`=> (deep-merge {:env {:A "1" :B "2"}} {:env {:B "two"}})`,
+		`=> (deep-merge {:tags ["base"]} {:tags ["extra"]} :list-append)`,
+		`=> (deep-merge {:a 1 :b 2} {:b 2 :c 3} :error-on-conflict)`,
+	)
+
+	Ground.MustSet("json-patch",
+		Func("json-patch", "[val patch]", JSONPatch),
+		`applies a JSON Patch (RFC 6902) to a value`,
+		`The patch is a list of operations, each a scope with an :op and a :path, and a :value or :from depending on the op. Supported ops are "add", "remove", "replace", "move", "copy", and "test".`,
+		`Paths are JSON Pointers (RFC 6901) which index into scopes by key and lists by index. The original value is not modified.`,
+		`=> (json-patch {:metadata {:name "app"}} [{:op "add" :path "/metadata/labels" :value {:tier "web"}}])`,
+		`=> (json-patch {:args ["a" "b"]} [{:op "remove" :path "/args/0"} {:op "add" :path "/args/-" :value "c"}])`,
+	)
+
+	Ground.MustSet("merge-patch",
+		Func("merge-patch", "[val patch]", MergePatch),
+		`applies a JSON Merge Patch (RFC 7386) to a value`,
+		`Scopes in the patch are merged into the value recursively. Null values in the patch remove the binding from the value. Any other value in the patch replaces the corresponding value, including lists.`,
+		`=> (merge-patch {:spec {:replicas 1 :paused true}} {:spec {:replicas 3 :paused null}})`,
+	)
+
+	Ground.MustSet("symbol->string",
 		Func("symbol->string", "[sym]", func(sym Symbol) String {
 			return String(sym)
 		}),
 		`convert a symbol to a string`,
 		`=> (symbol->string :hello!)`)
 
-	Ground.Set("string->symbol",
+	Ground.MustSet("string->symbol",
 		Func("string->symbol", "[str]", func(str String) Symbol {
 			return Symbol(str)
 		}),
 		`convert a string to a symbol`,
 		`=> (string->symbol "hello!")`)
 
-	Ground.Set("str",
+	Ground.MustSet("str",
 		Func("str", "vals", func(vals ...Value) String {
 			var str string = ""
 
 			for _, v := range vals {
-				var s string
-				if err := v.Decode(&s); err == nil {
-					str += s
-				} else {
-					str += v.String()
-				}
+				str += stringify(v)
 			}
 
 			return String(str)
@@ -514,33 +1077,181 @@ func init() {
 		`returns the concatenation of all given strings or values`,
 		`=> (str "abc" 123 "def" 456)`)
 
-	Ground.Set("substring",
-		Func("substring", "[str start & end]", func(str String, start Int, endOptional ...Int) (String, error) {
+	Ground.MustSet("substring",
+		Func("substring", "[str start & end]", func(str string, start int, endOptional ...int) (String, error) {
+			runes := []rune(str)
+
+			end := len(runes)
 			switch len(endOptional) {
 			case 0:
-				return str[start:], nil
 			case 1:
-				return str[start:endOptional[0]], nil
+				end = endOptional[0]
 			default:
-				// TODO: test
 				return "", ArityError{
 					Name: "substring",
 					Need: 3,
 					Have: 4,
 				}
 			}
+
+			if start < 0 || end > len(runes) || start > end {
+				return "", fmt.Errorf("substring: range [%d:%d] out of bounds for length %d", start, end, len(runes))
+			}
+
+			return String(runes[start:end]), nil
 		}),
 		`returns a portion of a string`,
 		`With one number supplied, returns the portion from the offset to the end.`,
 		`With two numbers supplied, returns the portion between the first offset and the last offset, exclusive.`,
+		`Offsets count characters, not bytes.`,
 		`=> (substring "abcdef" 2 4)`)
 
-	Ground.Set("trim",
-		Func("trim", "[str]", strings.TrimSpace),
+	Ground.MustSet("str-index",
+		Func("str-index", "[str substr]", func(str, substr string) Value {
+			idx := strings.Index(str, substr)
+			if idx == -1 {
+				return Null{}
+			}
+
+			return Int(utf8.RuneCountInString(str[:idx]))
+		}),
+		`returns the offset of the first occurrence of substr in str`,
+		`Returns null if str does not contain substr. The offset counts characters, not bytes, so it can be passed to substring.`,
+		`=> (str-index "hello world" "world")`,
+		`=> (str-index "hello" "bye")`)
+
+	Ground.MustSet("split",
+		Func("split", "[delim str]", func(delim, str string) []string {
+			return strings.Split(str, delim)
+		}),
+		`splits a string on a delimiter`,
+		`=> (split "/" "feature/add-widgets")`)
+
+	Ground.MustSet("join",
+		Func("join", "[delim vals]", func(delim string, vals []Value) string {
+			strs := make([]string, len(vals))
+			for i, v := range vals {
+				strs[i] = stringify(v)
+			}
+
+			return strings.Join(strs, delim)
+		}),
+		`joins a list of strings or values together with delim in between`,
+		`Values which are not strings are joined as they would be by (str).`,
+		`=> (join ", " ["hello" "world"])`,
+		`=> (join "." [1 2 3])`)
+
+	Ground.MustSet("trim",
+		Func("trim", "[str & cutset]", func(str string, cutset ...string) (string, error) {
+			switch len(cutset) {
+			case 0:
+				return strings.TrimSpace(str), nil
+			case 1:
+				return strings.Trim(str, cutset[0]), nil
+			default:
+				return "", ArityError{
+					Name: "trim",
+					Need: 2,
+					Have: 3,
+				}
+			}
+		}),
 		`removes whitespace from both ends of a string`,
-		`=> (trim " hello world!\n ")`)
+		`If a cutset is given, removes any of its characters instead.`,
+		`=> (trim " hello world!\n ")`,
+		`=> (trim "--hello--" "-")`)
+
+	Ground.MustSet("replace",
+		Func("replace", "[str old new & n]", func(str, old, new string, n ...int) (string, error) {
+			switch len(n) {
+			case 0:
+				return strings.ReplaceAll(str, old, new), nil
+			case 1:
+				return strings.Replace(str, old, new, n[0]), nil
+			default:
+				return "", ArityError{
+					Name: "replace",
+					Need: 4,
+					Have: 5,
+				}
+			}
+		}),
+		`replaces occurrences of old in str with new`,
+		`Replaces every occurrence unless a number n is given, in which case only the first n are replaced.`,
+		`=> (replace "feature/add widgets" "/" "-")`,
+		`=> (replace "a.b.c" "." "" 1)`)
+
+	Ground.MustSet("upper",
+		Func("upper", "[str]", strings.ToUpper),
+		`converts all letters in a string to upper case`,
+		`=> (upper "hello")`)
+
+	Ground.MustSet("lower",
+		Func("lower", "[str]", strings.ToLower),
+		`converts all letters in a string to lower case`,
+		`=> (lower "Feature/Add-Widgets")`,
+		`=> (join "-" (split " " (lower (replace "Fix Bug/123" "/" " "))))`)
+
+	Ground.MustSet("parse-int",
+		Func("parse-int", "[str & radix]", func(str string, radix ...int) (int, error) {
+			base := 10
+			if len(radix) > 0 {
+				base = radix[0]
+			}
+
+			num, err := strconv.ParseInt(str, base, 0)
+			if err != nil {
+				return 0, err
+			}
+
+			return int(num), nil
+		}),
+		`parses an integer from a string`,
+		`The radix defaults to 10. A radix of 0 infers it from a 0b, 0o, or 0x prefix.`,
+		`=> (parse-int "42")`,
+		`=> (parse-int "755" 8)`,
+		`=> (parse-int "0xff" 0)`)
+
+	Ground.MustSet("format",
+		Func("format", "[template & vals]", func(template string, vals ...Value) String {
+			return String(Format(template, vals...))
+		}),
+		`renders values into a printf-style template`,
+		`Strings are rendered as-is by %s, numbers by %d or %f, and any value by %v as Bass would print it, so strings are quoted.`,
+		`Other values are rendered for humans by %s: host paths as paths on the host, thunks as their name, and thunk paths as the thunk's name followed by the path. %j renders any value as JSON.`,
+		`=> (format "%s is %d years old" "bass" 3)`,
+		`=> (format "%v" {:a 1})`,
+		`=> (format "%s vs. %v" "str" "str")`,
+		`=> (format "app:%s" ($ go build ./...))`,
+		`=> (format "config: %j" {:replicas 3 :region "us-east-1"})`)
+
+	Ground.MustSet("pad-left",
+		Func("pad-left", "[str width & pad]", func(str string, width int, pad ...string) String {
+			p := " "
+			if len(pad) > 0 {
+				p = pad[0]
+			}
+
+			return String(PadLeft(str, width, p))
+		}),
+		`pads the start of a string until it is width characters long`,
+		`Pads with spaces unless another padding string is given.`,
+		`=> (pad-left "42" 5 "0")`)
+
+	Ground.MustSet("pad-right",
+		Func("pad-right", "[str width & pad]", func(str string, width int, pad ...string) String {
+			p := " "
+			if len(pad) > 0 {
+				p = pad[0]
+			}
 
-	Ground.Set("scope->list",
+			return String(PadRight(str, width, p))
+		}),
+		`pads the end of a string until it is width characters long`,
+		`Pads with spaces unless another padding string is given.`,
+		`=> (str (pad-right "name" 8) "|")`)
+
+	Ground.MustSet("scope->list",
 		Func("scope->list", "[obj]", func(obj *Scope) List {
 			var vals []Value
 			_ = obj.Each(func(k Symbol, v Value) error {
@@ -555,7 +1266,7 @@ func init() {
 		`=> (scope->list {:a 1 :b 2 :c 3})`,
 		`=> (apply assoc (cons {:d 4} (scope->list {:a 1 :b 2 :c 3})))`)
 
-	Ground.Set("string->fs-path",
+	Ground.MustSet("string->fs-path",
 		Func("string->fspath", "[str]", func(s string) FilesystemPath {
 			return ParseFileOrDirPath(s).FilesystemPath()
 		}),
@@ -564,7 +1275,7 @@ func init() {
 		`=> (string->fs-path "file")`,
 		`=> (string->fs-path "dir/")`)
 
-	Ground.Set("string->cmd-path",
+	Ground.MustSet("string->cmd-path",
 		Func("string->cmd-path", "[str]", func(s string) Path {
 			if !strings.Contains(s, "/") {
 				return CommandPath{s}
@@ -587,7 +1298,7 @@ func init() {
 		`=> (string->cmd-path "scripts/foo")`,
 		`=> (string->cmd-path "bash")`)
 
-	Ground.Set("string->dir",
+	Ground.MustSet("string->dir",
 		Func("string->dir", "[str]", func(s string) DirPath {
 			fod := ParseFileOrDirPath(s)
 
@@ -604,13 +1315,13 @@ func init() {
 		`=> (string->dir "dir")`,
 		`=> (string->dir "dir/")`)
 
-	Ground.Set("subpath",
+	Ground.MustSet("subpath",
 		Func("subpath", "[parent-dir child-path]", (Path).Extend),
 		`extend path with another path`,
 		`=> (subpath ./dir/ ./file)`,
 		`=> (subpath (.tests) ./coverage.html)`)
 
-	Ground.Set("path-name",
+	Ground.MustSet("path-name",
 		Func("path-name", "[path]", (Path).Name),
 		`returns the base name of the path`,
 		`For a command path, this returns the command name.`,
@@ -623,7 +1334,7 @@ func init() {
 		`=> (path-name (.tests))`,
 	)
 
-	Ground.Set("path-stem",
+	Ground.MustSet("path-stem",
 		Func("path-stem", "[path]", func(p Path) string {
 			name := p.Name()
 
@@ -641,8 +1352,66 @@ func init() {
 		`=> (path-stem (.tests))`,
 	)
 
+	Ground.MustSet("stat",
+		Func("stat", "[path]", func(ctx context.Context, p Path) (Value, error) {
+			info, err := Stat(ctx, p)
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return Null{}, nil
+				}
+
+				return nil, err
+			}
+
+			return info.Scope(), nil
+		}),
+		`returns metadata about a file or directory`,
+		`Returns a scope with :type ("file", "dir", "symlink", or "other"), :size in bytes, :mode permission bits, and :mtime in seconds since the Unix epoch. Symlinks are not followed; their :target is included instead.`,
+		`Returns null if a host path does not exist.`,
+		`For a thunk path, the metadata is fetched from the thunk's runtime. A directory's :size is the total size of its files and its :mtime is that of its most recently modified file.`,
+		`=> (stat *dir*)`,
+		`=> (when (stat *dir*/out.tar) (log "already built"))`)
+
+	Ground.MustSet("path-base",
+		Func("path-base", "[path]", PathBase),
+		`returns the last element of the path as a relative path`,
+		`Works with file and dir paths along with paths into thunks, host directories, and embedded filesystems. Errors for command paths.`,
+		`=> (path-base ./some/file.bass)`,
+		`=> (path-base ./some/dir/)`,
+		`=> (path-base (.tests)/out/)`)
+
+	Ground.MustSet("path-dir",
+		Func("path-dir", "[path]", PathDir),
+		`returns the directory containing the path`,
+		`For paths into thunks, host directories, and embedded filesystems, the directory is within the same thunk, host directory, or filesystem.`,
+		`=> (path-dir ./some/file.bass)`,
+		`=> (path-dir ./some/dir/)`,
+		`=> (path-dir (.tests)/out/report.html)`)
+
+	Ground.MustSet("path-ext",
+		Func("path-ext", "[path]", PathExt),
+		`returns the extension of the path's name, including the leading dot`,
+		`Returns an empty string if the name has no extension. Errors for command paths.`,
+		`=> (path-ext ./some/file.bass)`,
+		`=> (path-ext ./some/dir/)`)
+
+	Ground.MustSet("path-join",
+		Func("path-join", "[path & paths]", PathJoin),
+		`extends path with each of the given paths in order`,
+		`Equivalent to calling (subpath) repeatedly.`,
+		`=> (path-join ./src/ ./pkg/ ./main.go)`,
+		`=> (path-join (.tests) ./out/ ./report.html)`)
+
+	Ground.MustSet("relative-to",
+		Func("relative-to", "[path base-dir]", RelativeTo),
+		`returns path relative to base-dir`,
+		`The result extends base-dir to refer to path, i.e. (subpath base-dir (relative-to path base-dir)) is path.`,
+		`Both paths must be plain paths or be within the same thunk, host directory, or embedded filesystem.`,
+		`=> (relative-to ./src/pkg/main.go ./src/)`,
+		`=> (relative-to ./docs/ ./src/pkg/)`)
+
 	// thunk constructors
-	Ground.Set("with-image",
+	Ground.MustSet("with-image",
 		Func("with-image", "[thunk image]", (Thunk).WithImage),
 		`returns thunk with the base image set to image`,
 		`Image is either a thunk? or an image ref.`,
@@ -651,62 +1420,199 @@ func init() {
 		`=> (with-image ($ go test ./...) (linux/golang))`,
 		`=> (from (linux/golang) ($ go test ./...))`)
 
-	Ground.Set("with-dir",
+	Ground.MustSet("commit",
+		Func("commit", "[thunk]", func(ctx context.Context, thunk Thunk) (ThunkImage, error) {
+			if err := thunk.Run(ctx); err != nil {
+				return ThunkImage{}, err
+			}
+
+			return ThunkImage{Thunk: &thunk}, nil
+		}),
+		`runs a thunk and returns it as an image for other thunks`,
+		`Thunks using the image start from the filesystem the committed thunk left behind, including its working directory. The thunk runs once, when committed, so a failure surfaces right away rather than in each thunk using it.`,
+		`Nothing is exported or pushed; the image is only usable by thunks run by the same runtime.`,
+		`=> (def deps (commit (from (linux/node) ($ npm install))))`,
+		`=> (run (from deps ($ npm test)))`,
+		`=> (run (from deps ($ npm run lint)))`)
+
+	Ground.MustSet("with-dir",
 		Func("with-dir", "[thunk dir]", (Thunk).WithDir),
 		`returns thunk with the working directory set to dir`,
 		`Unlike (cd), the value of (with-dir) is resolved at runtime, meaning it can use container-local paths.`,
 		`If the thunk needs to write to its output directory, the output path passed to the command must be relative to the given dir. Thunk paths and other mounts will always be 1 level deep in the output directory, so use ../ to refer to back to the output directory, repeated for each additional level of depth. If the depth is unknown, you should use (cd) instead.`,
 		`=> (with-dir (.tests) ./src/)`)
 
-	Ground.Set("with-args",
+	Ground.MustSet("with-args",
 		Func("with-args", "[thunk args]", (Thunk).WithArgs),
 		`returns thunk with args set to args`,
 		`=> (with-args (.go) ["test" "./..."])`)
 
-	Ground.Set("with-cmd",
+	Ground.MustSet("with-cmd",
 		Func("with-cmd", "[thunk cmd]", (Thunk).WithCmd),
 		`returns thunk with cmd set to cmd`,
 		`=> (let [inner (with-args (.go) ["build"])] (with-args (with-cmd inner ./wrapped) (cons (thunk-cmd inner) (thunk-args inner))))`)
 
-	Ground.Set("with-stdin",
-		Func("with-stdin", "[thunk vals]", (Thunk).WithStdin),
+	Ground.MustSet("with-stdin",
+		Func("with-stdin", "[thunk vals]", func(ctx context.Context, thunk Thunk, vals Value) (Thunk, error) {
+			var src PipeSource
+			if err := vals.Decode(&src); err == nil {
+				stdin, err := DrainSource(ctx, src)
+				if err != nil {
+					return Thunk{}, err
+				}
+
+				return thunk.WithStdin(stdin), nil
+			}
+
+			var file ThunkMountSource
+			if err := vals.Decode(&file); err == nil && file.Cache == nil && file.Secret == nil {
+				return thunk.WithStdinFile(file), nil
+			}
+
+			var list List
+			if err := vals.Decode(&list); err != nil {
+				return Thunk{}, err
+			}
+
+			stdin, err := ToSlice(list)
+			if err != nil {
+				return Thunk{}, err
+			}
+
+			return thunk.WithStdin(stdin), nil
+		}),
 		`returns thunk with stdin set to vals`,
-		`=> (with-stdin ($ jq ".a") [{:a 1} {:a 2}])`)
+		`Vals may be a list or a source, in which case all of its remaining values are read. Passing *stdin* feeds the values piped to bass into the thunk.`,
+		`Vals may also be a host path, thunk path, or embedded path, which the runtime streams to the command as with (with-stdin-file).`,
+		`=> (with-stdin ($ jq ".a") [{:a 1} {:a 2}])`,
+		`=> (with-stdin ($ jq ".a") (list->source [{:a 1} {:a 2}]))`)
+
+	Ground.MustSet("with-stdin-file",
+		Func("with-stdin-file", "[thunk file]", func(thunk Thunk, file Value) (Thunk, error) {
+			var heredoc String
+			if err := file.Decode(&heredoc); err == nil {
+				file = NewInMemoryFile("stdin", string(heredoc))
+			}
 
-	Ground.Set("with-env",
+			var src ThunkMountSource
+			if err := file.Decode(&src); err != nil {
+				return Thunk{}, err
+			}
+
+			return thunk.WithStdinFile(src), nil
+		}),
+		`returns thunk with the content of file streamed to its stdin`,
+		`File may be a host path, thunk path, or embedded path. The runtime streams its content to the command after any values set by (with-stdin), so it is never read into memory.`,
+		`File may also be a string, which is passed as-is like a heredoc.`,
+		`=> (with-stdin-file ($ wc -l) *dir*/README.md)`,
+		`=> (with-stdin-file ($ wc -l) "one\ntwo\n")`)
+
+	Ground.MustSet("|",
+		Func("|", "[thunk & thunks]", func(thunk Thunk, thunks ...Thunk) Thunk {
+			for _, next := range thunks {
+				thunk = thunk.Pipe(next)
+			}
+
+			return thunk
+		}),
+		`pipes each thunk's stdout to the next thunk's stdin`,
+		`Returns the last thunk, with its stdin file set to the stdout of the thunk before it, and so on. Intermediate output is streamed within the runtime rather than read into Bass, and the pipeline is cached as a single thunk.`,
+		`Each thunk may run with a different image, and any stdin values set by (with-stdin) are still sent first.`,
+		`=> (| ($ echo "hello, world!") ($ tr "a-z" "A-Z") ($ rev))`)
+
+	Ground.MustSet("with-env",
 		Func("with-env", "[thunk env]", (Thunk).WithEnv),
 		`returns thunk with env set to the given env`,
 		`=> (with-env ($ jq ".a") {:FOO "hello"})`)
 
-	Ground.Set("with-insecure",
+	Ground.MustSet("with-inherited-env",
+		Wrap(Op("with-inherited-env", "[thunk patterns]", func(scope *Scope, thunk Thunk, patterns []string) (Thunk, error) {
+			env := NewEmptyScope()
+			if val, found := scope.Get(RunBindingEnv); found {
+				if err := val.Decode(&env); err != nil {
+					return Thunk{}, fmt.Errorf("with-inherited-env: %s: %w", RunBindingEnv, err)
+				}
+			}
+
+			return thunk.WithInheritedEnv(env, patterns)
+		})),
+		`returns thunk with vars from *env* matching any of the glob patterns added to its env`,
+		`The values are read from the *env* of the calling scope when (with-inherited-env) is called, not when the thunk runs, so like *env* they are only available to the entrypoint script. They are passed as secrets, so they are not revealed when the thunk is displayed.`,
+		`Their names are visible, so the thunk shows which vars it inherits, and they are part of the thunk's hash: a var which is set in one run but not another results in a different thunk. Their values are not, so a cached result is reused even if a value changes.`,
+		`Vars already set in the thunk's env take precedence. Set a var with (with-env) to include its value in the hash.`,
+		`=> (with-inherited-env ($ env) ["CI" "GITHUB_*"])`)
+
+	Ground.MustSet("with-insecure",
 		Func("with-insecure", "[thunk bool]", (Thunk).WithInsecure),
 		`returns thunk with the insecure flag set to bool`,
 		`The insecure flag determines whether the thunk runs with elevated privileges, and is named to be indicate the reduced security assumptions.`,
 		`=> (with-insecure (.boom) true)`,
 		`=> (= (.boom) (with-insecure (.boom) false))`)
 
-	Ground.Set("with-label",
+	Ground.MustSet("with-retries",
+		Func("with-retries", "[thunk int]", (Thunk).WithRetries),
+		`returns thunk marked as flaky, retrying up to int times if it fails`,
+		`Retries apply when the thunk is run with (run), (succeeds?), or (start). Each run of a flaky thunk is recorded in the run history along with how many attempts it took, so that flakes can be tracked over time with bass --flakes.`,
+		`Retries do not change the thunk's hash, so a thunk with retries is equal to the same thunk without them.`,
+		`=> (with-retries ($ go test ./...) 2)`,
+		`=> (= (.boom) (with-retries (.boom) 3))`)
+
+	Ground.MustSet("with-label",
 		Func("with-label", "[thunk name val]", (Thunk).WithLabel),
 		`returns thunk with the label set to val`,
 		`Labels are typically used to control caching. Two thunks that differ only in labels will evaluate separately and produce independent results.`,
 		`=> (with-label ($ sleep 10) :at (now 10))`)
 
-	Ground.Set("with-port",
+	Ground.MustSet("with-port",
 		Func("with-port", "[thunk sym int]", (Thunk).WithPort),
 		`returns thunk with a named port appended to its ports`,
 		`=> (with-port ($ godoc -http=:6060) :godoc 6060)`)
 
-	Ground.Set("with-tls",
+	Ground.MustSet("with-tls",
 		Func("with-tls", "[thunk cert-path key-path]", (Thunk).WithTLS),
 		`returns thunk with paths to a TLS certificate and key to generate`,
 		`=> (with-tls ($ godoc -http=:6060) ./cert.pem ./key.pem)`)
 
-	Ground.Set("with-mount",
+	Ground.MustSet("with-mount",
 		Func("with-mount", "[thunk source target]", (Thunk).WithMount),
 		`returns thunk with a mount from source to the target path`,
 		`=> (with-mount ($ find ./inputs/) *dir*/inputs/ ./inputs/)`)
 
-	Ground.Set("thunk-cmd",
+	Ground.MustSet("with-services",
+		Func("with-services", "[thunk services & opts]", (Thunk).WithServices),
+		`returns thunk with addrs for each service set in its env`,
+		`Takes a scope mapping names to services. A service is either a thunk or a scope with a :thunk, an optional :depends-on list of other service names, and an optional :health check thunk. Each service thunk must provide at least one port.`,
+		`For each port, NAME_PORT_ADDR is set to "$host:$port". The first port of each service is also set as NAME_ADDR, and its host as NAME_HOST.`,
+		`Each service may also be reached by its name from the thunk and from the services that depend on it, e.g. db:5432, on runtimes which support it. Names are lowercased with _ replaced by -, and names which are not then valid DNS labels are not published.`,
+		`The name is recorded as a label on the service thunk, so the same thunk given two names runs as two separate services.`,
+		`Services are given the addrs of the services they depend on, so they are started in dependency order. A service's health check is run by the runtime against each instance it starts, with the instance's addrs in env, and retried until it passes before anything using the service runs. Health checks are never cached.`,
+		`Accepts an optional scope of options. Its :deadline field configures the number of seconds each health check may take to pass, defaulting to 60.`,
+		`The services are started before the thunk runs, once their ports are ready, and stopped after it exits.`,
+		`=> (def db (-> ($ postgres) (with-port :pg 5432)))`,
+		`=> (with-services ($ psql) {:db db})`,
+		`=> (def app (-> ($ app) (with-port :http 80)))`,
+		`=> (with-services ($ run-tests) {:db {:thunk db :health (with-env ($ pg_isready) {:PGHOST "db"})} :app {:thunk app :depends-on [:db]}} {:deadline 30})`)
+
+	Ground.MustSet("thunk",
+		Func("thunk", "[base & overrides]", func(base Thunk, overrides ...ThunkOverrides) (Thunk, error) {
+			var err error
+			for _, o := range overrides {
+				base, err = base.Merge(o)
+				if err != nil {
+					return Thunk{}, err
+				}
+			}
+
+			return base, nil
+		}),
+		`returns base with fields set from each overrides scope`,
+		`Overrides may set :image, :insecure, :cmd, :args, :stdin, :env, :dir, :mounts, :labels, :ports, and :tls.`,
+		`Env and labels are deep-merged into the base thunk's. Mounts are added, replacing any mount with the same target, and ports are added from a scope mapping names to port numbers. All other fields are replaced.`,
+		`See also (defthunk).`,
+		`=> (def go-base (with-env ($ go) {:CGO_ENABLED "0"}))`,
+		`=> (thunk go-base {:args ["test" "./..."] :env {:GOFLAGS "-mod=mod"}})`)
+
+	Ground.MustSet("thunk-cmd",
 		Func("thunk-cmd", "[thunk]", func(thunk Thunk) Value {
 			return thunk.Cmd.ToValue()
 		}),
@@ -714,7 +1620,7 @@ func init() {
 		`=> (thunk-cmd (.foo))`,
 		`=> (thunk-cmd (./foo))`)
 
-	Ground.Set("thunk-args",
+	Ground.MustSet("thunk-args",
 		Func("thunk-args", "[thunk]", func(thunk Thunk) Value {
 			return NewList(thunk.Args...)
 		}),
@@ -722,26 +1628,126 @@ func init() {
 		`=> (thunk-args ($ foo abc))`,
 		`=> (thunk-args ($ foo))`)
 
-	Ground.Set("load",
+	Ground.MustSet("load",
 		Func("load", "[thunk]", Bass.Load),
 		`load a thunk as a module`,
 		`This is the primitive mechanism for loading other Bass code.`,
 		`Typically used in combination with *dir* to load paths relative to the current file's directory.`,
 		`=> (load (.strings))`)
 
-	Ground.Set("resolve",
-		Func("resolve", "[platform ref]", func(ctx context.Context, ref ImageRef) (ImageRef, error) {
-			runtime, err := RuntimeFromContext(ctx, ref.Platform)
+	Ground.MustSet("run-script",
+		Wrap(Op("run-script", "[script & args]", func(ctx context.Context, scope *Scope, script Value, args ...Value) (Value, error) {
+			ctx, cmd, err := scriptCmd(ctx, scope, script)
 			if err != nil {
-				return ImageRef{}, err
+				return nil, err
 			}
 
-			return runtime.Resolve(ctx, ref)
-		}),
+			thunk := Thunk{
+				Cmd:  cmd,
+				Args: args,
+			}
+
+			return Bass.RunScript(ForkTrace(ctx), thunk, thunk.RunState(io.Discard))
+		})),
+		`runs a script and returns the result of its (main)`,
+		`The script is evaluated in a fresh scope with its own *dir*, an empty *env* and *vars*, and its own stack trace, but shares the runtimes and caches of the current run.`,
+		`The script may also be a string referring to a script in an OCI image, like oci://ghcr.io/acme/pipelines:v1#/ci/main.bass. The script's directory is fetched from the image, and a tag is pinned to the digest it first resolves to in the bass.lock file in *dir*.`,
+		`Args are passed to (main) as-is, so unlike running the script from the command line they may be any value. Values emitted to the script's *stdout* are discarded; return them from (main) instead.`,
+		`=> (run-script *dir*/build.bass "v1.0")`,
+		`=> (run-script "oci://ghcr.io/acme/pipelines:v1#/ci/test.bass" *dir*)`)
+
+	Ground.MustSet("resolve",
+		Func("resolve", "[platform ref]", ResolveImage),
 		`resolve an image reference to its most exact form`,
+		`Each ref is only resolved once per run, so repeated calls with a tag like "latest" return the same digest. With --resolve-ttl, resolutions are saved to the script's bass.lock and reused by later runs until they expire.`,
 		`=> (resolve {:platform {:os "linux"} :repository "golang" :tag "latest"})`)
 
-	Ground.Set("start",
+	Ground.MustSet("select-image",
+		Func("select-image", "[images]", SelectImage),
+		`returns the image matching the platform of an available runtime`,
+		`Takes a scope mapping platforms to images. Each platform is either an os and arch separated by a hyphen, like linux-arm64, or an os alone to match any arch.`,
+		`Runtimes are checked in order of configuration, so the first runtime with a matching image wins. An image ref without an arch is pinned to the arch of the matching runtime.`,
+		`=> (from (select-image {:linux-amd64 (linux/alpine) :linux-arm64 (linux/arm64v8/alpine)}) ($ uname -m))`)
+
+	Ground.MustSet("last-success",
+		Func("last-success", "[category]", func(category String) (Value, error) {
+			record, found, err := LastSuccess(string(category))
+			if err != nil {
+				return nil, err
+			}
+
+			if !found {
+				return Null{}, nil
+			}
+
+			return record.ToValue(), nil
+		}),
+		`returns the most recent successful run in the category from the run history, or null`,
+		`Each script run by the bass command is recorded in the run history. Its category defaults to the script's name, and can be set with --category.`,
+		`The run is returned as a scope with :category, :script, :script-digest, :thunks, :started, :duration-ms, and :status fields, along with any :approvals of gates.`,
+		`=> (last-success "test")`,
+		`=> (when (last-success "test:abc123") (log "tests passed for abc123"))`)
+
+	Ground.MustSet("cache-stats",
+		Func("cache-stats", "[]", func(ctx context.Context) Value {
+			stats, ok := CacheStatsFromContext(ctx)
+			if !ok {
+				return CacheReport{}.ToValue()
+			}
+
+			return stats.Report().ToValue()
+		}),
+		`returns the cache hits and misses of the steps run so far`,
+		`The stats are returned as a scope with :hits, :misses, :hit-percent, :bytes-reused, and :time-saved-ms fields.`,
+		`Time saved is estimated from how long each cached step took the last time it ran, so it only counts steps which have been seen running before.`,
+		`Bytes reused are counted from the runtime's cache records once the run finishes, so they are 0 until then.`,
+		`=> (cache-stats)`,
+		`=> (let [{:hits h :misses m} (cache-stats)] (log "cache efficiency" :hits h :misses m))`)
+
+	Ground.MustSet("watch-image",
+		Func("watch-image", "[ref & opts]", WatchImage),
+		`returns a source which emits an image ref each time its tag moves`,
+		`The ref is either an image ref scope or a "repository:tag" string, which defaults to the linux platform and the latest tag.`,
+		`The tag is resolved every :interval seconds (default 60), and the ref is emitted with its new :digest whenever the digest changes. The first digest seen is emitted right away.`,
+		`If :memos is given, e.g. *dir*/bass.lock, the last digest seen is memoized there so that a restarted watch only emits once the tag moves again.`,
+		`=> (def updates (watch-image "golang:1.19"))`,
+		`=> (each updates (fn [ref] (run (from ref ($ go version)))))`)
+
+	Ground.MustSet("image-config",
+		Func("image-config", "[ref]", func(ctx context.Context, refVal Value) (ImageConfig, error) {
+			ref, err := decodeImageRefArg(refVal)
+			if err != nil {
+				return ImageConfig{}, err
+			}
+
+			return ResolveImageConfig(ctx, ref)
+		}),
+		`returns the config of an image without pulling its layers`,
+		`The ref is either an image ref scope or a "repository:tag" string, which defaults to the linux platform.`,
+		`Returns a scope with :env, :entrypoint, :cmd, :workdir, :user, :labels, and :exposed-ports bindings.`,
+		`=> (:labels (image-config "golang:1.19"))`,
+		`=> (:env (image-config (linux/alpine)))`)
+
+	Ground.MustSet("watch-git",
+		Func("watch-git", "[repo ref & opts]", WatchGit),
+		`returns a source which emits each commit pushed to a git ref, oldest first`,
+		`Commits are emitted as scopes with :sha, :author, :message, and :time bindings.`,
+		`Git commands run in thunks from the :image option, which is required. The ref is resolved every :interval seconds (default 60). The first time a ref is seen, only its current commit is emitted. If the ref is force-pushed, only the new commit is emitted.`,
+		`If :memos is given, e.g. *dir*/bass.lock, the last commit emitted is memoized there so that a restarted watch resumes where it left off.`,
+		`See also (git:watch), which passes the git image given to the module.`,
+		`=> (def commits (watch-git "https://github.com/vito/bass" "main" {:image (linux/alpine/git)}))`,
+		`=> (each commits (fn [commit] (log "testing" :sha commit:sha)))`)
+
+	Ground.MustSet("prefetch",
+		Func("prefetch", "thunks", func(ctx context.Context, thunks ...Thunk) {
+			StartPrefetch(ctx, thunks...)
+		}),
+		`starts pulling the images and syncing the host paths used by thunks in the background`,
+		`Walks each thunk and the thunks it depends on, collecting every image reference and mounted host path, and fetches them concurrently so that the network and disk time overlaps with evaluation.`,
+		`Returns null immediately. Failures are logged, since running the thunks will try again.`,
+		`=> (prefetch (from (linux/alpine) ($ echo "Hello, world!")))`)
+
+	Ground.MustSet("start",
 		Func("start", "[thunk handler]", func(ctx context.Context, thunk Thunk, handler Combiner) (Combiner, error) {
 			return thunk.Start(ctx, handler)
 		}),
@@ -755,13 +1761,13 @@ func init() {
 		`=> ((start (from (linux/alpine) ($ banana)) raiser))`,
 		`=> ((start (from (linux/alpine) ($ echo)) raiser))`)
 
-	Ground.Set("addr", Func("addr", "[thunk port & fmt]", (Thunk).Addr),
+	Ground.MustSet("addr", Func("addr", "[thunk port & fmt]", (Thunk).Addr),
 		`returns an address for a port provided by the thunk`,
 		`Takes an optional format argument which defaults to "$host:$port".`,
 		`=> (def thunk (-> ($ python -m http.server) (with-port :http 8080)))`,
 		`=> (addr thunk :http)`)
 
-	Ground.Set("wait",
+	Ground.MustSet("wait",
 		Func("wait", "[]", func(ctx context.Context) error {
 			return RunsFromContext(ctx).Wait()
 		}),
@@ -770,7 +1776,34 @@ func init() {
 		`=> (defn echo-server [msg] (start (from (linux/alpine) ($ sleep 1 $msg)) null?))`,
 		`=> (wait)`)
 
-	Ground.Set("read",
+	Ground.MustSet("interact",
+		Func("interact", "[thunk]", func(ctx context.Context, thunk Thunk) error {
+			return thunk.InteractTerminal(ctx)
+		}),
+		`runs a thunk attached to the terminal running bass`,
+		`The thunk's command is given a pseudo-terminal which follows the size of the user's terminal, and keystrokes are passed through as-is, so that REPLs, installers, and editors work as usual.`,
+		`Errors when bass is not running interactively. The thunk's stdin is ignored, and the result is never cached.`,
+		`=> (interact (from (linux/alpine) ($ sh)))`)
+
+	Ground.MustSet("workspace",
+		Func("workspace", "[]", func(ctx context.Context) (HostPath, error) {
+			ws, ok := WorkspaceFromContext(ctx)
+			if !ok {
+				return HostPath{}, ErrNoWorkspace
+			}
+
+			dir, err := ws.Dir()
+			if err != nil {
+				return HostPath{}, err
+			}
+
+			return NewHostDir(dir), nil
+		}),
+		`returns a temporary host directory scoped to the current run`,
+		`The same directory is returned for the rest of the run, and it is removed once the run finishes. Pass --keep-workspace to keep it around for debugging.`,
+		`=> (workspace)`)
+
+	Ground.MustSet("read",
 		Func("read", "[thunk-or-file protocol]", func(ctx context.Context, read Readable, proto Symbol) (*Source, error) {
 			sink := NewInMemorySink()
 
@@ -795,12 +1828,50 @@ func init() {
 		`=> (next (read file-thunk/file :json))`,
 	)
 
-	Ground.Set("cache-dir",
+	Ground.MustSet("diff",
+		Func("diff", "[a b]", func(ctx context.Context, a, b Value) (List, error) {
+			var pa, pb ThunkPath
+			if a.Decode(&pa) == nil && b.Decode(&pb) == nil {
+				return DiffPaths(ctx, pa, pb)
+			}
+
+			return DiffValuesList(a, b), nil
+		}),
+		`returns the differences between two values`,
+		`Scopes are compared binding by binding, lists element by element, and thunks field by field. Returns a list of scopes, each with the :path to the difference as a list of keywords and indexes, the kind of :change (:added, :removed, or :changed), and the :before and/or :after value. Returns an empty list if the values are equal.`,
+		`When given two thunk paths, the files within them are compared instead. Each file is compared by its size, mode, and SHA256 digest, and each scope's :path is the file's path and its :before and :after are summaries of the file.`,
+		`Useful for verifying reproducibility and figuring out why a thunk missed cache.`,
+		`=> (diff {:a 1 :b [1 2]} {:b [1 3] :c 4})`,
+		`=> (def a (from (linux/alpine) ($ sh -c "date > now")))`,
+		`=> (def b (with-label a :at (now 0)))`,
+		`=> (diff a b)`,
+		`=> (diff a/ b/)`)
+
+	Ground.MustSet("verify",
+		Func("verify", "[thunk]", VerifyThunk),
+		`runs a thunk twice, bypassing the cache, and returns the files in its output directory which differ between the runs`,
+		`Returns a list of changes in the same form as (diff), each with a :cause describing the likely source of non-determinism, such as an embedded timestamp or random value.`,
+		`=> (verify (from (linux/alpine) ($ sh -c "date > now")))`)
+
+	Ground.MustSet("reproducible?",
+		Func("reproducible?", "[thunk]", func(ctx context.Context, thunk Thunk) (bool, error) {
+			changes, err := thunk.Verify(ctx)
+			if err != nil {
+				return false, err
+			}
+
+			return len(changes) == 0, nil
+		}),
+		`returns true if running the thunk twice, bypassing the cache, produces identical output files`,
+		`Use (verify) to see which files differ.`,
+		`=> (reproducible? (from (linux/alpine) ($ sh -c "echo hello > greeting")))`)
+
+	Ground.MustSet("cache-dir",
 		Func("cache-dir", "[id]", NewCacheDir),
 		`returns a cache directory corresponding to the string identifier`,
 		`Cache directories may be mounted to thunks. Their content persists across thunk runs.`)
 
-	Ground.Set("binds?",
+	Ground.MustSet("binds?",
 		Func("binds?", "[scope sym]", (*Scope).Binds),
 		`returns true if the scope has a value bound to the given symbol`,
 		`=> (binds? {:x 1} :x)`,
@@ -848,14 +1919,34 @@ var primPreds = []primPred{
 	}},
 
 	{"number?", func(val Value) bool {
-		var x Int
+		var x Number
 		return val.Decode(&x) == nil
 	}, []string{
 		`returns true if the value is a number`,
 		`=> (number? 123)`,
+		`=> (number? 1.5)`,
 		`=> (number? "123")`,
 	}},
 
+	{"integer?", func(val Value) bool {
+		var i Int
+		var bi BigInt
+		return val.Decode(&i) == nil || val.Decode(&bi) == nil
+	}, []string{
+		`returns true if the value is an integer`,
+		`=> (integer? 123)`,
+		`=> (integer? 1.5)`,
+	}},
+
+	{"float?", func(val Value) bool {
+		var x Float
+		return val.Decode(&x) == nil
+	}, []string{
+		`returns true if the value is a floating-point number`,
+		`=> (float? 1.5)`,
+		`=> (float? 123)`,
+	}},
+
 	{"string?", func(val Value) bool {
 		var x String
 		return val.Decode(&x) == nil
@@ -905,6 +1996,13 @@ var primPreds = []primPred{
 		`=> (source? *stdout*)`,
 	}},
 
+	{"tty?", IsTerminal, []string{
+		`returns true if the value is a source or sink attached to a terminal`,
+		`Checking does not block or consume any values, so a script can use it to tell whether anything was piped to *stdin*.`,
+		`=> (tty? *stdin*)`,
+		`=> (tty? (list->source [1 2 3]))`,
+	}},
+
 	{"list?", IsList, []string{
 		`returns true if the value is a linked list`,
 		`A linked list is a pair whose second value is another list or empty.`,
@@ -1035,6 +2133,7 @@ func zapField(k Symbol, v Value) (zap.Field, error) {
 
 	var str string
 	var num int
+	var flt float64
 	var bol bool
 	var am zapcore.ArrayMarshaler
 	var om zapcore.ObjectMarshaler
@@ -1042,6 +2141,8 @@ func zapField(k Symbol, v Value) (zap.Field, error) {
 		return zap.String(name, str), nil
 	} else if v.Decode(&num) == nil {
 		return zap.Int(name, num), nil
+	} else if v.Decode(&flt) == nil {
+		return zap.Float64(name, flt), nil
 	} else if v.Decode(&bol) == nil {
 		return zap.Bool(name, bol), nil
 	} else if v.Decode(&am) == nil {
@@ -1052,3 +2153,34 @@ func zapField(k Symbol, v Value) (zap.Field, error) {
 
 	return zap.Field{}, EncodeError{v}
 }
+
+// stringify returns the string value, or the string form of any other value.
+func stringify(val Value) string {
+	var str string
+	if err := val.Decode(&str); err == nil {
+		return str
+	}
+
+	return val.String()
+}
+
+// bitwise applies a bitwise operation to the integers, using the big.Int
+// operation if any of them is a BigInt.
+func bitwise(num Number, nums []Number, intOp func(int, int) int, bigOp func(z, x, y *big.Int) *big.Int) (Number, error) {
+	for _, n := range append([]Number{num}, nums...) {
+		if isFloat(n) {
+			return nil, ErrNotInteger
+		}
+	}
+
+	res := num
+	for _, n := range nums {
+		if x, y, ok := intPair(res, n); ok {
+			res = Int(intOp(x, y))
+		} else {
+			res = NewBigInt(bigOp(new(big.Int), bigInt(res), bigInt(n)))
+		}
+	}
+
+	return res, nil
+}
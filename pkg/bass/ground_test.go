@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"math/big"
 	"regexp"
 	"strings"
 	"testing"
@@ -243,12 +245,37 @@ func TestGroundPrimitivePredicates(t *testing.T) {
 			Name: "number?",
 			Trues: []bass.Value{
 				bass.Int(0),
+				bass.Float(1.5),
+				bigInt,
 			},
 			Falses: []bass.Value{
 				bass.Bool(true),
 				bass.String("1"),
 			},
 		},
+		{
+			Name: "integer?",
+			Trues: []bass.Value{
+				bass.Int(0),
+				bigInt,
+			},
+			Falses: []bass.Value{
+				bass.Float(1),
+				bass.String("1"),
+			},
+		},
+		{
+			Name: "float?",
+			Trues: []bass.Value{
+				bass.Float(0),
+				bass.Float(1.5),
+			},
+			Falses: []bass.Value{
+				bass.Int(1),
+				bigInt,
+				bass.String("1.5"),
+			},
+		},
 		{
 			Name: "string?",
 			Trues: []bass.Value{
@@ -352,6 +379,15 @@ func TestGroundPrimitivePredicates(t *testing.T) {
 				bass.Stdout,
 			},
 		},
+		{
+			Name: "tty?",
+			Falses: []bass.Value{
+				bass.NewSource(bass.NewInMemorySource()),
+				bass.NewSource(bass.NewJSONSource("test", bytes.NewBufferString("{}"))),
+				bass.NewSink(bass.NewInMemorySink()),
+				bass.String("stdin"),
+			},
+		},
 		{
 			Name: "combiner?",
 			Trues: []bass.Value{
@@ -486,11 +522,217 @@ func TestGroundNumeric(t *testing.T) {
 			Bass:   "(* 1 2 3 4)",
 			Result: bass.Int(24),
 		},
+		{
+			Name:   "divide",
+			Bass:   "[(/ 84 2) (/ -84 2) (/ 100 2 5) (/ 1 -1)]",
+			Result: bass.NewList(bass.Int(42), bass.Int(-42), bass.Int(10), bass.Int(-1)),
+		},
+		{
+			Name:   "divide inexact",
+			Bass:   "[(/ 7 2) (/ -7 2) (/ 4) (/ 1 3)]",
+			Result: bass.NewList(bass.Float(3.5), bass.Float(-3.5), bass.Float(0.25), bass.Float(1.0/3)),
+		},
+		{
+			Name:   "divide floats",
+			Bass:   "[(/ 7.0 2) (/ 1 0.5) (/ 2.5)]",
+			Result: bass.NewList(bass.Float(3.5), bass.Float(2), bass.Float(0.4)),
+		},
+		{
+			Name:   "divide big",
+			Bass:   "[(/ 18446744073709551616 2) (/ -9223372036854775808 -1)]",
+			Result: bass.NewList(bigInt, bigInt),
+		},
+		{
+			Name: "divide by zero",
+			Bass: "(/ 1 0)",
+			Err:  bass.ErrDivideByZero,
+		},
+		{
+			Name: "divide by float zero",
+			Bass: "(/ 1.5 0.0)",
+			Err:  bass.ErrDivideByZero,
+		},
+		{
+			Name: "divide reciprocal of zero",
+			Bass: "(/ 0)",
+			Err:  bass.ErrDivideByZero,
+		},
 		{
 			Name:   "quot",
 			Bass:   "(quot 84 2)",
 			Result: bass.Int(42),
 		},
+		{
+			Name:   "quot truncates",
+			Bass:   "(quot -7 2)",
+			Result: bass.Int(-3),
+		},
+		{
+			Name:   "quot most negative",
+			Bass:   "(quot -9223372036854775808 -1)",
+			Result: bigInt,
+		},
+		{
+			Name: "quot by zero",
+			Bass: "(quot 1 0)",
+			Err:  bass.ErrDivideByZero,
+		},
+		{
+			Name:   "rem",
+			Bass:   "[(rem 7 2) (rem -7 2) (rem 7 -2)]",
+			Result: bass.NewList(bass.Int(1), bass.Int(-1), bass.Int(1)),
+		},
+		{
+			Name: "rem by zero",
+			Bass: "(rem 1 0)",
+			Err:  bass.ErrDivideByZero,
+		},
+		{
+			Name:   "mod",
+			Bass:   "[(mod 7 2) (mod -7 2) (mod 7 -2) (mod -6 3)]",
+			Result: bass.NewList(bass.Int(1), bass.Int(1), bass.Int(-1), bass.Int(0)),
+		},
+		{
+			Name: "mod by zero",
+			Bass: "(mod 1 0)",
+			Err:  bass.ErrDivideByZero,
+		},
+		{
+			Name:   "quot big",
+			Bass:   "(quot (* 99999999999 99999999999) 7)",
+			Result: bass.NewBigInt(new(big.Int).Quo(new(big.Int).Mul(big.NewInt(99999999999), big.NewInt(99999999999)), big.NewInt(7))),
+		},
+		{
+			Name:   "rem big",
+			Bass:   "[(rem (* 99999999999 99999999999) 7) (rem (- 0 (* 99999999999 99999999999)) 7)]",
+			Result: bass.NewList(bass.Int(2), bass.Int(-2)),
+		},
+		{
+			Name:   "mod big",
+			Bass:   "[(mod (* 99999999999 99999999999) -7) (mod (- 0 (* 99999999999 99999999999)) 7)]",
+			Result: bass.NewList(bass.Int(-5), bass.Int(5)),
+		},
+		{
+			Name:   "quot rem mod float",
+			Bass:   "[(quot 7.5 2) (rem -7.5 2) (mod -7.5 2)]",
+			Result: bass.NewList(bass.Float(3), bass.Float(-1.5), bass.Float(0.5)),
+		},
+		{
+			Name: "rem by float zero",
+			Bass: "(rem 1 0.0)",
+			Err:  bass.ErrDivideByZero,
+		},
+		{
+			Name:   "abs",
+			Bass:   "[(abs -42) (abs 42) (abs 0)]",
+			Result: bass.NewList(bass.Int(42), bass.Int(42), bass.Int(0)),
+		},
+		{
+			Name:   "floor",
+			Bass:   "[(floor 3.7) (floor -3.2) (floor 3.0) (floor 42) (floor 1e20)]",
+			Result: bass.NewList(bass.Int(3), bass.Int(-4), bass.Int(3), bass.Int(42), bass.NewBigInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(20), nil))),
+		},
+		{
+			Name:   "ceil",
+			Bass:   "[(ceil 3.2) (ceil -3.7) (ceil 3.0) (ceil 42)]",
+			Result: bass.NewList(bass.Int(4), bass.Int(-3), bass.Int(3), bass.Int(42)),
+		},
+		{
+			Name: "floor infinity",
+			Bass: "(floor (* 1e308 10.0))",
+			Err:  bass.ErrNotFinite,
+		},
+		{
+			Name:   "pow",
+			Bass:   "[(pow 2 10) (pow -3 3) (pow 5 0)]",
+			Result: bass.NewList(bass.Int(1024), bass.Int(-27), bass.Int(1)),
+		},
+		{
+			Name:   "pow big exponent",
+			Bass:   "[(pow 1 9223372036854775808) (pow -1 9223372036854775809) (pow 0 9223372036854775808)]",
+			Result: bass.NewList(bass.Int(1), bass.Int(-1), bass.Int(0)),
+		},
+		{
+			Name: "pow exponent too large",
+			Bass: "(pow 2 9223372036854775808)",
+			Err:  bass.ErrExponentTooLarge,
+		},
+		{
+			Name:   "pow float exponent",
+			Bass:   "(pow 4 0.5)",
+			Result: bass.Float(2),
+		},
+		{
+			Name: "pow negative exponent",
+			Bass: "(pow 2 -1)",
+			Err:  bass.ErrNegativeExponent,
+		},
+		{
+			Name:   "bit-and",
+			Bass:   "[(bit-and 12 10) (bit-and 7 6 4) (bit-and 5)]",
+			Result: bass.NewList(bass.Int(8), bass.Int(4), bass.Int(5)),
+		},
+		{
+			Name:   "bit-or",
+			Bass:   "[(bit-or 12 10) (bit-or 1 2 4)]",
+			Result: bass.NewList(bass.Int(14), bass.Int(7)),
+		},
+		{
+			Name:   "bit-xor",
+			Bass:   "[(bit-xor 12 10) (bit-xor 1 3 7)]",
+			Result: bass.NewList(bass.Int(6), bass.Int(5)),
+		},
+		{
+			Name:   "bit-not",
+			Bass:   "[(bit-not 0) (bit-not -1) (bit-not 5)]",
+			Result: bass.NewList(bass.Int(-1), bass.Int(0), bass.Int(-6)),
+		},
+		{
+			Name:   "shift-left",
+			Bass:   "[(shift-left 1 4) (shift-left -3 2)]",
+			Result: bass.NewList(bass.Int(16), bass.Int(-12)),
+		},
+		{
+			Name:   "shift-right",
+			Bass:   "[(shift-right 256 4) (shift-right -16 2)]",
+			Result: bass.NewList(bass.Int(16), bass.Int(-4)),
+		},
+		{
+			Name: "shift-left overflow",
+			Bass: "[(shift-left 1 62) (shift-left 1 63) (shift-left 1 64) (shift-left -1 63) (shift-left 3 100)]",
+			Result: bass.NewList(
+				bass.Int(1<<62),
+				bass.NewBigInt(new(big.Int).Lsh(big.NewInt(1), 63)),
+				bass.NewBigInt(new(big.Int).Lsh(big.NewInt(1), 64)),
+				bass.Int(math.MinInt64),
+				bass.NewBigInt(new(big.Int).Lsh(big.NewInt(3), 100)),
+			),
+		},
+		{
+			Name:   "shift-right big",
+			Bass:   "[(shift-right (shift-left 1 64) 1) (shift-right (shift-left 1 64) 64) (shift-right (shift-left -1 64) 70) (shift-right 1 64) (shift-right -1 64)]",
+			Result: bass.NewList(bass.NewBigInt(new(big.Int).Lsh(big.NewInt(1), 63)), bass.Int(1), bass.Int(-1), bass.Int(0), bass.Int(-1)),
+		},
+		{
+			Name: "shift-right negative",
+			Bass: "(shift-right 1 -1)",
+			Err:  bass.ErrNegativeShift,
+		},
+		{
+			Name:   "bitwise big",
+			Bass:   "[(bit-and (shift-left 3 64) (shift-left 1 65)) (bit-or (shift-left 1 64) 1) (bit-xor (shift-left 1 64) (shift-left 1 64)) (bit-not (shift-left 1 64))]",
+			Result: bass.NewList(bass.NewBigInt(new(big.Int).Lsh(big.NewInt(1), 65)), bass.NewBigInt(new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 64), big.NewInt(1))), bass.Int(0), bass.NewBigInt(new(big.Int).Not(new(big.Int).Lsh(big.NewInt(1), 64)))),
+		},
+		{
+			Name: "bitwise float",
+			Bass: "(bit-and 1.5 1)",
+			Err:  bass.ErrNotInteger,
+		},
+		{
+			Name: "shift negative",
+			Bass: "(shift-left 1 -1)",
+			Err:  bass.ErrNegativeShift,
+		},
 		{
 			Name:   "max",
 			Bass:   "(max 1 3 7 5 4)",
@@ -506,11 +748,94 @@ func TestGroundNumeric(t *testing.T) {
 			Bass:   "(min 5 3 7 2 4)",
 			Result: bass.Int(2),
 		},
+		{
+			Name:   "+ float",
+			Bass:   "(+ 1 2.5 0.25)",
+			Result: bass.Float(3.75),
+		},
+		{
+			Name:   "- float",
+			Bass:   "(- 1 2.5)",
+			Result: bass.Float(-1.5),
+		},
+		{
+			Name:   "- unary float",
+			Bass:   "(- 1.5)",
+			Result: bass.Float(-1.5),
+		},
+		{
+			Name:   "* float",
+			Bass:   "(* 2 1.25)",
+			Result: bass.Float(2.5),
+		},
+		{
+			Name:   "max float",
+			Bass:   "(max 1 2.5 2)",
+			Result: bass.Float(2.5),
+		},
+		{
+			Name:   "min float",
+			Bass:   "(min 1 0.5 2)",
+			Result: bass.Float(0.5),
+		},
+		{
+			Name:   "abs float",
+			Bass:   "(abs -1.5)",
+			Result: bass.Float(1.5),
+		},
+		{
+			Name:   "pow float",
+			Bass:   "(pow 1.5 2)",
+			Result: bass.Float(2.25),
+		},
+		{
+			Name:   "pow float negative",
+			Bass:   "(pow 2.0 -1)",
+			Result: bass.Float(0.5),
+		},
+		{
+			Name:   "+ overflow",
+			Bass:   "(+ 9223372036854775807 1)",
+			Result: bigInt,
+		},
+		{
+			Name:   "- overflow",
+			Bass:   "(- -9223372036854775808 1)",
+			Result: bass.NewBigInt(new(big.Int).Sub(big.NewInt(math.MinInt64), big.NewInt(1))),
+		},
+		{
+			Name:   "- unary overflow",
+			Bass:   "(- -9223372036854775808)",
+			Result: bigInt,
+		},
+		{
+			Name:   "* overflow",
+			Bass:   "(* 4294967296 4294967296)",
+			Result: bass.NewBigInt(new(big.Int).Lsh(big.NewInt(1), 64)),
+		},
+		{
+			Name:   "big int back to int",
+			Bass:   "(- (+ 9223372036854775807 1) 1)",
+			Result: bass.Int(math.MaxInt64),
+		},
+		{
+			Name:   "pow overflow",
+			Bass:   "(pow 2 100)",
+			Result: bass.NewBigInt(new(big.Int).Lsh(big.NewInt(1), 100)),
+		},
+		{
+			Name:   "big int and float",
+			Bass:   "(* 9223372036854775808 0.5)",
+			Result: bass.Float(4611686018427387904),
+		},
 	} {
 		test.Run(t)
 	}
 }
 
+// bigInt is one more than the largest Int.
+var bigInt = bass.NewBigInt(new(big.Int).Add(big.NewInt(math.MaxInt64), big.NewInt(1))).(bass.BigInt)
+
 func TestGroundArrow(t *testing.T) {
 	for _, test := range []BasicExample{
 		{
@@ -715,6 +1040,36 @@ func TestGroundComparison(t *testing.T) {
 			Bass:   "(<= 1 2 2)",
 			Result: bass.Bool(true),
 		},
+		{
+			Name:   "< floats",
+			Bass:   "(< 1 1.5 2)",
+			Result: bass.Bool(true),
+		},
+		{
+			Name:   ">= floats",
+			Bass:   "(>= 2.5 2.5 2)",
+			Result: bass.Bool(true),
+		},
+		{
+			Name:   "> big ints",
+			Bass:   "(> 9223372036854775808 9223372036854775807 -9223372036854775809)",
+			Result: bass.Bool(true),
+		},
+		{
+			Name:   "= same floats",
+			Bass:   "(= 1.5 1.5)",
+			Result: bass.Bool(true),
+		},
+		{
+			Name:   "= int and float",
+			Bass:   "(= 1 1.0)",
+			Result: bass.Bool(false),
+		},
+		{
+			Name:   "= same big ints",
+			Bass:   "(= 9223372036854775808 (+ 9223372036854775807 1))",
+			Result: bass.Bool(true),
+		},
 	} {
 		test.Run(t)
 	}
@@ -842,6 +1197,21 @@ func TestGroundScope(t *testing.T) {
 				"sentinel": sentinel,
 			},
 		},
+		{
+			Name:        "def frozen",
+			Bass:        "(def frozen (freeze! {:a 1})) (eval [def :b 2] frozen)",
+			ErrContains: "cannot bind b: scope is frozen",
+		},
+		{
+			Name:        "bind frozen",
+			Bass:        "(bind (freeze! {:a 1}) :a 2)",
+			ErrContains: "cannot bind a: scope is frozen",
+		},
+		{
+			Name:   "bind frozen shadowing",
+			Bass:   "(bind (make-scope (freeze! {:a 1})) :a 2)",
+			Result: bass.Bool(true),
+		},
 		{
 			Name:   "def evaluation",
 			Bass:   "(def foo sentinel)",
@@ -964,6 +1334,36 @@ func TestGroundScope(t *testing.T) {
 		})
 	}
 
+	t.Run("ground is frozen", func(t *testing.T) {
+		is := is.New(t)
+
+		is.True(bass.Ground.IsFrozen())
+		is.Equal(bass.Ground.Set("if", bass.Null{}), bass.FrozenError{
+			Scope:   bass.Ground,
+			Binding: "if",
+		})
+
+		_, err := bass.EvalFSFile(context.Background(), bass.NewStandardScope(), bass.NewInMemoryFile("test", "(def if 1) if"))
+		is.NoErr(err)
+	})
+
+	t.Run("registering a builtin after ground is frozen panics", func(t *testing.T) {
+		is := is.New(t)
+
+		defer func() {
+			is.Equal(recover(), bass.FrozenError{
+				Scope:   bass.Ground,
+				Binding: "late",
+			})
+
+			_, found := bass.Ground.Get("late")
+			is.True(!found)
+		}()
+
+		bass.Ground.MustSet("late", bass.Func("late", "[]", func() {}))
+		t.Fatal("did not panic")
+	})
+
 	t.Run("scope creation", func(t *testing.T) {
 		is := is.New(t)
 
@@ -1089,7 +1489,7 @@ commentary split along multiple lines
 and another paragraph
 
 --------------------------------------------------
-abc number?
+abc number? integer?
 
 docs for abc
 
@@ -1112,7 +1512,7 @@ args: ()
 documented inside
 
 --------------------------------------------------
-commented number?
+commented number? integer?
 
 comments for commented
 
@@ -1282,6 +1682,127 @@ func TestGroundBoolean(t *testing.T) {
 	}
 }
 
+func TestGroundDynamic(t *testing.T) {
+	type example struct {
+		Name string
+		Bass string
+
+		Result      bass.Value
+		ErrContains string
+	}
+
+	for _, test := range []example{
+		{
+			Name:   "default",
+			Bass:   "(defdynamic *v* 1) *v*",
+			Result: bass.Int(1),
+		},
+		{
+			Name:   "with-dynamic",
+			Bass:   "(defdynamic *v* 1) (with-dynamic *v* 2 *v*)",
+			Result: bass.Int(2),
+		},
+		{
+			Name:   "reverts after body",
+			Bass:   "(defdynamic *v* 1) (with-dynamic *v* 2 *v*) *v*",
+			Result: bass.Int(1),
+		},
+		{
+			Name:   "visible to callees",
+			Bass:   "(defdynamic *v* 1) (defn get-v [] *v*) (with-dynamic *v* 2 (get-v))",
+			Result: bass.Int(2),
+		},
+		{
+			Name:   "nested",
+			Bass:   "(defdynamic *v* 1) (with-dynamic *v* 2 (with-dynamic *v* (+ *v* 1) *v*))",
+			Result: bass.Int(3),
+		},
+		{
+			Name:   "not visible to other variables",
+			Bass:   "(defdynamic *a* 1) (defdynamic *b* 2) (with-dynamic *a* 3 *b*)",
+			Result: bass.Int(2),
+		},
+		{
+			Name:        "not dynamic",
+			Bass:        "(def v 1) (with-dynamic v 2 v)",
+			ErrContains: "cannot decode",
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			is := is.New(t)
+
+			res, err := bass.EvalFSFile(context.Background(), bass.NewStandardScope(), bass.NewInMemoryFile("test", test.Bass))
+			if test.ErrContains != "" {
+				is.True(err != nil)
+				is.True(strings.Contains(err.Error(), test.ErrContains))
+			} else {
+				is.NoErr(err)
+				Equal(t, res, test.Result)
+			}
+		})
+	}
+}
+
+func TestGroundLazy(t *testing.T) {
+	type example struct {
+		Name string
+		Bass string
+
+		Result bass.Value
+	}
+
+	for _, test := range []example{
+		{
+			Name:   "force",
+			Bass:   "(force (delay (+ 1 2)))",
+			Result: bass.Int(3),
+		},
+		{
+			Name:   "force non-promise",
+			Bass:   "(force 3)",
+			Result: bass.Int(3),
+		},
+		{
+			Name:   "delay is not evaluated until forced",
+			Bass:   "(def x 1) (def p (delay x)) (def x 2) (force p)",
+			Result: bass.Int(2),
+		},
+		{
+			Name:   "force memoizes",
+			Bass:   "(def n 0) (def p (delay (def n (+ n 1)))) (force p) (force p) n",
+			Result: bass.Int(1),
+		},
+		{
+			Name:   "take from infinite sequence",
+			Bass:   "(defn nats [n] (lazy-cons n (nats (+ n 1)))) (take 3 (nats 5))",
+			Result: bass.NewList(bass.Int(5), bass.Int(6), bass.Int(7)),
+		},
+		{
+			Name:   "take past end",
+			Bass:   "(defn upto [n m] (if (> n m) [] (lazy-cons n (upto (+ n 1) m)))) (take 5 (upto 1 2))",
+			Result: bass.NewList(bass.Int(1), bass.Int(2)),
+		},
+		{
+			Name:   "first",
+			Bass:   "(first (lazy-cons 1 (error \"never\")))",
+			Result: bass.Int(1),
+		},
+		{
+			Name:   "take from list",
+			Bass:   "(take 2 [1 2 3])",
+			Result: bass.NewList(bass.Int(1), bass.Int(2)),
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			is := is.New(t)
+
+			res, err := bass.EvalFSFile(context.Background(), bass.NewStandardScope(), bass.NewInMemoryFile("test", test.Bass))
+			is.NoErr(err)
+			Equal(t, res, test.Result)
+		})
+	}
+}
+
 func TestGroundInvariants(t *testing.T) {
 	for _, expr := range []string{
 		`(= (not x) (if x false true))`,
@@ -1470,7 +1991,109 @@ func TestGroundStdlib(t *testing.T) {
 				bass.Bindings{"a": bass.Int(1)}.Scope(),
 			),
 		},
-	} {
+		{
+			Name:   "apply operative",
+			Bass:   "(apply (op [x] _ x) (quote ((foo bar))))",
+			Result: bass.NewList(bass.Symbol("foo"), bass.Symbol("bar")),
+		},
+		{
+			Name:   "apply scope",
+			Bass:   "(apply (op [x] e (eval x e)) [:a] {:a 42})",
+			Result: bass.Int(42),
+		},
+		{
+			Name:   "partial",
+			Bass:   "((partial - 10 2) 3)",
+			Result: bass.Int(5),
+		},
+		{
+			Name:   "partial evaluates once",
+			Bass:   "((partial list (quote a)) (quote b))",
+			Result: bass.NewList(bass.Symbol("a"), bass.Symbol("b")),
+		},
+		{
+			Name:   "partial operative",
+			Bass:   "((partial (op xs _ xs) (quote a)) b c)",
+			Result: bass.NewList(bass.Symbol("a"), bass.Symbol("b"), bass.Symbol("c")),
+		},
+		{
+			Name:   "comp",
+			Bass:   "((comp str (partial * 2) +) 1 2 3)",
+			Result: bass.String("12"),
+		},
+		{
+			Name:   "comp single",
+			Bass:   "(map (comp str) [1 2 3])",
+			Result: bass.NewList(bass.String("1"), bass.String("2"), bass.String("3")),
+		},
+		{
+			Name:        "comp operative",
+			Bass:        "(comp quote)",
+			ErrContains: "decode arg[0]",
+		},
+		{
+			Name:   "map",
+			Bass:   "(map (fn [x] (* x 7)) [5 6 7])",
+			Result: bass.NewList(bass.Int(35), bass.Int(42), bass.Int(49)),
+		},
+		{
+			Name:   "map empty",
+			Bass:   "(map (fn [x] (error \"unreachable\")) [])",
+			Result: bass.Empty{},
+		},
+		{
+			Name:        "map error",
+			Bass:        "(map (fn [x] (error \"bam\")) [1])",
+			ErrContains: "bam",
+		},
+		{
+			Name:   "filter",
+			Bass:   `(filter (fn [x] (if (symbol? x) x null)) [:abc 123 :def "456" false])`,
+			Result: bass.NewList(bass.Symbol("abc"), bass.Symbol("def")),
+		},
+		{
+			Name:   "flat-map",
+			Bass:   "(flat-map (fn [x] [x x]) [1 2])",
+			Result: bass.NewList(bass.Int(1), bass.Int(1), bass.Int(2), bass.Int(2)),
+		},
+		{
+			Name:        "flat-map non-list",
+			Bass:        "(flat-map (fn [x] x) [1])",
+			ErrContains: "cannot decode",
+		},
+		{
+			Name: "zip",
+			Bass: "(zip [1 2 3] [:a :b])",
+			Result: bass.NewList(
+				bass.NewList(bass.Int(1), bass.Symbol("a")),
+				bass.NewList(bass.Int(2), bass.Symbol("b")),
+			),
+		},
+		{
+			Name:   "zip nothing",
+			Bass:   "(zip)",
+			Result: bass.Empty{},
+		},
+		{
+			Name:   "concat",
+			Bass:   "(concat [1] [] [2 3])",
+			Result: bass.NewList(bass.Int(1), bass.Int(2), bass.Int(3)),
+		},
+		{
+			Name: "matrix",
+			Bass: `(keys (matrix {:go ["1.21" "1.22"] :os [:linux]} (fn [_] (.true))))`,
+			Result: bass.NewList(
+				bass.Symbol("go=1.21,os=linux"),
+				bass.Symbol("go=1.22,os=linux"),
+			),
+		},
+		{
+			Name: "matrix labels",
+			Bass: `(def m (matrix {:go ["1.21"] :os ["linux"]} (fn [{:os os}] (with-env ($ go test) {:GOOS os}))))
+				(= (vals m) [(-> ($ go test) (with-env {:GOOS "linux"}) (with-label :go "1.21") (with-label :os "linux"))])`,
+			Result: bass.Bool(true),
+		},
+	} {
 		t.Run(test.Name, func(t *testing.T) {
 			is := is.New(t)
 
@@ -1562,11 +2185,54 @@ func TestGroundPipes(t *testing.T) {
 			Stdin:  []bass.Value{},
 			Result: bass.Symbol("default"),
 		},
+		{
+			Name:   "with-stdin source",
+			Bass:   "(= (with-stdin ($ cat) source) (with-stdin ($ cat) [1 2]))",
+			Stdin:  []bass.Value{bass.Int(1), bass.Int(2)},
+			Result: bass.Bool(true),
+		},
+		{
+			Name:   "with-stdin drains source",
+			Bass:   "(do (with-stdin ($ cat) source) (next source :end))",
+			Stdin:  []bass.Value{bass.Int(1), bass.Int(2)},
+			Result: bass.Symbol("end"),
+		},
+		{
+			Name:   "tty? source",
+			Bass:   "(tty? source)",
+			Result: bass.Bool(false),
+		},
 		{
 			Name:   "take",
 			Bass:   "(take 2 (list->source [1 2 3]))",
 			Result: bass.NewList(bass.Int(1), bass.Int(2)),
 		},
+		{
+			Name:   "collect",
+			Bass:   "(collect (stream [1 2 3]))",
+			Result: bass.NewList(bass.Int(1), bass.Int(2), bass.Int(3)),
+		},
+		{
+			Name:   "collect empty",
+			Bass:   "(collect (stream []))",
+			Result: bass.Empty{},
+		},
+		{
+			Name:   "collect stdin",
+			Bass:   "(collect source)",
+			Stdin:  []bass.Value{bass.Int(1), bass.String("two")},
+			Result: bass.NewList(bass.Int(1), bass.String("two")),
+		},
+		{
+			Name:   "collect within limit",
+			Bass:   "(collect (stream [1 2 3]) 3)",
+			Result: bass.NewList(bass.Int(1), bass.Int(2), bass.Int(3)),
+		},
+		{
+			Name: "collect over limit",
+			Bass: "(collect (stream [1 2 3]) 2)",
+			Err:  bass.CollectLimitError{Limit: 2},
+		},
 		{
 			Name:   "across",
 			Bass:   "(next (across (list->source [0 2 4]) (list->source [1 3 5])))",
@@ -1724,11 +2390,116 @@ func TestGroundStrings(t *testing.T) {
 				Have: 4,
 			},
 		},
+		{
+			Name:   "substring unicode",
+			Bass:   `(substring "héllo" 1 3)`,
+			Result: bass.String("él"),
+		},
+		{
+			Name:        "substring out of bounds",
+			Bass:        `(substring "abc" 2 5)`,
+			ErrContains: "out of bounds",
+		},
+		{
+			Name:   "str-index",
+			Bass:   `[(str-index "héllo world" "world") (str-index "abc" "a") (str-index "abc" "z")]`,
+			Result: bass.NewList(bass.Int(6), bass.Int(0), bass.Null{}),
+		},
+		{
+			Name:   "split",
+			Bass:   `(split "/" "a/b//c")`,
+			Result: bass.NewList(bass.String("a"), bass.String("b"), bass.String(""), bass.String("c")),
+		},
+		{
+			Name:   "join",
+			Bass:   `[(join ", " ["a" "b" "c"]) (join "-" []) (join "." [1 :two 3])]`,
+			Result: bass.NewList(bass.String("a, b, c"), bass.String(""), bass.String("1.two.3")),
+		},
 		{
 			Name:   "trim",
 			Bass:   "(trim \" \n\tfoo\n\t \")",
 			Result: bass.String("foo"),
 		},
+		{
+			Name:   "trim cutset",
+			Bass:   `(trim "--foo-bar--" "-")`,
+			Result: bass.String("foo-bar"),
+		},
+		{
+			Name:   "replace",
+			Bass:   `[(replace "a.b.c" "." "-") (replace "a.b.c" "." "" 1)]`,
+			Result: bass.NewList(bass.String("a-b-c"), bass.String("ab.c")),
+		},
+		{
+			Name:   "upper",
+			Bass:   `(upper "héllo")`,
+			Result: bass.String("HÉLLO"),
+		},
+		{
+			Name:   "lower",
+			Bass:   `(lower "HÉLLO")`,
+			Result: bass.String("héllo"),
+		},
+		{
+			Name:   "slugify",
+			Bass:   `(join "-" (split " " (lower (replace "Fix Bug/123" "/" " "))))`,
+			Result: bass.String("fix-bug-123"),
+		},
+		{
+			Name:   "parse-int",
+			Bass:   `[(parse-int "42") (parse-int "-17") (parse-int "755" 8) (parse-int "ff" 16) (parse-int "0b101" 0)]`,
+			Result: bass.NewList(bass.Int(42), bass.Int(-17), bass.Int(493), bass.Int(255), bass.Int(5)),
+		},
+		{
+			Name:        "parse-int invalid",
+			Bass:        `(parse-int "nope")`,
+			ErrContains: `invalid syntax`,
+		},
+		{
+			Name:   "format",
+			Bass:   `(format "%s-%d %v %v %v" "tag" 42 true "quoted" {:a 1})`,
+			Result: bass.String(`tag-42 true "quoted" {:a 1}`),
+		},
+		{
+			Name:   "format strings",
+			Bass:   `(format "%s %v %v %q %v" "a \"b\"" "a \"b\"" {:s "nested"} "q" [1 2.5 "x"])`,
+			Result: bass.String(`a "b" "a \"b\"" {:s "nested"} "q" (1 2.5 "x")`),
+		},
+		{
+			Name:   "format width v",
+			Bass:   `(format "[%6v|%-6v]" "ab" 42)`,
+			Result: bass.String(`[  "ab"|42    ]`),
+		},
+		{
+			Name:   "format symbols",
+			Bass:   `(format "%v %v" :kw ./file)`,
+			Result: bass.String(`kw ./file`),
+		},
+		{
+			Name:   "format json",
+			Bass:   `(format "%j %j %j" {:a 1 :b [true null]} "str" ./file)`,
+			Result: bass.String(`{"a":1,"b":[true,null]} "str" {"file":"file"}`),
+		},
+		{
+			Name:   "format width",
+			Bass:   `(format "[%-5s|%5s|%q]" :ab ./x :kw)`,
+			Result: bass.String(`[ab   |  ./x|"kw"]`),
+		},
+		{
+			Name:   "format bad verb",
+			Bass:   `(format "%d" :kw)`,
+			Result: bass.String(`%!d(kw)`),
+		},
+		{
+			Name:   "pad-left",
+			Bass:   `[(pad-left "42" 5 "0") (pad-left "abc" 5) (pad-left "toolong" 3) (pad-left "x" 4 "ab")]`,
+			Result: bass.NewList(bass.String("00042"), bass.String("  abc"), bass.String("toolong"), bass.String("abax")),
+		},
+		{
+			Name:   "pad-right",
+			Bass:   `[(pad-right "42" 5 "0") (pad-right "abc" 5) (pad-right "ü" 3 ".")]`,
+			Result: bass.NewList(bass.String("42000"), bass.String("abc  "), bass.String("ü..")),
+		},
 		{
 			Name:   "json",
 			Bass:   `(json {:a 1 :b true :multi-word "hello world!\n"})`,
@@ -1844,6 +2615,125 @@ func TestGroundPaths(t *testing.T) {
 			Bass:   `(path-stem .foo)`,
 			Result: bass.String("foo"),
 		},
+		{
+			Name:   "path-base file",
+			Bass:   `(path-base ./foo/bar.txt)`,
+			Result: bass.FilePath{"bar.txt"},
+		},
+		{
+			Name:   "path-base dir",
+			Bass:   `(path-base ./foo/bar/)`,
+			Result: bass.DirPath{"bar"},
+		},
+		{
+			Name:   "path-base thunk",
+			Bass:   `(path-base (subpath (.foo) ./bar/baz))`,
+			Result: bass.FilePath{"baz"},
+		},
+		{
+			Name:        "path-base command",
+			Bass:        `(path-base .foo)`,
+			ErrContains: "cannot (path-base) .foo",
+		},
+		{
+			Name:   "path-dir file",
+			Bass:   `(path-dir ./foo/bar.txt)`,
+			Result: bass.DirPath{"foo"},
+		},
+		{
+			Name:   "path-dir dir",
+			Bass:   `(path-dir ./foo/bar/)`,
+			Result: bass.DirPath{"foo"},
+		},
+		{
+			Name: "path-dir thunk",
+			Bass: `(path-dir (subpath (.foo) ./bar/baz))`,
+			Result: bass.ThunkPath{
+				Thunk: bass.Thunk{
+					Cmd: bass.ThunkCmd{
+						Cmd: &bass.CommandPath{"foo"},
+					},
+				},
+				Path: bass.FileOrDirPath{
+					Dir: &bass.DirPath{"bar"},
+				},
+			},
+		},
+		{
+			Name:   "path-dir host",
+			Bind:   bass.Bindings{"host": bass.NewHostPath("/ctx", bass.ParseFileOrDirPath("foo/bar"))},
+			Bass:   `(path-dir host)`,
+			Result: bass.NewHostPath("/ctx", bass.ParseFileOrDirPath("foo/")),
+		},
+		{
+			Name:   "path-ext",
+			Bass:   `[(path-ext ./foo/bar.tar.gz) (path-ext ./foo/bar) (path-ext (subpath (.foo) ./baz.bass))]`,
+			Result: bass.NewList(bass.String(".gz"), bass.String(""), bass.String(".bass")),
+		},
+		{
+			Name:        "path-ext command",
+			Bass:        `(path-ext .foo)`,
+			ErrContains: "cannot (path-ext)",
+		},
+		{
+			Name:   "path-join",
+			Bass:   `(path-join ./src/ ./pkg/ ./main.go)`,
+			Result: bass.FilePath{"src/pkg/main.go"},
+		},
+		{
+			Name:   "path-join single",
+			Bass:   `(path-join ./src/)`,
+			Result: bass.DirPath{"src"},
+		},
+		{
+			Name: "path-join thunk",
+			Bass: `(path-join (.foo) ./bar/ ./baz)`,
+			Result: bass.ThunkPath{
+				Thunk: bass.Thunk{
+					Cmd: bass.ThunkCmd{
+						Cmd: &bass.CommandPath{"foo"},
+					},
+				},
+				Path: bass.FileOrDirPath{
+					File: &bass.FilePath{"bar/baz"},
+				},
+			},
+		},
+		{
+			Name:        "path-join file",
+			Bass:        `(path-join ./file ./other)`,
+			ErrContains: "cannot extend path",
+		},
+		{
+			Name:   "relative-to",
+			Bass:   `(relative-to ./src/pkg/main.go ./src/)`,
+			Result: bass.FilePath{"pkg/main.go"},
+		},
+		{
+			Name:   "relative-to parent",
+			Bass:   `(relative-to ./docs/ ./src/pkg/)`,
+			Result: bass.DirPath{"../../docs"},
+		},
+		{
+			Name:   "relative-to thunk",
+			Bass:   `(relative-to (subpath (.foo) ./out/report.html) (subpath (.foo) ./out/))`,
+			Result: bass.FilePath{"report.html"},
+		},
+		{
+			Name:        "relative-to different thunks",
+			Bass:        `(relative-to (subpath (.foo) ./out/report.html) (subpath (.bar) ./out/))`,
+			ErrContains: "cannot (relative-to)",
+		},
+		{
+			Name:        "relative-to file base",
+			Bass:        `(relative-to ./foo ./bar)`,
+			ErrContains: "cannot (relative-to)",
+		},
+		{
+			Name:        "relative-to mixed types",
+			Bass:        `(relative-to (subpath (.foo) ./out/) ./out/)`,
+			ErrContains: "cannot (relative-to)",
+		},
 	} {
 		t.Run(example.Name, example.Run)
 	}
@@ -1972,6 +2862,160 @@ func TestGroundObject(t *testing.T) {
 			Bass:   "(vals {:a 1 :b 2 :c 3})",
 			Result: bass.NewList(bass.Int(1), bass.Int(2), bass.Int(3)),
 		},
+		{
+			Name:   "get",
+			Bass:   "(get {:a 1} :a)",
+			Result: bass.Int(1),
+		},
+		{
+			Name:   "get string key",
+			Bass:   `(get {:content-type "json"} "content-type")`,
+			Result: bass.String("json"),
+		},
+		{
+			Name:   "get missing",
+			Bass:   "(get {:a 1} :b)",
+			Result: bass.Null{},
+		},
+		{
+			Name:   "get default",
+			Bass:   "(get {:a 1} :b 42)",
+			Result: bass.Int(42),
+		},
+		{
+			Name:   "get null",
+			Bass:   "(get null :a 42)",
+			Result: bass.Int(42),
+		},
+		{
+			Name:   "get index",
+			Bass:   "[(get [1 2 3] 1) (get [1 2 3] 3 :none)]",
+			Result: bass.NewList(bass.Int(2), bass.Symbol("none")),
+		},
+		{
+			Name:   "get-in",
+			Bass:   "(get-in {:a {:b [1 {:c 2}]}} [:a :b 1 :c])",
+			Result: bass.Int(2),
+		},
+		{
+			Name:   "get-in missing",
+			Bass:   "[(get-in {:a {:b null}} [:a :b :c] 42) (get-in {:a 1} [:b :c])]",
+			Result: bass.NewList(bass.Int(42), bass.Null{}),
+		},
+		{
+			Name:   "or-else",
+			Bass:   "[(or-else null 42) (or-else false 42) (or-else 1 42)]",
+			Result: bass.NewList(bass.Int(42), bass.Bool(false), bass.Int(1)),
+		},
+		{
+			Name: "deep-merge",
+			Bass: `(deep-merge {:env {:A "1" :B "2"} :tags ["a"]} {:env {:B "two"} :tags ["b"]})`,
+			Result: bass.Bindings{
+				"env": bass.Bindings{
+					"A": bass.String("1"),
+					"B": bass.String("two"),
+				}.Scope(),
+				"tags": bass.NewList(bass.String("b")),
+			}.Scope(),
+		},
+		{
+			Name: "deep-merge list-append",
+			Bass: `(deep-merge {:tags ["a"] :labels {:x [1]} :n 1} {:tags ["b"] :labels {:x [2]} :n 2} :list-append)`,
+			Result: bass.Bindings{
+				"tags": bass.NewList(bass.String("a"), bass.String("b")),
+				"labels": bass.Bindings{
+					"x": bass.NewList(bass.Int(1), bass.Int(2)),
+				}.Scope(),
+				"n": bass.Int(2),
+			}.Scope(),
+		},
+		{
+			Name: "deep-merge error-on-conflict",
+			Bass: `(deep-merge {:a 1 :b 2} {:b 2 :c 3} :error-on-conflict)`,
+			Result: bass.Bindings{
+				"a": bass.Int(1),
+				"b": bass.Int(2),
+				"c": bass.Int(3),
+			}.Scope(),
+		},
+		{
+			Name:        "deep-merge conflict",
+			Bass:        `(deep-merge {:env {:A "1"}} {:env {:A "2"}} :error-on-conflict)`,
+			ErrContains: `merge conflict at env.A: "1" != "2"`,
+		},
+		{
+			Name:        "deep-merge unknown strategy",
+			Bass:        `(deep-merge {} {} :first-wins)`,
+			ErrContains: `unknown merge strategy: first-wins`,
+		},
+		{
+			Name: "json-patch add",
+			Bass: `(json-patch {:metadata {:name "app"}} [{:op "add" :path "/metadata/labels" :value {:tier "web"}}])`,
+			Result: bass.Bindings{
+				"metadata": bass.Bindings{
+					"name":   bass.String("app"),
+					"labels": bass.Bindings{"tier": bass.String("web")}.Scope(),
+				}.Scope(),
+			}.Scope(),
+		},
+		{
+			Name: "json-patch lists",
+			Bass: `(json-patch {:args ["a" "b"]} [{:op "remove" :path "/args/0"} {:op "add" :path "/args/-" :value "c"} {:op "add" :path "/args/0" :value "z"}])`,
+			Result: bass.Bindings{
+				"args": bass.NewList(bass.String("z"), bass.String("b"), bass.String("c")),
+			}.Scope(),
+		},
+		{
+			Name: "json-patch replace, move, and copy",
+			Bass: `(json-patch {:a 1 :b {:c 2}} [{:op "replace" :path "/a" :value 10} {:op "move" :from "/b/c" :path "/d"} {:op "copy" :from "/a" :path "/b/e"}])`,
+			Result: bass.Bindings{
+				"a": bass.Int(10),
+				"b": bass.Bindings{"e": bass.Int(10)}.Scope(),
+				"d": bass.Int(2),
+			}.Scope(),
+		},
+		{
+			Name: "json-patch escaped keys",
+			Bass: `(json-patch {} [{:op "add" :path "/app.kubernetes.io~1name" :value "web"}])`,
+			Result: bass.Bindings{
+				"app.kubernetes.io/name": bass.String("web"),
+			}.Scope(),
+		},
+		{
+			Name:   "json-patch test passes",
+			Bass:   `(json-patch {:a [1 2]} [{:op "test" :path "/a/1" :value 2}])`,
+			Result: bass.Bindings{"a": bass.NewList(bass.Int(1), bass.Int(2))}.Scope(),
+		},
+		{
+			Name:        "json-patch test fails",
+			Bass:        `(json-patch {:a 1} [{:op "test" :path "/a" :value 2}])`,
+			ErrContains: `json patch op 0 (test /a): test failed: 1 != 2`,
+		},
+		{
+			Name:        "json-patch missing path",
+			Bass:        `(json-patch {:a 1} [{:op "remove" :path "/b"}])`,
+			ErrContains: `path not found: b`,
+		},
+		{
+			Name:        "json-patch index out of bounds",
+			Bass:        `(json-patch [1] [{:op "replace" :path "/1" :value 2}])`,
+			ErrContains: `index out of bounds: 1`,
+		},
+		{
+			Name: "merge-patch",
+			Bass: `(merge-patch {:spec {:replicas 1 :paused true :tags ["a"]}} {:spec {:replicas 3 :paused null :tags ["b"]}})`,
+			Result: bass.Bindings{
+				"spec": bass.Bindings{
+					"replicas": bass.Int(3),
+					"tags":     bass.NewList(bass.String("b")),
+				}.Scope(),
+			}.Scope(),
+		},
+		{
+			Name:   "merge-patch non-scope",
+			Bass:   `(merge-patch {:a 1} [1 2])`,
+			Result: bass.NewList(bass.Int(1), bass.Int(2)),
+		},
 	} {
 		t.Run(example.Name, example.Run)
 	}
@@ -2203,3 +3247,84 @@ func TestGroundMeta(t *testing.T) {
 		t.Run(example.Name, example.Run)
 	}
 }
+
+func TestGroundContracts(t *testing.T) {
+	type example struct {
+		Name      string
+		Bass      string
+		Contracts bool
+
+		Result      bass.Value
+		ErrContains string
+	}
+
+	for _, test := range []example{
+		{
+			Name:   "assert passes",
+			Bass:   "(assert (= 4 (+ 2 2)))",
+			Result: bass.Bool(true),
+		},
+		{
+			Name:        "assert fails",
+			Bass:        "(assert (= 5 (+ 2 2)))",
+			ErrContains: "assertion failed at <fs>/test:1:8..1:21: (= 5 (+ 2 2))",
+		},
+		{
+			Name:        "assert fails with message",
+			Bass:        `(assert false (str "math" " is broken"))`,
+			ErrContains: "assertion failed at <fs>/test:1:8..1:13: false: math is broken",
+		},
+		{
+			Name:   "contracts disabled",
+			Bass:   `(defn halve [x] (pre (number? x)) (post string?) (quot x 2)) (halve 4)`,
+			Result: bass.Int(2),
+		},
+		{
+			Name:      "contracts pass",
+			Bass:      `(defn halve [x] (pre (number? x) (>= x 0)) (post number?) (quot x 2)) (halve 4)`,
+			Contracts: true,
+			Result:    bass.Int(2),
+		},
+		{
+			Name:        "precondition fails",
+			Bass:        `(defn halve [x] (pre (number? x) (>= x 0)) (quot x 2)) (halve -4)`,
+			Contracts:   true,
+			ErrContains: "precondition failed at <fs>/test:1:33..1:41: (>= x 0)",
+		},
+		{
+			Name:        "postcondition fails",
+			Bass:        `(defn halve [x] (post string?) (quot x 2)) (halve 4)`,
+			Contracts:   true,
+			ErrContains: "postcondition failed at <fs>/test:1:22..1:29: (string? 2)",
+		},
+		{
+			Name:      "postcondition does not evaluate result",
+			Bass:      `(defn sym [] (post symbol?) (quote foo)) (sym)`,
+			Contracts: true,
+			Result:    bass.Symbol("foo"),
+		},
+		{
+			Name:        "misplaced",
+			Bass:        `(defn halve [x] (quot x 2) (pre (number? x))) (halve 4)`,
+			ErrContains: "must lead the body",
+		},
+	} {
+		t.Run(test.Name, func(t *testing.T) {
+			is := is.New(t)
+
+			ctx := context.Background()
+			if test.Contracts {
+				ctx = bass.WithContracts(ctx)
+			}
+
+			res, err := bass.EvalFSFile(ctx, bass.NewStandardScope(), bass.NewInMemoryFile("test", test.Bass))
+			if test.ErrContains != "" {
+				is.True(err != nil)
+				is.True(strings.Contains(err.Error(), test.ErrContains))
+			} else {
+				is.NoErr(err)
+				Equal(t, res, test.Result)
+			}
+		})
+	}
+}
@@ -0,0 +1,411 @@
+package bass
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gofrs/flock"
+)
+
+// HistoryPath is the file where each script run is recorded, one JSON record
+// per line.
+func HistoryPath() string {
+	return filepath.Join(CacheHome, "history.jsonl")
+}
+
+// Run statuses recorded in the run history.
+const (
+	RunSucceeded = "succeeded"
+	RunFailed    = "failed"
+)
+
+// RunRecord describes a script run in the run history.
+type RunRecord struct {
+	// Category groups related runs, e.g. "test" or "test:<commit>".
+	Category string `json:"category"`
+
+	// Script is the path to the script that was run.
+	Script string `json:"script"`
+
+	// ScriptDigest is the SHA256 digest of the script's content.
+	ScriptDigest string `json:"script_digest,omitempty"`
+
+	// Thunks lists the SHA256 digests of the thunks run by the script.
+	Thunks []string `json:"thunks,omitempty"`
+
+	// Started is when the run started.
+	Started time.Time `json:"started"`
+
+	// Duration is how long the run took.
+	Duration time.Duration `json:"duration"`
+
+	// Status is either RunSucceeded or RunFailed.
+	Status string `json:"status"`
+
+	// Error is the error message of a failed run.
+	Error string `json:"error,omitempty"`
+
+	// Approvals lists the gates approved during the run.
+	Approvals []GateApproval `json:"approvals,omitempty"`
+
+	// Flakes lists the runs of thunks with retries during the run.
+	Flakes []FlakeRecord `json:"flakes,omitempty"`
+}
+
+// ToValue returns the record as a scope.
+func (record RunRecord) ToValue() Value {
+	thunks := make([]Value, len(record.Thunks))
+	for i, digest := range record.Thunks {
+		thunks[i] = String(digest)
+	}
+
+	scope := Bindings{
+		"category":      String(record.Category),
+		"script":        String(record.Script),
+		"script-digest": String(record.ScriptDigest),
+		"thunks":        NewList(thunks...),
+		"started":       String(record.Started.Format(time.RFC3339)),
+		"duration-ms":   Int(record.Duration.Milliseconds()),
+		"status":        Symbol(record.Status),
+	}.Scope()
+
+	if record.Error != "" {
+		scope.Set("error", String(record.Error))
+	}
+
+	if len(record.Approvals) > 0 {
+		approvals := make([]Value, len(record.Approvals))
+		for i, approval := range record.Approvals {
+			approvals[i] = approval.ToValue()
+		}
+
+		scope.Set("approvals", NewList(approvals...))
+	}
+
+	return scope
+}
+
+// RunHistory collects the thunks run during a script run.
+type RunHistory struct {
+	mu        sync.Mutex
+	thunks    []string
+	seen      map[string]bool
+	approvals []GateApproval
+	flakes    []FlakeRecord
+}
+
+type historyKey struct{}
+
+// TrackHistory returns a context in which each thunk run is collected into
+// the returned RunHistory.
+func TrackHistory(ctx context.Context) (context.Context, *RunHistory) {
+	history := &RunHistory{
+		seen: map[string]bool{},
+	}
+
+	return context.WithValue(ctx, historyKey{}, history), history
+}
+
+// Thunks returns the digests of the thunks run so far, in the order they were
+// first run.
+func (history *RunHistory) Thunks() []string {
+	history.mu.Lock()
+	defer history.mu.Unlock()
+
+	return append([]string{}, history.thunks...)
+}
+
+// Approvals returns the gates approved so far, in the order they were
+// approved.
+func (history *RunHistory) Approvals() []GateApproval {
+	history.mu.Lock()
+	defer history.mu.Unlock()
+
+	return append([]GateApproval{}, history.approvals...)
+}
+
+// Flakes returns the runs of thunks with retries so far.
+func (history *RunHistory) Flakes() []FlakeRecord {
+	history.mu.Lock()
+	defer history.mu.Unlock()
+
+	return append([]FlakeRecord{}, history.flakes...)
+}
+
+// noteFlake adds the flake record to the run history in the context, if any.
+func noteFlake(ctx context.Context, record FlakeRecord) {
+	history, ok := ctx.Value(historyKey{}).(*RunHistory)
+	if !ok {
+		return
+	}
+
+	history.mu.Lock()
+	defer history.mu.Unlock()
+
+	history.flakes = append(history.flakes, record)
+}
+
+// noteApproval adds the approval to the run history in the context, if any.
+func noteApproval(ctx context.Context, approval GateApproval) {
+	history, ok := ctx.Value(historyKey{}).(*RunHistory)
+	if !ok {
+		return
+	}
+
+	history.mu.Lock()
+	defer history.mu.Unlock()
+
+	history.approvals = append(history.approvals, approval)
+}
+
+// noteThunk adds the thunk to the run history in the context, if any.
+func noteThunk(ctx context.Context, thunk Thunk) {
+	history, ok := ctx.Value(historyKey{}).(*RunHistory)
+	if !ok {
+		return
+	}
+
+	digest, err := thunk.SHA256()
+	if err != nil {
+		return
+	}
+
+	history.mu.Lock()
+	defer history.mu.Unlock()
+
+	if history.seen[digest] {
+		return
+	}
+
+	history.seen[digest] = true
+	history.thunks = append(history.thunks, digest)
+}
+
+// HistorySizeLimit is the size in bytes past which the run history is
+// trimmed. The oldest records are dropped until it is half this size.
+var HistorySizeLimit int64 = 16 * 1024 * 1024
+
+// historyChunkSize is how much of the run history is read at a time while
+// scanning it backwards.
+const historyChunkSize = 64 * 1024
+
+// historyLock returns a lock which guards the run history across processes.
+//
+// NB: the history file itself is not locked since trimming replaces it.
+func historyLock() *flock.Flock {
+	return flock.New(HistoryPath() + ".lock")
+}
+
+// AppendHistory adds the record to the run history, trimming the oldest
+// records if the history has grown past HistorySizeLimit.
+func AppendHistory(record RunRecord) error {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(HistoryPath()), 0700); err != nil {
+		return err
+	}
+
+	lock := historyLock()
+	if err := lock.Lock(); err != nil {
+		return fmt.Errorf("lock: %w", err)
+	}
+
+	defer lock.Unlock()
+
+	f, err := os.OpenFile(HistoryPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	if _, err := f.Write(append(payload, '\n')); err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if info.Size() > HistorySizeLimit {
+		return trimHistory(info.Size() - HistorySizeLimit/2)
+	}
+
+	return nil
+}
+
+// trimHistory drops the records in the first n bytes of the run history,
+// along with the record straddling the cutoff. The history must be locked.
+func trimHistory(n int64) error {
+	content, err := os.ReadFile(HistoryPath())
+	if err != nil {
+		return err
+	}
+
+	if n > int64(len(content)) {
+		n = int64(len(content))
+	}
+
+	kept := content[n:]
+	if n > 0 && content[n-1] != '\n' {
+		idx := bytes.IndexByte(kept, '\n')
+		if idx == -1 {
+			kept = nil
+		} else {
+			kept = kept[idx+1:]
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(HistoryPath()), "history-*.jsonl")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(kept); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), HistoryPath())
+}
+
+// ReadHistory returns the recorded runs in the given category, most recent
+// first. An empty category returns all runs.
+func ReadHistory(category string) ([]RunRecord, error) {
+	var records []RunRecord
+	err := scanHistory(func(record RunRecord) bool {
+		if category == "" || record.Category == category {
+			records = append(records, record)
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// LastSuccess returns the most recent successful run in the category.
+//
+// The history is scanned from the end, so this only reads as far back as the
+// last success.
+func LastSuccess(category string) (RunRecord, bool, error) {
+	var last RunRecord
+	var found bool
+	err := scanHistory(func(record RunRecord) bool {
+		if record.Category == category && record.Status == RunSucceeded {
+			last = record
+			found = true
+			return false
+		}
+
+		return true
+	})
+	if err != nil {
+		return RunRecord{}, false, err
+	}
+
+	return last, found, nil
+}
+
+// scanHistory calls cb with each record in the run history, most recent
+// first, until it returns false.
+func scanHistory(cb func(RunRecord) bool) error {
+	if _, err := os.Stat(filepath.Dir(HistoryPath())); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	lock := historyLock()
+	if err := lock.RLock(); err != nil {
+		return fmt.Errorf("lock: %w", err)
+	}
+
+	defer lock.Unlock()
+
+	f, err := os.Open(HistoryPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	emit := func(line []byte) bool {
+		if len(line) == 0 {
+			return true
+		}
+
+		var record RunRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			// skip records truncated by a crash
+			return true
+		}
+
+		return cb(record)
+	}
+
+	chunk := make([]byte, historyChunkSize)
+
+	var partial []byte
+	for offset := info.Size(); offset > 0; {
+		n := int64(len(chunk))
+		if n > offset {
+			n = offset
+		}
+
+		offset -= n
+
+		if _, err := f.ReadAt(chunk[:n], offset); err != nil {
+			return err
+		}
+
+		// NB: the capacity limit makes append copy, so partial never aliases
+		// chunk
+		lines := bytes.Split(append(chunk[:n:n], partial...), []byte{'\n'})
+
+		partial = lines[0]
+		for i := len(lines) - 1; i > 0; i-- {
+			if !emit(lines[i]) {
+				return nil
+			}
+		}
+	}
+
+	emit(partial)
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package bass_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vito/bass/pkg/bass"
+	. "github.com/vito/bass/pkg/basstest"
+	"github.com/vito/is"
+)
+
+func TestHistory(t *testing.T) {
+	is := is.New(t)
+
+	oldHome := bass.CacheHome
+	bass.CacheHome = t.TempDir()
+	defer func() { bass.CacheHome = oldHome }()
+
+	records, err := bass.ReadHistory("")
+	is.NoErr(err)
+	is.Equal(len(records), 0)
+
+	_, found, err := bass.LastSuccess("test")
+	is.NoErr(err)
+	is.True(!found)
+
+	started := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	for _, record := range []bass.RunRecord{
+		{Category: "test", Script: "a.bass", Started: started, Status: bass.RunSucceeded, Thunks: []string{"abc"}},
+		{Category: "build", Script: "b.bass", Started: started, Status: bass.RunSucceeded},
+		{Category: "test", Script: "c.bass", Started: started.Add(time.Minute), Status: bass.RunFailed, Error: "boom"},
+	} {
+		is.NoErr(bass.AppendHistory(record))
+	}
+
+	records, err = bass.ReadHistory("")
+	is.NoErr(err)
+	is.Equal(len(records), 3)
+	is.Equal(records[0].Script, "c.bass")
+
+	records, err = bass.ReadHistory("test")
+	is.NoErr(err)
+	is.Equal(len(records), 2)
+
+	last, found, err := bass.LastSuccess("test")
+	is.NoErr(err)
+	is.True(found)
+	is.Equal(last.Script, "a.bass")
+
+	res, err := bass.EvalFSFile(context.Background(), bass.NewStandardScope(), bass.NewInMemoryFile("test", `
+		[(:script (last-success "test"))
+		 (:thunks (last-success "test"))
+		 (:started (last-success "test"))
+		 (last-success "deploy")]
+	`))
+	is.NoErr(err)
+	Equal(t, res, bass.NewList(
+		bass.String("a.bass"),
+		bass.NewList(bass.String("abc")),
+		bass.String("2022-01-02T03:04:05Z"),
+		bass.Null{},
+	))
+}
+
+func TestHistoryTrim(t *testing.T) {
+	is := is.New(t)
+
+	oldHome := bass.CacheHome
+	bass.CacheHome = t.TempDir()
+	defer func() { bass.CacheHome = oldHome }()
+
+	oldLimit := bass.HistorySizeLimit
+	bass.HistorySizeLimit = 64 * 1024
+	defer func() { bass.HistorySizeLimit = oldLimit }()
+
+	started := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// NB: large enough for reads to span several chunks
+	padding := strings.Repeat("x", 1000)
+
+	errs := make(chan error, 4*50)
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+
+			for i := 0; i < 50; i++ {
+				errs <- bass.AppendHistory(bass.RunRecord{
+					Category: fmt.Sprintf("worker-%d", w),
+					Script:   fmt.Sprintf("%d.bass", i),
+					Started:  started.Add(time.Duration(i) * time.Minute),
+					Status:   bass.RunFailed,
+					Error:    padding,
+				})
+			}
+		}(w)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		is.NoErr(err)
+	}
+
+	is.NoErr(bass.AppendHistory(bass.RunRecord{
+		Category: "worker-0",
+		Script:   "last.bass",
+		Started:  started,
+		Status:   bass.RunSucceeded,
+	}))
+
+	info, err := os.Stat(bass.HistoryPath())
+	is.NoErr(err)
+	is.True(info.Size() <= bass.HistorySizeLimit)
+
+	records, err := bass.ReadHistory("")
+	is.NoErr(err)
+	is.True(len(records) > 0)
+	is.True(len(records) < 200)
+	is.Equal(records[0].Script, "last.bass")
+
+	for w := 0; w < 4; w++ {
+		records, err := bass.ReadHistory(fmt.Sprintf("worker-%d", w))
+		is.NoErr(err)
+
+		if w == 0 {
+			records = records[1:]
+		}
+
+		// each worker's records are kept in order, newest first
+		for i, record := range records {
+			is.Equal(record.Script, fmt.Sprintf("%d.bass", 49-i))
+			is.Equal(record.Error, padding)
+		}
+	}
+
+	last, found, err := bass.LastSuccess("worker-0")
+	is.NoErr(err)
+	is.True(found)
+	is.Equal(last.Script, "last.bass")
+}
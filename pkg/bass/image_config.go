@@ -0,0 +1,114 @@
+package bass
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// ImageConfigResolver is implemented by runtimes which can fetch the config
+// of an image without pulling its layers.
+type ImageConfigResolver interface {
+	// ImageConfig returns the raw OCI image config JSON of the image.
+	ImageConfig(context.Context, ImageRef) ([]byte, error)
+}
+
+// ImageConfig is the subset of an OCI image config which is useful to
+// scripts.
+type ImageConfig struct {
+	Env          map[string]string
+	Entrypoint   []string
+	Cmd          []string
+	WorkingDir   string
+	User         string
+	Labels       map[string]string
+	ExposedPorts []string
+}
+
+// ParseImageConfig parses the raw OCI image config JSON returned by an
+// ImageConfigResolver.
+func ParseImageConfig(payload []byte) (ImageConfig, error) {
+	var img ocispecs.Image
+	if err := json.Unmarshal(payload, &img); err != nil {
+		return ImageConfig{}, fmt.Errorf("unmarshal image config: %w", err)
+	}
+
+	config := ImageConfig{
+		Env:        map[string]string{},
+		Entrypoint: img.Config.Entrypoint,
+		Cmd:        img.Config.Cmd,
+		WorkingDir: img.Config.WorkingDir,
+		User:       img.Config.User,
+		Labels:     img.Config.Labels,
+	}
+
+	for _, kv := range img.Config.Env {
+		k, v, _ := strings.Cut(kv, "=")
+		config.Env[k] = v
+	}
+
+	for port := range img.Config.ExposedPorts {
+		config.ExposedPorts = append(config.ExposedPorts, port)
+	}
+
+	sort.Strings(config.ExposedPorts)
+
+	return config, nil
+}
+
+// ToValue returns the config as a scope.
+func (config ImageConfig) ToValue() Value {
+	env := NewEmptyScope()
+	for k, v := range config.Env {
+		env.Set(Symbol(k), String(v))
+	}
+
+	labels := NewEmptyScope()
+	for k, v := range config.Labels {
+		labels.Set(Symbol(k), String(v))
+	}
+
+	return Bindings{
+		"env":           env,
+		"entrypoint":    stringsToList(config.Entrypoint),
+		"cmd":           stringsToList(config.Cmd),
+		"workdir":       String(config.WorkingDir),
+		"user":          String(config.User),
+		"labels":        labels,
+		"exposed-ports": stringsToList(config.ExposedPorts),
+	}.Scope()
+}
+
+func stringsToList(strs []string) List {
+	vals := make([]Value, len(strs))
+	for i, str := range strs {
+		vals[i] = String(str)
+	}
+
+	return NewList(vals...)
+}
+
+// ResolveImageConfig fetches the config of the image from the runtime for
+// its platform.
+func ResolveImageConfig(ctx context.Context, ref ImageRef) (ImageConfig, error) {
+	runtime, err := RuntimeFromContext(ctx, ref.Platform)
+	if err != nil {
+		return ImageConfig{}, err
+	}
+
+	resolver, ok := runtime.(ImageConfigResolver)
+	if !ok {
+		return ImageConfig{}, fmt.Errorf("runtime %T cannot fetch image configs", runtime)
+	}
+
+	payload, err := resolver.ImageConfig(ctx, ref)
+	if err != nil {
+		return ImageConfig{}, err
+	}
+
+	return ParseImageConfig(payload)
+}
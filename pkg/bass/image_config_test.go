@@ -0,0 +1,86 @@
+package bass_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/runtimes"
+	"github.com/vito/is"
+)
+
+type imageConfigRuntime struct {
+	FakeRuntime
+
+	configs map[string]string
+}
+
+func (fake *imageConfigRuntime) ImageConfig(_ context.Context, ref bass.ImageRef) ([]byte, error) {
+	return []byte(fake.configs[ref.Repository.Static+":"+ref.Tag]), nil
+}
+
+func TestImageConfig(t *testing.T) {
+	is := is.New(t)
+
+	fake := &imageConfigRuntime{
+		configs: map[string]string{
+			"golang:1.19": `{
+				"architecture": "amd64",
+				"os": "linux",
+				"config": {
+					"Env": ["PATH=/usr/local/go/bin:/usr/bin", "GOLANG_VERSION=1.19", "EMPTY="],
+					"Cmd": ["bash"],
+					"WorkingDir": "/go",
+					"ExposedPorts": {"8080/tcp": {}, "443/tcp": {}},
+					"Labels": {"org.opencontainers.image.source": "https://github.com/docker-library/golang"}
+				}
+			}`,
+		},
+	}
+
+	ctx := bass.WithRuntimePool(context.Background(), &runtimes.Pool{
+		Runtimes: []runtimes.Assoc{
+			{
+				Platform: bass.LinuxPlatform,
+				Runtime:  fake,
+			},
+		},
+	})
+
+	res, err := bass.EvalFSFile(ctx, bass.NewStandardScope(), bass.NewInMemoryFile("test", `
+		(def config (image-config "golang:1.19"))
+		[(:GOLANG_VERSION (:env config))
+		 (:EMPTY (:env config))
+		 (:cmd config)
+		 (:entrypoint config)
+		 (:workdir config)
+		 (:exposed-ports config)
+		 (:org.opencontainers.image.source (:labels config))]
+	`))
+	is.NoErr(err)
+	is.Equal(res, bass.NewList(
+		bass.String("1.19"),
+		bass.String(""),
+		bass.NewList(bass.String("bash")),
+		bass.NewList(),
+		bass.String("/go"),
+		bass.NewList(bass.String("443/tcp"), bass.String("8080/tcp")),
+		bass.String("https://github.com/docker-library/golang"),
+	))
+
+	t.Run("unsupported runtime", func(t *testing.T) {
+		is := is.New(t)
+
+		ctx := bass.WithRuntimePool(context.Background(), &runtimes.Pool{
+			Runtimes: []runtimes.Assoc{
+				{
+					Platform: bass.LinuxPlatform,
+					Runtime:  &FakeRuntime{},
+				},
+			},
+		})
+
+		_, err := bass.EvalFSFile(ctx, bass.NewStandardScope(), bass.NewInMemoryFile("test", `(image-config "golang:1.19")`))
+		is.True(err != nil)
+	})
+}
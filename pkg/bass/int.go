@@ -21,6 +21,9 @@ func (value Int) Decode(dest any) error {
 	case *Int:
 		*x = value
 		return nil
+	case *Number:
+		*x = value
+		return nil
 	case *Value:
 		*x = value
 		return nil
@@ -30,6 +33,9 @@ func (value Int) Decode(dest any) error {
 	case *int:
 		*x = int(value)
 		return nil
+	case *float64:
+		*x = float64(value)
+		return nil
 	default:
 		return DecodeError{
 			Source:      value,
@@ -40,7 +46,12 @@ func (value Int) Decode(dest any) error {
 
 // Eval returns the value.
 func (value Int) Eval(_ context.Context, _ *Scope, cont Cont) ReadyCont {
-	return cont.Call(value, nil)
+	return cont.Call(IntValue(int(value)), nil)
+}
+
+// Float64 returns the integer as a float64.
+func (value Int) Float64() float64 {
+	return float64(value)
 }
 
 var _ Bindable = Int(0)
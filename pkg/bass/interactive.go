@@ -0,0 +1,109 @@
+package bass
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// InteractiveRuntime is implemented by runtimes which can run a thunk
+// attached to the user's terminal.
+type InteractiveRuntime interface {
+	// Interact runs the thunk's command with the given stdio, returning once
+	// it exits. The result is not cached.
+	Interact(context.Context, Thunk, InteractiveIO) error
+}
+
+// InteractiveIO configures the stdio of an interactive thunk.
+type InteractiveIO struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// TTY allocates a pseudo-terminal for the command, in which case Stderr
+	// is ignored.
+	TTY bool
+
+	// Resize sends the size of the user's terminal whenever it changes,
+	// starting with its initial size. It is closed once the command is no
+	// longer attached.
+	Resize <-chan WindowSize
+}
+
+// WindowSize is the size of a terminal, in characters.
+type WindowSize struct {
+	Rows int
+	Cols int
+}
+
+// Terminal attaches interactive thunks to the user's terminal.
+type Terminal interface {
+	// Attach prepares the terminal for an interactive command, e.g. by
+	// switching it to raw mode, and returns the stdio to use along with a
+	// function which restores the terminal.
+	//
+	// Returns ErrNonInteractive if there is no terminal to attach to.
+	Attach() (InteractiveIO, func(), error)
+}
+
+// ErrNoInteractiveRuntime is returned when running a thunk interactively on a
+// runtime which does not support it.
+var ErrNoInteractiveRuntime = errors.New("runtime does not support interactive thunks")
+
+type terminalKey struct{}
+
+// WithTerminal sets the Terminal used by (interact).
+func WithTerminal(ctx context.Context, term Terminal) context.Context {
+	return context.WithValue(ctx, terminalKey{}, term)
+}
+
+// TerminalFromContext returns the Terminal set in the context, or a Terminal
+// which always returns ErrNonInteractive.
+func TerminalFromContext(ctx context.Context) Terminal {
+	term := ctx.Value(terminalKey{})
+	if term == nil {
+		return NonInteractive{}
+	}
+
+	return term.(Terminal)
+}
+
+func (NonInteractive) Attach() (InteractiveIO, func(), error) {
+	return InteractiveIO{}, nil, ErrNonInteractive
+}
+
+// Interact runs the thunk with the given stdio using the runtime for its
+// platform.
+func (thunk Thunk) Interact(ctx context.Context, tio InteractiveIO) error {
+	platform := thunk.Platform()
+	if platform == nil {
+		return fmt.Errorf("cannot interact with a thunk with no image: %s", thunk)
+	}
+
+	runtime, err := RuntimeFromContext(ctx, *platform)
+	if err != nil {
+		return err
+	}
+
+	interactive, ok := runtime.(InteractiveRuntime)
+	if !ok {
+		return ErrNoInteractiveRuntime
+	}
+
+	noteThunk(ctx, thunk)
+
+	return interactive.Interact(ctx, thunk, tio)
+}
+
+// InteractTerminal runs the thunk attached to the Terminal in the context.
+func (thunk Thunk) InteractTerminal(ctx context.Context) error {
+	tio, restore, err := TerminalFromContext(ctx).Attach()
+	if err != nil {
+		return err
+	}
+
+	defer restore()
+
+	return thunk.Interact(ctx, tio)
+}
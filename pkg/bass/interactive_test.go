@@ -0,0 +1,66 @@
+package bass_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/runtimes"
+	"github.com/vito/is"
+)
+
+type fakeTerminal struct {
+	stdout   *bytes.Buffer
+	restored bool
+}
+
+func (fake *fakeTerminal) Attach() (bass.InteractiveIO, func(), error) {
+	return bass.InteractiveIO{
+		Stdin:  new(bytes.Buffer),
+		Stdout: fake.stdout,
+		TTY:    true,
+	}, func() { fake.restored = true }, nil
+}
+
+func TestInteract(t *testing.T) {
+	is := is.New(t)
+
+	fake := &FakeRuntime{}
+
+	ctx := bass.WithRuntimePool(context.Background(), &runtimes.Pool{
+		Runtimes: []runtimes.Assoc{
+			{
+				Platform: fakePlatform,
+				Runtime:  fake,
+			},
+		},
+	})
+
+	thunk := bass.Thunk{
+		Image: &bass.ThunkImage{
+			Ref: &bass.ImageRef{
+				Platform: fakePlatform,
+			},
+		},
+		Cmd: bass.ThunkCmd{Cmd: &bass.CommandPath{"sh"}},
+	}
+
+	scope := bass.NewStandardScope()
+	scope.Set("thunk", thunk)
+
+	_, err := bass.EvalFSFile(ctx, scope, bass.NewInMemoryFile("test", "(interact thunk)"))
+	is.True(errors.Is(err, bass.ErrNonInteractive))
+	is.Equal(len(fake.Interacted), 0)
+
+	term := &fakeTerminal{stdout: new(bytes.Buffer)}
+	ctx = bass.WithTerminal(ctx, term)
+
+	_, err = bass.EvalFSFile(ctx, scope, bass.NewInMemoryFile("test", "(interact thunk)"))
+	is.NoErr(err)
+	is.Equal(len(fake.Interacted), 1)
+	is.True(fake.Interacted[0].Equal(thunk))
+	is.Equal(term.stdout.String(), "interacted with sh")
+	is.True(term.restored)
+}
@@ -0,0 +1,68 @@
+package bass
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Bounds of the small Int values which are boxed once up front.
+const (
+	minSmallInt = -256
+	maxSmallInt = 1024
+)
+
+var smallInts [maxSmallInt - minSmallInt]Number
+
+func init() {
+	for i := range smallInts {
+		smallInts[i] = Int(i + minSmallInt)
+	}
+}
+
+// IntValue returns the Int as a Value, reusing a preallocated Value for small
+// ints to avoid allocating on every conversion.
+func IntValue(i int) Number {
+	if i >= minSmallInt && i < maxSmallInt {
+		return smallInts[i-minSmallInt]
+	}
+
+	return Int(i)
+}
+
+// maxInterned bounds the number of interned symbols, so that long-running
+// processes which decode many distinct keys do not grow without bound.
+const maxInterned = 1 << 16
+
+var (
+	interned      sync.Map // string => Value holding a Symbol
+	internedCount int64
+)
+
+// SymbolValue returns the symbol with the given name as a Value, reusing a
+// previously allocated Value for the same name.
+//
+// Note that Null{} and Empty{} need no such treatment; they are zero-sized, so
+// converting them to a Value never allocates.
+func SymbolValue(name string) Value {
+	if val, found := interned.Load(name); found {
+		return val.(Value)
+	}
+
+	var val Value = Symbol(name)
+	if atomic.LoadInt64(&internedCount) >= maxInterned {
+		return val
+	}
+
+	actual, loaded := interned.LoadOrStore(name, val)
+	if !loaded {
+		atomic.AddInt64(&internedCount, 1)
+	}
+
+	return actual.(Value)
+}
+
+// Intern returns the symbol with the given name, sharing its storage with
+// previously interned symbols of the same name.
+func Intern(name string) Symbol {
+	return SymbolValue(name).(Symbol)
+}
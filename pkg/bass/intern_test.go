@@ -0,0 +1,33 @@
+package bass_test
+
+import (
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/is"
+)
+
+func TestIntValue(t *testing.T) {
+	is := is.New(t)
+
+	for _, i := range []int{-1000, -256, -1, 0, 1, 255, 256, 1023, 1024, 1 << 40} {
+		is.Equal(bass.IntValue(i), bass.Int(i))
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = bass.IntValue(512)
+	})
+	is.Equal(allocs, 0.0)
+}
+
+func TestSymbolValue(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(bass.SymbolValue("interned-sym"), bass.Symbol("interned-sym"))
+	is.Equal(bass.Intern("interned-sym"), bass.Symbol("interned-sym"))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = bass.SymbolValue("interned-sym")
+	})
+	is.Equal(allocs, 0.0)
+}
@@ -13,15 +13,9 @@ import (
 var Internal *Scope = NewEmptyScope()
 
 func init() {
-	Internal.Set("string-upper-case",
-		Func("string-upper-case", "[str]", strings.ToUpper))
-
 	Internal.Set("string-contains",
 		Func("string-contains", "[str substr]", strings.Contains))
 
-	Internal.Set("string-split",
-		Func("string-split", "[delim str]", strings.Split))
-
 	Internal.Set("time-measure",
 		Op("time-measure", "[form]", func(ctx context.Context, cont Cont, scope *Scope, form Value) ReadyCont {
 			before := Clock.Now()
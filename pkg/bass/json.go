@@ -1,10 +1,12 @@
 package bass
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"sync"
 )
 
 func NewDecoder(r io.Reader) *Decoder {
@@ -36,6 +38,126 @@ func NewEncoder(w io.Writer) *json.Encoder {
 	return enc
 }
 
+// Encoder writes values as JSON, streaming the elements of lists and the
+// bindings of scopes straight to the writer rather than marshaling each of
+// them into memory first.
+type Encoder struct {
+	w *bufio.Writer
+
+	// scratch holds each non-list, non-scope value as it is encoded.
+	scratch    *bytes.Buffer
+	scratchEnc *json.Encoder
+}
+
+// NewValueEncoder returns an Encoder which writes to w.
+func NewValueEncoder(w io.Writer) *Encoder {
+	scratch := new(bytes.Buffer)
+
+	return &Encoder{
+		w: bufio.NewWriter(w),
+
+		scratch:    scratch,
+		scratchEnc: NewEncoder(scratch),
+	}
+}
+
+// EncodeValue writes the value as JSON followed by a newline.
+func (enc *Encoder) EncodeValue(val Value) error {
+	if err := enc.encode(val); err != nil {
+		return err
+	}
+
+	if err := enc.w.WriteByte('\n'); err != nil {
+		return err
+	}
+
+	return enc.w.Flush()
+}
+
+func (enc *Encoder) encode(val Value) error {
+	switch x := val.(type) {
+	case Annotated:
+		return enc.encode(x.Value)
+
+	case Pair:
+		if err := enc.w.WriteByte('['); err != nil {
+			return err
+		}
+
+		var rest Value = x
+		for first := true; ; first = false {
+			pair, ok := rest.(Pair)
+			if !ok {
+				break
+			}
+
+			if !first {
+				if err := enc.w.WriteByte(','); err != nil {
+					return err
+				}
+			}
+
+			if err := enc.encode(pair.A); err != nil {
+				return err
+			}
+
+			rest = pair.D
+		}
+
+		if _, ok := rest.(Empty); !ok {
+			return EncodeError{val}
+		}
+
+		return enc.w.WriteByte(']')
+
+	case *Scope:
+		if err := enc.w.WriteByte('{'); err != nil {
+			return err
+		}
+
+		first := true
+		err := x.Each(func(k Symbol, v Value) error {
+			if !first {
+				if err := enc.w.WriteByte(','); err != nil {
+					return err
+				}
+			}
+
+			first = false
+
+			if err := enc.write(k.JSONKey()); err != nil {
+				return err
+			}
+
+			if err := enc.w.WriteByte(':'); err != nil {
+				return err
+			}
+
+			return enc.encode(v)
+		})
+		if err != nil {
+			return err
+		}
+
+		return enc.w.WriteByte('}')
+
+	default:
+		return enc.write(val)
+	}
+}
+
+// write marshals the value with the standard encoder.
+func (enc *Encoder) write(val any) error {
+	enc.scratch.Reset()
+
+	if err := enc.scratchEnc.Encode(val); err != nil {
+		return err
+	}
+
+	_, err := enc.w.Write(bytes.TrimSuffix(enc.scratch.Bytes(), []byte{'\n'}))
+	return err
+}
+
 func UnmarshalJSON(payload []byte, dest any) error {
 	return NewDecoder(bytes.NewBuffer(payload)).Decode(dest)
 }
@@ -51,91 +173,142 @@ func MarshalJSON(val any) ([]byte, error) {
 	return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}), nil
 }
 
-func decodeValue(dec *json.Decoder) (Value, error) {
-	tok, err := dec.Token()
-	if err != nil {
-		return nil, err
-	}
+// decodeFrame is an object or array which is being decoded.
+type decodeFrame struct {
+	// scope is set when decoding an object.
+	scope *Scope
 
-	switch x := tok.(type) {
-	case nil:
-		return Null{}, nil
-	case bool:
-		return Bool(x), nil
-	case string:
-		return String(x), nil
+	// key is the key of the object's value being decoded.
+	key Symbol
 
-	case json.Number:
-		i, err := x.Int64()
-		if err != nil {
-			// TODO: make sure there's a test or justification for this, just
-			// matching current impl for now
-			return String(x.String()), nil
-		} else {
-			return Int(i), nil
-		}
+	// vals collects the values when decoding an array.
+	vals *[]Value
+}
+
+// valsPool recycles the slices used to collect array values, which are only
+// needed until the values are consed into a list.
+var valsPool = sync.Pool{
+	New: func() any {
+		vals := make([]Value, 0, 16)
+		return &vals
+	},
+}
+
+// decodeValue decodes the next JSON value from the decoder.
+//
+// Nested objects and arrays are tracked on an explicit stack rather than by
+// recursion, so arbitrarily deep payloads cannot exhaust the goroutine stack.
+func decodeValue(dec *json.Decoder) (Value, error) {
+	var stack []*decodeFrame
 
-	case json.Delim:
-		switch x {
-		case '{':
-			scope := NewEmptyScope()
+	for {
+		if len(stack) > 0 {
+			top := stack[len(stack)-1]
 
-			for dec.More() {
+			if top.scope != nil {
 				key, err := dec.Token()
 				if err != nil {
 					return nil, err
 				}
 
+				if key == json.Delim('}') {
+					stack = stack[:len(stack)-1]
+
+					val, done := decodeAttach(stack, top.scope)
+					if done {
+						return val, nil
+					}
+
+					continue
+				}
+
 				str, ok := key.(string)
 				if !ok {
 					return nil, fmt.Errorf("expected string key, got %T", key)
 				}
 
-				sym := SymbolFromJSONKey(str)
-
-				val, err := decodeValue(dec)
+				top.key = SymbolFromJSONKey(str)
+			} else if !dec.More() {
+				end, err := dec.Token()
 				if err != nil {
 					return nil, err
 				}
 
-				scope.Set(sym, val)
-			}
+				if end != json.Delim(']') {
+					return nil, fmt.Errorf("expected end of array, got %T: %v", end, end)
+				}
 
-			end, err := dec.Token()
-			if err != nil {
-				return nil, err
-			}
+				stack = stack[:len(stack)-1]
 
-			if end != json.Delim('}') {
-				return nil, fmt.Errorf("expected end of object, got %T: %v", end, end)
-			}
+				list := NewList(*top.vals...)
 
-			return scope, nil
-		case '[':
-			var vals []Value
-			for dec.More() {
-				val, err := decodeValue(dec)
-				if err != nil {
-					return nil, err
+				for i := range *top.vals {
+					(*top.vals)[i] = nil
 				}
 
-				vals = append(vals, val)
-			}
+				*top.vals = (*top.vals)[:0]
+				valsPool.Put(top.vals)
 
-			end, err := dec.Token()
-			if err != nil {
-				return nil, err
-			}
+				val, done := decodeAttach(stack, list)
+				if done {
+					return val, nil
+				}
 
-			if end != json.Delim(']') {
-				return nil, fmt.Errorf("expected end of array, got %T: %v", end, end)
+				continue
 			}
+		}
 
-			return NewList(vals...), nil
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		var val Value
+		switch x := tok.(type) {
+		case nil:
+			val = Null{}
+		case bool:
+			val = Bool(x)
+		case string:
+			val = String(x)
+		case json.Number:
+			val = jsonNumber(x)
+		case json.Delim:
+			switch x {
+			case '{':
+				stack = append(stack, &decodeFrame{scope: NewEmptyScope()})
+				continue
+			case '[':
+				stack = append(stack, &decodeFrame{vals: valsPool.Get().(*[]Value)})
+				continue
+			default:
+				return nil, fmt.Errorf("impossible: unknown delimiter: %s", x)
+			}
 		default:
-			return nil, fmt.Errorf("impossible: unknown delimiter: %s", x)
+			return nil, fmt.Errorf("impossible: unknown token: %v", x)
+		}
+
+		val, done := decodeAttach(stack, val)
+		if done {
+			return val, nil
 		}
-	default:
-		return nil, fmt.Errorf("impossible: unknown delimiter: %s", x)
 	}
 }
+
+// decodeAttach adds the decoded value to the innermost object or array on
+// the stack, or returns true if the stack is empty, in which case the value
+// is the result.
+func decodeAttach(stack []*decodeFrame, val Value) (Value, bool) {
+	if len(stack) == 0 {
+		return val, true
+	}
+
+	top := stack[len(stack)-1]
+	if top.scope != nil {
+		top.scope.Set(top.key, val)
+	} else {
+		*top.vals = append(*top.vals, val)
+	}
+
+	return nil, false
+}
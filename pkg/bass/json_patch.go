@@ -0,0 +1,339 @@
+package bass
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// JSONPatchOp is a single operation in a JSON Patch document (RFC 6902).
+type JSONPatchOp struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	From  string `json:"from,omitempty"`
+	Value Value  `json:"value,omitempty"`
+}
+
+// JSONPatch applies a JSON Patch (RFC 6902) to a value, returning the patched
+// value.
+//
+// Scopes and lists along the patched paths are copied, so the original value
+// is left untouched. Operations are applied in order; if one fails, the
+// whole patch fails.
+func JSONPatch(doc Value, ops []JSONPatchOp) (Value, error) {
+	for i, op := range ops {
+		var err error
+		doc, err = op.apply(doc)
+		if err != nil {
+			return nil, fmt.Errorf("json patch op %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	return doc, nil
+}
+
+func (op JSONPatchOp) apply(doc Value) (Value, error) {
+	path, err := parseJSONPointer(op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add":
+		if op.Value == nil {
+			return nil, fmt.Errorf("missing value")
+		}
+
+		return patchAdd(doc, path, op.Value)
+	case "remove":
+		return patchRemove(doc, path)
+	case "replace":
+		if op.Value == nil {
+			return nil, fmt.Errorf("missing value")
+		}
+
+		doc, err := patchRemove(doc, path)
+		if err != nil {
+			return nil, err
+		}
+
+		return patchAdd(doc, path, op.Value)
+	case "move":
+		from, err := parseJSONPointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, fmt.Errorf("cannot move %s into itself", op.From)
+		}
+
+		val, err := jsonPointerGet(doc, from)
+		if err != nil {
+			return nil, err
+		}
+
+		doc, err = patchRemove(doc, from)
+		if err != nil {
+			return nil, err
+		}
+
+		return patchAdd(doc, path, val)
+	case "copy":
+		from, err := parseJSONPointer(op.From)
+		if err != nil {
+			return nil, err
+		}
+
+		val, err := jsonPointerGet(doc, from)
+		if err != nil {
+			return nil, err
+		}
+
+		return patchAdd(doc, path, val)
+	case "test":
+		if op.Value == nil {
+			return nil, fmt.Errorf("missing value")
+		}
+
+		val, err := jsonPointerGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !val.Equal(op.Value) {
+			return nil, fmt.Errorf("test failed: %s != %s", val, op.Value)
+		}
+
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("unknown op: %q", op.Op)
+	}
+}
+
+// MergePatch applies a JSON Merge Patch (RFC 7386) to a value, returning the
+// patched value.
+//
+// Scopes in the patch are merged into the value recursively, with null values
+// removing the corresponding binding. Any other patch value replaces the
+// value entirely.
+func MergePatch(doc Value, patch Value) Value {
+	var patchScope *Scope
+	if err := patch.Decode(&patchScope); err != nil {
+		return patch
+	}
+
+	var docScope *Scope
+	if err := doc.Decode(&docScope); err != nil {
+		docScope = NewEmptyScope()
+	}
+
+	merged := docScope.Copy()
+	_ = patchScope.Each(func(k Symbol, v Value) error {
+		if _, isNull := v.(Null); isNull {
+			merged = withoutBinding(merged, k)
+			return nil
+		}
+
+		existing, found := merged.Get(k)
+		if !found {
+			existing = Null{}
+		}
+
+		merged.Set(k, MergePatch(existing, v))
+		return nil
+	})
+
+	return merged
+}
+
+// parseJSONPointer parses a JSON Pointer (RFC 6901) into its unescaped
+// reference tokens.
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid pointer %q: must start with /", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+
+	return tokens, nil
+}
+
+func jsonPointerGet(doc Value, path []string) (Value, error) {
+	for _, token := range path {
+		var scope *Scope
+		var list List
+		if err := doc.Decode(&scope); err == nil {
+			val, found := scope.Get(SymbolFromJSONKey(token))
+			if !found {
+				return nil, fmt.Errorf("path not found: %s", token)
+			}
+
+			doc = val
+		} else if err := doc.Decode(&list); err == nil {
+			vals, err := ToSlice(list)
+			if err != nil {
+				return nil, err
+			}
+
+			idx, err := patchIndex(token, len(vals)-1)
+			if err != nil {
+				return nil, err
+			}
+
+			doc = vals[idx]
+		} else {
+			return nil, fmt.Errorf("cannot index into %s", doc)
+		}
+	}
+
+	return doc, nil
+}
+
+func patchAdd(doc Value, path []string, val Value) (Value, error) {
+	if len(path) == 0 {
+		return val, nil
+	}
+
+	return patchParent(doc, path, func(scope *Scope, key Symbol) (*Scope, error) {
+		scope.Set(key, val)
+		return scope, nil
+	}, func(vals []Value, token string) ([]Value, error) {
+		if token == "-" {
+			return append(vals, val), nil
+		}
+
+		idx, err := patchIndex(token, len(vals))
+		if err != nil {
+			return nil, err
+		}
+
+		inserted := append([]Value{}, vals[:idx]...)
+		inserted = append(inserted, val)
+		return append(inserted, vals[idx:]...), nil
+	})
+}
+
+func patchRemove(doc Value, path []string) (Value, error) {
+	if len(path) == 0 {
+		return Null{}, nil
+	}
+
+	return patchParent(doc, path, func(scope *Scope, key Symbol) (*Scope, error) {
+		if _, found := scope.Get(key); !found {
+			return nil, fmt.Errorf("path not found: %s", key)
+		}
+
+		return withoutBinding(scope, key), nil
+	}, func(vals []Value, token string) ([]Value, error) {
+		idx, err := patchIndex(token, len(vals)-1)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(append([]Value{}, vals[:idx]...), vals[idx+1:]...), nil
+	})
+}
+
+// patchParent walks to the parent of the value at path, copying each scope
+// and list along the way, and updates it with the appropriate callback.
+func patchParent(
+	doc Value,
+	path []string,
+	updateScope func(*Scope, Symbol) (*Scope, error),
+	updateList func([]Value, string) ([]Value, error),
+) (Value, error) {
+	token := path[0]
+
+	var scope *Scope
+	if err := doc.Decode(&scope); err == nil {
+		key := SymbolFromJSONKey(token)
+
+		if len(path) == 1 {
+			return updateScope(scope.Copy(), key)
+		}
+
+		child, found := scope.Get(key)
+		if !found {
+			return nil, fmt.Errorf("path not found: %s", token)
+		}
+
+		patched, err := patchParent(child, path[1:], updateScope, updateList)
+		if err != nil {
+			return nil, err
+		}
+
+		copied := scope.Copy()
+		copied.Set(key, patched)
+		return copied, nil
+	}
+
+	var list List
+	if err := doc.Decode(&list); err == nil {
+		vals, err := ToSlice(list)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(path) == 1 {
+			vals, err := updateList(vals, token)
+			if err != nil {
+				return nil, err
+			}
+
+			return NewList(vals...), nil
+		}
+
+		idx, err := patchIndex(token, len(vals)-1)
+		if err != nil {
+			return nil, err
+		}
+
+		patched, err := patchParent(vals[idx], path[1:], updateScope, updateList)
+		if err != nil {
+			return nil, err
+		}
+
+		copied := append([]Value{}, vals...)
+		copied[idx] = patched
+		return NewList(copied...), nil
+	}
+
+	return nil, fmt.Errorf("cannot index into %s", doc)
+}
+
+// patchIndex parses a list index token, which must be between 0 and max,
+// inclusive.
+func patchIndex(token string, max int) (int, error) {
+	idx, err := strconv.Atoi(token)
+	if err != nil || idx < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid index: %q", token)
+	}
+
+	if idx > max {
+		return 0, fmt.Errorf("index out of bounds: %d", idx)
+	}
+
+	return idx, nil
+}
+
+// withoutBinding returns a copy of the scope without the given binding.
+func withoutBinding(scope *Scope, key Symbol) *Scope {
+	copied := NewEmptyScope()
+	_ = scope.Each(func(k Symbol, v Value) error {
+		if k != key {
+			copied.Set(k, v)
+		}
+
+		return nil
+	})
+
+	return copied
+}
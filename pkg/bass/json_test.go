@@ -0,0 +1,163 @@
+package bass_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	. "github.com/vito/bass/pkg/basstest"
+	"github.com/vito/is"
+)
+
+func TestUnmarshalJSONDeeplyNested(t *testing.T) {
+	is := is.New(t)
+
+	// encoding/json limits nesting to 10000 levels
+	depth := 4000
+
+	payload := strings.Repeat(`{"a":[`, depth) + "1" + strings.Repeat("]}", depth)
+
+	var val bass.Value
+	is.NoErr(bass.UnmarshalJSON([]byte(payload), &val))
+
+	for i := 0; i < depth; i++ {
+		var scope *bass.Scope
+		is.NoErr(val.Decode(&scope))
+
+		list, found := scope.Get("a")
+		is.True(found)
+
+		var pair bass.Pair
+		is.NoErr(list.Decode(&pair))
+
+		val = pair.A
+	}
+
+	Equal(t, val, bass.Int(1))
+}
+
+func TestUnmarshalJSONValues(t *testing.T) {
+	is := is.New(t)
+
+	var val bass.Value
+	is.NoErr(bass.UnmarshalJSON([]byte(`{"a":[1,"two",null,true,{"b":[]},[[]]],"c":{}}`), &val))
+
+	Equal(t, val, bass.Bindings{
+		"a": bass.NewList(
+			bass.Int(1),
+			bass.String("two"),
+			bass.Null{},
+			bass.Bool(true),
+			bass.Bindings{"b": bass.Empty{}}.Scope(),
+			bass.NewList(bass.Empty{}),
+		),
+		"c": bass.NewEmptyScope(),
+	}.Scope())
+}
+
+func BenchmarkUnmarshalJSONWide(b *testing.B) {
+	entries := make([]string, 1000)
+	for i := range entries {
+		entries[i] = fmt.Sprintf(`{"name":"entry-%d","value":%d,"tags":["a","b","c"]}`, i, i)
+	}
+
+	payload := []byte("[" + strings.Join(entries, ",") + "]")
+
+	b.SetBytes(int64(len(payload)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var val bass.Value
+		if err := bass.UnmarshalJSON(payload, &val); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalJSONDeep(b *testing.B) {
+	depth := 1000
+
+	payload := []byte(strings.Repeat(`{"a":[`, depth) + "1" + strings.Repeat("]}", depth))
+
+	b.SetBytes(int64(len(payload)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var val bass.Value
+		if err := bass.UnmarshalJSON(payload, &val); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestEncodeValue(t *testing.T) {
+	for _, val := range []bass.Value{
+		bass.Int(42),
+		bass.String("<hello> & goodbye"),
+		bass.Null{},
+		bass.Empty{},
+		bass.NewList(bass.Int(1), bass.String("two"), bass.NewList(bass.Bool(true))),
+		bass.Bindings{
+			"a": bass.NewList(bass.Bindings{"b": bass.Int(1)}.Scope()),
+			"c": bass.NewEmptyScope(),
+		}.Scope(),
+		bass.Annotated{
+			Value: bass.Int(1),
+			Meta:  bass.Bindings{"doc": bass.String("one")}.Scope(),
+		},
+	} {
+		val := val
+		t.Run(val.String(), func(t *testing.T) {
+			is := is.New(t)
+
+			buf := new(bytes.Buffer)
+			is.NoErr(bass.NewValueEncoder(buf).EncodeValue(val))
+
+			var decoded bass.Value
+			is.NoErr(bass.UnmarshalJSON(buf.Bytes(), &decoded))
+
+			var expected bass.Value
+			payload, err := bass.MarshalJSON(val)
+			is.NoErr(err)
+			is.NoErr(bass.UnmarshalJSON(payload, &expected))
+
+			Equal(t, decoded, expected)
+		})
+	}
+
+	t.Run("dotted pair", func(t *testing.T) {
+		is := is.New(t)
+
+		err := bass.NewValueEncoder(new(bytes.Buffer)).EncodeValue(bass.Pair{
+			A: bass.Int(1),
+			D: bass.Int(2),
+		})
+		is.True(err != nil)
+	})
+}
+
+func BenchmarkEncodeValue(b *testing.B) {
+	vals := make([]bass.Value, 10000)
+	for i := range vals {
+		vals[i] = bass.Bindings{
+			"name": bass.String(fmt.Sprintf("entry-%d", i)),
+			"tags": bass.NewList(bass.String("a"), bass.String("b")),
+		}.Scope()
+	}
+
+	list := bass.NewList(vals...)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := bass.NewValueEncoder(io.Discard).EncodeValue(list); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
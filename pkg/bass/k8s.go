@@ -0,0 +1,232 @@
+package bass
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/vito/bass/pkg/k8s"
+)
+
+func init() {
+	Ground.MustSet("k8s-apply",
+		Func("k8s-apply", "[manifests & opts]", func(ctx context.Context, manifests Value, opts ...K8sOpts) (List, error) {
+			client, err := NewK8sClient(ctx, k8sOpts(opts))
+			if err != nil {
+				return nil, err
+			}
+
+			var objs []*Scope
+			var obj *Scope
+			if err := manifests.Decode(&obj); err == nil {
+				objs = []*Scope{obj}
+			} else if err := manifests.Decode(&objs); err != nil {
+				return nil, fmt.Errorf("k8s-apply: manifests must be a scope or a list of scopes: %w", err)
+			}
+
+			applied := make([]Value, len(objs))
+			for i, obj := range objs {
+				manifest, err := MarshalJSON(obj)
+				if err != nil {
+					return nil, err
+				}
+
+				res, err := client.Apply(ctx, manifest)
+				if err != nil {
+					return nil, err
+				}
+
+				applied[i], err = k8sScope(res)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			return NewList(applied...), nil
+		}),
+		`applies manifests to a Kubernetes cluster`,
+		`Takes a manifest or a list of manifests and applies them using server-side apply, returning the applied resources.`,
+		`Options may be given as a scope: :kubeconfig is a path or a secret containing the kubeconfig (defaulting to $KUBECONFIG or ~/.kube/config), whose users may authenticate with tokens, client certificates, or exec credential plugins, :context selects a context other than the current one, and :namespace sets the namespace for resources which don't specify one.`,
+		`=> (k8s-apply [deployment service] {:context "prod" :kubeconfig (secret :kubeconfig)})`)
+
+	Ground.MustSet("k8s-wait",
+		Func("k8s-wait", "[resource & opts]", func(ctx context.Context, resource *Scope, opts ...K8sOpts) (*Scope, error) {
+			o := k8sOpts(opts)
+
+			client, err := NewK8sClient(ctx, o)
+			if err != nil {
+				return nil, err
+			}
+
+			if o.Timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, time.Duration(o.Timeout)*time.Second)
+				defer cancel()
+			}
+
+			payload, err := MarshalJSON(resource)
+			if err != nil {
+				return nil, err
+			}
+
+			res, err := client.Wait(ctx, payload)
+			if err != nil {
+				return nil, err
+			}
+
+			return k8sScope(res)
+		}),
+		`waits for a Kubernetes resource to become ready`,
+		`Deployments, stateful sets, and daemon sets are ready once their rollout is complete. Jobs are ready once they succeed, and raise an error if they fail. Other resources are ready once they exist.`,
+		`Takes the same options as (k8s-apply), along with :timeout in seconds.`,
+		`Returns the resource as last observed.`,
+		`=> (k8s-wait deployment {:context "prod" :timeout 300})`)
+
+	Ground.MustSet("k8s-logs",
+		Func("k8s-logs", "[selector & opts]", func(ctx context.Context, selector Value, opts ...K8sOpts) (*Scope, error) {
+			o := k8sOpts(opts)
+
+			labelSelector, err := k8sSelector(selector)
+			if err != nil {
+				return nil, err
+			}
+
+			client, err := NewK8sClient(ctx, o)
+			if err != nil {
+				return nil, err
+			}
+
+			logs, err := client.Logs(ctx, labelSelector, k8s.LogOpts{
+				Container: o.Container,
+				Tail:      o.Tail,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			scope := NewEmptyScope()
+			for pod, log := range logs {
+				scope.Set(SymbolFromJSONKey(pod), String(log))
+			}
+
+			return scope, nil
+		}),
+		`fetches the logs of the pods matching a label selector`,
+		`The selector is either a string, like "app=web", or a scope of labels to match.`,
+		`Takes the same options as (k8s-apply), along with :container to select a container and :tail to limit the number of lines.`,
+		`Returns a scope mapping each pod's name to its logs.`,
+		`=> (k8s-logs {:app "web"} {:context "prod" :tail 100})`)
+}
+
+// K8sOpts are the options accepted by the k8s builtins.
+type K8sOpts struct {
+	// Kubeconfig is either a path to a kubeconfig file or a Secret containing
+	// its content.
+	Kubeconfig Value `json:"kubeconfig,omitempty"`
+
+	// Context is the kubeconfig context to use instead of the current one.
+	Context string `json:"context,omitempty"`
+
+	// Namespace is the namespace for resources which don't specify one.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Timeout is the number of seconds (k8s-wait) waits before giving up.
+	Timeout int `json:"timeout,omitempty"`
+
+	// Container selects the container to fetch logs from.
+	Container string `json:"container,omitempty"`
+
+	// Tail limits the number of lines of logs to fetch.
+	Tail int `json:"tail,omitempty"`
+}
+
+func k8sOpts(opts []K8sOpts) K8sOpts {
+	if len(opts) == 0 {
+		return K8sOpts{}
+	}
+
+	return opts[0]
+}
+
+// NewK8sClient loads the kubeconfig and initializes a client for the selected
+// context, connecting through the context's NetworkConfig.
+func NewK8sClient(ctx context.Context, opts K8sOpts) (*k8s.Client, error) {
+	config, err := loadKubeconfig(opts.Kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	network, _ := NetworkConfigFromContext(ctx)
+	httpClient, err := network.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return config.Client(httpClient, opts.Context, opts.Namespace)
+}
+
+// loadKubeconfig loads the kubeconfig from a path or a secret, defaulting to
+// $KUBECONFIG or ~/.kube/config.
+func loadKubeconfig(src Value) (*k8s.Config, error) {
+	if src == nil {
+		return k8s.LoadConfig("")
+	}
+
+	var secret Secret
+	if err := src.Decode(&secret); err == nil {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+
+		return k8s.ParseConfig(secret.Reveal(), wd)
+	}
+
+	var path string
+	if err := src.Decode(&path); err != nil {
+		return nil, fmt.Errorf("kubeconfig must be a path or a secret: %w", err)
+	}
+
+	return k8s.LoadConfig(path)
+}
+
+// k8sScope decodes a JSON resource returned by the API server.
+func k8sScope(payload []byte) (*Scope, error) {
+	var scope *Scope
+	if err := NewDecoder(bytes.NewReader(payload)).Decode(&scope); err != nil {
+		return nil, err
+	}
+
+	return scope, nil
+}
+
+func k8sSelector(selector Value) (string, error) {
+	var str string
+	if err := selector.Decode(&str); err == nil {
+		return str, nil
+	}
+
+	var labels *Scope
+	if err := selector.Decode(&labels); err != nil {
+		return "", fmt.Errorf("selector must be a string or a scope: %w", err)
+	}
+
+	var reqs []string
+	err := labels.Each(func(k Symbol, v Value) error {
+		var val string
+		if err := v.Decode(&val); err != nil {
+			return err
+		}
+
+		reqs = append(reqs, k.JSONKey()+"="+val)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("selector: %w", err)
+	}
+
+	return strings.Join(reqs, ","), nil
+}
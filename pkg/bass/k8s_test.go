@@ -0,0 +1,152 @@
+package bass_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vito/bass/pkg/bass"
+	. "github.com/vito/bass/pkg/basstest"
+	"github.com/vito/bass/pkg/k8s"
+	"github.com/vito/is"
+)
+
+func TestK8s(t *testing.T) {
+	is := is.New(t)
+
+	oldInterval := k8s.PollInterval
+	k8s.PollInterval = time.Millisecond
+	defer func() { k8s.PollInterval = oldInterval }()
+
+	var applied map[string]any
+	var polls int
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprintln(w, `{"message":"bad token"}`)
+			return
+		}
+
+		const deployment = "/apis/apps/v1/namespaces/staging/deployments/web"
+
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/apis/apps/v1":
+			fmt.Fprintln(w, `{"resources":[
+				{"name":"deployments","kind":"Deployment","namespaced":true},
+				{"name":"deployments/scale","kind":"Scale","namespaced":true}
+			]}`)
+
+		case r.Method == "PATCH" && r.URL.Path == deployment:
+			is.Equal(r.Header.Get("Content-Type"), "application/apply-patch+yaml")
+			is.Equal(r.URL.Query().Get("fieldManager"), "bass")
+
+			payload, err := io.ReadAll(r.Body)
+			is.NoErr(err)
+			is.NoErr(json.Unmarshal(payload, &applied))
+
+			applied["metadata"].(map[string]any)["generation"] = 2
+			json.NewEncoder(w).Encode(applied)
+
+		case r.Method == "GET" && r.URL.Path == deployment:
+			polls++
+
+			status := map[string]any{"observedGeneration": 1}
+			if polls > 1 {
+				status = map[string]any{
+					"observedGeneration": 2,
+					"updatedReplicas":    2,
+					"availableReplicas":  2,
+				}
+			}
+
+			applied["status"] = status
+			json.NewEncoder(w).Encode(applied)
+
+		case r.Method == "GET" && r.URL.Path == "/api/v1/namespaces/staging/pods":
+			is.Equal(r.URL.Query().Get("labelSelector"), "app=web")
+			fmt.Fprintln(w, `{"items":[{"metadata":{"name":"web-1"}}]}`)
+
+		case r.Method == "GET" && r.URL.Path == "/api/v1/namespaces/staging/pods/web-1/log":
+			is.Equal(r.URL.Query().Get("tailLines"), "10")
+			fmt.Fprint(w, "listening\n")
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"message":"%s %s not found"}`, r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	is.NoErr(os.WriteFile(kubeconfig, []byte(`
+current-context: elsewhere
+clusters:
+- name: test
+  cluster:
+    server: `+srv.URL+`
+    certificate-authority-data: `+base64.StdEncoding.EncodeToString(ca)+`
+users:
+- name: test
+  user:
+    token: s3cr3t
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+    namespace: staging
+`), 0600))
+
+	scope := bass.NewStandardScope()
+	scope.Set("opts", bass.Bindings{
+		"kubeconfig": bass.String(kubeconfig),
+		"context":    bass.String("test"),
+	}.Scope())
+
+	res, err := bass.EvalFSFile(context.Background(), scope, bass.NewInMemoryFile("test", `
+		(def deployment
+			{:apiVersion "apps/v1"
+			 :kind "Deployment"
+			 :metadata {:name "web"}
+			 :spec {:replicas 2}})
+
+		(def [applied] (k8s-apply [deployment] opts))
+		(def ready (k8s-wait applied opts))
+
+		[applied:metadata:generation
+		 ready:status:availableReplicas
+		 (k8s-logs {:app "web"} (assoc opts :tail 10))]
+	`))
+	is.NoErr(err)
+	Equal(t, res, bass.NewList(
+		bass.Int(2),
+		bass.Int(2),
+		bass.Bindings{"web-1": bass.String("listening\n")}.Scope(),
+	))
+	is.Equal(polls, 2)
+
+	_, err = bass.EvalFSFile(context.Background(), scope, bass.NewInMemoryFile("test", `
+		(k8s-apply {:apiVersion "v1" :kind "ConfigMap" :metadata {:name "cfg"}} opts)
+	`))
+	is.True(err != nil)
+	is.True(strings.Contains(err.Error(), "discover v1: 404 Not Found: GET /api/v1 not found"))
+
+	_, err = bass.EvalFSFile(context.Background(), scope, bass.NewInMemoryFile("test", `
+		(k8s-apply [] {:kubeconfig opts:kubeconfig :context "bogus"})
+	`))
+	is.True(err != nil)
+	is.True(strings.Contains(err.Error(), `unknown context: "bogus"`))
+}
@@ -0,0 +1,163 @@
+package bass
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Promise is a form whose evaluation is delayed until it is forced.
+//
+// A promise is evaluated at most once; forcing it again returns the same
+// value.
+type Promise struct {
+	form  Value
+	scope *Scope
+
+	forced bool
+	val    Value
+	mu     sync.Mutex
+}
+
+var _ Value = (*Promise)(nil)
+
+// Delay returns a promise to evaluate the form in the scope.
+func Delay(form Value, scope *Scope) *Promise {
+	return &Promise{
+		form:  form,
+		scope: scope,
+	}
+}
+
+// Force evaluates the promise's form, or returns its value if it has already
+// been forced.
+func (value *Promise) Force(ctx context.Context, cont Cont) ReadyCont {
+	value.mu.Lock()
+	if value.forced {
+		val := value.val
+		value.mu.Unlock()
+		return cont.Call(val, nil)
+	}
+
+	form, scope := value.form, value.scope
+	value.mu.Unlock()
+
+	// evaluate outside of the lock; a promise may force itself while forced,
+	// in which case the first result to arrive wins
+	return form.Eval(ctx, scope, Continue(func(res Value) Value {
+		value.mu.Lock()
+		if !value.forced {
+			value.forced = true
+			value.val = res
+
+			// release the form and scope for garbage collection
+			value.form = nil
+			value.scope = nil
+		}
+
+		val := value.val
+		value.mu.Unlock()
+
+		return cont.Call(val, nil)
+	}))
+}
+
+func (value *Promise) String() string {
+	return "<promise>"
+}
+
+func (value *Promise) Equal(other Value) bool {
+	var o *Promise
+	return other.Decode(&o) == nil && value == o
+}
+
+func (value *Promise) Decode(dest any) error {
+	switch x := dest.(type) {
+	case **Promise:
+		*x = value
+		return nil
+	case *Value:
+		*x = value
+		return nil
+	default:
+		return DecodeError{
+			Source:      value,
+			Destination: dest,
+		}
+	}
+}
+
+func (value *Promise) MarshalJSON() ([]byte, error) {
+	return nil, EncodeError{value}
+}
+
+func (value *Promise) Eval(_ context.Context, _ *Scope, cont Cont) ReadyCont {
+	return cont.Call(value, nil)
+}
+
+// LazyCons is a pair whose rest is a promise, allowing for sequences which are
+// only computed as they are consumed, and which may be infinite.
+//
+// Forcing the rest yields another LazyCons, or an empty list at the end of the
+// sequence.
+type LazyCons struct {
+	A Value
+	D *Promise
+}
+
+var _ List = LazyCons{}
+
+func (value LazyCons) First() Value {
+	return value.A
+}
+
+func (value LazyCons) Rest() Value {
+	return value.D
+}
+
+func (value LazyCons) String() string {
+	return fmt.Sprintf("(%s & %s)", value.A, value.D)
+}
+
+func (value LazyCons) Equal(other Value) bool {
+	var o LazyCons
+	return other.Decode(&o) == nil &&
+		value.A.Equal(o.A) &&
+		value.D.Equal(o.D)
+}
+
+func (value LazyCons) Decode(dest any) error {
+	switch x := dest.(type) {
+	case *LazyCons:
+		*x = value
+		return nil
+	case *List:
+		*x = value
+		return nil
+	case *Value:
+		*x = value
+		return nil
+	default:
+		return DecodeError{
+			Source:      value,
+			Destination: dest,
+		}
+	}
+}
+
+func (value LazyCons) MarshalJSON() ([]byte, error) {
+	return nil, EncodeError{value}
+}
+
+// Eval returns the value; unlike a Pair, a LazyCons is never a form.
+func (value LazyCons) Eval(_ context.Context, _ *Scope, cont Cont) ReadyCont {
+	return cont.Call(value, nil)
+}
+
+func (value LazyCons) Bind(_ context.Context, _ *Scope, cont Cont, _ Value, _ ...Annotated) ReadyCont {
+	return cont.Call(nil, CannotBindError{value})
+}
+
+func (value LazyCons) EachBinding(func(Symbol, Range) error) error {
+	return CannotBindError{value}
+}
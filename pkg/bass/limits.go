@@ -0,0 +1,119 @@
+package bass
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
+// Limits bounds the resources consumed by evaluation, so that untrusted
+// scripts can be run safely.
+//
+// A zero value for any field means no limit.
+type Limits struct {
+	// MaxSteps is the maximum number of evaluation steps, i.e. continuations
+	// processed by the trampoline.
+	MaxSteps int64
+
+	// MaxDepth is the maximum depth of nested combiner calls.
+	//
+	// Tail calls count towards the depth too, so that unbounded recursion
+	// fails rather than running forever.
+	MaxDepth int
+
+	// MaxValues is the maximum number of values allocated, approximated by
+	// the number of builtin results and scopes created for combiner calls.
+	MaxValues int64
+}
+
+// Kinds of limits which may be exceeded.
+const (
+	LimitSteps  = "steps"
+	LimitDepth  = "depth"
+	LimitValues = "values"
+)
+
+// LimitError is returned when evaluation exceeds one of its Limits.
+type LimitError struct {
+	// Limit is the kind of limit that was exceeded.
+	Limit string
+
+	// Max is the configured maximum.
+	Max int64
+}
+
+func (err LimitError) Error() string {
+	return fmt.Sprintf("evaluation limit exceeded: %s (max %d)", err.Limit, err.Max)
+}
+
+type limitsKey struct{}
+
+// depthKey is the depth of combiner calls, carried on the context passed to
+// each call's body.
+type depthKey struct{}
+
+// limiter tracks resource usage against Limits for a single evaluation.
+type limiter struct {
+	Limits
+
+	steps  int64
+	values int64
+}
+
+// WithLimits bounds evaluation within the returned context by the given
+// limits. Usage is counted from zero, shared by all evaluation using the
+// context.
+func WithLimits(ctx context.Context, limits Limits) context.Context {
+	return context.WithValue(ctx, limitsKey{}, &limiter{Limits: limits})
+}
+
+// LimitsFromContext returns the limits configured in the context, if any.
+func LimitsFromContext(ctx context.Context) (Limits, bool) {
+	lim := limiterFromContext(ctx)
+	if lim == nil {
+		return Limits{}, false
+	}
+
+	return lim.Limits, true
+}
+
+func limiterFromContext(ctx context.Context) *limiter {
+	lim, _ := ctx.Value(limitsKey{}).(*limiter)
+	return lim
+}
+
+func (lim *limiter) step() error {
+	if lim.MaxSteps > 0 && atomic.AddInt64(&lim.steps, 1) > lim.MaxSteps {
+		return LimitError{Limit: LimitSteps, Max: lim.MaxSteps}
+	}
+
+	return nil
+}
+
+func (lim *limiter) alloc() error {
+	if lim.MaxValues > 0 && atomic.AddInt64(&lim.values, 1) > lim.MaxValues {
+		return LimitError{Limit: LimitValues, Max: lim.MaxValues}
+	}
+
+	return nil
+}
+
+// enter counts a combiner call, returning the context for its body.
+func (lim *limiter) enter(ctx context.Context) (context.Context, error) {
+	if err := lim.alloc(); err != nil {
+		return nil, err
+	}
+
+	if lim.MaxDepth <= 0 {
+		return ctx, nil
+	}
+
+	depth, _ := ctx.Value(depthKey{}).(int)
+	depth++
+
+	if depth > lim.MaxDepth {
+		return nil, LimitError{Limit: LimitDepth, Max: int64(lim.MaxDepth)}
+	}
+
+	return context.WithValue(ctx, depthKey{}, depth), nil
+}
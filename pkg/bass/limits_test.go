@@ -0,0 +1,83 @@
+package bass_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/is"
+)
+
+func TestLimits(t *testing.T) {
+	for _, test := range []struct {
+		Name   string
+		Limits bass.Limits
+		Src    string
+		Limit  string
+	}{
+		{
+			Name:   "steps",
+			Limits: bass.Limits{MaxSteps: 1000},
+			Src:    `(defn loop [n] (loop (+ n 1))) (loop 0)`,
+			Limit:  bass.LimitSteps,
+		},
+		{
+			Name:   "depth",
+			Limits: bass.Limits{MaxDepth: 10},
+			Src:    `((fn [] ((fn [] ((fn [] ((fn [] ((fn [] ((fn [] ((fn [] ((fn [] ((fn [] ((fn [] ((fn [] :deep))))))))))))))))))))))`,
+			Limit:  bass.LimitDepth,
+		},
+		{
+			Name:   "recursion",
+			Limits: bass.Limits{MaxDepth: 100},
+			Src:    `(defn loop [] (loop)) (loop)`,
+			Limit:  bass.LimitDepth,
+		},
+		{
+			Name:   "non-tail recursion",
+			Limits: bass.Limits{MaxDepth: 100},
+			Src:    `(defn count [n] (+ 1 (count n))) (count 0)`,
+			Limit:  bass.LimitDepth,
+		},
+		{
+			Name:   "values",
+			Limits: bass.Limits{MaxValues: 100},
+			Src:    `(defn build [acc] (build (cons 1 acc))) (build [])`,
+			Limit:  bass.LimitValues,
+		},
+	} {
+		test := test
+		t.Run(test.Name, func(t *testing.T) {
+			is := is.New(t)
+
+			// NB: a limit which is never hit would otherwise hang the test
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			ctx = bass.WithLimits(ctx, test.Limits)
+
+			_, err := bass.EvalFSFile(ctx, bass.NewStandardScope(), bass.NewInMemoryFile("test", test.Src))
+			is.True(err != nil)
+
+			var limitErr bass.LimitError
+			is.True(errors.As(err, &limitErr))
+			is.Equal(limitErr.Limit, test.Limit)
+		})
+	}
+
+	t.Run("within limits", func(t *testing.T) {
+		is := is.New(t)
+
+		ctx := bass.WithLimits(context.Background(), bass.Limits{
+			MaxSteps:  10000,
+			MaxDepth:  100,
+			MaxValues: 10000,
+		})
+
+		res, err := bass.EvalFSFile(ctx, bass.NewStandardScope(), bass.NewInMemoryFile("test", `(+ 1 2 3)`))
+		is.NoErr(err)
+		is.Equal(res, bass.Int(6))
+	})
+}
@@ -132,6 +132,7 @@ func EncodeList(list List, enc zapcore.ArrayEncoder) error {
 	return Each(list, func(v Value) error {
 		var str string
 		var num int
+		var flt float64
 		var bol bool
 		var am zapcore.ArrayMarshaler
 		var om zapcore.ObjectMarshaler
@@ -139,6 +140,8 @@ func EncodeList(list List, enc zapcore.ArrayEncoder) error {
 			enc.AppendString(str)
 		} else if v.Decode(&num) == nil {
 			enc.AppendInt(num)
+		} else if v.Decode(&flt) == nil {
+			enc.AppendFloat64(flt)
 		} else if v.Decode(&bol) == nil {
 			enc.AppendBool(bol)
 		} else if v.Decode(&am) == nil {
@@ -152,3 +155,19 @@ func EncodeList(list List, enc zapcore.ArrayEncoder) error {
 		return nil
 	})
 }
+
+// callEach calls the combiner with each value in the list, passing each value
+// and its result to cb.
+//
+// Calls are trampolined individually, so this is only suitable for
+// builtins which need to call back into Bass for each member of a list.
+func callEach(ctx context.Context, scope *Scope, comb Combiner, list List, cb func(Value, Value) error) error {
+	return Each(list, func(v Value) error {
+		res, err := Trampoline(ctx, comb.Call(ctx, NewList(v), scope, Identity))
+		if err != nil {
+			return err
+		}
+
+		return cb(v, res)
+	})
+}
@@ -0,0 +1,114 @@
+package bass
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/vito/bass/pkg/zapctx"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Ground.MustSet("with-lock",
+		Func("with-lock", "[name f]", func(ctx context.Context, name string, fn Combiner) (Value, error) {
+			unlock, err := LockerFromContext(ctx).Lock(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+
+			defer unlock()
+
+			return Trampoline(ctx, fn.Call(ctx, NewList(), NewEmptyScope(), Identity))
+		}),
+		`calls f with no arguments while holding the named lock`,
+		`Only one run may hold a lock at a time, so concurrent runs wait for each other before entering the critical section, e.g. to avoid conflicting deployments.`,
+		`Locks are held with a file lock on the local machine. When using runtimes served by a daemon (see --daemon), locks are held by the daemon instead, so that they are shared by all of its clients.`,
+		`The lock is released once f returns or errors. Returns the result of f.`,
+		`=> (with-lock "deploy" (fn [] (log "deploying") :deployed))`)
+}
+
+// LockRetryDelay is how often a lock held by another run is retried.
+var LockRetryDelay = 100 * time.Millisecond
+
+// LocksDir is the directory containing the files locked by FileLocker.
+func LocksDir() string {
+	return filepath.Join(CacheHome, "locks")
+}
+
+// Locker acquires named locks for (with-lock).
+type Locker interface {
+	// Lock blocks until the named lock is acquired or the context is
+	// canceled, returning a function which releases it.
+	Lock(ctx context.Context, name string) (func(), error)
+}
+
+// FileLocker is a Locker which locks files in a directory, serializing runs
+// on the local machine.
+type FileLocker struct {
+	Dir string
+}
+
+func (locker FileLocker) Lock(ctx context.Context, name string) (func(), error) {
+	if err := validateFileName("lock", name); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(locker.Dir, 0700); err != nil {
+		return nil, err
+	}
+
+	lock := flock.New(filepath.Join(locker.Dir, name+".lock"))
+
+	locked, err := lock.TryLock()
+	if err != nil {
+		return nil, fmt.Errorf("lock %s: %w", name, err)
+	}
+
+	if !locked {
+		zapctx.FromContext(ctx).Info("waiting for lock", zap.String("lock", name))
+
+		locked, err = lock.TryLockContext(ctx, LockRetryDelay)
+		if err != nil {
+			return nil, fmt.Errorf("lock %s: %w", name, err)
+		}
+
+		if !locked {
+			return nil, fmt.Errorf("lock %s: %w", name, ctx.Err())
+		}
+	}
+
+	if _, err := os.Stat(lock.Path()); err != nil {
+		// the lock file was removed while we were waiting, e.g. by pruning the
+		// cache, so another run may already hold a new one; lock that instead
+		_ = lock.Unlock()
+		return locker.Lock(ctx, name)
+	}
+
+	return func() {
+		if err := lock.Unlock(); err != nil {
+			zapctx.FromContext(ctx).Warn("failed to unlock", zap.String("lock", name), zap.Error(err))
+		}
+	}, nil
+}
+
+type lockerKey struct{}
+
+// WithLocker sets the Locker used by (with-lock) within the returned context.
+func WithLocker(ctx context.Context, locker Locker) context.Context {
+	return context.WithValue(ctx, lockerKey{}, locker)
+}
+
+// LockerFromContext returns the Locker set by WithLocker, or a FileLocker in
+// LocksDir.
+func LockerFromContext(ctx context.Context) Locker {
+	locker, ok := ctx.Value(lockerKey{}).(Locker)
+	if !ok {
+		return FileLocker{Dir: LocksDir()}
+	}
+
+	return locker
+}
@@ -0,0 +1,57 @@
+package bass_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/is"
+)
+
+func TestWithLock(t *testing.T) {
+	is := is.New(t)
+
+	oldHome := bass.CacheHome
+	bass.CacheHome = t.TempDir()
+	defer func() { bass.CacheHome = oldHome }()
+
+	ctx := context.Background()
+
+	res, err := bass.EvalFSFile(ctx, bass.NewStandardScope(), bass.NewInMemoryFile("test", `(with-lock "deploy" (fn [] :deployed))`))
+	is.NoErr(err)
+	is.Equal(res, bass.Symbol("deployed"))
+
+	_, err = bass.EvalFSFile(ctx, bass.NewStandardScope(), bass.NewInMemoryFile("test", `(with-lock "../deploy" (fn [] :deployed))`))
+	is.True(err != nil)
+
+	// the lock is released after an error
+	_, err = bass.EvalFSFile(ctx, bass.NewStandardScope(), bass.NewInMemoryFile("test", `(with-lock "deploy" (fn [] (error "oh no")))`))
+	is.True(err != nil)
+
+	unlock, err := bass.LockerFromContext(ctx).Lock(ctx, "deploy")
+	is.NoErr(err)
+
+	// another holder waits until the lock is released
+	waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	_, err = bass.LockerFromContext(ctx).Lock(waitCtx, "deploy")
+	is.True(errors.Is(err, context.DeadlineExceeded))
+
+	locked := make(chan error, 1)
+	go func() {
+		_, err := bass.EvalFSFile(ctx, bass.NewStandardScope(), bass.NewInMemoryFile("test", `(with-lock "deploy" (fn [] :deployed))`))
+		locked <- err
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("acquired lock while held")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	is.NoErr(<-locked)
+}
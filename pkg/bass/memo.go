@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 
 	"github.com/gofrs/flock"
@@ -22,7 +23,7 @@ type Memos interface {
 }
 
 func init() {
-	Ground.Set("recall-memo",
+	Ground.MustSet("recall-memo",
 		Func("recall-memo", "[memos thunk binding input]", func(ctx context.Context, memos Readable, thunk Thunk, binding Symbol, input Value) (Value, error) {
 			memo, err := OpenMemos(ctx, memos)
 			if err != nil {
@@ -35,6 +36,12 @@ func init() {
 			}
 
 			if found {
+				// memoized image resolutions pin the image just like resolving it
+				var ref ImageRef
+				if err := res.Decode(&ref); err == nil {
+					recordImageDep(ctx, ref)
+				}
+
 				return res, nil
 			}
 
@@ -44,7 +51,7 @@ func init() {
 		`Returns null if no result is found.`,
 		`See (memo) for the higher-level interface.`)
 
-	Ground.Set("store-memo",
+	Ground.MustSet("store-memo",
 		Func("store-memo", "[memos thunk binding input result]", func(ctx context.Context, memos Readable, thunk Thunk, binding Symbol, input Value, res Value) (Value, error) {
 			memo, err := OpenMemos(ctx, memos)
 			if err != nil {
@@ -62,6 +69,47 @@ func init() {
 		`See (memo) for the higher-level interface.`)
 }
 
+// internalMemoPrefix marks the modules under which Bass keeps its own memos,
+// such as pinned image digests, so that they can't be confused with a real
+// module.
+const internalMemoPrefix = "bass:"
+
+// internalMemos maps each internal memo module's name to whether its memos
+// are discarded when bumping, so that they are refreshed by the next run.
+var internalMemos = map[string]bool{}
+
+// internalMemo returns the module under which Bass keeps its own memos of
+// the given kind.
+//
+// Internal memos are not module thunks, so they can't be loaded by bass
+// --bump. If refresh is true, bumping discards them instead, otherwise they
+// are left as-is.
+func internalMemo(name string, refresh bool) Thunk {
+	internalMemos[name] = refresh
+
+	return Thunk{
+		Cmd: ThunkCmd{
+			Cmd: &CommandPath{internalMemoPrefix + name},
+		},
+	}
+}
+
+// InternalMemo reports whether the thunk is a module under which Bass keeps
+// its own memos, and if so whether they should be discarded when bumping.
+func InternalMemo(thunk Thunk) (refresh bool, internal bool) {
+	if thunk.Image != nil || len(thunk.Args) > 0 || thunk.Cmd.Cmd == nil {
+		return false, false
+	}
+
+	name := thunk.Cmd.Cmd.Command
+	if !strings.HasPrefix(name, internalMemoPrefix) {
+		return false, false
+	}
+
+	refresh, internal = internalMemos[strings.TrimPrefix(name, internalMemoPrefix)]
+	return refresh, internal
+}
+
 type Lockfile struct {
 	path string
 	lock *flock.Flock
@@ -0,0 +1,305 @@
+package bass
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func init() {
+	Ground.MustSet("migrate",
+		Func("migrate", "[config]", func(ctx context.Context, config Migration) (*Scope, error) {
+			return config.Run(ctx)
+		}),
+		`runs database migrations against a temporary service database`,
+		`The config is a scope containing the :db service to migrate, the :tool thunk which applies the migrations, and the :migrations directory, as a host path or thunk path.`,
+		`The :db service is configured like a service given to (with-services), and the :tool is run with its addrs, e.g. DB_ADDR, in its env. The tool should reference the migrations directory itself, typically as an arg.`,
+		`Each non-blank line printed by the tool is taken to be an applied version. Alternatively, a :protocol such as :json may be given to decode its output.`,
+		`Migrations are memoized by the digest of the migrations directory's content, so the tool only runs again when a migration changes or the tool or database is changed.`,
+		`Returns a scope containing the :digest of the migrations and the applied :versions.`,
+		`=> (def db (-> ($ postgres) (with-port :pg 5432)))`,
+		`=> (migrate {:db db :tool ($ sh -c "migrate -path $0 -database postgres://$DB_ADDR/test up" *dir*/migrations/) :migrations *dir*/migrations/})`)
+}
+
+// Migration configures a migration tool to run against a service database.
+type Migration struct {
+	// DB is the service to migrate, either a thunk or a scope as accepted by
+	// WithServices.
+	DB Value `json:"db"`
+
+	// Tool is the thunk which applies the migrations.
+	Tool Thunk `json:"tool"`
+
+	// Migrations is the directory containing the migrations, as a host path
+	// or thunk path.
+	Migrations Value `json:"migrations"`
+
+	// Protocol is used to decode the applied versions from the tool's output.
+	// By default, each non-blank line is a version.
+	Protocol Symbol `json:"protocol,omitempty"`
+
+	// Deadline is the number of seconds that the database's health check may
+	// take to pass.
+	Deadline int `json:"deadline,omitempty"`
+}
+
+// MigrationsDir is the directory where the versions applied by (migrate) are
+// memoized.
+func MigrationsDir() string {
+	return filepath.Join(CacheHome, "migrations")
+}
+
+// MigrationsPath returns the path to the file which memoizes the versions
+// applied by the migrated thunk for the given migrations digest.
+func MigrationsPath(thunkHash, digest string) string {
+	return filepath.Join(MigrationsDir(), thunkHash, strings.TrimPrefix(digest, "sha256:")+".json")
+}
+
+// Run runs the migration tool unless the migrations have already been
+// applied by the same tool and database, returning the digest of the
+// migrations and the applied versions.
+func (migration Migration) Run(ctx context.Context) (*Scope, error) {
+	digest, err := MigrationsDigest(ctx, migration.Migrations)
+	if err != nil {
+		return nil, fmt.Errorf("digest migrations: %w", err)
+	}
+
+	thunk, err := migration.Tool.WithServices(
+		Bindings{"db": migration.DB}.Scope(),
+		ServicesOpts{Deadline: migration.Deadline},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := thunk.Hash()
+	if err != nil {
+		return nil, err
+	}
+
+	memoPath := MigrationsPath(hash, digest)
+
+	versions, err := loadMigrations(memoPath)
+	if err != nil {
+		return nil, fmt.Errorf("load memoized migrations: %w", err)
+	}
+
+	if versions == nil {
+		buf := new(bytes.Buffer)
+		if err := thunk.Read(ctx, buf); err != nil {
+			return nil, err
+		}
+
+		versions, err = migration.versions(ctx, buf)
+		if err != nil {
+			return nil, fmt.Errorf("decode versions: %w", err)
+		}
+
+		if err := saveMigrations(memoPath, versions); err != nil {
+			return nil, fmt.Errorf("memoize migrations: %w", err)
+		}
+	}
+
+	return Bindings{
+		"digest":   String(digest),
+		"versions": versions,
+	}.Scope(), nil
+}
+
+func (migration Migration) versions(ctx context.Context, out io.Reader) (List, error) {
+	if migration.Protocol == "" {
+		var versions []Value
+
+		scanner := bufio.NewScanner(out)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				versions = append(versions, String(line))
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+
+		return NewList(versions...), nil
+	}
+
+	sink := NewInMemorySink()
+	if err := DecodeProto(ctx, migration.Protocol, sink, out); err != nil {
+		return nil, err
+	}
+
+	return NewList(sink.Values...), nil
+}
+
+// MigrationsDigest returns the SHA256 digest of the content of a migrations
+// directory, given as a host path or thunk path.
+//
+// Each file's path relative to the directory is included in the digest, so
+// renaming a migration changes the digest too.
+func MigrationsDigest(ctx context.Context, dir Value) (string, error) {
+	var files map[string][]byte
+	var err error
+
+	var hostPath HostPath
+	var thunkPath ThunkPath
+	if dir.Decode(&hostPath) == nil {
+		files, err = hostPathDigests(hostPath)
+	} else if dir.Decode(&thunkPath) == nil {
+		files, err = thunkPathDigests(ctx, thunkPath)
+	} else {
+		return "", fmt.Errorf("migrations must be a host path or thunk path: %s", dir)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(h, "%s\x00", name)
+		h.Write(files[name])
+	}
+
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hostPathDigests(dir HostPath) (map[string][]byte, error) {
+	root, err := dir.checkEscape()
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{}
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+
+		defer f.Close()
+
+		files[filepath.ToSlash(rel)], err = fileDigest(f)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+func thunkPathDigests(ctx context.Context, dir ThunkPath) (map[string][]byte, error) {
+	platform := dir.Thunk.Platform()
+	if platform == nil {
+		return nil, fmt.Errorf("cannot export bass thunk path: %s", dir)
+	}
+
+	runtime, err := RuntimeFromContext(ctx, *platform)
+	if err != nil {
+		return nil, err
+	}
+
+	r, w := io.Pipe()
+
+	go func() {
+		w.CloseWithError(runtime.ExportPath(ctx, w, dir))
+	}()
+
+	defer r.Close()
+
+	files := map[string][]byte{}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, err
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		files[strings.TrimPrefix(hdr.Name, "./")], err = fileDigest(tr)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+func fileDigest(r io.Reader) ([]byte, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+
+	return h.Sum(nil), nil
+}
+
+func loadMigrations(path string) (List, error) {
+	payload, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var versions List
+	if err := UnmarshalJSON(payload, &versions); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+func saveMigrations(path string, versions List) error {
+	payload, err := MarshalJSON(versions)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, payload, 0600)
+}
@@ -0,0 +1,110 @@
+package bass_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/vito/bass/pkg/bass"
+	. "github.com/vito/bass/pkg/basstest"
+	"github.com/vito/bass/pkg/runtimes/fake"
+	"github.com/vito/is"
+)
+
+func TestMigrate(t *testing.T) {
+	is := is.New(t)
+
+	oldHome := bass.CacheHome
+	bass.CacheHome = t.TempDir()
+	defer func() { bass.CacheHome = oldHome }()
+
+	runtime := fake.NewRuntime()
+	runtime.Stub(func(thunk bass.Thunk) bool {
+		return strings.HasPrefix(thunk.Cmdline(), "migrate ")
+	}, fake.Result{
+		Stdout: []byte("1_users\n\n2_posts\n"),
+	})
+	runtime.StubCmdline("generate", fake.Result{
+		Files: fstest.MapFS{
+			"migrations/1_users.sql": {Data: []byte("create table users;")},
+		},
+	})
+
+	ctx := fake.WithRuntime(context.Background(), runtime)
+
+	migrationsDir := t.TempDir()
+	is.NoErr(os.WriteFile(filepath.Join(migrationsDir, "1_users.sql"), []byte("create table users;"), 0644))
+
+	scope := bass.NewStandardScope()
+	scope.Set("migrations", bass.NewHostDir(migrationsDir))
+
+	migrate := func() bass.Value {
+		res, err := bass.EvalFSFile(ctx, scope, bass.NewInMemoryFile("test", `
+			(def image {:platform {:os "linux"} :repository "alpine" :tag "latest"})
+			(def db (-> (from image ($ postgres)) (with-port :pg 5432)))
+			(migrate {:db db
+			          :tool (from image ($ migrate -path migrations -database "$DB_ADDR" up))
+			          :migrations migrations})
+		`))
+		is.NoErr(err)
+		return res
+	}
+
+	countRuns := func() int {
+		var count int
+		for _, thunk := range runtime.Runs() {
+			if strings.HasPrefix(thunk.Cmdline(), "migrate ") {
+				count++
+			}
+		}
+
+		return count
+	}
+
+	res := migrate()
+	is.Equal(countRuns(), 1)
+
+	var result struct {
+		Digest   string   `json:"digest"`
+		Versions []string `json:"versions"`
+	}
+	is.NoErr(res.Decode(&result))
+	is.True(strings.HasPrefix(result.Digest, "sha256:"))
+	is.Equal(result.Versions, []string{"1_users", "2_posts"})
+
+	run, found := runtime.Runs()[0].Env.Get("DB_ADDR")
+	is.True(found)
+	var addr bass.ThunkAddr
+	is.NoErr(run.Decode(&addr))
+
+	// memoized by the content of the migrations
+	Equal(t, migrate(), res)
+	is.Equal(countRuns(), 1)
+
+	is.NoErr(os.WriteFile(filepath.Join(migrationsDir, "2_posts.sql"), []byte("create table posts;"), 0644))
+
+	changed := migrate()
+	is.Equal(countRuns(), 2)
+
+	var changedResult struct {
+		Digest string `json:"digest"`
+	}
+	is.NoErr(changed.Decode(&changedResult))
+	is.True(changedResult.Digest != result.Digest)
+
+	// thunk paths are digested by their content too
+	is.NoErr(os.Remove(filepath.Join(migrationsDir, "2_posts.sql")))
+
+	generated, err := bass.EvalFSFile(ctx, scope, bass.NewInMemoryFile("test", `
+		(def image {:platform {:os "linux"} :repository "alpine" :tag "latest"})
+		(subpath (from image ($ generate)) ./migrations/)
+	`))
+	is.NoErr(err)
+
+	thunkDigest, err := bass.MigrationsDigest(ctx, generated)
+	is.NoErr(err)
+	is.Equal(thunkDigest, result.Digest)
+}
@@ -0,0 +1,47 @@
+package bass
+
+import (
+	"context"
+	"fmt"
+)
+
+// MountCheck configures what a runtime does when a thunk's command never
+// accesses one of its mounts.
+//
+// Mounts are checked by tracing file access while the command runs, which
+// only some runtimes support, and only for thunks that actually run rather
+// than hit cache. Unused mounts are a sign of an oversized build context:
+// they bust the cache when they change without affecting the result.
+type MountCheck string
+
+const (
+	// MountCheckWarn logs a warning for each unused mount.
+	MountCheckWarn MountCheck = "warn"
+
+	// MountCheckError fails the thunk if any mount is unused.
+	MountCheckError MountCheck = "error"
+)
+
+// ParseMountCheck parses a MountCheck from its name.
+func ParseMountCheck(str string) (MountCheck, error) {
+	switch check := MountCheck(str); check {
+	case MountCheckWarn, MountCheckError:
+		return check, nil
+	default:
+		return "", fmt.Errorf("unknown mount check %q: must be %s or %s", str, MountCheckWarn, MountCheckError)
+	}
+}
+
+type mountCheckKey struct{}
+
+// WithMountCheck enables checking for unused mounts within the returned
+// context.
+func WithMountCheck(ctx context.Context, check MountCheck) context.Context {
+	return context.WithValue(ctx, mountCheckKey{}, check)
+}
+
+// MountCheckFromContext returns the check set by WithMountCheck, if any.
+func MountCheckFromContext(ctx context.Context) (MountCheck, bool) {
+	check, ok := ctx.Value(mountCheckKey{}).(MountCheck)
+	return check, ok
+}
@@ -0,0 +1,93 @@
+package bass_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/is"
+)
+
+func TestParseMountCheck(t *testing.T) {
+	is := is.New(t)
+
+	check, err := bass.ParseMountCheck("warn")
+	is.NoErr(err)
+	is.Equal(check, bass.MountCheckWarn)
+
+	check, err = bass.ParseMountCheck("error")
+	is.NoErr(err)
+	is.Equal(check, bass.MountCheckError)
+
+	_, err = bass.ParseMountCheck("ignore")
+	is.True(err != nil)
+}
+
+func TestMountCheckContext(t *testing.T) {
+	is := is.New(t)
+
+	ctx := context.Background()
+
+	_, ok := bass.MountCheckFromContext(ctx)
+	is.True(!ok)
+
+	check, ok := bass.MountCheckFromContext(bass.WithMountCheck(ctx, bass.MountCheckError))
+	is.True(ok)
+	is.Equal(check, bass.MountCheckError)
+}
+
+func TestAccessTraceContext(t *testing.T) {
+	is := is.New(t)
+
+	ctx := context.Background()
+	_, ok := bass.AccessTraceFromContext(ctx)
+	is.True(!ok)
+
+	trace := bass.NewAccessTrace()
+	got, ok := bass.AccessTraceFromContext(bass.WithAccessTrace(ctx, trace))
+	is.True(ok)
+	is.True(got == trace)
+}
+
+func TestAccessTraceReport(t *testing.T) {
+	is := is.New(t)
+
+	trace := bass.NewAccessTrace()
+
+	// reads are merged across thunks using the same context dir
+	trace.Record("/src", map[string]bool{
+		"go.mod":        true,
+		"README.md":     false,
+		"pkg/a/a.go":    true,
+		"pkg/a/b.go":    false,
+		"pkg/b/b.go":    false,
+		"docs/index.md": false,
+	})
+
+	trace.Record("/src", map[string]bool{
+		"pkg/a/b.go": true,
+		"pkg/b/b.go": false,
+	})
+
+	trace.Record("/all", map[string]bool{
+		"foo": true,
+	})
+
+	// no suggestion is made if any thunk using the context wasn't traced
+	trace.Record("/partial", map[string]bool{
+		"foo": true,
+		"bar": false,
+	})
+	trace.Incomplete("/partial")
+
+	report := new(bytes.Buffer)
+	trace.Report(report)
+	is.Equal(report.String(), `bass: context /all: all 1 files read
+bass: context /partial: some thunks using it were not traced, so no .bassignore is suggested
+bass: context /src: read 3 of 6 files; suggested .bassignore:
+  *
+  !go.mod
+  !pkg/a
+`)
+}
@@ -0,0 +1,237 @@
+package bass
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// NetworkConfig configures outbound network calls made by Bass itself, such
+// as pulling images, dialing SSH runners, and HTTP requests.
+type NetworkConfig struct {
+	// HTTPProxy is the proxy for plain HTTP requests.
+	HTTPProxy string `json:"http_proxy,omitempty"`
+
+	// HTTPSProxy is the proxy for HTTPS requests and other TCP connections,
+	// which are tunneled through it using CONNECT.
+	HTTPSProxy string `json:"https_proxy,omitempty"`
+
+	// NoProxy is a comma-separated list of hosts, domains, and CIDRs which
+	// are dialed directly, in the same format as $NO_PROXY.
+	NoProxy string `json:"no_proxy,omitempty"`
+
+	// CACerts are paths to PEM-encoded certificates to trust in addition to
+	// the system's.
+	CACerts []string `json:"ca_certs,omitempty"`
+}
+
+// NetworkConfigFromEnv returns the configuration set by $HTTP_PROXY,
+// $HTTPS_PROXY, and $NO_PROXY (or their lowercase forms).
+func NetworkConfigFromEnv() NetworkConfig {
+	env := httpproxy.FromEnvironment()
+	return NetworkConfig{
+		HTTPProxy:  env.HTTPProxy,
+		HTTPSProxy: env.HTTPSProxy,
+		NoProxy:    env.NoProxy,
+	}
+}
+
+// Merge returns the config with any fields set by the other config taking
+// precedence. CA certificates from both are trusted.
+func (config NetworkConfig) Merge(other NetworkConfig) NetworkConfig {
+	if other.HTTPProxy != "" {
+		config.HTTPProxy = other.HTTPProxy
+	}
+
+	if other.HTTPSProxy != "" {
+		config.HTTPSProxy = other.HTTPSProxy
+	}
+
+	if other.NoProxy != "" {
+		config.NoProxy = other.NoProxy
+	}
+
+	if len(other.CACerts) > 0 {
+		certs := make([]string, 0, len(config.CACerts)+len(other.CACerts))
+		certs = append(certs, config.CACerts...)
+		certs = append(certs, other.CACerts...)
+		config.CACerts = certs
+	}
+
+	return config
+}
+
+// Proxy returns the proxy to use for the given URL, or nil if it should be
+// requested directly.
+func (config NetworkConfig) Proxy(u *url.URL) (*url.URL, error) {
+	return (&httpproxy.Config{
+		HTTPProxy:  config.HTTPProxy,
+		HTTPSProxy: config.HTTPSProxy,
+		NoProxy:    config.NoProxy,
+	}).ProxyFunc()(u)
+}
+
+// CertPool returns the system's certificate pool with the configured CA
+// certificates added.
+func (config NetworkConfig) CertPool() (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	for _, path := range config.CACerts {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read ca cert: %w", err)
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", path)
+		}
+	}
+
+	return pool, nil
+}
+
+// TLSConfig returns a TLS config which trusts the configured CA certificates.
+func (config NetworkConfig) TLSConfig() (*tls.Config, error) {
+	pool, err := config.CertPool()
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Config{RootCAs: pool}, nil
+}
+
+// HTTPClient returns a HTTP client which uses the configured proxies and
+// trusts the configured CA certificates.
+func (config NetworkConfig) HTTPClient() (*http.Client, error) {
+	tlsConfig, err := config.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		return config.Proxy(req.URL)
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// DialContext opens a TCP connection to the address, tunneling it through
+// the HTTPS proxy using CONNECT unless the address matches NoProxy.
+func (config NetworkConfig) DialContext(ctx context.Context, dialer *net.Dialer, addr string) (net.Conn, error) {
+	proxy, err := config.Proxy(&url.URL{Scheme: "https", Host: addr})
+	if err != nil {
+		return nil, err
+	}
+
+	if proxy == nil {
+		return dialer.DialContext(ctx, "tcp", addr)
+	}
+
+	proxyAddr := proxy.Host
+	if proxy.Port() == "" {
+		if proxy.Scheme == "https" {
+			proxyAddr = net.JoinHostPort(proxy.Hostname(), "443")
+		} else {
+			proxyAddr = net.JoinHostPort(proxy.Hostname(), "80")
+		}
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, fmt.Errorf("dial proxy: %w", err)
+	}
+
+	if proxy.Scheme == "https" {
+		tlsConfig, err := config.TLSConfig()
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+
+		tlsConfig.ServerName = proxy.Hostname()
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+
+	if proxy.User != nil {
+		pass, _ := proxy.User.Password()
+		creds := proxy.User.Username() + ":" + pass
+		req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(creds)))
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy connect: %w", err)
+	}
+
+	br := bufio.NewReader(conn)
+	res, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("proxy connect: %w", err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy connect: %s", res.Status)
+	}
+
+	if br.Buffered() > 0 {
+		// the server spoke first, e.g. with a SSH banner
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+
+	return conn, nil
+}
+
+// bufferedConn is a connection whose first bytes were already read into a
+// buffer.
+type bufferedConn struct {
+	net.Conn
+
+	r *bufio.Reader
+}
+
+func (conn *bufferedConn) Read(p []byte) (int, error) {
+	return conn.r.Read(p)
+}
+
+type networkConfigKey struct{}
+
+// WithNetworkConfig sets the network config used within the returned context.
+func WithNetworkConfig(ctx context.Context, config NetworkConfig) context.Context {
+	return context.WithValue(ctx, networkConfigKey{}, config)
+}
+
+// NetworkConfigFromContext returns the network config set by
+// WithNetworkConfig.
+func NetworkConfigFromContext(ctx context.Context) (NetworkConfig, bool) {
+	config, ok := ctx.Value(networkConfigKey{}).(NetworkConfig)
+	return config, ok
+}
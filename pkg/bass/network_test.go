@@ -0,0 +1,151 @@
+package bass_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/is"
+)
+
+func TestNetworkConfigMerge(t *testing.T) {
+	is := is.New(t)
+
+	base := bass.NetworkConfig{
+		HTTPProxy:  "http://env-proxy",
+		HTTPSProxy: "http://env-proxy",
+		NoProxy:    "localhost",
+		CACerts:    []string{"a.crt"},
+	}
+
+	merged := base.Merge(bass.NetworkConfig{
+		HTTPSProxy: "http://flag-proxy",
+		CACerts:    []string{"b.crt"},
+	})
+
+	is.Equal(merged, bass.NetworkConfig{
+		HTTPProxy:  "http://env-proxy",
+		HTTPSProxy: "http://flag-proxy",
+		NoProxy:    "localhost",
+		CACerts:    []string{"a.crt", "b.crt"},
+	})
+
+	// the original is left alone
+	is.Equal(base.CACerts, []string{"a.crt"})
+}
+
+func TestNetworkConfigProxy(t *testing.T) {
+	is := is.New(t)
+
+	config := bass.NetworkConfig{
+		HTTPSProxy: "http://proxy.example.com:3128",
+		NoProxy:    "internal.example.com,10.0.0.0/8",
+	}
+
+	proxy, err := config.Proxy(&url.URL{Scheme: "https", Host: "registry-1.docker.io"})
+	is.NoErr(err)
+	is.Equal(proxy.String(), "http://proxy.example.com:3128")
+
+	proxy, err = config.Proxy(&url.URL{Scheme: "https", Host: "git.internal.example.com"})
+	is.NoErr(err)
+	is.Equal(proxy, nil)
+
+	proxy, err = config.Proxy(&url.URL{Scheme: "https", Host: "10.1.2.3:443"})
+	is.NoErr(err)
+	is.Equal(proxy, nil)
+
+	proxy, err = config.Proxy(&url.URL{Scheme: "http", Host: "example.com"})
+	is.NoErr(err)
+	is.Equal(proxy, nil)
+}
+
+func TestNetworkConfigCertPool(t *testing.T) {
+	is := is.New(t)
+
+	_, err := bass.NetworkConfig{
+		CACerts: []string{filepath.Join(t.TempDir(), "missing.crt")},
+	}.CertPool()
+	is.True(err != nil)
+
+	bogus := filepath.Join(t.TempDir(), "bogus.crt")
+	is.NoErr(os.WriteFile(bogus, []byte("not a cert"), 0644))
+
+	_, err = bass.NetworkConfig{
+		CACerts: []string{bogus},
+	}.CertPool()
+	is.True(err != nil)
+}
+
+func TestNetworkConfigDialContext(t *testing.T) {
+	is := is.New(t)
+
+	target, err := net.Listen("tcp", "127.0.0.1:0")
+	is.NoErr(err)
+	defer target.Close()
+
+	go func() {
+		conn, err := target.Accept()
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		conn.Write([]byte("hello"))
+	}()
+
+	var connected string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		connected = r.Host
+
+		// the requested host only exists as far as the proxy is concerned
+		upstream, err := net.Dial("tcp", target.Addr().String())
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		defer upstream.Close()
+
+		w.WriteHeader(http.StatusOK)
+
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		io.Copy(conn, upstream)
+	}))
+	defer proxy.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	config := bass.NetworkConfig{
+		HTTPSProxy: proxy.URL,
+	}
+
+	conn, err := config.DialContext(ctx, &net.Dialer{}, "runner.bass.invalid:6455")
+	is.NoErr(err)
+	defer conn.Close()
+
+	payload, err := io.ReadAll(conn)
+	is.NoErr(err)
+	is.Equal(string(payload), "hello")
+	is.Equal(connected, "runner.bass.invalid:6455")
+}
@@ -0,0 +1,330 @@
+package bass
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Number is a numeric value: an Int, a BigInt, or a Float.
+//
+// Arithmetic on numbers of different types promotes the result to the more
+// general type. Integers are promoted to a BigInt when the result would
+// overflow an Int, and to a Float when the other operand is a Float.
+type Number interface {
+	Value
+
+	// Float64 returns the number as a float64, which may lose precision.
+	Float64() float64
+}
+
+var _ Number = Int(0)
+var _ Number = BigInt{}
+var _ Number = Float(0)
+
+// AddNumbers returns the sum of a and b.
+func AddNumbers(a, b Number) Number {
+	if x, y, ok := intPair(a, b); ok {
+		sum := x + y
+		if (y > 0 && sum > x) || (y <= 0 && sum <= x) {
+			return IntValue(sum)
+		}
+	}
+
+	if isFloat(a) || isFloat(b) {
+		return Float(a.Float64() + b.Float64())
+	}
+
+	return NewBigInt(new(big.Int).Add(bigInt(a), bigInt(b)))
+}
+
+// SubNumbers returns the difference of a and b.
+func SubNumbers(a, b Number) Number {
+	return AddNumbers(a, NegateNumber(b))
+}
+
+// MulNumbers returns the product of a and b.
+func MulNumbers(a, b Number) Number {
+	if x, y, ok := intPair(a, b); ok {
+		if x == 0 || y == 0 {
+			return IntValue(0)
+		}
+
+		prod := x * y
+		if prod/y == x && !(x == -1 && y == math.MinInt) && !(y == -1 && x == math.MinInt) {
+			return IntValue(prod)
+		}
+	}
+
+	if isFloat(a) || isFloat(b) {
+		return Float(a.Float64() * b.Float64())
+	}
+
+	return NewBigInt(new(big.Int).Mul(bigInt(a), bigInt(b)))
+}
+
+// DivNumbers returns the quotient of a and b.
+//
+// Dividing integers results in an integer if b divides a evenly, and a Float
+// otherwise. Dividing by zero, including 0.0, returns ErrDivideByZero.
+func DivNumbers(a, b Number) (Number, error) {
+	if b.Float64() == 0 && CompareNumbers(b, Int(0)) == 0 {
+		return nil, ErrDivideByZero
+	}
+
+	if isFloat(a) || isFloat(b) {
+		return Float(a.Float64() / b.Float64()), nil
+	}
+
+	if x, y, ok := intPair(a, b); ok && x%y == 0 {
+		if y == -1 {
+			return NegateNumber(a), nil
+		}
+
+		return IntValue(x / y), nil
+	}
+
+	quo, rem := new(big.Int).QuoRem(bigInt(a), bigInt(b), new(big.Int))
+	if rem.Sign() == 0 {
+		return NewBigInt(quo), nil
+	}
+
+	f, _ := new(big.Rat).SetFrac(bigInt(a), bigInt(b)).Float64()
+	return Float(f), nil
+}
+
+// QuotNumbers returns the quotient of a and b, truncated towards zero.
+//
+// Dividing by zero, including 0.0, returns ErrDivideByZero.
+func QuotNumbers(a, b Number) (Number, error) {
+	if CompareNumbers(b, Int(0)) == 0 {
+		return nil, ErrDivideByZero
+	}
+
+	if isFloat(a) || isFloat(b) {
+		return Float(math.Trunc(a.Float64() / b.Float64())), nil
+	}
+
+	if x, y, ok := intPair(a, b); ok {
+		if y == -1 {
+			// NB: avoid overflowing on the most negative int
+			return NegateNumber(a), nil
+		}
+
+		return IntValue(x / y), nil
+	}
+
+	return NewBigInt(new(big.Int).Quo(bigInt(a), bigInt(b))), nil
+}
+
+// RemNumbers returns the remainder of dividing a by b, which has the same
+// sign as a.
+//
+// Dividing by zero, including 0.0, returns ErrDivideByZero.
+func RemNumbers(a, b Number) (Number, error) {
+	if CompareNumbers(b, Int(0)) == 0 {
+		return nil, ErrDivideByZero
+	}
+
+	if isFloat(a) || isFloat(b) {
+		return Float(math.Mod(a.Float64(), b.Float64())), nil
+	}
+
+	if x, y, ok := intPair(a, b); ok {
+		return IntValue(x % y), nil
+	}
+
+	return NewBigInt(new(big.Int).Rem(bigInt(a), bigInt(b))), nil
+}
+
+// ModNumbers returns a modulo b, which has the same sign as b.
+//
+// Dividing by zero, including 0.0, returns ErrDivideByZero.
+func ModNumbers(a, b Number) (Number, error) {
+	rem, err := RemNumbers(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	zero := CompareNumbers(rem, Int(0))
+	if zero != 0 && (zero < 0) != (CompareNumbers(b, Int(0)) < 0) {
+		return AddNumbers(rem, b), nil
+	}
+
+	return rem, nil
+}
+
+// PowNumbers returns base raised to the power of exp.
+//
+// The result is a Float if either number is a Float. Otherwise exp must not
+// be negative, since the result would not be an integer, and it must fit in
+// an Int unless the result is trivially 0, 1, or -1.
+func PowNumbers(base, exp Number) (Number, error) {
+	if isFloat(base) || isFloat(exp) {
+		return Float(math.Pow(base.Float64(), exp.Float64())), nil
+	}
+
+	if CompareNumbers(exp, Int(0)) < 0 {
+		return nil, ErrNegativeExponent
+	}
+
+	n, ok := exp.(Int)
+	if !ok {
+		switch {
+		case CompareNumbers(base, Int(0)) == 0, CompareNumbers(base, Int(1)) == 0:
+			return base, nil
+		case CompareNumbers(base, Int(-1)) == 0:
+			return IntValue(1 - 2*int(bigInt(exp).Bit(0))), nil
+		default:
+			return nil, ErrExponentTooLarge
+		}
+	}
+
+	var res Number = Int(1)
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			res = MulNumbers(res, base)
+		}
+
+		if n > 1 {
+			base = MulNumbers(base, base)
+		}
+	}
+
+	return res, nil
+}
+
+// NegateNumber returns the negation of num.
+func NegateNumber(num Number) Number {
+	switch x := num.(type) {
+	case Int:
+		if x != math.MinInt {
+			return IntValue(-int(x))
+		}
+	case Float:
+		return -x
+	}
+
+	return NewBigInt(new(big.Int).Neg(bigInt(num)))
+}
+
+// RoundNumber rounds a Float to an integer using round, e.g. math.Floor,
+// promoting it to a BigInt if it does not fit in an Int. Integers are returned
+// as-is.
+func RoundNumber(num Number, round func(float64) float64) (Number, error) {
+	f, ok := num.(Float)
+	if !ok {
+		return num, nil
+	}
+
+	if math.IsInf(float64(f), 0) || math.IsNaN(float64(f)) {
+		return nil, ErrNotFinite
+	}
+
+	return NewBigInt(bigInt(Float(round(float64(f))))), nil
+}
+
+// CompareNumbers returns -1 if a is less than b, 1 if a is greater than b,
+// and 0 if they are equal.
+func CompareNumbers(a, b Number) int {
+	if x, y, ok := intPair(a, b); ok {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	if isFloat(a) || isFloat(b) {
+		x, y := a.Float64(), b.Float64()
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	return bigInt(a).Cmp(bigInt(b))
+}
+
+// ParseNumber parses an integer or floating-point literal, e.g. from a JSON
+// number or Bass source.
+//
+// Integers which do not fit in an Int are parsed as a BigInt. Integers may
+// have a base prefix, as accepted by strconv.ParseInt with a base of 0.
+func ParseNumber(str string) (Number, error) {
+	i, err := strconv.ParseInt(str, 0, 64)
+	if err == nil {
+		return IntValue(int(i)), nil
+	}
+
+	if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+		if bi, ok := new(big.Int).SetString(str, 0); ok {
+			return NewBigInt(bi), nil
+		}
+	}
+
+	if strings.HasPrefix(str, "0x") || strings.HasPrefix(str, "0X") || strings.ContainsAny(str, "_IiNn") {
+		// NB: ParseFloat would otherwise accept hex floats, underscores,
+		// infinities, and NaN
+		return nil, err
+	}
+
+	f, floatErr := strconv.ParseFloat(str, 64)
+	if floatErr != nil {
+		return nil, err
+	}
+
+	return Float(f), nil
+}
+
+// jsonNumber converts a JSON number to a Number, or a String if it cannot be
+// represented, e.g. because it is out of range for a Float.
+func jsonNumber(num json.Number) Value {
+	val, err := ParseNumber(num.String())
+	if err != nil {
+		return String(num.String())
+	}
+
+	return val
+}
+
+func intPair(a, b Number) (int, int, bool) {
+	x, ok := a.(Int)
+	if !ok {
+		return 0, 0, false
+	}
+
+	y, ok := b.(Int)
+	if !ok {
+		return 0, 0, false
+	}
+
+	return int(x), int(y), true
+}
+
+func isFloat(num Number) bool {
+	_, ok := num.(Float)
+	return ok
+}
+
+// bigInt converts an integer to a *big.Int, which must not be modified.
+func bigInt(num Number) *big.Int {
+	switch x := num.(type) {
+	case Int:
+		return big.NewInt(int64(x))
+	case BigInt:
+		return x.int
+	default:
+		bf, _ := big.NewFloat(num.Float64()).Int(nil)
+		return bf
+	}
+}
@@ -0,0 +1,657 @@
+package bass
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CredentialKindStorage is a credential for an object storage endpoint, keyed
+// by its host, e.g. s3.us-east-1.amazonaws.com. The username is the access
+// key ID and the secret is the secret access key.
+const CredentialKindStorage = "storage"
+
+// DefaultStorageRegion is the region used when none is configured.
+const DefaultStorageRegion = "us-east-1"
+
+// StoragePartSize is the size of each part of a multipart upload. Content
+// which fits in a single part is uploaded with a single request instead.
+//
+// S3 requires every part but the last to be at least 5 MiB, and allows at
+// most 10,000 parts.
+var StoragePartSize = 16 * 1024 * 1024
+
+func init() {
+	Ground.MustSet("s3-put",
+		Func("s3-put", "[bucket key content & opts]", func(ctx context.Context, bucket, key string, content Value, opts ...StorageOpts) (*Scope, error) {
+			client, err := NewStorageClient(ctx, storageOpts(opts))
+			if err != nil {
+				return nil, err
+			}
+
+			var payload io.Reader
+			var str string
+			var readable Readable
+			if err := content.Decode(&str); err == nil {
+				payload = strings.NewReader(str)
+			} else if err := content.Decode(&readable); err == nil {
+				rc, err := readable.Open(ctx)
+				if err != nil {
+					return nil, err
+				}
+
+				defer rc.Close()
+
+				payload = rc
+			} else {
+				return nil, fmt.Errorf("s3-put: content must be a string or a readable path: %w", err)
+			}
+
+			etag, err := client.Put(ctx, bucket, key, payload, storageOpts(opts).ContentType)
+			if err != nil {
+				return nil, err
+			}
+
+			return Bindings{
+				"bucket": String(bucket),
+				"key":    String(key),
+				"etag":   String(etag),
+			}.Scope(), nil
+		}),
+		`uploads content to an object in an S3-compatible bucket`,
+		`The content is either a string or a readable path, like a thunk path or host path. Content larger than a single part is streamed using a multipart upload, so it is never held in memory all at once.`,
+		`Options may be given as a scope: :endpoint is the storage API endpoint (defaulting to AWS S3 for the :region, which defaults to us-east-1), :content_type sets the object's Content-Type, and :access_key_id and :secret_access_key set the credentials, typically as secrets.`,
+		`If no credentials are given, they are requested from the credential helper as "get storage <endpoint host>", with the access key ID as the username.`,
+		`Google Cloud Storage is supported using HMAC keys with the endpoint https://storage.googleapis.com and the region auto.`,
+		`Returns a scope containing the :bucket, :key, and :etag of the uploaded object.`,
+		`=> (s3-put "my-bucket" "releases/app.tgz" built/app.tgz {:region "us-west-2"})`)
+
+	Ground.MustSet("s3-get",
+		Func("s3-get", "[bucket key dest & opts]", func(ctx context.Context, bucket, key string, dest HostPath, opts ...StorageOpts) (HostPath, error) {
+			if dest.Path.File == nil {
+				return HostPath{}, fmt.Errorf("s3-get: destination must be a file path: %s", dest)
+			}
+
+			client, err := NewStorageClient(ctx, storageOpts(opts))
+			if err != nil {
+				return HostPath{}, err
+			}
+
+			destPath := dest.FromSlash()
+			if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+				return HostPath{}, err
+			}
+
+			// write to a temporary file first so a failed download doesn't
+			// leave a partial file behind
+			tmp, err := os.CreateTemp(filepath.Dir(destPath), filepath.Base(destPath)+".*")
+			if err != nil {
+				return HostPath{}, err
+			}
+
+			defer os.Remove(tmp.Name())
+			defer tmp.Close()
+
+			if err := client.Get(ctx, bucket, key, tmp); err != nil {
+				return HostPath{}, err
+			}
+
+			if err := tmp.Close(); err != nil {
+				return HostPath{}, err
+			}
+
+			if err := os.Rename(tmp.Name(), destPath); err != nil {
+				return HostPath{}, err
+			}
+
+			return dest, nil
+		}),
+		`downloads an object in an S3-compatible bucket to a host file path`,
+		`The object is streamed to the file, replacing it once the download is complete. Returns the path.`,
+		`Takes the same options as (s3-put).`,
+		`=> (s3-get "my-bucket" "releases/app.tgz" *dir*/app.tgz {:region "us-west-2"})`)
+
+	Ground.MustSet("s3-list",
+		Func("s3-list", "[bucket & opts]", func(ctx context.Context, bucket string, opts ...StorageOpts) (List, error) {
+			client, err := NewStorageClient(ctx, storageOpts(opts))
+			if err != nil {
+				return nil, err
+			}
+
+			keys, err := client.List(ctx, bucket, storageOpts(opts).Prefix)
+			if err != nil {
+				return nil, err
+			}
+
+			vals := make([]Value, len(keys))
+			for i, key := range keys {
+				vals[i] = String(key)
+			}
+
+			return NewList(vals...), nil
+		}),
+		`lists the keys of objects in an S3-compatible bucket`,
+		`Takes the same options as (s3-put), along with :prefix to only list keys with the given prefix.`,
+		`=> (s3-list "my-bucket" {:prefix "releases/"})`)
+}
+
+// StorageOpts are the options accepted by the object storage builtins.
+type StorageOpts struct {
+	// Endpoint is the URL of the storage API, e.g.
+	// https://storage.googleapis.com. Defaults to AWS S3 for the region.
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Region is the region to sign requests for.
+	Region string `json:"region,omitempty"`
+
+	// AccessKeyID and SecretAccessKey are the credentials to sign requests
+	// with. Either may be a string or a Secret.
+	AccessKeyID     Value `json:"access_key_id,omitempty"`
+	SecretAccessKey Value `json:"secret_access_key,omitempty"`
+
+	// SessionToken is a token for temporary credentials, if any.
+	SessionToken Value `json:"session_token,omitempty"`
+
+	// ContentType is the Content-Type of uploaded objects.
+	ContentType string `json:"content_type,omitempty"`
+
+	// Prefix limits listed keys to those with the prefix.
+	Prefix string `json:"prefix,omitempty"`
+}
+
+func storageOpts(opts []StorageOpts) StorageOpts {
+	if len(opts) == 0 {
+		return StorageOpts{}
+	}
+
+	return opts[0]
+}
+
+// StorageCredentials are the credentials used to sign object storage
+// requests.
+type StorageCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// StorageClient is a minimal client for S3-compatible object storage.
+type StorageClient struct {
+	Endpoint    *url.URL
+	Region      string
+	Credentials StorageCredentials
+
+	http *http.Client
+}
+
+// NewStorageClient initializes a client, resolving credentials from the
+// options or from the credential helper.
+func NewStorageClient(ctx context.Context, opts StorageOpts) (*StorageClient, error) {
+	region := opts.Region
+	if region == "" {
+		region = DefaultStorageRegion
+	}
+
+	endpoint := opts.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("storage endpoint: %w", err)
+	}
+
+	var creds StorageCredentials
+	if opts.AccessKeyID != nil || opts.SecretAccessKey != nil {
+		creds.AccessKeyID, err = revealString(opts.AccessKeyID)
+		if err != nil {
+			return nil, fmt.Errorf("access_key_id: %w", err)
+		}
+
+		creds.SecretAccessKey, err = revealString(opts.SecretAccessKey)
+		if err != nil {
+			return nil, fmt.Errorf("secret_access_key: %w", err)
+		}
+	} else {
+		helper, found := CredentialHelperFromContext(ctx)
+		if !found {
+			return nil, fmt.Errorf("no storage credentials given: %w", ErrNoCredentialHelper)
+		}
+
+		cred, found, err := helper.Get(ctx, CredentialKindStorage, u.Host)
+		if err != nil {
+			return nil, err
+		}
+
+		if !found {
+			return nil, CredentialNotFoundError{
+				Kind: CredentialKindStorage,
+				Key:  u.Host,
+			}
+		}
+
+		creds.AccessKeyID = cred.Username
+		creds.SecretAccessKey = cred.Secret
+	}
+
+	if opts.SessionToken != nil {
+		creds.SessionToken, err = revealString(opts.SessionToken)
+		if err != nil {
+			return nil, fmt.Errorf("session_token: %w", err)
+		}
+	}
+
+	network, _ := NetworkConfigFromContext(ctx)
+	httpClient, err := network.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return &StorageClient{
+		Endpoint:    u,
+		Region:      region,
+		Credentials: creds,
+
+		http: httpClient,
+	}, nil
+}
+
+// Put uploads an object and returns its ETag.
+//
+// The content is read one part at a time, using a multipart upload if it is
+// larger than StoragePartSize.
+func (client *StorageClient) Put(ctx context.Context, bucket, key string, content io.Reader, contentType string) (string, error) {
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	part, more, err := readPart(content)
+	if err != nil {
+		return "", fmt.Errorf("put s3://%s/%s: %w", bucket, key, err)
+	}
+
+	if !more {
+		res, err := client.do(ctx, http.MethodPut, bucket, key, nil, header, part)
+		if err != nil {
+			return "", fmt.Errorf("put s3://%s/%s: %w", bucket, key, err)
+		}
+
+		res.Body.Close()
+
+		return strings.Trim(res.Header.Get("ETag"), `"`), nil
+	}
+
+	etag, err := client.putMultipart(ctx, bucket, key, header, part, content)
+	if err != nil {
+		return "", fmt.Errorf("put s3://%s/%s: %w", bucket, key, err)
+	}
+
+	return etag, nil
+}
+
+// readPart reads up to StoragePartSize bytes, returning whether there may be
+// more content to read.
+func readPart(r io.Reader) ([]byte, bool, error) {
+	buf := make([]byte, StoragePartSize)
+
+	n, err := io.ReadFull(r, buf)
+	switch {
+	case err == nil:
+		return buf, true, nil
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return buf[:n], false, nil
+	default:
+		return nil, false, err
+	}
+}
+
+type multipartPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+func (client *StorageClient) putMultipart(ctx context.Context, bucket, key string, header http.Header, first []byte, rest io.Reader) (string, error) {
+	res, err := client.do(ctx, http.MethodPost, bucket, key, url.Values{"uploads": {""}}, header, nil)
+	if err != nil {
+		return "", fmt.Errorf("create multipart upload: %w", err)
+	}
+
+	var upload struct {
+		UploadID string `xml:"UploadId"`
+	}
+	err = xml.NewDecoder(res.Body).Decode(&upload)
+	res.Body.Close()
+	if err != nil {
+		return "", fmt.Errorf("create multipart upload: decode response: %w", err)
+	}
+
+	etag, err := client.uploadParts(ctx, bucket, key, upload.UploadID, first, rest)
+	if err != nil {
+		// don't leave parts around to be billed for
+		res, abortErr := client.do(ctx, http.MethodDelete, bucket, key, url.Values{"uploadId": {upload.UploadID}}, nil, nil)
+		if abortErr == nil {
+			res.Body.Close()
+		}
+
+		return "", err
+	}
+
+	return etag, nil
+}
+
+func (client *StorageClient) uploadParts(ctx context.Context, bucket, key, uploadID string, part []byte, rest io.Reader) (string, error) {
+	var parts []multipartPart
+
+	more := true
+	for len(part) > 0 {
+		num := len(parts) + 1
+
+		res, err := client.do(ctx, http.MethodPut, bucket, key, url.Values{
+			"partNumber": {strconv.Itoa(num)},
+			"uploadId":   {uploadID},
+		}, nil, part)
+		if err != nil {
+			return "", fmt.Errorf("upload part %d: %w", num, err)
+		}
+
+		res.Body.Close()
+
+		parts = append(parts, multipartPart{
+			PartNumber: num,
+			ETag:       res.Header.Get("ETag"),
+		})
+
+		if !more {
+			break
+		}
+
+		part, more, err = readPart(rest)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	complete, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []multipartPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return "", err
+	}
+
+	res, err := client.do(ctx, http.MethodPost, bucket, key, url.Values{"uploadId": {uploadID}}, nil, complete)
+	if err != nil {
+		return "", fmt.Errorf("complete multipart upload: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	// completing may fail after the response has started, in which case the
+	// error is in the body of a 200 response
+	var result struct {
+		ETag    string `xml:"ETag"`
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if err := xml.NewDecoder(res.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("complete multipart upload: decode response: %w", err)
+	}
+
+	if result.Code != "" {
+		return "", fmt.Errorf("complete multipart upload: %s: %s", result.Code, result.Message)
+	}
+
+	return strings.Trim(result.ETag, `"`), nil
+}
+
+// Get streams the content of an object to w.
+func (client *StorageClient) Get(ctx context.Context, bucket, key string, w io.Writer) error {
+	res, err := client.do(ctx, http.MethodGet, bucket, key, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("get s3://%s/%s: %w", bucket, key, err)
+	}
+
+	defer res.Body.Close()
+
+	if _, err := io.Copy(w, res.Body); err != nil {
+		return fmt.Errorf("get s3://%s/%s: %w", bucket, key, err)
+	}
+
+	return nil
+}
+
+// List returns the keys of all objects with the given prefix, following
+// continuation tokens until the listing is complete.
+func (client *StorageClient) List(ctx context.Context, bucket, prefix string) ([]string, error) {
+	var keys []string
+
+	query := url.Values{"list-type": {"2"}}
+	if prefix != "" {
+		query.Set("prefix", prefix)
+	}
+
+	for {
+		res, err := client.do(ctx, http.MethodGet, bucket, "", query, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("list s3://%s/%s: %w", bucket, prefix, err)
+		}
+
+		var result struct {
+			Contents []struct {
+				Key string `xml:"Key"`
+			} `xml:"Contents"`
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+		}
+		err = xml.NewDecoder(res.Body).Decode(&result)
+		res.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("list s3://%s/%s: decode response: %w", bucket, prefix, err)
+		}
+
+		for _, obj := range result.Contents {
+			keys = append(keys, obj.Key)
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return keys, nil
+		}
+
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+func (client *StorageClient) do(ctx context.Context, method, bucket, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	u := *client.Endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + bucket
+	if key != "" {
+		u.Path += "/" + key
+	}
+
+	// escape the path the same way it is canonicalized for signing
+	u.RawPath = awsURIEncode(u.Path, false)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	for k, vs := range header {
+		req.Header[k] = vs
+	}
+
+	payloadHash := sha256.Sum256(body)
+	SignAWSV4(req, hex.EncodeToString(payloadHash[:]), client.Credentials, client.Region, "s3", time.Now())
+
+	res, err := client.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode >= 300 {
+		defer res.Body.Close()
+
+		var failure struct {
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		}
+		if err := xml.NewDecoder(res.Body).Decode(&failure); err != nil || failure.Code == "" {
+			return nil, fmt.Errorf("%s", res.Status)
+		}
+
+		return nil, fmt.Errorf("%s: %s: %s", res.Status, failure.Code, failure.Message)
+	}
+
+	return res, nil
+}
+
+// SignAWSV4 signs the request using AWS Signature Version 4, setting the
+// Authorization header along with the X-Amz-* headers it covers.
+//
+// The Host header and all headers already set on the request are signed. The
+// request's path must already be escaped as required by AWS, i.e. with every
+// byte other than unreserved characters and slashes percent-encoded.
+func SignAWSV4(req *http.Request, payloadHash string, creds StorageCredentials, region, service string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{
+		"host": req.URL.Host,
+	}
+	for k, vs := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(vs, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	canonicalHeaders := new(strings.Builder)
+	for _, name := range names {
+		fmt.Fprintf(canonicalHeaders, "%s:%s\n", name, headers[name])
+	}
+
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		awsCanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := []byte("AWS4" + creds.SecretAccessKey)
+	for _, part := range []string{date, region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID,
+		scope,
+		signedHeaders,
+		signature,
+	))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func awsCanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, k := range keys {
+		vals := append([]string{}, query[k]...)
+		sort.Strings(vals)
+
+		for _, v := range vals {
+			pairs = append(pairs, awsURIEncode(k, true)+"="+awsURIEncode(v, true))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+// awsURIEncode encodes a string as required by AWS Signature Version 4.
+func awsURIEncode(s string, encodeSlash bool) string {
+	buf := new(strings.Builder)
+	for _, b := range []byte(s) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9',
+			b == '-', b == '_', b == '.', b == '~':
+			buf.WriteByte(b)
+		case b == '/' && !encodeSlash:
+			buf.WriteByte(b)
+		default:
+			fmt.Fprintf(buf, "%%%02X", b)
+		}
+	}
+
+	return buf.String()
+}
+
+func revealString(val Value) (string, error) {
+	if val == nil {
+		return "", fmt.Errorf("missing value")
+	}
+
+	var secret Secret
+	if err := val.Decode(&secret); err == nil {
+		return string(secret.Reveal()), nil
+	}
+
+	var str string
+	if err := val.Decode(&str); err != nil {
+		return "", err
+	}
+
+	return str, nil
+}
@@ -0,0 +1,219 @@
+package bass
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// CredentialKindOAuth is a client credential for an OAuth2 token endpoint,
+// keyed by its host. The username is the client ID and the secret is the
+// client secret.
+const CredentialKindOAuth = "oauth"
+
+func init() {
+	Ground.MustSet("oidc-token",
+		Func("oidc-token", "[audience & opts]", func(ctx context.Context, audience string, opts ...OIDCOpts) (Secret, error) {
+			var o OIDCOpts
+			if len(opts) > 0 {
+				o = opts[0]
+			}
+
+			token, err := FetchOIDCToken(ctx, audience, o)
+			if err != nil {
+				return Secret{}, err
+			}
+
+			return NewSecret("oidc-token", []byte(token)), nil
+		}),
+		`fetches a short-lived token for the audience as a secret`,
+		`If a :token_url is given, the token is requested using the OAuth2 client credentials flow. The :client_id and :client_secret may be given as strings or secrets, and are otherwise requested from the credential helper as "get oauth <token_url host>", with the client ID as the username. An optional :scope may be requested too.`,
+		`Otherwise, when running in GitHub Actions with the id-token: write permission, an OIDC ID token is requested from GitHub for the audience.`,
+		`=> (oidc-token "sts.amazonaws.com")`,
+		`=> (oidc-token "https://api.example.com" {:token_url "https://auth.example.com/oauth/token" :client_id "ci" :client_secret (secret :oauth-client-secret)})`)
+}
+
+// OIDCOpts are the options accepted by (oidc-token).
+type OIDCOpts struct {
+	// TokenURL is the OAuth2 token endpoint to use for the client credentials
+	// flow.
+	TokenURL string `json:"token_url,omitempty"`
+
+	// ClientID and ClientSecret authenticate the client. Either may be a
+	// string or a Secret.
+	ClientID     Value `json:"client_id,omitempty"`
+	ClientSecret Value `json:"client_secret,omitempty"`
+
+	// Scope is the space-separated list of scopes to request.
+	Scope string `json:"scope,omitempty"`
+}
+
+// FetchOIDCToken fetches a token for the audience, using the client
+// credentials flow if a token URL is configured, or GitHub Actions' OIDC
+// provider otherwise.
+func FetchOIDCToken(ctx context.Context, audience string, opts OIDCOpts) (string, error) {
+	network, _ := NetworkConfigFromContext(ctx)
+	httpClient, err := network.HTTPClient()
+	if err != nil {
+		return "", err
+	}
+
+	if opts.TokenURL != "" {
+		return clientCredentialsToken(ctx, httpClient, audience, opts)
+	}
+
+	requestURL, hasURL := os.LookupEnv("ACTIONS_ID_TOKEN_REQUEST_URL")
+	requestToken, hasToken := os.LookupEnv("ACTIONS_ID_TOKEN_REQUEST_TOKEN")
+	if hasURL && hasToken {
+		return githubOIDCToken(ctx, httpClient, audience, requestURL, requestToken)
+	}
+
+	return "", fmt.Errorf("oidc token: no :token_url given and not running in GitHub Actions with an id-token permission")
+}
+
+// clientCredentialsToken requests an access token using the OAuth2 client
+// credentials grant.
+func clientCredentialsToken(ctx context.Context, httpClient *http.Client, audience string, opts OIDCOpts) (string, error) {
+	u, err := url.Parse(opts.TokenURL)
+	if err != nil {
+		return "", fmt.Errorf("token_url: %w", err)
+	}
+
+	var clientID, clientSecret string
+	if opts.ClientID != nil || opts.ClientSecret != nil {
+		clientID, err = revealString(opts.ClientID)
+		if err != nil {
+			return "", fmt.Errorf("client_id: %w", err)
+		}
+
+		clientSecret, err = revealString(opts.ClientSecret)
+		if err != nil {
+			return "", fmt.Errorf("client_secret: %w", err)
+		}
+	} else {
+		helper, found := CredentialHelperFromContext(ctx)
+		if !found {
+			return "", fmt.Errorf("no client credentials given: %w", ErrNoCredentialHelper)
+		}
+
+		cred, found, err := helper.Get(ctx, CredentialKindOAuth, u.Host)
+		if err != nil {
+			return "", err
+		}
+
+		if !found {
+			return "", CredentialNotFoundError{
+				Kind: CredentialKindOAuth,
+				Key:  u.Host,
+			}
+		}
+
+		clientID = cred.Username
+		clientSecret = cred.Secret
+	}
+
+	form := url.Values{
+		"grant_type": {"client_credentials"},
+		"audience":   {audience},
+	}
+
+	if opts.Scope != "" {
+		form.Set("scope", opts.Scope)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+
+	var res struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := oidcRequest(httpClient, req, &res); err != nil {
+		return "", fmt.Errorf("client credentials: %w", err)
+	}
+
+	if res.AccessToken == "" {
+		return "", fmt.Errorf("client credentials: no access_token in response")
+	}
+
+	return res.AccessToken, nil
+}
+
+// githubOIDCToken requests an ID token from the GitHub Actions OIDC
+// provider.
+func githubOIDCToken(ctx context.Context, httpClient *http.Client, audience, requestURL, requestToken string) (string, error) {
+	u, err := url.Parse(requestURL)
+	if err != nil {
+		return "", fmt.Errorf("ACTIONS_ID_TOKEN_REQUEST_URL: %w", err)
+	}
+
+	query := u.Query()
+	query.Set("audience", audience)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+requestToken)
+
+	var res struct {
+		Value string `json:"value"`
+	}
+	if err := oidcRequest(httpClient, req, &res); err != nil {
+		return "", fmt.Errorf("github oidc: %w", err)
+	}
+
+	if res.Value == "" {
+		return "", fmt.Errorf("github oidc: no token in response")
+	}
+
+	return res.Value, nil
+}
+
+func oidcRequest(httpClient *http.Client, req *http.Request, dest any) error {
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		// NB: OAuth2 errors are described by error and error_description
+		var status struct {
+			Error       string `json:"error"`
+			Description string `json:"error_description"`
+			Message     string `json:"message"`
+		}
+
+		body, _ := io.ReadAll(res.Body)
+		_ = json.Unmarshal(body, &status)
+
+		msg := status.Message
+		if status.Error != "" {
+			msg = status.Error
+			if status.Description != "" {
+				msg += ": " + status.Description
+			}
+		}
+
+		if msg == "" {
+			return fmt.Errorf("%s", res.Status)
+		}
+
+		return fmt.Errorf("%s: %s", res.Status, msg)
+	}
+
+	return json.NewDecoder(res.Body).Decode(dest)
+}
@@ -0,0 +1,75 @@
+package bass_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/is"
+)
+
+func TestOIDCToken(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth/token":
+			id, secret, ok := r.BasicAuth()
+			if !ok || id != "ci" || secret != "s3cr3t" {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprintln(w, `{"error":"invalid_client","error_description":"bad client credentials"}`)
+				return
+			}
+
+			is.NoErr(r.ParseForm())
+			is.Equal(r.PostForm.Get("grant_type"), "client_credentials")
+			is.Equal(r.PostForm.Get("scope"), "deploy")
+
+			fmt.Fprintf(w, `{"access_token":"access-for-%s","token_type":"Bearer","expires_in":300}`, r.PostForm.Get("audience"))
+
+		case "/github/token":
+			is.Equal(r.Header.Get("Authorization"), "Bearer request-token")
+			fmt.Fprintf(w, `{"value":"id-for-%s"}`, r.URL.Query().Get("audience"))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	scope := bass.NewStandardScope()
+	scope.Set("token-url", bass.String(srv.URL+"/oauth/token"))
+
+	eval := func(src string) (string, error) {
+		res, err := bass.EvalFSFile(context.Background(), scope, bass.NewInMemoryFile("test", src))
+		if err != nil {
+			return "", err
+		}
+
+		var secret bass.Secret
+		if err := res.Decode(&secret); err != nil {
+			return "", err
+		}
+
+		return string(secret.Reveal()), nil
+	}
+
+	token, err := eval(`(oidc-token "https://api.example.com" {:token_url token-url :client_id "ci" :client_secret "s3cr3t" :scope "deploy"})`)
+	is.NoErr(err)
+	is.Equal(token, "access-for-https://api.example.com")
+
+	_, err = eval(`(oidc-token "https://api.example.com" {:token_url token-url :client_id "ci" :client_secret "wrong" :scope "deploy"})`)
+	is.True(err != nil)
+	is.True(strings.Contains(err.Error(), "401 Unauthorized: invalid_client: bad client credentials"))
+
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", srv.URL+"/github/token?api-version=2.0")
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "request-token")
+
+	token, err = eval(`(oidc-token "sts.amazonaws.com")`)
+	is.NoErr(err)
+	is.Equal(token, "id-for-sts.amazonaws.com")
+}
@@ -7,6 +7,14 @@ type Operative struct {
 	ScopeBinding Bindable
 	Body         Value
 	StaticScope  *Scope
+
+	// Pre is a list of expressions which must be truthy before the body is
+	// evaluated, when contracts are enabled.
+	Pre []Value
+
+	// Post is a list of predicates which must return a truthy value when
+	// called with the body's result, when contracts are enabled.
+	Post []Value
 }
 
 var _ Value = (*Operative)(nil)
@@ -57,9 +65,25 @@ var _ Combiner = (*Operative)(nil)
 func (combiner *Operative) Call(ctx context.Context, val Value, scope *Scope, cont Cont) ReadyCont {
 	sub := NewEmptyScope(combiner.StaticScope)
 
+	if lim := limiterFromContext(ctx); lim != nil {
+		var err error
+		ctx, err = lim.enter(ctx)
+		if err != nil {
+			return cont.Call(nil, err)
+		}
+	}
+
 	return combiner.Bindings.Bind(ctx, sub, Continue(func(Value) Value {
 		return combiner.ScopeBinding.Bind(ctx, sub, Continue(func(Value) Value {
-			return combiner.Body.Eval(ctx, sub, cont)
+			if !ContractsEnabled(ctx) || (len(combiner.Pre) == 0 && len(combiner.Post) == 0) {
+				return combiner.Body.Eval(ctx, sub, cont)
+			}
+
+			return checkPre(ctx, sub, combiner.Pre, Continue(func(Value) Value {
+				return combiner.Body.Eval(ctx, sub, Continue(func(res Value) Value {
+					return checkPost(ctx, sub, combiner.Post, res, cont)
+				}))
+			}))
 		}), scope)
 	}), val)
 }
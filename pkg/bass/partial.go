@@ -0,0 +1,101 @@
+package bass
+
+import (
+	"context"
+)
+
+// Partial is an operative which calls an underlying combiner with a fixed
+// list of arguments prepended to the arguments it's given.
+//
+// Partially applying an applicative wraps a Partial of its underlying
+// operative, so the fixed arguments are never evaluated twice.
+type Partial struct {
+	Combiner Combiner
+	Args     []Value
+}
+
+// NewPartial partially applies the combiner to the given arguments,
+// preserving whether it is applicative or operative.
+func NewPartial(comb Combiner, args ...Value) Combiner {
+	var app Applicative
+	if err := comb.Decode(&app); err == nil {
+		return Wrap(Partial{
+			Combiner: app.Unwrap(),
+			Args:     args,
+		})
+	}
+
+	return Partial{
+		Combiner: comb,
+		Args:     args,
+	}
+}
+
+var _ Value = Partial{}
+
+func (value Partial) Equal(other Value) bool {
+	var o Partial
+	if err := other.Decode(&o); err != nil {
+		return false
+	}
+
+	if !value.Combiner.Equal(o.Combiner) || len(value.Args) != len(o.Args) {
+		return false
+	}
+
+	for i, arg := range value.Args {
+		if !arg.Equal(o.Args[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (value Partial) String() string {
+	return NewList(append([]Value{Symbol("partial"), value.Combiner}, value.Args...)...).String()
+}
+
+func (value Partial) Decode(dest any) error {
+	switch x := dest.(type) {
+	case *Partial:
+		*x = value
+		return nil
+	case *Combiner:
+		*x = value
+		return nil
+	case *Value:
+		*x = value
+		return nil
+	default:
+		return DecodeError{
+			Source:      value,
+			Destination: dest,
+		}
+	}
+}
+
+func (value Partial) MarshalJSON() ([]byte, error) {
+	return nil, EncodeError{value}
+}
+
+// Eval returns the value.
+func (value Partial) Eval(_ context.Context, _ *Scope, cont Cont) ReadyCont {
+	return cont.Call(value, nil)
+}
+
+var _ Combiner = Partial{}
+
+// Call prepends the fixed arguments to the given arguments and calls the
+// underlying combiner.
+func (combiner Partial) Call(ctx context.Context, val Value, scope *Scope, cont Cont) ReadyCont {
+	args := val
+	for i := len(combiner.Args) - 1; i >= 0; i-- {
+		args = Pair{
+			A: combiner.Args[i],
+			D: args,
+		}
+	}
+
+	return combiner.Combiner.Call(ctx, args, scope, cont)
+}
@@ -0,0 +1,163 @@
+package bass
+
+import (
+	"path"
+	"path/filepath"
+)
+
+// PathBase returns the last element of the path as a relative file or
+// directory path.
+func PathBase(p Path) (Path, error) {
+	fsp, _, ok := splitPath(p)
+	if !ok {
+		return nil, PathOpError{"path-base", []Path{p}}
+	}
+
+	if fsp.IsDir() {
+		return DirPath{fsp.Name()}, nil
+	}
+
+	return FilePath{fsp.Name()}, nil
+}
+
+// PathDir returns the directory containing the path, within the same thunk,
+// host directory, or filesystem.
+func PathDir(p Path) (Path, error) {
+	fsp, rewrap, ok := splitPath(p)
+	if !ok {
+		return nil, PathOpError{"path-dir", []Path{p}}
+	}
+
+	return rewrap(fsp.Dir()), nil
+}
+
+// PathExt returns the extension of the path's name, including the leading
+// dot, or an empty string if it has none.
+func PathExt(p Path) (string, error) {
+	fsp, _, ok := splitPath(p)
+	if !ok {
+		return "", PathOpError{"path-ext", []Path{p}}
+	}
+
+	return path.Ext(fsp.Name()), nil
+}
+
+// PathJoin extends the path with each of the given paths in order.
+func PathJoin(p Path, paths ...Path) (Path, error) {
+	var err error
+	for _, child := range paths {
+		p, err = p.Extend(child)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// RelativeTo returns the relative path which, when used to extend the base
+// directory, refers to the given path.
+//
+// Both paths must be within the same thunk, host directory, or filesystem.
+func RelativeTo(p Path, base Path) (Path, error) {
+	opErr := PathOpError{"relative-to", []Path{p, base}}
+
+	fsp, _, ok := splitPath(p)
+	if !ok {
+		return nil, opErr
+	}
+
+	baseFsp, _, ok := splitPath(base)
+	if !ok || !baseFsp.IsDir() || !samePathRoot(p, base) {
+		return nil, opErr
+	}
+
+	rel, err := filepath.Rel(
+		filepath.FromSlash(rawPath(baseFsp)),
+		filepath.FromSlash(rawPath(fsp)),
+	)
+	if err != nil {
+		return nil, opErr
+	}
+
+	rel = filepath.ToSlash(rel)
+
+	if fsp.IsDir() {
+		return DirPath{rel}, nil
+	}
+
+	return FilePath{rel}, nil
+}
+
+// splitPath returns the filesystem path within a path, along with a function
+// which wraps another filesystem path in the same thunk, host directory, or
+// filesystem.
+func splitPath(p Path) (FilesystemPath, func(FilesystemPath) Path, bool) {
+	switch x := p.(type) {
+	case FilePath:
+		return x, func(fsp FilesystemPath) Path { return fsp }, true
+	case DirPath:
+		return x, func(fsp FilesystemPath) Path { return fsp }, true
+	case HostPath:
+		return x.Path.FilesystemPath(), func(fsp FilesystemPath) Path {
+			return HostPath{
+				ContextDir: x.ContextDir,
+				Path:       NewFileOrDirPath(fsp),
+			}
+		}, true
+	case ThunkPath:
+		return x.Path.FilesystemPath(), func(fsp FilesystemPath) Path {
+			return ThunkPath{
+				Thunk: x.Thunk,
+				Path:  NewFileOrDirPath(fsp),
+			}
+		}, true
+	case *FSPath:
+		return x.Path.FilesystemPath(), func(fsp FilesystemPath) Path {
+			return NewFSPath(x.FS, NewFileOrDirPath(fsp))
+		}, true
+	default:
+		return nil, nil, false
+	}
+}
+
+// samePathRoot returns true if both paths are plain filesystem paths or are
+// within the same thunk, host directory, or filesystem.
+func samePathRoot(a, b Path) bool {
+	switch x := a.(type) {
+	case FilePath, DirPath:
+		switch b.(type) {
+		case FilePath, DirPath:
+			return true
+		}
+	case HostPath:
+		y, ok := b.(HostPath)
+		return ok && x.ContextDir == y.ContextDir
+	case ThunkPath:
+		y, ok := b.(ThunkPath)
+		return ok && x.Thunk.Equal(y.Thunk)
+	case *FSPath:
+		y, ok := b.(*FSPath)
+		return ok && x.FS == y.FS
+	}
+
+	return false
+}
+
+// rawPath returns the cleaned path of a FilePath or DirPath, or / for the root
+// directory.
+func rawPath(fsp FilesystemPath) string {
+	var p string
+	switch x := fsp.(type) {
+	case FilePath:
+		p = x.Path
+	case DirPath:
+		p = x.Path
+	}
+
+	if p == "" {
+		return "/"
+	}
+
+	return p
+}
@@ -2,12 +2,13 @@ package bass
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+
+	"golang.org/x/term"
 )
 
 type PipeSource interface {
@@ -20,6 +21,54 @@ type PipeSink interface {
 	Emit(Value) error
 }
 
+// TerminalPipe is implemented by pipes which may be attached to a terminal,
+// as with *stdin* when nothing is piped to bass.
+type TerminalPipe interface {
+	// IsTerminal returns true if the pipe is attached to a terminal. It does
+	// not block or consume any input.
+	IsTerminal() bool
+}
+
+// IsTerminal returns true if the value is a source or sink attached to a
+// terminal.
+func IsTerminal(val Value) bool {
+	var src *Source
+	if err := val.Decode(&src); err == nil {
+		tp, ok := src.PipeSource.(TerminalPipe)
+		return ok && tp.IsTerminal()
+	}
+
+	var sink *Sink
+	if err := val.Decode(&sink); err == nil {
+		tp, ok := sink.PipeSink.(TerminalPipe)
+		return ok && tp.IsTerminal()
+	}
+
+	return false
+}
+
+// DrainSource reads all remaining values from the source.
+func DrainSource(ctx context.Context, src PipeSource) ([]Value, error) {
+	var vals []Value
+	for {
+		val, err := src.Next(ctx)
+		if err != nil {
+			if errors.Is(err, ErrEndOfSource) {
+				return vals, nil
+			}
+
+			return nil, err
+		}
+
+		vals = append(vals, val)
+	}
+}
+
+func isTerminalFile(x any) bool {
+	f, ok := x.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
 var Stdin = &Source{
 	NewJSONSource("stdin", os.Stdin),
 }
@@ -31,7 +80,8 @@ var Stdout = &Sink{
 type JSONSink struct {
 	Name string
 
-	enc *json.Encoder
+	out io.Writer
+	enc *Encoder
 }
 
 var _ PipeSink = (*JSONSink)(nil)
@@ -73,7 +123,8 @@ func (sink *InMemorySink) Source() PipeSource {
 func NewJSONSink(name string, out io.Writer) *JSONSink {
 	return &JSONSink{
 		Name: name,
-		enc:  NewEncoder(out),
+		out:  out,
+		enc:  NewValueEncoder(out),
 	}
 }
 
@@ -82,7 +133,11 @@ func (sink *JSONSink) String() string {
 }
 
 func (sink *JSONSink) Emit(val Value) error {
-	return sink.enc.Encode(val)
+	return sink.enc.EncodeValue(val)
+}
+
+func (sink *JSONSink) IsTerminal() bool {
+	return isTerminalFile(sink.out)
 }
 
 type InMemorySource struct {
@@ -123,6 +178,7 @@ func (src *InMemorySource) Next(_ context.Context) (Value, error) {
 type JSONSource struct {
 	Name string
 
+	in  io.Reader
 	dec *Decoder
 }
 
@@ -132,6 +188,7 @@ func NewJSONSource(name string, in io.Reader) *JSONSource {
 	return &JSONSource{
 		Name: name,
 
+		in:  in,
 		dec: NewDecoder(in),
 	}
 }
@@ -140,6 +197,10 @@ func (source *JSONSource) String() string {
 	return source.Name
 }
 
+func (source *JSONSource) IsTerminal() bool {
+	return isTerminalFile(source.in)
+}
+
 func (source *JSONSource) Next(context.Context) (Value, error) {
 	var val Value
 	err := source.dec.Decode(&val)
@@ -0,0 +1,148 @@
+package bass
+
+import (
+	"context"
+
+	"github.com/vito/bass/pkg/zapctx"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	gproto "google.golang.org/protobuf/proto"
+)
+
+// PrefetchPlan lists the inputs of a thunk which involve network or disk I/O
+// that can be done ahead of running it.
+type PrefetchPlan struct {
+	// Images lists every image reference used by the thunk, its image thunks,
+	// and the thunks whose paths it mounts.
+	Images []ImageRef
+
+	// HostPaths lists every host path mounted by the thunk or its dependent
+	// thunks.
+	HostPaths []HostPath
+}
+
+// Prefetcher is an optional interface implemented by runtimes which can pull
+// images and sync host paths ahead of running a thunk.
+type Prefetcher interface {
+	Prefetch(context.Context, PrefetchPlan) error
+}
+
+// PlanPrefetch walks the thunk and the thunks it depends on, collecting the
+// images and host paths they use.
+func PlanPrefetch(thunk Thunk) PrefetchPlan {
+	var plan PrefetchPlan
+	plan.add(thunk)
+	return plan
+}
+
+func (plan *PrefetchPlan) add(thunk Thunk) {
+	if thunk.Image != nil {
+		switch {
+		case thunk.Image.Ref != nil:
+			plan.addImage(*thunk.Image.Ref)
+		case thunk.Image.Thunk != nil:
+			plan.add(*thunk.Image.Thunk)
+		case thunk.Image.Archive != nil:
+			plan.add(thunk.Image.Archive.File.Thunk)
+		}
+	}
+
+	if thunk.Cmd.Thunk != nil {
+		plan.add(thunk.Cmd.Thunk.Thunk)
+	}
+
+	if thunk.Cmd.Host != nil {
+		plan.addHostPath(*thunk.Cmd.Host)
+	}
+
+	for _, mount := range thunk.Mounts {
+		switch {
+		case mount.Source.ThunkPath != nil:
+			plan.add(mount.Source.ThunkPath.Thunk)
+		case mount.Source.HostPath != nil:
+			plan.addHostPath(*mount.Source.HostPath)
+		}
+	}
+}
+
+func (plan *PrefetchPlan) addImage(ref ImageRef) {
+	msg, err := ref.MarshalProto()
+	if err == nil {
+		for _, existing := range plan.Images {
+			existingMsg, err := existing.MarshalProto()
+			if err == nil && gproto.Equal(msg, existingMsg) {
+				return
+			}
+		}
+	}
+
+	plan.Images = append(plan.Images, ref)
+}
+
+func (plan *PrefetchPlan) addHostPath(hp HostPath) {
+	for _, existing := range plan.HostPaths {
+		if existing.Equal(hp) {
+			return
+		}
+	}
+
+	plan.HostPaths = append(plan.HostPaths, hp)
+}
+
+// Prefetch pulls the images and syncs the host paths used by the thunks,
+// concurrently, so that running them later does not have to wait on them.
+//
+// Runtimes which do not implement Prefetcher only have their images
+// resolved.
+func Prefetch(ctx context.Context, thunks ...Thunk) error {
+	eg, ctx := errgroup.WithContext(ctx)
+
+	for _, thunk := range thunks {
+		platform := thunk.Platform()
+		if platform == nil {
+			continue
+		}
+
+		runtime, err := RuntimeFromContext(ctx, *platform)
+		if err != nil {
+			return err
+		}
+
+		plan := PlanPrefetch(thunk)
+
+		if prefetcher, ok := runtime.(Prefetcher); ok {
+			eg.Go(func() error {
+				return prefetcher.Prefetch(ctx, plan)
+			})
+
+			continue
+		}
+
+		for _, ref := range plan.Images {
+			ref := ref
+			eg.Go(func() error {
+				_, err := ResolveImage(ctx, ref)
+				return err
+			})
+		}
+	}
+
+	return eg.Wait()
+}
+
+// StartPrefetch prefetches the thunks in the background, overlapping the
+// pulls and syncs with evaluation. Failures are logged rather than returned,
+// since running the thunks will try again.
+func StartPrefetch(ctx context.Context, thunks ...Thunk) {
+	ctx, stop := context.WithCancel(ctx)
+
+	RunsFromContext(ctx).Go("prefetch", stop, func() error {
+		defer stop()
+
+		if err := Prefetch(ctx, thunks...); err != nil && ctx.Err() == nil {
+			zapctx.FromContext(ctx).Warn("prefetch failed", zap.Error(err))
+		}
+
+		return nil
+	})
+}
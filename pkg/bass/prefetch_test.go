@@ -0,0 +1,87 @@
+package bass_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/runtimes"
+	"github.com/vito/is"
+)
+
+type prefetchingRuntime struct {
+	FakeRuntime
+
+	mu    sync.Mutex
+	plans []bass.PrefetchPlan
+}
+
+func (fake *prefetchingRuntime) Prefetch(ctx context.Context, plan bass.PrefetchPlan) error {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	fake.plans = append(fake.plans, plan)
+	return nil
+}
+
+func TestPlanPrefetch(t *testing.T) {
+	is := is.New(t)
+
+	alpine := bass.ImageRef{
+		Platform: bass.LinuxPlatform,
+		Repository: bass.ImageRepository{
+			Static: "alpine",
+		},
+		Tag: "latest",
+	}
+
+	golang := bass.ImageRef{
+		Platform: bass.LinuxPlatform,
+		Repository: bass.ImageRepository{
+			Static: "golang",
+		},
+		Tag: "1.18",
+	}
+
+	src := bass.NewHostPath("/src", bass.ParseFileOrDirPath("./"))
+
+	base := bass.Thunk{
+		Cmd: bass.ThunkCmd{Cmd: &bass.CommandPath{"apk"}},
+	}.WithImage(bass.ThunkImage{Ref: &alpine})
+
+	build := bass.Thunk{
+		Cmd: bass.ThunkCmd{Cmd: &bass.CommandPath{"go"}},
+	}.WithImage(bass.ThunkImage{Ref: &golang}).
+		WithMount(bass.ThunkMountSource{HostPath: &src}, bass.ParseFileOrDirPath("./src/"))
+
+	thunk := bass.Thunk{
+		Cmd: bass.ThunkCmd{Cmd: &bass.CommandPath{"test"}},
+	}.WithImage(bass.ThunkImage{Thunk: &base}).
+		WithMount(bass.ThunkMountSource{
+			ThunkPath: &bass.ThunkPath{
+				Thunk: build,
+				Path:  bass.ParseFileOrDirPath("./out/"),
+			},
+		}, bass.ParseFileOrDirPath("./out/")).
+		WithMount(bass.ThunkMountSource{HostPath: &src}, bass.ParseFileOrDirPath("./src/"))
+
+	plan := bass.PlanPrefetch(thunk)
+	is.Equal(len(plan.Images), 2)
+	is.Equal(plan.Images[0].Repository.Static, "alpine")
+	is.Equal(plan.Images[1].Repository.Static, "golang")
+	is.Equal(plan.HostPaths, []bass.HostPath{src})
+
+	fake := &prefetchingRuntime{}
+	ctx := bass.WithRuntimePool(context.Background(), &runtimes.Pool{
+		Runtimes: []runtimes.Assoc{
+			{
+				Platform: bass.LinuxPlatform,
+				Runtime:  fake,
+			},
+		},
+	})
+
+	is.NoErr(bass.Prefetch(ctx, thunk))
+	is.Equal(len(fake.plans), 1)
+	is.Equal(len(fake.plans[0].Images), 2)
+}
@@ -0,0 +1,174 @@
+package bass
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+func init() {
+	Ground.MustSet("prompt",
+		Func("prompt", "[message & default]", func(ctx context.Context, msg string, def ...String) (String, error) {
+			answer, err := PrompterFromContext(ctx).Prompt(msg, false)
+			if err != nil {
+				if errors.Is(err, ErrNonInteractive) && len(def) > 0 {
+					return def[0], nil
+				}
+
+				return "", err
+			}
+
+			if answer == "" && len(def) > 0 {
+				return def[0], nil
+			}
+
+			return String(answer), nil
+		}),
+		`asks the user for a line of input`,
+		`Reads from the terminal running bass. Returns the default, if given, when the answer is blank or when bass is not running interactively; otherwise errors when not interactive.`,
+		`=> (prompt "Which environment?" "staging")`)
+
+	Ground.MustSet("prompt-secret",
+		Func("prompt-secret", "[message & name]", func(ctx context.Context, msg string, name ...Symbol) (Secret, error) {
+			answer, err := PrompterFromContext(ctx).Prompt(msg, true)
+			if err != nil {
+				return Secret{}, err
+			}
+
+			secretName := "prompt"
+			if len(name) > 0 {
+				secretName = name[0].String()
+			}
+
+			return NewSecret(secretName, []byte(answer)), nil
+		}),
+		`asks the user for a secret without echoing it`,
+		`Returns the answer as a secret with the given name, or prompt if no name is given. Errors when bass is not running interactively.`,
+		`=> (prompt-secret "Token:" :github-token)`)
+
+	Ground.MustSet("confirm",
+		Func("confirm", "[message]", func(ctx context.Context, msg string) (bool, error) {
+			return PrompterFromContext(ctx).Confirm(msg)
+		}),
+		`asks the user a yes or no question`,
+		`Returns true if the user answers yes. Errors when bass is not running interactively, unless --yes is given, in which case it always returns true.`,
+		`Useful for guarding manual approval steps.`,
+		`=> (when (confirm "Deploy to prod?") (log "deploying"))`)
+}
+
+// ErrNonInteractive is returned when prompting without a terminal.
+var ErrNonInteractive = errors.New("cannot prompt: not running interactively")
+
+// Prompter asks the user for input.
+type Prompter interface {
+	// Prompt asks for a line of input. If secret is true, the input is not
+	// echoed.
+	Prompt(msg string, secret bool) (string, error)
+
+	// Confirm asks a yes or no question.
+	Confirm(msg string) (bool, error)
+}
+
+type prompterKey struct{}
+
+// WithPrompter sets the Prompter used by (prompt) and friends.
+func WithPrompter(ctx context.Context, prompter Prompter) context.Context {
+	return context.WithValue(ctx, prompterKey{}, prompter)
+}
+
+// PrompterFromContext returns the Prompter set in the context, or a
+// Prompter which always returns ErrNonInteractive.
+func PrompterFromContext(ctx context.Context) Prompter {
+	prompter := ctx.Value(prompterKey{})
+	if prompter == nil {
+		return NonInteractive{}
+	}
+
+	return prompter.(Prompter)
+}
+
+// NonInteractive is a Prompter which always returns ErrNonInteractive.
+type NonInteractive struct{}
+
+func (NonInteractive) Prompt(string, bool) (string, error) {
+	return "", ErrNonInteractive
+}
+
+func (NonInteractive) Confirm(string) (bool, error) {
+	return false, ErrNonInteractive
+}
+
+// AssumeYes is a Prompter which confirms everything without asking, and
+// otherwise defers to another Prompter.
+type AssumeYes struct {
+	Prompter
+}
+
+func (AssumeYes) Confirm(string) (bool, error) {
+	return true, nil
+}
+
+// TTY is a Prompter which reads from the controlling terminal.
+//
+// The terminal is opened directly so that prompts work even when stdin and
+// stdout are piped.
+type TTY struct {
+	l sync.Mutex
+}
+
+func (tty *TTY) Prompt(msg string, secret bool) (string, error) {
+	tty.l.Lock()
+	defer tty.l.Unlock()
+
+	f, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return "", ErrNonInteractive
+	}
+
+	defer f.Close()
+
+	if !term.IsTerminal(int(f.Fd())) {
+		return "", ErrNonInteractive
+	}
+
+	fmt.Fprintf(f, "%s ", strings.TrimSpace(msg))
+
+	if secret {
+		answer, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(f)
+		if err != nil {
+			return "", err
+		}
+
+		return string(answer), nil
+	}
+
+	answer, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(answer, "\r\n"), nil
+}
+
+func (tty *TTY) Confirm(msg string) (bool, error) {
+	for {
+		answer, err := tty.Prompt(strings.TrimSpace(msg)+" [y/n]", false)
+		if err != nil {
+			return false, err
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
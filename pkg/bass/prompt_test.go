@@ -0,0 +1,108 @@
+package bass_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/basstest"
+	"github.com/vito/is"
+)
+
+type fakePrompter struct {
+	answers map[string]string
+}
+
+func (fake fakePrompter) Prompt(msg string, secret bool) (string, error) {
+	return fake.answers[msg], nil
+}
+
+func (fake fakePrompter) Confirm(msg string) (bool, error) {
+	return fake.answers[msg] == "y", nil
+}
+
+func TestPrompt(t *testing.T) {
+	for _, example := range []struct {
+		Name     string
+		Prompter bass.Prompter
+		Bass     string
+		Result   bass.Value
+		Err      error
+	}{
+		{
+			Name:     "prompt",
+			Prompter: fakePrompter{map[string]string{"Env?": "prod"}},
+			Bass:     `(prompt "Env?")`,
+			Result:   bass.String("prod"),
+		},
+		{
+			Name:     "prompt blank with default",
+			Prompter: fakePrompter{},
+			Bass:     `(prompt "Env?" "staging")`,
+			Result:   bass.String("staging"),
+		},
+		{
+			Name:   "prompt non-interactive with default",
+			Bass:   `(prompt "Env?" "staging")`,
+			Result: bass.String("staging"),
+		},
+		{
+			Name: "prompt non-interactive",
+			Bass: `(prompt "Env?")`,
+			Err:  bass.ErrNonInteractive,
+		},
+		{
+			Name:     "prompt-secret",
+			Prompter: fakePrompter{map[string]string{"Token:": "hunter2"}},
+			Bass:     `(prompt-secret "Token:" :token)`,
+			Result:   bass.NewSecret("token", []byte("hunter2")),
+		},
+		{
+			Name: "prompt-secret non-interactive",
+			Bass: `(prompt-secret "Token:")`,
+			Err:  bass.ErrNonInteractive,
+		},
+		{
+			Name:     "confirm yes",
+			Prompter: fakePrompter{map[string]string{"Deploy?": "y"}},
+			Bass:     `(confirm "Deploy?")`,
+			Result:   bass.Bool(true),
+		},
+		{
+			Name:     "confirm no",
+			Prompter: fakePrompter{map[string]string{"Deploy?": "n"}},
+			Bass:     `(confirm "Deploy?")`,
+			Result:   bass.Bool(false),
+		},
+		{
+			Name: "confirm non-interactive",
+			Bass: `(confirm "Deploy?")`,
+			Err:  bass.ErrNonInteractive,
+		},
+		{
+			Name:     "confirm assuming yes",
+			Prompter: bass.AssumeYes{Prompter: bass.NonInteractive{}},
+			Bass:     `(confirm "Deploy?")`,
+			Result:   bass.Bool(true),
+		},
+	} {
+		example := example
+		t.Run(example.Name, func(t *testing.T) {
+			is := is.New(t)
+
+			ctx := context.Background()
+			if example.Prompter != nil {
+				ctx = bass.WithPrompter(ctx, example.Prompter)
+			}
+
+			res, err := bass.EvalFSFile(ctx, bass.NewStandardScope(), bass.NewInMemoryFile(example.Name, example.Bass))
+			if example.Err != nil {
+				is.True(errors.Is(err, example.Err))
+			} else {
+				is.NoErr(err)
+				basstest.Equal(t, res, example.Result)
+			}
+		})
+	}
+}
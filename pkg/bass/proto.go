@@ -3,6 +3,7 @@ package bass
 import (
 	"fmt"
 	"io/fs"
+	"math/big"
 	"path"
 
 	"github.com/vito/bass/pkg/proto"
@@ -38,6 +39,15 @@ func FromProto(val *proto.Value) (Value, error) {
 		return Bool(x.Bool.Value), nil
 	case *proto.Value_Int:
 		return Int(x.Int.Value), nil
+	case *proto.Value_Float:
+		return Float(x.Float.Value), nil
+	case *proto.Value_BigInt:
+		i, ok := new(big.Int).SetString(x.BigInt.Value, 10)
+		if !ok {
+			return nil, fmt.Errorf("unmarshal big int: invalid integer: %q", x.BigInt.Value)
+		}
+
+		return NewBigInt(i), nil
 	case *proto.Value_String_:
 		return String(x.String_.Value), nil
 	case *proto.Value_Secret:
@@ -134,6 +144,14 @@ func (value Int) MarshalProto() (proto.Message, error) {
 	return &proto.Int{Value: int64(value)}, nil
 }
 
+func (value Float) MarshalProto() (proto.Message, error) {
+	return &proto.Float{Value: float64(value)}, nil
+}
+
+func (value BigInt) MarshalProto() (proto.Message, error) {
+	return &proto.BigInt{Value: value.int.String()}, nil
+}
+
 func (value String) MarshalProto() (proto.Message, error) {
 	return &proto.String{Value: string(value)}, nil
 }
@@ -354,6 +372,15 @@ func (value Thunk) MarshalProto() (proto.Message, error) {
 		thunk.Stdin = append(thunk.Stdin, pv)
 	}
 
+	if value.StdinFile != nil {
+		sf, err := value.StdinFile.MarshalProto()
+		if err != nil {
+			return nil, fmt.Errorf("stdin file: %w", err)
+		}
+
+		thunk.StdinFile = sf.(*proto.ThunkMountSource)
+	}
+
 	if value.Env != nil {
 		err := value.Env.Each(func(sym Symbol, val Value) error {
 			pv, err := MarshalProto(val)
@@ -442,7 +469,8 @@ func (value ThunkPath) MarshalProto() (proto.Message, error) {
 	}
 
 	pv := &proto.ThunkPath{
-		Thunk: t.(*proto.Thunk),
+		Thunk:  t.(*proto.Thunk),
+		Stdout: value.Stdout,
 	}
 
 	pathp, err := value.Path.MarshalProto()
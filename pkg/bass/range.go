@@ -38,8 +38,17 @@ func (inner Range) IsWithin(outer Range) bool {
 	return true
 }
 
+// String renders the range with its file's original path if it is known to
+// SourceMaps.
 func (r Range) String() string {
-	return fmt.Sprintf("%s:%d:%d..%d:%d", r.File, r.Start.Ln, r.Start.Col, r.End.Ln, r.End.Col)
+	var file any = r.File
+	if r.File != nil {
+		if origin, found := SourceMaps.Origin(r.File); found {
+			file = origin
+		}
+	}
+
+	return fmt.Sprintf("%s:%d:%d..%d:%d", file, r.Start.Ln, r.Start.Col, r.End.Ln, r.End.Col)
 }
 
 func (r *Range) FromMeta(meta *Scope) error {
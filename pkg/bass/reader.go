@@ -7,6 +7,7 @@ import (
 	"io"
 	"strconv"
 	"strings"
+	"unicode"
 
 	slurpcore "github.com/spy16/slurp/core"
 	"github.com/spy16/slurp/reader"
@@ -20,8 +21,14 @@ type Reader struct {
 
 	Analyzer FormAnalyzer
 	Context  context.Context
+
+	depth int
 }
 
+// MaxReadDepth is the deepest a form may be nested before the reader gives up,
+// rather than exhausting the stack.
+const MaxReadDepth = 10000
+
 type FormAnalyzer interface {
 	Analyze(context.Context, Annotate)
 }
@@ -42,17 +49,24 @@ var (
 		'\\': '\\',
 		't':  '\t',
 		'a':  '\a',
-		'f':  '\a',
+		'f':  '\f',
 		'r':  '\r',
 		'b':  '\b',
 		'v':  '\v',
 	}
+
+	// hexEscapes maps each hex escape to the number of digits it takes.
+	hexEscapes = map[rune]int{
+		'x': 2,
+		'u': 4,
+		'U': 8,
+	}
 )
 
 func NewReader(src io.Reader, file Readable) *Reader {
 	r := slurpreader.New(
 		src,
-		slurpreader.WithNumReader(readInt),
+		slurpreader.WithNumReader(readNumber),
 		slurpreader.WithSymbolReader(readSymbol),
 	)
 
@@ -65,6 +79,7 @@ func NewReader(src io.Reader, file Readable) *Reader {
 	}
 
 	r.SetMacro('"', false, readString)
+	r.SetMacro('\\', false, readCharacter)
 	r.SetMacro('(', false, reader.readList)
 	r.SetMacro(')', false, slurpreader.UnmatchedDelimiter())
 	r.SetMacro('[', false, reader.readConsList)
@@ -74,6 +89,7 @@ func NewReader(src io.Reader, file Readable) *Reader {
 	r.SetMacro(';', false, reader.readCommented)
 	r.SetMacro('^', false, reader.readMeta)
 	r.SetMacro('!', true, readShebang)
+	r.SetMacro('"', true, readRegexp)
 	r.SetMacro('\'', false, nil)
 	r.SetMacro('~', false, nil)
 	r.SetMacro('`', false, nil)
@@ -102,6 +118,17 @@ func (reader *Reader) loc(start, end reader.Position) Range {
 func (reader *Reader) readAnnotate() (Annotate, error) {
 	rd := reader.rd
 
+	reader.depth++
+	defer func() { reader.depth-- }()
+
+	if reader.depth > MaxReadDepth {
+		return Annotate{}, slurpreader.Error{
+			Cause: ErrTooDeep,
+			Begin: rd.Position(),
+			End:   rd.Position(),
+		}
+	}
+
 	if err := rd.SkipSpaces(); err != nil {
 		return Annotate{}, err
 	}
@@ -196,7 +223,7 @@ func readSymbol(rd *slurpreader.Reader, init rune) (slurpcore.Any, error) {
 func readKeywordsOrJustSymbol(s string) (Value, error) {
 	kwSegments := strings.Split(s, ":")
 	if len(kwSegments) == 1 {
-		return Symbol(s), nil
+		return SymbolValue(s), nil
 	}
 
 	val, err := readKeywords(kwSegments)
@@ -210,6 +237,16 @@ func readKeywordsOrJustSymbol(s string) (Value, error) {
 func readKeywords(segments []string) (Value, error) {
 	start := segments[0]
 
+	if start == string(pairDelim) {
+		return nil, fmt.Errorf("cannot access keyword of %s", pairDelim)
+	}
+
+	for _, seg := range segments[1:] {
+		if seg == "" {
+			return nil, fmt.Errorf("empty keyword")
+		}
+	}
+
 	begin := 1
 
 	var val Value
@@ -219,7 +256,7 @@ func readKeywords(segments []string) (Value, error) {
 		val = Keyword(segments[1])
 		begin++
 	} else {
-		val = Symbol(start)
+		val = SymbolValue(start)
 	}
 
 	for i := begin; i <= len(segments)-1; i++ {
@@ -257,6 +294,10 @@ func readPath(segments []string) (Value, error) {
 	}
 
 	for i := 1; i <= end; i++ {
+		if segments[i] == "" {
+			return nil, fmt.Errorf("empty path segment")
+		}
+
 		var child FilesystemPath
 		if i == end && !isDir {
 			child = FilePath{
@@ -277,7 +318,7 @@ func readPath(segments []string) (Value, error) {
 	return path, nil
 }
 
-func readInt(rd *slurpreader.Reader, init rune) (slurpcore.Any, error) {
+func readNumber(rd *slurpreader.Reader, init rune) (slurpcore.Any, error) {
 	beginPos := rd.Position()
 
 	numStr, err := rd.Token(init)
@@ -285,12 +326,12 @@ func readInt(rd *slurpreader.Reader, init rune) (slurpcore.Any, error) {
 		return nil, err
 	}
 
-	v, err := strconv.ParseInt(numStr, 0, 64)
+	num, err := ParseNumber(numStr)
 	if err != nil {
 		return nil, annotateErr(rd, slurpreader.ErrNumberFormat, beginPos, numStr)
 	}
 
-	return Int(v), nil
+	return num, nil
 }
 
 func readString(rd *slurpreader.Reader, init rune) (slurpcore.Any, error) {
@@ -316,7 +357,14 @@ func readString(rd *slurpreader.Reader, init rune) (slurpcore.Any, error) {
 				return nil, annotateErr(rd, err, beginPos, string(init)+b.String())
 			}
 
-			// TODO: Support for Unicode escape \uNN format.
+			if width, isHex := hexEscapes[r2]; isHex {
+				err := readHexEscape(rd, &b, r2, width)
+				if err != nil {
+					return nil, annotateErr(rd, err, beginPos, string(init)+b.String())
+				}
+
+				continue
+			}
 
 			escaped, err := getEscape(r2)
 			if err != nil {
@@ -333,6 +381,47 @@ func readString(rd *slurpreader.Reader, init rune) (slurpcore.Any, error) {
 	return String(b.String()), nil
 }
 
+// readHexEscape reads the digits of a \x, \u, or \U escape sequence. A \x
+// escape is a single byte, which need not be valid UTF-8 on its own.
+func readHexEscape(rd *slurpreader.Reader, b *strings.Builder, kind rune, width int) error {
+	digits := make([]rune, width)
+	for i := range digits {
+		r, err := rd.NextRune()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = slurpreader.ErrEOF
+			}
+
+			return err
+		}
+
+		digits[i] = r
+	}
+
+	code, err := strconv.ParseUint(string(digits), 16, 32)
+	if err != nil {
+		return fmt.Errorf("illegal escape sequence '\\%c%s'", kind, string(digits))
+	}
+
+	if kind == 'x' {
+		b.WriteByte(byte(code))
+		return nil
+	}
+
+	if code > unicode.MaxRune || (code >= 0xD800 && code < 0xE000) {
+		return fmt.Errorf("illegal escape sequence '\\%c%s': invalid code point", kind, string(digits))
+	}
+
+	b.WriteRune(rune(code))
+
+	return nil
+}
+
+func readCharacter(rd *slurpreader.Reader, init rune) (slurpcore.Any, error) {
+	beginPos := rd.Position()
+	return nil, annotateErr(rd, fmt.Errorf("%w: character literals are not supported", ErrBadSyntax), beginPos, string(init))
+}
+
 func getEscape(r rune) (rune, error) {
 	escaped, found := escapeMap[r]
 	if !found {
@@ -398,6 +487,10 @@ func (reader *Reader) readList(rd *slurpreader.Reader, _ rune) (slurpcore.Any, e
 		return nil, err
 	}
 
+	if len(vals) > 0 {
+		vals[0] = callPositionSymbol(vals[0])
+	}
+
 	for i := len(vals) - 1; i >= 0; i-- {
 		list = Pair{
 			A: vals[i],
@@ -408,6 +501,22 @@ func (reader *Reader) readList(rd *slurpreader.Reader, _ rune) (slurpcore.Any, e
 	return list, nil
 }
 
+// callPositionSymbol reads a lone slash at the head of a list as the symbol
+// bound to division. Everywhere else it is the root directory.
+func callPositionSymbol(val Value) Value {
+	var ann Annotate
+	if err := val.Decode(&ann); err == nil {
+		ann.Value = callPositionSymbol(ann.Value)
+		return ann
+	}
+
+	if val.Equal(DirPath{}) {
+		return Symbol("/")
+	}
+
+	return val
+}
+
 func (reader *Reader) container(end rune, _ string, f func(slurpcore.Any) error) error {
 	rd := reader.rd
 
@@ -437,6 +546,9 @@ func (reader *Reader) container(end rune, _ string, f func(slurpcore.Any) error)
 			if err == slurpreader.ErrSkip {
 				continue
 			}
+			if err == io.EOF {
+				return slurpreader.Error{Cause: slurpreader.ErrEOF}
+			}
 			return err
 		}
 
@@ -532,11 +644,19 @@ func desugarMeta(v Value) (Bind, error) {
 func (reader *Reader) readMeta(rd *slurpreader.Reader, _ rune) (slurpcore.Any, error) {
 	metaForm, err := reader.readAnnotate()
 	if err != nil {
+		if err == io.EOF {
+			err = slurpreader.ErrEOF
+		}
+
 		return nil, err
 	}
 
 	form, err := reader.readAnnotate()
 	if err != nil {
+		if err == io.EOF {
+			err = slurpreader.ErrEOF
+		}
+
 		return nil, err
 	}
 
@@ -637,6 +757,57 @@ func readCommentedLine(rd *slurpreader.Reader) (string, error) {
 	return line, nil
 }
 
+// readRegexp reads a #"pattern" literal, compiling it so that bad patterns
+// are reported when read.
+//
+// Unlike strings, escapes are left for the regexp to interpret, except for
+// \" which escapes a quote.
+func readRegexp(rd *slurpreader.Reader, _ rune) (slurpcore.Any, error) {
+	beginPos := rd.Position()
+
+	var b strings.Builder
+	for {
+		r, err := rd.NextRune()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = slurpreader.ErrEOF
+			}
+
+			return nil, annotateErr(rd, err, beginPos, `#"`+b.String())
+		}
+
+		if r == '"' {
+			break
+		}
+
+		if r == '\\' {
+			r2, err := rd.NextRune()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					err = slurpreader.ErrEOF
+				}
+
+				return nil, annotateErr(rd, err, beginPos, `#"`+b.String())
+			}
+
+			if r2 != '"' {
+				b.WriteRune(r)
+			}
+
+			r = r2
+		}
+
+		b.WriteRune(r)
+	}
+
+	re, err := CompileRegexp(b.String())
+	if err != nil {
+		return nil, annotateErr(rd, err, beginPos, `#"`+b.String()+`"`)
+	}
+
+	return re, nil
+}
+
 func readShebang(rd *slurpreader.Reader, _ rune) (slurpcore.Any, error) {
 	for {
 		r, err := rd.NextRune()
@@ -3,8 +3,12 @@ package bass_test
 import (
 	"bytes"
 	"errors"
+	"math"
+	"math/big"
+	"strings"
 	"testing"
 
+	slurpreader "github.com/spy16/slurp/reader"
 	"github.com/vito/bass/pkg/bass"
 	. "github.com/vito/bass/pkg/basstest"
 	"github.com/vito/is"
@@ -38,6 +42,46 @@ func TestReader(t *testing.T) {
 			Source: "42",
 			Result: bass.Int(42),
 		},
+		{
+			Source: "-42",
+			Result: bass.Int(-42),
+		},
+		{
+			Source: "3.14",
+			Result: bass.Float(3.14),
+		},
+		{
+			Source: "-2.5e3",
+			Result: bass.Float(-2500),
+		},
+		{
+			Source: "92233720368547758070",
+			Result: bass.NewBigInt(new(big.Int).Mul(big.NewInt(math.MaxInt64), big.NewInt(10))),
+		},
+		{
+			Source: "0x1F",
+			Result: bass.Int(31),
+		},
+		{
+			Source: "0o755",
+			Result: bass.Int(0755),
+		},
+		{
+			Source: "0b1010",
+			Result: bass.Int(10),
+		},
+		{
+			Source: "-0xff",
+			Result: bass.Int(-255),
+		},
+		{
+			Source: "0x10000000000000000",
+			Result: bass.NewBigInt(new(big.Int).Lsh(big.NewInt(1), 64)),
+		},
+		{
+			Source: "0b102",
+			Err:    slurpreader.ErrNumberFormat,
+		},
 
 		{
 			Source: "hello",
@@ -61,7 +105,12 @@ func TestReader(t *testing.T) {
 
 		{
 			Source: `"hello \"\n\\\t\a\f\r\b\v"`,
-			Result: bass.String("hello \"\n\\\t\a\a\r\b\v"),
+			Result: bass.String("hello \"\n\\\t\a\f\r\b\v"),
+		},
+
+		{
+			Source: `"\x00\xff\u00e9\U0001F600"`,
+			Result: bass.String("\x00\xff\u00e9\U0001F600"),
 		},
 
 		{
@@ -243,6 +292,22 @@ func TestReader(t *testing.T) {
 				},
 			},
 		},
+		{
+			Source: "/",
+			Result: bass.DirPath{},
+		},
+		{
+			Source: "(/ 84 2)",
+			Result: bass.NewList(bass.Symbol("/"), bass.Int(84), bass.Int(2)),
+		},
+		{
+			Source: "(list / 2)",
+			Result: bass.NewList(bass.Symbol("list"), bass.DirPath{}, bass.Int(2)),
+		},
+		{
+			Source: "[/ 2]",
+			Result: bass.NewConsList(bass.DirPath{}, bass.Int(2)),
+		},
 		{
 			Source: "/absolute/path",
 			Result: bass.ExtendPath{
@@ -295,6 +360,11 @@ func TestReader(t *testing.T) {
 			Result: bass.Int(42),
 		},
 
+		{
+			Source: `#"^v(\d+)\.\"(\\d)"`,
+			Result: mustCompileRegexp(`^v(\d+)\."(\\d)`),
+		},
+
 		// quote, syntax-quote, and unquote are not special forms
 		{
 			Source: `'`,
@@ -480,6 +550,26 @@ func TestReaderMeta(t *testing.T) {
 	}
 }
 
+func TestReaderMaxDepth(t *testing.T) {
+	is := is.New(t)
+
+	for _, src := range []string{
+		strings.Repeat("(", bass.MaxReadDepth+1),
+		strings.Repeat("^:a ", bass.MaxReadDepth+1) + "1",
+	} {
+		reader := bass.NewReader(bytes.NewBufferString(src), bass.NewInMemoryFile("test", src))
+
+		_, err := reader.Next()
+		is.True(errors.Is(err, bass.ErrTooDeep))
+	}
+
+	src := strings.Repeat("(", bass.MaxReadDepth-1) + strings.Repeat(")", bass.MaxReadDepth-1)
+	reader := bass.NewReader(bytes.NewBufferString(src), bass.NewInMemoryFile("test", src))
+
+	_, err := reader.Next()
+	is.NoErr(err)
+}
+
 func (example ReaderExample) Run(t *testing.T) {
 	t.Run(example.Source, func(t *testing.T) {
 		is := is.New(t)
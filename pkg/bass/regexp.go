@@ -0,0 +1,135 @@
+package bass
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// Regexp is a compiled regular expression, using Go's RE2 syntax.
+//
+// Regexps are written as #"pattern", and are compiled when read so that bad
+// patterns are reported before the code runs. Backslashes in the pattern are
+// taken literally, except \" which escapes a quote.
+type Regexp struct {
+	*regexp.Regexp
+}
+
+// CompileRegexp compiles the pattern into a Regexp.
+func CompileRegexp(pattern string) (Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return Regexp{}, err
+	}
+
+	return Regexp{re}, nil
+}
+
+func init() {
+	Ground.MustSet("re-pattern",
+		Func("re-pattern", "[pattern]", CompileRegexp),
+		`compiles a string into a regexp`,
+		`Use this for patterns built at runtime; otherwise prefer a #"pattern" literal, which is checked when read.`,
+		`=> (re-pattern (str "^" "v[0-9]+"))`)
+
+	Ground.MustSet("re-match?",
+		Func("re-match?", "[re str]", func(re Regexp, str string) bool {
+			return re.MatchString(str)
+		}),
+		`returns true if the regexp matches anywhere in the string`,
+		`Anchor the regexp with ^ and $ to match the whole string.`,
+		`=> (re-match? #"^v[0-9]+" "v1.2.3")`)
+
+	Ground.MustSet("re-find",
+		Func("re-find", "[re str]", func(re Regexp, str string) Value {
+			loc := re.FindStringIndex(str)
+			if loc == nil {
+				return Null{}
+			}
+
+			return String(str[loc[0]:loc[1]])
+		}),
+		`returns the first match of the regexp in the string, or null`,
+		`=> (re-find #"[0-9]+" "build-1234")`)
+
+	Ground.MustSet("re-groups",
+		Func("re-groups", "[re str]", func(re Regexp, str string) Value {
+			loc := re.FindStringSubmatchIndex(str)
+			if loc == nil {
+				return Null{}
+			}
+
+			groups := make([]Value, len(loc)/2)
+			for i := range groups {
+				start, end := loc[2*i], loc[2*i+1]
+				if start == -1 {
+					// optional group which did not participate
+					groups[i] = Null{}
+				} else {
+					groups[i] = String(str[start:end])
+				}
+			}
+
+			return NewList(groups...)
+		}),
+		`returns the first match of the regexp in the string along with its groups, or null`,
+		`The first element is the entire match, followed by each group. Groups which did not participate in the match are null.`,
+		`=> (re-groups #"v([0-9]+)\.([0-9]+)" "release v1.22")`)
+
+	Ground.MustSet("re-replace",
+		Func("re-replace", "[re str replacement]", func(re Regexp, str, replacement string) String {
+			return String(re.ReplaceAllString(str, replacement))
+		}),
+		`replaces every match of the regexp in the string`,
+		`The replacement may refer to groups as $1 or ${1}, and to named groups as ${name}.`,
+		`=> (re-replace #"[^a-z0-9]+" "feature/add widgets" "-")`,
+		`=> (re-replace #"(\w+)@(\w+)" "user@host" "$2:$1")`)
+}
+
+var _ Value = Regexp{}
+
+func (value Regexp) String() string {
+	var b strings.Builder
+	b.WriteString(`#"`)
+
+	escaped := false
+	for _, r := range value.Regexp.String() {
+		if r == '"' && !escaped {
+			b.WriteRune('\\')
+		}
+
+		b.WriteRune(r)
+
+		escaped = r == '\\' && !escaped
+	}
+
+	b.WriteRune('"')
+
+	return b.String()
+}
+
+func (value Regexp) Equal(other Value) bool {
+	var o Regexp
+	return other.Decode(&o) == nil && value.Regexp.String() == o.Regexp.String()
+}
+
+func (value Regexp) Decode(dest any) error {
+	switch x := dest.(type) {
+	case *Regexp:
+		*x = value
+		return nil
+	case *Value:
+		*x = value
+		return nil
+	default:
+		return DecodeError{
+			Source:      value,
+			Destination: dest,
+		}
+	}
+}
+
+// Eval returns the value.
+func (value Regexp) Eval(_ context.Context, _ *Scope, cont Cont) ReadyCont {
+	return cont.Call(value, nil)
+}
@@ -0,0 +1,106 @@
+package bass_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	. "github.com/vito/bass/pkg/basstest"
+	"github.com/vito/is"
+)
+
+func mustCompileRegexp(pattern string) bass.Regexp {
+	re, err := bass.CompileRegexp(pattern)
+	if err != nil {
+		panic(err)
+	}
+
+	return re
+}
+
+func TestRegexpString(t *testing.T) {
+	is := is.New(t)
+
+	for _, pattern := range []string{
+		`^v[0-9]+$`,
+		`a"b`,
+		`a\"b`,
+		`\\`,
+		`\\"`,
+	} {
+		re := mustCompileRegexp(pattern)
+
+		read, err := bass.EvalFSFile(context.Background(), bass.NewStandardScope(), bass.NewInMemoryFile("test", re.String()))
+		is.NoErr(err)
+
+		var readRe bass.Regexp
+		is.NoErr(read.Decode(&readRe))
+
+		// escaped quotes read back as quotes, which match the same strings
+		is.True(readRe.MatchString(`a"b`) == re.MatchString(`a"b`))
+		is.True(readRe.MatchString(`\"`) == re.MatchString(`\"`))
+	}
+}
+
+func TestRegexpEqual(t *testing.T) {
+	is := is.New(t)
+
+	Equal(t, mustCompileRegexp(`a+`), mustCompileRegexp(`a+`))
+	Equal(t, mustCompileRegexp(`a+`), wrappedValue{mustCompileRegexp(`a+`)})
+	is.True(!mustCompileRegexp(`a+`).Equal(mustCompileRegexp(`a*`)))
+	is.True(!mustCompileRegexp(`a+`).Equal(bass.String("a+")))
+}
+
+func TestRegexpReadError(t *testing.T) {
+	is := is.New(t)
+
+	_, err := bass.EvalFSFile(context.Background(), bass.NewStandardScope(), bass.NewInMemoryFile("test", `(def pat #"v(")`))
+	is.True(err != nil)
+	is.True(strings.Contains(err.Error(), "missing closing )"))
+}
+
+func TestGroundRegexp(t *testing.T) {
+	for _, example := range []BasicExample{
+		{
+			Name:   "re-pattern",
+			Bass:   `(re-pattern (str "^" "v[0-9]+"))`,
+			Result: mustCompileRegexp(`^v[0-9]+`),
+		},
+		{
+			Name:        "re-pattern invalid",
+			Bass:        `(re-pattern "v(")`,
+			ErrContains: "missing closing )",
+		},
+		{
+			Name:   "re-match?",
+			Bass:   `[(re-match? #"^v[0-9]+" "v1.2.3") (re-match? #"^v[0-9]+" "1.2.3") (re-match? #"[0-9]" "v1")]`,
+			Result: bass.NewList(bass.Bool(true), bass.Bool(false), bass.Bool(true)),
+		},
+		{
+			Name:   "re-find",
+			Bass:   `[(re-find #"[0-9]+" "build-1234-5") (re-find #"[0-9]+" "build")]`,
+			Result: bass.NewList(bass.String("1234"), bass.Null{}),
+		},
+		{
+			Name: "re-groups",
+			Bass: `[(re-groups #"v(\d+)\.(\d+)(-rc)?" "release v1.22") (re-groups #"v(\d+)" "none")]`,
+			Result: bass.NewList(
+				bass.NewList(bass.String("v1.22"), bass.String("1"), bass.String("22"), bass.Null{}),
+				bass.Null{},
+			),
+		},
+		{
+			Name:   "re-replace",
+			Bass:   `[(re-replace #"[^a-z0-9]+" "feature/add widgets" "-") (re-replace #"(\w+)@(\w+)" "user@host" "$2:$1")]`,
+			Result: bass.NewList(bass.String("feature-add-widgets"), bass.String("host:user")),
+		},
+		{
+			Name:        "string pattern",
+			Bass:        `(re-match? "^v" "v1")`,
+			ErrContains: "cannot decode",
+		},
+	} {
+		t.Run(example.Name, example.Run)
+	}
+}
@@ -0,0 +1,250 @@
+package bass
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// RemoteScriptScheme is the prefix of a reference to a script in an OCI
+// image.
+const RemoteScriptScheme = "oci://"
+
+// ErrNoScriptFetcher is returned when running a remote script without a
+// ScriptFetcher in the context.
+var ErrNoScriptFetcher = errors.New("remote scripts are not supported")
+
+// RemoteScript refers to a script within an OCI image, written as
+// oci://<repository>[:<tag>|@<digest>]#<path>, e.g.
+// oci://ghcr.io/acme/pipelines:v1#/ci/main.bass.
+//
+// Sibling files in the script's directory, and its subdirectories, are
+// fetched along with it, so that it can load other modules from *dir*.
+type RemoteScript struct {
+	// Repository is the image repository, e.g. ghcr.io/acme/pipelines.
+	Repository string
+
+	// Tag is the image tag, if Digest is not set.
+	Tag string
+
+	// Digest pins the image to exact content.
+	Digest string
+
+	// Path is the absolute slash-separated path of the script in the image.
+	Path string
+}
+
+// IsRemoteScript returns true if the string refers to a RemoteScript.
+func IsRemoteScript(str string) bool {
+	return strings.HasPrefix(str, RemoteScriptScheme)
+}
+
+// ParseRemoteScript parses an oci:// reference to a script. The tag defaults
+// to latest.
+func ParseRemoteScript(str string) (RemoteScript, error) {
+	if !IsRemoteScript(str) {
+		return RemoteScript{}, fmt.Errorf("remote script must start with %s: %s", RemoteScriptScheme, str)
+	}
+
+	ref, scriptPath, found := strings.Cut(strings.TrimPrefix(str, RemoteScriptScheme), "#")
+	if !found || !strings.HasPrefix(scriptPath, "/") || strings.HasSuffix(scriptPath, "/") {
+		return RemoteScript{}, fmt.Errorf("remote script must end with #/path/to/script%s: %s", Ext, str)
+	}
+
+	script := RemoteScript{
+		Path: path.Clean(scriptPath),
+	}
+
+	if repo, dig, found := strings.Cut(ref, "@"); found {
+		parsed, err := digest.Parse(dig)
+		if err != nil {
+			return RemoteScript{}, fmt.Errorf("remote script %s: %w", str, err)
+		}
+
+		script.Repository = repo
+		script.Digest = parsed.String()
+	} else if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		script.Repository = ref[:i]
+		script.Tag = ref[i+1:]
+	} else {
+		script.Repository = ref
+		script.Tag = "latest"
+	}
+
+	if script.Repository == "" {
+		return RemoteScript{}, fmt.Errorf("remote script has no repository: %s", str)
+	}
+
+	return script, nil
+}
+
+// Ref returns the image reference, pinned to the digest if it has one.
+func (script RemoteScript) Ref() string {
+	if script.Digest != "" {
+		return script.Repository + "@" + script.Digest
+	}
+
+	return script.Repository + ":" + script.Tag
+}
+
+func (script RemoteScript) String() string {
+	return RemoteScriptScheme + script.Ref() + "#" + script.Path
+}
+
+// RemoteScriptsDir is the directory where remote scripts are extracted by
+// their ScriptFetcher.
+func RemoteScriptsDir() string {
+	return filepath.Join(CacheHome, "scripts")
+}
+
+// ScriptFetcher fetches remote scripts from their images.
+type ScriptFetcher interface {
+	// ResolveScript returns the digest of the script's image.
+	ResolveScript(context.Context, RemoteScript) (string, error)
+
+	// FetchScript fetches the script's directory from its image, which is
+	// pinned to a digest, returning the host path of the script.
+	FetchScript(context.Context, RemoteScript) (string, error)
+}
+
+type scriptFetcherKey struct{}
+
+// WithScriptFetcher sets the fetcher used for remote scripts within the
+// returned context.
+func WithScriptFetcher(ctx context.Context, fetcher ScriptFetcher) context.Context {
+	return context.WithValue(ctx, scriptFetcherKey{}, fetcher)
+}
+
+// ScriptFetcherFromContext returns the fetcher set by WithScriptFetcher.
+func ScriptFetcherFromContext(ctx context.Context) (ScriptFetcher, bool) {
+	fetcher, ok := ctx.Value(scriptFetcherKey{}).(ScriptFetcher)
+	return fetcher, ok
+}
+
+// remoteScriptMemoThunk is the module under which pinned digests are stored
+// in Memos.
+var remoteScriptMemoThunk = internalMemo("run-script", true)
+
+const remoteScriptMemoBinding Symbol = "pin"
+
+// PinRemoteScript pins the script's image to a digest.
+//
+// If memos is non-nil, the digest a tag resolves to is recorded the first
+// time, e.g. in a bass.lock file, and reused from then on, so that the
+// script only changes when the record is removed or a digest is given
+// explicitly.
+func PinRemoteScript(ctx context.Context, script RemoteScript, memos Memos) (RemoteScript, error) {
+	if script.Digest != "" {
+		return script, nil
+	}
+
+	fetcher, found := ScriptFetcherFromContext(ctx)
+	if !found {
+		return RemoteScript{}, ErrNoScriptFetcher
+	}
+
+	input := String(script.Ref())
+
+	if memos != nil {
+		pinned, found, err := memos.Retrieve(remoteScriptMemoThunk, remoteScriptMemoBinding, input)
+		if err != nil {
+			return RemoteScript{}, fmt.Errorf("retrieve pin of %s: %w", input, err)
+		}
+
+		var dig string
+		if found && pinned.Decode(&dig) == nil {
+			script.Digest = dig
+			return script, nil
+		}
+	}
+
+	dig, err := fetcher.ResolveScript(ctx, script)
+	if err != nil {
+		return RemoteScript{}, fmt.Errorf("resolve %s: %w", script, err)
+	}
+
+	if memos != nil {
+		err := memos.Store(remoteScriptMemoThunk, remoteScriptMemoBinding, input, String(dig))
+		if err != nil {
+			return RemoteScript{}, fmt.Errorf("store pin of %s: %w", input, err)
+		}
+	}
+
+	script.Digest = dig
+
+	return script, nil
+}
+
+// FetchRemoteScript pins the script with PinRemoteScript and fetches it,
+// returning the host path of the script.
+func FetchRemoteScript(ctx context.Context, script RemoteScript, memos Memos) (string, error) {
+	fetcher, found := ScriptFetcherFromContext(ctx)
+	if !found {
+		return "", ErrNoScriptFetcher
+	}
+
+	pinned, err := PinRemoteScript(ctx, script, memos)
+	if err != nil {
+		return "", err
+	}
+
+	scriptPath, err := fetcher.FetchScript(ctx, pinned)
+	if err != nil {
+		return "", fmt.Errorf("fetch %s: %w", pinned, err)
+	}
+
+	return scriptPath, nil
+}
+
+// scriptCmd returns the command for running a script, fetching it first if
+// it is a remote script.
+//
+// The returned context records the script's dependencies under the remote
+// script.
+func scriptCmd(ctx context.Context, scope *Scope, script Value) (context.Context, ThunkCmd, error) {
+	var str string
+	if err := script.Decode(&str); err != nil || !IsRemoteScript(str) {
+		var cmd ThunkCmd
+		if err := script.Decode(&cmd); err != nil {
+			return nil, ThunkCmd{}, err
+		}
+
+		return ctx, cmd, nil
+	}
+
+	remote, err := ParseRemoteScript(str)
+	if err != nil {
+		return nil, ThunkCmd{}, err
+	}
+
+	var memos Memos
+	var dir HostPath
+	if err := scope.GetDecode(RunBindingDir, &dir); err == nil {
+		memos = NewLockfileMemo(filepath.Join(dir.FromSlash(), "bass.lock"))
+	}
+
+	pinned, err := PinRemoteScript(ctx, remote, memos)
+	if err != nil {
+		return nil, ThunkCmd{}, err
+	}
+
+	scriptPath, err := FetchRemoteScript(ctx, pinned, nil)
+	if err != nil {
+		return nil, ThunkCmd{}, err
+	}
+
+	ctx = RecordDep(ctx, Dep{
+		Kind:   DepRemoteScript,
+		Name:   remote.String(),
+		Digest: pinned.Digest,
+	})
+
+	host := NewHostPath(filepath.Dir(scriptPath), ParseFileOrDirPath(filepath.Base(scriptPath)))
+
+	return ctx, ThunkCmd{Host: &host}, nil
+}
@@ -0,0 +1,150 @@
+package bass_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	. "github.com/vito/bass/pkg/basstest"
+	"github.com/vito/is"
+)
+
+func TestParseRemoteScript(t *testing.T) {
+	for _, example := range []struct {
+		Ref    string
+		Script bass.RemoteScript
+		Err    bool
+	}{
+		{
+			Ref: "oci://ghcr.io/acme/pipelines:v1#/ci/main.bass",
+			Script: bass.RemoteScript{
+				Repository: "ghcr.io/acme/pipelines",
+				Tag:        "v1",
+				Path:       "/ci/main.bass",
+			},
+		},
+		{
+			Ref: "oci://localhost:5000/pipelines#/main.bass",
+			Script: bass.RemoteScript{
+				Repository: "localhost:5000/pipelines",
+				Tag:        "latest",
+				Path:       "/main.bass",
+			},
+		},
+		{
+			Ref: "oci://acme/pipelines@sha256:6c3c624b58dbbcd3c0dd82b4c53f04194d1247c6eebdaab7c610cf7d66709b3b#/ci/../ci/main.bass",
+			Script: bass.RemoteScript{
+				Repository: "acme/pipelines",
+				Digest:     "sha256:6c3c624b58dbbcd3c0dd82b4c53f04194d1247c6eebdaab7c610cf7d66709b3b",
+				Path:       "/ci/main.bass",
+			},
+		},
+		{Ref: "oci://acme/pipelines", Err: true},
+		{Ref: "oci://acme/pipelines#ci/main.bass", Err: true},
+		{Ref: "oci://acme/pipelines#/ci/", Err: true},
+		{Ref: "oci://acme/pipelines@sha256:bogus#/main.bass", Err: true},
+		{Ref: "oci://#/main.bass", Err: true},
+		{Ref: "./main.bass", Err: true},
+	} {
+		t.Run(example.Ref, func(t *testing.T) {
+			is := is.New(t)
+
+			script, err := bass.ParseRemoteScript(example.Ref)
+			if example.Err {
+				is.True(err != nil)
+				return
+			}
+
+			is.NoErr(err)
+			is.Equal(script, example.Script)
+		})
+	}
+}
+
+type fakeScriptFetcher struct {
+	digest  string
+	scripts map[string]string
+	fetched []bass.RemoteScript
+}
+
+func (fetcher *fakeScriptFetcher) ResolveScript(ctx context.Context, script bass.RemoteScript) (string, error) {
+	return fetcher.digest, nil
+}
+
+func (fetcher *fakeScriptFetcher) FetchScript(ctx context.Context, script bass.RemoteScript) (string, error) {
+	fetcher.fetched = append(fetcher.fetched, script)
+	return fetcher.scripts[script.Digest], nil
+}
+
+func TestRemoteScript(t *testing.T) {
+	is := is.New(t)
+
+	dir := t.TempDir()
+
+	v1 := filepath.Join(dir, "v1", "main.bass")
+	is.NoErr(os.MkdirAll(filepath.Dir(v1), 0755))
+	is.NoErr(os.WriteFile(v1, []byte(`(defn main [x] [:v1 x])`), 0644))
+
+	v2 := filepath.Join(dir, "v2", "main.bass")
+	is.NoErr(os.MkdirAll(filepath.Dir(v2), 0755))
+	is.NoErr(os.WriteFile(v2, []byte(`(defn main [x] [:v2 x])`), 0644))
+
+	digest1 := "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	digest2 := "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+
+	fetcher := &fakeScriptFetcher{
+		digest: digest1,
+		scripts: map[string]string{
+			digest1: v1,
+			digest2: v2,
+		},
+	}
+
+	ctx := bass.WithScriptFetcher(context.Background(), fetcher)
+
+	deps := bass.NewDepGraph()
+	ctx = bass.WithDepGraph(ctx, deps)
+
+	scope := bass.NewRunScope(bass.NewStandardScope(), bass.RunState{
+		Dir: bass.NewHostDir(dir),
+	})
+
+	eval := func(src string) bass.Value {
+		res, err := bass.EvalFSFile(ctx, scope, bass.NewInMemoryFile("test", src))
+		is.NoErr(err)
+		return res
+	}
+
+	Equal(t, eval(`(run-script "oci://acme/ci:v1#/ci/main.bass" 42)`), bass.NewList(bass.Symbol("v1"), bass.Int(42)))
+
+	_, err := os.Stat(filepath.Join(dir, "bass.lock"))
+	is.NoErr(err)
+
+	// the pin is reused even though the tag now resolves to another digest
+	fetcher.digest = digest2
+	Equal(t, eval(`(run-script "oci://acme/ci:v1#/ci/main.bass" 43)`), bass.NewList(bass.Symbol("v1"), bass.Int(43)))
+
+	// an explicit digest bypasses the pin
+	Equal(t, eval(`(run-script "oci://acme/ci@`+digest2+`#/ci/main.bass" 44)`), bass.NewList(bass.Symbol("v2"), bass.Int(44)))
+
+	is.Equal(len(fetcher.fetched), 3)
+	is.Equal(fetcher.fetched[0].Digest, digest1)
+	is.Equal(fetcher.fetched[1].Digest, digest1)
+	is.Equal(fetcher.fetched[2].Digest, digest2)
+
+	is.Equal(deps.Tree(), []bass.DepTree{
+		{
+			Dep:  bass.Dep{Kind: bass.DepRemoteScript, Name: "oci://acme/ci:v1#/ci/main.bass", Digest: digest1},
+			Deps: []bass.DepTree{{Dep: bass.Dep{Kind: bass.DepModule, Name: v1}}},
+		},
+		{
+			Dep:  bass.Dep{Kind: bass.DepRemoteScript, Name: "oci://acme/ci@" + digest2 + "#/ci/main.bass", Digest: digest2},
+			Deps: []bass.DepTree{{Dep: bass.Dep{Kind: bass.DepModule, Name: v2}}},
+		},
+	})
+
+	_, err = bass.EvalFSFile(context.Background(), scope, bass.NewInMemoryFile("test", `(run-script "oci://acme/ci:v1#/ci/main.bass")`))
+	is.True(err != nil)
+}
@@ -0,0 +1,180 @@
+package bass
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ResolveCache caches the digests that image refs resolve to.
+//
+// Within a single run each ref is resolved at most once, so every thunk
+// referring to a tag like :latest sees the same image. When Memos is set,
+// resolutions are also persisted, e.g. to a bass.lock file, and reused by
+// later runs until they are older than TTL.
+type ResolveCache struct {
+	// TTL is how long a persisted resolution remains valid.
+	TTL time.Duration
+
+	// Memos optionally persists resolutions across runs.
+	Memos Memos
+
+	resolved map[string]*resolveEntry
+	mu       sync.Mutex
+}
+
+type resolveEntry struct {
+	ref  ImageRef
+	err  error
+	done chan struct{}
+}
+
+// resolveMemoThunk is the module under which resolutions are stored in
+// Memos.
+var resolveMemoThunk = internalMemo("resolve", true)
+
+const resolveMemoBinding Symbol = "resolve"
+
+// NewResolveCache returns an empty cache whose persisted resolutions expire
+// after the given TTL.
+func NewResolveCache(ttl time.Duration) *ResolveCache {
+	return &ResolveCache{
+		TTL:      ttl,
+		resolved: map[string]*resolveEntry{},
+	}
+}
+
+type resolveCacheKey struct{}
+
+// WithResolveCache sets the cache used by ResolveImage within the returned
+// context.
+func WithResolveCache(ctx context.Context, cache *ResolveCache) context.Context {
+	return context.WithValue(ctx, resolveCacheKey{}, cache)
+}
+
+// ResolveCacheFromContext returns the cache set by WithResolveCache.
+func ResolveCacheFromContext(ctx context.Context) (*ResolveCache, bool) {
+	cache, ok := ctx.Value(resolveCacheKey{}).(*ResolveCache)
+	return cache, ok
+}
+
+// ResolveImage resolves the ref using the runtime for its platform, consulting
+// the context's ResolveCache if it has one.
+func ResolveImage(ctx context.Context, ref ImageRef) (ImageRef, error) {
+	runtime, err := RuntimeFromContext(ctx, ref.Platform)
+	if err != nil {
+		return ImageRef{}, err
+	}
+
+	var resolved ImageRef
+	if cache, found := ResolveCacheFromContext(ctx); found {
+		resolved, err = cache.Resolve(ctx, ref, runtime.Resolve)
+	} else {
+		resolved, err = runtime.Resolve(ctx, ref)
+	}
+
+	if err != nil {
+		return ImageRef{}, err
+	}
+
+	recordImageDep(ctx, resolved)
+
+	return resolved, nil
+}
+
+// Resolve returns the cached resolution of the ref, calling resolve if it has
+// not been resolved yet. Concurrent calls for the same ref share a single
+// call to resolve.
+//
+// Refs which already have a digest are returned as-is.
+func (cache *ResolveCache) Resolve(ctx context.Context, ref ImageRef, resolve func(context.Context, ImageRef) (ImageRef, error)) (ImageRef, error) {
+	if ref.Digest != "" {
+		return ref, nil
+	}
+
+	key, err := resolveKey(ref)
+	if err != nil {
+		return ImageRef{}, err
+	}
+
+	cache.mu.Lock()
+	entry, found := cache.resolved[key]
+	if found {
+		cache.mu.Unlock()
+		<-entry.done
+		return entry.ref, entry.err
+	}
+
+	entry = &resolveEntry{done: make(chan struct{})}
+	cache.resolved[key] = entry
+	cache.mu.Unlock()
+
+	entry.ref, entry.err = cache.resolve(ctx, ref, resolve)
+	close(entry.done)
+
+	if entry.err != nil {
+		// don't hold on to errors; a later call may succeed
+		cache.mu.Lock()
+		delete(cache.resolved, key)
+		cache.mu.Unlock()
+	}
+
+	return entry.ref, entry.err
+}
+
+func (cache *ResolveCache) resolve(ctx context.Context, ref ImageRef, resolve func(context.Context, ImageRef) (ImageRef, error)) (ImageRef, error) {
+	if cache.Memos == nil {
+		return resolve(ctx, ref)
+	}
+
+	input, err := ValueOf(ref)
+	if err != nil {
+		return ImageRef{}, err
+	}
+
+	memo, found, err := cache.Memos.Retrieve(resolveMemoThunk, resolveMemoBinding, input)
+	if err != nil {
+		return ImageRef{}, fmt.Errorf("retrieve resolution of %s: %w", input, err)
+	}
+
+	if found {
+		var persisted struct {
+			Digest     string `json:"digest"`
+			ResolvedAt int    `json:"resolved_at"`
+		}
+
+		if err := memo.Decode(&persisted); err == nil {
+			resolvedAt := time.Unix(int64(persisted.ResolvedAt), 0)
+			if Clock.Since(resolvedAt) < cache.TTL {
+				ref.Digest = persisted.Digest
+				return ref, nil
+			}
+		}
+	}
+
+	resolved, err := resolve(ctx, ref)
+	if err != nil {
+		return ImageRef{}, err
+	}
+
+	err = cache.Memos.Store(resolveMemoThunk, resolveMemoBinding, input, Bindings{
+		"digest":      String(resolved.Digest),
+		"resolved_at": Int(Clock.Now().Unix()),
+	}.Scope())
+	if err != nil {
+		return ImageRef{}, fmt.Errorf("store resolution of %s: %w", input, err)
+	}
+
+	return resolved, nil
+}
+
+func resolveKey(ref ImageRef) (string, error) {
+	payload, err := json.Marshal(ref)
+	if err != nil {
+		return "", err
+	}
+
+	return string(payload), nil
+}
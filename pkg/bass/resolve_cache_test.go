@@ -0,0 +1,157 @@
+package bass_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/proto"
+	"github.com/vito/is"
+	"google.golang.org/protobuf/encoding/prototext"
+)
+
+type countingResolver struct {
+	calls int
+	mu    sync.Mutex
+}
+
+func (resolver *countingResolver) Resolve(_ context.Context, ref bass.ImageRef) (bass.ImageRef, error) {
+	resolver.mu.Lock()
+	defer resolver.mu.Unlock()
+
+	resolver.calls++
+	ref.Digest = fmt.Sprintf("sha256:%d", resolver.calls)
+
+	return ref, nil
+}
+
+func TestResolveCache(t *testing.T) {
+	is := is.New(t)
+
+	ctx := context.Background()
+	resolver := &countingResolver{}
+	cache := bass.NewResolveCache(0)
+
+	ref := bass.ImageRef{
+		Platform: fakePlatform,
+		Repository: bass.ImageRepository{
+			Static: "alpine",
+		},
+		Tag: "latest",
+	}
+
+	first, err := cache.Resolve(ctx, ref, resolver.Resolve)
+	is.NoErr(err)
+	is.Equal(first.Digest, "sha256:1")
+
+	again, err := cache.Resolve(ctx, ref, resolver.Resolve)
+	is.NoErr(err)
+	is.Equal(again.Digest, "sha256:1")
+	is.Equal(resolver.calls, 1)
+
+	other := ref
+	other.Tag = "edge"
+	edge, err := cache.Resolve(ctx, other, resolver.Resolve)
+	is.NoErr(err)
+	is.Equal(edge.Digest, "sha256:2")
+
+	pinned := ref
+	pinned.Digest = "sha256:pinned"
+	res, err := cache.Resolve(ctx, pinned, resolver.Resolve)
+	is.NoErr(err)
+	is.Equal(res, pinned)
+	is.Equal(resolver.calls, 2)
+}
+
+func TestResolveCacheMemos(t *testing.T) {
+	is := is.New(t)
+
+	oldClock := bass.Clock
+	clock := clockwork.NewFakeClockAt(time.Unix(1600000000, 0))
+	bass.Clock = clock
+	defer func() { bass.Clock = oldClock }()
+
+	ctx := context.Background()
+	resolver := &countingResolver{}
+	memos := bass.NewLockfileMemo(filepath.Join(t.TempDir(), "bass.lock"))
+
+	ref := bass.ImageRef{
+		Platform: fakePlatform,
+		Repository: bass.ImageRepository{
+			Static: "alpine",
+		},
+		Tag: "latest",
+	}
+
+	newCache := func() *bass.ResolveCache {
+		cache := bass.NewResolveCache(time.Hour)
+		cache.Memos = memos
+		return cache
+	}
+
+	res, err := newCache().Resolve(ctx, ref, resolver.Resolve)
+	is.NoErr(err)
+	is.Equal(res.Digest, "sha256:1")
+
+	// a later run reuses the persisted resolution
+	clock.Advance(30 * time.Minute)
+	res, err = newCache().Resolve(ctx, ref, resolver.Resolve)
+	is.NoErr(err)
+	is.Equal(res.Digest, "sha256:1")
+	is.Equal(resolver.calls, 1)
+
+	// once it expires, it is resolved again
+	clock.Advance(time.Hour)
+	res, err = newCache().Resolve(ctx, ref, resolver.Resolve)
+	is.NoErr(err)
+	is.Equal(res.Digest, "sha256:2")
+	is.Equal(resolver.calls, 2)
+}
+
+func TestResolveCacheMemosAreInternal(t *testing.T) {
+	is := is.New(t)
+
+	ctx := context.Background()
+	lockPath := filepath.Join(t.TempDir(), "bass.lock")
+
+	cache := bass.NewResolveCache(time.Hour)
+	cache.Memos = bass.NewLockfileMemo(lockPath)
+
+	_, err := cache.Resolve(ctx, bass.ImageRef{
+		Platform: fakePlatform,
+		Repository: bass.ImageRepository{
+			Static: "alpine",
+		},
+		Tag: "latest",
+	}, (&countingResolver{}).Resolve)
+	is.NoErr(err)
+
+	lockContent, err := os.ReadFile(lockPath)
+	is.NoErr(err)
+
+	content := &proto.Memosphere{}
+	is.NoErr(prototext.Unmarshal(lockContent, content))
+	is.Equal(len(content.Memos), 1)
+
+	var module bass.Thunk
+	is.NoErr(module.UnmarshalProto(content.Memos[0].Module))
+
+	// resolutions are discarded by --bump rather than loaded as a module
+	refresh, internal := bass.InternalMemo(module)
+	is.True(internal)
+	is.True(refresh)
+
+	// a real module with the same command is not mistaken for one
+	_, internal = bass.InternalMemo(bass.Thunk{
+		Cmd: bass.ThunkCmd{
+			Cmd: &bass.CommandPath{"resolve"},
+		},
+	})
+	is.True(!internal)
+}
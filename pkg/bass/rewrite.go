@@ -0,0 +1,486 @@
+package bass
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RewriteVarPrefix marks a symbol in a rewrite pattern as a variable.
+//
+// A variable matches any form and substitutes it into the replacement. A
+// variable which appears more than once must match equal forms each time. The
+// variable ?_ matches any form without binding it.
+const RewriteVarPrefix = "?"
+
+// Rewrite is a find/replace rule over forms.
+type Rewrite struct {
+	// Pattern is the form to find.
+	Pattern Value
+
+	// Replacement is the form to replace each match with.
+	Replacement Value
+}
+
+// ParseRewrite reads a pattern and its replacement from source.
+func ParseRewrite(pattern, replacement string) (Rewrite, error) {
+	pat, err := readRewriteForm(pattern)
+	if err != nil {
+		return Rewrite{}, fmt.Errorf("pattern: %w", err)
+	}
+
+	rep, err := readRewriteForm(replacement)
+	if err != nil {
+		return Rewrite{}, fmt.Errorf("replacement: %w", err)
+	}
+
+	vars := map[Symbol]bool{}
+	eachRewriteVar(pat, func(sym Symbol) {
+		vars[sym] = true
+	})
+
+	var unbound []string
+	eachRewriteVar(rep, func(sym Symbol) {
+		if !vars[sym] {
+			unbound = append(unbound, string(sym))
+		}
+	})
+
+	if len(unbound) > 0 {
+		return Rewrite{}, fmt.Errorf("replacement uses variables not in pattern: %s", strings.Join(unbound, ", "))
+	}
+
+	return Rewrite{
+		Pattern:     pat,
+		Replacement: rep,
+	}, nil
+}
+
+func readRewriteForm(src string) (Value, error) {
+	forms, err := ParseSyntax(src, NewInMemoryFile("rewrite", src))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(forms.Nodes) != 1 {
+		return nil, fmt.Errorf("expected 1 form, got %d", len(forms.Nodes))
+	}
+
+	return unannotate(forms.Nodes[0].Form), nil
+}
+
+// RewriteSyntax applies the rewrite to each top-level form in the syntax,
+// returning the number of forms that changed.
+//
+// Only the source text of each match is replaced; everything else, including
+// comments and line breaks within a changed form, is left untouched.
+func (rw Rewrite) RewriteSyntax(syntax *Syntax) int {
+	var changed int
+	for _, node := range syntax.Nodes {
+		src, ok := rw.rewriteSource(node.Source)
+		if !ok {
+			continue
+		}
+
+		form, err := readSourceForm(src)
+		if err != nil {
+			// the spliced source should always be readable; fall back to
+			// re-rendering the form just in case
+			form, _ = rw.Apply(node.Form)
+			node.SetForm(form)
+		} else {
+			node.Form = form
+			node.Source = src
+			node.syncComment()
+		}
+
+		changed++
+	}
+
+	return changed
+}
+
+// Apply replaces each match of the pattern in the form, innermost first.
+// Annotations on the forms surrounding a match are preserved.
+//
+// Patterns only match whole forms; the tail of a list is never matched on its
+// own, so (old-fn ?x) does not match (list old-fn x).
+func (rw Rewrite) Apply(form Value) (Value, bool) {
+	var ann Annotate
+	if err := form.Decode(&ann); err == nil {
+		inner, changed := rw.Apply(ann.Value)
+		if !changed {
+			return form, false
+		}
+
+		ann.Value = inner
+		return ann, true
+	}
+
+	form, changed := rw.applyWithin(form)
+
+	bindings := map[Symbol]Value{}
+	if rewriteMatch(rw.Pattern, form, bindings) {
+		return rewriteSubst(rw.Replacement, bindings), true
+	}
+
+	return form, changed
+}
+
+// applyWithin applies the rewrite to each element of a list, cons, or bind.
+func (rw Rewrite) applyWithin(form Value) (Value, bool) {
+	switch x := form.(type) {
+	case Pair:
+		a, ca := rw.Apply(x.A)
+		d, cd := rw.applyTail(x.D)
+		if ca || cd {
+			return Pair{A: a, D: d}, true
+		}
+	case Cons:
+		a, ca := rw.Apply(x.A)
+		d, cd := rw.applyTail(x.D)
+		if ca || cd {
+			return Cons{A: a, D: d}, true
+		}
+	case Bind:
+		var bind Bind
+		changed := false
+		for i, v := range x {
+			val, cv := rw.Apply(v)
+			if cv && !changed {
+				bind = append(Bind{}, x[:i]...)
+				changed = true
+			}
+
+			if changed {
+				bind = append(bind, val)
+			}
+		}
+
+		if changed {
+			return bind, true
+		}
+	}
+
+	return form, false
+}
+
+// applyTail continues down the spine of a list without matching against it,
+// only applying the rewrite to a dotted tail.
+func (rw Rewrite) applyTail(tail Value) (Value, bool) {
+	switch tail.(type) {
+	case Pair, Cons:
+		return rw.applyWithin(tail)
+	default:
+		return rw.Apply(tail)
+	}
+}
+
+// rewriteSource applies the rewrite to the source text of a single form,
+// innermost first, splicing each replacement over the text of its match.
+func (rw Rewrite) rewriteSource(src string) (string, bool) {
+	form, err := readSourceForm(src)
+	if err != nil {
+		return src, false
+	}
+
+	changed := false
+
+	children := rewriteChildren(form)
+	for i := len(children) - 1; i >= 0; i-- {
+		start, end, ok := sourceSpan(src, children[i])
+		if !ok {
+			continue
+		}
+
+		child, cc := rw.rewriteSource(src[start:end])
+		if !cc {
+			continue
+		}
+
+		src = src[:start] + child + src[end:]
+		changed = true
+	}
+
+	if changed {
+		form, err = readSourceForm(src)
+		if err != nil {
+			return src, true
+		}
+	}
+
+	bindings := map[Symbol]Value{}
+	if !rewriteMatch(rw.Pattern, form, bindings) {
+		return src, changed
+	}
+
+	// substitute placeholders for the matched forms so that their original
+	// source text can be spliced into the rendered replacement
+	var sources []string
+	holders := map[Symbol]Value{}
+	for sym, val := range bindings {
+		holders[sym] = rewritePlaceholders(src, val, &sources)
+	}
+
+	out := rewriteSubst(rw.Replacement, holders).String()
+	for i, text := range sources {
+		out = strings.Replace(out, rewritePlaceholder(i).String(), text, 1)
+	}
+
+	return out, true
+}
+
+func rewritePlaceholder(i int) Symbol {
+	return Symbol(fmt.Sprintf("\x00rewrite-%d\x00", i))
+}
+
+// rewritePlaceholders replaces each form with a known source span with a
+// placeholder symbol, collecting its source text.
+func rewritePlaceholders(src string, val Value, sources *[]string) Value {
+	if start, end, ok := sourceSpan(src, val); ok {
+		sym := rewritePlaceholder(len(*sources))
+		*sources = append(*sources, src[start:end])
+		return sym
+	}
+
+	switch x := unannotate1(val).(type) {
+	case Pair:
+		return Pair{
+			A: rewritePlaceholders(src, x.A, sources),
+			D: rewritePlaceholders(src, x.D, sources),
+		}
+	case Cons:
+		return Cons{
+			A: rewritePlaceholders(src, x.A, sources),
+			D: rewritePlaceholders(src, x.D, sources),
+		}
+	case Bind:
+		bind := make(Bind, len(x))
+		for i, v := range x {
+			bind[i] = rewritePlaceholders(src, v, sources)
+		}
+
+		return bind
+	}
+
+	return unannotate(val)
+}
+
+// rewriteChildren returns the elements of a list, cons, or bind form, along
+// with a dotted tail, if any.
+func rewriteChildren(form Value) []Value {
+	var children []Value
+	switch x := unannotate1(form).(type) {
+	case Pair:
+		children = append(children, x.A)
+		children = append(children, rewriteTailChildren(x.D)...)
+	case Cons:
+		children = append(children, x.A)
+		children = append(children, rewriteTailChildren(x.D)...)
+	case Bind:
+		children = append(children, x...)
+	}
+
+	return children
+}
+
+func rewriteTailChildren(tail Value) []Value {
+	switch tail.(type) {
+	case Pair, Cons:
+		return rewriteChildren(tail)
+	case Empty:
+		return nil
+	default:
+		return []Value{tail}
+	}
+}
+
+// readSourceForm reads the single form in src, annotated with ranges
+// relative to the start of src.
+func readSourceForm(src string) (Value, error) {
+	return NewReader(strings.NewReader(src), NewInMemoryFile("rewrite", src)).Next()
+}
+
+// sourceSpan returns the byte offsets of an annotated form within the source
+// it was read from.
+func sourceSpan(src string, form Value) (int, int, bool) {
+	var ann Annotate
+	if err := form.Decode(&ann); err != nil {
+		return 0, 0, false
+	}
+
+	start, ok := sourceOffset(src, ann.Range.Start)
+	if !ok {
+		return 0, 0, false
+	}
+
+	end, ok := sourceOffset(src, ann.Range.End)
+	if !ok || end < start {
+		return 0, 0, false
+	}
+
+	return start, end, true
+}
+
+// sourceOffset converts a line and rune column into a byte offset.
+func sourceOffset(src string, pos Position) (int, bool) {
+	offset := 0
+	for ln := 1; ln < pos.Ln; ln++ {
+		nl := strings.IndexByte(src[offset:], '\n')
+		if nl == -1 {
+			return 0, false
+		}
+
+		offset += nl + 1
+	}
+
+	col := 0
+	for i := range src[offset:] {
+		if col == pos.Col {
+			return offset + i, true
+		}
+
+		col++
+	}
+
+	if col == pos.Col {
+		return len(src), true
+	}
+
+	return 0, false
+}
+
+func rewriteMatch(pattern, form Value, bindings map[Symbol]Value) bool {
+	orig := form
+	form = unannotate1(form)
+
+	switch p := pattern.(type) {
+	case Symbol:
+		if !isRewriteVar(p) {
+			break
+		}
+
+		if p == RewriteVarPrefix+"_" {
+			return true
+		}
+
+		if bound, found := bindings[p]; found {
+			return unannotate(bound).Equal(unannotate(form))
+		}
+
+		bindings[p] = orig
+		return true
+
+	case Pair:
+		f, ok := form.(Pair)
+		return ok &&
+			rewriteMatch(p.A, f.A, bindings) &&
+			rewriteMatch(p.D, f.D, bindings)
+
+	case Cons:
+		f, ok := form.(Cons)
+		return ok &&
+			rewriteMatch(p.A, f.A, bindings) &&
+			rewriteMatch(p.D, f.D, bindings)
+
+	case Bind:
+		f, ok := form.(Bind)
+		if !ok || len(f) != len(p) {
+			return false
+		}
+
+		for i := range p {
+			if !rewriteMatch(p[i], f[i], bindings) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return pattern.Equal(unannotate(form))
+}
+
+func rewriteSubst(replacement Value, bindings map[Symbol]Value) Value {
+	switch r := replacement.(type) {
+	case Symbol:
+		if bound, found := bindings[r]; found {
+			return bound
+		}
+	case Pair:
+		return Pair{
+			A: rewriteSubst(r.A, bindings),
+			D: rewriteSubst(r.D, bindings),
+		}
+	case Cons:
+		return Cons{
+			A: rewriteSubst(r.A, bindings),
+			D: rewriteSubst(r.D, bindings),
+		}
+	case Bind:
+		bind := make(Bind, len(r))
+		for i, v := range r {
+			bind[i] = rewriteSubst(v, bindings)
+		}
+
+		return bind
+	}
+
+	return replacement
+}
+
+func eachRewriteVar(form Value, cb func(Symbol)) {
+	switch x := form.(type) {
+	case Symbol:
+		if isRewriteVar(x) && x != RewriteVarPrefix+"_" {
+			cb(x)
+		}
+	case Pair:
+		eachRewriteVar(x.A, cb)
+		eachRewriteVar(x.D, cb)
+	case Cons:
+		eachRewriteVar(x.A, cb)
+		eachRewriteVar(x.D, cb)
+	case Bind:
+		for _, v := range x {
+			eachRewriteVar(v, cb)
+		}
+	}
+}
+
+func isRewriteVar(sym Symbol) bool {
+	return len(sym) > len(RewriteVarPrefix) && strings.HasPrefix(string(sym), RewriteVarPrefix)
+}
+
+// unannotate strips the annotations from a form and everything within it.
+func unannotate(form Value) Value {
+	var ann Annotate
+	if err := form.Decode(&ann); err == nil {
+		return unannotate(ann.Value)
+	}
+
+	switch x := form.(type) {
+	case Pair:
+		return Pair{A: unannotate(x.A), D: unannotate(x.D)}
+	case Cons:
+		return Cons{A: unannotate(x.A), D: unannotate(x.D)}
+	case Bind:
+		bind := make(Bind, len(x))
+		for i, v := range x {
+			bind[i] = unannotate(v)
+		}
+
+		return bind
+	}
+
+	return form
+}
+
+// unannotate1 strips the annotations from a form, leaving those within it.
+func unannotate1(form Value) Value {
+	var ann Annotate
+	for form.Decode(&ann) == nil {
+		form = ann.Value
+	}
+
+	return form
+}
@@ -0,0 +1,128 @@
+package bass_test
+
+import (
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/is"
+)
+
+func TestRewrite(t *testing.T) {
+	for _, example := range []struct {
+		Name        string
+		Pattern     string
+		Replacement string
+		Source      string
+		Result      string
+		Changed     int
+	}{
+		{
+			Name:        "rename",
+			Pattern:     "(old-fn ?a ?b)",
+			Replacement: "(new-fn ?b ?a)",
+			Source:      "(old-fn 1 2)\n(other-fn 3)\n",
+			Result:      "(new-fn 2 1)\n(other-fn 3)\n",
+			Changed:     1,
+		},
+		{
+			Name:        "nested",
+			Pattern:     "(old-fn ?a)",
+			Replacement: "(new-fn ?a)",
+			Source:      "(defn foo [x]\n  (old-fn (old-fn x)))\n",
+			Result:      "(defn foo [x]\n  (new-fn (new-fn x)))\n",
+			Changed:     1,
+		},
+		{
+			Name:        "rest",
+			Pattern:     "(old-fn & ?args)",
+			Replacement: "(new-fn :extra & ?args)",
+			Source:      "(old-fn 1 2 3)",
+			Result:      "(new-fn :extra 1 2 3)",
+			Changed:     1,
+		},
+		{
+			Name:        "repeated variables",
+			Pattern:     "(= ?x ?x)",
+			Replacement: "true",
+			Source:      "(= a a)\n(= a b)\n",
+			Result:      "true\n(= a b)\n",
+			Changed:     1,
+		},
+		{
+			Name:        "wildcard",
+			Pattern:     "(log ?_)",
+			Replacement: "null",
+			Source:      "(log 1)\n(log 2 3)\n",
+			Result:      "null\n(log 2 3)\n",
+			Changed:     1,
+		},
+		{
+			Name:        "cons and bind",
+			Pattern:     "{:old ?v}",
+			Replacement: "{:new [?v]}",
+			Source:      "(run {:old 42})",
+			Result:      "(run {:new [42]})",
+			Changed:     1,
+		},
+		{
+			Name:        "comments outside matches are preserved",
+			Pattern:     "(old-fn)",
+			Replacement: "(new-fn)",
+			Source:      "; calls the thing\n(old-fn) ; trailing\n\n; untouched\n(foo ; inner\n  )\n",
+			Result:      "; calls the thing\n(new-fn) ; trailing\n\n; untouched\n(foo ; inner\n  )\n",
+			Changed:     1,
+		},
+		{
+			Name:        "comments inside matches are preserved",
+			Pattern:     "(old-fn ?a ?b)",
+			Replacement: "(new-fn ?b ?a)",
+			Source:      "(defn foo [x]\n  ; first\n  (old-fn\n    (bar x) ; the bar\n    (baz ; the baz\n      x)))\n",
+			Result:      "(defn foo [x]\n  ; first\n  (new-fn (baz ; the baz\n      x) (bar x)))\n",
+			Changed:     1,
+		},
+		{
+			Name:        "list tails are not matched",
+			Pattern:     "(old-fn ?a)",
+			Replacement: "(new-fn ?a)",
+			Source:      "(list old-fn x)\n[old-fn x]\n",
+			Result:      "(list old-fn x)\n[old-fn x]\n",
+			Changed:     0,
+		},
+		{
+			Name:        "dotted tails are matched",
+			Pattern:     "(old-fn ?a)",
+			Replacement: "(new-fn ?a)",
+			Source:      "(foo & (old-fn x))",
+			Result:      "(foo & (new-fn x))",
+			Changed:     1,
+		},
+		{
+			Name:        "no match",
+			Pattern:     "(old-fn)",
+			Replacement: "(new-fn)",
+			Source:      "(old-fn 1)",
+			Result:      "(old-fn 1)",
+			Changed:     0,
+		},
+	} {
+		t.Run(example.Name, func(t *testing.T) {
+			is := is.New(t)
+
+			rw, err := bass.ParseRewrite(example.Pattern, example.Replacement)
+			is.NoErr(err)
+
+			syntax, err := bass.ParseSyntax(example.Source, bass.NewInMemoryFile("test", example.Source))
+			is.NoErr(err)
+
+			is.Equal(rw.RewriteSyntax(syntax), example.Changed)
+			is.Equal(syntax.String(), example.Result)
+		})
+	}
+}
+
+func TestParseRewriteUnboundVariable(t *testing.T) {
+	is := is.New(t)
+
+	_, err := bass.ParseRewrite("(old-fn ?a)", "(new-fn ?a ?b)")
+	is.True(err != nil)
+}
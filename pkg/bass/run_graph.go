@@ -0,0 +1,188 @@
+package bass
+
+import (
+	"context"
+	"runtime"
+
+	"golang.org/x/sync/errgroup"
+)
+
+func init() {
+	Ground.MustSet("run-graph",
+		Func("run-graph", "thunks", RunGraph),
+		`runs thunks concurrently, each after the thunks whose paths it uses`,
+		`Walks the args, stdin, env, dir, mounts, command, and image of each thunk for thunk paths. A thunk which uses a path from another given thunk, directly or through other thunks, is only started once that thunk has succeeded. All other thunks run concurrently, up to a limit of one per CPU.`,
+		`Errors with the first failure, canceling any thunks still running. Returns null.`,
+		`=> (def build (from (linux/alpine) ($ sh -c "echo hi > /out")))`,
+		`=> (run-graph (from (linux/alpine) ($ cat build/out)) (from (linux/alpine) ($ echo unrelated)) build)`)
+}
+
+// RunGraphParallelism is the maximum number of thunks run at once by
+// RunGraph.
+var RunGraphParallelism = runtime.NumCPU()
+
+// RunGraph runs the thunks, running each one after any of the others whose
+// paths it depends on, as determined by ThunkDeps. Independent thunks are run
+// concurrently, bounded by RunGraphParallelism.
+func RunGraph(ctx context.Context, thunks ...Thunk) error {
+	keys := make([]string, 0, len(thunks))
+	nodes := map[string]Thunk{}
+	for _, thunk := range thunks {
+		key, err := thunk.SHA256()
+		if err != nil {
+			return err
+		}
+
+		if _, dup := nodes[key]; dup {
+			continue
+		}
+
+		keys = append(keys, key)
+		nodes[key] = thunk
+	}
+
+	done := map[string]chan struct{}{}
+	for _, key := range keys {
+		done[key] = make(chan struct{})
+	}
+
+	deps := map[string][]string{}
+	for _, key := range keys {
+		seen := map[string]bool{key: true}
+
+		var walk func(Thunk) error
+		walk = func(thunk Thunk) error {
+			for _, dep := range ThunkDeps(thunk) {
+				depKey, err := dep.SHA256()
+				if err != nil {
+					return err
+				}
+
+				if seen[depKey] {
+					continue
+				}
+
+				seen[depKey] = true
+
+				if _, inGraph := nodes[depKey]; inGraph {
+					deps[key] = append(deps[key], depKey)
+				}
+
+				if err := walk(dep); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+
+		if err := walk(nodes[key]); err != nil {
+			return err
+		}
+	}
+
+	parallelism := RunGraphParallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	sem := make(chan struct{}, parallelism)
+
+	eg, ctx := errgroup.WithContext(ctx)
+	for _, key := range keys {
+		key := key
+		eg.Go(func() error {
+			for _, dep := range deps[key] {
+				select {
+				case <-done[dep]:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			err := nodes[key].Run(ctx)
+			<-sem
+			if err != nil {
+				return err
+			}
+
+			close(done[key])
+
+			return nil
+		})
+	}
+
+	return eg.Wait()
+}
+
+// ThunkDeps returns the thunks whose paths are used by the thunk, including
+// its image thunk. Thunks it only references as services through addrs are
+// not included, since they run alongside it rather than before it.
+func ThunkDeps(thunk Thunk) []Thunk {
+	var deps []Thunk
+
+	addPath := func(path *ThunkPath) {
+		if path != nil {
+			deps = append(deps, path.Thunk)
+		}
+	}
+
+	if thunk.Image != nil {
+		switch {
+		case thunk.Image.Thunk != nil:
+			deps = append(deps, *thunk.Image.Thunk)
+		case thunk.Image.Archive != nil:
+			deps = append(deps, thunk.Image.Archive.File.Thunk)
+		}
+	}
+
+	addPath(thunk.Cmd.Thunk)
+
+	for _, arg := range thunk.Args {
+		deps = appendValueDeps(deps, arg)
+	}
+
+	for _, val := range thunk.Stdin {
+		deps = appendValueDeps(deps, val)
+	}
+
+	if thunk.StdinFile != nil {
+		addPath(thunk.StdinFile.ThunkPath)
+	}
+
+	if thunk.Env != nil {
+		deps = appendValueDeps(deps, thunk.Env)
+	}
+
+	if thunk.Dir != nil {
+		addPath(thunk.Dir.ThunkDir)
+	}
+
+	for _, mount := range thunk.Mounts {
+		addPath(mount.Source.ThunkPath)
+	}
+
+	return deps
+}
+
+func appendValueDeps(deps []Thunk, val Value) []Thunk {
+	switch x := val.(type) {
+	case ThunkPath:
+		return append(deps, x.Thunk)
+	case Pair:
+		return appendValueDeps(appendValueDeps(deps, x.A), x.D)
+	case *Scope:
+		_ = x.Each(func(_ Symbol, v Value) error {
+			deps = appendValueDeps(deps, v)
+			return nil
+		})
+	}
+
+	return deps
+}
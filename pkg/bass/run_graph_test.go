@@ -0,0 +1,141 @@
+package bass_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/runtimes"
+	"github.com/vito/is"
+)
+
+type graphRuntime struct {
+	FakeRuntime
+
+	gates map[string]chan struct{}
+	fails map[string]bool
+
+	started chan string
+
+	mu   sync.Mutex
+	done []string
+}
+
+func (fake *graphRuntime) Run(ctx context.Context, thunk bass.Thunk) error {
+	name := thunk.Cmd.ToValue().String()
+
+	fake.started <- name
+
+	if gate, found := fake.gates[name]; found {
+		<-gate
+	}
+
+	fake.mu.Lock()
+	fake.done = append(fake.done, name)
+	fake.mu.Unlock()
+
+	if fake.fails[name] {
+		return fmt.Errorf("%s failed", name)
+	}
+
+	return nil
+}
+
+func TestRunGraph(t *testing.T) {
+	is := is.New(t)
+
+	image := &bass.ThunkImage{
+		Ref: &bass.ImageRef{
+			Platform: bass.LinuxPlatform,
+			Repository: bass.ImageRepository{
+				Static: "alpine",
+			},
+		},
+	}
+
+	build := bass.Thunk{
+		Image: image,
+		Cmd:   bass.ThunkCmd{Cmd: &bass.CommandPath{"build"}},
+	}
+
+	out := bass.ParseFileOrDirPath("out")
+
+	// depends on build through its args
+	test := bass.Thunk{
+		Image: image,
+		Cmd:   bass.ThunkCmd{Cmd: &bass.CommandPath{"test"}},
+	}.WithArgs([]bass.Value{bass.ThunkPath{Thunk: build, Path: out}})
+
+	// depends on build through test's mount
+	deploy := bass.Thunk{
+		Image: image,
+		Cmd:   bass.ThunkCmd{Cmd: &bass.CommandPath{"deploy"}},
+		Mounts: []bass.ThunkMount{
+			{
+				Source: bass.ThunkMountSource{
+					ThunkPath: &bass.ThunkPath{Thunk: test, Path: out},
+				},
+				Target: bass.FileOrDirPath{Dir: &bass.DirPath{Path: "src"}},
+			},
+		},
+	}
+
+	lint := bass.Thunk{
+		Image: image,
+		Cmd:   bass.ThunkCmd{Cmd: &bass.CommandPath{"lint"}},
+	}
+
+	is.Equal(len(bass.ThunkDeps(test)), 1)
+	is.Equal(len(bass.ThunkDeps(deploy)), 1)
+	is.Equal(len(bass.ThunkDeps(lint)), 0)
+
+	newRuntime := func() *graphRuntime {
+		return &graphRuntime{
+			gates:   map[string]chan struct{}{"build": make(chan struct{})},
+			fails:   map[string]bool{},
+			started: make(chan string, 4),
+		}
+	}
+
+	withRuntime := func(fake *graphRuntime) context.Context {
+		return bass.WithRuntimePool(context.Background(), &runtimes.Pool{
+			Runtimes: []runtimes.Assoc{
+				{
+					Platform: bass.LinuxPlatform,
+					Runtime:  fake,
+				},
+			},
+		})
+	}
+
+	fake := newRuntime()
+
+	ran := make(chan error, 1)
+	go func() {
+		ran <- bass.RunGraph(withRuntime(fake), deploy, lint, test, build)
+	}()
+
+	// build and lint start concurrently; test and deploy wait on build
+	started := map[string]bool{<-fake.started: true, <-fake.started: true}
+	is.Equal(started, map[string]bool{".build": true, ".lint": true})
+
+	close(fake.gates["build"])
+	is.NoErr(<-ran)
+
+	is.Equal(len(fake.done), 4)
+	is.Equal(fake.done[2:], []string{".test", ".deploy"})
+
+	t.Run("stops at the first failure", func(t *testing.T) {
+		is := is.New(t)
+
+		fake := newRuntime()
+		close(fake.gates["build"])
+		fake.fails[".build"] = true
+
+		err := bass.RunGraph(withRuntime(fake), deploy, test, build)
+		is.True(err != nil)
+		is.Equal(fake.done, []string{".build"})
+	})
+}
@@ -0,0 +1,140 @@
+package bass
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gofrs/flock"
+)
+
+// RunsDir is the directory containing a lock file for each run on the local
+// machine, held for as long as the run is active.
+func RunsDir() string {
+	return filepath.Join(CacheHome, "runs")
+}
+
+// ActiveRun is a run registered in RunsDir by StartRun.
+//
+// Runtimes label the resources they create with the run's ID so that the
+// resources left behind by a run which crashed or was killed can be found and
+// released once its lock is no longer held; see OrphanedRuns.
+type ActiveRun struct {
+	ID string
+
+	lock *flock.Flock
+}
+
+// StartRun registers a new run with a random ID in RunsDir, locking its file
+// until Finish is called.
+func StartRun() (*ActiveRun, error) {
+	if err := os.MkdirAll(RunsDir(), 0700); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+
+	id := hex.EncodeToString(buf)
+
+	lock := flock.New(runLockPath(id))
+
+	locked, err := lock.TryLock()
+	if err != nil {
+		return nil, fmt.Errorf("lock run %s: %w", id, err)
+	}
+
+	if !locked {
+		return nil, fmt.Errorf("lock run %s: already locked", id)
+	}
+
+	return &ActiveRun{
+		ID:   id,
+		lock: lock,
+	}, nil
+}
+
+// Finish removes the run from RunsDir and releases its lock.
+func (run *ActiveRun) Finish() error {
+	if err := os.Remove(runLockPath(run.ID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return run.lock.Unlock()
+}
+
+// OrphanedRuns returns the IDs of the runs registered in RunsDir which are no
+// longer active, i.e. whose process exited without calling Finish.
+func OrphanedRuns() ([]string, error) {
+	entries, err := os.ReadDir(RunsDir())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		id := strings.TrimSuffix(entry.Name(), ".lock")
+		if id == entry.Name() {
+			continue
+		}
+
+		lock := flock.New(runLockPath(id))
+
+		locked, err := lock.TryLock()
+		if err != nil {
+			return nil, fmt.Errorf("lock run %s: %w", id, err)
+		}
+
+		if !locked {
+			// still running
+			continue
+		}
+
+		if err := lock.Unlock(); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// ForgetRun removes an orphaned run from RunsDir once the resources labeled
+// with its ID have been released.
+func ForgetRun(id string) error {
+	err := os.Remove(runLockPath(id))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func runLockPath(id string) string {
+	return filepath.Join(RunsDir(), id+".lock")
+}
+
+type runIDKey struct{}
+
+// WithRunID sets the ID of the active run within the returned context.
+func WithRunID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, runIDKey{}, id)
+}
+
+// RunIDFromContext returns the ID set by WithRunID, if any.
+func RunIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(runIDKey{}).(string)
+	return id, ok
+}
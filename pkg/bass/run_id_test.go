@@ -0,0 +1,45 @@
+package bass_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/is"
+)
+
+func TestRunIDs(t *testing.T) {
+	is := is.New(t)
+
+	oldHome := bass.CacheHome
+	bass.CacheHome = t.TempDir()
+	defer func() { bass.CacheHome = oldHome }()
+
+	orphans, err := bass.OrphanedRuns()
+	is.NoErr(err)
+	is.Equal(len(orphans), 0)
+
+	run, err := bass.StartRun()
+	is.NoErr(err)
+	is.True(run.ID != "")
+
+	// a run whose process exited without finishing leaves its file unlocked
+	is.NoErr(os.WriteFile(filepath.Join(bass.RunsDir(), "crashed.lock"), nil, 0600))
+
+	orphans, err = bass.OrphanedRuns()
+	is.NoErr(err)
+	is.Equal(orphans, []string{"crashed"})
+
+	is.NoErr(bass.ForgetRun("crashed"))
+
+	orphans, err = bass.OrphanedRuns()
+	is.NoErr(err)
+	is.Equal(len(orphans), 0)
+
+	is.NoErr(run.Finish())
+
+	entries, err := os.ReadDir(bass.RunsDir())
+	is.NoErr(err)
+	is.Equal(len(entries), 0)
+}
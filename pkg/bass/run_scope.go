@@ -5,12 +5,14 @@ const (
 	RunBindingStdout Symbol = "*stdout*"
 	RunBindingDir    Symbol = "*dir*"
 	RunBindingEnv    Symbol = "*env*"
+	RunBindingVars   Symbol = "*vars*"
 	RunBindingMain   Symbol = "main"
 )
 
 type RunState struct {
 	Dir    Path
 	Env    *Scope
+	Vars   *Scope
 	Stdin  *Source
 	Stdout *Sink
 }
@@ -30,6 +32,13 @@ func NewRunScope(parent *Scope, state RunState) *Scope {
 		env = state.Env.Copy()
 	}
 
+	var vars *Scope
+	if state.Vars == nil {
+		vars = NewEmptyScope()
+	} else {
+		vars = state.Vars.Copy()
+	}
+
 	stdin := state.Stdin
 	if stdin == nil {
 		stdin = NewSource(NewInMemorySource())
@@ -48,17 +57,26 @@ func NewRunScope(parent *Scope, state RunState) *Scope {
 		`System environment variables are only available to the entrypoint script. To propagate them further they must be explicitly passed to thunks using (with-env).`,
 		`System environment variables are unset from the physical OS process as part of initialization to ensure they cannot be leaked.`)
 
+	scope.Set(RunBindingVars, vars, `configuration variables`,
+		`Variables are loaded from bass.vars.yaml or bass.vars.json files in the entrypoint script's directory and its parent directories up to the root of its git repository, followed by any --var name=value flags. Outside of a git repository, only the script's directory is searched.`,
+		`A --var value is parsed as JSON if it is valid JSON, e.g. --var replicas=3 sets a number and --var tags='["a","b"]' sets a list, and is otherwise a string.`,
+		`Later sources take precedence: a file in a parent directory is overridden by a file closer to the script, which is overridden by --var flags. A directory may only contain one of the two files.`,
+		`Like *env*, variables are only available to the entrypoint script. Pass them along to other scripts and thunks explicitly.`)
+
 	scope.Set(RunBindingStdin, stdin, `standard input stream`,
-		`Values read from *stdin* will be parsed from the process's stdin as a JSON stream.`)
+		`Values read from *stdin* will be parsed from the process's stdin as a JSON stream.`,
+		`To feed piped input to a thunk, pass *stdin* to (with-stdin). Use (tty? *stdin*) to check whether anything was piped without blocking on input.`)
 
 	scope.Set(RunBindingStdout, stdout, `standard output sink`,
-		`Values emitted by a script to *stdout* will be encoded as a JSON stream to the process's stdout.`)
+		`Values emitted by a script to *stdout* will be encoded as a JSON stream to the process's stdout.`,
+		`Pass --out yaml or --out pretty to write them as YAML documents or indented JSON instead.`)
 
 	scope.Set(RunBindingMain, Func("main", "[]", func() {}),
 		`script entrypoint`,
 		`The (main) function is called with any provided command-line args when running a Bass script.`,
 		`Scripts should define it to capture system arguments and run the script's desired effects.`,
-		`Putting effects in (main) instead of running them at the toplevel makes the Bass language server happier.`)
+		`Putting effects in (main) instead of running them at the toplevel makes the Bass language server happier.`,
+		`To parse command-line flags and args, declare them in :flags, :args, and :rest metadata on (main). Each flag is configured with an optional :type (:string, :int, :bool, or :strings), :doc, :default, and :required. The type defaults to the type of the :default. (main) is then called with a scope of flag values followed by the positional args, and --help prints usage generated from the spec and the doc comment.`)
 
 	return NewEmptyScope(scope)
 }
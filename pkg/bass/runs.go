@@ -2,6 +2,7 @@ package bass
 
 import (
 	"context"
+	"sort"
 	"sync"
 
 	"github.com/hashicorp/go-multierror"
@@ -10,23 +11,40 @@ import (
 type Runs struct {
 	wg sync.WaitGroup
 
-	stops  []func()
-	stopsL sync.Mutex
+	stops   []func()
+	running map[int]string
+	lastID  int
+	stopsL  sync.Mutex
 
 	errs  error
 	errsL sync.Mutex
 }
 
-func (runs *Runs) Go(stop func(), f func() error) {
+// Go calls f in a goroutine, calling stop when the runs are stopped. The name
+// identifies the run if it is still running when it should have finished.
+func (runs *Runs) Go(name string, stop func(), f func() error) {
+	runs.stopsL.Lock()
+	runs.lastID++
+	id := runs.lastID
+	if runs.running == nil {
+		runs.running = map[int]string{}
+	}
+	runs.running[id] = name
+	runs.stops = append(runs.stops, stop)
+	runs.stopsL.Unlock()
+
 	runs.wg.Add(1)
 	go func() {
 		defer runs.wg.Done()
-		runs.record(f())
-	}()
 
-	runs.stopsL.Lock()
-	runs.stops = append(runs.stops, stop)
-	runs.stopsL.Unlock()
+		err := f()
+
+		runs.stopsL.Lock()
+		delete(runs.running, id)
+		runs.stopsL.Unlock()
+
+		runs.record(err)
+	}()
 }
 
 func (runs *Runs) Stop() {
@@ -39,7 +57,30 @@ func (runs *Runs) Stop() {
 
 func (runs *Runs) Wait() error {
 	runs.wg.Wait()
-	return runs.errs
+	return runs.Err()
+}
+
+// WaitContext waits for the runs to finish like Wait, but gives up once the
+// context is done, returning a LeakError naming the runs which are still
+// running.
+func (runs *Runs) WaitContext(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		runs.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return runs.Err()
+	case <-ctx.Done():
+		select {
+		case <-done:
+			return runs.Err()
+		default:
+			return LeakError{Runs: runs.Running()}
+		}
+	}
 }
 
 func (runs *Runs) StopAndWait() error {
@@ -47,6 +88,21 @@ func (runs *Runs) StopAndWait() error {
 	return runs.Wait()
 }
 
+// Running returns the names of the runs which have not finished, sorted.
+func (runs *Runs) Running() []string {
+	runs.stopsL.Lock()
+	defer runs.stopsL.Unlock()
+
+	names := make([]string, 0, len(runs.running))
+	for _, name := range runs.running {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
 func (runs *Runs) Err() error {
 	runs.errsL.Lock()
 	defer runs.errsL.Unlock()
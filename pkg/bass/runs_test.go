@@ -2,9 +2,11 @@ package bass_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/vito/bass/pkg/bass"
 	"github.com/vito/bass/pkg/basstest"
@@ -53,3 +55,43 @@ func TestRuns(t *testing.T) {
 	is.True(strings.Contains(err.Error(), "it failed!: oh no"))
 	is.True(strings.Contains(err.Error(), "it failed!: let's go"))
 }
+
+func TestRunsWaitContext(t *testing.T) {
+	is := is.New(t)
+
+	runs := new(bass.Runs)
+
+	release := make(chan struct{})
+
+	stopped, stop := context.WithCancel(context.Background())
+	runs.Go("stops", stop, func() error {
+		<-stopped.Done()
+		return nil
+	})
+
+	runs.Go("ignores stop", func() {}, func() error {
+		<-release
+		return errors.New("finally")
+	})
+
+	is.Equal(runs.Running(), []string{"ignores stop", "stops"})
+
+	runs.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := runs.WaitContext(ctx)
+
+	var leaks bass.LeakError
+	is.True(errors.As(err, &leaks))
+	is.Equal(leaks.Runs, []string{"ignores stop"})
+	is.Equal(err.Error(), "1 runs still running: ignores stop")
+
+	close(release)
+
+	err = runs.WaitContext(context.Background())
+	is.True(err != nil)
+	is.Equal(err.Error(), "finally")
+	is.Equal(runs.Running(), []string{})
+}
@@ -10,6 +10,7 @@ import (
 type RuntimePool interface {
 	Select(Platform) (Runtime, error)
 	All() ([]Runtime, error)
+	Platforms() []Platform
 }
 
 type Runtime interface {
@@ -27,6 +27,9 @@ type Scope struct {
 	Parents  []*Scope
 	Bindings Bindings
 	Order    []Symbol
+
+	// frozen scopes may no longer be Set
+	frozen bool
 }
 
 // Bindings maps Symbols to Values in a scope.
@@ -91,6 +94,60 @@ func Assoc(obj *Scope, kv ...Value) (*Scope, error) {
 	return clone, nil
 }
 
+// lookup returns the value at the key in a scope, or at the index in a list,
+// or nil if it is missing or obj is neither.
+func lookup(obj Value, key Value) Value {
+	var scope *Scope
+	if err := obj.Decode(&scope); err == nil {
+		var sym Symbol
+		if err := key.Decode(&sym); err != nil {
+			var str string
+			if err := key.Decode(&str); err != nil {
+				return nil
+			}
+
+			sym = Symbol(str)
+		}
+
+		val, found := scope.Get(sym)
+		if !found {
+			return nil
+		}
+
+		return val
+	}
+
+	var idx int
+	if err := key.Decode(&idx); err != nil {
+		return nil
+	}
+
+	var list []Value
+	if err := obj.Decode(&list); err != nil {
+		return nil
+	}
+
+	if idx < 0 || idx >= len(list) {
+		return nil
+	}
+
+	return list[idx]
+}
+
+// getOr returns val, or the first default if val is nil, or null if no
+// default is given.
+func getOr(val Value, def []Value) Value {
+	if val != nil {
+		return val
+	}
+
+	if len(def) > 0 {
+		return def[0]
+	}
+
+	return Null{}
+}
+
 // Bindable is any value which may be used to destructure a value into bindings
 // in a scope.
 type Bindable interface {
@@ -337,7 +394,16 @@ func (value *Scope) Eval(_ context.Context, _ *Scope, cont Cont) ReadyCont {
 }
 
 // Set assigns the value in the local bindings.
-func (scope *Scope) Set(binding Symbol, value Value, docs ...string) {
+//
+// Returns FrozenError if the scope has been frozen.
+func (scope *Scope) Set(binding Symbol, value Value, docs ...string) error {
+	if scope.frozen {
+		return FrozenError{
+			Scope:   scope,
+			Binding: binding,
+		}
+	}
+
 	if len(docs) > 0 {
 		value = annotate(value, docs...)
 	}
@@ -348,6 +414,31 @@ func (scope *Scope) Set(binding Symbol, value Value, docs ...string) {
 	}
 
 	scope.Bindings[binding] = value
+
+	return nil
+}
+
+// MustSet is like Set, but panics if the scope has been frozen.
+//
+// It is used to register builtins in Ground, so that registering one after
+// Ground is frozen fails loudly rather than silently not binding it.
+func (scope *Scope) MustSet(binding Symbol, value Value, docs ...string) {
+	if err := scope.Set(binding, value, docs...); err != nil {
+		panic(err)
+	}
+}
+
+// Freeze prevents any further bindings from being Set in the scope.
+//
+// Parent scopes are not frozen, and child scopes may still shadow the scope's
+// bindings.
+func (scope *Scope) Freeze() {
+	scope.frozen = true
+}
+
+// IsFrozen returns true if the scope has been frozen.
+func (scope *Scope) IsFrozen() bool {
+	return scope.frozen
 }
 
 // Get fetches the given binding.
@@ -440,6 +531,7 @@ func (scope *Scope) MarshalLogObject(enc zapcore.ObjectEncoder) error {
 
 		var str string
 		var num int
+		var flt float64
 		var bol bool
 		var am zapcore.ArrayMarshaler
 		var om zapcore.ObjectMarshaler
@@ -447,6 +539,8 @@ func (scope *Scope) MarshalLogObject(enc zapcore.ObjectEncoder) error {
 			enc.AddString(key, str)
 		} else if v.Decode(&num) == nil {
 			enc.AddInt(key, num)
+		} else if v.Decode(&flt) == nil {
+			enc.AddFloat64(key, flt)
 		} else if v.Decode(&bol) == nil {
 			enc.AddBool(key, bol)
 		} else if v.Decode(&am) == nil {
@@ -3,19 +3,500 @@ package bass
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/vito/bass/pkg/ioctx"
 )
 
+// Metadata keys for declaring a script's command-line interface on its
+// (main) function or its commands.
+const (
+	MainFlagsMetaBinding Symbol = "flags"
+	MainArgsMetaBinding  Symbol = "args"
+	MainRestMetaBinding  Symbol = "rest"
+
+	// CommandMetaBinding marks a function defined with (defcommand).
+	CommandMetaBinding Symbol = "command"
+)
+
+// RunMain calls the script's entrypoint with the given args.
+//
+// If the script defines commands with (defcommand) and the first arg names
+// one of them, the command is called with the remaining args. Otherwise
+// (main) is called.
 func RunMain(ctx context.Context, scope *Scope, args ...Value) error {
+	_, err := CallMain(ctx, scope, args...)
+	return err
+}
+
+// CallMain calls the script's entrypoint like RunMain, returning its result.
+//
+// Null is returned if the script has no entrypoint or only printed usage.
+func CallMain(ctx context.Context, scope *Scope, args ...Value) (Value, error) {
+	commands := ScriptCommands(scope)
+
+	argv, isArgv := stringArgs(args)
+	if len(commands) > 0 && isArgv {
+		if len(argv) > 0 {
+			if cmd, found := commands[argv[0]]; found {
+				return callEntrypoint(ctx, scope, cmd, args[1:])
+			}
+		}
+
+		// only dispatch to (main) if the script defined one
+		if _, hasMain := scope.Bindings[RunBindingMain]; !hasMain {
+			if len(argv) == 0 || argv[0] == "--help" || argv[0] == "-h" {
+				commandsUsage(ioctx.StderrFromContext(ctx), commands)
+				return Null{}, nil
+			}
+
+			return nil, fmt.Errorf("unknown command: %s (available: %s)", argv[0], strings.Join(commandNames(commands), ", "))
+		}
+	}
+
+	val, found := scope.Get(RunBindingMain)
+	if !found {
+		return Null{}, nil
+	}
+
+	return callEntrypoint(ctx, scope, val, args)
+}
+
+// ScriptCommands returns the commands defined in the script's scope with
+// (defcommand), by name.
+func ScriptCommands(scope *Scope) map[string]Value {
+	commands := map[string]Value{}
+	for name, val := range scope.Bindings {
+		var ann Annotated
+		if err := val.Decode(&ann); err != nil || ann.Meta == nil {
+			continue
+		}
+
+		var isCommand bool
+		if err := ann.Meta.GetDecode(CommandMetaBinding, &isCommand); err == nil && isCommand {
+			commands[name.String()] = val
+		}
+	}
+
+	return commands
+}
+
+// callEntrypoint calls (main) or a command, parsing string args according to
+// the spec declared in its metadata, if any.
+func callEntrypoint(ctx context.Context, scope *Scope, val Value, args []Value) (Value, error) {
 	var comb Combiner
-	if err := scope.GetDecode(RunBindingMain, &comb); err != nil {
-		var unb UnboundError
-		if errors.As(err, &unb) {
+	if err := val.Decode(&comb); err != nil {
+		return nil, err
+	}
+
+	var ann Annotated
+	if err := val.Decode(&ann); err == nil && ann.Meta != nil {
+		spec, ok, err := ParseMainSpec(ann.Meta)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			argv, isArgv := stringArgs(args)
+			if isArgv {
+				parsed, help, err := spec.Parse(argv)
+				if err != nil {
+					return nil, err
+				}
+
+				if help {
+					spec.Usage(ioctx.StderrFromContext(ctx))
+					return Null{}, nil
+				}
+
+				args = parsed
+			}
+		}
+	}
+
+	return Trampoline(ctx, comb.Call(ctx, NewList(args...), scope, Identity))
+}
+
+func commandNames(commands map[string]Value) []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func commandsUsage(w io.Writer, commands map[string]Value) {
+	fmt.Fprintln(w, "usage: command [flags] [args...]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+
+	for _, name := range commandNames(commands) {
+		var summary string
+
+		var ann Annotated
+		if err := commands[name].Decode(&ann); err == nil {
+			var doc string
+			if err := ann.Meta.GetDecode(DocMetaBinding, &doc); err == nil {
+				summary, _, _ = strings.Cut(doc, "\n")
+			}
+		}
+
+		fmt.Fprintf(w, "  %s\t%s\n", name, summary)
+	}
+}
+
+// MainSpec declares the flags and positional arguments accepted by a
+// script's (main) function.
+//
+// It is parsed from the :flags, :args, and :rest metadata on (main). When
+// the script is run with string arguments, e.g. from the command line, they
+// are parsed according to the spec and (main) is called with a scope of flag
+// values followed by the positional arguments.
+type MainSpec struct {
+	// Doc is the doc comment of (main), shown in usage.
+	Doc string
+
+	// Flags maps flag names to their configuration.
+	Flags map[string]MainFlag
+
+	// Args lists the names of required positional arguments.
+	Args []MainArg
+
+	// Rest is the name for any remaining positional arguments. If empty,
+	// extra arguments are an error.
+	Rest string
+}
+
+// MainFlag configures a flag accepted by (main).
+type MainFlag struct {
+	// Type is one of :string, :int, :bool, or :strings.
+	Type Symbol `json:"type,omitempty"`
+
+	// Doc describes the flag in usage.
+	Doc string `json:"doc,omitempty"`
+
+	// Default is the value used when the flag is not given.
+	Default Value `json:"default,omitempty"`
+
+	// Required makes it an error to omit the flag.
+	Required bool `json:"required,omitempty"`
+}
+
+// MainArg is a positional argument accepted by (main).
+type MainArg struct {
+	Name Symbol `json:"name"`
+	Doc  string `json:"doc,omitempty"`
+}
+
+func (arg *MainArg) FromValue(val Value) error {
+	var sym Symbol
+	if err := val.Decode(&sym); err == nil {
+		arg.Name = sym
+		return nil
+	}
+
+	var scope *Scope
+	if err := val.Decode(&scope); err != nil {
+		return fmt.Errorf("arg must be a symbol or a scope: %w", err)
+	}
+
+	return decodeStruct(scope, arg)
+}
+
+// ParseMainSpec parses the command-line interface declared in the metadata
+// of (main). It returns false if none is declared.
+func ParseMainSpec(meta *Scope) (MainSpec, bool, error) {
+	spec := MainSpec{
+		Flags: map[string]MainFlag{},
+	}
+
+	var declared bool
+
+	var doc string
+	if err := meta.GetDecode(DocMetaBinding, &doc); err == nil {
+		spec.Doc = doc
+	}
+
+	if val, found := meta.Get(MainFlagsMetaBinding); found {
+		declared = true
+
+		var flags *Scope
+		if err := val.Decode(&flags); err != nil {
+			return MainSpec{}, false, fmt.Errorf("main :flags: %w", err)
+		}
+
+		err := flags.Each(func(name Symbol, val Value) error {
+			var flag MainFlag
+
+			var fs *Scope
+			if err := val.Decode(&fs); err != nil {
+				return fmt.Errorf("flag %s: must be a scope: %w", name, err)
+			}
+
+			if err := decodeStruct(fs, &flag); err != nil {
+				return fmt.Errorf("flag %s: %w", name, err)
+			}
+
+			if flag.Type == "" {
+				flag.Type = flagType(flag.Default)
+			}
+
+			switch flag.Type {
+			case "string", "int", "bool", "strings":
+			default:
+				return fmt.Errorf("flag %s: unknown type: %s", name, flag.Type)
+			}
+
+			spec.Flags[name.String()] = flag
 			return nil
+		})
+		if err != nil {
+			return MainSpec{}, false, err
 		}
+	}
 
-		return err
+	if val, found := meta.Get(MainArgsMetaBinding); found {
+		declared = true
+
+		if err := val.Decode(&spec.Args); err != nil {
+			return MainSpec{}, false, fmt.Errorf("main :args: %w", err)
+		}
 	}
 
-	_, err := Trampoline(ctx, comb.Call(ctx, NewList(args...), scope, Identity))
-	return err
+	if val, found := meta.Get(MainRestMetaBinding); found {
+		declared = true
+
+		var rest Symbol
+		if err := val.Decode(&rest); err != nil {
+			return MainSpec{}, false, fmt.Errorf("main :rest: %w", err)
+		}
+
+		spec.Rest = rest.String()
+	}
+
+	return spec, declared, nil
+}
+
+// Parse parses command-line arguments into the values to pass to (main): a
+// scope of flag values followed by the positional arguments.
+//
+// It returns true if --help was given.
+func (spec MainSpec) Parse(argv []string) ([]Value, bool, error) {
+	fs := spec.flagSet()
+
+	err := fs.Parse(argv)
+	if err != nil {
+		if errors.Is(err, pflag.ErrHelp) {
+			return nil, true, nil
+		}
+
+		return nil, false, FlagError{Err: err, Flags: fs}
+	}
+
+	opts := NewEmptyScope()
+	for _, name := range spec.flagNames() {
+		flag := spec.Flags[name]
+		pf := fs.Lookup(name)
+
+		if !pf.Changed {
+			if flag.Required {
+				return nil, false, FlagError{
+					Err:   fmt.Errorf("missing required flag: --%s", name),
+					Flags: fs,
+				}
+			}
+
+			if flag.Default == nil && flag.Type != "bool" {
+				opts.Set(Symbol(name), Null{})
+				continue
+			}
+		}
+
+		var val Value
+		switch flag.Type {
+		case "int":
+			i, _ := fs.GetInt(name)
+			val = Int(i)
+		case "bool":
+			b, _ := fs.GetBool(name)
+			val = Bool(b)
+		case "strings":
+			ss, _ := fs.GetStringArray(name)
+			vals := make([]Value, len(ss))
+			for i, s := range ss {
+				vals[i] = String(s)
+			}
+			val = NewList(vals...)
+		default:
+			s, _ := fs.GetString(name)
+			val = String(s)
+		}
+
+		opts.Set(Symbol(name), val)
+	}
+
+	positional := fs.Args()
+	if len(positional) < len(spec.Args) {
+		missing := make([]string, 0, len(spec.Args)-len(positional))
+		for _, arg := range spec.Args[len(positional):] {
+			missing = append(missing, arg.Name.String())
+		}
+
+		return nil, false, FlagError{
+			Err:   fmt.Errorf("missing arguments: %s", strings.Join(missing, " ")),
+			Flags: fs,
+		}
+	}
+
+	if len(positional) > len(spec.Args) && spec.Rest == "" {
+		return nil, false, FlagError{
+			Err:   fmt.Errorf("too many arguments: %s", strings.Join(positional[len(spec.Args):], " ")),
+			Flags: fs,
+		}
+	}
+
+	vals := []Value{opts}
+	for _, arg := range positional {
+		vals = append(vals, String(arg))
+	}
+
+	return vals, false, nil
+}
+
+// Usage writes a usage message generated from the spec.
+func (spec MainSpec) Usage(w io.Writer) {
+	usage := []string{"usage:", "[flags]"}
+	for _, arg := range spec.Args {
+		usage = append(usage, arg.Name.String())
+	}
+
+	if spec.Rest != "" {
+		usage = append(usage, "["+spec.Rest+"...]")
+	}
+
+	fmt.Fprintln(w, strings.Join(usage, " "))
+
+	if spec.Doc != "" {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, spec.Doc)
+	}
+
+	var documented bool
+	for _, arg := range spec.Args {
+		if arg.Doc != "" {
+			documented = true
+		}
+	}
+
+	if documented {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "args:")
+		for _, arg := range spec.Args {
+			fmt.Fprintf(w, "  %s\t%s\n", arg.Name, arg.Doc)
+		}
+	}
+
+	if len(spec.Flags) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "flags:")
+		fs := spec.flagSet()
+		fs.SetOutput(w)
+		fs.PrintDefaults()
+	}
+}
+
+func (spec MainSpec) flagNames() []string {
+	names := make([]string, 0, len(spec.Flags))
+	for name := range spec.Flags {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func (spec MainSpec) flagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("main", pflag.ContinueOnError)
+	fs.SortFlags = false
+	fs.SetOutput(io.Discard)
+
+	for _, name := range spec.flagNames() {
+		flag := spec.Flags[name]
+
+		switch flag.Type {
+		case "int":
+			var def int
+			if i, ok := flag.Default.(Int); ok {
+				def = int(i)
+			}
+
+			fs.Int(name, def, flag.Doc)
+		case "bool":
+			var def bool
+			if b, ok := flag.Default.(Bool); ok {
+				def = bool(b)
+			}
+
+			fs.Bool(name, def, flag.Doc)
+		case "strings":
+			var def []string
+			if l, ok := flag.Default.(List); ok {
+				_ = Each(l, func(v Value) error {
+					var s string
+					if err := v.Decode(&s); err == nil {
+						def = append(def, s)
+					}
+					return nil
+				})
+			}
+
+			fs.StringArray(name, def, flag.Doc)
+		default:
+			var def string
+			if flag.Default != nil {
+				if err := flag.Default.Decode(&def); err != nil {
+					def = flag.Default.String()
+				}
+			}
+
+			fs.String(name, def, flag.Doc)
+		}
+	}
+
+	return fs
+}
+
+func flagType(def Value) Symbol {
+	switch def.(type) {
+	case Int:
+		return "int"
+	case Bool:
+		return "bool"
+	case List:
+		return "strings"
+	default:
+		return "string"
+	}
+}
+
+func stringArgs(args []Value) ([]string, bool) {
+	argv := make([]string, len(args))
+	for i, arg := range args {
+		var s String
+		if err := arg.Decode(&s); err != nil {
+			return nil, false
+		}
+
+		argv[i] = string(s)
+	}
+
+	return argv, true
 }
@@ -0,0 +1,224 @@
+package bass_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/basstest"
+	"github.com/vito/bass/pkg/ioctx"
+	"github.com/vito/is"
+)
+
+const mainWithSpec = `
+; deploys the app
+^{:flags {:env {:default "staging" :doc "environment to deploy to"}
+          :replicas {:default 1 :doc "number of replicas"}
+          :dry-run {:type :bool}
+          :tag {:type :strings}
+          :token {}}
+  :args [{:name :target :doc "thing to deploy"}]
+  :rest :extra}
+(defn main [opts target & extra]
+  (emit [opts target extra] *stdout*))
+`
+
+func TestRunMainSpec(t *testing.T) {
+	for _, example := range []struct {
+		Name        string
+		Args        []bass.Value
+		Result      bass.Value
+		ErrContains string
+		Usage       string
+	}{
+		{
+			Name: "defaults",
+			Args: []bass.Value{bass.String("app")},
+			Result: bass.NewList(
+				bass.Bindings{
+					"env":      bass.String("staging"),
+					"replicas": bass.Int(1),
+					"dry-run":  bass.Bool(false),
+					"tag":      bass.Null{},
+					"token":    bass.Null{},
+				}.Scope(),
+				bass.String("app"),
+				bass.NewList(),
+			),
+		},
+		{
+			Name: "flags",
+			Args: []bass.Value{
+				bass.String("--env"), bass.String("prod"),
+				bass.String("--replicas=3"),
+				bass.String("--dry-run"),
+				bass.String("--tag"), bass.String("a"),
+				bass.String("--tag"), bass.String("b"),
+				bass.String("--token"), bass.String("xyz"),
+				bass.String("app"),
+				bass.String("more"),
+			},
+			Result: bass.NewList(
+				bass.Bindings{
+					"env":      bass.String("prod"),
+					"replicas": bass.Int(3),
+					"dry-run":  bass.Bool(true),
+					"tag":      bass.NewList(bass.String("a"), bass.String("b")),
+					"token":    bass.String("xyz"),
+				}.Scope(),
+				bass.String("app"),
+				bass.NewList(bass.String("more")),
+			),
+		},
+		{
+			Name:        "missing args",
+			Args:        []bass.Value{bass.String("--env"), bass.String("prod")},
+			ErrContains: "missing arguments: target",
+		},
+		{
+			Name:        "bad int",
+			Args:        []bass.Value{bass.String("--replicas"), bass.String("lots"), bass.String("app")},
+			ErrContains: "invalid argument",
+		},
+		{
+			Name:  "help",
+			Args:  []bass.Value{bass.String("--help")},
+			Usage: "usage: [flags] target [extra...]\n\ndeploys the app\n\nargs:\n  target\tthing to deploy\n",
+		},
+		{
+			Name: "non-string args",
+			Args: []bass.Value{bass.Bindings{"env": bass.String("dev")}.Scope(), bass.String("app")},
+			Result: bass.NewList(
+				bass.Bindings{"env": bass.String("dev")}.Scope(),
+				bass.String("app"),
+				bass.NewList(),
+			),
+		},
+	} {
+		example := example
+		t.Run(example.Name, func(t *testing.T) {
+			is := is.New(t)
+
+			stderr := new(bytes.Buffer)
+			ctx := ioctx.StderrToContext(context.Background(), stderr)
+
+			sink := bass.NewInMemorySink()
+			scope := bass.NewRunScope(bass.NewStandardScope(), bass.RunState{
+				Stdout: bass.NewSink(sink),
+			})
+
+			_, err := bass.EvalFSFile(ctx, scope, bass.NewInMemoryFile("main.bass", mainWithSpec))
+			is.NoErr(err)
+
+			err = bass.RunMain(ctx, scope, example.Args...)
+			if example.ErrContains != "" {
+				is.True(err != nil)
+				is.True(bytes.Contains([]byte(err.Error()), []byte(example.ErrContains)))
+				return
+			}
+
+			is.NoErr(err)
+
+			if example.Usage != "" {
+				is.True(bytes.HasPrefix(stderr.Bytes(), []byte(example.Usage)))
+				is.True(bytes.Contains(stderr.Bytes(), []byte(`--env string`)))
+				is.Equal(len(sink.Values), 0)
+				return
+			}
+
+			is.Equal(len(sink.Values), 1)
+			basstest.Equal(t, sink.Values[0], example.Result)
+		})
+	}
+}
+
+const scriptWithCommands = `
+; builds the thing
+;
+; more details
+^{:flags {:race {:type :bool}}}
+(defcommand build [opts & pkgs]
+  (emit [:build opts pkgs] *stdout*))
+
+(defcommand deploy [& args]
+  (emit [:deploy args] *stdout*))
+`
+
+func TestRunMainCommands(t *testing.T) {
+	for _, example := range []struct {
+		Name        string
+		Main        string
+		Args        []bass.Value
+		Result      bass.Value
+		ErrContains string
+		Usage       string
+	}{
+		{
+			Name: "command with spec",
+			Args: []bass.Value{bass.String("build"), bass.String("--race")},
+			Result: bass.NewList(
+				bass.Symbol("build"),
+				bass.Bindings{"race": bass.Bool(true)}.Scope(),
+				bass.NewList(),
+			),
+		},
+		{
+			Name: "command without spec",
+			Args: []bass.Value{bass.String("deploy"), bass.String("--env"), bass.String("prod")},
+			Result: bass.NewList(
+				bass.Symbol("deploy"),
+				bass.NewList(bass.String("--env"), bass.String("prod")),
+			),
+		},
+		{
+			Name:  "no command",
+			Usage: "usage: command [flags] [args...]\n\ncommands:\n  build\tbuilds the thing\n  deploy\t\n",
+		},
+		{
+			Name:        "unknown command",
+			Args:        []bass.Value{bass.String("test")},
+			ErrContains: "unknown command: test (available: build, deploy)",
+		},
+		{
+			Name:   "falls back to main",
+			Main:   `(defn main args (emit [:main args] *stdout*))`,
+			Args:   []bass.Value{bass.String("test")},
+			Result: bass.NewList(bass.Symbol("main"), bass.NewList(bass.String("test"))),
+		},
+	} {
+		example := example
+		t.Run(example.Name, func(t *testing.T) {
+			is := is.New(t)
+
+			stderr := new(bytes.Buffer)
+			ctx := ioctx.StderrToContext(context.Background(), stderr)
+
+			sink := bass.NewInMemorySink()
+			scope := bass.NewRunScope(bass.NewStandardScope(), bass.RunState{
+				Stdout: bass.NewSink(sink),
+			})
+
+			_, err := bass.EvalFSFile(ctx, scope, bass.NewInMemoryFile("project.bass", scriptWithCommands+example.Main))
+			is.NoErr(err)
+
+			err = bass.RunMain(ctx, scope, example.Args...)
+			if example.ErrContains != "" {
+				is.True(err != nil)
+				is.True(bytes.Contains([]byte(err.Error()), []byte(example.ErrContains)))
+				return
+			}
+
+			is.NoErr(err)
+
+			if example.Usage != "" {
+				is.Equal(stderr.String(), example.Usage)
+				is.Equal(len(sink.Values), 0)
+				return
+			}
+
+			is.Equal(len(sink.Values), 1)
+			basstest.Equal(t, sink.Values[0], example.Result)
+		})
+	}
+}
@@ -12,7 +12,7 @@ import (
 var Secrets = NewEmptyScope()
 
 func init() {
-	Ground.Set("mask",
+	Ground.MustSet("mask",
 		Func("mask", "[secret name]", func(val String, name Symbol) Secret {
 			return NewSecret(name.String(), []byte(val))
 		}),
@@ -21,6 +21,19 @@ func init() {
 		`Prevents the string from being revealed in a serialized thunk or thunk path.`,
 		`Does NOT currently prevent the string's value from being displayed in log output; you still have to be careful there.`,
 		`=> (mask "super secret" :github-token)`)
+
+	Ground.MustSet("secret",
+		Func("secret", "[name]", func(ctx context.Context, name Symbol) (Secret, error) {
+			helper, found := CredentialHelperFromContext(ctx)
+			if !found {
+				return Secret{}, ErrNoCredentialHelper
+			}
+
+			return helper.Secret(ctx, name.String())
+		}),
+		`fetches a named secret from the credential helper`,
+		`The helper is configured by "credential_helper" in the Bass config, defaulting to bass-credential-helper if it is in $PATH. It is run as "bass-credential-helper get secret <name>".`,
+		`Errors if no helper is configured or if the helper has no such secret.`)
 }
 
 type Secret struct {
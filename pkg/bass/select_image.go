@@ -0,0 +1,63 @@
+package bass
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SelectImage picks the image from images whose platform matches the first
+// platform supported by the runtime pool.
+//
+// Images are keyed by platform, either as os-arch (e.g. linux-arm64) or as an
+// os alone to match any architecture. When the selected image is an image ref
+// without an architecture, its platform is pinned to the matched runtime's
+// platform so that the thunk is run on the same runtime.
+func SelectImage(ctx context.Context, images *Scope) (Value, error) {
+	pool, err := RuntimePoolFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type candidate struct {
+		platform Platform
+		image    Value
+	}
+
+	var candidates []candidate
+	err = images.Each(func(key Symbol, image Value) error {
+		os, arch, _ := strings.Cut(key.String(), "-")
+		candidates = append(candidates, candidate{
+			platform: Platform{OS: os, Arch: arch},
+			image:    image,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	available := pool.Platforms()
+	for _, runtimePlatform := range available {
+		for _, c := range candidates {
+			if !c.platform.CanSelect(runtimePlatform) {
+				continue
+			}
+
+			var ref ImageRef
+			if err := c.image.Decode(&ref); err == nil && ref.Platform.Arch == "" {
+				ref.Platform.Arch = runtimePlatform.Arch
+				return ValueOf(ref)
+			}
+
+			return c.image, nil
+		}
+	}
+
+	var platforms []string
+	for _, platform := range available {
+		platforms = append(platforms, platform.String())
+	}
+
+	return nil, fmt.Errorf("no image for available platforms: %s", strings.Join(platforms, "; "))
+}
@@ -0,0 +1,76 @@
+package bass_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	. "github.com/vito/bass/pkg/basstest"
+	"github.com/vito/bass/pkg/runtimes"
+	"github.com/vito/is"
+)
+
+func TestSelectImage(t *testing.T) {
+	ctx := bass.WithRuntimePool(context.Background(), &runtimes.Pool{
+		Runtimes: []runtimes.Assoc{
+			{
+				Platform: bass.Platform{OS: "linux", Arch: "arm64"},
+				Runtime:  &FakeRuntime{},
+			},
+			{
+				Platform: bass.Platform{OS: "linux", Arch: "amd64"},
+				Runtime:  &FakeRuntime{},
+			},
+		},
+	})
+
+	for _, example := range []struct {
+		Name   string
+		Bass   string
+		Result bass.Value
+		Err    string
+	}{
+		{
+			Name:   "first matching runtime",
+			Bass:   `(select-image {:linux-amd64 :a :linux-arm64 :b})`,
+			Result: bass.Symbol("b"),
+		},
+		{
+			Name:   "os only",
+			Bass:   `(select-image {:darwin :a :linux :b})`,
+			Result: bass.Symbol("b"),
+		},
+		{
+			Name: "pins arch",
+			Bass: `(select-image {:linux {:platform {:os "linux"} :repository "alpine" :tag "latest"}})`,
+			Result: bass.Bindings{
+				"platform": bass.Bindings{
+					"os":   bass.String("linux"),
+					"arch": bass.String("arm64"),
+				}.Scope(),
+				"repository": bass.String("alpine"),
+				"tag":        bass.String("latest"),
+			}.Scope(),
+		},
+		{
+			Name: "no match",
+			Bass: `(select-image {:darwin-arm64 :a :windows :b})`,
+			Err:  "no image for available platforms: os=linux, arch=arm64; os=linux, arch=amd64",
+		},
+	} {
+		example := example
+		t.Run(example.Name, func(t *testing.T) {
+			is := is.New(t)
+
+			res, err := bass.EvalFSFile(ctx, bass.NewStandardScope(), bass.NewInMemoryFile("test", example.Bass))
+			if example.Err != "" {
+				is.True(err != nil)
+				is.Equal(err.Error(), example.Err)
+				return
+			}
+
+			is.NoErr(err)
+			Equal(t, res, example.Result)
+		})
+	}
+}
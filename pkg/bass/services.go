@@ -0,0 +1,338 @@
+package bass
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultServiceDeadline is the number of seconds a service's health check
+// may take to pass when no deadline is configured.
+const DefaultServiceDeadline = 60
+
+// ServiceNameLabel is the label which WithServices sets on each service
+// thunk to the DNS name derived from its binding name.
+//
+// Runtimes which support it publish the name to the thunks using the
+// service, so that e.g. a service named db can be reached at db rather than
+// its thunk's hash.
+//
+// Like any label, it changes the thunk's hash, so the same thunk used as
+// services with two different names runs as two instances. It is namespaced
+// so that it does not clobber a label set by the user.
+const ServiceNameLabel Symbol = "bass.service"
+
+// ServiceHealthLabel is the label which WithServices sets on each service
+// thunk which has a health check, so that the runtime can run the check
+// against the service each time it starts it. Like ServiceNameLabel, it is
+// namespaced to stay clear of the user's labels.
+const ServiceHealthLabel Symbol = "bass.health"
+
+// dnsLabel matches a valid DNS label, per RFC 1123.
+var dnsLabel = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// Service configures a thunk which provides ports to other thunks.
+//
+// It may be specified either as a bare thunk or as a scope with the
+// following fields.
+type Service struct {
+	// Thunk is the command which runs the service.
+	Thunk Thunk `json:"thunk"`
+
+	// DependsOn lists the names of other services in the same group which
+	// must be started and healthy before this service starts.
+	DependsOn []Symbol `json:"depends-on,omitempty"`
+
+	// Health is an optional command which is run repeatedly until it
+	// succeeds in order to determine whether the service is ready.
+	//
+	// Its env contains the service's addrs.
+	Health *Thunk `json:"health,omitempty"`
+}
+
+// ServiceHealth is a health check which the runtime runs against a service
+// once its ports are ready, before anything using the service may run.
+type ServiceHealth struct {
+	// Check is run repeatedly until it succeeds.
+	//
+	// Its env refers to the service thunk without its health label, which the
+	// runtime should resolve to the service instance being checked.
+	Check Thunk `json:"check"`
+
+	// Deadline is the number of seconds the check may take to pass.
+	Deadline int `json:"deadline"`
+}
+
+var _ Decodable = &Service{}
+
+func (svc *Service) FromValue(val Value) error {
+	var thunk Thunk
+	if err := val.Decode(&thunk); err == nil {
+		svc.Thunk = thunk
+		return nil
+	}
+
+	var scope *Scope
+	if err := val.Decode(&scope); err != nil {
+		return fmt.Errorf("service must be a thunk or a scope: %w", err)
+	}
+
+	return decodeStruct(scope, svc)
+}
+
+// ServicesOpts configures a service group.
+type ServicesOpts struct {
+	// Deadline is the number of seconds that each health check may take to
+	// pass.
+	Deadline int `json:"deadline,omitempty"`
+}
+
+// WithServices adds an address to the thunk's env for each port provided
+// by each service thunk.
+//
+// For a service named db providing a port named pg, DB_PG_ADDR is set to
+// "$host:$port". The first port of each service is also set as DB_ADDR and
+// its host as DB_HOST.
+//
+// The services are started by the runtime when the thunk runs, since their
+// addrs are referenced by its env, and they are stopped once it exits.
+//
+// Each service thunk is labeled with a DNS name derived from its name, e.g.
+// my-db for my_db, which runtimes may publish so that the service can be
+// reached by name from the thunk and from services which depend on it.
+// Names which are not valid DNS labels are not published.
+//
+// Services which depend on other services are given their addrs too, which
+// results in the runtime starting them in topological order. Services with a
+// health check are labeled with it, and the runtime runs the check against
+// each instance it starts before considering the service started, so that
+// dependents wait for it.
+func (thunk Thunk) WithServices(services *Scope, optsList ...ServicesOpts) (Thunk, error) {
+	opts := ServicesOpts{
+		Deadline: DefaultServiceDeadline,
+	}
+
+	for _, o := range optsList {
+		if o.Deadline != 0 {
+			opts.Deadline = o.Deadline
+		}
+	}
+
+	specs := map[Symbol]Service{}
+	var names []Symbol
+	err := services.Each(func(name Symbol, val Value) error {
+		var svc Service
+		if err := val.Decode(&svc); err != nil {
+			return fmt.Errorf("service %s: %w", name, err)
+		}
+
+		// names which aren't valid DNS labels are not published; the service
+		// is still reachable through its addrs
+		if host, ok := serviceHostName(name); ok {
+			svc.Thunk = svc.Thunk.WithLabel(ServiceNameLabel, String(host))
+		}
+
+		specs[name] = svc
+		names = append(names, name)
+		return nil
+	})
+	if err != nil {
+		return Thunk{}, err
+	}
+
+	order, err := serviceOrder(names, specs)
+	if err != nil {
+		return Thunk{}, err
+	}
+
+	envs := map[Symbol]*Scope{}
+	for _, name := range order {
+		svc := specs[name]
+
+		for _, dep := range svc.DependsOn {
+			svc.Thunk = svc.Thunk.WithEnv(envs[dep])
+		}
+
+		if svc.Health != nil {
+			env, err := serviceEnv(name, svc.Thunk)
+			if err != nil {
+				return Thunk{}, err
+			}
+
+			svc.Thunk = svc.Thunk.WithServiceHealth(ServiceHealth{
+				Check:    svc.Health.WithEnv(env),
+				Deadline: opts.Deadline,
+			})
+		}
+
+		env, err := serviceEnv(name, svc.Thunk)
+		if err != nil {
+			return Thunk{}, err
+		}
+
+		envs[name] = env
+	}
+
+	for _, name := range names {
+		thunk = thunk.WithEnv(envs[name])
+	}
+
+	return thunk, nil
+}
+
+// serviceOrder sorts the services so that each service comes after the
+// services it depends on.
+func serviceOrder(names []Symbol, specs map[Symbol]Service) ([]Symbol, error) {
+	var order []Symbol
+
+	visited := map[Symbol]bool{}
+	visiting := map[Symbol]bool{}
+
+	var visit func(Symbol, []Symbol) error
+	visit = func(name Symbol, path []Symbol) error {
+		if visited[name] {
+			return nil
+		}
+
+		path = append(path, name)
+
+		if visiting[name] {
+			chain := make([]string, len(path))
+			for i, n := range path {
+				chain[i] = n.String()
+			}
+
+			return fmt.Errorf("service dependency cycle: %s", strings.Join(chain, " -> "))
+		}
+
+		visiting[name] = true
+
+		for _, dep := range specs[name].DependsOn {
+			if _, found := specs[dep]; !found {
+				return fmt.Errorf("service %s depends on unknown service %s", name, dep)
+			}
+
+			if err := visit(dep, path); err != nil {
+				return err
+			}
+		}
+
+		visiting[name] = false
+		visited[name] = true
+		order = append(order, name)
+
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
+func serviceEnv(name Symbol, svc Thunk) (*Scope, error) {
+	if len(svc.Ports) == 0 {
+		return nil, fmt.Errorf("service %s has no ports", name)
+	}
+
+	prefix := serviceEnvName(name.String())
+
+	env := NewEmptyScope()
+
+	for i, port := range svc.Ports {
+		addr, err := svc.Addr(Symbol(port.Name))
+		if err != nil {
+			return nil, err
+		}
+
+		if i == 0 {
+			env.Set(Symbol(prefix+"_ADDR"), addr)
+
+			host := addr
+			host.Format = "$host"
+			env.Set(Symbol(prefix+"_HOST"), host)
+		}
+
+		env.Set(Symbol(prefix+"_"+serviceEnvName(port.Name)+"_ADDR"), addr)
+	}
+
+	return env, nil
+}
+
+// WithServiceHealth labels the thunk with a health check for the runtime to
+// run each time it starts the thunk as a service.
+func (thunk Thunk) WithServiceHealth(health ServiceHealth) Thunk {
+	return thunk.WithLabel(ServiceHealthLabel, Bindings{
+		"check":    health.Check,
+		"deadline": Int(health.Deadline),
+	}.Scope())
+}
+
+// ServiceHealth returns the health check set by WithServiceHealth, if any,
+// along with the thunk without it, which is the service that the check's
+// addrs refer to.
+func (thunk Thunk) ServiceHealth() (ServiceHealth, Thunk, bool) {
+	if thunk.Labels == nil {
+		return ServiceHealth{}, thunk, false
+	}
+
+	var scope *Scope
+	if err := thunk.Labels.GetDecode(ServiceHealthLabel, &scope); err != nil {
+		return ServiceHealth{}, thunk, false
+	}
+
+	var health ServiceHealth
+	if err := decodeStruct(scope, &health); err != nil {
+		return ServiceHealth{}, thunk, false
+	}
+
+	labels := NewEmptyScope()
+	_ = thunk.Labels.Each(func(key Symbol, val Value) error {
+		if key != ServiceHealthLabel {
+			labels.Set(key, val)
+		}
+
+		return nil
+	})
+
+	if labels.IsEmpty() {
+		labels = nil
+	}
+
+	thunk.Labels = labels
+
+	return health, thunk, true
+}
+
+// ServiceName returns the DNS name assigned to the thunk by WithServices, if
+// it is a service.
+func (thunk Thunk) ServiceName() (string, bool) {
+	if thunk.Labels == nil {
+		return "", false
+	}
+
+	var name string
+	if err := thunk.Labels.GetDecode(ServiceNameLabel, &name); err != nil {
+		return "", false
+	}
+
+	return name, true
+}
+
+// serviceHostName derives a DNS name from the service's name, returning false
+// if it is not a valid DNS label.
+func serviceHostName(name Symbol) (string, bool) {
+	host := strings.ToLower(strings.ReplaceAll(name.String(), "_", "-"))
+	if !dnsLabel.MatchString(host) {
+		return "", false
+	}
+
+	return host, true
+}
+
+func serviceEnvName(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
@@ -17,6 +17,9 @@ type Session struct {
 	// Root is the base level scope inherited by all modules.
 	Root *Scope
 
+	// Limits optionally bounds the resources consumed by each run.
+	Limits *Limits
+
 	modules map[uint64]*Scope
 	mutex   sync.Mutex
 }
@@ -43,6 +46,26 @@ func (session *Session) Run(ctx context.Context, thunk Thunk, state RunState) er
 	return nil
 }
 
+// RunScript evaluates the thunk's script in a new module and calls its
+// entrypoint with the thunk's args, returning the result.
+//
+// Unlike Load, the module is not cached, so each call starts with a fresh
+// scope.
+func (session *Session) RunScript(ctx context.Context, thunk Thunk, state RunState) (Value, error) {
+	module, err := session.run(ctx, thunk, state, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return CallMain(ctx, module, thunk.Args...)
+}
+
+// Evaluate evaluates the thunk's script in a new module without calling its
+// entrypoint, returning the module.
+func (session *Session) Evaluate(ctx context.Context, thunk Thunk, state RunState) (*Scope, error) {
+	return session.run(ctx, thunk, state, false)
+}
+
 func (session *Session) Load(ctx context.Context, thunk Thunk) (*Scope, error) {
 	key, err := thunk.HashKey()
 	if err != nil {
@@ -54,6 +77,7 @@ func (session *Session) Load(ctx context.Context, thunk Thunk) (*Scope, error) {
 	session.mutex.Unlock()
 
 	if cached {
+		recordModuleDep(ctx, thunk.Cmd)
 		return module, nil
 	}
 
@@ -70,6 +94,12 @@ func (session *Session) Load(ctx context.Context, thunk Thunk) (*Scope, error) {
 }
 
 func (session *Session) run(ctx context.Context, thunk Thunk, state RunState, runMain bool) (*Scope, error) {
+	if session.Limits != nil && limiterFromContext(ctx) == nil {
+		ctx = WithLimits(ctx, *session.Limits)
+	}
+
+	ctx = recordModuleDep(ctx, thunk.Cmd)
+
 	var module *Scope
 
 	if thunk.Cmd.Cmd != nil {
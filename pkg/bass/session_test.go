@@ -41,6 +41,10 @@ func TestBass(t *testing.T) {
 			File:   "use.bass",
 			Result: bass.String("61,2,3"),
 		},
+		{
+			File:   "run-script.bass",
+			Result: bass.NewList(bass.Int(3), bass.Int(7)),
+		},
 		{
 			File:   "env.bass",
 			Result: bass.NewList(bass.String("123"), bass.String("123")),
@@ -0,0 +1,73 @@
+package bass
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// SourceMaps records where scripts which are run from a bundle were
+// originally written, so that error locations refer to the original files
+// rather than an in-memory filesystem or the bundle cache.
+var SourceMaps = NewSourceMap()
+
+// SourceMap maps the files of embedded filesystems and copied directories
+// back to the host directories they came from.
+type SourceMap struct {
+	fss  map[fs.FS]string
+	dirs map[string]string
+	mu   sync.Mutex
+}
+
+// NewSourceMap returns an empty source map.
+func NewSourceMap() *SourceMap {
+	return &SourceMap{
+		fss:  map[fs.FS]string{},
+		dirs: map[string]string{},
+	}
+}
+
+// MapFS maps the files in the filesystem to the same paths under the origin
+// directory.
+func (m *SourceMap) MapFS(fsys fs.FS, origin string) {
+	m.mu.Lock()
+	m.fss[fsys] = origin
+	m.mu.Unlock()
+}
+
+// MapDir maps the files under the host directory to the same paths under the
+// origin directory.
+func (m *SourceMap) MapDir(dir, origin string) {
+	m.mu.Lock()
+	m.dirs[filepath.Clean(dir)] = origin
+	m.mu.Unlock()
+}
+
+// Origin returns the original path of the file, if it has been mapped.
+func (m *SourceMap) Origin(file Readable) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch x := file.(type) {
+	case *FSPath:
+		origin, found := m.fss[x.FS]
+		if !found {
+			return "", false
+		}
+
+		return filepath.Join(origin, x.Path.FilesystemPath().FromSlash()), true
+	case HostPath:
+		path := x.FromSlash()
+		for dir, origin := range m.dirs {
+			rel, err := filepath.Rel(dir, path)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				continue
+			}
+
+			return filepath.Join(origin, rel), true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,247 @@
+package bass
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+func init() {
+	Ground.MustSet("ssh-exec",
+		Func("ssh-exec", "[target command]", func(ctx context.Context, target SSHTarget, command Value) (*Scope, error) {
+			cmdline, err := sshCmdline(command)
+			if err != nil {
+				return nil, err
+			}
+
+			client, err := target.Dial(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			defer client.Close()
+
+			stdout := new(bytes.Buffer)
+			stderr := new(bytes.Buffer)
+
+			exit, err := sshRun(client, cmdline, nil, stdout, stderr)
+			if err != nil {
+				return nil, err
+			}
+
+			return Bindings{
+				"exit":    Int(exit),
+				"stdout":  String(stdout.String()),
+				"stderr":  String(stderr.String()),
+				"success": Bool(exit == 0),
+			}.Scope(), nil
+		}),
+		`runs a command on a remote machine over SSH`,
+		`The target is a scope with the :host to connect to, the :user to log in as, and optionally a :port and a private :key, typically a secret. Without a :key, keys are requested from ssh-agent.`,
+		`The host key is verified against :host_key, in authorized_keys format, or the :known_hosts file, which defaults to ~/.ssh/known_hosts.`,
+		`The command is either a string, which is interpreted by the remote shell, or a list of arguments, which are quoted.`,
+		`Returns a scope containing the :exit status, :stdout, :stderr, and whether the command was a :success. A command which fails does not raise an error.`,
+		`=> (ssh-exec {:host "deploy.example.com" :user "deploy" :key (secret :deploy-key)} ["systemctl" "restart" "app"])`)
+
+	Ground.MustSet("ssh-copy",
+		Func("ssh-copy", "[target src dest]", func(ctx context.Context, target SSHTarget, src Readable, dest string) error {
+			rc, err := src.Open(ctx)
+			if err != nil {
+				return err
+			}
+
+			defer rc.Close()
+
+			client, err := target.Dial(ctx)
+			if err != nil {
+				return err
+			}
+
+			defer client.Close()
+
+			stderr := new(bytes.Buffer)
+			exit, err := sshRun(client, "cat > "+shellQuote(dest), rc, io.Discard, stderr)
+			if err != nil {
+				return err
+			}
+
+			if exit != 0 {
+				return fmt.Errorf("copy to %s: exit status %d: %s", dest, exit, strings.TrimSpace(stderr.String()))
+			}
+
+			return nil
+		}),
+		`copies a file to a remote machine over SSH`,
+		`Takes the same target as (ssh-exec), a readable path to copy, like a thunk path or host path, and the destination path on the remote machine.`,
+		`=> (ssh-copy {:host "deploy.example.com" :user "deploy" :key (secret :deploy-key)} built/app "/opt/app/bin/app")`)
+}
+
+// SSHTarget is a remote machine targeted by (ssh-exec) and (ssh-copy).
+type SSHTarget struct {
+	// Host is the hostname or address to connect to.
+	Host string `json:"host"`
+
+	// Port is the port to connect to, defaulting to 22.
+	Port int `json:"port,omitempty"`
+
+	// User is the user to log in as.
+	User string `json:"user"`
+
+	// Key is a PEM-encoded private key, either as a Secret or a string.
+	Key Value `json:"key,omitempty"`
+
+	// HostKey is the expected host key, in authorized_keys format.
+	HostKey string `json:"host_key,omitempty"`
+
+	// KnownHosts is the path to a known_hosts file to verify the host key
+	// against, defaulting to ~/.ssh/known_hosts.
+	KnownHosts string `json:"known_hosts,omitempty"`
+}
+
+// Dial connects to the target through the configured network.
+func (target SSHTarget) Dial(ctx context.Context) (*ssh.Client, error) {
+	config, err := target.clientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	port := target.Port
+	if port == 0 {
+		port = 22
+	}
+
+	addr := net.JoinHostPort(target.Host, strconv.Itoa(port))
+
+	network, _ := NetworkConfigFromContext(ctx)
+	conn, err := network.DialContext(ctx, &net.Dialer{}, addr)
+	if err != nil {
+		return nil, fmt.Errorf("ssh %s: %w", addr, err)
+	}
+
+	clientConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("ssh %s: %w", addr, err)
+	}
+
+	return ssh.NewClient(clientConn, chans, reqs), nil
+}
+
+func (target SSHTarget) clientConfig() (*ssh.ClientConfig, error) {
+	config := &ssh.ClientConfig{
+		User: target.User,
+	}
+
+	if target.HostKey != "" {
+		key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(target.HostKey))
+		if err != nil {
+			return nil, fmt.Errorf("parse host_key: %w", err)
+		}
+
+		config.HostKeyCallback = ssh.FixedHostKey(key)
+		config.HostKeyAlgorithms = []string{key.Type()}
+	} else {
+		knownHosts := target.KnownHosts
+		if knownHosts == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, err
+			}
+
+			knownHosts = filepath.Join(home, ".ssh", "known_hosts")
+		}
+
+		check, err := knownhosts.New(knownHosts)
+		if err != nil {
+			return nil, fmt.Errorf("read known_hosts: %w", err)
+		}
+
+		config.HostKeyCallback = check
+	}
+
+	if target.Key != nil {
+		pem, err := revealString(target.Key)
+		if err != nil {
+			return nil, fmt.Errorf("key: %w", err)
+		}
+
+		signer, err := ssh.ParsePrivateKey([]byte(pem))
+		if err != nil {
+			return nil, fmt.Errorf("parse key: %w", err)
+		}
+
+		config.Auth = []ssh.AuthMethod{ssh.PublicKeys(signer)}
+	} else if socket, ok := os.LookupEnv("SSH_AUTH_SOCK"); ok {
+		conn, err := net.Dial("unix", socket)
+		if err != nil {
+			return nil, fmt.Errorf("dial SSH_AUTH_SOCK: %w", err)
+		}
+
+		config.Auth = []ssh.AuthMethod{ssh.PublicKeysCallback(agent.NewClient(conn).Signers)}
+	} else {
+		return nil, fmt.Errorf("no key given and no ssh-agent available")
+	}
+
+	return config, nil
+}
+
+// sshRun runs the command in a new session and returns its exit status.
+func sshRun(client *ssh.Client, cmdline string, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	sess, err := client.NewSession()
+	if err != nil {
+		return 0, fmt.Errorf("ssh session: %w", err)
+	}
+
+	defer sess.Close()
+
+	sess.Stdin = stdin
+	sess.Stdout = stdout
+	sess.Stderr = stderr
+
+	err = sess.Run(cmdline)
+
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitStatus(), nil
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("ssh run: %w", err)
+	}
+
+	return 0, nil
+}
+
+func sshCmdline(command Value) (string, error) {
+	var str string
+	if err := command.Decode(&str); err == nil {
+		return str, nil
+	}
+
+	var args []string
+	if err := command.Decode(&args); err != nil {
+		return "", fmt.Errorf("command must be a string or a list of strings: %w", err)
+	}
+
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+
+	return strings.Join(quoted, " "), nil
+}
+
+func shellQuote(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -0,0 +1,187 @@
+package bass_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	. "github.com/vito/bass/pkg/basstest"
+	"github.com/vito/is"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSSHExec(t *testing.T) {
+	is := is.New(t)
+
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	is.NoErr(err)
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	is.NoErr(err)
+
+	clientPub, clientPriv, err := ed25519.GenerateKey(rand.Reader)
+	is.NoErr(err)
+	authorized, err := ssh.NewPublicKey(clientPub)
+	is.NoErr(err)
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(clientPriv)
+	is.NoErr(err)
+	clientPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})
+
+	var copiedLock sync.Mutex
+	copied := map[string]string{}
+
+	addr := serveSSH(t, hostSigner, authorized, func(cmd string, stdin io.Reader, stdout, stderr io.Writer) int {
+		switch {
+		case cmd == `'echo' 'it'\''s'`:
+			fmt.Fprintln(stdout, "it's")
+			return 0
+		case cmd == "exit 3":
+			fmt.Fprintln(stderr, "bad")
+			return 3
+		case strings.HasPrefix(cmd, "cat > "):
+			content, _ := io.ReadAll(stdin)
+			copiedLock.Lock()
+			copied[strings.TrimPrefix(cmd, "cat > ")] = string(content)
+			copiedLock.Unlock()
+			return 0
+		default:
+			fmt.Fprintln(stderr, "unknown command:", cmd)
+			return 127
+		}
+	})
+
+	host, portStr, err := net.SplitHostPort(addr)
+	is.NoErr(err)
+	port, err := strconv.Atoi(portStr)
+	is.NoErr(err)
+
+	srcDir := t.TempDir()
+	is.NoErr(os.WriteFile(filepath.Join(srcDir, "app"), []byte("binary"), 0644))
+
+	scope := bass.NewStandardScope()
+	scope.Set("target", bass.Bindings{
+		"host":     bass.String(host),
+		"port":     bass.Int(port),
+		"user":     bass.String("deploy"),
+		"key":      bass.NewSecret("deploy-key", clientPEM),
+		"host_key": bass.String(ssh.MarshalAuthorizedKey(hostSigner.PublicKey())),
+	}.Scope())
+	scope.Set("app", bass.NewHostPath(srcDir, bass.ParseFileOrDirPath("app")))
+
+	res, err := bass.EvalFSFile(context.Background(), scope, bass.NewInMemoryFile("test", `
+		[(ssh-exec target ["echo" "it's"])
+		 (ssh-exec target "exit 3")
+		 (ssh-copy target app "/opt/app bin")]
+	`))
+	is.NoErr(err)
+	Equal(t, res, bass.NewList(
+		bass.Bindings{
+			"exit":    bass.Int(0),
+			"stdout":  bass.String("it's\n"),
+			"stderr":  bass.String(""),
+			"success": bass.Bool(true),
+		}.Scope(),
+		bass.Bindings{
+			"exit":    bass.Int(3),
+			"stdout":  bass.String(""),
+			"stderr":  bass.String("bad\n"),
+			"success": bass.Bool(false),
+		}.Scope(),
+		bass.Null{},
+	))
+	copiedLock.Lock()
+	is.Equal(copied, map[string]string{`'/opt/app bin'`: "binary"})
+	copiedLock.Unlock()
+
+	_, otherPriv, err := ed25519.GenerateKey(rand.Reader)
+	is.NoErr(err)
+	otherSigner, err := ssh.NewSignerFromKey(otherPriv)
+	is.NoErr(err)
+
+	scope.Set("wrong-host-key", bass.String(ssh.MarshalAuthorizedKey(otherSigner.PublicKey())))
+	_, err = bass.EvalFSFile(context.Background(), scope, bass.NewInMemoryFile("test", `
+		(ssh-exec (assoc target :host_key wrong-host-key) "true")
+	`))
+	is.True(err != nil)
+	is.True(strings.Contains(err.Error(), "host key mismatch"))
+}
+
+// serveSSH serves a SSH server which runs exec requests with the given
+// handler, returning the address it listens on.
+func serveSSH(t *testing.T, hostKey ssh.Signer, authorized ssh.PublicKey, handler func(string, io.Reader, io.Writer, io.Writer) int) string {
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) != string(authorized.Marshal()) {
+				return nil, fmt.Errorf("unauthorized")
+			}
+
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					return
+				}
+
+				go ssh.DiscardRequests(reqs)
+
+				for newCh := range chans {
+					ch, reqs, err := newCh.Accept()
+					if err != nil {
+						return
+					}
+
+					go func() {
+						defer ch.Close()
+
+						for req := range reqs {
+							if req.Type != "exec" {
+								req.Reply(false, nil)
+								continue
+							}
+
+							var exec struct{ Command string }
+							ssh.Unmarshal(req.Payload, &exec)
+							req.Reply(true, nil)
+
+							status := handler(exec.Command, ch, ch, ch.Stderr())
+							ch.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{uint32(status)}))
+							return
+						}
+					}()
+				}
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
@@ -0,0 +1,202 @@
+package bass
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"time"
+)
+
+// Types of files reported by Stat.
+const (
+	FileTypeFile    = "file"
+	FileTypeDir     = "dir"
+	FileTypeSymlink = "symlink"
+	FileTypeOther   = "other"
+)
+
+// FileInfo is metadata about a file, directory, or symlink.
+type FileInfo struct {
+	// Type is one of FileTypeFile, FileTypeDir, FileTypeSymlink, or
+	// FileTypeOther.
+	Type string
+
+	// Size is the size of the file in bytes. For a directory in a thunk, it is
+	// the total size of its files.
+	Size int64
+
+	// Mode is the file's permission bits.
+	Mode fs.FileMode
+
+	// ModTime is the file's modification time. For a directory in a thunk, it
+	// is the most recent modification time of its contents.
+	ModTime time.Time
+
+	// Target is the path a symlink points to.
+	Target string
+}
+
+// Scope returns the file info as a scope with :type, :size, :mode, :mtime,
+// and, for symlinks, :target fields.
+//
+// The modification time is given in seconds since the Unix epoch, so that it
+// may be compared with (>) and friends.
+func (info FileInfo) Scope() *Scope {
+	scope := Bindings{
+		"type":  String(info.Type),
+		"size":  Int(info.Size),
+		"mode":  Int(info.Mode.Perm()),
+		"mtime": Int(info.ModTime.Unix()),
+	}.Scope()
+
+	if info.Target != "" {
+		scope.Set("target", String(info.Target))
+	}
+
+	return scope
+}
+
+// Stat returns metadata about a host path, thunk path, or embedded filesystem
+// path.
+//
+// Symlinks are not followed. If a host path or embedded filesystem path does
+// not exist, it returns fs.ErrNotExist.
+func Stat(ctx context.Context, p Path) (FileInfo, error) {
+	switch x := p.(type) {
+	case HostPath:
+		return statHostPath(x)
+	case *FSPath:
+		fi, err := fs.Stat(x.FS, path.Clean(x.Path.Slash()))
+		if err != nil {
+			return FileInfo{}, err
+		}
+
+		return fileInfoFromOS(fi, ""), nil
+	case ThunkPath:
+		return x.Stat(ctx)
+	default:
+		return FileInfo{}, PathOpError{"stat", []Path{p}}
+	}
+}
+
+// Stat exports the thunk path's metadata from its runtime.
+//
+// A file's metadata is taken from the first entry of the exported archive, so
+// its content is never read. A directory's size and modification time are
+// derived from all of its entries.
+func (path ThunkPath) Stat(ctx context.Context) (FileInfo, error) {
+	platform := path.Thunk.Platform()
+	if platform == nil {
+		return FileInfo{}, fmt.Errorf("cannot stat bass thunk path: %s", path)
+	}
+
+	runtime, err := RuntimeFromContext(ctx, *platform)
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	r, w := io.Pipe()
+	defer r.Close()
+
+	go func() {
+		w.CloseWithError(runtime.ExportPath(ctx, w, path))
+	}()
+
+	tr := tar.NewReader(r)
+
+	if !path.Path.FilesystemPath().IsDir() {
+		hdr, err := tr.Next()
+		if err != nil {
+			return FileInfo{}, err
+		}
+
+		return fileInfoFromTar(hdr), nil
+	}
+
+	info := FileInfo{
+		Type: FileTypeDir,
+		Mode: 0755,
+	}
+
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return info, nil
+			}
+
+			return FileInfo{}, err
+		}
+
+		if hdr.Typeflag == tar.TypeDir && (hdr.Name == "./" || hdr.Name == ".") {
+			info.Mode = hdr.FileInfo().Mode()
+		}
+
+		if hdr.Typeflag == tar.TypeReg {
+			info.Size += hdr.Size
+		}
+
+		if hdr.ModTime.After(info.ModTime) {
+			info.ModTime = hdr.ModTime
+		}
+	}
+}
+
+func statHostPath(path HostPath) (FileInfo, error) {
+	fp := path.FromSlash()
+
+	fi, err := os.Lstat(fp)
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	var target string
+	if fi.Mode()&fs.ModeSymlink != 0 {
+		target, err = os.Readlink(fp)
+		if err != nil {
+			return FileInfo{}, err
+		}
+	}
+
+	return fileInfoFromOS(fi, target), nil
+}
+
+func fileInfoFromOS(fi fs.FileInfo, target string) FileInfo {
+	return FileInfo{
+		Type:    fileType(fi.Mode()),
+		Size:    fi.Size(),
+		Mode:    fi.Mode().Perm(),
+		ModTime: fi.ModTime(),
+		Target:  target,
+	}
+}
+
+func fileInfoFromTar(hdr *tar.Header) FileInfo {
+	info := fileInfoFromOS(hdr.FileInfo(), "")
+	if hdr.Typeflag == tar.TypeSymlink {
+		info.Target = hdr.Linkname
+	}
+
+	return info
+}
+
+func fileType(mode fs.FileMode) string {
+	switch {
+	case mode.IsRegular():
+		return FileTypeFile
+	case mode.IsDir():
+		return FileTypeDir
+	case mode&fs.ModeSymlink != 0:
+		return FileTypeSymlink
+	default:
+		return FileTypeOther
+	}
+}
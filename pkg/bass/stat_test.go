@@ -0,0 +1,117 @@
+package bass_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/basstest"
+	"github.com/vito/is"
+)
+
+func TestStatHostPath(t *testing.T) {
+	is := is.New(t)
+
+	dir := t.TempDir()
+	mtime := time.Unix(1600000000, 0)
+
+	is.NoErr(os.WriteFile(filepath.Join(dir, "file"), []byte("hello"), 0644))
+	is.NoErr(os.Chtimes(filepath.Join(dir, "file"), mtime, mtime))
+
+	fi, err := os.Stat(filepath.Join(dir, "file"))
+	is.NoErr(err)
+
+	scope := bass.NewStandardScope()
+	scope.Set("dir", bass.NewHostDir(dir))
+
+	res, err := bass.EvalFSFile(context.Background(), scope, bass.NewInMemoryFile("test", "(stat dir/file)"))
+	is.NoErr(err)
+	basstest.Equal(t, res, bass.Bindings{
+		"type":  bass.String("file"),
+		"size":  bass.Int(5),
+		"mode":  bass.Int(fi.Mode().Perm()),
+		"mtime": bass.Int(1600000000),
+	}.Scope())
+
+	res, err = bass.EvalFSFile(context.Background(), scope, bass.NewInMemoryFile("test", "(:type (stat dir))"))
+	is.NoErr(err)
+	basstest.Equal(t, res, bass.String("dir"))
+
+	res, err = bass.EvalFSFile(context.Background(), scope, bass.NewInMemoryFile("test", "(stat dir/missing)"))
+	is.NoErr(err)
+	basstest.Equal(t, res, bass.Null{})
+
+	if runtime.GOOS != "windows" {
+		is.NoErr(os.Symlink("file", filepath.Join(dir, "link")))
+
+		res, err = bass.EvalFSFile(context.Background(), scope, bass.NewInMemoryFile("test", "[(:type (stat dir/link)) (:target (stat dir/link))]"))
+		is.NoErr(err)
+		basstest.Equal(t, res, bass.NewList(bass.String("symlink"), bass.String("file")))
+	}
+}
+
+func TestStatThunkPath(t *testing.T) {
+	is := is.New(t)
+
+	thunk := bass.Thunk{
+		Image: &bass.ThunkImage{
+			Ref: &bass.ImageRef{
+				Platform: fakePlatform,
+			},
+		},
+		Cmd: bass.ThunkCmd{Cmd: &bass.CommandPath{"build"}},
+	}
+
+	file := bass.ThunkPath{
+		Thunk: thunk,
+		Path:  bass.ParseFileOrDirPath("out/report.txt"),
+	}
+
+	dir := bass.ThunkPath{
+		Thunk: thunk,
+		Path:  bass.ParseFileOrDirPath("out/"),
+	}
+
+	older := time.Unix(1600000000, 0)
+	newer := time.Unix(1700000000, 0)
+
+	ctx := withFakeRuntime(context.Background(), []ExportPath{
+		{
+			ThunkPath: file,
+			FS: fstest.MapFS{
+				"report.txt": {Data: []byte("hello"), Mode: 0600, ModTime: older},
+			},
+		},
+		{
+			ThunkPath: dir,
+			FS: fstest.MapFS{
+				"report.txt": {Data: []byte("hello"), Mode: 0600, ModTime: older},
+				"sub/data":   {Data: []byte("world!"), Mode: 0644, ModTime: newer},
+			},
+		},
+	})
+
+	info, err := bass.Stat(ctx, file)
+	is.NoErr(err)
+	is.Equal(info.Type, bass.FileTypeFile)
+	is.Equal(info.Size, int64(5))
+	is.Equal(info.ModTime.Unix(), older.Unix())
+
+	info, err = bass.Stat(ctx, dir)
+	is.NoErr(err)
+	is.Equal(info.Type, bass.FileTypeDir)
+	is.Equal(info.Size, int64(11))
+	is.Equal(info.ModTime.Unix(), newer.Unix())
+}
+
+func TestStatCommandPath(t *testing.T) {
+	is := is.New(t)
+
+	_, err := bass.Stat(context.Background(), bass.CommandPath{"foo"})
+	is.True(err != nil)
+}
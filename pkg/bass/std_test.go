@@ -0,0 +1,65 @@
+package bass_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/runtimes/fake"
+	"github.com/vito/is"
+)
+
+func TestNixImage(t *testing.T) {
+	for _, example := range []struct {
+		Installable string
+		Flake       string
+		Pinned      string
+	}{
+		{
+			Installable: "nixpkgs#go_1_22",
+			Flake:       "nixpkgs",
+			Pinned:      "github:NixOS/nixpkgs/abc123#go_1_22",
+		},
+		{
+			Installable: "nixpkgs",
+			Flake:       "nixpkgs",
+			Pinned:      "github:NixOS/nixpkgs/abc123",
+		},
+	} {
+		example := example
+		t.Run(example.Installable, func(t *testing.T) {
+			is := is.New(t)
+
+			runtime := fake.NewRuntime()
+			runtime.Stub(func(thunk bass.Thunk) bool {
+				return strings.Contains(thunk.Cmdline(), "flake metadata --json "+example.Flake)
+			}, fake.Result{
+				Stdout: []byte(`{"url":"github:NixOS/nixpkgs/abc123"}`),
+			})
+
+			ctx := fake.WithRuntime(context.Background(), runtime)
+
+			res, err := bass.EvalFSFile(ctx, bass.NewStandardScope(), bass.NewInMemoryFile("test", `
+				(use (.nix {:platform {:os "linux"} :repository "nixos/nix" :tag "latest"}))
+				(nix:image "`+example.Installable+`" 0)
+			`))
+			is.NoErr(err)
+
+			var thunk bass.Thunk
+			is.NoErr(res.Decode(&thunk))
+			is.True(strings.HasSuffix(thunk.Cmdline(), "profile install "+example.Pinned))
+		})
+	}
+}
+
+func TestNotifyRender(t *testing.T) {
+	is := is.New(t)
+
+	res, err := bass.EvalFSFile(context.Background(), bass.NewStandardScope(), bass.NewInMemoryFile("test", `
+		(use (.notify {:platform {:os "linux"} :repository "curlimages/curl" :tag "latest"}))
+		(notify:render "build {{status}} after {{attempts}} attempts }}" {:status "passed" :attempts 3})
+	`))
+	is.NoErr(err)
+	is.Equal(res, bass.String("build passed after 3 attempts }}"))
+}
@@ -31,4 +31,10 @@ func init() {
 			fmt.Fprintf(stderr, aec.YellowF.Apply("eval ground %s: %s\n"), lib, err)
 		}
 	}
+
+	// protect the stdlib from being clobbered by anything sharing it
+	//
+	// NB: init funcs run in file name order, so builtins must be registered in
+	// files sorting before this one; registering one later panics in MustSet
+	Ground.Freeze()
 }
@@ -11,7 +11,7 @@ var _ Value = Symbol("")
 
 func SymbolFromJSONKey(key string) Symbol {
 	// NB: this used to translate _ to -, but that was a silly idea.
-	return Symbol(key)
+	return Intern(key)
 }
 
 func (value Symbol) String() string {
@@ -58,19 +58,21 @@ func (value Symbol) Decode(dest any) error {
 }
 
 // Eval returns the value.
-func (value Symbol) Eval(_ context.Context, scope *Scope, cont Cont) ReadyCont {
+func (value Symbol) Eval(ctx context.Context, scope *Scope, cont Cont) ReadyCont {
 	res, found := scope.Get(value)
 	if !found {
 		return cont.Call(nil, UnboundError{value, scope})
 	}
 
-	return cont.Call(res, nil)
+	return cont.Call(derefDynamic(ctx, res), nil)
 }
 
 var _ Bindable = Symbol("")
 
 func (binding Symbol) Bind(ctx context.Context, scope *Scope, cont Cont, val Value, doc ...Annotated) ReadyCont {
-	scope.Set(binding, val)
+	if err := scope.Set(binding, val); err != nil {
+		return cont.Call(nil, err)
+	}
 
 	// if len(doc) > 0 {
 	// 	scope.SetDoc(binding, doc[0])
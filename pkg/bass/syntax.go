@@ -0,0 +1,390 @@
+package bass
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Syntax is a parsed source file which retains all of its comments and
+// whitespace, so that tools like formatters and codemods can rewrite some of
+// its forms and write the rest back unchanged.
+type Syntax struct {
+	// Nodes contains each top-level form, in order.
+	Nodes []*SyntaxNode
+
+	// Trailer is any whitespace and comments following the last form.
+	Trailer string
+}
+
+// SyntaxNode is a top-level form along with the source text surrounding it.
+//
+// A node's comment is the run of comment lines directly above the form, with
+// no blank line in between. Comments separated from a form by a blank line are
+// left in Space and are not attached to anything.
+type SyntaxNode struct {
+	// Form is the value read from Source.
+	Form Value
+
+	// Source is the literal source text of the form.
+	Source string
+
+	// Space is any whitespace and unattached comments preceding the node.
+	Space string
+
+	// CommentLines are the raw lines of the node's attached comment, each
+	// including its indentation and trailing newline.
+	CommentLines []string
+
+	// Indent is the whitespace between the start of the form's line and the
+	// form.
+	Indent string
+
+	// Trailing is a comment following the form on the same line, including
+	// the whitespace before it.
+	Trailing string
+}
+
+// ParseSyntax reads every form in the source, retaining the text around them.
+//
+// Writing the result with String returns the original source.
+func ParseSyntax(src string, file Readable) (*Syntax, error) {
+	reader := NewReader(strings.NewReader(src), file)
+
+	var forms []Value
+	for {
+		form, err := reader.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, err
+		}
+
+		forms = append(forms, form)
+	}
+
+	syntax := &Syntax{}
+
+	offset := 0
+	for _, form := range forms {
+		start := skipTrivia(src, offset)
+		end := scanForm(src, start)
+
+		trailEnd := scanTrailing(src, end)
+
+		node := &SyntaxNode{
+			Form:     form,
+			Source:   src[start:end],
+			Trailing: src[end:trailEnd],
+		}
+
+		node.splitTrivia(src[offset:start])
+
+		syntax.Nodes = append(syntax.Nodes, node)
+
+		offset = trailEnd
+	}
+
+	if skipTrivia(src, offset) != len(src) {
+		return nil, fmt.Errorf("syntax: read %d forms but found more in source", len(forms))
+	}
+
+	syntax.Trailer = src[offset:]
+
+	return syntax, nil
+}
+
+// String returns the source text of the syntax.
+func (syntax *Syntax) String() string {
+	var out strings.Builder
+	for _, node := range syntax.Nodes {
+		out.WriteString(node.String())
+	}
+
+	out.WriteString(syntax.Trailer)
+
+	return out.String()
+}
+
+// Forms returns the form of each node.
+func (syntax *Syntax) Forms() []Value {
+	forms := make([]Value, len(syntax.Nodes))
+	for i, node := range syntax.Nodes {
+		forms[i] = node.Form
+	}
+
+	return forms
+}
+
+// Append adds a form to the end of the syntax, preceded by a blank line and
+// the given comment, if any.
+func (syntax *Syntax) Append(form Value, comment string) *SyntaxNode {
+	node := &SyntaxNode{
+		Space: syntax.Trailer,
+	}
+
+	if len(syntax.Nodes) > 0 {
+		for !strings.HasSuffix(node.Space, "\n\n") {
+			node.Space += "\n"
+		}
+	}
+
+	syntax.Trailer = "\n"
+
+	node.SetForm(form)
+	node.Attach(comment)
+
+	syntax.Nodes = append(syntax.Nodes, node)
+
+	return node
+}
+
+// String returns the source text of the node.
+func (node *SyntaxNode) String() string {
+	var out strings.Builder
+	out.WriteString(node.Space)
+	for _, line := range node.CommentLines {
+		out.WriteString(line)
+	}
+	out.WriteString(node.Indent)
+	out.WriteString(node.Source)
+	out.WriteString(node.Trailing)
+	return out.String()
+}
+
+// SetForm replaces the node's form, rendering it as its source text. The
+// node's comments and surrounding whitespace are left intact.
+func (node *SyntaxNode) SetForm(form Value) {
+	node.Form = form
+	node.Source = form.String()
+	node.syncComment()
+}
+
+// Comment returns the text of the node's attached comment, in the same form
+// the reader gives to documentation.
+func (node *SyntaxNode) Comment() string {
+	var paras []string
+	var para []string
+	for _, line := range node.CommentLines {
+		text := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), ";"))
+		if text == "" {
+			if len(para) > 0 {
+				paras = append(paras, strings.Join(para, " "))
+			}
+
+			para = nil
+		} else {
+			para = append(para, text)
+		}
+	}
+
+	if len(para) > 0 {
+		paras = append(paras, strings.Join(para, " "))
+	}
+
+	return strings.Join(paras, "\n\n")
+}
+
+// Detach removes the node's attached comment and returns its text.
+func (node *SyntaxNode) Detach() string {
+	comment := node.Comment()
+	node.CommentLines = nil
+	node.syncComment()
+	return comment
+}
+
+// Attach replaces the node's attached comment. Paragraphs are separated by
+// blank lines. An empty comment detaches the node's comment.
+func (node *SyntaxNode) Attach(comment string) {
+	node.CommentLines = nil
+
+	comment = strings.TrimSpace(comment)
+	if comment != "" {
+		// comment lines must begin on their own line
+		if node.Space != "" && !strings.HasSuffix(node.Space, "\n") {
+			node.Space += "\n"
+		}
+
+		for i, para := range strings.Split(comment, "\n\n") {
+			if i > 0 {
+				node.CommentLines = append(node.CommentLines, node.Indent+";\n")
+			}
+
+			for _, line := range strings.Split(para, "\n") {
+				node.CommentLines = append(node.CommentLines, node.Indent+"; "+strings.TrimSpace(line)+"\n")
+			}
+		}
+	}
+
+	node.syncComment()
+}
+
+// syncComment updates the form's comment to match the source, preferring the
+// attached comment over a trailing comment like the reader does.
+func (node *SyntaxNode) syncComment() {
+	var ann Annotate
+	if node.Form == nil || node.Form.Decode(&ann) != nil {
+		return
+	}
+
+	ann.Comment = node.Comment()
+	if ann.Comment == "" && node.Trailing != "" {
+		ann.Comment = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(node.Trailing), ";"))
+	}
+
+	node.Form = ann
+}
+
+// splitTrivia divides the text preceding a form into unattached space, the
+// attached comment lines, and the form's indentation.
+func (node *SyntaxNode) splitTrivia(trivia string) {
+	lastNL := strings.LastIndexByte(trivia, '\n')
+	node.Indent = trivia[lastNL+1:]
+
+	lines := strings.SplitAfter(trivia[:lastNL+1], "\n")
+	lines = lines[:len(lines)-1] // last is always empty after SplitAfter
+
+	attached := len(lines)
+	for attached > 0 {
+		line := strings.TrimLeftFunc(lines[attached-1], isSpace)
+		if !strings.HasPrefix(line, ";") {
+			break
+		}
+
+		attached--
+	}
+
+	node.Space = strings.Join(lines[:attached], "")
+	node.CommentLines = lines[attached:]
+}
+
+// skipTrivia returns the offset of the next form at or after i, skipping
+// whitespace, comments, and shebang lines.
+func skipTrivia(src string, i int) int {
+	for i < len(src) {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case isSpace(r):
+			i += size
+		case r == ';' || strings.HasPrefix(src[i:], "#!"):
+			i = skipLine(src, i)
+		default:
+			return i
+		}
+	}
+
+	return i
+}
+
+func skipLine(src string, i int) int {
+	nl := strings.IndexByte(src[i:], '\n')
+	if nl == -1 {
+		return len(src)
+	}
+
+	return i + nl
+}
+
+// scanTrailing returns the end of a comment following the form on the same
+// line, or i if there is none.
+func scanTrailing(src string, i int) int {
+	j := i
+	for j < len(src) && src[j] == ' ' {
+		j++
+	}
+
+	if j < len(src) && src[j] == ';' {
+		return skipLine(src, j)
+	}
+
+	return i
+}
+
+// scanForm returns the end of the form starting at i. The source must have
+// already been read successfully.
+func scanForm(src string, i int) int {
+	// number of forms remaining; a meta prefix (^) adds one more
+	pending := 1
+	depth := 0
+
+	for i < len(src) {
+		i = skipTrivia(src, i)
+		if i == len(src) {
+			break
+		}
+
+		r, size := utf8.DecodeRuneInString(src[i:])
+		switch r {
+		case '(', '[', '{':
+			depth++
+			i += size
+			continue
+		case ')', ']', '}':
+			depth--
+			i += size
+		case '^':
+			i += size
+			pending++
+			continue
+		case '"':
+			i = scanString(src, i+size)
+		default:
+			i += size
+			for i < len(src) {
+				r, size := utf8.DecodeRuneInString(src[i:])
+				if isTerminal(r) {
+					break
+				}
+
+				i += size
+			}
+		}
+
+		if depth == 0 {
+			pending--
+			if pending == 0 {
+				break
+			}
+		}
+	}
+
+	return i
+}
+
+func scanString(src string, i int) int {
+	for i < len(src) {
+		switch src[i] {
+		case '\\':
+			i += 2
+		case '"':
+			return i + 1
+		default:
+			i++
+		}
+	}
+
+	return len(src)
+}
+
+func isSpace(r rune) bool {
+	return unicode.IsSpace(r) || r == ','
+}
+
+// isTerminal mirrors the runes which end a token in the reader.
+func isTerminal(r rune) bool {
+	if isSpace(r) {
+		return true
+	}
+
+	switch r {
+	case '"', ';', '\\', '(', ')', '[', ']', '{', '}', '^':
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,139 @@
+package bass_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	. "github.com/vito/bass/pkg/basstest"
+	"github.com/vito/is"
+)
+
+func TestParseSyntaxLossless(t *testing.T) {
+	is := is.New(t)
+
+	var sources []string
+	err := filepath.WalkDir("../..", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if filepath.Ext(path) != bass.Ext {
+			return nil
+		}
+
+		sources = append(sources, path)
+		return nil
+	})
+	is.NoErr(err)
+	is.True(len(sources) > 0)
+
+	for _, path := range sources {
+		t.Run(path, func(t *testing.T) {
+			is := is.New(t)
+
+			src, err := os.ReadFile(path)
+			is.NoErr(err)
+
+			syntax, err := bass.ParseSyntax(string(src), bass.NewInMemoryFile(path, string(src)))
+			if err != nil {
+				// some files are intentionally broken
+				t.Skipf("unreadable: %s", err)
+			}
+
+			is.Equal(syntax.String(), string(src))
+
+			reader := bass.NewReader(strings.NewReader(string(src)), bass.NewInMemoryFile(path, string(src)))
+			for _, node := range syntax.Nodes {
+				form, err := reader.Next()
+				is.NoErr(err)
+				Equal(t, node.Form, form)
+			}
+		})
+	}
+}
+
+func TestParseSyntaxComments(t *testing.T) {
+	is := is.New(t)
+
+	src := `#!/usr/bin/env bass
+
+; a detached comment
+
+; first line
+; second line
+;
+; second paragraph
+(def a 1) ; trailing
+
+(def b
+  ; inner comment
+  2)
+
+^:private
+(def c 3)
+; dangling
+`
+
+	syntax, err := bass.ParseSyntax(src, bass.NewInMemoryFile("test", src))
+	is.NoErr(err)
+	is.Equal(syntax.String(), src)
+	is.Equal(len(syntax.Nodes), 3)
+
+	a, b, c := syntax.Nodes[0], syntax.Nodes[1], syntax.Nodes[2]
+	is.Equal(a.Source, "(def a 1)")
+	is.Equal(a.Comment(), "first line second line\n\nsecond paragraph")
+	is.Equal(a.Trailing, " ; trailing")
+	is.Equal(a.Space, "#!/usr/bin/env bass\n\n; a detached comment\n\n")
+	is.Equal(b.Source, "(def b\n  ; inner comment\n  2)")
+	is.Equal(b.Comment(), "")
+	is.Equal(c.Source, "^:private\n(def c 3)")
+	is.Equal(syntax.Trailer, "\n; dangling\n")
+
+	is.Equal(a.Detach(), "first line second line\n\nsecond paragraph")
+	b.Attach("now documented\n\nwith two paragraphs")
+	c.SetForm(bass.NewList(bass.Symbol("def"), bass.Symbol("c"), bass.Int(4)))
+
+	is.Equal(syntax.String(), `#!/usr/bin/env bass
+
+; a detached comment
+
+(def a 1) ; trailing
+
+; now documented
+;
+; with two paragraphs
+(def b
+  ; inner comment
+  2)
+
+(def c 4)
+; dangling
+`)
+
+	var ann bass.Annotate
+	is.NoErr(syntax.Nodes[1].Form.Decode(&ann))
+	is.Equal(ann.Comment, "now documented\n\nwith two paragraphs")
+
+	is.NoErr(syntax.Nodes[0].Form.Decode(&ann))
+	is.Equal(ann.Comment, "trailing")
+
+	reparsed, err := bass.ParseSyntax(syntax.String(), bass.NewInMemoryFile("test", src))
+	is.NoErr(err)
+	is.Equal(reparsed.String(), syntax.String())
+	is.Equal(reparsed.Nodes[1].Comment(), "now documented\n\nwith two paragraphs")
+}
+
+func TestSyntaxAppend(t *testing.T) {
+	is := is.New(t)
+
+	syntax, err := bass.ParseSyntax("(def a 1)\n", bass.NewInMemoryFile("test", ""))
+	is.NoErr(err)
+
+	syntax.Append(bass.NewList(bass.Symbol("def"), bass.Symbol("b"), bass.Int(2)), "the b")
+
+	is.Equal(syntax.String(), "(def a 1)\n\n; the b\n(def b 2)\n")
+}
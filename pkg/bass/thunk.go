@@ -8,6 +8,7 @@ import (
 	"io"
 	"log"
 	"math/rand"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -37,6 +38,10 @@ type Thunk struct {
 	// the command.
 	Stdin []Value `json:"stdin,omitempty"`
 
+	// StdinFile is a file whose content is streamed to the command's stdin
+	// after any Stdin values, without being read into memory.
+	StdinFile *ThunkMountSource `json:"stdin_file,omitempty"`
+
 	// Env is a mapping from environment variables to their string or path
 	// values.
 	Env *Scope `json:"env,omitempty"`
@@ -78,6 +83,13 @@ type Thunk struct {
 
 	// TLS configures paths to place generated certificates.
 	TLS *ThunkTLS `json:"tls,omitempty"`
+
+	// Retries is the number of times to retry running the thunk if it fails,
+	// for thunks known to be flaky.
+	//
+	// Retries do not change what the thunk does, so they are not included in
+	// its hash or sent to runtimes.
+	Retries int `json:"-"`
 }
 
 type ThunkPort struct {
@@ -129,6 +141,13 @@ func (thunk *Thunk) UnmarshalProto(msg proto.Message) error {
 		thunk.Stdin = append(thunk.Stdin, val)
 	}
 
+	if p.StdinFile != nil {
+		thunk.StdinFile = &ThunkMountSource{}
+		if err := thunk.StdinFile.UnmarshalProto(p.StdinFile); err != nil {
+			return fmt.Errorf("unmarshal proto stdin file: %w", err)
+		}
+	}
+
 	if len(p.Env) > 0 {
 		thunk.Env = NewEmptyScope()
 
@@ -217,7 +236,15 @@ func (thunk Thunk) Run(ctx context.Context) error {
 			return err
 		}
 
-		return runtime.Run(ctx, thunk)
+		noteThunk(ctx, thunk)
+
+		return runCheckpointed(ctx, thunk, func() error {
+			return runWithRetries(ctx, thunk, func() error {
+				return inflightRuns.Run(ctx, thunk, func() error {
+					return runtime.Run(ctx, thunk)
+				})
+			})
+		})
 	} else {
 		return Bass.Run(ctx, thunk, thunk.RunState(io.Discard))
 	}
@@ -241,6 +268,8 @@ func (thunk Thunk) Read(ctx context.Context, w io.Writer) error {
 			return err
 		}
 
+		noteThunk(ctx, thunk)
+
 		return runtime.Read(ctx, w, thunk)
 	} else {
 		return Bass.Run(ctx, thunk, thunk.RunState(w))
@@ -271,7 +300,7 @@ func (thunk Thunk) Start(ctx context.Context, handler Combiner) (Combiner, error
 
 	wg := new(sync.WaitGroup)
 	wg.Add(1)
-	runs.Go(stop, func() error {
+	runs.Go(thunk.String(), stop, func() error {
 		defer wg.Done()
 
 		runErr := thunk.Run(ctx)
@@ -404,18 +433,120 @@ func (thunk Thunk) WithEnv(env *Scope) Thunk {
 	return thunk
 }
 
+// WithInheritedEnv copies the variables in env whose names match any of the
+// glob patterns into the thunk's env. The env is typically the *env* of the
+// entrypoint script, since the system env is cleared on startup.
+//
+// The values are captured when WithInheritedEnv is called, not when the
+// thunk runs. They are passed as secrets named after their variable, so that
+// they are not revealed when the thunk is displayed and do not affect the
+// thunk's hash. This means that a cached result is reused even if a value
+// changes. The names of the matched variables do affect the hash, so a
+// variable which is set in one env but not another results in two different
+// thunks.
+//
+// Variables already set in the thunk's env take precedence; set a variable
+// explicitly with WithEnv to include its value in the hash.
+func (thunk Thunk) WithInheritedEnv(env *Scope, patterns []string) (Thunk, error) {
+	inherited := NewEmptyScope()
+
+	err := env.Each(func(sym Symbol, val Value) error {
+		if thunk.Env != nil && thunk.Env.Binds(sym) {
+			return nil
+		}
+
+		var str string
+		if err := val.Decode(&str); err != nil {
+			// not a string, so it did not come from the system env
+			return nil
+		}
+
+		name := sym.JSONKey()
+		for _, pattern := range patterns {
+			match, err := path.Match(pattern, name)
+			if err != nil {
+				return fmt.Errorf("inherit env %q: %w", pattern, err)
+			}
+
+			if match {
+				inherited.Set(sym, NewSecret(name, []byte(str)))
+				break
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return Thunk{}, err
+	}
+
+	if inherited.IsEmpty() {
+		return thunk, nil
+	}
+
+	if thunk.Env == nil {
+		thunk.Env = inherited
+	} else {
+		thunk.Env = NewEmptyScope(thunk.Env, inherited)
+	}
+
+	return thunk, nil
+}
+
 // WithStdin sets the thunk's stdin values.
 func (thunk Thunk) WithStdin(stdin []Value) Thunk {
 	thunk.Stdin = stdin
 	return thunk
 }
 
+// WithStdinFile sets a file to stream to the thunk's stdin.
+func (thunk Thunk) WithStdinFile(src ThunkMountSource) Thunk {
+	thunk.StdinFile = &src
+	return thunk
+}
+
+// StdoutPath is the path that a thunk's stdout is exported as.
+//
+// Thunk paths referring to stdout are marked by ThunkPath.Stdout rather than
+// by this path, so they never collide with an output file of the same name.
+var StdoutPath = FileOrDirPath{File: &FilePath{Path: "-"}}
+
+// Stdout returns a thunk path referring to the thunk's stdout.
+//
+// Runtimes capture the stdout to the path rather than reading it as values,
+// so it can be mounted or streamed into another thunk without passing
+// through the host.
+func (thunk Thunk) Stdout() ThunkPath {
+	return ThunkPath{
+		Thunk:  thunk,
+		Path:   StdoutPath,
+		Stdout: true,
+	}
+}
+
+// Pipe streams the thunk's stdout to the next thunk's stdin, replacing any
+// stdin file it already had.
+//
+// The returned thunk embeds the thunk, so the pipeline is hashed and cached
+// as a whole.
+func (thunk Thunk) Pipe(next Thunk) Thunk {
+	stdout := thunk.Stdout()
+	return next.WithStdinFile(ThunkMountSource{ThunkPath: &stdout})
+}
+
 // WithInsecure sets whether the thunk should be run insecurely.
 func (thunk Thunk) WithInsecure(insecure bool) Thunk {
 	thunk.Insecure = insecure
 	return thunk
 }
 
+// WithRetries sets the number of times to retry running the thunk if it
+// fails.
+func (thunk Thunk) WithRetries(retries int) Thunk {
+	thunk.Retries = retries
+	return thunk
+}
+
 // WithDir sets the thunk's working directory.
 func (thunk Thunk) WithDir(dir ThunkDir) Thunk {
 	thunk.Dir = &dir
@@ -0,0 +1,246 @@
+package bass
+
+import "fmt"
+
+// ThunkOverrides configures fields to change when deriving a thunk from
+// another thunk with Merge.
+type ThunkOverrides struct {
+	Image     *ThunkImage       `json:"image,omitempty"`
+	Insecure  *bool             `json:"insecure,omitempty"`
+	Cmd       *ThunkCmd         `json:"cmd,omitempty"`
+	Args      *List             `json:"args,omitempty"`
+	Stdin     *List             `json:"stdin,omitempty"`
+	StdinFile *ThunkMountSource `json:"stdin_file,omitempty"`
+	Env       *Scope            `json:"env,omitempty"`
+	Dir       *ThunkDir         `json:"dir,omitempty"`
+	Mounts    []ThunkMount      `json:"mounts,omitempty"`
+	Labels    *Scope            `json:"labels,omitempty"`
+	Ports     *Scope            `json:"ports,omitempty"`
+	TLS       *ThunkTLS         `json:"tls,omitempty"`
+}
+
+// Merge returns a copy of the thunk with the given overrides applied.
+//
+// Env and labels are deep-merged, with nested scopes merged recursively.
+// Mounts and ports are added, replacing any existing mount with the same
+// target or port with the same name. All other fields are replaced.
+func (thunk Thunk) Merge(overrides ThunkOverrides) (Thunk, error) {
+	if overrides.Image != nil {
+		thunk.Image = overrides.Image
+	}
+
+	if overrides.Insecure != nil {
+		thunk.Insecure = *overrides.Insecure
+	}
+
+	if overrides.Cmd != nil {
+		thunk.Cmd = *overrides.Cmd
+	}
+
+	if overrides.Args != nil {
+		args, err := ToSlice(*overrides.Args)
+		if err != nil {
+			return Thunk{}, err
+		}
+
+		thunk.Args = args
+	}
+
+	if overrides.Stdin != nil {
+		stdin, err := ToSlice(*overrides.Stdin)
+		if err != nil {
+			return Thunk{}, err
+		}
+
+		thunk.Stdin = stdin
+	}
+
+	if overrides.StdinFile != nil {
+		thunk.StdinFile = overrides.StdinFile
+	}
+
+	if overrides.Env != nil {
+		env, err := DeepMerge(thunk.Env, overrides.Env)
+		if err != nil {
+			return Thunk{}, err
+		}
+
+		thunk.Env = env
+	}
+
+	if overrides.Dir != nil {
+		thunk.Dir = overrides.Dir
+	}
+
+	if len(overrides.Mounts) > 0 {
+		mounts := make([]ThunkMount, 0, len(thunk.Mounts)+len(overrides.Mounts))
+		for _, mount := range thunk.Mounts {
+			var replaced bool
+			for _, override := range overrides.Mounts {
+				if override.Target.ToValue().Equal(mount.Target.ToValue()) {
+					replaced = true
+					break
+				}
+			}
+
+			if !replaced {
+				mounts = append(mounts, mount)
+			}
+		}
+
+		thunk.Mounts = append(mounts, overrides.Mounts...)
+	}
+
+	if overrides.Labels != nil {
+		labels, err := DeepMerge(thunk.Labels, overrides.Labels)
+		if err != nil {
+			return Thunk{}, err
+		}
+
+		thunk.Labels = labels
+	}
+
+	if overrides.Ports != nil {
+		ports := append([]ThunkPort{}, thunk.Ports...)
+		err := overrides.Ports.Each(func(name Symbol, val Value) error {
+			var port int
+			if err := val.Decode(&port); err != nil {
+				return err
+			}
+
+			for i, existing := range ports {
+				if existing.Name == name.String() {
+					ports[i].Port = port
+					return nil
+				}
+			}
+
+			ports = append(ports, ThunkPort{
+				Name: name.String(),
+				Port: port,
+			})
+
+			return nil
+		})
+		if err != nil {
+			return Thunk{}, err
+		}
+
+		thunk.Ports = ports
+	}
+
+	if overrides.TLS != nil {
+		thunk.TLS = overrides.TLS
+	}
+
+	return thunk, nil
+}
+
+// MergeStrategy determines how DeepMergeWith resolves a symbol bound to
+// values in both scopes which are not both scopes.
+type MergeStrategy string
+
+const (
+	// MergeLastWins takes the value from the override scope.
+	MergeLastWins MergeStrategy = "last-wins"
+
+	// MergeListAppend appends the override list to the base list when both
+	// values are lists, and otherwise takes the value from the override scope.
+	MergeListAppend MergeStrategy = "list-append"
+
+	// MergeErrorOnConflict returns a MergeConflictError unless the values are
+	// equal.
+	MergeErrorOnConflict MergeStrategy = "error-on-conflict"
+)
+
+// DeepMerge returns a new scope containing the bindings of base overlaid with
+// the bindings of override. When both scopes bind a scope to the same symbol,
+// the two scopes are merged recursively.
+//
+// Either scope may be nil.
+func DeepMerge(base, override *Scope) (*Scope, error) {
+	return DeepMergeWith(base, override, MergeLastWins)
+}
+
+// DeepMergeWith is like DeepMerge, but resolves conflicting values with the
+// given strategy.
+func DeepMergeWith(base, override *Scope, strategy MergeStrategy) (*Scope, error) {
+	switch strategy {
+	case MergeLastWins, MergeListAppend, MergeErrorOnConflict:
+	default:
+		return nil, fmt.Errorf("unknown merge strategy: %s", strategy)
+	}
+
+	return deepMerge(base, override, strategy, nil)
+}
+
+func deepMerge(base, override *Scope, strategy MergeStrategy, path []Symbol) (*Scope, error) {
+	merged := NewEmptyScope()
+
+	if base != nil {
+		err := base.Each(func(sym Symbol, val Value) error {
+			merged.Set(sym, val)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if override != nil {
+		// returned as-is rather than wrapped by Each
+		var failed error
+
+		err := override.Each(func(sym Symbol, val Value) error {
+			existing, found := merged.Get(sym)
+			if !found {
+				merged.Set(sym, val)
+				return nil
+			}
+
+			symPath := append(append([]Symbol{}, path...), sym)
+
+			var baseScope, overrideScope *Scope
+			if existing.Decode(&baseScope) == nil && val.Decode(&overrideScope) == nil {
+				sub, err := deepMerge(baseScope, overrideScope, strategy, symPath)
+				if err != nil {
+					failed = err
+					return err
+				}
+
+				merged.Set(sym, sub)
+				return nil
+			}
+
+			switch strategy {
+			case MergeListAppend:
+				var baseList, overrideList []Value
+				if existing.Decode(&baseList) == nil && val.Decode(&overrideList) == nil {
+					val = NewList(append(baseList, overrideList...)...)
+				}
+			case MergeErrorOnConflict:
+				if !existing.Equal(val) {
+					failed = MergeConflictError{
+						Path:     symPath,
+						Base:     existing,
+						Override: val,
+					}
+
+					return failed
+				}
+			}
+
+			merged.Set(sym, val)
+			return nil
+		})
+		if failed != nil {
+			return nil, failed
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return merged, nil
+}
@@ -3,10 +3,15 @@ package bass
 import (
 	"archive/tar"
 	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/vito/bass/pkg/proto"
 	"google.golang.org/protobuf/encoding/protojson"
@@ -16,6 +21,10 @@ import (
 type ThunkPath struct {
 	Thunk Thunk         `json:"thunk"`
 	Path  FileOrDirPath `json:"path"`
+
+	// Stdout is true if the path refers to the thunk's stdout rather than a
+	// file it created.
+	Stdout bool `json:"stdout,omitempty"`
 }
 
 var _ Value = ThunkPath{}
@@ -28,7 +37,8 @@ func (value ThunkPath) Equal(other Value) bool {
 	var o ThunkPath
 	return other.Decode(&o) == nil &&
 		value.Thunk.Equal(o.Thunk) && // TODO test
-		value.Path.ToValue().Equal(o.Path.ToValue())
+		value.Path.ToValue().Equal(o.Path.ToValue()) &&
+		value.Stdout == o.Stdout
 }
 
 func (value *ThunkPath) UnmarshalProto(msg proto.Message) error {
@@ -45,6 +55,8 @@ func (value *ThunkPath) UnmarshalProto(msg proto.Message) error {
 		return err
 	}
 
+	value.Stdout = p.Stdout
+
 	return nil
 }
 
@@ -131,6 +143,7 @@ func (path ThunkPath) Name() string {
 
 func (path ThunkPath) Extend(ext Path) (Path, error) {
 	extended := path
+	extended.Stdout = false
 
 	var err error
 	extended.Path, err = path.Path.Extend(ext)
@@ -141,9 +154,16 @@ func (path ThunkPath) Extend(ext Path) (Path, error) {
 	return extended, nil
 }
 
+// IsStdout returns true if the path refers to the thunk's stdout rather than
+// a file it created.
+func (path ThunkPath) IsStdout() bool {
+	return path.Stdout
+}
+
 func (path ThunkPath) Dir() ThunkPath {
 	dirp := path.Path.File.Dir()
 	path.Path = FileOrDirPath{Dir: &dirp}
+	path.Stdout = false
 	return path
 }
 
@@ -177,15 +197,189 @@ func (path ThunkPath) Open(ctx context.Context) (io.ReadCloser, error) {
 
 	tr := tar.NewReader(r)
 
-	_, err = tr.Next()
+	hdr, err := tr.Next()
 	if err != nil {
 		return nil, err
 	}
 
-	return readCloser{tr, r}, nil
+	return thunkFile{tr, r, hdr}, nil
+}
+
+// Materialize exports the thunk path to a directory in CacheHome and returns
+// it as a host path, for when its bytes must leave the runtime that produced
+// it, e.g. to be mounted into a thunk on another runtime.
+//
+// Thunks on the same runtime should mount the thunk path directly instead,
+// which avoids the export entirely.
+func (path ThunkPath) Materialize(ctx context.Context) (HostPath, error) {
+	platform := path.Thunk.Platform()
+	if platform == nil {
+		return HostPath{}, fmt.Errorf("cannot export bass thunk path: %s", path)
+	}
+
+	runtime, err := RuntimeFromContext(ctx, *platform)
+	if err != nil {
+		return HostPath{}, err
+	}
+
+	digest, err := path.Thunk.Hash()
+	if err != nil {
+		return HostPath{}, err
+	}
+
+	// each path is exported into its own directory, so that exporting a
+	// parent directory never sees a partial export of a child path
+	pathDigest := sha256.Sum256([]byte(path.Path.Slash()))
+	contextDir := filepath.Join(thunkDirsPath(), digest, fmt.Sprintf("%x", pathDigest[:8]))
+
+	fsp := path.Path.FilesystemPath()
+
+	hostPath := NewHostDir(contextDir)
+	if !fsp.IsDir() {
+		hostPath = NewHostPath(contextDir, ParseFileOrDirPath("./"+fsp.Name()))
+	}
+
+	if _, err := os.Stat(contextDir); err == nil {
+		// mark as used, for PruneCache
+		now := time.Now()
+		_ = os.Chtimes(filepath.Dir(contextDir), now, now)
+		return hostPath, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(contextDir), 0700); err != nil {
+		return HostPath{}, err
+	}
+
+	tmp, err := os.MkdirTemp(filepath.Dir(contextDir), ".export.*")
+	if err != nil {
+		return HostPath{}, err
+	}
+
+	defer os.RemoveAll(tmp)
+
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(runtime.ExportPath(ctx, w, path))
+	}()
+
+	defer r.Close()
+
+	if err := untar(r, tmp); err != nil {
+		return HostPath{}, fmt.Errorf("export %s: %w", path, err)
+	}
+
+	if err := os.Rename(tmp, contextDir); err != nil {
+		if _, statErr := os.Stat(contextDir); statErr != nil {
+			return HostPath{}, err
+		}
+
+		// exported concurrently by someone else
+	}
+
+	return hostPath, nil
+}
+
+func thunkDirsPath() string {
+	return filepath.Join(CacheHome, "thunk-dirs")
+}
+
+// untar extracts the tar stream into dir, refusing any paths which escape it,
+// either directly or through a symlink extracted earlier.
+func untar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		name := filepath.Clean(filepath.FromSlash(hdr.Name))
+		if name == "." {
+			continue
+		}
+
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("invalid path in archive: %s", hdr.Name)
+		}
+
+		if err := refuseSymlinks(dir, name); err != nil {
+			return fmt.Errorf("invalid path in archive: %s: %w", hdr.Name, err)
+		}
+
+		target := filepath.Join(dir, name)
+
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+
+		mode := os.FileMode(hdr.Mode & 07777)
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, mode|0700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+			if err != nil {
+				return err
+			}
+
+			_, err = io.Copy(f, tr)
+			f.Close()
+			if err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return err
+			}
+		default:
+			// skip hardlinks and special files
+		}
+	}
+}
+
+// refuseSymlinks returns an error if the relative path or any of its parents
+// within dir is a symlink, since writing through it could escape dir.
+func refuseSymlinks(dir, name string) error {
+	parts := strings.Split(name, string(filepath.Separator))
+
+	cur := dir
+	for _, part := range parts {
+		cur = filepath.Join(cur, part)
+
+		info, err := os.Lstat(cur)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+
+			return err
+		}
+
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return fmt.Errorf("refusing to write through symlink %s", cur)
+		}
+	}
+
+	return nil
 }
 
-type readCloser struct {
+// thunkFile is a file read from the export of a thunk path.
+type thunkFile struct {
 	io.Reader
 	io.Closer
+
+	hdr *tar.Header
+}
+
+// Stat returns the file's info from its header, so that its size is known
+// before reading it, like an *os.File.
+func (file thunkFile) Stat() (fs.FileInfo, error) {
+	return file.hdr.FileInfo(), nil
 }
@@ -1,8 +1,13 @@
 package bass_test
 
 import (
+	"context"
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
+	"testing/fstest"
+	"time"
 
 	"github.com/vito/bass/pkg/bass"
 	. "github.com/vito/bass/pkg/basstest"
@@ -154,3 +159,76 @@ func TestThunkPathExtend(t *testing.T) {
 	is.True(sub == nil)
 	is.True(err != nil)
 }
+
+func TestThunkPathMaterialize(t *testing.T) {
+	is := is.New(t)
+
+	oldHome := bass.CacheHome
+	bass.CacheHome = t.TempDir()
+	defer func() { bass.CacheHome = oldHome }()
+
+	thunk := uniq(bass.Thunk{
+		Image: &bass.ThunkImage{
+			Ref: &bass.ImageRef{
+				Platform: fakePlatform,
+			},
+		},
+		Cmd: bass.ThunkCmd{Cmd: &bass.CommandPath{"build"}},
+	})
+
+	dirPath := bass.ThunkPath{
+		Thunk: thunk,
+		Path:  bass.ParseFileOrDirPath("out/"),
+	}
+
+	filePath := bass.ThunkPath{
+		Thunk: thunk,
+		Path:  bass.ParseFileOrDirPath("out/bin/app"),
+	}
+
+	ctx := withFakeRuntime(context.Background(), []ExportPath{
+		{dirPath, fstest.MapFS{
+			"bin/app":   {Data: []byte("app"), Mode: 0755},
+			"README.md": {Data: []byte("readme"), Mode: 0644},
+		}},
+		{filePath, fstest.MapFS{
+			"app": {Data: []byte("app"), Mode: 0755},
+		}},
+	})
+
+	dir, err := dirPath.Materialize(ctx)
+	is.NoErr(err)
+
+	content, err := os.ReadFile(filepath.Join(dir.FromSlash(), "bin", "app"))
+	is.NoErr(err)
+	is.Equal(string(content), "app")
+
+	content, err = os.ReadFile(filepath.Join(dir.FromSlash(), "README.md"))
+	is.NoErr(err)
+	is.Equal(string(content), "readme")
+
+	file, err := filePath.Materialize(ctx)
+	is.NoErr(err)
+	is.Equal(file.Path.FilesystemPath().Name(), "app")
+
+	content, err = os.ReadFile(file.FromSlash())
+	is.NoErr(err)
+	is.Equal(string(content), "app")
+
+	// exported once, then reused
+	again, err := dirPath.Materialize(withFakeRuntime(context.Background(), nil))
+	is.NoErr(err)
+	is.Equal(again, dir)
+
+	// recently used, so kept
+	freed, err := bass.PruneCache(bass.PruneOpts{KeepDuration: time.Hour})
+	is.NoErr(err)
+	is.Equal(freed, int64(0))
+
+	freed, err = bass.PruneCache(bass.PruneOpts{All: true})
+	is.NoErr(err)
+	is.Equal(freed, int64(len("app")+len("readme")+len("app")))
+
+	_, err = os.Stat(dir.FromSlash())
+	is.True(os.IsNotExist(err))
+}
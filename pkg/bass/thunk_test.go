@@ -1,10 +1,16 @@
 package bass_test
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io/fs"
+	"path"
+	"strings"
 	"testing"
 
 	"github.com/vito/bass/pkg/bass"
+	. "github.com/vito/bass/pkg/basstest"
 	"github.com/vito/is"
 )
 
@@ -35,3 +41,525 @@ func TestThunkHash(t *testing.T) {
 	// always the same value
 	is.Equal(hash, "PM31VIOOJVOPK")
 }
+
+func TestThunkWithServices(t *testing.T) {
+	is := is.New(t)
+
+	db := bass.Thunk{
+		Cmd: bass.ThunkCmd{
+			Cmd: &bass.CommandPath{"postgres"},
+		},
+		Ports: []bass.ThunkPort{
+			{Name: "pg", Port: 5432},
+			{Name: "metrics", Port: 9187},
+		},
+	}
+
+	thunk := bass.Thunk{
+		Cmd: bass.ThunkCmd{
+			Cmd: &bass.CommandPath{"psql"},
+		},
+	}
+
+	withSvcs, err := thunk.WithServices(bass.Bindings{
+		"my-db": db,
+	}.Scope())
+	is.NoErr(err)
+
+	// each service is labeled with the DNS name derived from its name
+	svc := db.WithLabel(bass.ServiceNameLabel, bass.String("my-db"))
+
+	pgAddr, err := svc.Addr("pg")
+	is.NoErr(err)
+
+	metricsAddr, err := svc.Addr("metrics")
+	is.NoErr(err)
+
+	hostAddr := pgAddr
+	hostAddr.Format = "$host"
+
+	is.True(withSvcs.Env.Equal(bass.Bindings{
+		"MY_DB_ADDR":         pgAddr,
+		"MY_DB_HOST":         hostAddr,
+		"MY_DB_PG_ADDR":      pgAddr,
+		"MY_DB_METRICS_ADDR": metricsAddr,
+	}.Scope()))
+
+	name, ok := pgAddr.Thunk.ServiceName()
+	is.True(ok)
+	is.Equal(name, "my-db")
+
+	_, ok = db.ServiceName()
+	is.True(!ok)
+
+	withSvcs, err = thunk.WithServices(bass.Bindings{
+		"MY_DB": db,
+	}.Scope())
+	is.NoErr(err)
+
+	var upperAddr bass.ThunkAddr
+	is.NoErr(withSvcs.Env.GetDecode("MY_DB_ADDR", &upperAddr))
+	name, _ = upperAddr.Thunk.ServiceName()
+	is.Equal(name, "my-db")
+
+	_, err = thunk.WithServices(bass.Bindings{
+		"no-ports": thunk,
+	}.Scope())
+	is.True(err != nil)
+
+	// names which aren't valid DNS labels are still usable through their
+	// addrs, but not published
+	withSvcs, err = thunk.WithServices(bass.Bindings{
+		"my.db": db,
+	}.Scope())
+	is.NoErr(err)
+
+	var dottedAddr bass.ThunkAddr
+	is.NoErr(withSvcs.Env.GetDecode("MY.DB_ADDR", &dottedAddr))
+	_, ok = dottedAddr.Thunk.ServiceName()
+	is.True(!ok)
+}
+
+func TestThunkWithServicesDependencies(t *testing.T) {
+	is := is.New(t)
+
+	db := bass.Thunk{
+		Cmd:   bass.ThunkCmd{Cmd: &bass.CommandPath{"postgres"}},
+		Ports: []bass.ThunkPort{{Name: "pg", Port: 5432}},
+	}
+
+	app := bass.Thunk{
+		Cmd:   bass.ThunkCmd{Cmd: &bass.CommandPath{"app"}},
+		Ports: []bass.ThunkPort{{Name: "http", Port: 80}},
+	}
+
+	check := bass.Thunk{
+		Cmd:  bass.ThunkCmd{Cmd: &bass.CommandPath{"pg_isready"}},
+		Args: []bass.Value{bass.String("-q")},
+	}
+
+	thunk := bass.Thunk{
+		Cmd: bass.ThunkCmd{Cmd: &bass.CommandPath{"run-tests"}},
+	}
+
+	withSvcs, err := thunk.WithServices(bass.Bindings{
+		"app": bass.Bindings{
+			"thunk":      app,
+			"depends-on": bass.NewList(bass.Symbol("db")),
+		}.Scope(),
+		"db": bass.Bindings{
+			"thunk":  db,
+			"health": check,
+		}.Scope(),
+	}.Scope(), bass.ServicesOpts{Deadline: 30})
+	is.NoErr(err)
+
+	var appAddr bass.ThunkAddr
+	err = withSvcs.Env.GetDecode("APP_ADDR", &appAddr)
+	is.NoErr(err)
+
+	// the app is given the db's addr
+	var dbAddr bass.ThunkAddr
+	err = appAddr.Thunk.Env.GetDecode("DB_ADDR", &dbAddr)
+	is.NoErr(err)
+
+	var mainAddr bass.ThunkAddr
+	err = withSvcs.Env.GetDecode("DB_ADDR", &mainAddr)
+	is.NoErr(err)
+	is.True(dbAddr.Thunk.Equal(mainAddr.Thunk))
+
+	// the db is labeled with its health check for the runtime to run
+	health, svc, ok := dbAddr.Thunk.ServiceHealth()
+	is.True(ok)
+	is.Equal(health.Deadline, 30)
+	is.Equal(health.Check.Cmd.Cmd.Command, "pg_isready")
+	is.Equal(health.Check.Args, []bass.Value{bass.String("-q")})
+	is.True(svc.Equal(db.WithLabel(bass.ServiceNameLabel, bass.String("db"))))
+
+	// the check is given the addrs of the db without its health label, which
+	// the runtime resolves to the instance being checked
+	var checkAddr bass.ThunkAddr
+	err = health.Check.Env.GetDecode("DB_PG_ADDR", &checkAddr)
+	is.NoErr(err)
+	is.True(checkAddr.Thunk.Equal(svc))
+
+	is.Equal(checkAddr.Thunk.Name(), svc.Name())
+
+	_, _, ok = app.ServiceHealth()
+	is.True(!ok)
+
+	_, err = thunk.WithServices(bass.Bindings{
+		"a": bass.Bindings{
+			"thunk":      app,
+			"depends-on": bass.NewList(bass.Symbol("b")),
+		}.Scope(),
+		"b": bass.Bindings{
+			"thunk":      db,
+			"depends-on": bass.NewList(bass.Symbol("a")),
+		}.Scope(),
+	}.Scope())
+	is.True(err != nil)
+	is.True(strings.Contains(err.Error(), "cycle"))
+
+	_, err = thunk.WithServices(bass.Bindings{
+		"a": bass.Bindings{
+			"thunk":      app,
+			"depends-on": bass.NewList(bass.Symbol("c")),
+		}.Scope(),
+	}.Scope())
+	is.True(err != nil)
+	is.True(strings.Contains(err.Error(), "unknown service"))
+}
+
+func TestThunkWithServicesUserLabels(t *testing.T) {
+	is := is.New(t)
+
+	db := bass.Thunk{
+		Cmd:   bass.ThunkCmd{Cmd: &bass.CommandPath{"postgres"}},
+		Ports: []bass.ThunkPort{{Name: "pg", Port: 5432}},
+	}.
+		WithLabel("service", bass.String("postgres")).
+		WithLabel("health", bass.String("unchecked"))
+
+	check := bass.Thunk{
+		Cmd: bass.ThunkCmd{Cmd: &bass.CommandPath{"pg_isready"}},
+	}
+
+	thunk := bass.Thunk{
+		Cmd: bass.ThunkCmd{Cmd: &bass.CommandPath{"run-tests"}},
+	}
+
+	withSvcs, err := thunk.WithServices(bass.Bindings{
+		"db": bass.Bindings{
+			"thunk":  db,
+			"health": check,
+		}.Scope(),
+	}.Scope())
+	is.NoErr(err)
+
+	var dbAddr bass.ThunkAddr
+	err = withSvcs.Env.GetDecode("DB_ADDR", &dbAddr)
+	is.NoErr(err)
+
+	name, ok := dbAddr.Thunk.ServiceName()
+	is.True(ok)
+	is.Equal(name, "db")
+
+	// the user's labels keep their values, even without the health check
+	_, svc, ok := dbAddr.Thunk.ServiceHealth()
+	is.True(ok)
+
+	var label string
+	is.NoErr(svc.Labels.GetDecode("service", &label))
+	is.Equal(label, "postgres")
+	is.NoErr(svc.Labels.GetDecode("health", &label))
+	is.Equal(label, "unchecked")
+}
+
+func TestThunkMerge(t *testing.T) {
+	is := is.New(t)
+
+	base := bass.Thunk{
+		Cmd: bass.ThunkCmd{
+			Cmd: &bass.CommandPath{"go"},
+		},
+		Args: []bass.Value{bass.String("build")},
+		Env: bass.Bindings{
+			"A": bass.String("1"),
+			"NESTED": bass.Bindings{
+				"x": bass.Int(1),
+				"y": bass.Int(2),
+			}.Scope(),
+		}.Scope(),
+		Labels: bass.Bindings{
+			"os": bass.String("linux"),
+		}.Scope(),
+		Mounts: []bass.ThunkMount{
+			{
+				Source: bass.ThunkMountSource{
+					ThunkPath: &bass.ThunkPath{
+						Thunk: bass.MustThunk(bass.CommandPath{"foo"}),
+						Path:  bass.ParseFileOrDirPath("out/"),
+					},
+				},
+				Target: bass.ParseFileOrDirPath("foo/"),
+			},
+		},
+		Ports: []bass.ThunkPort{
+			{Name: "http", Port: 80},
+		},
+	}
+
+	var overrides bass.ThunkOverrides
+	err := bass.Bindings{
+		"args": bass.NewList(bass.String("test")),
+		"env": bass.Bindings{
+			"B": bass.String("2"),
+			"NESTED": bass.Bindings{
+				"y": bass.Int(3),
+			}.Scope(),
+		}.Scope(),
+		"labels": bass.Bindings{
+			"arch": bass.String("amd64"),
+		}.Scope(),
+		"mounts": bass.NewList(bass.Bindings{
+			"source": bass.ThunkPath{
+				Thunk: bass.MustThunk(bass.CommandPath{"bar"}),
+				Path:  bass.ParseFileOrDirPath("out/"),
+			},
+			"target": bass.DirPath{"foo"},
+		}.Scope()),
+		"ports": bass.Bindings{
+			"http":  bass.Int(8080),
+			"debug": bass.Int(6060),
+		}.Scope(),
+		"insecure": bass.Bool(true),
+	}.Scope().Decode(&overrides)
+	is.NoErr(err)
+
+	merged, err := base.Merge(overrides)
+	is.NoErr(err)
+
+	is.Equal(merged.Cmd, base.Cmd)
+	is.Equal(merged.Args, []bass.Value{bass.String("test")})
+	is.True(merged.Insecure)
+
+	Equal(t, merged.Env, bass.Bindings{
+		"A": bass.String("1"),
+		"B": bass.String("2"),
+		"NESTED": bass.Bindings{
+			"x": bass.Int(1),
+			"y": bass.Int(3),
+		}.Scope(),
+	}.Scope())
+
+	Equal(t, merged.Labels, bass.Bindings{
+		"os":   bass.String("linux"),
+		"arch": bass.String("amd64"),
+	}.Scope())
+
+	is.Equal(len(merged.Mounts), 1)
+	Equal(t, merged.Mounts[0].Source.ThunkPath.Thunk, bass.MustThunk(bass.CommandPath{"bar"}))
+
+	is.Equal(merged.Ports, []bass.ThunkPort{
+		{Name: "http", Port: 8080},
+		{Name: "debug", Port: 6060},
+	})
+
+	// base is unchanged
+	is.Equal(base.Args, []bass.Value{bass.String("build")})
+	is.Equal(base.Ports, []bass.ThunkPort{{Name: "http", Port: 80}})
+	_, found := base.Env.Get("B")
+	is.True(!found)
+}
+
+func TestThunkWithInheritedEnv(t *testing.T) {
+	is := is.New(t)
+
+	env := bass.Bindings{
+		"CI":         bass.String("true"),
+		"GITHUB_SHA": bass.String("abc123"),
+		"GITHUB_REF": bass.String("refs/heads/main"),
+		"OTHER":      bass.String("nope"),
+	}.Scope()
+
+	thunk := bass.Thunk{
+		Cmd: bass.ThunkCmd{
+			Cmd: &bass.CommandPath{"env"},
+		},
+	}.WithEnv(bass.Bindings{
+		"GITHUB_REF": bass.String("explicit"),
+	}.Scope())
+
+	inherited, err := thunk.WithInheritedEnv(env, []string{"CI", "GITHUB_*"})
+	is.NoErr(err)
+
+	var sha bass.Secret
+	is.NoErr(inherited.Env.GetDecode("GITHUB_SHA", &sha))
+	is.Equal(sha.Name, "GITHUB_SHA")
+	is.Equal(string(sha.Reveal()), "abc123")
+
+	var ci bass.Secret
+	is.NoErr(inherited.Env.GetDecode("CI", &ci))
+	is.Equal(string(ci.Reveal()), "true")
+
+	// explicitly set vars take precedence
+	var ref string
+	is.NoErr(inherited.Env.GetDecode("GITHUB_REF", &ref))
+	is.Equal(ref, "explicit")
+
+	is.True(!inherited.Env.Binds("OTHER"))
+
+	// values do not affect the hash
+	before, err := inherited.SHA256()
+	is.NoErr(err)
+
+	env.Set("GITHUB_SHA", bass.String("def456"))
+
+	again, err := thunk.WithInheritedEnv(env, []string{"CI", "GITHUB_*"})
+	is.NoErr(err)
+
+	after, err := again.SHA256()
+	is.NoErr(err)
+	is.Equal(before, after)
+
+	// the values are captured when the env is inherited
+	is.NoErr(again.Env.GetDecode("GITHUB_SHA", &sha))
+	is.Equal(string(sha.Reveal()), "def456")
+	is.NoErr(inherited.Env.GetDecode("GITHUB_SHA", &sha))
+	is.Equal(string(sha.Reveal()), "abc123")
+
+	// which vars are inherited does affect the hash
+	without, err := thunk.WithInheritedEnv(bass.Bindings{
+		"CI": bass.String("true"),
+	}.Scope(), []string{"CI", "GITHUB_*"})
+	is.NoErr(err)
+
+	missing, err := without.SHA256()
+	is.NoErr(err)
+	is.True(missing != before)
+
+	_, err = thunk.WithInheritedEnv(env, []string{"["})
+	is.True(err != nil)
+
+	// the builtin reads *env* from the calling scope, not the host
+	t.Setenv("BASS_TEST_HOST_ONLY", "leaked")
+
+	scope := bass.NewRunScope(bass.NewStandardScope(), bass.RunState{
+		Env: bass.Bindings{"CI": bass.String("true")}.Scope(),
+	})
+
+	res, err := bass.EvalFSFile(context.Background(), scope, bass.NewInMemoryFile("test", `(with-inherited-env ($ env) ["CI" "BASS_TEST_*"])`))
+	is.NoErr(err)
+
+	var fromScope bass.Thunk
+	is.NoErr(res.Decode(&fromScope))
+	is.NoErr(fromScope.Env.GetDecode("CI", &ci))
+	is.Equal(string(ci.Reveal()), "true")
+	is.True(!fromScope.Env.Binds("BASS_TEST_HOST_ONLY"))
+}
+
+func TestThunkWithStdinFile(t *testing.T) {
+	is := is.New(t)
+
+	scope := bass.NewStandardScope()
+
+	res, err := bass.EvalFSFile(context.Background(), scope, bass.NewInMemoryFile("test", `(with-stdin-file ($ cat) "hello\n")`))
+	is.NoErr(err)
+
+	var thunk bass.Thunk
+	is.NoErr(res.Decode(&thunk))
+	is.True(thunk.StdinFile != nil)
+	is.True(thunk.StdinFile.FSPath != nil)
+
+	content, err := fs.ReadFile(thunk.StdinFile.FSPath.FS, path.Clean(thunk.StdinFile.FSPath.Path.Slash()))
+	is.NoErr(err)
+	is.Equal(string(content), "hello\n")
+
+	// with-stdin streams paths too, rather than reading them as values
+	scope.Set("input", bass.NewHostPath(t.TempDir(), bass.ParseFileOrDirPath("input.json")))
+
+	res, err = bass.EvalFSFile(context.Background(), scope, bass.NewInMemoryFile("test", `(with-stdin ($ jq ".a") input)`))
+	is.NoErr(err)
+
+	var fromPath bass.Thunk
+	is.NoErr(res.Decode(&fromPath))
+	is.Equal(len(fromPath.Stdin), 0)
+	is.True(fromPath.StdinFile != nil)
+	is.True(fromPath.StdinFile.HostPath != nil)
+
+	withFile := thunk.WithStdinFile(bass.ThunkMountSource{
+		ThunkPath: &bass.ThunkPath{
+			Thunk: thunk,
+			Path:  bass.ParseFileOrDirPath("out.json"),
+		},
+	})
+
+	msg, err := withFile.MarshalProto()
+	is.NoErr(err)
+
+	var roundtrip bass.Thunk
+	is.NoErr(roundtrip.UnmarshalProto(msg))
+	Equal(t, roundtrip, withFile)
+
+	// the stdin file is part of the thunk's identity
+	withHash, err := withFile.Hash()
+	is.NoErr(err)
+
+	withFile.StdinFile = nil
+	plainHash, err := withFile.Hash()
+	is.NoErr(err)
+	is.True(plainHash != withHash)
+}
+
+func TestThunkPipe(t *testing.T) {
+	is := is.New(t)
+
+	scope := bass.NewStandardScope()
+
+	eval := func(src string) bass.Thunk {
+		res, err := bass.EvalFSFile(context.Background(), scope, bass.NewInMemoryFile("test", src))
+		is.NoErr(err)
+
+		var thunk bass.Thunk
+		is.NoErr(res.Decode(&thunk))
+		return thunk
+	}
+
+	piped := eval(`(| ($ echo hello) ($ tr "a-z" "A-Z") ($ rev))`)
+	is.Equal(piped.Cmdline(), "rev")
+
+	is.True(piped.StdinFile != nil && piped.StdinFile.ThunkPath != nil)
+	upper := piped.StdinFile.ThunkPath
+	is.True(upper.IsStdout())
+	is.Equal(upper.Thunk.Cmdline(), `tr a-z A-Z`)
+
+	is.True(upper.Thunk.StdinFile != nil && upper.Thunk.StdinFile.ThunkPath != nil)
+	echo := upper.Thunk.StdinFile.ThunkPath
+	is.True(echo.IsStdout())
+	is.Equal(echo.Thunk.Cmdline(), "echo hello")
+	is.True(echo.Thunk.StdinFile == nil)
+
+	// an output file which happens to share the name is not stdout
+	dash := bass.ThunkPath{Thunk: echo.Thunk, Path: bass.StdoutPath}
+	is.True(!dash.IsStdout())
+	is.True(!dash.Equal(*echo))
+
+	payload, err := json.Marshal(echo)
+	is.NoErr(err)
+
+	var decoded bass.ThunkPath
+	is.NoErr(json.Unmarshal(payload, &decoded))
+	is.True(decoded.IsStdout())
+
+	// the whole pipeline is part of the last thunk's identity
+	pipedHash, err := piped.Hash()
+	is.NoErr(err)
+
+	otherHash, err := eval(`(| ($ echo goodbye) ($ tr "a-z" "A-Z") ($ rev))`).Hash()
+	is.NoErr(err)
+	is.True(pipedHash != otherHash)
+
+	is.Equal(eval(`(| ($ rev))`).Cmdline(), "rev")
+}
+
+func TestThunkArgHelpers(t *testing.T) {
+	is := is.New(t)
+
+	for src, cmdline := range map[string]string{
+		`(prepend-args ($ go build ./cmd/app) "-v" "-x")`:        "go -v -x build ./cmd/app",
+		`(append-args ($ go test) "-run" "TestFoo")`:             "go test -run TestFoo",
+		`(append-args ($ go test))`:                              "go test",
+		`(wrap-cmd ($ go test "./...") "strace" "-f")`:           "strace -f .go test ./...",
+		`(wrap-cmd ($ go test "./...") .strace "-f")`:            "strace -f .go test ./...",
+		`(wrap-cmd ($ go test "./...") "./bin/launch")`:          "./bin/launch .go test ./...",
+		`(wrap-cmd (wrap-cmd ($ go test) "strace") "sudo" "-E")`: "sudo -E .strace .go test",
+	} {
+		res, err := bass.EvalFSFile(context.Background(), bass.NewStandardScope(), bass.NewInMemoryFile("test", src))
+		is.NoErr(err)
+
+		var thunk bass.Thunk
+		is.NoErr(res.Decode(&thunk))
+		is.Equal(thunk.Cmdline(), cmdline)
+	}
+}
@@ -3,7 +3,6 @@ package bass
 import (
 	"fmt"
 
-	"github.com/hashicorp/go-multierror"
 	"github.com/vito/bass/pkg/proto"
 	"github.com/vito/bass/std"
 )
@@ -303,40 +302,49 @@ func (enum ThunkMountSource) MarshalJSON() ([]byte, error) {
 }
 
 func (enum *ThunkMountSource) FromValue(val Value) error {
+	errs := EnumDecodeError{Source: val, Destination: enum}
+
 	var host HostPath
 	if err := val.Decode(&host); err == nil {
 		enum.HostPath = &host
 		return nil
+	} else {
+		errs.Try(host, err)
 	}
 
 	var fs *FSPath
 	if err := val.Decode(&fs); err == nil {
 		enum.FSPath = fs
 		return nil
+	} else {
+		errs.Try(fs, err)
 	}
 
 	var tp ThunkPath
 	if err := val.Decode(&tp); err == nil {
 		enum.ThunkPath = &tp
 		return nil
+	} else {
+		errs.Try(tp, err)
 	}
 
 	var cache CachePath
 	if err := val.Decode(&cache); err == nil {
 		enum.Cache = &cache
 		return nil
+	} else {
+		errs.Try(cache, err)
 	}
 
 	var secret Secret
 	if err := val.Decode(&secret); err == nil {
 		enum.Secret = &secret
 		return nil
+	} else {
+		errs.Try(secret, err)
 	}
 
-	return DecodeError{
-		Source:      val,
-		Destination: enum,
-	}
+	return errs
 }
 
 // ThunkImage specifies the base image of a thunk - either a reference to be
@@ -489,14 +497,14 @@ func (image ThunkImage) MarshalJSON() ([]byte, error) {
 }
 
 func (image *ThunkImage) FromValue(val Value) error {
-	var errs error
+	errs := EnumDecodeError{Source: val, Destination: image}
 
 	var ref ImageRef
 	if err := val.Decode(&ref); err == nil {
 		image.Ref = &ref
 		return nil
 	} else {
-		errs = multierror.Append(errs, fmt.Errorf("%T: %w", val, err))
+		errs.Try(ref, err)
 	}
 
 	var thunk Thunk
@@ -504,7 +512,7 @@ func (image *ThunkImage) FromValue(val Value) error {
 		image.Thunk = &thunk
 		return nil
 	} else {
-		errs = multierror.Append(errs, fmt.Errorf("%T: %w", val, err))
+		errs.Try(thunk, err)
 	}
 
 	var archive ImageArchive
@@ -512,10 +520,10 @@ func (image *ThunkImage) FromValue(val Value) error {
 		image.Archive = &archive
 		return nil
 	} else {
-		errs = multierror.Append(errs, fmt.Errorf("%T: %w", val, err))
+		errs.Try(archive, err)
 	}
 
-	return fmt.Errorf("image enum: %w", errs)
+	return errs
 }
 
 type ThunkCmd struct {
@@ -686,13 +694,13 @@ func (tc ThunkCmd) MarshalJSON() ([]byte, error) {
 }
 
 func (tc *ThunkCmd) FromValue(val Value) error {
-	var errs error
+	errs := EnumDecodeError{Source: val, Destination: tc}
 	var file FilePath
 	if err := val.Decode(&file); err == nil {
 		tc.File = &file
 		return nil
 	} else {
-		errs = multierror.Append(errs, fmt.Errorf("%T: %w", file, err))
+		errs.Try(file, err)
 	}
 
 	var cmd CommandPath
@@ -700,7 +708,7 @@ func (tc *ThunkCmd) FromValue(val Value) error {
 		tc.Cmd = &cmd
 		return nil
 	} else {
-		errs = multierror.Append(errs, fmt.Errorf("%T: %w", cmd, err))
+		errs.Try(cmd, err)
 	}
 
 	var wlp ThunkPath
@@ -709,10 +717,10 @@ func (tc *ThunkCmd) FromValue(val Value) error {
 			tc.Thunk = &wlp
 			return nil
 		} else {
-			errs = multierror.Append(errs, fmt.Errorf("%T does not point to a File", wlp))
+			errs.Try(wlp, fmt.Errorf("%s does not point to a file", wlp))
 		}
 	} else {
-		errs = multierror.Append(errs, fmt.Errorf("%T: %w", wlp, err))
+		errs.Try(wlp, err)
 	}
 
 	var host HostPath
@@ -720,7 +728,7 @@ func (tc *ThunkCmd) FromValue(val Value) error {
 		tc.Host = &host
 		return nil
 	} else {
-		errs = multierror.Append(errs, fmt.Errorf("%T: %w", file, err))
+		errs.Try(host, err)
 	}
 
 	var fsp *FSPath
@@ -728,7 +736,7 @@ func (tc *ThunkCmd) FromValue(val Value) error {
 		tc.FS = fsp
 		return nil
 	} else {
-		errs = multierror.Append(errs, fmt.Errorf("%T: %w", file, err))
+		errs.Try(fsp, err)
 	}
 
 	var cache CachePath
@@ -736,7 +744,7 @@ func (tc *ThunkCmd) FromValue(val Value) error {
 		tc.Cache = &cache
 		return nil
 	} else {
-		errs = multierror.Append(errs, fmt.Errorf("%T: %w", file, err))
+		errs.Try(cache, err)
 	}
 
 	return errs
@@ -828,14 +836,14 @@ func (path ThunkDir) MarshalJSON() ([]byte, error) {
 }
 
 func (path *ThunkDir) FromValue(val Value) error {
-	var errs error
+	errs := EnumDecodeError{Source: val, Destination: path}
 
 	var dir DirPath
 	if err := val.Decode(&dir); err == nil {
 		path.Dir = &dir
 		return nil
 	} else {
-		errs = multierror.Append(errs, fmt.Errorf("%T: %w", dir, err))
+		errs.Try(dir, err)
 	}
 
 	var wlp ThunkPath
@@ -847,7 +855,7 @@ func (path *ThunkDir) FromValue(val Value) error {
 			return fmt.Errorf("dir thunk path must be a directory: %s", wlp)
 		}
 	} else {
-		errs = multierror.Append(errs, fmt.Errorf("%T: %w", wlp, err))
+		errs.Try(wlp, err)
 	}
 
 	var hp HostPath
@@ -859,7 +867,7 @@ func (path *ThunkDir) FromValue(val Value) error {
 			return fmt.Errorf("dir host path must be a directory: %s", wlp)
 		}
 	} else {
-		errs = multierror.Append(errs, fmt.Errorf("%T: %w", hp, err))
+		errs.Try(hp, err)
 	}
 
 	return errs
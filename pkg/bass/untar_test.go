@@ -0,0 +1,59 @@
+package bass
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vito/is"
+)
+
+func TestUntarRefusesSymlinks(t *testing.T) {
+	for _, example := range []struct {
+		Name    string
+		Headers []*tar.Header
+	}{
+		{
+			Name: "file through symlinked dir",
+			Headers: []*tar.Header{
+				{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "../outside"},
+				{Name: "link/file", Typeflag: tar.TypeReg, Mode: 0644},
+			},
+		},
+		{
+			Name: "file over symlink",
+			Headers: []*tar.Header{
+				{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "../outside/file"},
+				{Name: "link", Typeflag: tar.TypeReg, Mode: 0644},
+			},
+		},
+	} {
+		example := example
+		t.Run(example.Name, func(t *testing.T) {
+			is := is.New(t)
+
+			root := t.TempDir()
+			outside := filepath.Join(root, "outside")
+			is.NoErr(os.Mkdir(outside, 0755))
+
+			dir := filepath.Join(root, "dir")
+			is.NoErr(os.Mkdir(dir, 0755))
+
+			buf := new(bytes.Buffer)
+			tw := tar.NewWriter(buf)
+			for _, hdr := range example.Headers {
+				is.NoErr(tw.WriteHeader(hdr))
+			}
+			is.NoErr(tw.Close())
+
+			err := untar(buf, dir)
+			is.True(err != nil)
+
+			entries, err := os.ReadDir(outside)
+			is.NoErr(err)
+			is.Equal(len(entries), 0)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math/big"
 	"reflect"
 	"strings"
 )
@@ -54,14 +55,13 @@ func ValueOf(src any) (Value, error) {
 	case bool:
 		return Bool(x), nil
 	case int:
-		return Int(x), nil
+		return IntValue(x), nil
+	case float64:
+		return Float(x), nil
+	case *big.Int:
+		return NewBigInt(new(big.Int).Set(x)), nil
 	case json.Number:
-		i, err := x.Int64()
-		if err != nil {
-			return String(x.String()), nil
-		}
-
-		return Int(i), nil
+		return jsonNumber(x), nil
 	case string:
 		return String(x), nil
 	case map[string]any:
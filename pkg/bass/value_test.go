@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"math/big"
 	"strconv"
 	"testing"
 
@@ -22,6 +23,8 @@ var allConstValues = []bass.Value{
 	bass.Bool(true),
 	bass.Bool(false),
 	bass.Int(42),
+	bass.Float(4.2),
+	bass.NewBigInt(new(big.Int).Lsh(big.NewInt(42), 100)),
 	bass.String("hello"),
 	noopOp,
 	noopFn,
@@ -144,9 +147,17 @@ func TestValueOf(t *testing.T) {
 			json.Number(strconv.Itoa(math.MaxInt64)),
 			bass.Int(math.MaxInt64),
 		},
+		{
+			json.Number("92233720368547758070"),
+			bass.NewBigInt(new(big.Int).Mul(big.NewInt(math.MaxInt64), big.NewInt(10))),
+		},
+		{
+			1.5,
+			bass.Float(1.5),
+		},
 		{
 			json.Number(fmt.Sprintf("%.5f", math.Pi)),
-			bass.String("3.14159"),
+			bass.Float(3.14159),
 		},
 		{
 			[]string{},
@@ -0,0 +1,64 @@
+package bass
+
+import (
+	"context"
+	"time"
+)
+
+// Verify runs the thunk twice, bypassing the cache, and compares the files in
+// its output directory.
+//
+// It returns any files which differ between the two runs. See Cause for
+// the likely source of each change.
+func (thunk Thunk) Verify(ctx context.Context) ([]FileChange, error) {
+	nonce := time.Now().UnixNano()
+
+	a := ThunkPath{
+		Thunk: thunk.WithLabel("verify", Int(nonce)),
+		Path:  ParseFileOrDirPath("./"),
+	}
+
+	b := ThunkPath{
+		Thunk: thunk.WithLabel("verify", Int(nonce+1)),
+		Path:  ParseFileOrDirPath("./"),
+	}
+
+	return DiffFiles(ctx, a, b)
+}
+
+// Cause guesses the source of the change when it is observed between two runs
+// of the same thunk, where it can be detected from the file summaries alone.
+func (change FileChange) Cause() string {
+	switch change.Change {
+	case FileAdded, FileRemoved:
+		return "file is only sometimes created; possibly random or time-based naming"
+	}
+
+	if change.Before.Digest == change.After.Digest {
+		return "file mode differs"
+	}
+
+	if change.Before.Size == change.After.Size {
+		return "content differs with the same size; possibly an embedded timestamp, random value, or ordering"
+	}
+
+	return "content differs in size; possibly an embedded timestamp, random value, or ordering"
+}
+
+// VerifyThunk runs the thunk twice, bypassing the cache, and returns the
+// files which differ between the two runs along with the likely cause.
+func VerifyThunk(ctx context.Context, thunk Thunk) (List, error) {
+	changes, err := thunk.Verify(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	vals := make([]Value, len(changes))
+	for i, change := range changes {
+		scope := change.ToValue().(*Scope)
+		scope.Set("cause", String(change.Cause()))
+		vals[i] = scope
+	}
+
+	return NewList(vals...), nil
+}
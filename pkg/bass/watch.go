@@ -0,0 +1,223 @@
+package bass
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vito/bass/pkg/zapctx"
+	"go.uber.org/zap"
+)
+
+// DefaultWatchInterval is how often watches poll for changes when no
+// interval is configured.
+const DefaultWatchInterval = time.Minute
+
+// WatchOpts configures a watch.
+type WatchOpts struct {
+	// Interval is the number of seconds between each poll.
+	Interval int `json:"interval,omitempty"`
+
+	// Memos is where to memoize the last value seen, so that a restarted
+	// watch does not emit it again.
+	Memos Readable `json:"memos,omitempty"`
+}
+
+func decodeWatchOpts(opts []*Scope) (WatchOpts, error) {
+	var watchOpts WatchOpts
+	if len(opts) > 0 {
+		if err := decodeStruct(opts[0], &watchOpts); err != nil {
+			return WatchOpts{}, err
+		}
+	}
+
+	return watchOpts, nil
+}
+
+func (opts WatchOpts) interval() time.Duration {
+	if opts.Interval <= 0 {
+		return DefaultWatchInterval
+	}
+
+	return time.Duration(opts.Interval) * time.Second
+}
+
+func (opts WatchOpts) openMemos(ctx context.Context) (Memos, error) {
+	if opts.Memos == nil {
+		return nil, nil
+	}
+
+	memos, err := OpenMemos(ctx, opts.Memos)
+	if err != nil {
+		return nil, fmt.Errorf("open memos at %s: %w", opts.Memos, err)
+	}
+
+	return memos, nil
+}
+
+// watchImageMemoThunk is the module under which the digests seen by image
+// watches are stored in Memos.
+var watchImageMemoThunk = internalMemo("watch-image", false)
+
+const watchImageMemoBinding Symbol = "digest"
+
+// ImageWatcher is a PipeSource which emits an image ref each time the digest
+// its tag resolves to changes.
+type ImageWatcher struct {
+	ref      ImageRef
+	interval time.Duration
+	memos    Memos
+
+	input  Value
+	last   string
+	polled bool
+}
+
+var _ PipeSource = (*ImageWatcher)(nil)
+
+// NewImageWatcher returns a watcher for the ref, starting from the digest
+// memoized by a previous watch, if any.
+func NewImageWatcher(ctx context.Context, ref ImageRef, opts WatchOpts) (*ImageWatcher, error) {
+	ref.Digest = ""
+
+	input, err := ValueOf(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	memos, err := opts.openMemos(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	watcher := &ImageWatcher{
+		ref:      ref,
+		interval: opts.interval(),
+		memos:    memos,
+		input:    input,
+	}
+
+	if memos != nil {
+		res, found, err := memos.Retrieve(watchImageMemoThunk, watchImageMemoBinding, input)
+		if err != nil {
+			return nil, fmt.Errorf("retrieve last digest of %s: %w", input, err)
+		}
+
+		if found {
+			var digest string
+			if err := res.Decode(&digest); err == nil {
+				watcher.last = digest
+			}
+		}
+	}
+
+	return watcher, nil
+}
+
+func (watcher *ImageWatcher) String() string {
+	ref, err := watcher.ref.Ref()
+	if err != nil {
+		return "<watch-image>"
+	}
+
+	return fmt.Sprintf("<watch-image: %s>", ref)
+}
+
+// Next blocks until the ref resolves to a new digest, polling every
+// interval. Errors resolving the ref are logged and retried.
+func (watcher *ImageWatcher) Next(ctx context.Context) (Value, error) {
+	logger := zapctx.FromContext(ctx)
+
+	for {
+		if watcher.polled {
+			select {
+			case <-Clock.After(watcher.interval):
+			case <-ctx.Done():
+				return nil, ErrInterrupted
+			}
+		}
+
+		watcher.polled = true
+
+		runtime, err := RuntimeFromContext(ctx, watcher.ref.Platform)
+		if err != nil {
+			return nil, err
+		}
+
+		resolved, err := runtime.Resolve(ctx, watcher.ref)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ErrInterrupted
+			}
+
+			logger.Warn("failed to resolve watched image",
+				zap.String("image", watcher.String()),
+				zap.Error(err))
+			continue
+		}
+
+		if resolved.Digest == watcher.last {
+			continue
+		}
+
+		watcher.last = resolved.Digest
+
+		if watcher.memos != nil {
+			err := watcher.memos.Store(watchImageMemoThunk, watchImageMemoBinding, watcher.input, String(resolved.Digest))
+			if err != nil {
+				return nil, fmt.Errorf("store last digest of %s: %w", watcher.input, err)
+			}
+		}
+
+		return ValueOf(resolved)
+	}
+}
+
+// WatchImage returns a source which emits the ref each time its tag moves.
+// The ref may be an image ref or a "repository:tag" string.
+func WatchImage(ctx context.Context, refVal Value, opts ...*Scope) (*Source, error) {
+	ref, err := decodeImageRefArg(refVal)
+	if err != nil {
+		return nil, err
+	}
+
+	watchOpts, err := decodeWatchOpts(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	watcher, err := NewImageWatcher(ctx, ref, watchOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSource(watcher), nil
+}
+
+// decodeImageRefArg decodes an image ref scope or a "repository:tag" string.
+func decodeImageRefArg(val Value) (ImageRef, error) {
+	var ref ImageRef
+	if err := val.Decode(&ref); err == nil {
+		return ref, nil
+	}
+
+	var str string
+	if err := val.Decode(&str); err != nil {
+		return ImageRef{}, fmt.Errorf("expected image ref or string, got %s", val)
+	}
+
+	ref = ImageRef{
+		Platform: LinuxPlatform,
+	}
+
+	repo, tag := str, ""
+	if i := strings.LastIndex(str, ":"); i > strings.LastIndex(str, "/") {
+		repo, tag = str[:i], str[i+1:]
+	}
+
+	ref.Repository.Static = repo
+	ref.Tag = tag
+
+	return ref, nil
+}
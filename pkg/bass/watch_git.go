@@ -0,0 +1,256 @@
+package bass
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vito/bass/pkg/zapctx"
+	"go.uber.org/zap"
+)
+
+// watchGitMemoThunk is the module under which the cursors of git watches are
+// stored in Memos.
+var watchGitMemoThunk = internalMemo("watch-git", false)
+
+const watchGitMemoBinding Symbol = "sha"
+
+// gitLogFormat separates the fields of each commit with a unit separator.
+// Commits are separated by NUL bytes via git log -z.
+const gitLogFormat = "%H%x1f%an <%ae>%x1f%ct%x1f%B"
+
+// gitLogScript fetches the commit to emit and logs every commit since the
+// cursor, or only the commit itself if the cursor is unset or is no longer in
+// its history, e.g. after a force-push.
+const gitLogScript = `set -e
+git init -q
+git fetch -q --filter=blob:none "$1" "$2"
+if [ -n "$3" ] && git merge-base --is-ancestor "$3" "$2" 2>/dev/null; then
+  exec git log --reverse -z --format="$4" "$3..$2"
+else
+  exec git log -1 -z --format="$4" "$2"
+fi
+`
+
+// GitWatcher is a PipeSource which emits each commit that appears on a git
+// ref, oldest first.
+type GitWatcher struct {
+	repo     string
+	ref      string
+	image    ThunkImage
+	interval time.Duration
+	memos    Memos
+
+	input   Value
+	last    string
+	pending []*Scope
+	polled  bool
+}
+
+var _ PipeSource = (*GitWatcher)(nil)
+
+// NewGitWatcher returns a watcher for the ref of the repo, starting from the
+// commit memoized by a previous watch, if any.
+//
+// Git commands are run in thunks using the given image.
+func NewGitWatcher(ctx context.Context, repo, ref string, image ThunkImage, opts WatchOpts) (*GitWatcher, error) {
+	input := Bindings{
+		"repo": String(repo),
+		"ref":  String(ref),
+	}.Scope()
+
+	memos, err := opts.openMemos(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	watcher := &GitWatcher{
+		repo:     repo,
+		ref:      ref,
+		image:    image,
+		interval: opts.interval(),
+		memos:    memos,
+		input:    input,
+	}
+
+	if memos != nil {
+		res, found, err := memos.Retrieve(watchGitMemoThunk, watchGitMemoBinding, input)
+		if err != nil {
+			return nil, fmt.Errorf("retrieve last commit of %s: %w", input, err)
+		}
+
+		if found {
+			var sha string
+			if err := res.Decode(&sha); err == nil {
+				watcher.last = sha
+			}
+		}
+	}
+
+	return watcher, nil
+}
+
+func (watcher *GitWatcher) String() string {
+	return fmt.Sprintf("<watch-git: %s %s>", watcher.repo, watcher.ref)
+}
+
+// Next returns the next commit on the ref, polling every interval until one
+// appears. Errors running git are logged and retried.
+func (watcher *GitWatcher) Next(ctx context.Context) (Value, error) {
+	logger := zapctx.FromContext(ctx)
+
+	for len(watcher.pending) == 0 {
+		if watcher.polled {
+			select {
+			case <-Clock.After(watcher.interval):
+			case <-ctx.Done():
+				return nil, ErrInterrupted
+			}
+		}
+
+		watcher.polled = true
+
+		commits, err := watcher.poll(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ErrInterrupted
+			}
+
+			logger.Warn("failed to poll watched repo",
+				zap.String("repo", watcher.String()),
+				zap.Error(err))
+			continue
+		}
+
+		watcher.pending = commits
+	}
+
+	commit := watcher.pending[0]
+	watcher.pending = watcher.pending[1:]
+
+	var sha string
+	if err := commit.GetDecode("sha", &sha); err != nil {
+		return nil, err
+	}
+
+	watcher.last = sha
+
+	if watcher.memos != nil {
+		err := watcher.memos.Store(watchGitMemoThunk, watchGitMemoBinding, watcher.input, String(sha))
+		if err != nil {
+			return nil, fmt.Errorf("store last commit of %s: %w", watcher.input, err)
+		}
+	}
+
+	return commit, nil
+}
+
+// poll resolves the ref and returns any commits since the last one emitted.
+func (watcher *GitWatcher) poll(ctx context.Context) ([]*Scope, error) {
+	lsRemote := Thunk{
+		Cmd: ThunkCmd{
+			Cmd: &CommandPath{"git"},
+		},
+		Args: []Value{
+			String("ls-remote"),
+			String(watcher.repo),
+			String(watcher.ref),
+		},
+	}.WithImage(watcher.image).
+		WithLabel("at", String(Clock.Now().UTC().Format(time.RFC3339)))
+
+	buf := new(bytes.Buffer)
+	if err := lsRemote.Read(ctx, buf); err != nil {
+		return nil, fmt.Errorf("ls-remote: %w", err)
+	}
+
+	fields := strings.Fields(buf.String())
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("ls-remote: ref not found: %s", watcher.ref)
+	}
+
+	sha := fields[0]
+	if sha == watcher.last {
+		return nil, nil
+	}
+
+	log := Thunk{
+		Cmd: ThunkCmd{
+			Cmd: &CommandPath{"sh"},
+		},
+		Args: []Value{
+			String("-c"),
+			String(gitLogScript),
+			String("sh"),
+			String(watcher.repo),
+			String(sha),
+			String(watcher.last),
+			String(gitLogFormat),
+		},
+	}.WithImage(watcher.image)
+
+	buf.Reset()
+	if err := log.Read(ctx, buf); err != nil {
+		return nil, fmt.Errorf("log: %w", err)
+	}
+
+	return parseGitLog(buf.String())
+}
+
+// parseGitLog parses the output of git log -z with gitLogFormat.
+func parseGitLog(out string) ([]*Scope, error) {
+	var commits []*Scope
+	for _, record := range strings.Split(out, "\x00") {
+		record = strings.TrimPrefix(record, "\n")
+		if record == "" {
+			continue
+		}
+
+		fields := strings.SplitN(record, "\x1f", 4)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("malformed git log record: %q", record)
+		}
+
+		unix, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("commit time: %w", err)
+		}
+
+		commits = append(commits, Bindings{
+			"sha":     String(fields[0]),
+			"author":  String(fields[1]),
+			"time":    String(time.Unix(unix, 0).UTC().Format(time.RFC3339)),
+			"message": String(strings.TrimSuffix(fields[3], "\n")),
+		}.Scope())
+	}
+
+	return commits, nil
+}
+
+// WatchGit returns a source which emits each commit that appears on the ref
+// of the repo. The :image option is required.
+func WatchGit(ctx context.Context, repo, ref string, opts ...*Scope) (*Source, error) {
+	watchOpts, err := decodeWatchOpts(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var image ThunkImage
+	if len(opts) == 0 || !opts[0].Binds("image") {
+		return nil, fmt.Errorf("watch-git: :image must be provided")
+	}
+
+	if err := opts[0].GetDecode("image", &image); err != nil {
+		return nil, fmt.Errorf("watch-git: image: %w", err)
+	}
+
+	watcher, err := NewGitWatcher(ctx, repo, ref, image, watchOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSource(watcher), nil
+}
@@ -0,0 +1,191 @@
+package bass_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/runtimes"
+	"github.com/vito/is"
+)
+
+type gitRuntime struct {
+	FakeRuntime
+
+	// history is the commits on the ref, oldest first
+	history []string
+
+	// heads is the number of commits on the ref at each poll
+	heads []int
+
+	mu    sync.Mutex
+	polls int
+}
+
+func (fake *gitRuntime) Read(_ context.Context, w io.Writer, thunk bass.Thunk) error {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	var args []string
+	for _, arg := range thunk.Args {
+		var str string
+		if err := arg.Decode(&str); err != nil {
+			return err
+		}
+
+		args = append(args, str)
+	}
+
+	switch args[0] {
+	case "ls-remote":
+		i := fake.polls
+		if i >= len(fake.heads) {
+			i = len(fake.heads) - 1
+		}
+
+		fake.polls++
+
+		_, err := fmt.Fprintf(w, "%s\trefs/heads/main\n", fake.history[fake.heads[i]-1])
+		return err
+	case "-c":
+		head, last := args[4], args[5]
+
+		var from, to int
+		for i, sha := range fake.history {
+			if sha == last {
+				from = i + 1
+			}
+
+			if sha == head {
+				to = i + 1
+			}
+		}
+
+		if last == "" {
+			from = to - 1
+		}
+
+		for i, sha := range fake.history[from:to] {
+			fmt.Fprintf(w, "%s\x1fAlice <alice@example.com>\x1f%d\x1fcommit %s\n\nbody\n\x00", sha, 1000+i, sha)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unexpected thunk: %s", thunk)
+	}
+}
+
+func TestWatchGit(t *testing.T) {
+	is := is.New(t)
+
+	oldClock := bass.Clock
+	clock := clockwork.NewFakeClock()
+	bass.Clock = clock
+	defer func() { bass.Clock = oldClock }()
+
+	fake := &gitRuntime{
+		history: []string{"a", "b", "c", "d"},
+		heads:   []int{1, 1, 3, 4},
+	}
+
+	ctx := bass.WithRuntimePool(context.Background(), &runtimes.Pool{
+		Runtimes: []runtimes.Assoc{
+			{
+				Platform: bass.LinuxPlatform,
+				Runtime:  fake,
+			},
+		},
+	})
+
+	image := bass.ThunkImage{
+		Ref: &bass.ImageRef{
+			Platform: bass.LinuxPlatform,
+			Repository: bass.ImageRepository{
+				Static: "alpine/git",
+			},
+		},
+	}
+
+	opts := bass.WatchOpts{
+		Interval: 10,
+		Memos:    bass.NewHostPath(t.TempDir(), bass.ParseFileOrDirPath("bass.lock")),
+	}
+
+	watcher, err := bass.NewGitWatcher(ctx, "https://example.com/repo", "main", image, opts)
+	is.NoErr(err)
+	is.Equal(watcher.String(), "<watch-git: https://example.com/repo main>")
+
+	next := func(watcher *bass.GitWatcher) string {
+		val, err := watcher.Next(ctx)
+		is.NoErr(err)
+
+		var commit *bass.Scope
+		is.NoErr(val.Decode(&commit))
+
+		var sha string
+		is.NoErr(commit.GetDecode("sha", &sha))
+		return sha
+	}
+
+	// emits only the current commit at first
+	val, err := watcher.Next(ctx)
+	is.NoErr(err)
+
+	var commit struct {
+		Sha     string `json:"sha"`
+		Author  string `json:"author"`
+		Message string `json:"message"`
+		Time    string `json:"time"`
+	}
+	is.NoErr(val.Decode(&commit))
+	is.Equal(commit.Sha, "a")
+	is.Equal(commit.Author, "Alice <alice@example.com>")
+	is.Equal(commit.Message, "commit a\n\nbody")
+	is.Equal(commit.Time, "1970-01-01T00:16:40Z")
+
+	// polls until new commits appear, emitting each one oldest first
+	got := make(chan string, 1)
+	go func() {
+		got <- next(watcher)
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Second)
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Second)
+
+	is.Equal(<-got, "b")
+	is.Equal(next(watcher), "c")
+	is.Equal(fake.polls, 3)
+
+	// a restarted watch resumes from the memoized commit
+	watcher, err = bass.NewGitWatcher(ctx, "https://example.com/repo", "main", image, opts)
+	is.NoErr(err)
+	is.Equal(next(watcher), "d")
+	is.Equal(fake.polls, 4)
+
+	t.Run("image is required", func(t *testing.T) {
+		is := is.New(t)
+
+		_, err := bass.EvalFSFile(ctx, bass.NewStandardScope(), bass.NewInMemoryFile("test", `(watch-git "https://example.com/repo" "main")`))
+		is.True(err != nil)
+	})
+
+	t.Run("builtin", func(t *testing.T) {
+		is := is.New(t)
+
+		res, err := bass.EvalFSFile(ctx, bass.NewStandardScope(), bass.NewInMemoryFile("test", `
+			(def commits
+			  (watch-git "https://example.com/repo" "main"
+			    {:image {:platform {:os "linux"} :repository "alpine/git" :tag "latest"}}))
+			(:sha (next commits))
+		`))
+		is.NoErr(err)
+		is.Equal(res, bass.String("d"))
+	})
+}
@@ -0,0 +1,129 @@
+package bass_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/runtimes"
+	"github.com/vito/is"
+)
+
+type movingTagRuntime struct {
+	FakeRuntime
+
+	digests []string
+
+	mu       sync.Mutex
+	resolves int
+}
+
+func (fake *movingTagRuntime) Resolve(_ context.Context, ref bass.ImageRef) (bass.ImageRef, error) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	i := fake.resolves
+	if i >= len(fake.digests) {
+		i = len(fake.digests) - 1
+	}
+
+	fake.resolves++
+	ref.Digest = fake.digests[i]
+
+	return ref, nil
+}
+
+func TestWatchImage(t *testing.T) {
+	is := is.New(t)
+
+	oldClock := bass.Clock
+	clock := clockwork.NewFakeClock()
+	bass.Clock = clock
+	defer func() { bass.Clock = oldClock }()
+
+	fake := &movingTagRuntime{
+		digests: []string{"sha256:a", "sha256:a", "sha256:b", "sha256:b"},
+	}
+
+	ctx := bass.WithRuntimePool(context.Background(), &runtimes.Pool{
+		Runtimes: []runtimes.Assoc{
+			{
+				Platform: bass.LinuxPlatform,
+				Runtime:  fake,
+			},
+		},
+	})
+
+	opts := bass.WatchOpts{
+		Interval: 10,
+		Memos:    bass.NewHostPath(t.TempDir(), bass.ParseFileOrDirPath("bass.lock")),
+	}
+
+	ref := bass.ImageRef{
+		Platform: bass.LinuxPlatform,
+		Repository: bass.ImageRepository{
+			Static: "golang",
+		},
+		Tag: "1.19",
+	}
+
+	watcher, err := bass.NewImageWatcher(ctx, ref, opts)
+	is.NoErr(err)
+	is.Equal(watcher.String(), "<watch-image: golang:1.19>")
+
+	var next bass.ImageRef
+	val, err := watcher.Next(ctx)
+	is.NoErr(err)
+	is.NoErr(val.Decode(&next))
+	is.Equal(next.Digest, "sha256:a")
+
+	// polls until the tag moves
+	got := make(chan bass.Value, 1)
+	go func() {
+		val, err := watcher.Next(ctx)
+		is.NoErr(err)
+		got <- val
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Second)
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Second)
+
+	is.NoErr((<-got).Decode(&next))
+	is.Equal(next.Digest, "sha256:b")
+	is.Equal(fake.resolves, 3)
+
+	// a restarted watch does not emit the memoized digest again
+	watcher, err = bass.NewImageWatcher(ctx, ref, opts)
+	is.NoErr(err)
+
+	cancelCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		_, err := watcher.Next(cancelCtx)
+		done <- err
+	}()
+
+	clock.BlockUntil(1)
+	is.Equal(fake.resolves, 4)
+	cancel()
+	is.Equal(<-done, bass.ErrInterrupted)
+
+	t.Run("string refs", func(t *testing.T) {
+		is := is.New(t)
+
+		res, err := bass.EvalFSFile(ctx, bass.NewStandardScope(), bass.NewInMemoryFile("test", `(next (watch-image "registry:5000/golang:1.19"))`))
+		is.NoErr(err)
+
+		var ref bass.ImageRef
+		is.NoErr(res.Decode(&ref))
+		is.Equal(ref.Repository.Static, "registry:5000/golang")
+		is.Equal(ref.Tag, "1.19")
+		is.Equal(ref.Platform, bass.LinuxPlatform)
+		is.Equal(ref.Digest, "sha256:b")
+	})
+}
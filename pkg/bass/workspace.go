@@ -0,0 +1,86 @@
+package bass
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+)
+
+// ErrNoWorkspace is returned by (workspace) when the run has no workspace.
+var ErrNoWorkspace = errors.New("no workspace configured for this run")
+
+// Workspace is a temporary host directory scoped to a single run, for scripts
+// which need somewhere to put intermediate files.
+//
+// The directory is created the first time it is requested and removed by
+// Cleanup, unless the workspace is kept.
+type Workspace struct {
+	// Keep retains the directory after the run, e.g. for debugging.
+	Keep bool
+
+	dir string
+	mu  sync.Mutex
+}
+
+// NewWorkspace returns a workspace which creates its directory on demand.
+func NewWorkspace(keep bool) *Workspace {
+	return &Workspace{Keep: keep}
+}
+
+// Dir returns the workspace directory, creating it if needed.
+func (ws *Workspace) Dir() (string, error) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.dir == "" {
+		dir, err := os.MkdirTemp("", "bass-workspace-")
+		if err != nil {
+			return "", err
+		}
+
+		ws.dir = dir
+	}
+
+	return ws.dir, nil
+}
+
+// Created returns the workspace directory, or an empty string if it was never
+// requested.
+func (ws *Workspace) Created() string {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	return ws.dir
+}
+
+// Cleanup removes the workspace directory, unless the workspace is kept.
+func (ws *Workspace) Cleanup() error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.Keep || ws.dir == "" {
+		return nil
+	}
+
+	if err := os.RemoveAll(ws.dir); err != nil {
+		return err
+	}
+
+	ws.dir = ""
+
+	return nil
+}
+
+type workspaceKey struct{}
+
+// WithWorkspace sets the workspace returned by (workspace) within the
+// returned context.
+func WithWorkspace(ctx context.Context, ws *Workspace) context.Context {
+	return context.WithValue(ctx, workspaceKey{}, ws)
+}
+
+// WorkspaceFromContext returns the workspace set by WithWorkspace.
+func WorkspaceFromContext(ctx context.Context) (*Workspace, bool) {
+	ws, ok := ctx.Value(workspaceKey{}).(*Workspace)
+	return ws, ok
+}
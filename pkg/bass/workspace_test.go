@@ -0,0 +1,60 @@
+package bass_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/is"
+)
+
+func TestWorkspace(t *testing.T) {
+	is := is.New(t)
+
+	ws := bass.NewWorkspace(false)
+	ctx := bass.WithWorkspace(context.Background(), ws)
+	is.Equal(ws.Created(), "")
+
+	res, err := bass.EvalFSFile(ctx, bass.NewStandardScope(), bass.NewInMemoryFile("test", "[(workspace) (workspace)]"))
+	is.NoErr(err)
+
+	var paths []bass.HostPath
+	is.NoErr(res.Decode(&paths))
+	is.Equal(len(paths), 2)
+	is.Equal(paths[0], paths[1])
+	is.Equal(paths[0], bass.NewHostDir(ws.Created()))
+
+	info, err := os.Stat(ws.Created())
+	is.NoErr(err)
+	is.True(info.IsDir())
+
+	dir := ws.Created()
+	is.NoErr(ws.Cleanup())
+
+	_, err = os.Stat(dir)
+	is.True(os.IsNotExist(err))
+}
+
+func TestWorkspaceKeep(t *testing.T) {
+	is := is.New(t)
+
+	ws := bass.NewWorkspace(true)
+
+	dir, err := ws.Dir()
+	is.NoErr(err)
+	defer os.RemoveAll(dir)
+
+	is.NoErr(ws.Cleanup())
+
+	_, err = os.Stat(dir)
+	is.NoErr(err)
+}
+
+func TestWorkspaceMissing(t *testing.T) {
+	is := is.New(t)
+
+	_, err := bass.EvalFSFile(context.Background(), bass.NewStandardScope(), bass.NewInMemoryFile("test", "(workspace)"))
+	is.True(errors.Is(err, bass.ErrNoWorkspace))
+}
@@ -2,6 +2,7 @@ package basstest
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -12,12 +13,27 @@ func Equal(t *testing.T, a, b bass.Value) {
 	t.Helper()
 
 	if !a.Equal(b) {
-		diff := tryDiff(a, b)
+		diff := valueDiff(a, b)
+		if diff == "" {
+			diff = tryDiff(a, b)
+		}
+
 		t.Logf("%s != %s\n%s", a, b, diff)
 		t.FailNow()
 	}
 }
 
+// valueDiff renders the structural differences between the values, one per
+// line.
+func valueDiff(a, b bass.Value) string {
+	var lines []string
+	for _, change := range bass.DiffValues(a, b) {
+		lines = append(lines, change.String())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
 func tryDiff(a, b any) (res string) {
 	defer func() {
 		// cmp panics if equal is asymmetrical; recover for better failure ux
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+)
+
+// binMagic marks the end of a binary with an embedded bundle.
+var binMagic = []byte("BASSBIN1")
+
+// binTrailerSize is the size of the length and magic appended after the
+// bundle.
+const binTrailerSize = 8 + 8
+
+// BuildBin writes a copy of the executable with the bundle appended to it.
+//
+// When the resulting binary starts, EmbeddedBundle finds the bundle so that
+// the binary can run its script instead of acting as bass.
+func BuildBin(w io.Writer, exe io.Reader, bundle []byte) error {
+	if _, err := io.Copy(w, exe); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(bundle); err != nil {
+		return err
+	}
+
+	trailer := make([]byte, binTrailerSize)
+	binary.BigEndian.PutUint64(trailer, uint64(len(bundle)))
+	copy(trailer[8:], binMagic)
+
+	_, err := w.Write(trailer)
+	return err
+}
+
+// EmbeddedBundle returns the bundle appended to the executable at the given
+// path by BuildBin, if any.
+func EmbeddedBundle(exePath string) ([]byte, bool, error) {
+	f, err := os.Open(exePath)
+	if err != nil {
+		return nil, false, err
+	}
+
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if info.Size() < binTrailerSize {
+		return nil, false, nil
+	}
+
+	trailer := make([]byte, binTrailerSize)
+	if _, err := f.ReadAt(trailer, info.Size()-binTrailerSize); err != nil {
+		return nil, false, err
+	}
+
+	if !bytes.Equal(trailer[8:], binMagic) {
+		return nil, false, nil
+	}
+
+	size := int64(binary.BigEndian.Uint64(trailer))
+	if size > info.Size()-binTrailerSize {
+		return nil, false, nil
+	}
+
+	bundle := make([]byte, size)
+	if _, err := f.ReadAt(bundle, info.Size()-binTrailerSize-size); err != nil {
+		return nil, false, err
+	}
+
+	return bundle, true, nil
+}
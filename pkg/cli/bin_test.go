@@ -0,0 +1,35 @@
+package cli_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vito/bass/pkg/cli"
+	"github.com/vito/is"
+)
+
+func TestBuildBin(t *testing.T) {
+	is := is.New(t)
+
+	dir := t.TempDir()
+
+	exePath := filepath.Join(dir, "exe")
+	is.NoErr(os.WriteFile(exePath, []byte("not really an executable"), 0755))
+
+	_, found, err := cli.EmbeddedBundle(exePath)
+	is.NoErr(err)
+	is.True(!found)
+
+	bin := new(bytes.Buffer)
+	is.NoErr(cli.BuildBin(bin, bytes.NewBufferString("not really an executable"), []byte("bundle")))
+
+	binPath := filepath.Join(dir, "bin")
+	is.NoErr(os.WriteFile(binPath, bin.Bytes(), 0755))
+
+	bundle, found, err := cli.EmbeddedBundle(binPath)
+	is.NoErr(err)
+	is.True(found)
+	is.Equal(string(bundle), "bundle")
+}
@@ -0,0 +1,393 @@
+package cli
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/psanford/memfs"
+	"github.com/vito/bass/pkg/bass"
+)
+
+// BundleManifestName is the name of the manifest file within a bundle.
+const BundleManifestName = "bass-bundle.json"
+
+// BundleManifest describes the contents of a bundle.
+type BundleManifest struct {
+	// Entrypoint is the path to the script to run, relative to the bundle
+	// root.
+	Entrypoint string `json:"entrypoint"`
+
+	// Source is the slash-separated path of the directory the bundle was
+	// created from, relative to the root of its git repository. It is used to
+	// map error locations back to the original files when the bundle is run
+	// from a checkout of the same repository.
+	Source string `json:"source,omitempty"`
+}
+
+// Bundle packages a script along with the modules it loads and the bass.lock
+// files next to them into a single gzipped tar archive, written to w.
+//
+// The script is loaded without calling its main to discover its modules, the
+// same as Deps, so modules loaded from outside of its directory, e.g. from
+// *dir*/../lib/, are included too. The bundle is rooted at the closest
+// directory containing all of them.
+//
+// The archive is reproducible: files are written in sorted order with
+// normalized metadata, so bundling the same files always produces the same
+// bytes. It returns the archive's SHA256 digest.
+func Bundle(ctx context.Context, env *bass.Scope, varFlags []string, w io.Writer, scriptPath string) (string, error) {
+	script, err := filepath.Abs(scriptPath)
+	if err != nil {
+		return "", err
+	}
+
+	graph, err := Deps(ctx, env, varFlags, script)
+	if err != nil {
+		return "", fmt.Errorf("load %s: %w", scriptPath, err)
+	}
+
+	paths := []string{script}
+	for _, dep := range graph.Deps() {
+		if dep.Kind == bass.DepModule && filepath.IsAbs(dep.Name) {
+			// modules loaded from the host; others are embedded or fetched
+			paths = append(paths, dep.Name)
+		}
+	}
+
+	for _, module := range paths {
+		lock := filepath.Join(filepath.Dir(module), "bass.lock")
+		if _, err := os.Stat(lock); err == nil {
+			paths = append(paths, lock)
+		}
+	}
+
+	root := filepath.Dir(script)
+	for _, p := range paths {
+		for !isWithin(root, p) {
+			root = filepath.Dir(root)
+		}
+	}
+
+	seen := map[string]bool{}
+	var files []string
+	for _, p := range paths {
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return "", err
+		}
+
+		file := filepath.ToSlash(rel)
+		if !seen[file] {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+
+	sort.Strings(files)
+
+	entrypoint, err := filepath.Rel(root, script)
+	if err != nil {
+		return "", err
+	}
+
+	manifest, err := json.Marshal(BundleManifest{
+		Entrypoint: filepath.ToSlash(entrypoint),
+		Source:     bundleSource(root),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	digest := sha256.New()
+
+	gw := gzip.NewWriter(io.MultiWriter(w, digest))
+	tw := tar.NewWriter(gw)
+
+	err = writeBundleFile(tw, BundleManifestName, 0644, bytes.NewReader(manifest), int64(len(manifest)))
+	if err != nil {
+		return "", err
+	}
+
+	for _, file := range files {
+		err := func() error {
+			f, err := os.Open(filepath.Join(root, filepath.FromSlash(file)))
+			if err != nil {
+				return err
+			}
+
+			defer f.Close()
+
+			info, err := f.Stat()
+			if err != nil {
+				return err
+			}
+
+			return writeBundleFile(tw, file, int64(info.Mode().Perm()), f, info.Size())
+		}()
+		if err != nil {
+			return "", fmt.Errorf("bundle %s: %w", file, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return "", err
+	}
+
+	if err := gw.Close(); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("sha256:%x", digest.Sum(nil)), nil
+}
+
+// isWithin returns true if the path is within the directory.
+func isWithin(dir, p string) bool {
+	rel, err := filepath.Rel(dir, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+func writeBundleFile(tw *tar.Writer, name string, mode int64, r io.Reader, size int64) error {
+	err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     mode,
+		Size:     size,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tw, r)
+	return err
+}
+
+// LoadBundle reads a bundle into an in-memory filesystem, returning the path
+// to its entrypoint script.
+func LoadBundle(r io.Reader) (*bass.FSPath, error) {
+	mfs := memfs.New()
+
+	err := readBundle(r, func(name string, mode fs.FileMode, content io.Reader) error {
+		if err := mfs.MkdirAll(path.Dir(name), 0755); err != nil {
+			return err
+		}
+
+		data, err := io.ReadAll(content)
+		if err != nil {
+			return err
+		}
+
+		return mfs.WriteFile(name, data, mode)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := fs.ReadFile(mfs, BundleManifestName)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest BundleManifest
+	if err := json.Unmarshal(payload, &manifest); err != nil {
+		return nil, err
+	}
+
+	if origin, found := bundleOrigin(manifest); found {
+		bass.SourceMaps.MapFS(mfs, origin)
+	}
+
+	return bass.NewFSPath(mfs, bass.ParseFileOrDirPath(manifest.Entrypoint)), nil
+}
+
+// IsBundle returns true if the file at the given path is a bundle.
+func IsBundle(filePath string) bool {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return false
+	}
+
+	defer f.Close()
+
+	gr, err := gzip.NewReader(bufio.NewReader(f))
+	if err != nil {
+		return false
+	}
+
+	hdr, err := tar.NewReader(gr).Next()
+	if err != nil {
+		return false
+	}
+
+	return hdr.Name == BundleManifestName
+}
+
+// OpenBundle extracts a bundle into a content-addressed directory under the
+// Bass cache and returns the path to its entrypoint script.
+//
+// Bundles which have already been extracted are not extracted again.
+func OpenBundle(bundlePath string) (string, error) {
+	content, err := os.ReadFile(bundlePath)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(bass.CacheHome, "bundles", fmt.Sprintf("%x", sha256.Sum256(content)))
+
+	manifest, err := readBundleManifest(dir)
+	if err == nil {
+		return bundleEntrypoint(dir, manifest), nil
+	}
+
+	tmp := dir + ".tmp"
+	if err := os.RemoveAll(tmp); err != nil {
+		return "", err
+	}
+
+	if err := extractBundle(tmp, bytes.NewReader(content)); err != nil {
+		_ = os.RemoveAll(tmp)
+		return "", fmt.Errorf("extract bundle: %w", err)
+	}
+
+	if err := os.Rename(tmp, dir); err != nil {
+		return "", err
+	}
+
+	manifest, err = readBundleManifest(dir)
+	if err != nil {
+		return "", err
+	}
+
+	return bundleEntrypoint(dir, manifest), nil
+}
+
+// bundleEntrypoint returns the path to the entrypoint of a bundle extracted to
+// dir, mapping the directory back to the bundle's source.
+func bundleEntrypoint(dir string, manifest BundleManifest) string {
+	if origin, found := bundleOrigin(manifest); found {
+		bass.SourceMaps.MapDir(dir, origin)
+	}
+
+	return filepath.Join(dir, filepath.FromSlash(manifest.Entrypoint))
+}
+
+// bundleSource returns the path of the directory relative to the root of its
+// git repository, or "" if it is not in one.
+//
+// The path is relative so that the bundle does not depend on where the
+// repository was checked out.
+func bundleSource(dir string) string {
+	root, found := ProjectRoot(dir)
+	if !found {
+		return ""
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return ""
+	}
+
+	return filepath.ToSlash(rel)
+}
+
+// bundleOrigin returns the directory that the bundle's source corresponds to
+// in the git repository containing the working directory. Outside of a
+// repository, the source is returned as a relative path.
+func bundleOrigin(manifest BundleManifest) (string, bool) {
+	if manifest.Source == "" {
+		return "", false
+	}
+
+	source := filepath.FromSlash(manifest.Source)
+
+	if root, found := ProjectRoot("."); found {
+		return filepath.Join(root, source), true
+	}
+
+	return source, true
+}
+
+func readBundleManifest(dir string) (BundleManifest, error) {
+	var manifest BundleManifest
+
+	payload, err := os.ReadFile(filepath.Join(dir, BundleManifestName))
+	if err != nil {
+		return manifest, err
+	}
+
+	err = json.Unmarshal(payload, &manifest)
+	return manifest, err
+}
+
+func extractBundle(dir string, r io.Reader) error {
+	return readBundle(r, func(name string, mode fs.FileMode, content io.Reader) error {
+		dest := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+
+		f, err := os.OpenFile(dest, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode)
+		if err != nil {
+			return err
+		}
+
+		defer f.Close()
+
+		if _, err := io.Copy(f, content); err != nil {
+			return err
+		}
+
+		return f.Close()
+	})
+}
+
+// readBundle calls cb for each file in the bundle.
+func readBundle(r io.Reader, cb func(string, fs.FileMode, io.Reader) error) error {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			return fmt.Errorf("unexpected entry type for %s: %c", hdr.Name, hdr.Typeflag)
+		}
+
+		name := path.Clean(hdr.Name)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("invalid path in bundle: %s", hdr.Name)
+		}
+
+		if err := cb(name, fs.FileMode(hdr.Mode).Perm(), tr); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/basstest"
+	"github.com/vito/bass/pkg/cli"
+	"github.com/vito/is"
+)
+
+func TestBundle(t *testing.T) {
+	is := is.New(t)
+
+	ctx := context.Background()
+
+	src := t.TempDir()
+	is.NoErr(os.MkdirAll(filepath.Join(src, "ci"), 0755))
+	is.NoErr(os.WriteFile(filepath.Join(src, "ci", "script.bass"), []byte(`
+(use (*dir*/../lib/helper.bass))
+(defn main [] (emit (helper:answer) *stdout*))
+`), 0644))
+	is.NoErr(os.MkdirAll(filepath.Join(src, "lib"), 0755))
+	is.NoErr(os.WriteFile(filepath.Join(src, "lib", "helper.bass"), []byte(`(defn answer [] 42)`), 0644))
+	is.NoErr(os.WriteFile(filepath.Join(src, "ci", "bass.lock"), []byte(`{}`), 0644))
+	is.NoErr(os.WriteFile(filepath.Join(src, "ci", "unused.bass"), []byte(`nope`), 0644))
+	is.NoErr(os.WriteFile(filepath.Join(src, "ignored.txt"), []byte(`nope`), 0644))
+	is.NoErr(os.MkdirAll(filepath.Join(src, ".git"), 0755))
+	is.NoErr(os.WriteFile(filepath.Join(src, ".git", "hidden.bass"), []byte(`nope`), 0644))
+
+	bundle := new(bytes.Buffer)
+	digest, err := cli.Bundle(ctx, bass.NewEmptyScope(), nil, bundle, filepath.Join(src, "ci", "script.bass"))
+	is.NoErr(err)
+
+	again := new(bytes.Buffer)
+	againDigest, err := cli.Bundle(ctx, bass.NewEmptyScope(), nil, again, filepath.Join(src, "ci", "script.bass"))
+	is.NoErr(err)
+	is.Equal(digest, againDigest)
+	is.Equal(bundle.Bytes(), again.Bytes())
+
+	bundlePath := filepath.Join(t.TempDir(), "pipeline.bundle")
+	is.NoErr(os.WriteFile(bundlePath, bundle.Bytes(), 0644))
+	is.True(cli.IsBundle(bundlePath))
+	is.True(!cli.IsBundle(filepath.Join(src, "ci", "script.bass")))
+
+	oldHome := bass.CacheHome
+	bass.CacheHome = t.TempDir()
+	defer func() { bass.CacheHome = oldHome }()
+
+	// source maps are relative to the repository of the working directory
+	chdir(t, src)
+
+	script, err := cli.OpenBundle(bundlePath)
+	is.NoErr(err)
+	is.Equal(filepath.Base(script), "script.bass")
+
+	root := filepath.Dir(filepath.Dir(script))
+
+	// only loaded modules and their lock files are included, even from
+	// outside of the script's directory
+	_, err = os.Stat(filepath.Join(root, "lib", "helper.bass"))
+	is.NoErr(err)
+	_, err = os.Stat(filepath.Join(root, "ci", "bass.lock"))
+	is.NoErr(err)
+	_, err = os.Stat(filepath.Join(root, "ci", "unused.bass"))
+	is.True(os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(root, "ignored.txt"))
+	is.True(os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(root, ".git"))
+	is.True(os.IsNotExist(err))
+
+	// errors in extracted scripts refer to the original files
+	origin, found := bass.SourceMaps.Origin(bass.ParseHostPath(script))
+	is.True(found)
+	is.Equal(origin, filepath.Join(src, "ci", "script.bass"))
+
+	// extracted bundles are reused
+	reopened, err := cli.OpenBundle(bundlePath)
+	is.NoErr(err)
+	is.Equal(reopened, script)
+
+	sink := bass.NewInMemorySink()
+	err = cli.Run(context.Background(), bass.NewEmptyScope(), nil, nil, script, nil, bass.NewSink(sink))
+	is.NoErr(err)
+	basstest.Equal(t, bass.NewList(sink.Values...), bass.NewList(bass.Int(42)))
+}
+
+func TestLoadBundleSourceMap(t *testing.T) {
+	is := is.New(t)
+
+	src := t.TempDir()
+	is.NoErr(os.MkdirAll(filepath.Join(src, ".git"), 0755))
+	is.NoErr(os.MkdirAll(filepath.Join(src, "ci"), 0755))
+	is.NoErr(os.WriteFile(filepath.Join(src, "ci", "script.bass"), []byte("(defn main [] (oops))\n"), 0644))
+
+	bundle := new(bytes.Buffer)
+	_, err := cli.Bundle(context.Background(), bass.NewEmptyScope(), nil, bundle, filepath.Join(src, "ci", "script.bass"))
+	is.NoErr(err)
+
+	// the bundle refers to its source relative to the repository, so it maps
+	// to whichever checkout it is loaded from
+	checkout := t.TempDir()
+	is.NoErr(os.MkdirAll(filepath.Join(checkout, ".git"), 0755))
+	chdir(t, checkout)
+
+	script, err := cli.LoadBundle(bundle)
+	is.NoErr(err)
+
+	loc := bass.Range{
+		File:  script,
+		Start: bass.Position{Ln: 1, Col: 15},
+		End:   bass.Position{Ln: 1, Col: 21},
+	}
+
+	is.Equal(loc.String(), filepath.Join(checkout, "ci", "script.bass")+":1:15..1:21")
+}
+
+func TestBundleReproducible(t *testing.T) {
+	is := is.New(t)
+
+	bundle := func() []byte {
+		src := t.TempDir()
+		is.NoErr(os.MkdirAll(filepath.Join(src, ".git"), 0755))
+		is.NoErr(os.MkdirAll(filepath.Join(src, "ci"), 0755))
+		is.NoErr(os.WriteFile(filepath.Join(src, "ci", "script.bass"), []byte("(defn main [] 42)\n"), 0644))
+
+		buf := new(bytes.Buffer)
+		_, err := cli.Bundle(context.Background(), bass.NewEmptyScope(), nil, buf, filepath.Join(src, "ci", "script.bass"))
+		is.NoErr(err)
+
+		return buf.Bytes()
+	}
+
+	// the same files bundled from different checkouts are identical
+	is.Equal(bundle(), bundle())
+}
+
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = os.Chdir(cwd)
+	})
+}
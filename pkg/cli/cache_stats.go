@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/progrock"
+	"github.com/vito/progrock/graph"
+)
+
+// CacheStatsWriter returns a progress writer which records each completed
+// vertex as a cache hit or miss in the stats.
+//
+// Vertexes hidden from the user and failed vertexes are not counted.
+func CacheStatsWriter(stats *bass.CacheStats) progrock.Writer {
+	return &cacheStatsWriter{
+		stats: stats,
+		done:  map[digest.Digest]bool{},
+	}
+}
+
+type cacheStatsWriter struct {
+	stats *bass.CacheStats
+
+	done map[digest.Digest]bool
+	l    sync.Mutex
+}
+
+func (w *cacheStatsWriter) WriteStatus(status *graph.SolveStatus) {
+	w.l.Lock()
+	defer w.l.Unlock()
+
+	for _, v := range status.Vertexes {
+		if v.Completed == nil || v.Error != "" || w.done[v.Digest] {
+			continue
+		}
+
+		if strings.Contains(v.Name, "[hide]") {
+			continue
+		}
+
+		w.done[v.Digest] = true
+
+		if v.Cached {
+			w.stats.Hit(v.Digest.String())
+		} else if v.Started != nil {
+			w.stats.Miss(v.Digest.String(), v.Completed.Sub(*v.Started))
+		}
+	}
+}
+
+func (w *cacheStatsWriter) Close() {}
+
+// WriteCacheStatsMarkdown writes the cache stats as a line of Markdown,
+// following a summary written by WriteMarkdown.
+func WriteCacheStatsMarkdown(w io.Writer, report bass.CacheReport) error {
+	_, err := fmt.Fprintf(w, "\nCache: **%d** hits, **%d** misses (**%d%%**), **%s** reused, about **%s** saved.\n",
+		report.Hits,
+		report.Misses,
+		report.HitPercent(),
+		summaryBytes(report.BytesReused),
+		summaryDuration(report.TimeSaved))
+	return err
+}
+
+func summaryBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,59 @@
+package cli_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/cli"
+	"github.com/vito/is"
+	"github.com/vito/progrock/graph"
+)
+
+func TestCacheStatsWriter(t *testing.T) {
+	is := is.New(t)
+
+	start := time.Date(1991, 6, 3, 12, 0, 0, 0, time.UTC)
+	at := func(sec int) *time.Time {
+		t := start.Add(time.Duration(sec) * time.Second)
+		return &t
+	}
+
+	stats := bass.NewCacheStats()
+	w := cli.CacheStatsWriter(stats)
+
+	w.WriteStatus(&graph.SolveStatus{
+		Vertexes: []*graph.Vertex{
+			{Digest: "build", Name: "go build", Started: at(0)},
+			{Digest: "fetch", Name: "pull alpine", Started: at(0)},
+		},
+	})
+
+	w.WriteStatus(&graph.SolveStatus{
+		Vertexes: []*graph.Vertex{
+			{Digest: "build", Name: "go build", Started: at(0), Completed: at(3)},
+			{Digest: "fetch", Name: "pull alpine", Started: at(0), Completed: at(0), Cached: true},
+			{Digest: "test", Name: "go test", Started: at(3), Completed: at(4), Error: "exit status 1"},
+			{Digest: "hidden", Name: "[hide] mount bass ca", Started: at(0), Completed: at(1)},
+		},
+	})
+
+	// repeated statuses are only counted once
+	w.WriteStatus(&graph.SolveStatus{
+		Vertexes: []*graph.Vertex{
+			{Digest: "build", Name: "go build", Started: at(0), Completed: at(3)},
+		},
+	})
+
+	report := stats.Report()
+	is.Equal(report, bass.CacheReport{
+		Hits:   1,
+		Misses: 1,
+	})
+
+	buf := new(bytes.Buffer)
+	report.BytesReused = 3072
+	is.NoErr(cli.WriteCacheStatsMarkdown(buf, report))
+	is.Equal(buf.String(), "\nCache: **1** hits, **1** misses (**50%**), **3.0KiB** reused, about **0.0s** saved.\n")
+}
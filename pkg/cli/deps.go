@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+
+	"github.com/vito/bass/pkg/bass"
+)
+
+// Deps loads the script without calling its main, returning the modules,
+// remote scripts, and pinned images that it depended on along the way.
+//
+// Anything the script does at the top level is still done, e.g. running
+// thunks to fetch remote libraries, but values it emits are discarded.
+func Deps(ctx context.Context, env *bass.Scope, varFlags []string, filePath string) (*bass.DepGraph, error) {
+	dir, base := filepath.Split(filePath)
+
+	vars, err := LoadVars(filepath.Dir(filePath), varFlags)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, runs := bass.TrackRuns(ctx)
+
+	if _, ok := bass.WorkspaceFromContext(ctx); !ok {
+		ws := bass.NewWorkspace(false)
+		ctx = bass.WithWorkspace(ctx, ws)
+		defer ws.Cleanup()
+	}
+
+	graph := bass.NewDepGraph()
+	ctx = bass.WithDepGraph(ctx, graph)
+
+	cmd := bass.NewHostPath(
+		dir,
+		bass.ParseFileOrDirPath(filepath.ToSlash(base)),
+	)
+
+	thunk := bass.Thunk{
+		Cmd: bass.ThunkCmd{Host: &cmd},
+		Env: env,
+	}
+
+	state := thunk.RunState(io.Discard)
+	state.Vars = vars
+
+	_, err = bass.NewBass().Evaluate(ctx, thunk, state)
+
+	stopErr := stopRuns(ctx, runs)
+	if err != nil {
+		return nil, err
+	}
+
+	if stopErr != nil {
+		return nil, stopErr
+	}
+
+	return graph, nil
+}
@@ -0,0 +1,75 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/cli"
+	"github.com/vito/is"
+)
+
+func TestDeps(t *testing.T) {
+	is := is.New(t)
+
+	dir := t.TempDir()
+
+	is.NoErr(os.WriteFile(filepath.Join(dir, "script.bass"), []byte(`
+(use (.strings) (*dir*/lib.bass))
+
+(defn main []
+  (error "main should not be called"))
+`), 0644))
+
+	is.NoErr(os.WriteFile(filepath.Join(dir, "lib.bass"), []byte(`
+(use (.strings) (.time))
+
+(def *memos* *dir*/bass.lock)
+
+(store-memo *memos* (.run) :resolve
+            [{:platform {:os "linux"} :repository "alpine" :tag "3.18"}]
+            {:platform {:os "linux"} :repository "alpine" :tag "3.18" :digest "sha256:abc"})
+
+(def image (linux/alpine :3.18))
+`), 0644))
+
+	graph, err := cli.Deps(context.Background(), bass.NewEmptyScope(), nil, filepath.Join(dir, "script.bass"))
+	is.NoErr(err)
+
+	is.Equal(graph.Tree(), []bass.DepTree{
+		{
+			Dep: bass.Dep{Kind: bass.DepModule, Name: filepath.Join(dir, "script.bass")},
+			Deps: []bass.DepTree{
+				{Dep: bass.Dep{Kind: bass.DepStd, Name: ".strings"}},
+				{
+					Dep: bass.Dep{Kind: bass.DepModule, Name: filepath.Join(dir, "lib.bass")},
+					Deps: []bass.DepTree{
+						{Dep: bass.Dep{Kind: bass.DepStd, Name: ".strings"}},
+						{Dep: bass.Dep{Kind: bass.DepStd, Name: ".time"}},
+						{Dep: bass.Dep{Kind: bass.DepImage, Name: "alpine:3.18", Digest: "sha256:abc"}},
+					},
+				},
+			},
+		},
+	})
+
+	buf := new(bytes.Buffer)
+	is.NoErr(graph.WriteDOT(buf))
+	is.Equal(buf.String(), `digraph deps {
+  dep0 [label=`+strconv.Quote(filepath.Join(dir, "script.bass"))+`, shape=box];
+  dep1 [label=".strings", shape=box];
+  dep2 [label=`+strconv.Quote(filepath.Join(dir, "lib.bass"))+`, shape=box];
+  dep3 [label=".time", shape=box];
+  dep4 [label="alpine:3.18\nsha256:abc", shape=cylinder];
+  dep0 -> dep1;
+  dep0 -> dep2;
+  dep2 -> dep1;
+  dep2 -> dep3;
+  dep2 -> dep4;
+}
+`)
+}
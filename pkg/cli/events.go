@@ -0,0 +1,298 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/progrock/graph"
+)
+
+// Types of events written by an EventWriter.
+const (
+	EventRunStarted    = "run_started"
+	EventRunFinished   = "run_finished"
+	EventThunkStarted  = "thunk_started"
+	EventThunkFinished = "thunk_finished"
+	EventCacheHit      = "cache_hit"
+	EventLog           = "log"
+	EventCacheStats    = "cache_stats"
+	EventProgress      = "progress"
+)
+
+// Event is a single line of an event stream.
+type Event struct {
+	// Type is the kind of event, e.g. EventThunkStarted.
+	Type string `json:"type"`
+
+	// Time is when the event occurred.
+	Time time.Time `json:"time"`
+
+	// Vertex is the digest of the vertex the event is for, if any.
+	Vertex digest.Digest `json:"vertex,omitempty"`
+
+	// Name is the name of the vertex the event is for, if any.
+	Name string `json:"name,omitempty"`
+
+	// Duration is the number of seconds a finished vertex or run took.
+	Duration float64 `json:"duration,omitempty"`
+
+	// Error is the error the vertex or run failed with, if any.
+	Error string `json:"error,omitempty"`
+
+	// Stream is the stream a log chunk was written to; 1 for stdout and 2 for
+	// stderr.
+	Stream int `json:"stream,omitempty"`
+
+	// Data is the content of a log chunk.
+	Data string `json:"data,omitempty"`
+
+	// Task is the ID of a task within the vertex, e.g. a layer being pulled.
+	Task string `json:"task,omitempty"`
+
+	// Current is the number of bytes the task has processed so far.
+	Current int64 `json:"current,omitempty"`
+
+	// Total is the number of bytes the task will process, if known.
+	Total int64 `json:"total,omitempty"`
+
+	// Done is true once the task has finished.
+	Done bool `json:"done,omitempty"`
+
+	// Args are the command-line arguments of a run.
+	Args []string `json:"args,omitempty"`
+
+	// Cache is the cache stats of a run.
+	Cache *bass.CacheReport `json:"cache,omitempty"`
+}
+
+// EventWriter writes progress as a stream of newline-delimited JSON events
+// so that it can be consumed by other programs.
+type EventWriter struct {
+	enc *json.Encoder
+
+	started time.Time
+
+	vs  map[digest.Digest]*eventVertex
+	vsL sync.Mutex
+}
+
+type eventVertex struct {
+	name      string
+	started   bool
+	cached    bool
+	completed bool
+
+	tasks map[string]eventTask
+}
+
+type eventTask struct {
+	current int64
+	done    bool
+}
+
+// NewEventWriter returns an EventWriter which writes events to w.
+func NewEventWriter(w io.Writer) *EventWriter {
+	return &EventWriter{
+		enc: json.NewEncoder(w),
+		vs:  map[digest.Digest]*eventVertex{},
+	}
+}
+
+// RunStarted writes an EventRunStarted event.
+func (events *EventWriter) RunStarted(args []string) error {
+	events.vsL.Lock()
+	defer events.vsL.Unlock()
+
+	events.started = time.Now()
+
+	return events.enc.Encode(Event{
+		Type: EventRunStarted,
+		Time: events.started,
+		Args: args,
+	})
+}
+
+// RunFinished writes an EventRunFinished event with the overall result of
+// the run.
+func (events *EventWriter) RunFinished(runErr error) error {
+	events.vsL.Lock()
+	defer events.vsL.Unlock()
+
+	now := time.Now()
+
+	event := Event{
+		Type: EventRunFinished,
+		Time: now,
+	}
+
+	if !events.started.IsZero() {
+		event.Duration = now.Sub(events.started).Seconds()
+	}
+
+	if runErr != nil {
+		event.Error = runErr.Error()
+	}
+
+	return events.enc.Encode(event)
+}
+
+// CacheStats writes an EventCacheStats event with the cache stats of the
+// run.
+func (events *EventWriter) CacheStats(report bass.CacheReport) error {
+	events.vsL.Lock()
+	defer events.vsL.Unlock()
+
+	return events.enc.Encode(Event{
+		Type:  EventCacheStats,
+		Time:  time.Now(),
+		Cache: &report,
+	})
+}
+
+// WriteStatus writes events for any vertexes which started, hit the cache,
+// or finished, followed by the progress of their tasks and any logs.
+//
+// Vertexes hidden from the user are skipped, along with their logs.
+func (events *EventWriter) WriteStatus(status *graph.SolveStatus) {
+	events.vsL.Lock()
+	defer events.vsL.Unlock()
+
+	for _, v := range status.Vertexes {
+		vtx, found := events.vs[v.Digest]
+		if !found {
+			vtx = &eventVertex{}
+			events.vs[v.Digest] = vtx
+		}
+
+		vtx.name = v.Name
+
+		if strings.Contains(v.Name, "[hide]") {
+			continue
+		}
+
+		if v.Started != nil && !vtx.started {
+			vtx.started = true
+			events.write(Event{
+				Type:   EventThunkStarted,
+				Time:   *v.Started,
+				Vertex: v.Digest,
+				Name:   v.Name,
+			})
+		}
+
+		if v.Cached && !vtx.cached {
+			vtx.cached = true
+			events.write(Event{
+				Type:   EventCacheHit,
+				Time:   eventTime(v.Completed, v.Started),
+				Vertex: v.Digest,
+				Name:   v.Name,
+			})
+		}
+
+		if v.Completed != nil && !vtx.completed {
+			vtx.completed = true
+
+			event := Event{
+				Type:   EventThunkFinished,
+				Time:   *v.Completed,
+				Vertex: v.Digest,
+				Name:   v.Name,
+				Error:  stripUselessPart(v.Error),
+			}
+
+			if v.Started != nil {
+				event.Duration = v.Completed.Sub(*v.Started).Seconds()
+			}
+
+			events.write(event)
+		}
+	}
+
+	for _, s := range status.Statuses {
+		vtx, found := events.vs[s.Vertex]
+		if !found {
+			vtx = &eventVertex{}
+			events.vs[s.Vertex] = vtx
+		}
+
+		if strings.Contains(vtx.name, "[hide]") {
+			continue
+		}
+
+		task := eventTask{
+			current: s.Current,
+			done:    s.Completed != nil,
+		}
+
+		if vtx.tasks == nil {
+			vtx.tasks = map[string]eventTask{}
+		}
+
+		if last, seen := vtx.tasks[s.ID]; seen && last == task {
+			continue
+		}
+
+		vtx.tasks[s.ID] = task
+
+		events.write(Event{
+			Type:    EventProgress,
+			Time:    s.Timestamp,
+			Vertex:  s.Vertex,
+			Name:    vtx.name,
+			Task:    s.ID,
+			Current: s.Current,
+			Total:   s.Total,
+			Done:    task.done,
+		})
+	}
+
+	for _, l := range status.Logs {
+		vtx, found := events.vs[l.Vertex]
+		if found && strings.Contains(vtx.name, "[hide]") {
+			continue
+		}
+
+		event := Event{
+			Type:   EventLog,
+			Time:   l.Timestamp,
+			Vertex: l.Vertex,
+			Stream: l.Stream,
+			Data:   string(l.Data),
+		}
+
+		if found {
+			event.Name = vtx.name
+		}
+
+		events.write(event)
+	}
+}
+
+// Close does nothing; the events continue until RunFinished, since progress
+// is recorded separately for each command.
+func (events *EventWriter) Close() {}
+
+func (events *EventWriter) write(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	// errors are ignored; a broken event stream shouldn't fail the run
+	_ = events.enc.Encode(event)
+}
+
+func eventTime(ts ...*time.Time) time.Time {
+	for _, t := range ts {
+		if t != nil {
+			return *t
+		}
+	}
+
+	return time.Now()
+}
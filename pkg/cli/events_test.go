@@ -0,0 +1,146 @@
+package cli_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vito/bass/pkg/cli"
+	"github.com/vito/is"
+	"github.com/vito/progrock/graph"
+)
+
+func TestEventWriter(t *testing.T) {
+	is := is.New(t)
+
+	start := time.Date(1991, 6, 3, 12, 0, 0, 0, time.UTC)
+	at := func(sec int) *time.Time {
+		t := start.Add(time.Duration(sec) * time.Second)
+		return &t
+	}
+
+	buf := new(bytes.Buffer)
+	events := cli.NewEventWriter(buf)
+
+	is.NoErr(events.RunStarted([]string{"ci.bass"}))
+
+	events.WriteStatus(&graph.SolveStatus{
+		Vertexes: []*graph.Vertex{
+			{Digest: "build", Name: "go build", Started: at(0)},
+			{Digest: "hidden", Name: "[hide] mount bass ca", Started: at(0)},
+		},
+		Logs: []*graph.VertexLog{
+			{Vertex: "build", Stream: 2, Data: []byte("compiling\n"), Timestamp: *at(1)},
+			{Vertex: "hidden", Stream: 1, Data: []byte("secret\n"), Timestamp: *at(1)},
+		},
+	})
+
+	// repeated statuses only emit new transitions
+	events.WriteStatus(&graph.SolveStatus{
+		Vertexes: []*graph.Vertex{
+			{Digest: "build", Name: "go build", Started: at(0), Completed: at(3), Error: "exit status 1"},
+			{Digest: "fetch", Name: "git clone", Started: at(3), Completed: at(3), Cached: true},
+		},
+	})
+
+	is.NoErr(events.RunFinished(errors.New("go build failed")))
+
+	var got []cli.Event
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var event cli.Event
+		is.NoErr(json.Unmarshal(scanner.Bytes(), &event))
+		got = append(got, event)
+	}
+	is.NoErr(scanner.Err())
+
+	types := []string{}
+	for _, event := range got {
+		types = append(types, event.Type)
+	}
+
+	is.Equal(types, []string{
+		cli.EventRunStarted,
+		cli.EventThunkStarted,
+		cli.EventLog,
+		cli.EventThunkFinished,
+		cli.EventThunkStarted,
+		cli.EventCacheHit,
+		cli.EventThunkFinished,
+		cli.EventRunFinished,
+	})
+
+	is.Equal(got[0].Args, []string{"ci.bass"})
+
+	is.Equal(got[1].Name, "go build")
+	is.Equal(got[1].Time, *at(0))
+
+	is.Equal(got[2].Name, "go build")
+	is.Equal(got[2].Stream, 2)
+	is.Equal(got[2].Data, "compiling\n")
+
+	is.Equal(got[3].Error, "exit status 1")
+	is.Equal(got[3].Duration, 3.0)
+
+	is.Equal(got[5].Name, "git clone")
+
+	is.Equal(got[7].Error, "go build failed")
+}
+
+func TestEventWriterProgress(t *testing.T) {
+	is := is.New(t)
+
+	now := time.Date(1991, 6, 3, 12, 0, 0, 0, time.UTC)
+
+	buf := new(bytes.Buffer)
+	events := cli.NewEventWriter(buf)
+
+	events.WriteStatus(&graph.SolveStatus{
+		Vertexes: []*graph.Vertex{
+			{Digest: "pull", Name: "pull alpine", Started: &now},
+			{Digest: "hidden", Name: "[hide] load bass shim", Started: &now},
+		},
+		Statuses: []*graph.VertexStatus{
+			{ID: "layer a", Vertex: "pull", Current: 10, Total: 100, Timestamp: now},
+			{ID: "layer b", Vertex: "pull", Current: 0, Total: 50, Timestamp: now},
+			{ID: "shim", Vertex: "hidden", Current: 1, Timestamp: now},
+		},
+	})
+
+	// unchanged tasks are not repeated
+	events.WriteStatus(&graph.SolveStatus{
+		Statuses: []*graph.VertexStatus{
+			{ID: "layer a", Vertex: "pull", Current: 100, Total: 100, Timestamp: now, Completed: &now},
+			{ID: "layer b", Vertex: "pull", Current: 0, Total: 50, Timestamp: now},
+		},
+	})
+
+	var got []cli.Event
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var event cli.Event
+		is.NoErr(json.Unmarshal(scanner.Bytes(), &event))
+
+		if event.Type == cli.EventProgress {
+			got = append(got, event)
+		}
+	}
+	is.NoErr(scanner.Err())
+
+	is.Equal(len(got), 3)
+
+	is.Equal(got[0].Name, "pull alpine")
+	is.Equal(got[0].Task, "layer a")
+	is.Equal(got[0].Current, int64(10))
+	is.Equal(got[0].Total, int64(100))
+	is.True(!got[0].Done)
+
+	is.Equal(got[1].Task, "layer b")
+
+	is.Equal(got[2].Task, "layer a")
+	is.Equal(got[2].Current, int64(100))
+	is.True(got[2].Done)
+}
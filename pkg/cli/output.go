@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/vito/bass/pkg/bass"
+	"golang.org/x/term"
+	"sigs.k8s.io/yaml"
+)
+
+// OutputFormat is the format in which values emitted to *stdout* are written.
+type OutputFormat string
+
+const (
+	// OutputJSON writes each value as a line of JSON.
+	OutputJSON OutputFormat = "json"
+
+	// OutputYAML writes each value as a YAML document.
+	OutputYAML OutputFormat = "yaml"
+
+	// OutputPretty writes each value as indented JSON.
+	OutputPretty OutputFormat = "pretty"
+)
+
+// OutputFormats lists the supported output formats.
+var OutputFormats = []OutputFormat{OutputJSON, OutputYAML, OutputPretty}
+
+// NewOutputSink returns a sink which writes values to w in the given format.
+func NewOutputSink(name string, format OutputFormat, w io.Writer) (*bass.Sink, error) {
+	switch format {
+	case OutputJSON:
+		return bass.NewSink(bass.NewJSONSink(name, w)), nil
+	case OutputYAML, OutputPretty:
+		return bass.NewSink(&formatSink{
+			Name:   name,
+			Format: format,
+			w:      w,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q (expected one of %v)", format, OutputFormats)
+	}
+}
+
+// formatSink re-encodes the JSON form of each emitted value.
+type formatSink struct {
+	Name   string
+	Format OutputFormat
+
+	w       io.Writer
+	emitted bool
+}
+
+var _ bass.PipeSink = (*formatSink)(nil)
+var _ bass.TerminalPipe = (*formatSink)(nil)
+
+func (sink *formatSink) String() string {
+	return sink.Name
+}
+
+func (sink *formatSink) Emit(val bass.Value) error {
+	buf := new(bytes.Buffer)
+	if err := bass.NewValueEncoder(buf).EncodeValue(val); err != nil {
+		return err
+	}
+
+	out := new(bytes.Buffer)
+	switch sink.Format {
+	case OutputYAML:
+		payload, err := yaml.JSONToYAML(buf.Bytes())
+		if err != nil {
+			return err
+		}
+
+		if sink.emitted {
+			out.WriteString("---\n")
+		}
+
+		out.Write(payload)
+	case OutputPretty:
+		if err := json.Indent(out, bytes.TrimSpace(buf.Bytes()), "", "  "); err != nil {
+			return err
+		}
+
+		out.WriteByte('\n')
+	}
+
+	sink.emitted = true
+
+	_, err := sink.w.Write(out.Bytes())
+	return err
+}
+
+func (sink *formatSink) IsTerminal() bool {
+	f, ok := sink.w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
@@ -0,0 +1,60 @@
+package cli_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/cli"
+	"github.com/vito/is"
+)
+
+func TestOutputSink(t *testing.T) {
+	vals := []bass.Value{
+		bass.Bindings{
+			"a": bass.Int(1),
+			"b": bass.NewList(bass.String("x"), bass.Bool(true)),
+		}.Scope(),
+		bass.Int(2),
+	}
+
+	for _, example := range []struct {
+		Format cli.OutputFormat
+		Output string
+	}{
+		{
+			Format: cli.OutputJSON,
+			Output: `{"a":1,"b":["x",true]}` + "\n" + `2` + "\n",
+		},
+		{
+			Format: cli.OutputYAML,
+			Output: "a: 1\nb:\n- x\n- true\n---\n2\n",
+		},
+		{
+			Format: cli.OutputPretty,
+			Output: "{\n  \"a\": 1,\n  \"b\": [\n    \"x\",\n    true\n  ]\n}\n2\n",
+		},
+	} {
+		t.Run(string(example.Format), func(t *testing.T) {
+			is := is.New(t)
+
+			buf := new(bytes.Buffer)
+			sink, err := cli.NewOutputSink("test", example.Format, buf)
+			is.NoErr(err)
+
+			for _, val := range vals {
+				is.NoErr(sink.PipeSink.Emit(val))
+			}
+
+			is.Equal(buf.String(), example.Output)
+			is.True(!bass.IsTerminal(sink))
+		})
+	}
+}
+
+func TestOutputSinkUnknownFormat(t *testing.T) {
+	is := is.New(t)
+
+	_, err := cli.NewOutputSink("test", "xml", new(bytes.Buffer))
+	is.True(err != nil)
+}
@@ -125,12 +125,18 @@ func WithProgress(ctx context.Context, f func(context.Context) error) (err error
 	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
 	defer stop()
 
-	statuses, recorder, err := electRecorder()
+	statuses, w, err := electRecorder()
 	if err != nil {
 		WriteError(ctx, err)
 		return
 	}
 
+	if tee, ok := progressWriterFromContext(ctx); ok {
+		w = progrock.MultiWriter{w, tee}
+	}
+
+	recorder := progrock.NewRecorder(w)
+
 	ctx = progrock.RecorderToContext(ctx, recorder)
 
 	if statuses != nil {
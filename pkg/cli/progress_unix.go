@@ -14,7 +14,7 @@ import (
 	"github.com/vito/progrock/ui"
 )
 
-func electRecorder() (ui.Reader, *progrock.Recorder, error) {
+func electRecorder() (ui.Reader, progrock.Writer, error) {
 	socketPath, err := xdg.StateFile(fmt.Sprintf("bass/recorder.%d.sock", syscall.Getpgrp()))
 	if err != nil {
 		return nil, nil, err
@@ -30,7 +30,7 @@ func electRecorder() (ui.Reader, *progrock.Recorder, error) {
 		r, w, err = progrock.ServeRPC(l)
 	}
 
-	return r, progrock.NewRecorder(w), err
+	return r, w, err
 }
 
 func cleanupRecorder() error {
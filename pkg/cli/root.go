@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ProjectRoot returns the root of the git repository containing dir, i.e.
+// the closest directory containing a .git entry, or false if dir is not
+// within one.
+func ProjectRoot(dir string) (string, bool) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+
+	for {
+		if _, err := os.Lstat(filepath.Join(abs, ".git")); err == nil {
+			return abs, true
+		}
+
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return "", false
+		}
+
+		abs = parent
+	}
+}
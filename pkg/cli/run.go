@@ -2,25 +2,58 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"path/filepath"
+	"time"
 
 	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/zapctx"
+	"go.uber.org/zap"
 )
 
-func Run(ctx context.Context, env *bass.Scope, inputs []string, filePath string, argv []string, stdout *bass.Sink) error {
-	ctx, runs := bass.TrackRuns(ctx)
+// RunsStopTimeout is how long to wait for thunks started by a script to stop
+// once it finishes before reporting them as leaked.
+var RunsStopTimeout = 10 * time.Second
 
+func Run(ctx context.Context, env *bass.Scope, inputs []string, varFlags []string, filePath string, argv []string, stdout *bass.Sink) error {
 	dir, base := filepath.Split(filePath)
 
+	vars, err := LoadVars(filepath.Dir(filePath), varFlags)
+	if err != nil {
+		return err
+	}
+
 	cmd := bass.NewHostPath(
 		dir,
 		bass.ParseFileOrDirPath(filepath.ToSlash(base)),
 	)
 
+	return runCmd(ctx, env, vars, inputs, bass.ThunkCmd{Host: &cmd}, bass.NewHostDir(filepath.Dir(filePath)), argv, stdout)
+}
+
+// RunFS runs a script from a filesystem, e.g. a bundle loaded into memory.
+//
+// Variables files are not loaded, since the script is not on the host.
+func RunFS(ctx context.Context, env *bass.Scope, inputs []string, script *bass.FSPath, argv []string, stdout *bass.Sink) error {
+	dir := script.Path.File.Dir()
+
+	return runCmd(ctx, env, nil, inputs, bass.ThunkCmd{FS: script}, &bass.FSPath{
+		FS:   script.FS,
+		Path: bass.FileOrDirPath{Dir: &dir},
+	}, argv, stdout)
+}
+
+func runCmd(ctx context.Context, env *bass.Scope, vars *bass.Scope, inputs []string, cmd bass.ThunkCmd, dir bass.Path, argv []string, stdout *bass.Sink) error {
+	ctx, runs := bass.TrackRuns(ctx)
+
+	if _, ok := bass.WorkspaceFromContext(ctx); !ok {
+		ws := bass.NewWorkspace(false)
+		ctx = bass.WithWorkspace(ctx, ws)
+		defer ws.Cleanup()
+	}
+
 	thunk := bass.Thunk{
-		Cmd: bass.ThunkCmd{
-			Host: &cmd,
-		},
+		Cmd: cmd,
 		Env: env,
 	}
 
@@ -34,14 +67,41 @@ func Run(ctx context.Context, env *bass.Scope, inputs []string, filePath string,
 	}
 
 	err := bass.NewBass().Run(ctx, thunk, bass.RunState{
-		Dir:    bass.NewHostDir(filepath.Dir(filePath)),
+		Dir:    dir,
 		Stdin:  stdin,
 		Stdout: stdout,
 		Env:    thunk.Env,
+		Vars:   vars,
 	})
+
+	// stop started thunks even if the script failed, so they don't outlive it
+	stopErr := stopRuns(ctx, runs)
 	if err != nil {
 		return err
 	}
 
-	return runs.StopAndWait()
+	return stopErr
+}
+
+// stopRuns stops the runs and waits up to RunsStopTimeout for them to finish.
+// Runs which are still running are logged as leaks rather than failing the
+// script.
+func stopRuns(ctx context.Context, runs *bass.Runs) error {
+	runs.Stop()
+
+	// the script's context may already be canceled, e.g. on interrupt
+	waitCtx, cancel := context.WithTimeout(context.Background(), RunsStopTimeout)
+	defer cancel()
+
+	err := runs.WaitContext(waitCtx)
+
+	var leaks bass.LeakError
+	if errors.As(err, &leaks) {
+		zapctx.FromContext(ctx).Warn("started thunks did not stop",
+			zap.Strings("runs", leaks.Runs),
+			zap.Duration("timeout", RunsStopTimeout))
+		return nil
+	}
+
+	return err
 }
@@ -5,10 +5,12 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/vito/bass/pkg/bass"
 	"github.com/vito/bass/pkg/basstest"
 	"github.com/vito/bass/pkg/cli"
+	"github.com/vito/bass/pkg/runtimes"
 	"github.com/vito/is"
 )
 
@@ -17,6 +19,7 @@ func TestRun(t *testing.T) {
 		name    string
 		env     *bass.Scope
 		inputs  []string
+		vars    []string
 		helpers map[string]string
 		script  string
 		argv    []string
@@ -52,6 +55,18 @@ func TestRun(t *testing.T) {
 				}.Scope(),
 			},
 		},
+		{
+			name:   "vars",
+			script: `(defn main [] (emit *vars*:region *stdout*) (emit *vars*:replicas *stdout*))`,
+			helpers: map[string]string{
+				"bass.vars.yaml": "region: us-east-1\nreplicas: 1\n",
+			},
+			vars: []string{"region=eu-west-1"},
+			stdout: []bass.Value{
+				bass.String("eu-west-1"),
+				bass.Int(1),
+			},
+		},
 		{
 			name: "waiting on started thunks propagates errors",
 			helpers: map[string]string{
@@ -85,7 +100,7 @@ func TestRun(t *testing.T) {
 			}
 
 			stdout := bass.NewInMemorySink()
-			runErr := cli.Run(context.Background(), test.env, test.inputs, script, test.argv, bass.NewSink(stdout))
+			runErr := cli.Run(context.Background(), test.env, test.inputs, test.vars, script, test.argv, bass.NewSink(stdout))
 			if test.err != nil {
 				is.Equal(test.err, runErr)
 			} else {
@@ -99,3 +114,74 @@ func TestRun(t *testing.T) {
 		})
 	}
 }
+
+type blockingRuntime struct {
+	bass.Runtime
+
+	ignoreCancel bool
+
+	canceled chan struct{}
+	release  chan struct{}
+}
+
+func (runtime *blockingRuntime) Run(ctx context.Context, thunk bass.Thunk) error {
+	if runtime.ignoreCancel {
+		<-runtime.release
+		return nil
+	}
+
+	<-ctx.Done()
+	close(runtime.canceled)
+	return ctx.Err()
+}
+
+func TestRunStopsStartedThunks(t *testing.T) {
+	oldTimeout := cli.RunsStopTimeout
+	cli.RunsStopTimeout = 100 * time.Millisecond
+	defer func() { cli.RunsStopTimeout = oldTimeout }()
+
+	run := func(t *testing.T, runtime *blockingRuntime, script string) error {
+		is := is.New(t)
+
+		ctx := bass.WithRuntimePool(context.Background(), &runtimes.Pool{
+			Runtimes: []runtimes.Assoc{
+				{
+					Platform: bass.LinuxPlatform,
+					Runtime:  runtime,
+				},
+			},
+		})
+
+		path := filepath.Join(t.TempDir(), "script.bass")
+		is.NoErr(os.WriteFile(path, []byte(`
+			(def image {:platform {:os "linux"} :repository "alpine" :tag "latest"})
+		`+script), 0644))
+
+		return cli.Run(ctx, nil, nil, nil, path, nil, bass.NewSink(bass.NewInMemorySink()))
+	}
+
+	t.Run("when the script fails", func(t *testing.T) {
+		is := is.New(t)
+
+		runtime := &blockingRuntime{canceled: make(chan struct{})}
+
+		err := run(t, runtime, `(defn main [] (start (from image ($ sleep infinity)) null?) (error "boom"))`)
+		is.True(err != nil)
+
+		select {
+		case <-runtime.canceled:
+		default:
+			t.Fatal("started thunk was not canceled")
+		}
+	})
+
+	t.Run("when a thunk ignores cancelation", func(t *testing.T) {
+		is := is.New(t)
+
+		runtime := &blockingRuntime{ignoreCancel: true, release: make(chan struct{})}
+		defer close(runtime.release)
+
+		err := run(t, runtime, `(defn main [] (start (from image ($ sleep infinity)) null?))`)
+		is.NoErr(err)
+	})
+}
@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vito/progrock"
+)
+
+// summaryLogLines is the number of trailing log lines included for each
+// failure in a summary.
+const summaryLogLines = 20
+
+type progressWriterKey struct{}
+
+// TeeProgress configures WithProgress to also send all recorded progress to
+// the given writer, e.g. a Progress for writing a summary. It may be called
+// multiple times to send progress to multiple writers.
+func TeeProgress(ctx context.Context, w progrock.Writer) context.Context {
+	if tee, ok := progressWriterFromContext(ctx); ok {
+		w = progrock.MultiWriter{tee, w}
+	}
+
+	return context.WithValue(ctx, progressWriterKey{}, w)
+}
+
+func progressWriterFromContext(ctx context.Context) (progrock.Writer, bool) {
+	w, ok := ctx.Value(progressWriterKey{}).(progrock.Writer)
+	return w, ok
+}
+
+// WriteMarkdown writes a Markdown summary of the recorded progress, suitable
+// for a GitHub Actions job summary.
+//
+// The summary counts the vertexes which ran, were cached, and failed along
+// with the wall-clock time they spanned, lists each of them with its
+// duration, and includes the tail of the logs of each failure. The given
+// error is the overall result of the run.
+func (prog *Progress) WriteMarkdown(w io.Writer, runErr error) error {
+	prog.vsL.Lock()
+	defer prog.vsL.Unlock()
+
+	vs := prog.visibleVertexes()
+
+	var ran, cached, failed int
+	var first, last time.Time
+	for _, vtx := range vs {
+		switch {
+		case vtx.Cached:
+			cached++
+		case vtx.Error != "" && !isCanceled(vtx.Error):
+			failed++
+		case vtx.Started != nil:
+			ran++
+		}
+
+		if vtx.Started != nil && (first.IsZero() || vtx.Started.Before(first)) {
+			first = *vtx.Started
+		}
+
+		if vtx.Completed != nil && vtx.Completed.After(last) {
+			last = *vtx.Completed
+		}
+	}
+
+	var total time.Duration
+	if !first.IsZero() && last.After(first) {
+		total = last.Sub(first)
+	}
+
+	buf := new(bytes.Buffer)
+
+	if runErr != nil {
+		fmt.Fprintln(buf, "## :x: bass run failed")
+		fmt.Fprintln(buf)
+		fmt.Fprintf(buf, "> %s\n", markdownLine(runErr.Error()))
+	} else {
+		fmt.Fprintln(buf, "## :white_check_mark: bass run succeeded")
+	}
+
+	fmt.Fprintln(buf)
+	fmt.Fprintf(buf, "**%d** ran, **%d** cached, **%d** failed in **%s**.\n", ran, cached, failed, summaryDuration(total))
+
+	if len(vs) > 0 {
+		fmt.Fprintln(buf)
+		fmt.Fprintln(buf, "| | Step | Duration |")
+		fmt.Fprintln(buf, "|-|------|---------:|")
+
+		for _, vtx := range vs {
+			dur := "-"
+			if vtx.Started != nil && vtx.Completed != nil {
+				dur = summaryDuration(vtx.Completed.Sub(*vtx.Started))
+			}
+
+			fmt.Fprintf(buf, "| %s | %s | %s |\n", vertexEmoji(vtx), markdownCell(vtx.Name), dur)
+		}
+	}
+
+	var failures []*Vertex
+	for _, vtx := range vs {
+		if vtx.Error != "" && !isCanceled(vtx.Error) {
+			failures = append(failures, vtx)
+		}
+	}
+
+	if len(failures) > 0 {
+		fmt.Fprintln(buf)
+		fmt.Fprintln(buf, "### Failures")
+
+		for _, vtx := range failures {
+			fmt.Fprintln(buf)
+			fmt.Fprintf(buf, "#### %s\n", markdownLine(vtx.Name))
+			fmt.Fprintln(buf)
+			fmt.Fprintf(buf, "> %s\n", markdownLine(stripUselessPart(vtx.Error)))
+
+			if vtx.Log.Len() > 0 {
+				fmt.Fprintln(buf)
+				fmt.Fprintln(buf, "```")
+				for _, line := range logTail(vtx.Log.Bytes(), summaryLogLines) {
+					fmt.Fprintln(buf, line)
+				}
+				fmt.Fprintln(buf, "```")
+			}
+		}
+	}
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// visibleVertexes returns the vertexes which are shown to the user, ordered
+// by start time.
+func (prog *Progress) visibleVertexes() []*Vertex {
+	vs := make([]*Vertex, 0, len(prog.vs))
+	for _, vtx := range prog.vs {
+		if strings.Contains(vtx.Name, "[hide]") {
+			continue
+		}
+
+		vs = append(vs, vtx)
+	}
+
+	sort.SliceStable(vs, func(i, j int) bool {
+		a, b := vs[i], vs[j]
+		if a.Started == nil || b.Started == nil {
+			if a.Started == nil && b.Started == nil {
+				return a.Name < b.Name
+			}
+
+			return a.Started != nil
+		}
+
+		return a.Started.Before(*b.Started)
+	})
+
+	return vs
+}
+
+func vertexEmoji(vtx *Vertex) string {
+	switch {
+	case vtx.Cached:
+		return ":zap:"
+	case vtx.Error != "" && isCanceled(vtx.Error):
+		return ":no_entry_sign:"
+	case vtx.Error != "":
+		return ":x:"
+	case vtx.Completed != nil:
+		return ":white_check_mark:"
+	case vtx.Started != nil:
+		return ":hourglass:"
+	default:
+		return ":white_circle:"
+	}
+}
+
+func summaryDuration(dt time.Duration) string {
+	return fmt.Sprintf("%.1fs", dt.Seconds())
+}
+
+// logTail returns the last n lines of the log, preceded by a note of how many
+// were omitted.
+func logTail(log []byte, n int) []string {
+	lines := strings.Split(strings.TrimRight(string(log), "\n"), "\n")
+	if len(lines) <= n {
+		return lines
+	}
+
+	omitted := len(lines) - n
+	return append([]string{fmt.Sprintf("... %d lines omitted ...", omitted)}, lines[omitted:]...)
+}
+
+func markdownLine(str string) string {
+	return strings.ReplaceAll(strings.TrimSpace(str), "\n", " ")
+}
+
+func markdownCell(str string) string {
+	return strings.ReplaceAll(markdownLine(str), "|", `\|`)
+}
@@ -0,0 +1,87 @@
+package cli_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/vito/bass/pkg/cli"
+	"github.com/vito/is"
+	"github.com/vito/progrock/graph"
+)
+
+func TestProgressWriteMarkdown(t *testing.T) {
+	is := is.New(t)
+
+	start := time.Date(1991, 6, 3, 12, 0, 0, 0, time.UTC)
+	at := func(sec int) *time.Time {
+		t := start.Add(time.Duration(sec) * time.Second)
+		return &t
+	}
+
+	var failLog strings.Builder
+	for i := 1; i <= 30; i++ {
+		fmt.Fprintf(&failLog, "line %d\n", i)
+	}
+
+	prog := cli.NewProgress()
+	prog.WriteStatus(&graph.SolveStatus{
+		Vertexes: []*graph.Vertex{
+			{
+				Digest:    "build",
+				Name:      "go build ./...",
+				Started:   at(0),
+				Completed: at(3),
+			},
+			{
+				Digest:    "fetch",
+				Name:      "git clone",
+				Started:   at(0),
+				Completed: at(0),
+				Cached:    true,
+			},
+			{
+				Digest:    "test",
+				Name:      "go test | tee",
+				Started:   at(3),
+				Completed: at(5),
+				Error:     "exit status 1",
+			},
+			{
+				Digest:    "hidden",
+				Name:      "[hide] mount bass ca",
+				Started:   at(0),
+				Completed: at(1),
+			},
+		},
+		Logs: []*graph.VertexLog{
+			{
+				Vertex: digest.Digest("test"),
+				Data:   []byte(failLog.String()),
+			},
+		},
+	})
+
+	buf := new(bytes.Buffer)
+	is.NoErr(prog.WriteMarkdown(buf, errors.New("go test failed")))
+
+	summary := buf.String()
+	is.True(strings.HasPrefix(summary, "## :x: bass run failed\n\n> go test failed\n"))
+	is.True(strings.Contains(summary, "**1** ran, **1** cached, **1** failed in **5.0s**."))
+	is.True(strings.Contains(summary, "| :white_check_mark: | go build ./... | 3.0s |\n"))
+	is.True(strings.Contains(summary, "| :zap: | git clone | 0.0s |\n"))
+	is.True(strings.Contains(summary, `| :x: | go test \| tee | 2.0s |`+"\n"))
+	is.True(!strings.Contains(summary, "[hide]"))
+	is.True(strings.Contains(summary, "#### go test | tee\n\n> exit status 1\n"))
+	is.True(strings.Contains(summary, "... 10 lines omitted ...\nline 11\n"))
+	is.True(strings.Contains(summary, "line 30\n```\n"))
+	is.True(!strings.Contains(summary, "line 10\n"))
+
+	buf.Reset()
+	is.NoErr(cli.NewProgress().WriteMarkdown(buf, nil))
+	is.Equal(buf.String(), "## :white_check_mark: bass run succeeded\n\n**0** ran, **0** cached, **0** failed in **0.0s**.\n")
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"os"
+	"sync"
+
+	"github.com/vito/bass/pkg/bass"
+	"golang.org/x/term"
+)
+
+// Terminal attaches interactive thunks to the controlling terminal.
+//
+// The terminal is opened directly so that interactive thunks work even when
+// stdin and stdout are piped. Only one thunk may be attached at a time.
+type Terminal struct {
+	l sync.Mutex
+}
+
+var _ bass.Terminal = &Terminal{}
+
+func (tty *Terminal) Attach() (bass.InteractiveIO, func(), error) {
+	f, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return bass.InteractiveIO{}, nil, bass.ErrNonInteractive
+	}
+
+	fd := int(f.Fd())
+	if !term.IsTerminal(fd) {
+		f.Close()
+		return bass.InteractiveIO{}, nil, bass.ErrNonInteractive
+	}
+
+	tty.l.Lock()
+
+	restore, err := AttachTerminal(f)
+	if err != nil {
+		tty.l.Unlock()
+		f.Close()
+		return bass.InteractiveIO{}, nil, err
+	}
+
+	tio := bass.InteractiveIO{
+		Stdin:  f,
+		Stdout: f,
+		Stderr: f,
+		TTY:    true,
+	}
+
+	sizes, stopWatching := watchWindowSize(fd)
+	tio.Resize = sizes
+
+	return tio, func() {
+		stopWatching()
+		restore()
+		f.Close()
+		tty.l.Unlock()
+	}, nil
+}
+
+// AttachTerminal switches the terminal to raw mode so that keystrokes are
+// passed through as-is, returning a function which restores its prior state.
+func AttachTerminal(f *os.File) (func(), error) {
+	fd := int(f.Fd())
+
+	before, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		_ = term.Restore(fd, before)
+	}, nil
+}
+
+// WatchWindowSize sends the size of the terminal whenever it changes,
+// starting with its initial size, until stop is called.
+func WatchWindowSize(f *os.File) (<-chan bass.WindowSize, func()) {
+	return watchWindowSize(int(f.Fd()))
+}
+
+// sendWindowSize sends the terminal's current size, replacing any size which
+// has not been received yet.
+func sendWindowSize(fd int, sizes chan bass.WindowSize) {
+	cols, rows, err := term.GetSize(fd)
+	if err != nil {
+		return
+	}
+
+	select {
+	case <-sizes:
+	default:
+	}
+
+	sizes <- bass.WindowSize{Rows: rows, Cols: cols}
+}
@@ -0,0 +1,37 @@
+//go:build !windows
+// +build !windows
+
+package cli
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/vito/bass/pkg/bass"
+)
+
+func watchWindowSize(fd int) (<-chan bass.WindowSize, func()) {
+	sizes := make(chan bass.WindowSize, 1)
+	sendWindowSize(fd, sizes)
+
+	winch := make(chan os.Signal, 1)
+	signal.Notify(winch, syscall.SIGWINCH)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(sizes)
+		defer signal.Stop(winch)
+
+		for {
+			select {
+			case <-winch:
+				sendWindowSize(fd, sizes)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return sizes, func() { close(done) }
+}
@@ -0,0 +1,11 @@
+package cli
+
+import "github.com/vito/bass/pkg/bass"
+
+// watchWindowSize only sends the initial size, since Windows has no signal
+// for resizes.
+func watchWindowSize(fd int) (<-chan bass.WindowSize, func()) {
+	sizes := make(chan bass.WindowSize, 1)
+	sendWindowSize(fd, sizes)
+	return sizes, func() { close(sizes) }
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vito/bass/pkg/bass"
+	"sigs.k8s.io/yaml"
+)
+
+// VarsFiles are the names of the files loaded into *vars*.
+var VarsFiles = []string{"bass.vars.yaml", "bass.vars.json"}
+
+// LoadVars loads the variables for a script in dir.
+//
+// Variables files are loaded from dir and each of its parent directories up
+// to the root of its git repository, with files closer to dir overriding
+// those further away. Outside of a git repository only dir is searched, so
+// that unrelated files in e.g. the home directory aren't picked up.
+//
+// The flags, each in name=value form, are applied last and override the
+// files. Values are parsed as JSON if possible, and are strings otherwise.
+func LoadVars(dir string, flags []string) (*bass.Scope, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	root, found := ProjectRoot(abs)
+	if !found {
+		root = abs
+	}
+
+	var dirs []string
+	for {
+		dirs = append(dirs, abs)
+
+		parent := filepath.Dir(abs)
+		if abs == root || parent == abs {
+			break
+		}
+
+		abs = parent
+	}
+
+	vars := bass.NewEmptyScope()
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		file, err := loadVarsFile(dirs[i])
+		if err != nil {
+			return nil, err
+		}
+
+		if file == nil {
+			continue
+		}
+
+		err = file.Each(func(sym bass.Symbol, val bass.Value) error {
+			vars.Set(sym, val)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, flag := range flags {
+		name, val, ok := strings.Cut(flag, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --var %q: expected name=value", flag)
+		}
+
+		vars.Set(bass.Symbol(name), parseVar(val))
+	}
+
+	return vars, nil
+}
+
+// parseVar parses a --var value as JSON, falling back to a string.
+func parseVar(val string) bass.Value {
+	var parsed bass.Value
+	if err := bass.UnmarshalJSON([]byte(val), &parsed); err == nil && parsed != nil {
+		return parsed
+	}
+
+	return bass.String(val)
+}
+
+// loadVarsFile loads the variables file in dir, returning nil if there is
+// none.
+func loadVarsFile(dir string) (*bass.Scope, error) {
+	var found string
+	var payload []byte
+	for _, name := range VarsFiles {
+		path := filepath.Join(dir, name)
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		if found != "" {
+			return nil, fmt.Errorf("%s: conflicts with %s", path, found)
+		}
+
+		found = path
+		payload = content
+	}
+
+	if found == "" {
+		return nil, nil
+	}
+
+	if filepath.Ext(found) == ".yaml" {
+		var err error
+		payload, err = yaml.YAMLToJSON(payload)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", found, err)
+		}
+	}
+
+	var vars *bass.Scope
+	if err := bass.UnmarshalJSON(payload, &vars); err != nil {
+		return nil, fmt.Errorf("%s: %w", found, err)
+	}
+
+	return vars, nil
+}
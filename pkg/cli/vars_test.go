@@ -0,0 +1,72 @@
+package cli_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/basstest"
+	"github.com/vito/bass/pkg/cli"
+	"github.com/vito/is"
+)
+
+func TestLoadVars(t *testing.T) {
+	is := is.New(t)
+
+	outside := t.TempDir()
+	root := filepath.Join(outside, "repo")
+	dir := filepath.Join(root, "ci", "deploy")
+	is.NoErr(os.MkdirAll(dir, 0755))
+	is.NoErr(os.MkdirAll(filepath.Join(root, ".git"), 0755))
+
+	// outside of the repository, so not loaded
+	is.NoErr(os.WriteFile(filepath.Join(outside, "bass.vars.yaml"), []byte(`
+region: nowhere
+outside: true
+`), 0644))
+
+	is.NoErr(os.WriteFile(filepath.Join(root, "bass.vars.yaml"), []byte(`
+region: us-east-1
+replicas: 1
+tags: [a, b]
+`), 0644))
+
+	is.NoErr(os.WriteFile(filepath.Join(dir, "bass.vars.json"), []byte(`{"replicas": 3, "env": "staging"}`), 0644))
+
+	vars, err := cli.LoadVars(dir, nil)
+	is.NoErr(err)
+	basstest.Equal(t, bass.Bindings{
+		"region":   bass.String("us-east-1"),
+		"replicas": bass.Int(3),
+		"tags":     bass.NewList(bass.String("a"), bass.String("b")),
+		"env":      bass.String("staging"),
+	}.Scope(), vars)
+
+	vars, err = cli.LoadVars(dir, []string{"env=prod", "debug=", "replicas=5", "tags=[\"c\"]", "tag=\"1.0\"", "ready=true"})
+	is.NoErr(err)
+	basstest.Equal(t, bass.Bindings{
+		"region":   bass.String("us-east-1"),
+		"replicas": bass.Int(5),
+		"tags":     bass.NewList(bass.String("c")),
+		"env":      bass.String("prod"),
+		"debug":    bass.String(""),
+		"tag":      bass.String("1.0"),
+		"ready":    bass.Bool(true),
+	}.Scope(), vars)
+
+	// outside of a repository, only the directory itself is searched
+	vars, err = cli.LoadVars(outside, nil)
+	is.NoErr(err)
+	basstest.Equal(t, bass.Bindings{
+		"region":  bass.String("nowhere"),
+		"outside": bass.Bool(true),
+	}.Scope(), vars)
+
+	_, err = cli.LoadVars(dir, []string{"bogus"})
+	is.True(err != nil)
+
+	is.NoErr(os.WriteFile(filepath.Join(dir, "bass.vars.yaml"), []byte(`env: dev`), 0644))
+	_, err = cli.LoadVars(dir, nil)
+	is.True(err != nil)
+}
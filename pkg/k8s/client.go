@@ -0,0 +1,298 @@
+// Package k8s is a minimal client for the Kubernetes API, supporting
+// server-side apply, waiting for rollouts, and fetching pod logs.
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldManager is the field manager used when applying manifests.
+const FieldManager = "bass"
+
+// PollInterval is how often Wait checks on the status of a resource.
+var PollInterval = 2 * time.Second
+
+// Client is a client for the Kubernetes API.
+type Client struct {
+	// Server is the URL of the API server.
+	Server string
+
+	// Namespace is the default namespace for namespaced resources.
+	Namespace string
+
+	// Token is a bearer token to authenticate with, if any.
+	Token string
+
+	// Username and Password are basic auth credentials, if any.
+	Username string
+	Password string
+
+	http *http.Client
+
+	// exec provides credentials from a plugin, if configured
+	exec *execPlugin
+
+	// resources caches API resources by group version and kind
+	resources map[string]map[string]apiResource
+}
+
+// LogOpts configures which logs are fetched by Logs.
+type LogOpts struct {
+	// Container selects the container to fetch logs from.
+	Container string
+
+	// Tail limits the number of lines of logs to fetch.
+	Tail int
+}
+
+// Apply applies the JSON manifest using server-side apply and returns the
+// applied resource.
+func (client *Client) Apply(ctx context.Context, manifest []byte) (json.RawMessage, error) {
+	path, err := client.resourcePath(ctx, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{
+		"fieldManager": {FieldManager},
+		"force":        {"true"},
+	}
+
+	var applied json.RawMessage
+	err = client.request(ctx, http.MethodPatch, path, query, "application/apply-patch+yaml", manifest, &applied)
+	if err != nil {
+		return nil, fmt.Errorf("apply %s: %w", path, err)
+	}
+
+	return applied, nil
+}
+
+// Wait polls the JSON resource until it is ready, returning it as last
+// observed.
+//
+// Deployments, stateful sets, and daemon sets are ready once their rollout
+// is complete. Jobs are ready once they succeed. Other resources are ready
+// once they exist.
+func (client *Client) Wait(ctx context.Context, resource []byte) (json.RawMessage, error) {
+	path, err := client.resourcePath(ctx, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	var meta struct {
+		Kind string `json:"kind"`
+	}
+	_ = json.Unmarshal(resource, &meta)
+
+	for {
+		var current json.RawMessage
+		err := client.request(ctx, http.MethodGet, path, nil, "", nil, &current)
+		if err != nil {
+			return nil, fmt.Errorf("wait for %s: %w", path, err)
+		}
+
+		ready, err := isReady(meta.Kind, current)
+		if err != nil {
+			return nil, fmt.Errorf("wait for %s: %w", path, err)
+		}
+
+		if ready {
+			return current, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("wait for %s: %w", path, ctx.Err())
+		case <-time.After(PollInterval):
+		}
+	}
+}
+
+// Logs fetches the logs of each pod matching the label selector, keyed by
+// pod name.
+func (client *Client) Logs(ctx context.Context, labelSelector string, opts LogOpts) (map[string]string, error) {
+	podsPath := "/api/v1/namespaces/" + url.PathEscape(client.Namespace) + "/pods"
+
+	var pods struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		} `json:"items"`
+	}
+	err := client.request(ctx, http.MethodGet, podsPath, url.Values{"labelSelector": {labelSelector}}, "", nil, &pods)
+	if err != nil {
+		return nil, fmt.Errorf("list pods: %w", err)
+	}
+
+	query := url.Values{}
+	if opts.Container != "" {
+		query.Set("container", opts.Container)
+	}
+
+	if opts.Tail > 0 {
+		query.Set("tailLines", strconv.Itoa(opts.Tail))
+	}
+
+	logs := map[string]string{}
+	for _, pod := range pods.Items {
+		buf := new(bytes.Buffer)
+		err := client.request(ctx, http.MethodGet, podsPath+"/"+url.PathEscape(pod.Metadata.Name)+"/log", query, "", nil, buf)
+		if err != nil {
+			return nil, fmt.Errorf("logs for pod %s: %w", pod.Metadata.Name, err)
+		}
+
+		logs[pod.Metadata.Name] = buf.String()
+	}
+
+	return logs, nil
+}
+
+// resourcePath returns the API path for the resource described by the JSON
+// manifest, discovering the resource name for its kind.
+func (client *Client) resourcePath(ctx context.Context, manifest []byte) (string, error) {
+	var meta struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+		Metadata   struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace,omitempty"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(manifest, &meta); err != nil {
+		return "", fmt.Errorf("resource: %w", err)
+	}
+
+	res, err := client.discover(ctx, meta.APIVersion, meta.Kind)
+	if err != nil {
+		return "", err
+	}
+
+	path := groupVersionPath(meta.APIVersion)
+	if res.Namespaced {
+		ns := meta.Metadata.Namespace
+		if ns == "" {
+			ns = client.Namespace
+		}
+
+		path += "/namespaces/" + url.PathEscape(ns)
+	}
+
+	return path + "/" + res.Name + "/" + url.PathEscape(meta.Metadata.Name), nil
+}
+
+type apiResource struct {
+	Name       string `json:"name"`
+	Kind       string `json:"kind"`
+	Namespaced bool   `json:"namespaced"`
+}
+
+func (client *Client) discover(ctx context.Context, apiVersion, kind string) (apiResource, error) {
+	kinds, found := client.resources[apiVersion]
+	if !found {
+		var list struct {
+			Resources []apiResource `json:"resources"`
+		}
+		err := client.request(ctx, http.MethodGet, groupVersionPath(apiVersion), nil, "", nil, &list)
+		if err != nil {
+			return apiResource{}, fmt.Errorf("discover %s: %w", apiVersion, err)
+		}
+
+		kinds = map[string]apiResource{}
+		for _, res := range list.Resources {
+			if strings.Contains(res.Name, "/") {
+				// subresource, e.g. deployments/scale
+				continue
+			}
+
+			kinds[res.Kind] = res
+		}
+
+		client.resources[apiVersion] = kinds
+	}
+
+	res, found := kinds[kind]
+	if !found {
+		return apiResource{}, fmt.Errorf("unknown kind %s in %s", kind, apiVersion)
+	}
+
+	return res, nil
+}
+
+// request sends a request to the API server and decodes the response into
+// dest, which is either an io.Writer for the raw response or a destination
+// for the JSON response.
+func (client *Client) request(ctx context.Context, method, path string, query url.Values, contentType string, body []byte, dest any) error {
+	u := client.Server + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	token := client.Token
+	if token == "" && client.exec != nil {
+		cred, err := client.exec.credential(ctx)
+		if err != nil {
+			return err
+		}
+
+		token = cred.Token
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if client.Username != "" {
+		req.SetBasicAuth(client.Username, client.Password)
+	}
+
+	res, err := client.http.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		var status struct {
+			Message string `json:"message"`
+		}
+		_ = json.NewDecoder(res.Body).Decode(&status)
+		if status.Message == "" {
+			return fmt.Errorf("%s", res.Status)
+		}
+
+		return fmt.Errorf("%s: %s", res.Status, status.Message)
+	}
+
+	if w, ok := dest.(io.Writer); ok {
+		_, err := io.Copy(w, res.Body)
+		return err
+	}
+
+	return json.NewDecoder(res.Body).Decode(dest)
+}
+
+func groupVersionPath(apiVersion string) string {
+	if strings.Contains(apiVersion, "/") {
+		return "/apis/" + apiVersion
+	}
+
+	return "/api/" + apiVersion
+}
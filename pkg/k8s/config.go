@@ -0,0 +1,377 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Config is the subset of the kubeconfig format needed to connect to a
+// cluster.
+type Config struct {
+	CurrentContext string `json:"current-context"`
+
+	Clusters []struct {
+		Name    string  `json:"name"`
+		Cluster Cluster `json:"cluster"`
+	} `json:"clusters"`
+
+	Users []struct {
+		Name string `json:"name"`
+		User User   `json:"user"`
+	} `json:"users"`
+
+	Contexts []struct {
+		Name    string  `json:"name"`
+		Context Context `json:"context"`
+	} `json:"contexts"`
+
+	// dir is the directory that relative paths are relative to.
+	dir string
+}
+
+// Cluster configures how to reach an API server.
+type Cluster struct {
+	Server                   string `json:"server"`
+	CertificateAuthority     string `json:"certificate-authority"`
+	CertificateAuthorityData []byte `json:"certificate-authority-data"`
+	InsecureSkipTLSVerify    bool   `json:"insecure-skip-tls-verify"`
+}
+
+// User configures how to authenticate to an API server.
+type User struct {
+	ClientCertificate     string `json:"client-certificate"`
+	ClientCertificateData []byte `json:"client-certificate-data"`
+	ClientKey             string `json:"client-key"`
+	ClientKeyData         []byte `json:"client-key-data"`
+	Token                 string `json:"token"`
+	TokenFile             string `json:"tokenFile"`
+	Username              string `json:"username"`
+	Password              string `json:"password"`
+
+	// Exec configures a credential plugin, e.g. for cloud providers.
+	Exec *ExecConfig `json:"exec"`
+}
+
+// ExecConfig configures a command which prints an ExecCredential.
+type ExecConfig struct {
+	APIVersion string   `json:"apiVersion"`
+	Command    string   `json:"command"`
+	Args       []string `json:"args"`
+	Env        []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"env"`
+}
+
+// Context selects a cluster, user, and namespace.
+type Context struct {
+	Cluster   string `json:"cluster"`
+	User      string `json:"user"`
+	Namespace string `json:"namespace"`
+}
+
+// LoadConfig loads the kubeconfig from the given path, or from $KUBECONFIG
+// or ~/.kube/config if it is empty.
+func LoadConfig(path string) (*Config, error) {
+	if path == "" {
+		if env := os.Getenv("KUBECONFIG"); env != "" {
+			path = filepath.SplitList(env)[0]
+		} else {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, err
+			}
+
+			path = filepath.Join(home, ".kube", "config")
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read kubeconfig: %w", err)
+	}
+
+	return ParseConfig(content, filepath.Dir(path))
+}
+
+// ParseConfig parses a kubeconfig, resolving relative paths within it
+// against dir.
+func ParseConfig(content []byte, dir string) (*Config, error) {
+	config := &Config{dir: dir}
+	if err := yaml.Unmarshal(content, config); err != nil {
+		return nil, fmt.Errorf("parse kubeconfig: %w", err)
+	}
+
+	return config, nil
+}
+
+// Client returns a client for the named context, or the current context if
+// the name is empty. The namespace defaults to the context's namespace.
+//
+// The given HTTP client's transport must be an *http.Transport; its TLS
+// config is modified to trust the cluster and present the user's
+// certificate.
+func (config *Config) Client(httpClient *http.Client, contextName, namespace string) (*Client, error) {
+	if contextName == "" {
+		contextName = config.CurrentContext
+	}
+
+	kctx, found := config.context(contextName)
+	if !found {
+		return nil, fmt.Errorf("kubeconfig: unknown context: %q", contextName)
+	}
+
+	cluster, found := config.cluster(kctx.Cluster)
+	if !found {
+		return nil, fmt.Errorf("kubeconfig: unknown cluster: %q", kctx.Cluster)
+	}
+
+	var user User
+	if kctx.User != "" {
+		user, found = config.user(kctx.User)
+		if !found {
+			return nil, fmt.Errorf("kubeconfig: unknown user: %q", kctx.User)
+		}
+	}
+
+	transport, ok := httpClient.Transport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("kubeconfig: unsupported transport: %T", httpClient.Transport)
+	}
+
+	tlsConfig := transport.TLSClientConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	tlsConfig.InsecureSkipVerify = cluster.InsecureSkipTLSVerify
+
+	ca, err := config.data(cluster.CertificateAuthorityData, cluster.CertificateAuthority)
+	if err != nil {
+		return nil, fmt.Errorf("kubeconfig: certificate authority: %w", err)
+	}
+
+	if len(ca) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("kubeconfig: no certificates found for cluster %q", kctx.Cluster)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	cert, err := config.data(user.ClientCertificateData, user.ClientCertificate)
+	if err != nil {
+		return nil, fmt.Errorf("kubeconfig: client certificate: %w", err)
+	}
+
+	key, err := config.data(user.ClientKeyData, user.ClientKey)
+	if err != nil {
+		return nil, fmt.Errorf("kubeconfig: client key: %w", err)
+	}
+
+	if len(cert) > 0 {
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("kubeconfig: client certificate: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{pair}
+	}
+
+	token := user.Token
+	if token == "" && user.TokenFile != "" {
+		content, err := os.ReadFile(config.path(user.TokenFile))
+		if err != nil {
+			return nil, fmt.Errorf("kubeconfig: token: %w", err)
+		}
+
+		token = strings.TrimSpace(string(content))
+	}
+
+	var plugin *execPlugin
+	if user.Exec != nil {
+		plugin = &execPlugin{
+			config: *user.Exec,
+			dir:    config.dir,
+		}
+
+		if len(cert) == 0 {
+			tlsConfig.GetClientCertificate = plugin.clientCertificate
+		}
+	}
+
+	if namespace == "" {
+		namespace = kctx.Namespace
+	}
+
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	return &Client{
+		Server:    strings.TrimSuffix(cluster.Server, "/"),
+		Namespace: namespace,
+		Token:     token,
+		Username:  user.Username,
+		Password:  user.Password,
+
+		http:      httpClient,
+		exec:      plugin,
+		resources: map[string]map[string]apiResource{},
+	}, nil
+}
+
+func (config *Config) context(name string) (Context, bool) {
+	for _, c := range config.Contexts {
+		if c.Name == name {
+			return c.Context, true
+		}
+	}
+
+	return Context{}, false
+}
+
+func (config *Config) cluster(name string) (Cluster, bool) {
+	for _, c := range config.Clusters {
+		if c.Name == name {
+			return c.Cluster, true
+		}
+	}
+
+	return Cluster{}, false
+}
+
+func (config *Config) user(name string) (User, bool) {
+	for _, u := range config.Users {
+		if u.Name == name {
+			return u.User, true
+		}
+	}
+
+	return User{}, false
+}
+
+func (config *Config) data(data []byte, path string) ([]byte, error) {
+	if len(data) > 0 || path == "" {
+		return data, nil
+	}
+
+	return os.ReadFile(config.path(path))
+}
+
+func (config *Config) path(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(config.dir, path)
+}
+
+// execPlugin runs a credential plugin, caching its credential until it
+// expires.
+type execPlugin struct {
+	config ExecConfig
+	dir    string
+
+	cred *execCredentialStatus
+	mu   sync.Mutex
+}
+
+// execCredential is the output of a credential plugin.
+type execCredential struct {
+	APIVersion string                `json:"apiVersion"`
+	Kind       string                `json:"kind"`
+	Status     *execCredentialStatus `json:"status"`
+}
+
+type execCredentialStatus struct {
+	Token                 string     `json:"token"`
+	ClientCertificateData string     `json:"clientCertificateData"`
+	ClientKeyData         string     `json:"clientKeyData"`
+	ExpirationTimestamp   *time.Time `json:"expirationTimestamp"`
+}
+
+func (plugin *execPlugin) credential(ctx context.Context) (*execCredentialStatus, error) {
+	plugin.mu.Lock()
+	defer plugin.mu.Unlock()
+
+	if plugin.cred != nil &&
+		(plugin.cred.ExpirationTimestamp == nil || time.Now().Before(*plugin.cred.ExpirationTimestamp)) {
+		return plugin.cred, nil
+	}
+
+	info, err := json.Marshal(execCredential{
+		APIVersion: plugin.config.APIVersion,
+		Kind:       "ExecCredential",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	command := plugin.config.Command
+	if strings.Contains(command, string(filepath.Separator)) {
+		// like kubectl, paths with a separator are relative to the kubeconfig
+		command = filepath.Join(plugin.dir, command)
+	}
+
+	cmd := exec.CommandContext(ctx, command, plugin.config.Args...)
+	cmd.Env = append(os.Environ(), "KUBERNETES_EXEC_INFO="+string(info))
+	for _, env := range plugin.config.Env {
+		cmd.Env = append(cmd.Env, env.Name+"="+env.Value)
+	}
+
+	stdout := new(bytes.Buffer)
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("exec credential plugin %s: %w", plugin.config.Command, err)
+	}
+
+	var cred execCredential
+	if err := json.Unmarshal(stdout.Bytes(), &cred); err != nil {
+		return nil, fmt.Errorf("exec credential plugin %s: decode: %w", plugin.config.Command, err)
+	}
+
+	if cred.Status == nil {
+		return nil, fmt.Errorf("exec credential plugin %s: no status", plugin.config.Command)
+	}
+
+	plugin.cred = cred.Status
+
+	return plugin.cred, nil
+}
+
+func (plugin *execPlugin) clientCertificate(info *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	cred, err := plugin.credential(info.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	if cred.ClientCertificateData == "" {
+		// no certificate; authenticate with the token instead
+		return &tls.Certificate{}, nil
+	}
+
+	pair, err := tls.X509KeyPair([]byte(cred.ClientCertificateData), []byte(cred.ClientKeyData))
+	if err != nil {
+		return nil, fmt.Errorf("exec credential plugin %s: client certificate: %w", plugin.config.Command, err)
+	}
+
+	return &pair, nil
+}
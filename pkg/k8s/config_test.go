@@ -0,0 +1,111 @@
+package k8s_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vito/bass/pkg/k8s"
+	"github.com/vito/is"
+)
+
+func TestConfigUnknownUser(t *testing.T) {
+	is := is.New(t)
+
+	config, err := k8s.ParseConfig([]byte(`
+current-context: test
+clusters:
+- name: test
+  cluster:
+    server: https://example.com
+users:
+- name: someone-else
+  user:
+    token: s3cr3t
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+`), t.TempDir())
+	is.NoErr(err)
+
+	_, err = config.Client(&http.Client{Transport: &http.Transport{}}, "", "")
+	is.True(err != nil)
+	is.True(strings.Contains(err.Error(), `unknown user: "test"`))
+}
+
+func TestConfigExecPlugin(t *testing.T) {
+	is := is.New(t)
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer from-plugin" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprintln(w, `{"message":"bad token"}`)
+			return
+		}
+
+		fmt.Fprintln(w, `{"items":[]}`)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+
+	// counts how many times the plugin runs, since the credential should be
+	// reused until it expires
+	calls := filepath.Join(dir, "calls")
+
+	plugin := filepath.Join(dir, "plugin")
+	is.NoErr(os.WriteFile(plugin, []byte(`#!/bin/sh
+echo called >> `+calls+`
+test -n "$KUBERNETES_EXEC_INFO" || exit 1
+echo '{"apiVersion":"client.authentication.k8s.io/v1","kind":"ExecCredential","status":{"token":"'$TOKEN'"}}'
+`), 0755))
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+
+	config, err := k8s.ParseConfig([]byte(`
+current-context: test
+clusters:
+- name: test
+  cluster:
+    server: `+srv.URL+`
+    certificate-authority-data: `+base64.StdEncoding.EncodeToString(ca)+`
+users:
+- name: test
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1
+      command: ./plugin
+      env:
+      - name: TOKEN
+        value: from-plugin
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+`), dir)
+	is.NoErr(err)
+
+	client, err := config.Client(&http.Client{Transport: &http.Transport{}}, "", "")
+	is.NoErr(err)
+
+	logs, err := client.Logs(context.Background(), "app=web", k8s.LogOpts{})
+	is.NoErr(err)
+	is.Equal(len(logs), 0)
+
+	_, err = client.Logs(context.Background(), "app=web", k8s.LogOpts{})
+	is.NoErr(err)
+
+	called, err := os.ReadFile(calls)
+	is.NoErr(err)
+	is.Equal(string(called), "called\n")
+}
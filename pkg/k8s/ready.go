@@ -0,0 +1,83 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// workload contains the fields used to determine whether a resource is
+// ready.
+type workload struct {
+	Metadata struct {
+		Generation int64 `json:"generation"`
+	} `json:"metadata"`
+
+	Spec struct {
+		Replicas    *int32 `json:"replicas"`
+		Completions *int32 `json:"completions"`
+	} `json:"spec"`
+
+	Status struct {
+		ObservedGeneration int64 `json:"observedGeneration"`
+
+		UpdatedReplicas   int32 `json:"updatedReplicas"`
+		ReadyReplicas     int32 `json:"readyReplicas"`
+		AvailableReplicas int32 `json:"availableReplicas"`
+
+		DesiredNumberScheduled int32 `json:"desiredNumberScheduled"`
+		UpdatedNumberScheduled int32 `json:"updatedNumberScheduled"`
+		NumberAvailable        int32 `json:"numberAvailable"`
+
+		Succeeded int32 `json:"succeeded"`
+
+		Conditions []struct {
+			Type    string `json:"type"`
+			Status  string `json:"status"`
+			Message string `json:"message"`
+		} `json:"conditions"`
+	} `json:"status"`
+}
+
+func isReady(kind string, resource []byte) (bool, error) {
+	var w workload
+	if err := json.Unmarshal(resource, &w); err != nil {
+		return false, err
+	}
+
+	replicas := int32(1)
+	if w.Spec.Replicas != nil {
+		replicas = *w.Spec.Replicas
+	}
+
+	observed := w.Status.ObservedGeneration >= w.Metadata.Generation
+
+	switch kind {
+	case "Deployment":
+		return observed &&
+			w.Status.UpdatedReplicas == replicas &&
+			w.Status.AvailableReplicas == replicas, nil
+	case "StatefulSet":
+		return observed &&
+			w.Status.UpdatedReplicas == replicas &&
+			w.Status.ReadyReplicas == replicas, nil
+	case "DaemonSet":
+		return observed &&
+			w.Status.UpdatedNumberScheduled == w.Status.DesiredNumberScheduled &&
+			w.Status.NumberAvailable == w.Status.DesiredNumberScheduled, nil
+	case "Job":
+		for _, cond := range w.Status.Conditions {
+			if cond.Type == "Failed" && cond.Status == "True" {
+				return false, fmt.Errorf("job failed: %s", cond.Message)
+			}
+		}
+
+		completions := int32(1)
+		if w.Spec.Completions != nil {
+			completions = *w.Spec.Completions
+		}
+
+		return w.Status.Succeeded >= completions, nil
+	default:
+		return true, nil
+	}
+}
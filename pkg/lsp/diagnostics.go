@@ -0,0 +1,88 @@
+package lsp
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+
+	"github.com/vito/bass/pkg/bass"
+)
+
+// publishDiagnostics reports the diagnostics for the document, replacing any
+// previously reported.
+func (h *langHandler) publishDiagnostics(uri DocumentURI, version int, diagnostics []Diagnostic) {
+	if h.conn == nil {
+		return
+	}
+
+	if diagnostics == nil {
+		diagnostics = []Diagnostic{}
+	}
+
+	h.conn.Notify(
+		context.Background(),
+		"textDocument/publishDiagnostics",
+		&PublishDiagnosticsParams{
+			URI:         uri,
+			Version:     version,
+			Diagnostics: diagnostics,
+		})
+}
+
+// errorDiagnostics converts an error from evaluating the file at the given
+// path into diagnostics, locating it using the call trace.
+//
+// Frames from files which are mapped back to the path, e.g. scripts
+// extracted from a bundle, are located in the original file.
+func errorDiagnostics(path string, trace *bass.Trace, err error) []Diagnostic {
+	diag := Diagnostic{
+		Severity: 1, // error
+		Message:  err.Error(),
+	}
+
+	var readErr bass.ReadError
+	if errors.As(err, &readErr) && isFile(readErr.Range.File, path) {
+		diag.Range = lspRange(readErr.Range)
+		return []Diagnostic{diag}
+	}
+
+	frames := trace.Frames()
+	for i := len(frames) - 1; i >= 0; i-- {
+		if isFile(frames[i].Range.File, path) {
+			diag.Range = lspRange(frames[i].Range)
+			return []Diagnostic{diag}
+		}
+	}
+
+	// not located in the file; report it at the top
+	return []Diagnostic{diag}
+}
+
+func isFile(file bass.Readable, path string) bool {
+	if file == nil {
+		return false
+	}
+
+	if origin, found := bass.SourceMaps.Origin(file); found {
+		return filepath.Clean(origin) == filepath.Clean(path)
+	}
+
+	var host bass.HostPath
+	if err := file.Decode(&host); err != nil {
+		return false
+	}
+
+	return host.FromSlash() == filepath.Clean(path)
+}
+
+func lspRange(loc bass.Range) Range {
+	if loc.End.Ln < loc.Start.Ln {
+		// read errors may not know where they end
+		loc.End = loc.Start
+	}
+
+	return Range{
+		Start: Position{Line: loc.Start.Ln - 1, Character: loc.Start.Col},
+		End:   Position{Line: loc.End.Ln - 1, Character: loc.End.Col},
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 	"unicode"
 	"unicode/utf16"
@@ -151,10 +152,14 @@ func (h *langHandler) definition(ctx context.Context, uri DocumentURI, params *D
 		return nil, nil
 	}
 
-	defFile, err := loc.File.CachePath(ctx, bass.CacheHome)
-	if err != nil {
-		logger.Error("failed to unembed definition", zap.Error(err))
-		return nil, err
+	// prefer the original file of bundled code when it is still around
+	defFile, found := bass.SourceMaps.Origin(loc.File)
+	if _, statErr := os.Stat(defFile); !found || statErr != nil {
+		defFile, err = loc.File.CachePath(ctx, bass.CacheHome)
+		if err != nil {
+			logger.Error("failed to unembed definition", zap.Error(err))
+			return nil, err
+		}
 	}
 
 	return []Location{
@@ -230,14 +230,23 @@ func (h *langHandler) updateFile(ctx context.Context, uri DocumentURI, text stri
 				break
 			}
 
+			h.publishDiagnostics(uri, f.Version, errorDiagnostics(fp, &bass.Trace{}, err))
 			return fmt.Errorf("read next: %w", err)
 		}
 	}
 
+	trace := &bass.Trace{}
+	ctx = bass.WithTrace(ctx, trace)
+
 	_, err = bass.EvalString(ctx, scope, text, source)
 	if err != nil {
+		// collect diagnostics before writing the error, which resets the trace
+		diagnostics := errorDiagnostics(fp, trace, err)
 		cli.WriteError(ctx, err)
 		logger.Error("eval failed (this is fine)")
+		h.publishDiagnostics(uri, f.Version, diagnostics)
+	} else {
+		h.publishDiagnostics(uri, f.Version, nil)
 	}
 
 	logger.Info("initialized scope")
@@ -27,6 +27,7 @@ type RuntimeClient interface {
 	Read(ctx context.Context, in *Thunk, opts ...grpc.CallOption) (Runtime_ReadClient, error)
 	Export(ctx context.Context, in *Thunk, opts ...grpc.CallOption) (Runtime_ExportClient, error)
 	ExportPath(ctx context.Context, in *ThunkPath, opts ...grpc.CallOption) (Runtime_ExportPathClient, error)
+	ImageConfig(ctx context.Context, in *ImageRef, opts ...grpc.CallOption) (*Bytes, error)
 }
 
 type runtimeClient struct {
@@ -174,6 +175,15 @@ func (x *runtimeExportPathClient) Recv() (*Bytes, error) {
 	return m, nil
 }
 
+func (c *runtimeClient) ImageConfig(ctx context.Context, in *ImageRef, opts ...grpc.CallOption) (*Bytes, error) {
+	out := new(Bytes)
+	err := c.cc.Invoke(ctx, "/bass.Runtime/ImageConfig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RuntimeServer is the server API for Runtime service.
 // All implementations must embed UnimplementedRuntimeServer
 // for forward compatibility
@@ -183,6 +193,7 @@ type RuntimeServer interface {
 	Read(*Thunk, Runtime_ReadServer) error
 	Export(*Thunk, Runtime_ExportServer) error
 	ExportPath(*ThunkPath, Runtime_ExportPathServer) error
+	ImageConfig(context.Context, *ImageRef) (*Bytes, error)
 	mustEmbedUnimplementedRuntimeServer()
 }
 
@@ -205,6 +216,9 @@ func (UnimplementedRuntimeServer) Export(*Thunk, Runtime_ExportServer) error {
 func (UnimplementedRuntimeServer) ExportPath(*ThunkPath, Runtime_ExportPathServer) error {
 	return status.Errorf(codes.Unimplemented, "method ExportPath not implemented")
 }
+func (UnimplementedRuntimeServer) ImageConfig(context.Context, *ImageRef) (*Bytes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImageConfig not implemented")
+}
 func (UnimplementedRuntimeServer) mustEmbedUnimplementedRuntimeServer() {}
 
 // UnsafeRuntimeServer may be embedded to opt out of forward compatibility for this service.
@@ -320,6 +334,24 @@ func (x *runtimeExportPathServer) Send(m *Bytes) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Runtime_ImageConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImageRef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RuntimeServer).ImageConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/bass.Runtime/ImageConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RuntimeServer).ImageConfig(ctx, req.(*ImageRef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Runtime_ServiceDesc is the grpc.ServiceDesc for Runtime service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -331,6 +363,10 @@ var Runtime_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Resolve",
 			Handler:    _Runtime_Resolve_Handler,
 		},
+		{
+			MethodName: "ImageConfig",
+			Handler:    _Runtime_ImageConfig_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
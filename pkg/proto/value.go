@@ -10,6 +10,10 @@ func NewValue(msg Message) (*Value, error) {
 		val.Value = &Value_Bool{x}
 	case *Int:
 		val.Value = &Value_Int{x}
+	case *Float:
+		val.Value = &Value_Float{x}
+	case *BigInt:
+		val.Value = &Value_BigInt{x}
 	case *String:
 		val.Value = &Value_String_{x}
 	case *Secret:
@@ -1,21 +1,25 @@
 package runtimes
 
 import (
+	"bytes"
 	"context"
 	"embed"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/fs"
+	"net"
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"text/tabwriter"
 	"time"
 
 	"github.com/adrg/xdg"
+	"github.com/containerd/containerd/content"
 	"github.com/containerd/containerd/platforms"
 	dockerconfig "github.com/docker/cli/cli/config"
 	"github.com/docker/distribution/reference"
@@ -31,6 +35,7 @@ import (
 	"github.com/moby/buildkit/solver/pb"
 	"github.com/moby/buildkit/util/entitlements"
 	"github.com/morikuni/aec"
+	digest "github.com/opencontainers/go-digest"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/tonistiigi/units"
 	"github.com/vito/bass/pkg/bass"
@@ -42,6 +47,7 @@ import (
 	"github.com/vito/progrock"
 	"github.com/vito/progrock/graph"
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 
 	_ "embed"
 )
@@ -53,9 +59,15 @@ type BuildkitConfig struct {
 	Addr         string `json:"addr,omitempty"`
 	DisableCache bool   `json:"disable_cache,omitempty"`
 	CertsDir     string `json:"certs_dir,omitempty"`
+
+	// ImageCache configures the runtime to pull images into the local OCI
+	// store shared by all runtimes, and to load them from there.
+	ImageCache bool `json:"image_cache,omitempty"`
 }
 
 var _ bass.Runtime = &Buildkit{}
+var _ bass.InteractiveRuntime = &Buildkit{}
+var _ bass.CacheReuser = &Buildkit{}
 
 //go:embed bin/exe.*
 var shims embed.FS
@@ -65,7 +77,6 @@ const BuildkitName = "buildkit"
 const shimExePath = "/bass/shim"
 const workDir = "/bass/work"
 const ioDir = "/bass/io"
-const inputFile = "/bass/io/in"
 const outputFile = "/bass/io/out"
 const caFile = "/bass/ca.crt"
 
@@ -93,6 +104,30 @@ type Buildkit struct {
 	Client   *kitdclient.Client
 	Platform ocispecs.Platform
 
+	// Emulated is true if the runtime is emulating a platform other than the
+	// workers' native platform.
+	Emulated bool
+
+	// emulationWarning ensures the emulation fallback is only warned about
+	// once per emulated runtime.
+	emulationWarning *sync.Once
+
+	// Images is the shared OCI store consulted before pulling images, if
+	// configured.
+	Images *OCIStore
+
+	// Network configures the proxies and CA certificates used when pulling
+	// images into Images.
+	Network bass.NetworkConfig
+
+	// RunID is the ID of the run which owns the runtime, if any. Uncached
+	// execs, such as services, are labeled with it so that their snapshots can
+	// be pruned by PruneRuns if the run never cleans up.
+	RunID string
+
+	// helper resolves registry credentials, if configured.
+	helper *bass.CredentialHelper
+
 	authp session.Attachable
 }
 
@@ -134,12 +169,35 @@ func NewBuildkit(ctx context.Context, _ bass.RuntimePool, cfg *bass.Scope) (bass
 		checkSame = platforms.Only(platform)
 	}
 
+	var images *OCIStore
+	if config.ImageCache {
+		images, err = OpenOCIStore(DefaultOCIStoreDir())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	network, _ := bass.NetworkConfigFromContext(ctx)
+
+	runID, _ := bass.RunIDFromContext(ctx)
+
+	var helper *bass.CredentialHelper
+	var authp session.Attachable = authprovider.NewDockerAuthProvider(dockerconfig.LoadDefaultConfigFile(os.Stderr))
+	if h, found := bass.CredentialHelperFromContext(ctx); found {
+		helper = &h
+		authp = newAuthProvider(h, network, authp)
+	}
+
 	return &Buildkit{
 		Config:   config,
 		Client:   client,
 		Platform: platform,
+		Images:   images,
+		Network:  network,
+		RunID:    runID,
 
-		authp: authprovider.NewDockerAuthProvider(dockerconfig.LoadDefaultConfigFile(os.Stderr)),
+		helper: helper,
+		authp:  authp,
 	}, nil
 }
 
@@ -181,6 +239,27 @@ func dialBuildkit(ctx context.Context, addr string) (*kitdclient.Client, error)
 }
 
 func (runtime *Buildkit) Resolve(ctx context.Context, imageRef bass.ImageRef) (bass.ImageRef, error) {
+	resolved, _, err := runtime.resolveImageConfig(ctx, imageRef)
+	if err != nil {
+		return bass.ImageRef{}, err
+	}
+
+	imageRef.Digest = resolved.String()
+
+	return imageRef, nil
+}
+
+// ImageConfig fetches the config of the image without pulling its layers.
+func (runtime *Buildkit) ImageConfig(ctx context.Context, imageRef bass.ImageRef) ([]byte, error) {
+	_, config, err := runtime.resolveImageConfig(ctx, imageRef)
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func (runtime *Buildkit) resolveImageConfig(ctx context.Context, imageRef bass.ImageRef) (digest.Digest, []byte, error) {
 	// track dependent services
 	ctx, svcs := bass.TrackRuns(ctx)
 	defer svcs.StopAndWait()
@@ -188,28 +267,29 @@ func (runtime *Buildkit) Resolve(ctx context.Context, imageRef bass.ImageRef) (b
 	ref, err := runtime.ref(ctx, imageRef)
 	if err != nil {
 		// TODO: it might make sense to resolve an OCI archive ref to a digest too
-		return bass.ImageRef{}, fmt.Errorf("resolve ref %v: %w", imageRef, err)
+		return "", nil, fmt.Errorf("resolve ref %v: %w", imageRef, err)
 	}
 
 	// convert 'ubuntu' to 'docker.io/library/ubuntu:latest'
 	normalized, err := reference.ParseNormalizedNamed(ref)
 	if err != nil {
-		return bass.ImageRef{}, fmt.Errorf("normalize ref: %w", err)
+		return "", nil, fmt.Errorf("normalize ref: %w", err)
 	}
 
 	statusProxy := forwardStatus(progrock.RecorderFromContext(ctx))
 	defer statusProxy.Wait()
 
+	var resolved digest.Digest
+	var config []byte
 	doBuild := func(ctx context.Context, gw gwclient.Client) (*gwclient.Result, error) {
-		digest, _, err := gw.ResolveImageConfig(ctx, normalized.String(), llb.ResolveImageConfigOpt{
+		var err error
+		resolved, config, err = gw.ResolveImageConfig(ctx, normalized.String(), llb.ResolveImageConfigOpt{
 			Platform: &runtime.Platform,
 		})
 		if err != nil {
 			return nil, err
 		}
 
-		imageRef.Digest = digest.String()
-
 		return &gwclient.Result{}, nil
 	}
 
@@ -219,10 +299,10 @@ func (runtime *Buildkit) Resolve(ctx context.Context, imageRef bass.ImageRef) (b
 		},
 	}, buildkitProduct, doBuild, statusProxy.Writer())
 	if err != nil {
-		return bass.ImageRef{}, statusProxy.NiceError("resolve failed", err)
+		return "", nil, statusProxy.NiceError("resolve failed", err)
 	}
 
-	return imageRef, nil
+	return resolved, config, nil
 }
 
 func (runtime *Buildkit) Run(ctx context.Context, thunk bass.Thunk) error {
@@ -239,6 +319,10 @@ func (runtime *Buildkit) Run(ctx context.Context, thunk bass.Thunk) error {
 }
 
 func (runtime *Buildkit) Start(ctx context.Context, thunk bass.Thunk) (StartResult, error) {
+	if result, found := startedServiceFromContext(ctx, thunk); found {
+		return result, nil
+	}
+
 	ctx, stop := context.WithCancel(ctx)
 
 	host := thunk.Name()
@@ -247,14 +331,32 @@ func (runtime *Buildkit) Start(ctx context.Context, thunk bass.Thunk) (StartResu
 
 	runs := bass.RunsFromContext(ctx)
 
+	var result StartResult
 	checked := make(chan error, 1)
-	runs.Go(stop, func() error {
-		checked <- health.Check(ctx)
+	runs.Go("health check "+host, stop, func() error {
+		ip, err := health.Check(ctx)
+		if err == nil {
+			result = StartResult{
+				Ports: PortInfos{},
+				IP:    ip,
+			}
+
+			for _, port := range thunk.Ports {
+				result.Ports[port.Name] = bass.Bindings{
+					"host": bass.String(host),
+					"port": bass.Int(port.Port),
+				}.Scope()
+			}
+
+			err = runtime.checkServiceHealth(ctx, thunk, result)
+		}
+
+		checked <- err
 		return nil
 	})
 
 	exited := make(chan error, 1)
-	runs.Go(stop, func() error {
+	runs.Go("service "+host, stop, func() error {
 		exited <- runtime.build(
 			ctx,
 			thunk,
@@ -269,16 +371,10 @@ func (runtime *Buildkit) Start(ctx context.Context, thunk bass.Thunk) (StartResu
 	})
 
 	select {
-	case <-checked:
-		result := StartResult{
-			Ports: PortInfos{},
-		}
-
-		for _, port := range thunk.Ports {
-			result.Ports[port.Name] = bass.Bindings{
-				"host": bass.String(host),
-				"port": bass.Int(port.Port),
-			}.Scope()
+	case err := <-checked:
+		if err != nil {
+			stop() // stop the unhealthy service
+			return StartResult{}, err
 		}
 
 		return result, nil
@@ -369,6 +465,28 @@ func (runtime *Buildkit) ExportPath(ctx context.Context, w io.Writer, tp bass.Th
 	thunk := tp.Thunk
 	path := tp.Path
 
+	if tp.IsStdout() {
+		return runtime.build(
+			ctx,
+			thunk,
+			func(st llb.ExecState, _ string) marshalable {
+				return llb.Scratch().File(
+					llb.Copy(st.GetMount(ioDir), filepath.Base(outputFile), path.FilesystemPath().FromSlash()),
+					llb.WithCustomName("[hide] copy stdout"),
+				)
+			},
+			[]kitdclient.ExportEntry{
+				{
+					Type: kitdclient.ExporterTar,
+					Output: func(map[string]string) (io.WriteCloser, error) {
+						return nopCloser{w}, nil
+					},
+				},
+			},
+			llb.AddEnv("_BASS_OUTPUT", outputFile),
+		)
+	}
+
 	return runtime.build(
 		ctx,
 		thunk,
@@ -436,11 +554,199 @@ func (runtime *Buildkit) Prune(ctx context.Context, opts bass.PruneOpts) error {
 		return err
 	}
 
+	if runtime.Images != nil {
+		freed, err := runtime.Images.Prune(ctx, opts)
+		if err != nil {
+			return fmt.Errorf("prune image cache: %w", err)
+		}
+
+		fmt.Fprintf(tw, "pruned image cache\tsize: %.2f\t%s\n", units.Bytes(freed), aec.LightBlackF.Apply(runtime.Images.Dir))
+
+		total += freed
+	}
+
 	fmt.Fprintf(tw, "total: %.2f\n", units.Bytes(total))
 
 	return tw.Flush()
 }
 
+// ReusedBytes sums the size of the cache records which existed before since
+// and have been used since.
+func (runtime *Buildkit) ReusedBytes(ctx context.Context, since time.Time) (int64, error) {
+	records, err := runtime.Client.DiskUsage(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("disk usage: %w", err)
+	}
+
+	var size int64
+	for _, record := range records {
+		if record.LastUsedAt == nil || record.LastUsedAt.Before(since) {
+			continue
+		}
+
+		if !record.CreatedAt.Before(since) {
+			// created by this run, not reused
+			continue
+		}
+
+		size += record.Size
+	}
+
+	return size, nil
+}
+
+// PruneRuns releases the snapshots of uncached execs labeled with any of the
+// given run IDs.
+func (runtime *Buildkit) PruneRuns(ctx context.Context, runIDs []string) error {
+	for _, id := range runIDs {
+		ch := make(chan kitdclient.UsageInfo)
+		done := make(chan struct{})
+
+		var pruned int
+		var size int64
+		go func() {
+			defer close(done)
+			for du := range ch {
+				pruned++
+				size += du.Size
+			}
+		}()
+
+		err := runtime.Client.Prune(ctx, ch,
+			kitdclient.WithFilter([]string{
+				"description~=" + strconv.Quote(runInputName(id)),
+			}))
+		close(ch)
+		<-done
+		if err != nil {
+			return fmt.Errorf("prune run %s: %w", id, err)
+		}
+
+		if pruned > 0 {
+			zapctx.FromContext(ctx).Info("pruned orphaned run",
+				zap.String("run", id),
+				zap.Int("records", pruned),
+				zap.String("size", fmt.Sprintf("%.2f", units.Bytes(size))))
+		}
+	}
+
+	return nil
+}
+
+// runInputName is the name of the command input file for uncached execs run
+// by the given run.
+func runInputName(runID string) string {
+	return "run-" + runID + ".json"
+}
+
+// Emulate returns a copy of the runtime which targets the given platform,
+// relying on binfmt/QEMU support in the Buildkit workers.
+func (runtime *Buildkit) Emulate(platform bass.Platform) (bass.Runtime, error) {
+	if platform.Arch == "" {
+		return nil, fmt.Errorf("cannot emulate platform without arch: %s", platform)
+	}
+
+	if _, found := allShims["exe."+platform.Arch]; !found {
+		return nil, fmt.Errorf("cannot emulate %s: no shim found for %s", platform, platform.Arch)
+	}
+
+	emu := *runtime
+	emu.Platform = ocispecs.Platform{
+		OS:           platform.OS,
+		Architecture: platform.Arch,
+	}
+	emu.Emulated = true
+	emu.emulationWarning = new(sync.Once)
+	return &emu, nil
+}
+
+// Prefetch pulls the planned images and syncs the planned host paths into
+// the buildkit cache, so that thunks using them can start right away.
+func (runtime *Buildkit) Prefetch(ctx context.Context, plan bass.PrefetchPlan) error {
+	// track dependent services
+	ctx, svcs := bass.TrackRuns(ctx)
+	defer svcs.StopAndWait()
+
+	statusProxy := forwardStatus(progrock.RecorderFromContext(ctx))
+	defer statusProxy.Wait()
+
+	localDirs := map[string]string{}
+	for _, hp := range plan.HostPaths {
+		localDirs[hp.ContextDir] = hp.ContextDir
+	}
+
+	doBuild := func(ctx context.Context, gw gwclient.Client) (*gwclient.Result, error) {
+		b := runtime.newBuilder(ctx, gw)
+
+		var states []llb.State
+		for _, imageRef := range plan.Images {
+			ref, err := runtime.ref(ctx, imageRef)
+			if err != nil {
+				return nil, err
+			}
+
+			if runtime.storeImage(imageRef) {
+				if _, err := runtime.Images.Pull(runtime.pullContext(ctx), ref, runtime.Platform); err != nil {
+					return nil, err
+				}
+
+				continue
+			}
+
+			states = append(states, llb.Image(
+				ref,
+				llb.WithMetaResolver(gw),
+				llb.Platform(runtime.Platform),
+			))
+		}
+
+		for _, hp := range plan.HostPaths {
+			st, _, err := b.hostPath(hp)
+			if err != nil {
+				return nil, err
+			}
+
+			states = append(states, st)
+		}
+
+		eg, ctx := errgroup.WithContext(ctx)
+		for _, st := range states {
+			st := st
+			eg.Go(func() error {
+				def, err := st.Marshal(ctx)
+				if err != nil {
+					return err
+				}
+
+				_, err = gw.Solve(ctx, gwclient.SolveRequest{
+					Definition: def.ToPB(),
+					Evaluate:   true,
+				})
+				return err
+			})
+		}
+
+		if err := eg.Wait(); err != nil {
+			return nil, err
+		}
+
+		return &gwclient.Result{}, nil
+	}
+
+	_, err := runtime.Client.Build(ctx, kitdclient.SolveOpt{
+		LocalDirs: localDirs,
+		OCIStores: runtime.ociStores(),
+		Session: []session.Attachable{
+			runtime.authp,
+		},
+	}, buildkitProduct, doBuild, statusProxy.Writer())
+	if err != nil {
+		return statusProxy.NiceError("prefetch failed", err)
+	}
+
+	return nil
+}
+
 func (runtime *Buildkit) Close() error {
 	return runtime.Client.Close()
 }
@@ -452,17 +758,94 @@ func (runtime *Buildkit) build(
 	exports []kitdclient.ExportEntry,
 	runOpts ...llb.RunOption,
 ) error {
-	var def *llb.Definition
-	var secrets map[string][]byte
-	var localDirs map[string]string
-	var allowed []entitlements.Entitlement
+	if runtime.Emulated {
+		runtime.emulationWarning.Do(func() {
+			zapctx.FromContext(ctx).Warn("no runtime for platform; falling back to emulation",
+				zap.String("platform", platforms.Format(runtime.Platform)))
+		})
+	}
 
 	statusProxy := forwardStatus(progrock.RecorderFromContext(ctx))
 	defer statusProxy.Wait()
 
-	// build llb definition using the remote gateway for image resolution
+	compiled, err := runtime.compile(ctx, statusProxy, thunk, transform, runOpts...)
+	if err != nil {
+		return err
+	}
+
+	// the trace file is read from the io dir, so it can only be collected
+	// when the io dir is exported locally or not exported at all
+	trace, tracing := bass.AccessTraceFromContext(ctx)
+	var traceDir string
+	if tracing && len(compiled.traced) > 0 {
+		for _, export := range exports {
+			if export.Type == kitdclient.ExporterLocal {
+				traceDir = export.OutputDir
+			}
+		}
+
+		if len(exports) == 0 && len(thunk.Ports) == 0 {
+			traceDir, err = os.MkdirTemp("", "bass-trace")
+			if err != nil {
+				return err
+			}
+
+			defer os.RemoveAll(traceDir)
+
+			exports = []kitdclient.ExportEntry{
+				{
+					Type:      kitdclient.ExporterLocal,
+					OutputDir: traceDir,
+				},
+			}
+		}
+	}
+
+	_, err = runtime.Client.Solve(ctx, compiled.def, compiled.solveOpt(runtime, exports), statusProxy.Writer())
+	if err != nil {
+		return statusProxy.NiceError("build failed", err)
+	}
+
+	if tracing && len(compiled.traced) > 0 {
+		var payload []byte
+		if traceDir != "" {
+			payload, _ = os.ReadFile(filepath.Join(traceDir, filepath.Base(traceFile)))
+		}
+
+		recordTrace(trace, compiled.traced, payload)
+	}
+
+	bass.RecordThunk(ctx, thunk)
+
+	return nil
+}
+
+// compiledThunk is the LLB definition of a thunk along with everything
+// needed to solve it.
+type compiledThunk struct {
+	def       *llb.Definition
+	secrets   map[string][]byte
+	localDirs map[string]string
+	allowed   []entitlements.Entitlement
+
+	// traced is the host path of each of the thunk's traced mounts by target.
+	traced map[string]bass.HostPath
+}
+
+// compile builds the LLB definition for the thunk, using the remote gateway
+// for image resolution.
+func (runtime *Buildkit) compile(
+	ctx context.Context,
+	statusProxy *statusProxy,
+	thunk bass.Thunk,
+	transform func(llb.ExecState, string) marshalable,
+	runOpts ...llb.RunOption,
+) (compiledThunk, error) {
+	var compiled compiledThunk
+
 	_, err := runtime.Client.Build(ctx, kitdclient.SolveOpt{
-		Session: []session.Attachable{runtime.authp},
+		OCIStores: runtime.ociStores(),
+		Session:   []session.Attachable{runtime.authp},
 	}, buildkitProduct, func(ctx context.Context, gw gwclient.Client) (*gwclient.Result, error) {
 		b := runtime.newBuilder(ctx, gw)
 
@@ -472,13 +855,32 @@ func (runtime *Buildkit) build(
 		}
 
 		if needsInsecure {
-			allowed = append(allowed, entitlements.EntitlementSecurityInsecure)
+			compiled.allowed = append(compiled.allowed, entitlements.EntitlementSecurityInsecure)
 		}
 
-		localDirs = b.localDirs
-		secrets = b.secrets
+		compiled.localDirs = b.localDirs
+		compiled.secrets = b.secrets
 
-		def, err = transform(st, sp).Marshal(ctx)
+		id, err := thunk.Hash()
+		if err != nil {
+			return nil, err
+		}
+
+		for traceID, traced := range b.traced {
+			if traceID == id {
+				compiled.traced = traced
+				continue
+			}
+
+			// the reads of thunks that this thunk depends on aren't traced
+			if trace, ok := bass.AccessTraceFromContext(ctx); ok {
+				for _, hp := range traced {
+					trace.Incomplete(hp.ContextDir)
+				}
+			}
+		}
+
+		compiled.def, err = transform(st, sp).Marshal(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -486,25 +888,201 @@ func (runtime *Buildkit) build(
 		return &gwclient.Result{}, nil
 	}, statusProxy.Writer())
 	if err != nil {
-		return statusProxy.NiceError("llb build failed", err)
+		return compiledThunk{}, statusProxy.NiceError("llb build failed", err)
 	}
 
-	_, err = runtime.Client.Solve(ctx, def, kitdclient.SolveOpt{
-		LocalDirs:           localDirs,
-		AllowedEntitlements: allowed,
+	return compiled, nil
+}
+
+func (compiled compiledThunk) solveOpt(runtime *Buildkit, exports []kitdclient.ExportEntry) kitdclient.SolveOpt {
+	return kitdclient.SolveOpt{
+		LocalDirs:           compiled.localDirs,
+		OCIStores:           runtime.ociStores(),
+		AllowedEntitlements: compiled.allowed,
 		Session: []session.Attachable{
 			runtime.authp,
-			secretsprovider.FromMap(secrets),
+			secretsprovider.FromMap(compiled.secrets),
 		},
 		Exports: exports,
+	}
+}
+
+// Interact runs the thunk's command in a container attached to the given
+// stdio. The command is run directly rather than through the shim, and its
+// result is not cached.
+func (runtime *Buildkit) Interact(ctx context.Context, thunk bass.Thunk, tio bass.InteractiveIO) error {
+	ctx, svcs := bass.TrackRuns(ctx)
+	defer svcs.StopAndWait()
+
+	cmd, err := NewCommand(ctx, runtime, thunk)
+	if err != nil {
+		return err
+	}
+
+	cwd := workDir
+	if cmd.Dir != nil {
+		if path.IsAbs(*cmd.Dir) {
+			cwd = *cmd.Dir
+		} else {
+			cwd = path.Join(workDir, *cmd.Dir)
+		}
+	}
+
+	statusProxy := forwardStatus(progrock.RecorderFromContext(ctx))
+	defer statusProxy.Wait()
+
+	compiled, err := runtime.compile(ctx, statusProxy, thunk, func(st llb.ExecState, _ string) marshalable {
+		return st.Root()
+	}, llb.IgnoreCache)
+	if err != nil {
+		return err
+	}
+
+	_, err = runtime.Client.Build(ctx, compiled.solveOpt(runtime, nil), buildkitProduct, func(ctx context.Context, gw gwclient.Client) (*gwclient.Result, error) {
+		exec, mounts, err := solveExecMounts(ctx, gw, compiled.def)
+		if err != nil {
+			return nil, err
+		}
+
+		ctr, err := gw.NewContainer(ctx, gwclient.NewContainerRequest{
+			Mounts:     mounts,
+			NetMode:    exec.Network,
+			ExtraHosts: exec.Meta.ExtraHosts,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		defer ctr.Release(ctx)
+
+		req := gwclient.StartRequest{
+			Args:         cmd.Args,
+			Env:          append(exec.Meta.Env, cmd.Env...),
+			User:         exec.Meta.User,
+			Cwd:          cwd,
+			Tty:          tio.TTY,
+			SecurityMode: exec.Security,
+		}
+
+		if tio.Stdin != nil {
+			req.Stdin = io.NopCloser(tio.Stdin)
+		}
+
+		if tio.Stdout != nil {
+			req.Stdout = nopCloser{tio.Stdout}
+		}
+
+		if tio.Stderr != nil && !tio.TTY {
+			req.Stderr = nopCloser{tio.Stderr}
+		}
+
+		proc, err := ctr.Start(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		if tio.Resize != nil {
+			go func() {
+				for size := range tio.Resize {
+					_ = proc.Resize(ctx, gwclient.WinSize{
+						Rows: uint32(size.Rows),
+						Cols: uint32(size.Cols),
+					})
+				}
+			}()
+		}
+
+		return &gwclient.Result{}, proc.Wait()
 	}, statusProxy.Writer())
 	if err != nil {
-		return statusProxy.NiceError("build failed", err)
+		return statusProxy.NiceError("interactive run failed", err)
 	}
 
 	return nil
 }
 
+// solveExecMounts finds the exec op which the definition's output belongs to
+// and solves each of its inputs, returning the mounts to use for running its
+// command in a container.
+func solveExecMounts(ctx context.Context, gw gwclient.Client, def *llb.Definition) (*pb.ExecOp, []gwclient.Mount, error) {
+	if len(def.Def) == 0 {
+		return nil, nil, fmt.Errorf("empty definition")
+	}
+
+	var term pb.Op
+	if err := term.Unmarshal(def.Def[len(def.Def)-1]); err != nil {
+		return nil, nil, fmt.Errorf("unmarshal terminal op: %w", err)
+	}
+
+	if len(term.Inputs) != 1 {
+		return nil, nil, fmt.Errorf("terminal op has %d inputs", len(term.Inputs))
+	}
+
+	var op pb.Op
+	var exec *pb.ExecOp
+	for _, dt := range def.Def[:len(def.Def)-1] {
+		if digest.FromBytes(dt) != term.Inputs[0].Digest {
+			continue
+		}
+
+		if err := op.Unmarshal(dt); err != nil {
+			return nil, nil, fmt.Errorf("unmarshal op: %w", err)
+		}
+
+		exec = op.GetExec()
+	}
+
+	if exec == nil {
+		return nil, nil, fmt.Errorf("definition does not refer to an exec op")
+	}
+
+	mounts := make([]gwclient.Mount, 0, len(exec.Mounts))
+	for _, m := range exec.Mounts {
+		mount := gwclient.Mount{
+			Selector:  m.Selector,
+			Dest:      m.Dest,
+			Readonly:  m.Readonly,
+			MountType: m.MountType,
+			CacheOpt:  m.CacheOpt,
+			SecretOpt: m.SecretOpt,
+			SSHOpt:    m.SSHOpt,
+		}
+
+		if m.Input != pb.Empty {
+			input := op.Inputs[m.Input]
+
+			// solve the input by pointing a new terminal op at it
+			inputTerm, err := (&pb.Op{Inputs: []*pb.Input{input}}).Marshal()
+			if err != nil {
+				return nil, nil, err
+			}
+
+			ops := make([][]byte, len(def.Def))
+			copy(ops, def.Def[:len(def.Def)-1])
+			ops[len(ops)-1] = inputTerm
+
+			res, err := gw.Solve(ctx, gwclient.SolveRequest{
+				Definition: (&llb.Definition{
+					Def:      ops,
+					Metadata: def.Metadata,
+				}).ToPB(),
+			})
+			if err != nil {
+				return nil, nil, fmt.Errorf("solve mount %s: %w", m.Dest, err)
+			}
+
+			mount.Ref, err = res.SingleRef()
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		mounts = append(mounts, mount)
+	}
+
+	return exec, mounts, nil
+}
+
 func result(ctx context.Context, gw gwclient.Client, st marshalable) (*gwclient.Result, error) {
 	def, err := st.Marshal(ctx)
 	if err != nil {
@@ -516,6 +1094,43 @@ func result(ctx context.Context, gw gwclient.Client, st marshalable) (*gwclient.
 	})
 }
 
+// checkServiceHealth runs the service's health check, if it has one, until
+// it passes or its deadline elapses. The check is never cached, and the addrs
+// in its env resolve to the given instance of the service rather than
+// starting another one.
+func (runtime *Buildkit) checkServiceHealth(ctx context.Context, thunk bass.Thunk, result StartResult) error {
+	health, svc, ok := thunk.ServiceHealth()
+	if !ok {
+		return nil
+	}
+
+	ctx = withStartedService(ctx, svc, result)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(health.Deadline)*time.Second)
+	defer cancel()
+
+	for {
+		err := runtime.build(
+			ctx,
+			health.Check,
+			func(st llb.ExecState, _ string) marshalable {
+				return st.GetMount(ioDir)
+			},
+			nil,             // exports
+			llb.IgnoreCache, // always check the running instance
+		)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("health check did not pass within %ds: %w", health.Deadline, err)
+		case <-time.After(time.Second):
+		}
+	}
+}
+
 type portHealthChecker struct {
 	runtime *Buildkit
 
@@ -532,16 +1147,25 @@ func (runtime *Buildkit) newHealth(host string, ports []bass.ThunkPort) *portHea
 	}
 }
 
-func (d *portHealthChecker) Check(ctx context.Context) error {
+// Check waits for the ports to be ready, returning the IP they were reached
+// at, if reported by the shim.
+func (d *portHealthChecker) Check(ctx context.Context) (net.IP, error) {
+	reached := new(bytes.Buffer)
 	_, err := d.runtime.Client.Build(ctx, kitdclient.SolveOpt{
 		Session: []session.Attachable{
 			d.runtime.authp,
 		},
-	}, buildkitProduct, d.doBuild, nil)
-	return err
+	}, buildkitProduct, func(ctx context.Context, gw gwclient.Client) (*gwclient.Result, error) {
+		return d.doBuild(ctx, gw, reached)
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return net.ParseIP(strings.TrimSpace(reached.String())), nil
 }
 
-func (d *portHealthChecker) doBuild(ctx context.Context, gw gwclient.Client) (*gwclient.Result, error) {
+func (d *portHealthChecker) doBuild(ctx context.Context, gw gwclient.Client, reached io.Writer) (*gwclient.Result, error) {
 	shimExe, err := d.runtime.shim()
 	if err != nil {
 		return nil, err
@@ -585,6 +1209,7 @@ func (d *portHealthChecker) doBuild(ctx context.Context, gw gwclient.Client) (*g
 
 	proc, err := container.Start(ctx, gwclient.StartRequest{
 		Args:   args,
+		Stdout: nopCloser{reached},
 		Stderr: nopCloser{ioctx.StderrFromContext(ctx)},
 	})
 	if err != nil {
@@ -605,6 +1230,10 @@ type builder struct {
 
 	secrets   map[string][]byte
 	localDirs map[string]string
+
+	// traced maps the hash of each thunk whose reads are traced to the host
+	// path of each traced mount by target.
+	traced map[string]map[string]bass.HostPath
 }
 
 func (runtime *Buildkit) newBuilder(ctx context.Context, resolver llb.ImageMetaResolver) *builder {
@@ -614,6 +1243,7 @@ func (runtime *Buildkit) newBuilder(ctx context.Context, resolver llb.ImageMetaR
 
 		secrets:   map[string][]byte{},
 		localDirs: map[string]string{},
+		traced:    map[string]map[string]bass.HostPath{},
 	}
 }
 
@@ -633,11 +1263,30 @@ func (b *builder) llb(ctx context.Context, thunk bass.Thunk, extraOpts ...llb.Ru
 		return llb.ExecState{}, "", false, err
 	}
 
+	check, checking := bass.MountCheckFromContext(ctx)
+	_, tracing := bass.AccessTraceFromContext(ctx)
+	if checking || tracing {
+		var traced map[string]bass.HostPath
+		cmd.MountCheck, traced = mountCheck(cmd, check, checking, tracing)
+		if len(traced) > 0 {
+			b.traced[id] = traced
+		}
+	}
+
 	cmdPayload, err := bass.MarshalJSON(cmd)
 	if err != nil {
 		return llb.ExecState{}, "", false, err
 	}
 
+	ignoreCache := len(thunk.Ports) > 0 || b.runtime.Config.DisableCache
+
+	// label uncached execs with the run ID via the input file, which shows up
+	// in the description of their snapshots
+	inputName := "in"
+	if ignoreCache && b.runtime.RunID != "" {
+		inputName = runInputName(b.runtime.RunID)
+	}
+
 	shimExe, err := b.runtime.shim()
 	if err != nil {
 		return llb.ExecState{}, "", false, err
@@ -648,14 +1297,21 @@ func (b *builder) llb(ctx context.Context, thunk bass.Thunk, extraOpts ...llb.Ru
 		return llb.ExecState{}, "", false, err
 	}
 
+	// mark emulated runs in their name rather than a label, since a label
+	// would change the thunk's hash and hostname
+	name := thunk.Cmdline()
+	if b.runtime.Emulated {
+		name += fmt.Sprintf(" [emulated %s]", platforms.Format(b.runtime.Platform))
+	}
+
 	runOpt := []llb.RunOption{
-		llb.WithCustomName(thunk.Cmdline()),
+		llb.WithCustomName(name),
 		// NB: this is load-bearing; it's what busts the cache with different labels
 		llb.Hostname(id),
 		llb.AddMount("/tmp", llb.Scratch(), llb.Tmpfs()),
 		llb.AddMount("/dev/shm", llb.Scratch(), llb.Tmpfs()),
 		llb.AddMount(ioDir, llb.Scratch().File(
-			llb.Mkfile("in", 0600, cmdPayload),
+			llb.Mkfile(inputName, 0600, cmdPayload),
 			llb.WithCustomName("[hide] mount command json"),
 		)),
 		llb.AddMount(shimExePath, shimExe, llb.SourcePath("run")),
@@ -664,9 +1320,29 @@ func (b *builder) llb(ctx context.Context, thunk bass.Thunk, extraOpts ...llb.Ru
 			llb.WithCustomName("[hide] mount bass ca"),
 		), llb.SourcePath("ca.crt")),
 		llb.With(llb.Dir(workDir)),
-		llb.Args([]string{shimExePath, "run", inputFile}),
+		llb.Args([]string{shimExePath, "run", path.Join(ioDir, inputName)}),
 	}
 
+	// pass the mount check as a secret env var so that it stays out of the
+	// cache key. The secret is always requested, so that the exec is the same
+	// whether or not mounts are being checked.
+	mountCheckID := "mount-check-" + id
+	if cmd.MountCheck != nil {
+		payload, err := json.Marshal(cmd.MountCheck)
+		if err != nil {
+			return llb.ExecState{}, "", false, err
+		}
+
+		b.secrets[mountCheckID] = payload
+	}
+
+	runOpt = append(runOpt, llb.AddSecret(
+		mountCheckEnv,
+		llb.SecretID(mountCheckID),
+		llb.SecretAsEnv(true),
+		llb.SecretOptional,
+	))
+
 	if thunk.TLS != nil {
 		crt, key, err := basstls.Generate(b.runtime.Config.CertsDir, id)
 		if err != nil {
@@ -751,7 +1427,13 @@ func (b *builder) llb(ctx context.Context, thunk bass.Thunk, extraOpts ...llb.Ru
 		}
 	}
 
-	if len(thunk.Ports) > 0 || b.runtime.Config.DisableCache {
+	for _, host := range cmd.Hosts {
+		// NB: the IP is excluded from the cache key, so this doesn't bust the
+		// cache when a service restarts with a different IP
+		runOpt = append(runOpt, llb.AddExtraHost(host.Host, host.Target))
+	}
+
+	if ignoreCache {
 		runOpt = append(runOpt, llb.IgnoreCache)
 	}
 
@@ -760,6 +1442,90 @@ func (b *builder) llb(ctx context.Context, thunk bass.Thunk, extraOpts ...llb.Ru
 	return imageRef.Run(runOpt...), sourcePath, needsInsecure, nil
 }
 
+// traceFile is where the shim writes the reads it traced, within the io dir.
+const traceFile = "/bass/io/trace.json"
+
+// mountCheckEnv is the env var through which the shim is given the mount
+// check.
+const mountCheckEnv = "_BASS_MOUNT_CHECK"
+
+// mountCheck configures the shim to check the command's mounts for unused
+// ones, excluding its stdin file which is always read, and to trace reads
+// from its host path mounts.
+//
+// It returns the host path of each traced mount by target.
+func mountCheck(cmd Command, check bass.MountCheck, checking, tracing bool) (*MountCheck, map[string]bass.HostPath) {
+	mc := &MountCheck{
+		Error: check == bass.MountCheckError,
+	}
+
+	traced := map[string]bass.HostPath{}
+	for _, mount := range cmd.Mounts {
+		if cmd.StdinFile != nil && mount.Target == *cmd.StdinFile {
+			continue
+		}
+
+		target := mount.Target
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(workDir, target)
+		}
+
+		if checking {
+			mc.Targets = append(mc.Targets, target)
+		}
+
+		// only host paths are traced, since those are what .bassignore applies
+		// to
+		if tracing && mount.Source.HostPath != nil {
+			mc.Trace = append(mc.Trace, target)
+			traced[target] = *mount.Source.HostPath
+		}
+	}
+
+	if len(mc.Trace) > 0 {
+		mc.TraceFile = traceFile
+	}
+
+	if len(mc.Targets) == 0 && len(mc.Trace) == 0 {
+		return nil, nil
+	}
+
+	return mc, traced
+}
+
+// recordTrace records the reads from each traced host path mount in the
+// trace file written by the shim, if any.
+//
+// Paths are recorded relative to each mount's context dir, since that's
+// where the .bassignore applies. If the thunk's reads weren't traced, e.g.
+// because it was cached before tracing was enabled, its context dirs are
+// marked incomplete instead.
+func recordTrace(trace *bass.AccessTrace, traced map[string]bass.HostPath, payload []byte) {
+	mounts := map[string]map[string]bool{}
+	if payload != nil {
+		if err := json.Unmarshal(payload, &mounts); err != nil {
+			mounts = nil
+		}
+	}
+
+	for target, hp := range traced {
+		files, found := mounts[target]
+		if !found {
+			trace.Incomplete(hp.ContextDir)
+			continue
+		}
+
+		prefix := path.Clean(hp.Path.FilesystemPath().Slash())
+
+		relFiles := map[string]bool{}
+		for rel, read := range files {
+			relFiles[path.Join(prefix, rel)] = read
+		}
+
+		trace.Record(hp.ContextDir, relFiles)
+	}
+}
+
 func (runtime *Buildkit) shim() (llb.State, error) {
 	shimExe, found := allShims["exe."+runtime.Platform.Architecture]
 	if !found {
@@ -800,6 +1566,72 @@ func (r *Buildkit) ref(ctx context.Context, imageRef bass.ImageRef) (string, err
 	return imageRef.Ref()
 }
 
+// storeImage returns whether the image should be pulled into the shared OCI
+// store rather than by Buildkit.
+//
+// Images from a thunk's registry are left to Buildkit, since the registry is
+// only reachable from the Buildkit host.
+func (runtime *Buildkit) storeImage(imageRef bass.ImageRef) bool {
+	return runtime.Images != nil && imageRef.Repository.Addr == nil
+}
+
+// storedImage loads the image from the shared OCI store, pulling it into the
+// store first if needed.
+//
+// Unlike llb.Image, the oci-layout source does not apply the image config, so
+// the env, working directory, and user are applied here.
+func (runtime *Buildkit) storedImage(ctx context.Context, ref string) (llb.State, error) {
+	manifest, err := runtime.Images.Pull(runtime.pullContext(ctx), ref, runtime.Platform)
+	if err != nil {
+		return llb.State{}, err
+	}
+
+	img, err := runtime.Images.Config(ctx, manifest)
+	if err != nil {
+		return llb.State{}, err
+	}
+
+	st := llb.OCILayout(OCIStoreID, manifest.Digest)
+
+	for _, env := range img.Config.Env {
+		name, val, _ := strings.Cut(env, "=")
+		st = st.AddEnv(name, val)
+	}
+
+	if img.Config.WorkingDir != "" {
+		st = st.Dir(img.Config.WorkingDir)
+	}
+
+	if img.Config.User != "" {
+		st = st.User(img.Config.User)
+	}
+
+	return st, nil
+}
+
+// pullContext returns a context for pulling into Images, using the network
+// config and credential helper the runtime was initialized with.
+func (runtime *Buildkit) pullContext(ctx context.Context) context.Context {
+	ctx = bass.WithNetworkConfig(ctx, runtime.Network)
+
+	if runtime.helper != nil {
+		ctx = bass.WithCredentialHelper(ctx, *runtime.helper)
+	}
+
+	return ctx
+}
+
+// ociStores returns the OCI stores to attach to a solve.
+func (runtime *Buildkit) ociStores() map[string]content.Store {
+	if runtime.Images == nil {
+		return nil
+	}
+
+	return map[string]content.Store{
+		OCIStoreID: runtime.Images,
+	}
+}
+
 func (b *builder) image(ctx context.Context, image *bass.ThunkImage) (llb.State, llb.State, string, bool, error) {
 	if image == nil {
 		// TODO: test
@@ -812,6 +1644,15 @@ func (b *builder) image(ctx context.Context, image *bass.ThunkImage) (llb.State,
 			return llb.State{}, llb.State{}, "", false, err
 		}
 
+		if b.runtime.storeImage(*image.Ref) {
+			st, err := b.runtime.storedImage(ctx, ref)
+			if err != nil {
+				return llb.State{}, llb.State{}, "", false, err
+			}
+
+			return st, llb.Scratch(), "", false, nil
+		}
+
 		return llb.Image(
 			ref,
 			llb.WithMetaResolver(b.resolver),
@@ -882,6 +1723,7 @@ func (b *builder) unpackImageArchive(ctx context.Context, thunkPath bass.ThunkPa
 
 	_, err = b.runtime.Client.Build(ctx, kitdclient.SolveOpt{
 		LocalDirs:           b.localDirs,
+		OCIStores:           b.runtime.ociStores(),
 		AllowedEntitlements: allowed,
 		Session: []session.Attachable{
 			b.runtime.authp,
@@ -936,8 +1778,94 @@ func (b *builder) unpackImageArchive(ctx context.Context, thunkPath bass.ThunkPa
 	return image, llb.Scratch(), "", needsInsecure, nil
 }
 
+// hostPath returns a state containing the host path synced from the client,
+// respecting any .bassignore in its context dir.
+func (b *builder) hostPath(hp bass.HostPath) (llb.State, string, error) {
+	contextDir := hp.ContextDir
+	b.localDirs[contextDir] = hp.ContextDir
+
+	var excludes []string
+	ignorePath := filepath.Join(contextDir, ".bassignore")
+	ignore, err := os.Open(ignorePath)
+	if err == nil {
+		excludes, err = dockerignore.ReadAll(ignore)
+		if err != nil {
+			return llb.State{}, "", fmt.Errorf("parse %s: %w", ignorePath, err)
+		}
+	}
+
+	sourcePath := hp.Path.FilesystemPath().FromSlash()
+
+	return llb.Scratch().File(llb.Copy(
+		llb.Local(
+			contextDir,
+			llb.ExcludePatterns(excludes),
+			llb.Differ(llb.DiffMetadata, false),
+		),
+		sourcePath, // allow fine-grained caching control
+		sourcePath,
+		&llb.CopyInfo{
+			CopyDirContentsOnly: true,
+			CreateDestPath:      true,
+		},
+	)), sourcePath, nil
+}
+
+// otherRuntime returns the platform of the thunk if it targets a platform
+// which this runtime does not run and another runtime in the pool does.
+func (runtime *Buildkit) otherRuntime(ctx context.Context, thunk bass.Thunk) (bass.Platform, bool) {
+	platform := thunk.Platform()
+	if platform == nil {
+		return bass.Platform{}, false
+	}
+
+	if platform.OS == runtime.Platform.OS &&
+		(platform.Arch == "" || platform.Arch == runtime.Platform.Architecture) {
+		return bass.Platform{}, false
+	}
+
+	other, err := bass.RuntimeFromContext(ctx, *platform)
+	if err != nil || other == bass.Runtime(runtime) {
+		return bass.Platform{}, false
+	}
+
+	return *platform, true
+}
+
 func (b *builder) initializeMount(ctx context.Context, source bass.ThunkMountSource, targetPath string) (llb.RunOption, string, bool, error) {
 	if source.ThunkPath != nil {
+		if other, ok := b.runtime.otherRuntime(ctx, source.ThunkPath.Thunk); ok {
+			// the thunk path belongs to another runtime, so its bytes have to
+			// go through the host
+			zapctx.FromContext(ctx).Debug("exporting thunk path from other runtime",
+				zap.Stringer("path", source.ThunkPath),
+				zap.Any("platform", other))
+
+			hp, err := source.ThunkPath.Materialize(ctx)
+			if err != nil {
+				return nil, "", false, err
+			}
+
+			return b.initializeMount(ctx, bass.ThunkMountSource{HostPath: &hp}, targetPath)
+		}
+
+		// the thunk path belongs to this runtime, so wire it directly into the
+		// build rather than exporting and re-importing it
+		if source.ThunkPath.IsStdout() {
+			thunkSt, _, needsInsecure, err := b.llb(ctx, source.ThunkPath.Thunk, llb.AddEnv("_BASS_OUTPUT", outputFile))
+			if err != nil {
+				return nil, "", false, fmt.Errorf("thunk llb: %w", err)
+			}
+
+			sourcePath := filepath.Base(outputFile)
+
+			return llb.AddMount(
+				targetPath,
+				thunkSt.GetMount(ioDir),
+				llb.SourcePath(sourcePath),
+			), sourcePath, needsInsecure, nil
+		}
+
 		thunkSt, baseSourcePath, needsInsecure, err := b.llb(ctx, source.ThunkPath.Thunk)
 		if err != nil {
 			return nil, "", false, fmt.Errorf("thunk llb: %w", err)
@@ -953,36 +1881,14 @@ func (b *builder) initializeMount(ctx context.Context, source bass.ThunkMountSou
 	}
 
 	if source.HostPath != nil {
-		contextDir := source.HostPath.ContextDir
-		b.localDirs[contextDir] = source.HostPath.ContextDir
-
-		var excludes []string
-		ignorePath := filepath.Join(contextDir, ".bassignore")
-		ignore, err := os.Open(ignorePath)
-		if err == nil {
-			excludes, err = dockerignore.ReadAll(ignore)
-			if err != nil {
-				return nil, "", false, fmt.Errorf("parse %s: %w", ignorePath, err)
-			}
+		st, sourcePath, err := b.hostPath(*source.HostPath)
+		if err != nil {
+			return nil, "", false, err
 		}
 
-		sourcePath := source.HostPath.Path.FilesystemPath().FromSlash()
-
 		return llb.AddMount(
 			targetPath,
-			llb.Scratch().File(llb.Copy(
-				llb.Local(
-					contextDir,
-					llb.ExcludePatterns(excludes),
-					llb.Differ(llb.DiffMetadata, false),
-				),
-				sourcePath, // allow fine-grained caching control
-				sourcePath,
-				&llb.CopyInfo{
-					CopyDirContentsOnly: true,
-					CreateDestPath:      true,
-				},
-			)),
+			st,
 			llb.SourcePath(sourcePath),
 		), sourcePath, false, nil
 	}
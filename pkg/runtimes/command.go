@@ -13,6 +13,9 @@ import (
 	"github.com/vito/bass/pkg/bass"
 )
 
+// stdinFilePath is where a thunk's stdin file is mounted in its container.
+const stdinFilePath = "/bass/stdin"
+
 // Command is a helper type constructed by a runtime by Resolving a Thunk.
 //
 // It contains the direct values to be provided for the process running in the
@@ -23,10 +26,24 @@ type Command struct {
 	Env   []string `json:"env"`
 	Dir   *string  `json:"dir"`
 
+	// StdinFile is the path to a file to stream to stdin after Stdin.
+	StdinFile *string `json:"stdin_file"`
+
+	// MountCheck configures the shim to check for mounts which the command
+	// never accesses. It is set by runtimes which support it.
+	//
+	// It isn't marshaled with the command, since it must not be part of the
+	// command's cache key; runtimes pass it to the shim separately.
+	MountCheck *MountCheck `json:"-"`
+
 	// these don't need to be marshaled, since they're part of the container
 	// setup and not passed to the shim
 	Mounts []CommandMount `json:"-"`
 
+	// Hosts are the names of the services the command uses, to be added to
+	// its hosts file.
+	Hosts []CommandHost `json:"-"`
+
 	mounted map[string]bool
 	starter Starter
 }
@@ -37,6 +54,23 @@ type CommandMount struct {
 	Target string
 }
 
+// MountCheck is passed to the shim to check for unused mounts, and
+// optionally to trace which files were read from them.
+type MountCheck struct {
+	// Targets are the absolute paths of the mounts to check.
+	Targets []string `json:"targets"`
+
+	// Error fails the command if any mount is unused, rather than warning.
+	Error bool `json:"error,omitempty"`
+
+	// Trace lists the absolute paths of the mounts whose reads are traced.
+	Trace []string `json:"trace,omitempty"`
+
+	// TraceFile is where the shim writes the files in each traced mount along
+	// with whether they were read.
+	TraceFile string `json:"trace_file,omitempty"`
+}
+
 type CommandHost struct {
 	Host   string
 	Target net.IP
@@ -50,10 +84,42 @@ type Starter interface {
 type StartResult struct {
 	// A mapping from each port to its address info (host, port, etc.)
 	Ports PortInfos
+
+	// IP is the address the service was reached at, if known.
+	IP net.IP
 }
 
 type PortInfos map[string]*bass.Scope
 
+type startedServiceKey struct{}
+
+// withStartedService records a service which has already been started, so
+// that Start returns its result rather than starting another instance.
+func withStartedService(ctx context.Context, svc bass.Thunk, result StartResult) context.Context {
+	started := map[string]StartResult{}
+	if parent, ok := ctx.Value(startedServiceKey{}).(map[string]StartResult); ok {
+		for k, v := range parent {
+			started[k] = v
+		}
+	}
+
+	started[svc.Name()] = result
+
+	return context.WithValue(ctx, startedServiceKey{}, started)
+}
+
+// startedServiceFromContext returns the result recorded for the service by
+// withStartedService, if any.
+func startedServiceFromContext(ctx context.Context, svc bass.Thunk) (StartResult, bool) {
+	started, ok := ctx.Value(startedServiceKey{}).(map[string]StartResult)
+	if !ok {
+		return StartResult{}, false
+	}
+
+	result, found := started[svc.Name()]
+	return result, found
+}
+
 // Resolve traverses the Thunk, resolving logical path values to their
 // concrete paths in the container, and collecting the requisite mount points
 // along the way.
@@ -122,9 +188,9 @@ func NewCommand(ctx context.Context, starter Starter, thunk bass.Thunk) (Command
 		}
 
 		stdinBuf := new(bytes.Buffer)
-		enc := bass.NewEncoder(stdinBuf)
+		enc := bass.NewValueEncoder(stdinBuf)
 		for _, val := range stdin {
-			err := enc.Encode(val)
+			err := enc.EncodeValue(val)
 			if err != nil {
 				return Command{}, fmt.Errorf("encode stdin: %w", err)
 			}
@@ -133,6 +199,15 @@ func NewCommand(ctx context.Context, starter Starter, thunk bass.Thunk) (Command
 		cmd.Stdin = stdinBuf.Bytes()
 	}
 
+	if thunk.StdinFile != nil {
+		target := stdinFilePath
+		cmd.StdinFile = &target
+		cmd.Mounts = append(cmd.Mounts, CommandMount{
+			Source: *thunk.StdinFile,
+			Target: target,
+		})
+	}
+
 	if thunk.Mounts != nil {
 		for _, m := range thunk.Mounts {
 			cmd.Mounts = append(cmd.Mounts, CommandMount{
@@ -142,6 +217,11 @@ func NewCommand(ctx context.Context, starter Starter, thunk bass.Thunk) (Command
 		}
 	}
 
+	// keep hosts in a stable order, since they're part of the container setup
+	sort.Slice(cmd.Hosts, func(i, j int) bool {
+		return cmd.Hosts[i].Host < cmd.Hosts[j].Host
+	})
+
 	// empty out fields only needed during creation so we can test with equality
 	cmd.mounted = nil
 	cmd.starter = nil
@@ -152,9 +232,11 @@ func NewCommand(ctx context.Context, starter Starter, thunk bass.Thunk) (Command
 func (cmd Command) Equal(other Command) bool {
 	return cmp.Equal(cmd.Args, other.Args) &&
 		cmp.Equal(cmd.Stdin, other.Stdin) &&
+		cmp.Equal(cmd.StdinFile, other.StdinFile) &&
 		cmp.Equal(cmd.Env, other.Env) &&
 		cmp.Equal(cmd.Dir, other.Dir) &&
-		cmp.Equal(cmd.Mounts, other.Mounts)
+		cmp.Equal(cmd.Mounts, other.Mounts) &&
+		cmp.Equal(cmd.Hosts, other.Hosts)
 }
 
 func (cmd *Command) resolveStr(ctx context.Context, val bass.Value) (string, error) {
@@ -355,6 +437,10 @@ func (cmd *Command) resolveValue(ctx context.Context, val bass.Value, dest any)
 			return fmt.Errorf("start %s: %w", addr.Thunk, err)
 		}
 
+		if err := cmd.addHost(addr.Thunk, result); err != nil {
+			return err
+		}
+
 		info, found := result.Ports[addr.Port]
 		if !found {
 			return fmt.Errorf("no info for port '%s': %+v", addr.Port, result.Ports)
@@ -371,6 +457,34 @@ func (cmd *Command) resolveValue(ctx context.Context, val bass.Value, dest any)
 	return val.Decode(dest)
 }
 
+// addHost records the service's name and IP, if it has both, so that the
+// command can reach it by name.
+func (cmd *Command) addHost(svc bass.Thunk, result StartResult) error {
+	name, ok := svc.ServiceName()
+	if !ok || result.IP == nil {
+		return nil
+	}
+
+	for _, host := range cmd.Hosts {
+		if host.Host != name {
+			continue
+		}
+
+		if !host.Target.Equal(result.IP) {
+			return fmt.Errorf("conflicting services named %s", name)
+		}
+
+		return nil
+	}
+
+	cmd.Hosts = append(cmd.Hosts, CommandHost{
+		Host:   name,
+		Target: result.IP,
+	})
+
+	return nil
+}
+
 func (cmd *Command) rel(workRelPath bass.FilesystemPath) string {
 	if cmd.Dir == nil {
 		return workRelPath.FromSlash()
@@ -2,6 +2,7 @@ package runtimes_test
 
 import (
 	"context"
+	"net"
 	"testing"
 
 	"github.com/vito/bass/pkg/bass"
@@ -206,6 +207,30 @@ func TestNewCommand(t *testing.T) {
 		})
 	})
 
+	t.Run("stdin file", func(t *testing.T) {
+		stdinThunk := thunk.WithStdinFile(bass.ThunkMountSource{
+			ThunkPath: &thunkFile,
+		})
+
+		is := is.New(t)
+		cmd, err := runtimes.NewCommand(ctx, starter, stdinThunk)
+		is.NoErr(err)
+
+		stdinFile := "/bass/stdin"
+		is.Equal(cmd, runtimes.Command{
+			Args:      []string{"run"},
+			StdinFile: &stdinFile,
+			Mounts: []runtimes.CommandMount{
+				{
+					Source: bass.ThunkMountSource{
+						ThunkPath: &thunkFile,
+					},
+					Target: stdinFile,
+				},
+			},
+		})
+	})
+
 	t.Run("paths in env", func(t *testing.T) {
 		envThunkPath := thunkFile
 		envThunkPath.Path = bass.FileOrDirPath{
@@ -450,6 +475,36 @@ func TestNewCommand(t *testing.T) {
 
 		is.Equal(starter.Started, svcThunk)
 	})
+
+	t.Run("service names", func(t *testing.T) {
+		svcAddr := thunkAddr
+		svcAddr.Thunk = svcAddr.Thunk.WithLabel(bass.ServiceNameLabel, bass.String("web"))
+
+		envThunk := thunk
+		envThunk.Env = bass.Bindings{
+			"WEB_ADDR":  svcAddr,
+			"WEB_HTTPS": svcAddr,
+		}.Scope()
+
+		starter := &FakeStarter{
+			StartResult: runtimes.StartResult{
+				Ports: runtimes.PortInfos{
+					"http": bass.Bindings{
+						"host": bass.String("drew"),
+						"port": bass.Int(6455),
+					}.Scope(),
+				},
+				IP: net.ParseIP("10.0.0.2"),
+			},
+		}
+
+		is := is.New(t)
+		cmd, err := runtimes.NewCommand(ctx, starter, envThunk)
+		is.NoErr(err)
+		is.Equal(cmd.Hosts, []runtimes.CommandHost{
+			{Host: "web", Target: net.ParseIP("10.0.0.2")},
+		})
+	})
 }
 
 func TestNewCommandInDir(t *testing.T) {
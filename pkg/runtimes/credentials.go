@@ -0,0 +1,179 @@
+package runtimes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	authutil "github.com/containerd/containerd/remotes/docker/auth"
+	remoteserrors "github.com/containerd/containerd/remotes/errors"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/auth"
+	"github.com/vito/bass/pkg/bass"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// helperAuthProvider serves registry credentials to Buildkit from a
+// credential helper, falling back to the Docker config for registries the
+// helper has no credentials for.
+type helperAuthProvider struct {
+	helper   bass.CredentialHelper
+	network  bass.NetworkConfig
+	fallback auth.AuthServer
+}
+
+// newAuthProvider returns a session attachable which serves registry
+// credentials from the helper before falling back to the given provider.
+//
+// Tokens are fetched using the network config, so that they go through the
+// same proxies and trust the same CAs as everything else.
+func newAuthProvider(helper bass.CredentialHelper, network bass.NetworkConfig, fallback session.Attachable) session.Attachable {
+	server, ok := fallback.(auth.AuthServer)
+	if !ok {
+		return fallback
+	}
+
+	return &helperAuthProvider{
+		helper:   helper,
+		network:  network,
+		fallback: server,
+	}
+}
+
+func (ap *helperAuthProvider) Register(server *grpc.Server) {
+	auth.RegisterAuthServer(server, ap)
+}
+
+func (ap *helperAuthProvider) Credentials(ctx context.Context, req *auth.CredentialsRequest) (*auth.CredentialsResponse, error) {
+	cred, found, err := ap.helper.Get(ctx, bass.CredentialKindRegistry, req.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return ap.fallback.Credentials(ctx, req)
+	}
+
+	return &auth.CredentialsResponse{
+		Username: cred.Username,
+		Secret:   cred.Secret,
+	}, nil
+}
+
+func (ap *helperAuthProvider) FetchToken(ctx context.Context, req *auth.FetchTokenRequest) (*auth.FetchTokenResponse, error) {
+	cred, found, err := ap.helper.Get(ctx, bass.CredentialKindRegistry, req.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return ap.fallback.FetchToken(ctx, req)
+	}
+
+	to := authutil.TokenOptions{
+		Realm:    req.Realm,
+		Service:  req.Service,
+		Scopes:   req.Scopes,
+		Username: cred.Username,
+		Secret:   cred.Secret,
+	}
+
+	httpClient, err := ap.network.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := authutil.FetchTokenWithOAuth(ctx, httpClient, nil, "bass", to)
+	if err == nil {
+		return tokenResponse(res.AccessToken, res.IssuedAt, res.ExpiresIn), nil
+	}
+
+	// registries without support for POST fall back to GET
+	var unexpected remoteserrors.ErrUnexpectedStatus
+	if !errors.As(err, &unexpected) {
+		return nil, err
+	}
+
+	switch unexpected.StatusCode {
+	case http.StatusNotFound, http.StatusUnauthorized, http.StatusMethodNotAllowed:
+	default:
+		return nil, err
+	}
+
+	getRes, err := authutil.FetchToken(ctx, httpClient, nil, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokenResponse(getRes.Token, getRes.IssuedAt, getRes.ExpiresIn), nil
+}
+
+// GetTokenAuthority disables tokens shared across sessions for registries
+// served by the helper, since the fallback's authority keys are derived from
+// the Docker config's credentials.
+func (ap *helperAuthProvider) GetTokenAuthority(ctx context.Context, req *auth.GetTokenAuthorityRequest) (*auth.GetTokenAuthorityResponse, error) {
+	_, found, err := ap.helper.Get(ctx, bass.CredentialKindRegistry, req.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	if found {
+		return nil, status.Errorf(codes.Unavailable, "client side tokens disabled for credential helper")
+	}
+
+	return ap.fallback.GetTokenAuthority(ctx, req)
+}
+
+func (ap *helperAuthProvider) VerifyTokenAuthority(ctx context.Context, req *auth.VerifyTokenAuthorityRequest) (*auth.VerifyTokenAuthorityResponse, error) {
+	_, found, err := ap.helper.Get(ctx, bass.CredentialKindRegistry, req.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	if found {
+		return nil, status.Errorf(codes.Unavailable, "client side tokens disabled for credential helper")
+	}
+
+	return ap.fallback.VerifyTokenAuthority(ctx, req)
+}
+
+func tokenResponse(token string, issuedAt time.Time, expiresIn int) *auth.FetchTokenResponse {
+	if expiresIn == 0 {
+		expiresIn = 60
+	}
+
+	res := &auth.FetchTokenResponse{
+		Token:     token,
+		ExpiresIn: int64(expiresIn),
+	}
+
+	if !issuedAt.IsZero() {
+		res.IssuedAt = issuedAt.Unix()
+	}
+
+	return res
+}
+
+// registryCreds returns the credentials for a registry host from the
+// credential helper in the context, falling back to the Docker config.
+func registryCreds(ctx context.Context) func(string) (string, string, error) {
+	helper, hasHelper := bass.CredentialHelperFromContext(ctx)
+
+	return func(host string) (string, string, error) {
+		if hasHelper {
+			cred, found, err := helper.Get(ctx, bass.CredentialKindRegistry, host)
+			if err != nil {
+				return "", "", err
+			}
+
+			if found {
+				return cred.Username, cred.Secret, nil
+			}
+		}
+
+		return dockerCreds(host)
+	}
+}
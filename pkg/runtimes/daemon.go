@@ -0,0 +1,452 @@
+package runtimes
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/proto"
+	"github.com/vito/bass/pkg/zapctx"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// DaemonSocketExt is the extension of each runtime socket served by a daemon.
+const DaemonSocketExt = ".sock"
+
+// daemonDialTimeout bounds how long to wait when checking whether a daemon
+// socket is live.
+var daemonDialTimeout = time.Second
+
+// DaemonDir returns the directory containing the sockets served by a daemon.
+func DaemonDir() string {
+	return filepath.Join(xdg.RuntimeDir, "bass", "daemon")
+}
+
+// DaemonSocket returns the path to the socket through which a daemon serves
+// the runtime for the given platform.
+func DaemonSocket(platform bass.Platform) string {
+	name := platform.OS
+	if platform.Arch != "" {
+		name += "-" + platform.Arch
+	}
+
+	return filepath.Join(DaemonDir(), "runtimes", name+DaemonSocketExt)
+}
+
+// DaemonControlSocket returns the path to the socket through which a daemon
+// serves its HTTP control API.
+func DaemonControlSocket() string {
+	return filepath.Join(DaemonDir(), "control"+DaemonSocketExt)
+}
+
+// ServeDaemon serves each runtime in the pool over a Unix socket in
+// DaemonDir until the context is canceled, so that many clients can share
+// the same warm runtimes and caches.
+//
+// Only the runtimes are shared. Each client still evaluates its own scripts
+// and shows its own progress.
+//
+// Returns ErrDaemonRunning if another daemon is serving any of the sockets.
+// Sockets left behind by a daemon which is no longer running are replaced.
+//
+// Calls from all clients are scheduled through the queue. The queue's jobs
+// may be listed through the control socket with DaemonJobs.
+//
+// Once the context is canceled, each server stops accepting new calls and
+// in-flight calls are given the drain timeout to finish before they are
+// canceled.
+func ServeDaemon(ctx context.Context, pool *Pool, queue *Queue, drainTimeout time.Duration) error {
+	logger := zapctx.FromContext(ctx)
+
+	if len(pool.Runtimes) == 0 {
+		return fmt.Errorf("no runtimes configured")
+	}
+
+	if err := os.MkdirAll(filepath.Join(DaemonDir(), "runtimes"), 0700); err != nil {
+		return err
+	}
+
+	// check every socket before serving any of them, so that a running daemon
+	// is left alone rather than partially taken over
+	sockets := []string{DaemonControlSocket()}
+	for _, assoc := range pool.Runtimes {
+		sockets = append(sockets, DaemonSocket(assoc.Platform))
+	}
+
+	for _, socket := range sockets {
+		if err := claimSocket(socket); err != nil {
+			return err
+		}
+	}
+
+	servers := new(errgroup.Group)
+
+	var served daemonRuntimes
+	served.Emulate = pool.Emulate
+
+	for _, assoc := range pool.Runtimes {
+		assoc := assoc
+
+		socket := DaemonSocket(assoc.Platform)
+
+		served.Runtimes = append(served.Runtimes, daemonRuntime{
+			Platform: assoc.Platform,
+			Socket:   socket,
+		})
+
+		listener, err := listenUnix(socket)
+		if err != nil {
+			return err
+		}
+
+		drainer := NewDrainer()
+
+		srv := grpc.NewServer()
+		proto.RegisterRuntimeServer(srv, &Server{
+			Runtime: assoc.Runtime,
+			Pool:    pool,
+			Drainer: drainer,
+			Queue:   queue,
+		})
+
+		servers.Go(func() error {
+			return srv.Serve(listener)
+		})
+
+		go func() {
+			<-ctx.Done()
+			drainer.Drain(ctx, drainTimeout, srv.GracefulStop)
+		}()
+
+		logger.Info("serving runtime",
+			zap.Any("platform", assoc.Platform),
+			zap.String("socket", socket))
+	}
+
+	// the control socket is served last, since clients find the runtimes
+	// through it
+	control, err := listenUnix(DaemonControlSocket())
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/runtimes", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(served)
+	})
+
+	mux.HandleFunc("/ps", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(queue.Jobs())
+	})
+
+	mux.HandleFunc("/gates", func(w http.ResponseWriter, r *http.Request) {
+		gates, err := bass.PendingGates()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(gates)
+	})
+
+	mux.HandleFunc("/gates/", approveGateHandler)
+
+	mux.HandleFunc("/locks/", lockHandler)
+
+	controlSrv := &http.Server{Handler: mux}
+	servers.Go(func() error {
+		if err := controlSrv.Serve(control); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+
+		return nil
+	})
+
+	go func() {
+		<-ctx.Done()
+		_ = controlSrv.Close()
+	}()
+
+	return servers.Wait()
+}
+
+// approveGateHandler approves a gate with a POST to /gates/<name>/approve,
+// e.g. from a webhook. The approver may be given as the approver form value.
+func approveGateHandler(w http.ResponseWriter, r *http.Request) {
+	name, action, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/gates/"), "/")
+	if !ok || action != "approve" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	approval, err := bass.ApproveGate(name, r.FormValue("approver"), bass.GateViaWebhook)
+	if errors.Is(err, bass.ErrGateNotPending) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(approval)
+}
+
+// lockHandler holds the lock named by a POST to /locks/<name> until the
+// request is closed, so that a client which goes away releases its locks.
+//
+// Once the lock is acquired, the response status and a line are written
+// immediately so that the client knows it holds the lock.
+func lockHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/locks/")
+
+	unlock, err := bass.FileLocker{Dir: bass.LocksDir()}.Lock(r.Context(), name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	defer unlock()
+
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintln(w, "locked")
+
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+
+	<-r.Context().Done()
+}
+
+// DaemonLocker is a bass.Locker which holds locks in a running daemon, so
+// that they are shared by all of its clients.
+type DaemonLocker struct{}
+
+func (DaemonLocker) Lock(ctx context.Context, name string) (func(), error) {
+	lockCtx, cancel := context.WithCancel(context.Background())
+
+	// stop waiting if the context is canceled before the lock is acquired
+	acquired := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			cancel()
+		case <-acquired:
+		}
+	}()
+
+	req, err := http.NewRequestWithContext(lockCtx, http.MethodPost, "http://daemon/locks/"+url.PathEscape(name), nil)
+	if err != nil {
+		close(acquired)
+		cancel()
+		return nil, err
+	}
+
+	res, err := daemonControlClient().Do(req)
+	close(acquired)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("lock %s: %w", name, err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		cancel()
+		return nil, fmt.Errorf("lock %s: daemon: %s", name, strings.TrimSpace(string(msg)))
+	}
+
+	if _, err := bufio.NewReader(res.Body).ReadString('\n'); err != nil {
+		res.Body.Close()
+		cancel()
+		return nil, fmt.Errorf("lock %s: %w", name, err)
+	}
+
+	return func() {
+		cancel()
+		res.Body.Close()
+	}, nil
+}
+
+func daemonControlClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", DaemonControlSocket())
+			},
+		},
+	}
+}
+
+// daemonRuntimes lists the runtimes served by a daemon in the order they
+// were configured, along with the pool's other settings.
+type daemonRuntimes struct {
+	Runtimes []daemonRuntime `json:"runtimes"`
+	Emulate  bool            `json:"emulate,omitempty"`
+}
+
+type daemonRuntime struct {
+	Platform bass.Platform `json:"platform"`
+	Socket   string        `json:"socket"`
+}
+
+// DaemonPool returns a pool of the runtimes served by a running daemon, or
+// false if no daemon is running.
+//
+// Only the runtimes are shared: the pool selects and emulates platforms as
+// configured by the daemon, while everything else, like the network config
+// and credential helper, is still configured by the client.
+func DaemonPool(ctx context.Context) (*Pool, bool, error) {
+	logger := zapctx.FromContext(ctx)
+
+	// don't wait long on a socket left behind by a daemon that is no longer
+	// running
+	reqCtx, cancel := context.WithTimeout(ctx, daemonDialTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, "http://daemon/runtimes", nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	res, err := daemonControlClient().Do(req)
+	if err != nil {
+		logger.Debug("no daemon running",
+			zap.String("socket", DaemonControlSocket()),
+			zap.Error(err))
+		return nil, false, nil
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("daemon: %s", res.Status)
+	}
+
+	var served daemonRuntimes
+	if err := json.NewDecoder(res.Body).Decode(&served); err != nil {
+		return nil, false, fmt.Errorf("daemon: decode runtimes: %w", err)
+	}
+
+	pool := &Pool{
+		Emulate: served.Emulate,
+	}
+
+	for _, runtime := range served.Runtimes {
+		client, err := grpc.DialContext(ctx, "unix://"+runtime.Socket,
+			grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, false, fmt.Errorf("dial daemon: %w", err)
+		}
+
+		pool.Runtimes = append(pool.Runtimes, Assoc{
+			Platform: runtime.Platform,
+			Runtime: &Client{
+				Conn:          client,
+				RuntimeClient: proto.NewRuntimeClient(client),
+			},
+		})
+	}
+
+	if len(pool.Runtimes) == 0 {
+		return nil, false, nil
+	}
+
+	return pool, true, nil
+}
+
+// DaemonJobs returns the running and waiting jobs in a running daemon's queue.
+func DaemonJobs(ctx context.Context) ([]QueueJob, error) {
+	client := daemonControlClient()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://daemon/ps", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("connect to daemon: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("daemon: %s", res.Status)
+	}
+
+	var jobs []QueueJob
+	if err := json.NewDecoder(res.Body).Decode(&jobs); err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// ErrDaemonRunning is returned by ServeDaemon when another daemon is already
+// serving one of its sockets.
+var ErrDaemonRunning = errors.New("daemon already running")
+
+// claimSocket removes the socket if it was left behind by a previous daemon
+// which is no longer running, returning ErrDaemonRunning if it is still being
+// served.
+func claimSocket(socket string) error {
+	conn, err := net.DialTimeout("unix", socket, daemonDialTimeout)
+	if err == nil {
+		conn.Close()
+		return fmt.Errorf("%w: %s", ErrDaemonRunning, socket)
+	}
+
+	if !errors.Is(err, syscall.ECONNREFUSED) && !errors.Is(err, syscall.ENOENT) {
+		return fmt.Errorf("check %s: %w", socket, err)
+	}
+
+	if err := os.Remove(socket); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// listenUnix listens on the socket path, which must have been claimed with
+// claimSocket.
+func listenUnix(socket string) (net.Listener, error) {
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+
+	return listener, nil
+}
@@ -0,0 +1,198 @@
+package runtimes_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adrg/xdg"
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/runtimes"
+	"github.com/vito/is"
+)
+
+type resolvingRuntime struct {
+	bass.Runtime
+}
+
+func (resolvingRuntime) Resolve(_ context.Context, ref bass.ImageRef) (bass.ImageRef, error) {
+	ref.Digest = "sha256:resolved"
+	return ref, nil
+}
+
+func TestDaemon(t *testing.T) {
+	is := is.New(t)
+
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	xdg.Reload()
+	defer xdg.Reload()
+
+	ctx := context.Background()
+
+	_, found, err := runtimes.DaemonPool(ctx)
+	is.NoErr(err)
+	is.True(!found)
+
+	// leave behind a socket as if from a daemon which was killed
+	is.NoErr(os.MkdirAll(runtimes.DaemonDir(), 0700))
+	stale, err := net.Listen("unix", runtimes.DaemonControlSocket())
+	is.NoErr(err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	is.NoErr(stale.Close())
+
+	platform := bass.Platform{OS: "linux", Arch: "amd64"}
+	otherPlatform := bass.Platform{OS: "linux", Arch: "386"}
+
+	daemonCtx, stop := context.WithCancel(ctx)
+	served := make(chan error, 1)
+	go func() {
+		served <- runtimes.ServeDaemon(daemonCtx, &runtimes.Pool{
+			Runtimes: []runtimes.Assoc{
+				{
+					Platform: platform,
+					Runtime:  resolvingRuntime{},
+				},
+				{
+					Platform: otherPlatform,
+					Runtime:  resolvingRuntime{},
+				},
+			},
+			Emulate: true,
+		}, runtimes.NewQueue(bass.QueueConfig{}), time.Second)
+	}()
+
+	socket := runtimes.DaemonSocket(platform)
+	is.Equal(filepath.Base(socket), "linux-amd64.sock")
+
+	for {
+		if conn, err := net.Dial("unix", runtimes.DaemonControlSocket()); err == nil {
+			conn.Close()
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	pool, found, err := runtimes.DaemonPool(ctx)
+	is.NoErr(err)
+	is.True(found)
+	defer pool.Close()
+
+	// a second daemon does not take over the running daemon's sockets
+	err = runtimes.ServeDaemon(ctx, &runtimes.Pool{
+		Runtimes: []runtimes.Assoc{
+			{
+				Platform: platform,
+				Runtime:  resolvingRuntime{},
+			},
+		},
+	}, runtimes.NewQueue(bass.QueueConfig{}), time.Second)
+	is.True(errors.Is(err, runtimes.ErrDaemonRunning))
+
+	_, found, err = runtimes.DaemonPool(ctx)
+	is.NoErr(err)
+	is.True(found)
+
+	// configured order and settings are kept
+	is.Equal(pool.Platforms(), []bass.Platform{platform, otherPlatform})
+	is.True(pool.Emulate)
+
+	runtime, err := pool.Select(platform)
+	is.NoErr(err)
+
+	ref, err := runtime.Resolve(ctx, bass.ImageRef{
+		Platform: platform,
+		Repository: bass.ImageRepository{
+			Static: "alpine",
+		},
+		Tag: "latest",
+	})
+	is.NoErr(err)
+	is.Equal(ref.Digest, "sha256:resolved")
+
+	jobs, err := runtimes.DaemonJobs(ctx)
+	is.NoErr(err)
+	is.Equal(len(jobs), 0)
+
+	oldHome := bass.CacheHome
+	bass.CacheHome = t.TempDir()
+	defer func() { bass.CacheHome = oldHome }()
+
+	control := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", runtimes.DaemonControlSocket())
+			},
+		},
+	}
+
+	oldInterval := bass.GatePollInterval
+	bass.GatePollInterval = 10 * time.Millisecond
+	defer func() { bass.GatePollInterval = oldInterval }()
+
+	approveGate := func() int {
+		res, err := control.PostForm("http://daemon/gates/deploy-prod/approve", url.Values{"approver": {"ci"}})
+		is.NoErr(err)
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	// nothing is waiting on the gate yet
+	is.Equal(approveGate(), http.StatusConflict)
+
+	approved := make(chan bass.GateApproval, 1)
+	go func() {
+		approval, err := bass.WaitForGate(ctx, "deploy-prod")
+		is.NoErr(err)
+		approved <- approval
+	}()
+
+	for {
+		pending, err := bass.PendingGates()
+		is.NoErr(err)
+
+		if len(pending) > 0 {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	is.Equal(approveGate(), http.StatusOK)
+
+	approval := <-approved
+	is.Equal(approval.Approver, "ci")
+	is.Equal(approval.Via, bass.GateViaWebhook)
+
+	unlock, err := runtimes.DaemonLocker{}.Lock(ctx, "deploy")
+	is.NoErr(err)
+
+	waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	_, err = runtimes.DaemonLocker{}.Lock(waitCtx, "deploy")
+	is.True(err != nil)
+
+	unlock()
+
+	unlock, err = runtimes.DaemonLocker{}.Lock(ctx, "deploy")
+	is.NoErr(err)
+	unlock()
+
+	stop()
+	is.NoErr(<-served)
+
+	_, err = os.Stat(socket)
+	is.True(os.IsNotExist(err))
+
+	_, found, err = runtimes.DaemonPool(ctx)
+	is.NoErr(err)
+	is.True(!found)
+}
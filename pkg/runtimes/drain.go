@@ -0,0 +1,102 @@
+package runtimes
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/vito/bass/pkg/zapctx"
+	"go.uber.org/zap"
+)
+
+// DefaultDrainTimeout is the default window given to in-flight work to finish
+// when a server shuts down.
+const DefaultDrainTimeout = time.Minute
+
+// drainProgressInterval is how often to log progress while draining.
+var drainProgressInterval = 5 * time.Second
+
+// Drainer tracks in-flight work handled by a server so that it can be shut
+// down gracefully.
+//
+// A nil Drainer is valid; work is tracked against a background context which
+// is never canceled.
+type Drainer struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	inflight int64
+}
+
+// NewDrainer returns a Drainer with no work in flight.
+func NewDrainer() *Drainer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Drainer{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Start marks the beginning of a unit of work, returning the context to run
+// it in and a func to call when it is done.
+//
+// The context is canceled if the work is still running once the drain window
+// has elapsed.
+func (drainer *Drainer) Start() (context.Context, func()) {
+	if drainer == nil {
+		return context.Background(), func() {}
+	}
+
+	atomic.AddInt64(&drainer.inflight, 1)
+	return drainer.ctx, func() {
+		atomic.AddInt64(&drainer.inflight, -1)
+	}
+}
+
+// InFlight returns the number of units of work currently running.
+func (drainer *Drainer) InFlight() int64 {
+	if drainer == nil {
+		return 0
+	}
+
+	return atomic.LoadInt64(&drainer.inflight)
+}
+
+// Drain calls gracefulStop, which should stop accepting new work and wait for
+// in-flight work to finish. If it does not return within the window, all
+// in-flight work is canceled.
+//
+// Progress is reported to the logger in ctx.
+func (drainer *Drainer) Drain(ctx context.Context, window time.Duration, gracefulStop func()) {
+	logger := zapctx.FromContext(ctx)
+
+	stopped := make(chan struct{})
+	go func() {
+		gracefulStop()
+		close(stopped)
+	}()
+
+	logger.Info("draining",
+		zap.Int64("inflight", drainer.InFlight()),
+		zap.Duration("window", window))
+
+	ticker := time.NewTicker(drainProgressInterval)
+	defer ticker.Stop()
+
+	timeout := time.After(window)
+	for {
+		select {
+		case <-stopped:
+			logger.Info("drained")
+			drainer.cancel()
+			return
+		case <-ticker.C:
+			logger.Info("draining", zap.Int64("inflight", drainer.InFlight()))
+		case <-timeout:
+			logger.Warn("drain window elapsed; canceling in-flight work",
+				zap.Int64("inflight", drainer.InFlight()))
+			drainer.cancel()
+			timeout = nil
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package runtimes_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vito/bass/pkg/runtimes"
+	"github.com/vito/is"
+)
+
+func TestDrainer(t *testing.T) {
+	t.Run("waits for in-flight work", func(t *testing.T) {
+		is := is.New(t)
+
+		drainer := runtimes.NewDrainer()
+
+		ctx, done := drainer.Start()
+		is.Equal(drainer.InFlight(), int64(1))
+
+		finished := make(chan struct{})
+		drainer.Drain(context.Background(), time.Minute, func() {
+			time.Sleep(10 * time.Millisecond)
+			is.NoErr(ctx.Err())
+			done()
+			close(finished)
+		})
+
+		<-finished
+		is.Equal(drainer.InFlight(), int64(0))
+	})
+
+	t.Run("cancels work after the window", func(t *testing.T) {
+		is := is.New(t)
+
+		drainer := runtimes.NewDrainer()
+
+		ctx, done := drainer.Start()
+
+		drainer.Drain(context.Background(), 10*time.Millisecond, func() {
+			<-ctx.Done()
+			done()
+		})
+
+		is.Equal(ctx.Err(), context.Canceled)
+		is.Equal(drainer.InFlight(), int64(0))
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		is := is.New(t)
+
+		var drainer *runtimes.Drainer
+
+		ctx, done := drainer.Start()
+		defer done()
+
+		is.NoErr(ctx.Err())
+		is.Equal(drainer.InFlight(), int64(0))
+	})
+}
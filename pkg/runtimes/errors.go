@@ -15,6 +15,10 @@ type NoRuntimeError struct {
 	Platform bass.Platform
 
 	AllRuntimes []Assoc
+
+	// Emulate is true if emulation was enabled but no runtime could emulate
+	// the platform.
+	Emulate bool
 }
 
 func (err NoRuntimeError) Error() string {
@@ -31,6 +35,13 @@ func (err NoRuntimeError) NiceError(w io.Writer, outer error) error {
 			fmt.Fprintf(w, "* %s", assoc.Platform)
 		}
 	}
+
+	if !err.Emulate {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, `set "emulate": true in your config to fall back to emulation`)
+	}
+
 	return nil
 }
 
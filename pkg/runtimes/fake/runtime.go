@@ -0,0 +1,253 @@
+package fake
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+	"testing/fstest"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/vito/bass/pkg/bass"
+)
+
+// Runtime is an in-memory bass.Runtime for unit testing code which runs
+// thunks, without needing Docker or Buildkit.
+//
+// Thunks are not actually run. Instead, each thunk's result is determined by
+// the first matching stub, and thunks matching no stub succeed with no
+// output. Every thunk run is recorded, in order; see Runs.
+type Runtime struct {
+	stubs []runtimeStub
+	runs  []bass.Thunk
+	l     sync.Mutex
+}
+
+// Result is the stubbed result of running a thunk.
+type Result struct {
+	// Stdout is the thunk's output, e.g. as parsed by (read).
+	Stdout []byte
+
+	// Files are the files in the thunk's output directory, served for the
+	// thunk's paths.
+	Files fstest.MapFS
+
+	// Err is returned when the thunk is run, e.g. to simulate a failing
+	// command.
+	Err error
+}
+
+type runtimeStub struct {
+	match  func(bass.Thunk) bool
+	result Result
+}
+
+var _ bass.Runtime = (*Runtime)(nil)
+
+// NewRuntime returns a Runtime with no stubs.
+func NewRuntime() *Runtime {
+	return &Runtime{}
+}
+
+// Stub sets the result for thunks matched by the given function. Stubs are
+// matched in the order they were added.
+func (fake *Runtime) Stub(match func(bass.Thunk) bool, result Result) {
+	fake.l.Lock()
+	fake.stubs = append(fake.stubs, runtimeStub{match, result})
+	fake.l.Unlock()
+}
+
+// StubCmdline sets the result for thunks whose command line, as returned by
+// Thunk.Cmdline, is equal to the given string, e.g. "go test ./...".
+func (fake *Runtime) StubCmdline(cmdline string, result Result) {
+	fake.Stub(func(thunk bass.Thunk) bool {
+		return thunk.Cmdline() == cmdline
+	}, result)
+}
+
+// Runs returns the thunks run so far, in order.
+func (fake *Runtime) Runs() []bass.Thunk {
+	fake.l.Lock()
+	defer fake.l.Unlock()
+	return append([]bass.Thunk{}, fake.runs...)
+}
+
+// Resolve returns the ref with a digest derived from its repository and tag,
+// unless it already has one.
+func (fake *Runtime) Resolve(_ context.Context, ref bass.ImageRef) (bass.ImageRef, error) {
+	if ref.Digest == "" {
+		ref.Digest = digest.FromString(ref.Repository.Static + ":" + ref.Tag).String()
+	}
+
+	return ref, nil
+}
+
+// Run records the thunk and returns its stubbed error.
+func (fake *Runtime) Run(_ context.Context, thunk bass.Thunk) error {
+	return fake.run(thunk).Err
+}
+
+// Read records the thunk and writes its stubbed output to w.
+func (fake *Runtime) Read(_ context.Context, w io.Writer, thunk bass.Thunk) error {
+	res := fake.run(thunk)
+	if res.Err != nil {
+		return res.Err
+	}
+
+	_, err := w.Write(res.Stdout)
+	return err
+}
+
+// Export writes the thunk's stubbed files to w as a tar stream.
+//
+// Note that unlike a real runtime it does not write an OCI image archive.
+func (fake *Runtime) Export(_ context.Context, w io.Writer, thunk bass.Thunk) error {
+	res := fake.run(thunk)
+	if res.Err != nil {
+		return res.Err
+	}
+
+	return writeTar(w, res.Files, ".")
+}
+
+// ExportPath writes the stubbed files under the thunk path to w as a tar
+// stream, or its stubbed stdout if the path refers to it.
+func (fake *Runtime) ExportPath(_ context.Context, w io.Writer, tp bass.ThunkPath) error {
+	res := fake.run(tp.Thunk)
+	if res.Err != nil {
+		return res.Err
+	}
+
+	if tp.IsStdout() {
+		name := tp.Path.FilesystemPath().Name()
+		return writeTar(w, fstest.MapFS{name: {Data: res.Stdout}}, name)
+	}
+
+	return writeTar(w, res.Files, path.Clean(strings.TrimPrefix(tp.Path.Slash(), "./")))
+}
+
+// Prune does nothing.
+func (fake *Runtime) Prune(context.Context, bass.PruneOpts) error {
+	return nil
+}
+
+// Close does nothing.
+func (fake *Runtime) Close() error {
+	return nil
+}
+
+func (fake *Runtime) run(thunk bass.Thunk) Result {
+	fake.l.Lock()
+	defer fake.l.Unlock()
+
+	fake.runs = append(fake.runs, thunk)
+
+	for _, stub := range fake.stubs {
+		if stub.match(thunk) {
+			return stub.result
+		}
+	}
+
+	return Result{}
+}
+
+// writeTar writes the files under root to w as a tar stream. Like a real
+// runtime, the entries are relative to root, or named after root if it is a
+// file.
+func writeTar(w io.Writer, files fstest.MapFS, root string) error {
+	if files == nil {
+		files = fstest.MapFS{}
+	}
+
+	info, err := fs.Stat(files, root)
+	if err != nil {
+		return err
+	}
+
+	tw := tar.NewWriter(w)
+
+	write := func(name string, filePath string, info fs.FileInfo) error {
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+
+		header.Name = name
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		content, err := files.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+
+		_, err = tw.Write(content)
+		return err
+	}
+
+	if info.IsDir() {
+		err = fs.WalkDir(files, root, func(filePath string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if entry.IsDir() {
+				return nil
+			}
+
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+
+			name := filePath
+			if root != "." {
+				name = strings.TrimPrefix(filePath, root+"/")
+			}
+
+			return write(name, filePath, info)
+		})
+	} else {
+		err = write(path.Base(root), root, info)
+	}
+	if err != nil {
+		return fmt.Errorf("write tar: %w", err)
+	}
+
+	return tw.Close()
+}
+
+// Pool is a bass.RuntimePool which selects the same runtime for every
+// platform.
+type Pool struct {
+	Runtime bass.Runtime
+}
+
+var _ bass.RuntimePool = Pool{}
+
+// WithRuntime returns a context in which thunks are run by the given runtime,
+// regardless of their platform.
+func WithRuntime(ctx context.Context, runtime bass.Runtime) context.Context {
+	return bass.WithRuntimePool(ctx, Pool{Runtime: runtime})
+}
+
+// Select returns the runtime.
+func (pool Pool) Select(bass.Platform) (bass.Runtime, error) {
+	return pool.Runtime, nil
+}
+
+// All returns the runtime.
+func (pool Pool) All() ([]bass.Runtime, error) {
+	return []bass.Runtime{pool.Runtime}, nil
+}
+
+// Platforms returns bass.LinuxPlatform.
+func (pool Pool) Platforms() []bass.Platform {
+	return []bass.Platform{bass.LinuxPlatform}
+}
@@ -0,0 +1,83 @@
+package fake_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"testing/fstest"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/runtimes/fake"
+	"github.com/vito/is"
+)
+
+func TestRuntime(t *testing.T) {
+	is := is.New(t)
+
+	runtime := fake.NewRuntime()
+	runtime.StubCmdline("echo hello", fake.Result{
+		Stdout: []byte(`"hello"`),
+	})
+	runtime.StubCmdline("build", fake.Result{
+		Files: fstest.MapFS{
+			"out/bin/app": {Data: []byte("app")},
+			"VERSION":     {Data: []byte("1.2.3")},
+		},
+	})
+	runtime.StubCmdline("exit 1", fake.Result{
+		Err: errors.New("exit status 1"),
+	})
+
+	ctx := fake.WithRuntime(context.Background(), runtime)
+
+	eval := func(src string) (bass.Value, error) {
+		return bass.EvalFSFile(ctx, bass.NewStandardScope(), bass.NewInMemoryFile("test", `
+			(def image {:platform {:os "linux"} :repository "alpine" :tag "latest"})
+		`+src))
+	}
+
+	res, err := eval(`(next (read (from image ($ echo hello)) :json))`)
+	is.NoErr(err)
+	is.Equal(res, bass.String("hello"))
+
+	res, err = eval(`
+		(def built (from image ($ build)))
+		[(next (read built/VERSION :raw))
+		 (next (read built/out/bin/app :raw))]
+	`)
+	is.NoErr(err)
+	is.Equal(res, bass.NewList(bass.String("1.2.3"), bass.String("app")))
+
+	res, err = eval(`(succeeds? (from image ($ exit 1)))`)
+	is.NoErr(err)
+	is.Equal(res, bass.Bool(false))
+
+	res, err = eval(`(succeeds? (from image ($ unstubbed)))`)
+	is.NoErr(err)
+	is.Equal(res, bass.Bool(true))
+
+	res, err = eval(`(| (from image ($ echo hello)) (from image ($ rev)))`)
+	is.NoErr(err)
+
+	var piped bass.Thunk
+	is.NoErr(res.Decode(&piped))
+	is.Equal(piped.Cmdline(), "rev")
+	is.True(piped.StdinFile != nil && piped.StdinFile.ThunkPath != nil)
+	is.True(piped.StdinFile.ThunkPath.IsStdout())
+	is.Equal(piped.StdinFile.ThunkPath.Thunk.Cmdline(), "echo hello")
+
+	stdout, err := piped.StdinFile.ThunkPath.Open(ctx)
+	is.NoErr(err)
+	content, err := io.ReadAll(stdout)
+	is.NoErr(err)
+	is.NoErr(stdout.Close())
+	is.Equal(string(content), `"hello"`)
+
+	var cmdlines []string
+	for _, thunk := range runtime.Runs() {
+		cmdlines = append(cmdlines, thunk.Cmdline())
+	}
+
+	is.Equal(cmdlines, []string{"echo hello", "build", "build", "exit 1", "unstubbed", "echo hello"})
+}
@@ -39,23 +39,30 @@ type SSHClient struct {
 	Hosts []string
 	User  string
 
+	// DrainTimeout is how long to wait for in-flight calls to finish when
+	// forwarding stops before canceling them.
+	DrainTimeout time.Duration
+
+	// Network configures the proxy through which hosts are dialed.
+	Network bass.NetworkConfig
+
 	ssh  *ssh.Client
-	conn *net.TCPConn
+	conn net.Conn
 }
 
 func (client *SSHClient) Dial(ctx context.Context, config *ssh.ClientConfig) error {
-	tcpConn, sshAddr, err := client.tryDialAll(ctx)
+	conn, sshAddr, err := client.tryDialAll(ctx)
 	if err != nil {
 		return err
 	}
 
-	clientConn, chans, reqs, err := ssh.NewClientConn(tcpConn, sshAddr, config)
+	clientConn, chans, reqs, err := ssh.NewClientConn(conn, sshAddr, config)
 	if err != nil {
 		return err
 	}
 
 	client.ssh = ssh.NewClient(clientConn, chans, reqs)
-	client.conn = tcpConn.(*net.TCPConn)
+	client.conn = conn
 
 	go client.keepAlive(ctx, time.Minute, 5*time.Minute)
 
@@ -79,8 +86,13 @@ func (client *SSHClient) Forward(ctx context.Context, assoc Assoc) error {
 		return err
 	}
 
+	drainer := NewDrainer()
+
 	srv := grpc.NewServer()
-	proto.RegisterRuntimeServer(srv, &Server{Runtime: assoc.Runtime})
+	proto.RegisterRuntimeServer(srv, &Server{
+		Runtime: assoc.Runtime,
+		Drainer: drainer,
+	})
 
 	go func() {
 		if err := srv.Serve(listener); err != nil {
@@ -106,7 +118,11 @@ func (client *SSHClient) Forward(ctx context.Context, assoc Assoc) error {
 		zap.Strings("hosts", client.Hosts),
 		zap.String("user", client.User))
 
-	return client.run(ctx, strings.Join(cmdline, " "))
+	err = client.run(ctx, strings.Join(cmdline, " "))
+
+	drainer.Drain(ctx, client.DrainTimeout, srv.GracefulStop)
+
+	return err
 }
 
 func (client *SSHClient) tryDialAll(ctx context.Context) (net.Conn, string, error) {
@@ -125,7 +141,7 @@ func (client *SSHClient) tryDialAll(ctx context.Context) (net.Conn, string, erro
 
 	var errs error
 	for _, host := range shuffled {
-		conn, err := dialer.Dial("tcp", host)
+		conn, err := client.Network.DialContext(ctx, dialer, host)
 		if err != nil {
 			logger.Error("failed to connect", zap.Error(err))
 			errs = multierror.Append(errs, err)
@@ -287,9 +303,12 @@ func (client *SSHClient) keepAlive(ctx context.Context, interval time.Duration,
 			logger.Debug("keepalive")
 
 		case <-ctx.Done():
-			if err := client.conn.SetKeepAlive(false); err != nil {
-				logger.Error("failed to disable keepalive", zap.Error(err))
-				return
+			// connections tunneled through a proxy are not TCP connections
+			if tcpConn, ok := client.conn.(*net.TCPConn); ok {
+				if err := tcpConn.SetKeepAlive(false); err != nil {
+					logger.Error("failed to disable keepalive", zap.Error(err))
+					return
+				}
 			}
 
 			return
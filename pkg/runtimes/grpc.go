@@ -13,6 +13,8 @@ import (
 	"github.com/vito/progrock"
 	"github.com/vito/progrock/graph"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -41,13 +43,27 @@ func (client *Client) Resolve(ctx context.Context, ref bass.ImageRef) (bass.Imag
 	return ret, nil
 }
 
+func (client *Client) ImageConfig(ctx context.Context, ref bass.ImageRef) ([]byte, error) {
+	p, err := ref.MarshalProto()
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := client.RuntimeClient.ImageConfig(ctx, p.(*proto.ImageRef))
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetData(), nil
+}
+
 func (client *Client) Run(ctx context.Context, thunk bass.Thunk) error {
 	p, err := thunk.MarshalProto()
 	if err != nil {
 		return err
 	}
 
-	r, err := client.RuntimeClient.Run(ctx, p.(*proto.Thunk))
+	r, err := client.RuntimeClient.Run(queueClassToOutgoing(ctx), p.(*proto.Thunk))
 	if err != nil {
 		return err
 	}
@@ -82,7 +98,7 @@ func (client *Client) Read(ctx context.Context, w io.Writer, thunk bass.Thunk) e
 		return err
 	}
 
-	r, err := client.RuntimeClient.Read(ctx, p.(*proto.Thunk))
+	r, err := client.RuntimeClient.Read(queueClassToOutgoing(ctx), p.(*proto.Thunk))
 	if err != nil {
 		return err
 	}
@@ -123,7 +139,7 @@ func (client *Client) Export(ctx context.Context, w io.Writer, thunk bass.Thunk)
 		return err
 	}
 
-	r, err := client.RuntimeClient.Export(ctx, p.(*proto.Thunk))
+	r, err := client.RuntimeClient.Export(queueClassToOutgoing(ctx), p.(*proto.Thunk))
 	if err != nil {
 		return err
 	}
@@ -153,7 +169,7 @@ func (client *Client) ExportPath(ctx context.Context, w io.Writer, tp bass.Thunk
 		return err
 	}
 
-	r, err := client.RuntimeClient.ExportPath(ctx, p.(*proto.ThunkPath))
+	r, err := client.RuntimeClient.ExportPath(queueClassToOutgoing(ctx), p.(*proto.ThunkPath))
 	if err != nil {
 		return err
 	}
@@ -181,6 +197,12 @@ func (client *Client) Prune(context.Context, bass.PruneOpts) error {
 	return fmt.Errorf("Prune unimplemented")
 }
 
+// Emulate returns the client itself, since a daemon configured to emulate
+// platforms does so on its side.
+func (client *Client) Emulate(bass.Platform) (bass.Runtime, error) {
+	return client, nil
+}
+
 func (client *Client) Close() error {
 	return client.Conn.Close()
 }
@@ -188,9 +210,61 @@ func (client *Client) Close() error {
 type Server struct {
 	bass.Runtime
 
+	// Pool optionally selects the runtime for thunks whose platform the
+	// Runtime doesn't match, e.g. to emulate them.
+	Pool *Pool
+
+	// Drainer optionally tracks in-flight calls for graceful shutdown.
+	Drainer *Drainer
+
+	// Queue optionally limits the concurrency of calls.
+	Queue *Queue
+
+	// runs coalesces identical runs from separate clients.
+	runs bass.RunGroup
+
 	proto.UnimplementedRuntimeServer
 }
 
+// runtime returns the runtime to use for the platform, selecting it from the
+// Pool if it isn't the Runtime's.
+func (srv *Server) runtime(platform *bass.Platform) (bass.Runtime, error) {
+	if srv.Pool == nil || platform == nil || platform.OS == "" {
+		return srv.Runtime, nil
+	}
+
+	return srv.Pool.Select(*platform)
+}
+
+// start waits for the call to be scheduled by the queue, returning the
+// context in which to run it and a func to call once it is done.
+func (srv *Server) start(stream grpc.ServerStream, desc string) (context.Context, func(), error) {
+	ctx, done := srv.Drainer.Start()
+
+	// stop waiting if the client goes away or the drain window elapses
+	waitCtx, cancel := context.WithCancel(stream.Context())
+	go func() {
+		select {
+		case <-ctx.Done():
+			cancel()
+		case <-waitCtx.Done():
+		}
+	}()
+
+	release, err := srv.Queue.Acquire(waitCtx, queueClassFromIncoming(stream.Context()), desc)
+	if err != nil {
+		cancel()
+		done()
+		return nil, nil, err
+	}
+
+	return ctx, func() {
+		release()
+		cancel()
+		done()
+	}, nil
+}
+
 func (srv *Server) Resolve(ctx context.Context, p *proto.ImageRef) (*proto.ImageRef, error) {
 	ref := bass.ImageRef{}
 
@@ -199,7 +273,12 @@ func (srv *Server) Resolve(ctx context.Context, p *proto.ImageRef) (*proto.Image
 		return nil, err
 	}
 
-	r, err := srv.Runtime.Resolve(ctx, ref)
+	runtime, err := srv.runtime(&ref.Platform)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := runtime.Resolve(ctx, ref)
 	if err != nil {
 		return nil, err
 	}
@@ -212,6 +291,32 @@ func (srv *Server) Resolve(ctx context.Context, p *proto.ImageRef) (*proto.Image
 	return ret.(*proto.ImageRef), err
 }
 
+func (srv *Server) ImageConfig(ctx context.Context, p *proto.ImageRef) (*proto.Bytes, error) {
+	ref := bass.ImageRef{}
+
+	err := ref.UnmarshalProto(p)
+	if err != nil {
+		return nil, err
+	}
+
+	runtime, err := srv.runtime(&ref.Platform)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver, ok := runtime.(bass.ImageConfigResolver)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "runtime %T cannot fetch image configs", runtime)
+	}
+
+	config, err := resolver.ImageConfig(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.Bytes{Data: config}, nil
+}
+
 func (srv *Server) Run(p *proto.Thunk, runSrv proto.Runtime_RunServer) error {
 	thunk := bass.Thunk{}
 
@@ -220,10 +325,24 @@ func (srv *Server) Run(p *proto.Thunk, runSrv proto.Runtime_RunServer) error {
 		return err
 	}
 
+	runtime, err := srv.runtime(thunk.Platform())
+	if err != nil {
+		return err
+	}
+
+	ctx, done, err := srv.start(runSrv, thunk.Cmdline())
+	if err != nil {
+		return err
+	}
+
+	defer done()
+
 	recorder := progrock.NewRecorder(runSrvRecorder{runSrv})
-	ctx := progrock.RecorderToContext(context.Background(), recorder)
+	ctx = progrock.RecorderToContext(ctx, recorder)
 
-	return srv.Runtime.Run(ctx, thunk)
+	return srv.runs.Run(ctx, thunk, func() error {
+		return runtime.Run(ctx, thunk)
+	})
 }
 
 func (srv *Server) Read(p *proto.Thunk, readSrv proto.Runtime_ReadServer) error {
@@ -234,10 +353,22 @@ func (srv *Server) Read(p *proto.Thunk, readSrv proto.Runtime_ReadServer) error
 		return err
 	}
 
+	runtime, err := srv.runtime(thunk.Platform())
+	if err != nil {
+		return err
+	}
+
+	ctx, done, err := srv.start(readSrv, thunk.Cmdline())
+	if err != nil {
+		return err
+	}
+
+	defer done()
+
 	recorder := progrock.NewRecorder(readSrvRecorder{readSrv})
-	ctx := progrock.RecorderToContext(context.Background(), recorder)
+	ctx = progrock.RecorderToContext(ctx, recorder)
 
-	return srv.Runtime.Read(ctx, readSrvWriter{readSrv}, thunk)
+	return runtime.Read(ctx, readSrvWriter{readSrv}, thunk)
 }
 
 func (srv *Server) Export(p *proto.Thunk, exportSrv proto.Runtime_ExportServer) error {
@@ -248,8 +379,19 @@ func (srv *Server) Export(p *proto.Thunk, exportSrv proto.Runtime_ExportServer)
 		return err
 	}
 
-	ctx := context.Background()
-	return srv.Runtime.Export(ctx, runSrvBytesWriter{exportSrv}, thunk)
+	runtime, err := srv.runtime(thunk.Platform())
+	if err != nil {
+		return err
+	}
+
+	ctx, done, err := srv.start(exportSrv, thunk.Cmdline())
+	if err != nil {
+		return err
+	}
+
+	defer done()
+
+	return runtime.Export(ctx, runSrvBytesWriter{exportSrv}, thunk)
 }
 
 func (srv *Server) ExportPath(p *proto.ThunkPath, exportSrv proto.Runtime_ExportPathServer) error {
@@ -260,8 +402,19 @@ func (srv *Server) ExportPath(p *proto.ThunkPath, exportSrv proto.Runtime_Export
 		return err
 	}
 
-	ctx := context.Background()
-	return srv.Runtime.ExportPath(ctx, runSrvBytesWriter{exportSrv}, tp)
+	runtime, err := srv.runtime(tp.Thunk.Platform())
+	if err != nil {
+		return err
+	}
+
+	ctx, done, err := srv.start(exportSrv, tp.Thunk.Cmdline())
+	if err != nil {
+		return err
+	}
+
+	defer done()
+
+	return runtime.ExportPath(ctx, runSrvBytesWriter{exportSrv}, tp)
 }
 
 type runSrvRecorder struct {
@@ -0,0 +1,522 @@
+package runtimes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/content/local"
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/platforms"
+	"github.com/containerd/containerd/reference"
+	"github.com/containerd/containerd/remotes"
+	"github.com/containerd/containerd/remotes/docker"
+	dockerconfig "github.com/docker/cli/cli/config"
+	"github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/zapctx"
+	"go.uber.org/zap"
+)
+
+// OCIStoreID is the ID under which the OCI store is passed to a Buildkit
+// solve, referenced by oci-layout:// sources.
+const OCIStoreID = "bass-images"
+
+// lastUsedAnnotation records when an image in the OCI store was last used,
+// for pruning.
+const lastUsedAnnotation = "dev.bass.last-used"
+
+// resolvedAnnotation records the digest that an image's ref resolved to when
+// it was pulled, which may be an index rather than the platform's manifest.
+const resolvedAnnotation = "dev.bass.resolved"
+
+// DefaultOCIStoreDir is the location of the OCI store shared by all runtimes.
+func DefaultOCIStoreDir() string {
+	return filepath.Join(bass.CacheHome, "oci")
+}
+
+// OCIStore is a local OCI image layout shared by runtimes, so that an image
+// pulled by one runtime does not have to be pulled again by another.
+//
+// Blobs are stored by digest. Each pulled image is recorded in the layout's
+// index.json as the manifest for the pulled platform, annotated with its ref
+// and the digest the ref resolved to.
+type OCIStore struct {
+	content.Store
+
+	Dir string
+
+	indexL sync.Mutex
+}
+
+var ociStores = map[string]*OCIStore{}
+var ociStoresL sync.Mutex
+
+// OpenOCIStore opens the OCI store in the given directory, creating it if
+// needed. Stores are shared by all runtimes in the process.
+func OpenOCIStore(dir string) (*OCIStore, error) {
+	ociStoresL.Lock()
+	defer ociStoresL.Unlock()
+
+	if store, found := ociStores[dir]; found {
+		return store, nil
+	}
+
+	cs, err := local.NewStore(dir)
+	if err != nil {
+		return nil, fmt.Errorf("open oci store: %w", err)
+	}
+
+	layout, err := json.Marshal(ocispecs.ImageLayout{
+		Version: ocispecs.ImageLayoutVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.WriteFile(filepath.Join(dir, ocispecs.ImageLayoutFile), layout, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &OCIStore{
+		Store: cs,
+		Dir:   dir,
+	}
+
+	ociStores[dir] = store
+
+	return store, nil
+}
+
+// Pull returns the manifest for the image ref and platform, pulling it into
+// the store if it is not already present.
+//
+// Images are looked up by digest. A ref with a digest is found without
+// touching the registry, while a tag is resolved each time so that the store
+// follows it as it moves. If the registry can't be reached, the image last
+// pulled for the tag is used instead.
+//
+// Registries are requested using the context's bass.NetworkConfig, and
+// authenticated with its bass.CredentialHelper if it has one. The progress of
+// each blob fetched is recorded to the context's progrock.Recorder.
+func (store *OCIStore) Pull(ctx context.Context, ref string, platform ocispecs.Platform) (ocispecs.Descriptor, error) {
+	match := platforms.Only(platform)
+
+	if spec, err := reference.Parse(ref); err == nil && spec.Digest() != "" {
+		found, err := store.lookup(byDigest(spec.Digest()), match)
+		if err != nil {
+			return ocispecs.Descriptor{}, err
+		}
+
+		if found != nil {
+			return *found, store.tag(ref, spec.Digest(), *found)
+		}
+	}
+
+	resolver, err := registryResolver(ctx)
+	if err != nil {
+		return ocispecs.Descriptor{}, err
+	}
+
+	name, desc, err := resolver.Resolve(ctx, ref)
+	if err != nil {
+		found, lookupErr := store.lookup(byRef(ref), match)
+		if lookupErr != nil {
+			return ocispecs.Descriptor{}, lookupErr
+		}
+
+		if found == nil {
+			return ocispecs.Descriptor{}, fmt.Errorf("resolve %s: %w", ref, err)
+		}
+
+		zapctx.FromContext(ctx).Warn("could not resolve image; using last pulled",
+			zap.String("ref", ref),
+			zap.Error(err))
+
+		return *found, store.tag(ref, resolvedDigest(*found), *found)
+	}
+
+	found, err := store.lookup(byDigest(desc.Digest), match)
+	if err != nil {
+		return ocispecs.Descriptor{}, err
+	}
+
+	if found != nil {
+		return *found, store.tag(ref, desc.Digest, *found)
+	}
+
+	progress := newPullProgress(ctx, ref)
+
+	manifest, err := store.fetch(ctx, resolver, name, desc, platform, progress)
+	progress.Done(err)
+	if err != nil {
+		return ocispecs.Descriptor{}, err
+	}
+
+	return manifest, store.tag(ref, desc.Digest, manifest)
+}
+
+func (store *OCIStore) fetch(ctx context.Context, resolver remotes.Resolver, name string, desc ocispecs.Descriptor, platform ocispecs.Platform, progress *pullProgress) (ocispecs.Descriptor, error) {
+	fetcher, err := resolver.Fetcher(ctx, name)
+	if err != nil {
+		return ocispecs.Descriptor{}, err
+	}
+
+	err = images.Dispatch(ctx, images.Handlers(
+		remotes.FetchHandler(progress.Ingester(store), fetcher),
+		images.LimitManifests(images.ChildrenHandler(store), platforms.Only(platform), 1),
+	), nil, desc)
+	if err != nil {
+		return ocispecs.Descriptor{}, fmt.Errorf("pull %s: %w", name, err)
+	}
+
+	return store.platformManifest(ctx, desc, platform)
+}
+
+// registryResolver returns a resolver for image registries, using the
+// context's bass.NetworkConfig and bass.CredentialHelper.
+func registryResolver(ctx context.Context) (remotes.Resolver, error) {
+	network, _ := bass.NetworkConfigFromContext(ctx)
+
+	client, err := network.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return docker.NewResolver(docker.ResolverOptions{
+		Hosts: docker.ConfigureDefaultRegistries(
+			docker.WithClient(client),
+			docker.WithAuthorizer(docker.NewDockerAuthorizer(
+				docker.WithAuthClient(client),
+				docker.WithAuthCreds(registryCreds(ctx)),
+			)),
+		),
+	}), nil
+}
+
+// Config returns the image config for the manifest.
+func (store *OCIStore) Config(ctx context.Context, manifest ocispecs.Descriptor) (ocispecs.Image, error) {
+	var img ocispecs.Image
+
+	config, err := images.Config(ctx, store, manifest, platforms.All)
+	if err != nil {
+		return img, err
+	}
+
+	payload, err := content.ReadBlob(ctx, store, config)
+	if err != nil {
+		return img, err
+	}
+
+	if err := json.Unmarshal(payload, &img); err != nil {
+		return img, fmt.Errorf("unmarshal image config: %w", err)
+	}
+
+	return img, nil
+}
+
+// Prune removes images which were not used within opts.KeepDuration, and the
+// least recently used images until the store is within opts.KeepBytes, and
+// then deletes any blobs no longer referenced. It returns the number of bytes
+// freed.
+func (store *OCIStore) Prune(ctx context.Context, opts bass.PruneOpts) (int64, error) {
+	store.indexL.Lock()
+	defer store.indexL.Unlock()
+
+	index, err := store.readIndex()
+	if err != nil {
+		return 0, err
+	}
+
+	entries := index.Manifests
+
+	// most recently used first
+	sort.SliceStable(entries, func(i, j int) bool {
+		return lastUsed(entries[i]).After(lastUsed(entries[j]))
+	})
+
+	var kept []ocispecs.Descriptor
+	var keptBytes int64
+	for _, entry := range entries {
+		if opts.All {
+			break
+		}
+
+		if opts.KeepDuration > 0 && time.Since(lastUsed(entry)) > opts.KeepDuration {
+			continue
+		}
+
+		size, err := store.imageSize(ctx, entry)
+		if err != nil {
+			return 0, err
+		}
+
+		if opts.KeepBytes > 0 && keptBytes+size > opts.KeepBytes {
+			continue
+		}
+
+		kept = append(kept, entry)
+		keptBytes += size
+	}
+
+	index.Manifests = kept
+
+	if err := store.writeIndex(index); err != nil {
+		return 0, err
+	}
+
+	reachable := map[digest.Digest]bool{}
+	err = images.Walk(ctx, images.Handlers(
+		images.HandlerFunc(func(ctx context.Context, desc ocispecs.Descriptor) ([]ocispecs.Descriptor, error) {
+			reachable[desc.Digest] = true
+			return nil, nil
+		}),
+		images.ChildrenHandler(store),
+	), kept...)
+	if err != nil {
+		return 0, err
+	}
+
+	var unreachable []content.Info
+	err = store.Walk(ctx, func(info content.Info) error {
+		if !reachable[info.Digest] {
+			unreachable = append(unreachable, info)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var freed int64
+	for _, info := range unreachable {
+		if err := store.Delete(ctx, info.Digest); err != nil {
+			return freed, err
+		}
+
+		freed += info.Size
+	}
+
+	return freed, nil
+}
+
+func (store *OCIStore) lookup(matchEntry func(ocispecs.Descriptor) bool, match platforms.Matcher) (*ocispecs.Descriptor, error) {
+	store.indexL.Lock()
+	defer store.indexL.Unlock()
+
+	index, err := store.readIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range index.Manifests {
+		if !matchEntry(entry) {
+			continue
+		}
+
+		if entry.Platform != nil && !match.Match(*entry.Platform) {
+			continue
+		}
+
+		entry := entry
+		return &entry, nil
+	}
+
+	return nil, nil
+}
+
+// byDigest matches entries which were resolved to the digest, either as the
+// platform's manifest or as the index containing it.
+func byDigest(dig digest.Digest) func(ocispecs.Descriptor) bool {
+	return func(entry ocispecs.Descriptor) bool {
+		return entry.Digest == dig || resolvedDigest(entry) == dig
+	}
+}
+
+// byRef matches entries last tagged with the ref.
+func byRef(ref string) func(ocispecs.Descriptor) bool {
+	return func(entry ocispecs.Descriptor) bool {
+		return entry.Annotations[ocispecs.AnnotationRefName] == ref
+	}
+}
+
+// Tag records the manifest as the image for the ref and the manifest's
+// platform, marking it as used now.
+func (store *OCIStore) Tag(ref string, manifest ocispecs.Descriptor) error {
+	return store.tag(ref, manifest.Digest, manifest)
+}
+
+func (store *OCIStore) tag(ref string, resolved digest.Digest, manifest ocispecs.Descriptor) error {
+	store.indexL.Lock()
+	defer store.indexL.Unlock()
+
+	index, err := store.readIndex()
+	if err != nil {
+		return err
+	}
+
+	manifest.Annotations = map[string]string{
+		ocispecs.AnnotationRefName: ref,
+		resolvedAnnotation:         resolved.String(),
+		lastUsedAnnotation:         time.Now().UTC().Format(time.RFC3339),
+	}
+
+	entries := []ocispecs.Descriptor{manifest}
+	for _, entry := range index.Manifests {
+		if entry.Annotations[ocispecs.AnnotationRefName] == ref &&
+			platformKey(entry.Platform) == platformKey(manifest.Platform) {
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	index.Manifests = entries
+
+	return store.writeIndex(index)
+}
+
+func (store *OCIStore) platformManifest(ctx context.Context, desc ocispecs.Descriptor, platform ocispecs.Platform) (ocispecs.Descriptor, error) {
+	if !images.IsIndexType(desc.MediaType) {
+		if desc.Platform == nil {
+			desc.Platform = &platform
+		}
+
+		return desc, nil
+	}
+
+	payload, err := content.ReadBlob(ctx, store, desc)
+	if err != nil {
+		return ocispecs.Descriptor{}, err
+	}
+
+	var index ocispecs.Index
+	if err := json.Unmarshal(payload, &index); err != nil {
+		return ocispecs.Descriptor{}, fmt.Errorf("unmarshal index: %w", err)
+	}
+
+	match := platforms.Only(platform)
+	for _, manifest := range index.Manifests {
+		if manifest.Platform == nil || match.Match(*manifest.Platform) {
+			return store.platformManifest(ctx, manifest, platform)
+		}
+	}
+
+	return ocispecs.Descriptor{}, fmt.Errorf("no manifest for platform %s", platforms.Format(platform))
+}
+
+func (store *OCIStore) imageSize(ctx context.Context, manifest ocispecs.Descriptor) (int64, error) {
+	var size int64
+	err := images.Walk(ctx, images.Handlers(
+		images.HandlerFunc(func(ctx context.Context, desc ocispecs.Descriptor) ([]ocispecs.Descriptor, error) {
+			size += desc.Size
+			return nil, nil
+		}),
+		images.ChildrenHandler(store),
+	), manifest)
+	if err != nil {
+		return 0, err
+	}
+
+	return size, nil
+}
+
+func (store *OCIStore) indexPath() string {
+	return filepath.Join(store.Dir, "index.json")
+}
+
+func (store *OCIStore) readIndex() (ocispecs.Index, error) {
+	index := ocispecs.Index{}
+	index.SchemaVersion = 2
+
+	payload, err := os.ReadFile(store.indexPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return index, nil
+		}
+
+		return index, err
+	}
+
+	if err := json.Unmarshal(payload, &index); err != nil {
+		return index, fmt.Errorf("unmarshal %s: %w", store.indexPath(), err)
+	}
+
+	return index, nil
+}
+
+func (store *OCIStore) writeIndex(index ocispecs.Index) error {
+	payload, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(store.Dir, "index.json.")
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(payload); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), store.indexPath())
+}
+
+func resolvedDigest(desc ocispecs.Descriptor) digest.Digest {
+	if resolved, found := desc.Annotations[resolvedAnnotation]; found {
+		return digest.Digest(resolved)
+	}
+
+	return desc.Digest
+}
+
+func lastUsed(desc ocispecs.Descriptor) time.Time {
+	t, _ := time.Parse(time.RFC3339, desc.Annotations[lastUsedAnnotation])
+	return t
+}
+
+func platformKey(platform *ocispecs.Platform) string {
+	if platform == nil {
+		return ""
+	}
+
+	return platforms.Format(*platform)
+}
+
+// dockerCreds returns the credentials for a registry host from the Docker
+// config file.
+func dockerCreds(host string) (string, string, error) {
+	if host == "registry-1.docker.io" {
+		host = "https://index.docker.io/v1/"
+	}
+
+	config := dockerconfig.LoadDefaultConfigFile(os.Stderr)
+
+	auth, err := config.GetAuthConfig(host)
+	if err != nil {
+		return "", "", err
+	}
+
+	if auth.IdentityToken != "" {
+		return "", auth.IdentityToken, nil
+	}
+
+	return auth.Username, auth.Password, nil
+}
@@ -0,0 +1,271 @@
+package runtimes_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/containerd/containerd/content"
+	"github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/runtimes"
+	"github.com/vito/is"
+	"github.com/vito/progrock"
+	"github.com/vito/progrock/graph"
+)
+
+func TestOCIStore(t *testing.T) {
+	is := is.New(t)
+
+	ctx := context.Background()
+
+	store, err := runtimes.OpenOCIStore(t.TempDir())
+	is.NoErr(err)
+
+	platform := ocispecs.Platform{OS: "linux", Architecture: "amd64"}
+
+	alpine := writeImage(ctx, t, store, platform, "alpine layer", "FOO=bar")
+	ubuntu := writeImage(ctx, t, store, platform, "ubuntu layer")
+
+	is.NoErr(store.Tag("docker.io/library/alpine:latest", alpine))
+	is.NoErr(store.Tag("docker.io/library/ubuntu:latest", ubuntu))
+
+	// already present, so no pull is needed
+	manifest, err := store.Pull(ctx, "docker.io/library/alpine:latest", platform)
+	is.NoErr(err)
+	is.Equal(manifest.Digest, alpine.Digest)
+
+	img, err := store.Config(ctx, manifest)
+	is.NoErr(err)
+	is.Equal(img.Config.Env, []string{"FOO=bar"})
+
+	freed, err := store.Prune(ctx, bass.PruneOpts{KeepDuration: time.Hour})
+	is.NoErr(err)
+	is.Equal(freed, int64(0))
+
+	freed, err = store.Prune(ctx, bass.PruneOpts{All: true})
+	is.NoErr(err)
+	is.True(freed > 0)
+
+	_, err = store.Info(ctx, alpine.Digest)
+	is.True(err != nil)
+}
+
+func TestOCIStorePullProgress(t *testing.T) {
+	is := is.New(t)
+
+	ctx := context.Background()
+
+	platform := ocispecs.Platform{OS: "linux", Architecture: "amd64"}
+
+	upstream, err := runtimes.OpenOCIStore(t.TempDir())
+	is.NoErr(err)
+
+	manifest := writeImage(ctx, t, upstream, platform, "some layer")
+
+	ctx, host := serveRegistry(ctx, t, upstream, manifest)
+
+	statuses := &statusRecorder{}
+	ctx = progrock.RecorderToContext(ctx, progrock.NewRecorder(statuses))
+
+	store, err := runtimes.OpenOCIStore(t.TempDir())
+	is.NoErr(err)
+
+	ref := host + "/test:latest"
+
+	pulled, err := store.Pull(ctx, ref, platform)
+	is.NoErr(err)
+	is.Equal(pulled.Digest, manifest.Digest)
+
+	var vertex *graph.Vertex
+	tasks := map[string]*graph.VertexStatus{}
+	var logs string
+	for _, status := range statuses.statuses {
+		for _, v := range status.Vertexes {
+			vertex = v
+		}
+
+		for _, s := range status.Statuses {
+			tasks[s.ID] = s
+		}
+
+		for _, l := range status.Logs {
+			logs += string(l.Data)
+		}
+	}
+
+	is.Equal(vertex.Name, "pull "+ref)
+	is.True(vertex.Completed != nil)
+	is.Equal(vertex.Error, "")
+	is.True(strings.HasPrefix(logs, "pulled 1 layers ("))
+
+	is.Equal(len(tasks), 3)
+	for id, task := range tasks {
+		is.True(task.Completed != nil)
+		is.Equal(task.Current, task.Total)
+
+		if strings.HasPrefix(id, "layer ") {
+			is.Equal(task.Total, int64(len("some layer")))
+		}
+	}
+}
+
+func TestOCIStoreResolvesTags(t *testing.T) {
+	is := is.New(t)
+
+	ctx := context.Background()
+
+	platform := ocispecs.Platform{OS: "linux", Architecture: "amd64"}
+
+	upstream, err := runtimes.OpenOCIStore(t.TempDir())
+	is.NoErr(err)
+
+	moved := writeImage(ctx, t, upstream, platform, "new layer")
+
+	ctx, host := serveRegistry(ctx, t, upstream, moved)
+
+	store, err := runtimes.OpenOCIStore(t.TempDir())
+	is.NoErr(err)
+
+	ref := host + "/test:latest"
+
+	// the tag was pulled before it moved
+	stale := writeImage(ctx, t, store, platform, "old layer")
+	is.NoErr(store.Tag(ref, stale))
+
+	pulled, err := store.Pull(ctx, ref, platform)
+	is.NoErr(err)
+	is.Equal(pulled.Digest, moved.Digest)
+
+	// pinned refs are found by digest, without asking the registry
+	pinned, err := store.Pull(ctx, host+"/other@"+moved.Digest.String(), platform)
+	is.NoErr(err)
+	is.Equal(pinned.Digest, moved.Digest)
+}
+
+// serveRegistry serves the blobs in the store as the "test" repository of a
+// registry whose manifest is always the given manifest, returning a context
+// which trusts it and its host.
+func serveRegistry(ctx context.Context, t *testing.T, upstream content.Store, manifest ocispecs.Descriptor) (context.Context, string) {
+	is := is.New(t)
+
+	registry := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var desc ocispecs.Descriptor
+		switch {
+		case r.URL.Path == "/v2/":
+			return
+		case strings.HasPrefix(r.URL.Path, "/v2/test/manifests/"):
+			desc = manifest
+		case strings.HasPrefix(r.URL.Path, "/v2/test/blobs/"):
+			desc.Digest = digest.Digest(path.Base(r.URL.Path))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		payload, err := content.ReadBlob(r.Context(), upstream, desc)
+		if err != nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if desc.MediaType != "" {
+			w.Header().Set("Content-Type", desc.MediaType)
+		}
+
+		w.Header().Set("Docker-Content-Digest", desc.Digest.String())
+		w.Header().Set("Content-Length", strconv.Itoa(len(payload)))
+
+		if r.Method != http.MethodHead {
+			w.Write(payload)
+		}
+	}))
+	t.Cleanup(registry.Close)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	is.NoErr(os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: registry.Certificate().Raw,
+	}), 0600))
+
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+
+	ctx = bass.WithNetworkConfig(ctx, bass.NetworkConfig{
+		CACerts: []string{caFile},
+	})
+
+	return ctx, strings.TrimPrefix(registry.URL, "https://")
+}
+
+type statusRecorder struct {
+	statuses []*graph.SolveStatus
+	l        sync.Mutex
+}
+
+func (w *statusRecorder) WriteStatus(status *graph.SolveStatus) {
+	w.l.Lock()
+	w.statuses = append(w.statuses, status)
+	w.l.Unlock()
+}
+
+func (w *statusRecorder) Close() {}
+
+func writeImage(ctx context.Context, t *testing.T, store content.Store, platform ocispecs.Platform, layerContent string, env ...string) ocispecs.Descriptor {
+	return writeLayers(ctx, t, store, platform, [][]byte{[]byte(layerContent)}, env...)
+}
+
+func writeLayers(ctx context.Context, t *testing.T, store content.Store, platform ocispecs.Platform, layerContents [][]byte, env ...string) ocispecs.Descriptor {
+	is := is.New(t)
+
+	write := func(mediaType string, payload []byte) ocispecs.Descriptor {
+		desc := ocispecs.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(payload),
+			Size:      int64(len(payload)),
+		}
+
+		is.NoErr(content.WriteBlob(ctx, store, desc.Digest.String(), bytes.NewReader(payload), desc))
+
+		return desc
+	}
+
+	var layers []ocispecs.Descriptor
+	for _, layerContent := range layerContents {
+		layers = append(layers, write(ocispecs.MediaTypeImageLayer, layerContent))
+	}
+
+	config, err := json.Marshal(ocispecs.Image{
+		OS:           platform.OS,
+		Architecture: platform.Architecture,
+		Config: ocispecs.ImageConfig{
+			Env: env,
+		},
+	})
+	is.NoErr(err)
+
+	manifest := ocispecs.Manifest{
+		MediaType: ocispecs.MediaTypeImageManifest,
+		Config:    write(ocispecs.MediaTypeImageConfig, config),
+		Layers:    layers,
+	}
+	manifest.SchemaVersion = 2
+
+	payload, err := json.Marshal(manifest)
+	is.NoErr(err)
+
+	desc := write(ocispecs.MediaTypeImageManifest, payload)
+	desc.Platform = &platform
+	return desc
+}
@@ -0,0 +1,67 @@
+package runtimes
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/zapctx"
+	"go.uber.org/zap"
+)
+
+// RunPruner is implemented by runtimes which label the resources they create
+// with the ID of the run that created them; see bass.ActiveRun.
+type RunPruner interface {
+	// PruneRuns releases the resources labeled with any of the given run IDs.
+	PruneRuns(ctx context.Context, runIDs []string) error
+}
+
+// PruneOrphans releases the resources left behind by runs on the local
+// machine which exited without cleaning up, e.g. because they crashed or were
+// killed, and forgets the runs once every runtime has released them.
+//
+// Runs are left alone if none of the pool's runtimes is a RunPruner, e.g.
+// when using runtimes served by a daemon, since their resources cannot be
+// released.
+func PruneOrphans(ctx context.Context, pool bass.RuntimePool) error {
+	orphans, err := bass.OrphanedRuns()
+	if err != nil {
+		return fmt.Errorf("list orphaned runs: %w", err)
+	}
+
+	if len(orphans) == 0 {
+		return nil
+	}
+
+	all, err := pool.All()
+	if err != nil {
+		return err
+	}
+
+	var pruners []RunPruner
+	for _, runtime := range all {
+		if pruner, ok := runtime.(RunPruner); ok {
+			pruners = append(pruners, pruner)
+		}
+	}
+
+	if len(pruners) == 0 {
+		return nil
+	}
+
+	zapctx.FromContext(ctx).Debug("pruning orphaned runs", zap.Strings("runs", orphans))
+
+	for _, pruner := range pruners {
+		if err := pruner.PruneRuns(ctx, orphans); err != nil {
+			return fmt.Errorf("prune orphaned runs: %w", err)
+		}
+	}
+
+	for _, id := range orphans {
+		if err := bass.ForgetRun(id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
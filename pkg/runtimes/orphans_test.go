@@ -0,0 +1,77 @@
+package runtimes_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/runtimes"
+	"github.com/vito/is"
+)
+
+type pruningRuntime struct {
+	bass.Runtime
+
+	pruned []string
+}
+
+func (runtime *pruningRuntime) PruneRuns(_ context.Context, ids []string) error {
+	runtime.pruned = append(runtime.pruned, ids...)
+	return nil
+}
+
+func TestPruneOrphans(t *testing.T) {
+	is := is.New(t)
+
+	oldHome := bass.CacheHome
+	bass.CacheHome = t.TempDir()
+	defer func() { bass.CacheHome = oldHome }()
+
+	ctx := context.Background()
+
+	run, err := bass.StartRun()
+	is.NoErr(err)
+	defer run.Finish()
+
+	is.NoErr(os.WriteFile(filepath.Join(bass.RunsDir(), "crashed.lock"), nil, 0600))
+
+	t.Run("without a pruner", func(t *testing.T) {
+		is := is.New(t)
+
+		pool := &runtimes.Pool{
+			Runtimes: []runtimes.Assoc{
+				{Platform: bass.LinuxPlatform, Runtime: emulatingRuntime{}},
+			},
+		}
+
+		is.NoErr(runtimes.PruneOrphans(ctx, pool))
+
+		orphans, err := bass.OrphanedRuns()
+		is.NoErr(err)
+		is.Equal(orphans, []string{"crashed"})
+	})
+
+	t.Run("with a pruner", func(t *testing.T) {
+		is := is.New(t)
+
+		pruner := &pruningRuntime{}
+		pool := &runtimes.Pool{
+			Runtimes: []runtimes.Assoc{
+				{Platform: bass.LinuxPlatform, Runtime: pruner},
+			},
+		}
+
+		is.NoErr(runtimes.PruneOrphans(ctx, pool))
+		is.Equal(pruner.pruned, []string{"crashed"})
+
+		orphans, err := bass.OrphanedRuns()
+		is.NoErr(err)
+		is.Equal(len(orphans), 0)
+
+		// the active run is left alone
+		_, err = os.Stat(filepath.Join(bass.RunsDir(), run.ID+".lock"))
+		is.NoErr(err)
+	})
+}
@@ -3,6 +3,7 @@ package runtimes
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/vito/bass/pkg/bass"
@@ -11,6 +12,13 @@ import (
 // Pool is the full set of platform <-> runtime pairs configured by the user.
 type Pool struct {
 	Runtimes []Assoc
+
+	// Emulate enables falling back to an Emulator runtime for the same OS when
+	// no runtime matches a platform.
+	Emulate bool
+
+	emulated  map[string]bass.Runtime
+	emulatedL sync.Mutex
 }
 
 // Assoc associates a platform to a runtime.
@@ -19,11 +27,24 @@ type Assoc struct {
 	Runtime  bass.Runtime
 }
 
+// Emulator is implemented by runtimes which can run thunks for other
+// architectures through emulation.
+type Emulator interface {
+	// Emulate returns a runtime which runs thunks for the given platform.
+	Emulate(bass.Platform) (bass.Runtime, error)
+}
+
 // NewPool initializes all runtimes in the given configuration.
 func NewPool(ctx context.Context, config *bass.Config) (*Pool, error) {
-	pool := &Pool{}
+	pool := &Pool{
+		Emulate: config.Emulate,
+	}
+
+	network := config.Network
 
 	for _, config := range config.Runtimes {
+		ctx := bass.WithNetworkConfig(ctx, network.Merge(config.Network))
+
 		runtime, err := Init(ctx, config.Runtime, pool, config.Config)
 		if err != nil {
 			return nil, fmt.Errorf("init %s runtime for platform %s: %w", config.Runtime, config.Platform, err)
@@ -46,9 +67,44 @@ func (pool *Pool) Select(platform bass.Platform) (bass.Runtime, error) {
 		}
 	}
 
+	if pool.Emulate {
+		pool.emulatedL.Lock()
+		defer pool.emulatedL.Unlock()
+
+		// reuse emulated runtimes so each platform is only set up once
+		if emu, found := pool.emulated[platform.String()]; found {
+			return emu, nil
+		}
+
+		for _, runtime := range pool.Runtimes {
+			if runtime.Platform.OS != platform.OS {
+				continue
+			}
+
+			emu, ok := runtime.Runtime.(Emulator)
+			if !ok {
+				continue
+			}
+
+			emulated, err := emu.Emulate(platform)
+			if err != nil {
+				return nil, err
+			}
+
+			if pool.emulated == nil {
+				pool.emulated = map[string]bass.Runtime{}
+			}
+
+			pool.emulated[platform.String()] = emulated
+
+			return emulated, nil
+		}
+	}
+
 	return nil, NoRuntimeError{
 		Platform:    platform,
 		AllRuntimes: pool.Runtimes,
+		Emulate:     pool.Emulate,
 	}
 }
 
@@ -62,6 +118,16 @@ func (pool *Pool) All() ([]bass.Runtime, error) {
 	return all, nil
 }
 
+// Platforms returns the platform of each runtime, in order of preference.
+func (pool *Pool) Platforms() []bass.Platform {
+	var platforms []bass.Platform
+	for _, assoc := range pool.Runtimes {
+		platforms = append(platforms, assoc.Platform)
+	}
+
+	return platforms
+}
+
 // Close closes each runtime.
 func (pool *Pool) Close() error {
 	var errs error
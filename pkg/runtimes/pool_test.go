@@ -0,0 +1,66 @@
+package runtimes_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/runtimes"
+	"github.com/vito/is"
+)
+
+type emulatingRuntime struct {
+	bass.Runtime
+
+	Platform bass.Platform
+
+	emulations *int
+}
+
+func (runtime emulatingRuntime) Emulate(platform bass.Platform) (bass.Runtime, error) {
+	*runtime.emulations++
+	return emulatingRuntime{Platform: platform}, nil
+}
+
+func TestPoolEmulate(t *testing.T) {
+	is := is.New(t)
+
+	amd64 := bass.Platform{OS: "linux", Arch: "amd64"}
+	arm64 := bass.Platform{OS: "linux", Arch: "arm64"}
+	darwin := bass.Platform{OS: "darwin", Arch: "arm64"}
+
+	var emulations int
+	native := emulatingRuntime{Platform: amd64, emulations: &emulations}
+
+	pool := &runtimes.Pool{
+		Runtimes: []runtimes.Assoc{
+			{
+				Platform: amd64,
+				Runtime:  native,
+			},
+		},
+	}
+
+	runtime, err := pool.Select(amd64)
+	is.NoErr(err)
+	is.Equal(runtime, native)
+
+	_, err = pool.Select(arm64)
+	var noRuntime runtimes.NoRuntimeError
+	is.True(errors.As(err, &noRuntime))
+
+	pool.Emulate = true
+
+	runtime, err = pool.Select(arm64)
+	is.NoErr(err)
+	is.Equal(runtime, emulatingRuntime{Platform: arm64})
+
+	// the emulated runtime is reused
+	runtime, err = pool.Select(arm64)
+	is.NoErr(err)
+	is.Equal(runtime, emulatingRuntime{Platform: arm64})
+	is.Equal(emulations, 1)
+
+	_, err = pool.Select(darwin)
+	is.True(errors.As(err, &noRuntime))
+}
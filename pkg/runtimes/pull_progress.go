@@ -0,0 +1,238 @@
+package runtimes
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/images"
+	"github.com/opencontainers/go-digest"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/vito/progrock"
+	"github.com/vito/progrock/graph"
+)
+
+// pullProgressInterval limits how often the progress of each blob is
+// recorded while it is being fetched.
+const pullProgressInterval = 100 * time.Millisecond
+
+// pullProgress records the progress of a pull as a vertex with a task for
+// each blob fetched, so that large pulls are not silent.
+//
+// Unlike progrock.VertexRecorder, it is safe for concurrent use, since blobs
+// are fetched concurrently.
+type pullProgress struct {
+	recorder *progrock.Recorder
+	vertex   *graph.Vertex
+
+	blobs  map[digest.Digest]*blobProgress
+	layers int
+	bytes  int64
+	l      sync.Mutex
+}
+
+type blobProgress struct {
+	status *graph.VertexStatus
+	synced time.Time
+}
+
+func newPullProgress(ctx context.Context, ref string) *pullProgress {
+	now := time.Now()
+
+	progress := &pullProgress{
+		recorder: progrock.RecorderFromContext(ctx),
+		vertex: &graph.Vertex{
+			Digest:  digest.FromString("pull " + ref),
+			Name:    "pull " + ref,
+			Started: &now,
+		},
+		blobs: map[digest.Digest]*blobProgress{},
+	}
+
+	progress.recorder.Record(&graph.SolveStatus{
+		Vertexes: []*graph.Vertex{progress.vertex},
+	})
+
+	return progress
+}
+
+// Ingester wraps the ingester so that blobs written to it are recorded.
+func (progress *pullProgress) Ingester(ingester content.Ingester) content.Ingester {
+	return pullIngester{
+		Ingester: ingester,
+		progress: progress,
+	}
+}
+
+// Done marks the pull as completed, logging the number of layers and bytes
+// fetched.
+func (progress *pullProgress) Done(err error) {
+	progress.l.Lock()
+	defer progress.l.Unlock()
+
+	now := time.Now()
+	progress.vertex.Completed = &now
+
+	if err != nil {
+		progress.vertex.Error = err.Error()
+	}
+
+	status := &graph.SolveStatus{
+		Vertexes: []*graph.Vertex{progress.vertex},
+	}
+
+	if err == nil {
+		status.Logs = []*graph.VertexLog{
+			{
+				Vertex:    progress.vertex.Digest,
+				Stream:    2,
+				Data:      []byte(fmt.Sprintf("pulled %d layers (%d bytes)\n", progress.layers, progress.bytes)),
+				Timestamp: now,
+			},
+		}
+	}
+
+	progress.recorder.Record(status)
+}
+
+func (progress *pullProgress) start(desc ocispecs.Descriptor) {
+	progress.l.Lock()
+	defer progress.l.Unlock()
+
+	now := time.Now()
+
+	blob := &blobProgress{
+		status: &graph.VertexStatus{
+			ID:        blobTask(desc),
+			Vertex:    progress.vertex.Digest,
+			Name:      blobTask(desc),
+			Total:     desc.Size,
+			Timestamp: now,
+			Started:   &now,
+		},
+		synced: now,
+	}
+
+	progress.blobs[desc.Digest] = blob
+
+	progress.record(blob)
+}
+
+func (progress *pullProgress) advance(desc ocispecs.Descriptor, n int64) {
+	progress.l.Lock()
+	defer progress.l.Unlock()
+
+	blob, found := progress.blobs[desc.Digest]
+	if !found {
+		return
+	}
+
+	blob.status.Current += n
+
+	now := time.Now()
+	if now.Sub(blob.synced) < pullProgressInterval {
+		return
+	}
+
+	blob.synced = now
+	blob.status.Timestamp = now
+
+	progress.record(blob)
+}
+
+func (progress *pullProgress) complete(desc ocispecs.Descriptor, err error) {
+	progress.l.Lock()
+	defer progress.l.Unlock()
+
+	blob, found := progress.blobs[desc.Digest]
+	if !found {
+		return
+	}
+
+	if err == nil {
+		if images.IsLayerType(desc.MediaType) {
+			progress.layers++
+		}
+
+		progress.bytes += blob.status.Current
+	}
+
+	now := time.Now()
+	blob.status.Timestamp = now
+	blob.status.Completed = &now
+
+	progress.record(blob)
+}
+
+func (progress *pullProgress) record(blob *blobProgress) {
+	progress.recorder.Record(&graph.SolveStatus{
+		Statuses: []*graph.VertexStatus{blob.status},
+	})
+}
+
+// blobTask returns the name of the task for fetching a blob, e.g.
+// "layer 0123456789ab".
+func blobTask(desc ocispecs.Descriptor) string {
+	kind := "manifest"
+	if images.IsLayerType(desc.MediaType) {
+		kind = "layer"
+	} else if images.IsConfigType(desc.MediaType) {
+		kind = "config"
+	}
+
+	id := desc.Digest.Encoded()
+	if len(id) > 12 {
+		id = id[:12]
+	}
+
+	return kind + " " + id
+}
+
+type pullIngester struct {
+	content.Ingester
+
+	progress *pullProgress
+}
+
+func (ingester pullIngester) Writer(ctx context.Context, opts ...content.WriterOpt) (content.Writer, error) {
+	var wOpts content.WriterOpts
+	for _, opt := range opts {
+		if err := opt(&wOpts); err != nil {
+			return nil, err
+		}
+	}
+
+	w, err := ingester.Ingester.Writer(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	ingester.progress.start(wOpts.Desc)
+
+	return &pullWriter{
+		Writer:   w,
+		desc:     wOpts.Desc,
+		progress: ingester.progress,
+	}, nil
+}
+
+type pullWriter struct {
+	content.Writer
+
+	desc     ocispecs.Descriptor
+	progress *pullProgress
+}
+
+func (w *pullWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	w.progress.advance(w.desc, int64(n))
+	return n, err
+}
+
+func (w *pullWriter) Commit(ctx context.Context, size int64, expected digest.Digest, opts ...content.Opt) error {
+	err := w.Writer.Commit(ctx, size, expected, opts...)
+	w.progress.complete(w.desc, err)
+	return err
+}
@@ -0,0 +1,208 @@
+package runtimes
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/vito/bass/pkg/bass"
+	"google.golang.org/grpc/metadata"
+)
+
+// DefaultQueueClass is the class of runs which do not declare one.
+const DefaultQueueClass = "default"
+
+// classMetadataKey is the gRPC metadata key through which clients declare the
+// class of their runs.
+const classMetadataKey = "bass-class"
+
+type classKey struct{}
+
+// WithQueueClass declares the queue class of runs submitted to a daemon with
+// the returned context.
+func WithQueueClass(ctx context.Context, class string) context.Context {
+	return context.WithValue(ctx, classKey{}, class)
+}
+
+// queueClassToOutgoing forwards the queue class from the context to the
+// daemon as gRPC metadata.
+func queueClassToOutgoing(ctx context.Context) context.Context {
+	class, ok := ctx.Value(classKey{}).(string)
+	if !ok || class == "" {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, classMetadataKey, class)
+}
+
+// queueClassFromIncoming returns the queue class declared by a client.
+func queueClassFromIncoming(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if ok {
+		if vals := md.Get(classMetadataKey); len(vals) > 0 && vals[0] != "" {
+			return vals[0]
+		}
+	}
+
+	return DefaultQueueClass
+}
+
+// QueueJob is a run which is either running or waiting in a Queue.
+type QueueJob struct {
+	ID          int       `json:"id"`
+	Class       string    `json:"class"`
+	Description string    `json:"description"`
+	Running     bool      `json:"running"`
+	Since       time.Time `json:"since"`
+}
+
+// Queue limits the number of concurrent runs, overall and in each class,
+// running waiting runs from higher priority classes first.
+//
+// Classes which are not configured are only limited by the overall
+// concurrency.
+type Queue struct {
+	config bass.QueueConfig
+
+	mu      sync.Mutex
+	nextID  int
+	total   int
+	running map[string]int
+	jobs    []*queued
+}
+
+type queued struct {
+	QueueJob
+
+	ready chan struct{}
+}
+
+// NewQueue returns a queue which limits runs as configured.
+func NewQueue(config bass.QueueConfig) *Queue {
+	return &Queue{
+		config:  config,
+		running: map[string]int{},
+	}
+}
+
+// Acquire waits until a run in the given class may start. The returned func
+// must be called once the run is done.
+//
+// A nil Queue runs everything immediately.
+func (queue *Queue) Acquire(ctx context.Context, class, desc string) (func(), error) {
+	if queue == nil {
+		return func() {}, nil
+	}
+
+	queue.mu.Lock()
+	queue.nextID++
+	job := &queued{
+		QueueJob: QueueJob{
+			ID:          queue.nextID,
+			Class:       class,
+			Description: desc,
+			Since:       time.Now(),
+		},
+		ready: make(chan struct{}),
+	}
+	queue.jobs = append(queue.jobs, job)
+	queue.schedule()
+	queue.mu.Unlock()
+
+	select {
+	case <-job.ready:
+		return func() { queue.done(job) }, nil
+	case <-ctx.Done():
+		queue.mu.Lock()
+		defer queue.mu.Unlock()
+
+		if job.Running {
+			// started just as the context was canceled
+			queue.release(job)
+		} else {
+			queue.remove(job)
+		}
+
+		return nil, ctx.Err()
+	}
+}
+
+// Jobs returns all running and waiting jobs, running jobs first.
+func (queue *Queue) Jobs() []QueueJob {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	jobs := make([]QueueJob, len(queue.jobs))
+	for i, job := range queue.jobs {
+		jobs[i] = job.QueueJob
+	}
+
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[i].Running && !jobs[j].Running
+	})
+
+	return jobs
+}
+
+func (queue *Queue) done(job *queued) {
+	queue.mu.Lock()
+	defer queue.mu.Unlock()
+
+	queue.release(job)
+}
+
+// release frees the job's slots and schedules waiting jobs.
+//
+// Must be called with the lock held.
+func (queue *Queue) release(job *queued) {
+	queue.remove(job)
+	queue.running[job.Class]--
+	queue.total--
+	queue.schedule()
+}
+
+func (queue *Queue) remove(job *queued) {
+	for i, j := range queue.jobs {
+		if j == job {
+			queue.jobs = append(queue.jobs[:i], queue.jobs[i+1:]...)
+			return
+		}
+	}
+}
+
+// schedule starts waiting jobs for each class with capacity, highest priority
+// classes first, and in order of submission within each class.
+//
+// Must be called with the lock held.
+func (queue *Queue) schedule() {
+	waiting := []*queued{}
+	for _, job := range queue.jobs {
+		if !job.Running {
+			waiting = append(waiting, job)
+		}
+	}
+
+	classes := queue.config.Classes
+
+	sort.SliceStable(waiting, func(i, j int) bool {
+		return classes[waiting[i].Class].Priority > classes[waiting[j].Class].Priority
+	})
+
+	for _, job := range waiting {
+		if queue.config.Concurrency > 0 && queue.total >= queue.config.Concurrency {
+			return
+		}
+
+		class := classes[job.Class]
+		if class.Concurrency > 0 && queue.running[job.Class] >= class.Concurrency {
+			continue
+		}
+
+		queue.total++
+		queue.running[job.Class]++
+		job.Running = true
+		job.Since = time.Now()
+		close(job.ready)
+	}
+}
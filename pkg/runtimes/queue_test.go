@@ -0,0 +1,121 @@
+package runtimes_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/runtimes"
+	"github.com/vito/is"
+)
+
+func TestQueue(t *testing.T) {
+	t.Run("class concurrency", func(t *testing.T) {
+		is := is.New(t)
+
+		queue := runtimes.NewQueue(bass.QueueConfig{
+			Classes: map[string]bass.QueueClass{
+				"builds": {Concurrency: 1},
+			},
+		})
+
+		ctx := context.Background()
+
+		releaseBuild, err := queue.Acquire(ctx, "builds", "build 1")
+		is.NoErr(err)
+
+		waited := make(chan func())
+		go func() {
+			release, err := queue.Acquire(ctx, "builds", "build 2")
+			is.NoErr(err)
+			waited <- release
+		}()
+
+		// other classes are not limited
+		releaseTest, err := queue.Acquire(ctx, "tests", "test")
+		is.NoErr(err)
+
+		waitForJobs(t, queue, 3)
+
+		jobs := queue.Jobs()
+		is.Equal(jobs[0].Description, "build 1")
+		is.True(jobs[0].Running)
+		is.Equal(jobs[1].Description, "test")
+		is.True(jobs[1].Running)
+		is.Equal(jobs[2].Description, "build 2")
+		is.True(!jobs[2].Running)
+
+		releaseBuild()
+		(<-waited)()
+		releaseTest()
+
+		is.Equal(len(queue.Jobs()), 0)
+	})
+
+	t.Run("priority", func(t *testing.T) {
+		is := is.New(t)
+
+		queue := runtimes.NewQueue(bass.QueueConfig{
+			Concurrency: 1,
+			Classes: map[string]bass.QueueClass{
+				"quick": {Priority: 1},
+			},
+		})
+
+		ctx := context.Background()
+
+		release, err := queue.Acquire(ctx, "heavy", "heavy 1")
+		is.NoErr(err)
+
+		started := make(chan string, 2)
+		for i, job := range []struct{ class, desc string }{
+			{"heavy", "heavy 2"},
+			{"quick", "quick"},
+		} {
+			job := job
+			go func() {
+				release, err := queue.Acquire(ctx, job.class, job.desc)
+				is.NoErr(err)
+				started <- job.desc
+				release()
+			}()
+
+			// heavy 1 plus each job submitted so far
+			waitForJobs(t, queue, i+2)
+		}
+
+		release()
+
+		is.Equal(<-started, "quick")
+		is.Equal(<-started, "heavy 2")
+	})
+
+	t.Run("canceled while waiting", func(t *testing.T) {
+		is := is.New(t)
+
+		queue := runtimes.NewQueue(bass.QueueConfig{Concurrency: 1})
+
+		release, err := queue.Acquire(context.Background(), "a", "first")
+		is.NoErr(err)
+		defer release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err = queue.Acquire(ctx, "a", "second")
+		is.Equal(err, context.DeadlineExceeded)
+		is.Equal(len(queue.Jobs()), 1)
+	})
+}
+
+func waitForJobs(t *testing.T, queue *runtimes.Queue, n int) {
+	deadline := time.Now().Add(time.Second)
+	for len(queue.Jobs()) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %d jobs", n)
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,230 @@
+package runtimes
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/containerd/containerd/archive/compression"
+	"github.com/containerd/containerd/content"
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/vito/bass/pkg/bass"
+)
+
+// whiteoutPrefix marks a file in an image layer which deletes the file of the
+// same name from lower layers.
+const whiteoutPrefix = ".wh."
+
+// whiteoutOpaque marks a directory in an image layer whose contents in lower
+// layers are deleted.
+const whiteoutOpaque = whiteoutPrefix + whiteoutPrefix + ".opq"
+
+// ScriptFetcher fetches remote scripts by pulling their images into an OCI
+// store and extracting the script's directory to bass.CacheHome.
+type ScriptFetcher struct {
+	Store *OCIStore
+}
+
+var _ bass.ScriptFetcher = ScriptFetcher{}
+
+// ResolveScript resolves the script's image tag to a digest.
+func (fetcher ScriptFetcher) ResolveScript(ctx context.Context, script bass.RemoteScript) (string, error) {
+	resolver, err := registryResolver(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	_, desc, err := resolver.Resolve(ctx, script.Ref())
+	if err != nil {
+		return "", err
+	}
+
+	return desc.Digest.String(), nil
+}
+
+// FetchScript extracts the script's directory from its image, returning the
+// path of the script on the host.
+//
+// The directory is extracted once per image digest and directory, and reused
+// by later runs. For multi-platform images, the Linux image for the host's
+// architecture is used.
+func (fetcher ScriptFetcher) FetchScript(ctx context.Context, script bass.RemoteScript) (string, error) {
+	if script.Digest == "" {
+		return "", fmt.Errorf("remote script must be pinned to a digest: %s", script)
+	}
+
+	scriptDir := path.Dir(script.Path)
+	key := sha256.Sum256([]byte(script.Digest + "\x00" + scriptDir))
+
+	dir := filepath.Join(bass.RemoteScriptsDir(), fmt.Sprintf("%x", key))
+	scriptPath := filepath.Join(dir, path.Base(script.Path))
+
+	if _, err := os.Stat(dir); err == nil {
+		return scriptPath, checkScript(scriptPath, script)
+	}
+
+	manifestDesc, err := fetcher.Store.Pull(ctx, script.Ref(), ocispecs.Platform{
+		OS:           "linux",
+		Architecture: runtime.GOARCH,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := content.ReadBlob(ctx, fetcher.Store, manifestDesc)
+	if err != nil {
+		return "", err
+	}
+
+	var manifest ocispecs.Manifest
+	if err := json.Unmarshal(payload, &manifest); err != nil {
+		return "", fmt.Errorf("unmarshal manifest: %w", err)
+	}
+
+	tmp := dir + ".tmp"
+	if err := os.RemoveAll(tmp); err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(tmp, 0755); err != nil {
+		return "", err
+	}
+
+	for _, layer := range manifest.Layers {
+		if err := fetcher.extractLayer(ctx, layer, scriptDir, tmp); err != nil {
+			_ = os.RemoveAll(tmp)
+			return "", fmt.Errorf("extract layer %s: %w", layer.Digest, err)
+		}
+	}
+
+	if err := os.Rename(tmp, dir); err != nil {
+		return "", err
+	}
+
+	return scriptPath, checkScript(scriptPath, script)
+}
+
+func checkScript(scriptPath string, script bass.RemoteScript) error {
+	if _, err := os.Stat(scriptPath); err != nil {
+		return fmt.Errorf("script not found in image: %s", script.Path)
+	}
+
+	return nil
+}
+
+// extractLayer applies the files under srcDir in the layer to destDir,
+// including any deletions.
+func (fetcher ScriptFetcher) extractLayer(ctx context.Context, layer ocispecs.Descriptor, srcDir, destDir string) error {
+	ra, err := fetcher.Store.ReaderAt(ctx, layer)
+	if err != nil {
+		return err
+	}
+
+	defer ra.Close()
+
+	stream, err := compression.DecompressStream(content.NewReader(ra))
+	if err != nil {
+		return err
+	}
+
+	defer stream.Close()
+
+	tr := tar.NewReader(stream)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		name := path.Clean("/" + hdr.Name)
+		if name != srcDir && !strings.HasPrefix(name, strings.TrimSuffix(srcDir, "/")+"/") {
+			continue
+		}
+
+		rel := strings.TrimPrefix(strings.TrimPrefix(name, srcDir), "/")
+		dest := filepath.Join(destDir, filepath.FromSlash(rel))
+
+		base := path.Base(name)
+		if base == whiteoutOpaque {
+			if err := clearDir(filepath.Dir(dest)); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if strings.HasPrefix(base, whiteoutPrefix) {
+			deleted := filepath.Join(filepath.Dir(dest), strings.TrimPrefix(base, whiteoutPrefix))
+			if err := os.RemoveAll(deleted); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(dest, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+				return err
+			}
+
+			// replace rather than write through any file from a lower layer
+			if err := os.RemoveAll(dest); err != nil {
+				return err
+			}
+
+			file, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, hdr.FileInfo().Mode().Perm()|0600)
+			if err != nil {
+				return err
+			}
+
+			_, err = io.Copy(file, tr)
+			if cerr := file.Close(); err == nil {
+				err = cerr
+			}
+
+			if err != nil {
+				return err
+			}
+		default:
+			// NB: links and devices are skipped; a script's directory should only
+			// need regular files
+		}
+	}
+}
+
+func clearDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
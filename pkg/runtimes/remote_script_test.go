@@ -0,0 +1,115 @@
+package runtimes_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/runtimes"
+	"github.com/vito/is"
+)
+
+func TestScriptFetcher(t *testing.T) {
+	is := is.New(t)
+
+	ctx := context.Background()
+
+	platform := ocispecs.Platform{OS: "linux", Architecture: runtime.GOARCH}
+
+	upstream, err := runtimes.OpenOCIStore(t.TempDir())
+	is.NoErr(err)
+
+	manifest := writeLayers(ctx, t, upstream, platform, [][]byte{
+		tarLayer(t, map[string]string{
+			"ci/main.bass":     "(defn main [] :old)",
+			"ci/old.bass":      "(def old true)",
+			"ci/lib/util.bass": "(def util true)",
+			"etc/passwd":       "root:x:0:0",
+		}),
+		tarLayer(t, map[string]string{
+			"ci/main.bass":    "(defn main [] :new)",
+			"ci/.wh.old.bass": "",
+		}),
+	})
+
+	ctx, host := serveRegistry(ctx, t, upstream, manifest)
+
+	store, err := runtimes.OpenOCIStore(t.TempDir())
+	is.NoErr(err)
+
+	fetcher := runtimes.ScriptFetcher{Store: store}
+
+	script, err := bass.ParseRemoteScript("oci://" + host + "/test:v1#/ci/main.bass")
+	is.NoErr(err)
+
+	dig, err := fetcher.ResolveScript(ctx, script)
+	is.NoErr(err)
+	is.Equal(dig, manifest.Digest.String())
+
+	_, err = fetcher.FetchScript(ctx, script)
+	is.True(err != nil) // not pinned
+
+	script.Digest = dig
+
+	oldCacheHome := bass.CacheHome
+	bass.CacheHome = t.TempDir()
+	defer func() { bass.CacheHome = oldCacheHome }()
+
+	scriptPath, err := fetcher.FetchScript(ctx, script)
+	is.NoErr(err)
+	is.Equal(filepath.Base(scriptPath), "main.bass")
+
+	content, err := os.ReadFile(scriptPath)
+	is.NoErr(err)
+	is.Equal(string(content), "(defn main [] :new)")
+
+	dir := filepath.Dir(scriptPath)
+
+	content, err = os.ReadFile(filepath.Join(dir, "lib", "util.bass"))
+	is.NoErr(err)
+	is.Equal(string(content), "(def util true)")
+
+	_, err = os.Stat(filepath.Join(dir, "old.bass"))
+	is.True(os.IsNotExist(err))
+
+	_, err = os.Stat(filepath.Join(dir, "..", "etc"))
+	is.True(os.IsNotExist(err))
+
+	// fetched again from the cache
+	cached, err := fetcher.FetchScript(ctx, script)
+	is.NoErr(err)
+	is.Equal(cached, scriptPath)
+
+	script.Path = "/ci/missing.bass"
+	_, err = fetcher.FetchScript(ctx, script)
+	is.True(err != nil)
+}
+
+func tarLayer(t *testing.T, files map[string]string) []byte {
+	is := is.New(t)
+
+	buf := new(bytes.Buffer)
+	tw := tar.NewWriter(buf)
+
+	for name, content := range files {
+		is.NoErr(tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(content)),
+		}))
+
+		_, err := tw.Write([]byte(content))
+		is.NoErr(err)
+	}
+
+	is.NoErr(tw.Close())
+
+	return buf.Bytes()
+}
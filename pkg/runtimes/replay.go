@@ -0,0 +1,271 @@
+package runtimes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/vito/bass/pkg/bass"
+)
+
+// ReplayName is the name of the replay runtime.
+const ReplayName = "replay"
+
+// ReplayConfig configures a Replay runtime.
+type ReplayConfig struct {
+	// Dir is the directory containing the fixture files.
+	Dir string `json:"dir"`
+
+	// Record is the name of a runtime whose responses are recorded to Dir. If
+	// empty, responses are replayed from Dir instead.
+	Record string `json:"record,omitempty"`
+
+	// Config is the config for the Record runtime.
+	Config *bass.Scope `json:"config,omitempty"`
+}
+
+func init() {
+	RegisterRuntime(ReplayName, NewReplay)
+}
+
+// Replay is a runtime which records the responses of another runtime to
+// fixture files and replays them later, e.g. for fast and deterministic test
+// suites for scripts which orchestrate many thunks.
+//
+// Responses are recorded per thunk, keyed by its hash. Note that a thunk's
+// hash does not cover the content of the host paths it uses, so fixtures need
+// to be re-recorded when they change.
+type Replay struct {
+	// Dir is the directory containing the fixture files.
+	Dir string
+
+	// Runtime is the runtime whose responses are recorded. If nil, responses
+	// are replayed from Dir, and thunks with no recorded response fail with
+	// ReplayMissingError.
+	Runtime bass.Runtime
+}
+
+var _ bass.Runtime = &Replay{}
+
+// NewReplay initializes a Replay runtime from a ReplayConfig.
+func NewReplay(ctx context.Context, pool bass.RuntimePool, cfg *bass.Scope) (bass.Runtime, error) {
+	var config ReplayConfig
+	if cfg != nil {
+		if err := cfg.Decode(&config); err != nil {
+			return nil, fmt.Errorf("replay runtime config: %w", err)
+		}
+	}
+
+	if config.Dir == "" {
+		return nil, fmt.Errorf("replay runtime config: dir must be set")
+	}
+
+	replay := &Replay{
+		Dir: config.Dir,
+	}
+
+	if config.Record != "" {
+		runtime, err := Init(ctx, config.Record, pool, config.Config)
+		if err != nil {
+			return nil, fmt.Errorf("init %s runtime to record: %w", config.Record, err)
+		}
+
+		replay.Runtime = runtime
+	}
+
+	return replay, nil
+}
+
+// ReplayMissingError is returned by a Replay runtime when a thunk has no
+// recorded response.
+type ReplayMissingError struct {
+	Thunk bass.Thunk
+	Op    string
+}
+
+func (err ReplayMissingError) Error() string {
+	return fmt.Sprintf("no recorded %s response for thunk: %s", err.Op, err.Thunk.Cmdline())
+}
+
+// replayResponse is the metadata of a recorded response, stored alongside its
+// output.
+type replayResponse struct {
+	// Cmdline is the thunk's command line, for humans.
+	Cmdline string `json:"cmdline"`
+
+	// Error is the error returned by the runtime, if any.
+	Error string `json:"error,omitempty"`
+}
+
+func (replay *Replay) Resolve(ctx context.Context, ref bass.ImageRef) (bass.ImageRef, error) {
+	key, err := json.Marshal(ref)
+	if err != nil {
+		return bass.ImageRef{}, err
+	}
+
+	fixture := filepath.Join(replay.Dir, "resolve", digest.FromBytes(key).Encoded()+".json")
+
+	if replay.Runtime != nil {
+		resolved, err := replay.Runtime.Resolve(ctx, ref)
+		if err != nil {
+			return bass.ImageRef{}, err
+		}
+
+		payload, err := json.Marshal(resolved)
+		if err != nil {
+			return bass.ImageRef{}, err
+		}
+
+		if err := writeFixture(fixture, payload); err != nil {
+			return bass.ImageRef{}, err
+		}
+
+		return resolved, nil
+	}
+
+	payload, err := os.ReadFile(fixture)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return bass.ImageRef{}, fmt.Errorf("no recorded resolve response for image: %s", string(key))
+		}
+
+		return bass.ImageRef{}, err
+	}
+
+	var resolved bass.ImageRef
+	if err := json.Unmarshal(payload, &resolved); err != nil {
+		return bass.ImageRef{}, fmt.Errorf("decode resolve fixture: %w", err)
+	}
+
+	return resolved, nil
+}
+
+func (replay *Replay) Run(ctx context.Context, thunk bass.Thunk) error {
+	return replay.respond(thunk, "run", "run", io.Discard, func(io.Writer) error {
+		return replay.Runtime.Run(ctx, thunk)
+	})
+}
+
+func (replay *Replay) Read(ctx context.Context, w io.Writer, thunk bass.Thunk) error {
+	return replay.respond(thunk, "read", "read", w, func(w io.Writer) error {
+		return replay.Runtime.Read(ctx, w, thunk)
+	})
+}
+
+func (replay *Replay) Export(ctx context.Context, w io.Writer, thunk bass.Thunk) error {
+	return replay.respond(thunk, "export", "export", w, func(w io.Writer) error {
+		return replay.Runtime.Export(ctx, w, thunk)
+	})
+}
+
+func (replay *Replay) ExportPath(ctx context.Context, w io.Writer, tp bass.ThunkPath) error {
+	key := "path-" + digest.FromString(tp.Path.Slash()).Encoded()
+	return replay.respond(tp.Thunk, "export "+tp.Path.Slash(), key, w, func(w io.Writer) error {
+		return replay.Runtime.ExportPath(ctx, w, tp)
+	})
+}
+
+// Prune prunes the recorded runtime, if any. Fixtures are left alone.
+func (replay *Replay) Prune(ctx context.Context, opts bass.PruneOpts) error {
+	if replay.Runtime != nil {
+		return replay.Runtime.Prune(ctx, opts)
+	}
+
+	return nil
+}
+
+func (replay *Replay) Close() error {
+	if replay.Runtime != nil {
+		return replay.Runtime.Close()
+	}
+
+	return nil
+}
+
+// respond either records the response of the given operation for the thunk,
+// or replays it, writing its output to w. The response is stored in fixture
+// files named after the key.
+func (replay *Replay) respond(thunk bass.Thunk, op, key string, w io.Writer, record func(io.Writer) error) error {
+	hash, err := thunk.Hash()
+	if err != nil {
+		return err
+	}
+
+	fixture := filepath.Join(replay.Dir, hash, key)
+
+	if replay.Runtime != nil {
+		buf := new(bytes.Buffer)
+		runErr := record(io.MultiWriter(w, buf))
+
+		res := replayResponse{
+			Cmdline: thunk.Cmdline(),
+		}
+
+		if runErr != nil {
+			res.Error = runErr.Error()
+		}
+
+		payload, err := json.Marshal(res)
+		if err != nil {
+			return err
+		}
+
+		if err := writeFixture(fixture+".json", payload); err != nil {
+			return err
+		}
+
+		if err := writeFixture(fixture+".out", buf.Bytes()); err != nil {
+			return err
+		}
+
+		return runErr
+	}
+
+	payload, err := os.ReadFile(fixture + ".json")
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ReplayMissingError{
+				Thunk: thunk,
+				Op:    op,
+			}
+		}
+
+		return err
+	}
+
+	var res replayResponse
+	if err := json.Unmarshal(payload, &res); err != nil {
+		return fmt.Errorf("decode %s fixture: %w", op, err)
+	}
+
+	out, err := os.Open(fixture + ".out")
+	if err != nil {
+		return err
+	}
+
+	defer out.Close()
+
+	if _, err := io.Copy(w, out); err != nil {
+		return err
+	}
+
+	if res.Error != "" {
+		return errors.New(res.Error)
+	}
+
+	return nil
+}
+
+func writeFixture(path string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, content, 0644)
+}
@@ -0,0 +1,66 @@
+package runtimes_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/vito/bass/pkg/bass"
+	"github.com/vito/bass/pkg/runtimes"
+	"github.com/vito/bass/pkg/runtimes/fake"
+	"github.com/vito/is"
+)
+
+func TestReplay(t *testing.T) {
+	is := is.New(t)
+
+	dir := t.TempDir()
+
+	recorded := fake.NewRuntime()
+	recorded.StubCmdline("echo hello", fake.Result{
+		Stdout: []byte(`"hello"`),
+	})
+	recorded.StubCmdline("build", fake.Result{
+		Files: fstest.MapFS{
+			"VERSION": {Data: []byte("1.2.3")},
+		},
+	})
+	recorded.StubCmdline("exit 1", fake.Result{
+		Err: errors.New("exit status 1"),
+	})
+
+	script := `
+		(def image {:platform {:os "linux"} :repository "alpine" :tag "latest"})
+		(def built (from image ($ build)))
+		[(next (read (from image ($ echo hello)) :json))
+		 (next (read built/VERSION :raw))
+		 (succeeds? (from image ($ exit 1)))]
+	`
+
+	eval := func(runtime bass.Runtime, src string) (bass.Value, error) {
+		ctx := fake.WithRuntime(context.Background(), runtime)
+		return bass.EvalFSFile(ctx, bass.NewStandardScope(), bass.NewInMemoryFile("test", src))
+	}
+
+	expected := bass.NewList(bass.String("hello"), bass.String("1.2.3"), bass.Bool(false))
+
+	res, err := eval(&runtimes.Replay{Dir: dir, Runtime: recorded}, script)
+	is.NoErr(err)
+	is.Equal(res, expected)
+	is.Equal(len(recorded.Runs()), 3)
+
+	res, err = eval(&runtimes.Replay{Dir: dir}, script)
+	is.NoErr(err)
+	is.Equal(res, expected)
+	is.Equal(len(recorded.Runs()), 3)
+
+	_, err = eval(&runtimes.Replay{Dir: dir}, `
+		(run (from {:platform {:os "linux"} :repository "alpine" :tag "latest"}
+			($ echo goodbye)))
+	`)
+	var missing runtimes.ReplayMissingError
+	is.True(errors.As(err, &missing))
+	is.Equal(missing.Op, "run")
+	is.Equal(missing.Thunk.Cmdline(), "echo goodbye")
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+// traceMounts writes the files in each traced mount along with whether they
+// were read to the check's trace file, keyed by mount target.
+//
+// Paths are slash-separated and relative to the mount target, so that the
+// runtime can map them back to the host paths they came from.
+func traceMounts(check *MountCheck, traced []string) error {
+	traceable := map[string]bool{}
+	for _, target := range traced {
+		traceable[target] = true
+	}
+
+	mounts := map[string]map[string]bool{}
+	for _, target := range check.Trace {
+		if !traceable[target] {
+			continue
+		}
+
+		files := map[string]bool{}
+
+		err := walkMount(target, func(p string, d fs.DirEntry) error {
+			if d.IsDir() {
+				return nil
+			}
+
+			rel, err := filepath.Rel(target, p)
+			if err != nil {
+				return err
+			}
+
+			var st unix.Stat_t
+			if err := unix.Lstat(p, &st); err != nil {
+				return err
+			}
+
+			files[filepath.ToSlash(rel)] = accessed(st)
+
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("trace mount %s: %w", target, err)
+		}
+
+		mounts[target] = files
+	}
+
+	payload, err := json.Marshal(mounts)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(check.TraceFile, payload, 0600)
+}
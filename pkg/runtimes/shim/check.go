@@ -19,6 +19,7 @@ func check(args []string) error {
 
 	host, ports := args[0], args[1:]
 
+	var reachedIP string
 	for _, nameAndPort := range ports {
 		name, port, ok := strings.Cut(nameAndPort, ":")
 		if !ok {
@@ -37,8 +38,13 @@ func check(args []string) error {
 		}
 
 		logger.Info("port is up", zap.String("reached", reached))
+
+		reachedIP = reached
 	}
 
+	// report the IP so the runtime can publish the service's name
+	fmt.Println(reachedIP)
+
 	return nil
 }
 
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/moby/sys/mountinfo"
+	"go.uber.org/zap"
+	"golang.org/x/sys/unix"
+)
+
+type MountCheck struct {
+	Targets   []string `json:"targets"`
+	Error     bool     `json:"error"`
+	Trace     []string `json:"trace"`
+	TraceFile string   `json:"trace_file"`
+}
+
+// errAccessed stops walking a mount once an access is found.
+var errAccessed = errors.New("accessed")
+
+// prepareMountCheck resets the access time of everything in each mount to
+// the epoch, returning the mounts whose access can be traced.
+//
+// Reading a file or listing a directory bumps its access time back up, even
+// with relatime, since the reset leaves the access time older than the
+// change time. Mounts whose access times can't be reset, e.g. read-only
+// mounts, or won't be updated, i.e. noatime mounts, are skipped.
+func prepareMountCheck(check *MountCheck) []string {
+	logger := StdLogger(logLevel)
+
+	tspec := unix.NsecToTimespec(epoch.UnixNano())
+	atimeOnly := []unix.Timespec{tspec, {Nsec: unix.UTIME_OMIT}}
+
+	var traced []string
+	for _, target := range append(check.Targets, check.Trace...) {
+		if contains(traced, target) {
+			continue
+		}
+
+		if noatime(target) {
+			logger.Debug("skipping noatime mount", zap.String("target", target))
+			continue
+		}
+
+		// collect paths first so that listing directories doesn't bump the
+		// access times just reset
+		var paths []string
+		err := walkMount(target, func(path string, _ fs.DirEntry) error {
+			paths = append(paths, path)
+			return nil
+		})
+		if err != nil {
+			logger.Debug("skipping unwalkable mount", zap.String("target", target), zap.Error(err))
+			continue
+		}
+
+		var resetErr error
+		for _, path := range paths {
+			resetErr = unix.UtimesNanoAt(unix.AT_FDCWD, path, atimeOnly, unix.AT_SYMLINK_NOFOLLOW)
+			if resetErr != nil {
+				break
+			}
+		}
+
+		if resetErr != nil {
+			logger.Debug("skipping untraceable mount", zap.String("target", target), zap.Error(resetErr))
+			continue
+		}
+
+		traced = append(traced, target)
+	}
+
+	return traced
+}
+
+// checkMounts reports each traced mount whose contents were never accessed,
+// returning an error if the check is configured to fail.
+func checkMounts(check *MountCheck, traced []string) error {
+	var unused []string
+	for _, target := range traced {
+		if !contains(check.Targets, target) {
+			continue
+		}
+
+		err := walkMount(target, func(path string, _ fs.DirEntry) error {
+			var st unix.Stat_t
+			if err := unix.Lstat(path, &st); err != nil {
+				return err
+			}
+
+			if accessed(st) {
+				return errAccessed
+			}
+
+			return nil
+		})
+		if errors.Is(err, errAccessed) {
+			continue
+		}
+
+		if err != nil {
+			return fmt.Errorf("check mount %s: %w", target, err)
+		}
+
+		unused = append(unused, target)
+	}
+
+	if len(unused) == 0 {
+		return nil
+	}
+
+	for _, target := range unused {
+		fmt.Fprintf(os.Stderr, "bass: mount %s was never accessed\n", target)
+	}
+
+	if check.Error {
+		return fmt.Errorf("unused mounts: %s", strings.Join(unused, ", "))
+	}
+
+	return nil
+}
+
+// accessed returns true if the path's access time has been bumped since
+// prepareMountCheck reset it.
+func accessed(st unix.Stat_t) bool {
+	return unix.TimespecToNsec(st.Atim) != epoch.UnixNano()
+}
+
+// walkMount calls fn for each path in the mount, without descending into
+// other mounts nested within it.
+func walkMount(target string, fn func(string, fs.DirEntry) error) error {
+	return filepath.WalkDir(target, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path != target && d.IsDir() {
+			mp, err := mountinfo.Mounted(path)
+			if err != nil {
+				return fmt.Errorf("check mounted: %w", err)
+			}
+
+			if mp {
+				return fs.SkipDir
+			}
+		}
+
+		return fn(path, d)
+	})
+}
+
+// noatime returns true if the mount at target does not update access times.
+func noatime(target string) bool {
+	mounts, err := mountinfo.GetMounts(mountinfo.SingleEntryFilter(target))
+	if err != nil || len(mounts) == 0 {
+		// not found; assume access times are updated
+		return false
+	}
+
+	for _, opt := range strings.Split(mounts[0].Options, ",") {
+		if opt == "noatime" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func contains(targets []string, target string) bool {
+	for _, t := range targets {
+		if t == target {
+			return true
+		}
+	}
+
+	return false
+}
@@ -25,6 +25,8 @@ type Command struct {
 	Stdin []byte   `json:"stdin"`
 	Env   []string `json:"env"`
 	Dir   *string  `json:"dir"`
+
+	StdinFile *string `json:"stdin_file"`
 }
 
 func run(args []string) error {
@@ -74,6 +76,17 @@ func run(args []string) error {
 	stdoutPath := os.Getenv("_BASS_OUTPUT")
 	os.Unsetenv("_BASS_OUTPUT")
 
+	// the mount check is passed separately so that it isn't part of the
+	// command's cache key
+	var mountCheck *MountCheck
+	if payload := os.Getenv("_BASS_MOUNT_CHECK"); payload != "" {
+		mountCheck = &MountCheck{}
+		if err := json.Unmarshal([]byte(payload), mountCheck); err != nil {
+			return fmt.Errorf("unmarshal mount check: %w", err)
+		}
+	}
+	os.Unsetenv("_BASS_MOUNT_CHECK")
+
 	var stdout io.Writer = os.Stdout
 	if stdoutPath != "" {
 		response, err := os.Create(stdoutPath)
@@ -102,9 +115,24 @@ func run(args []string) error {
 		execCmd.Dir = *cmd.Dir
 	}
 	execCmd.Stdin = bytes.NewBuffer(cmd.Stdin)
+	if cmd.StdinFile != nil {
+		stdinFile, err := os.Open(*cmd.StdinFile)
+		if err != nil {
+			return fmt.Errorf("open stdin file: %w", err)
+		}
+
+		defer stdinFile.Close()
+
+		execCmd.Stdin = io.MultiReader(bytes.NewBuffer(cmd.Stdin), stdinFile)
+	}
 	execCmd.Stdout = stdout
 	execCmd.Stderr = os.Stderr
 
+	var traced []string
+	if mountCheck != nil {
+		traced = prepareMountCheck(mountCheck)
+	}
+
 	ch, err := reaper.Default.Start(execCmd)
 	if err != nil {
 		return fmt.Errorf("start: %w", err)
@@ -121,6 +149,19 @@ func run(args []string) error {
 		return nil
 	}
 
+	if mountCheck != nil {
+		// check before normalizing, which resets access times in the workdir
+		if mountCheck.TraceFile != "" {
+			if err := traceMounts(mountCheck, traced); err != nil {
+				return err
+			}
+		}
+
+		if err := checkMounts(mountCheck, traced); err != nil {
+			return err
+		}
+	}
+
 	err = normalizeTimes(".")
 	if err != nil {
 		return fmt.Errorf("failed to normalize timestamps: %w", err)
@@ -58,6 +58,10 @@ func Suite(t *testing.T, pool bass.RuntimePool) {
 			File:   "response-stdout.bass",
 			Result: bass.NewList(allJSONValues...),
 		},
+		{
+			File:   "pipe.bass",
+			Result: bass.String("!DLROW ,OLLEH\n"),
+		},
 		{
 			File:   "thunk-paths.bass",
 			Result: bass.NewList(bass.Int(42), bass.String("hello")),
@@ -210,6 +214,10 @@ func Suite(t *testing.T, pool bass.RuntimePool) {
 			File:   "addrs.bass",
 			Result: bass.String("hello, world!"),
 		},
+		{
+			File:   "service-names.bass",
+			Result: bass.String("hello, world!"),
+		},
 		{
 			File:   "tls.bass",
 			Result: bass.Bool(true),
@@ -266,6 +274,46 @@ func Suite(t *testing.T, pool bass.RuntimePool) {
 		is.True(cmp.Equal(deadline, time.Now(), cmpopts.EquateApproxTime(10*time.Second)))
 	})
 
+	t.Run("unused mounts", func(t *testing.T) {
+		is := is.New(t)
+		t.Parallel()
+
+		ctx := context.Background()
+		ctx = bass.WithMountCheck(ctx, bass.MountCheckError)
+
+		displayBuf := new(bytes.Buffer)
+		ctx = ioctx.StderrToContext(ctx, displayBuf)
+		_, err := RunTest(ctx, t, pool, "unused-mounts.bass", nil)
+		t.Logf("progress:\n%s", displayBuf.String())
+		is.True(err != nil)
+		is.True(strings.Contains(displayBuf.String(), "unused mounts: /bass/work/unused"))
+	})
+
+	t.Run("access trace", func(t *testing.T) {
+		is := is.New(t)
+		t.Parallel()
+
+		trace := bass.NewAccessTrace()
+
+		ctx := context.Background()
+		ctx = bass.WithAccessTrace(ctx, trace)
+
+		res, err := RunTest(ctx, t, pool, "access-trace.bass", nil)
+		is.NoErr(err)
+		Equal(t, res, bass.String("used\n"))
+
+		report := new(bytes.Buffer)
+		trace.Report(report)
+		t.Logf("report:\n%s", report.String())
+
+		// paths are relative to the context dir, i.e. the testdata dir, and
+		// merged across both thunks
+		is.True(strings.Contains(report.String(), "read 2 of 3 files; suggested .bassignore:"))
+		is.True(strings.Contains(report.String(), "!access-trace/used\n"))
+		is.True(strings.Contains(report.String(), "!access-trace/also-used\n"))
+		is.True(!strings.Contains(report.String(), "unused"))
+	})
+
 	t.Run("secrets", func(t *testing.T) {
 		t.Parallel()
 